@@ -3,8 +3,10 @@ package all
 
 import (
 	"github.com/mrcruz117/al-service/api/http/api/mux"
+	"github.com/mrcruz117/al-service/api/http/domain/accountapi"
 	"github.com/mrcruz117/al-service/api/http/domain/authapi"
 	"github.com/mrcruz117/al-service/api/http/domain/checkapi"
+	"github.com/mrcruz117/al-service/api/http/domain/scimapi"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
@@ -19,12 +21,41 @@ type add struct{}
 // Add implements the RouterAdder interface.
 func (add) Add(app *web.App, cfg mux.Config) {
 	checkapi.Routes(app, checkapi.Config{
-		Build: cfg.Build,
-		Log:   cfg.Log,
-		DB:    cfg.DB,
+		Build:  cfg.Build,
+		Log:    cfg.Log,
+		DB:     cfg.DB,
+		Warmup: cfg.Warmup,
 	})
 
 	authapi.Routes(app, authapi.Config{
-		Auth: cfg.Auth,
+		Log:            cfg.Log,
+		Auth:           cfg.Auth,
+		ActiveKID:      cfg.ActiveKID,
+		TokenTTL:       cfg.TokenTTL,
+		ServiceTTL:     cfg.ServiceTTL,
+		Sessions:       cfg.Sessions,
+		SessionClients: cfg.SessionClients,
+		UserCore:       cfg.UserCore,
+		ClientCore:     cfg.ClientCore,
+		ServiceSecrets: cfg.ServiceSecrets,
+		ServiceMaxSkew: cfg.ServiceMaxSkew,
+	})
+
+	scimapi.Routes(app, scimapi.Config{
+		Log:       cfg.Log,
+		Auth:      cfg.Auth,
+		UserCore:  cfg.UserCore,
+		GroupCore: cfg.GroupCore,
+	})
+
+	accountapi.Routes(app, accountapi.Config{
+		Log:        cfg.Log,
+		Auth:       cfg.Auth,
+		UserCore:   cfg.UserCore,
+		TokenCore:  cfg.UserToken,
+		Preference: cfg.Preference,
+		Sessions:   cfg.Sessions,
+		Notify:     cfg.Notify,
+		LinkBase:   cfg.LinkBase,
 	})
 }
@@ -2,24 +2,56 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"expvar"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
+	"github.com/google/uuid"
 	"github.com/mrcruz117/al-service/api/cmd/services/auth/build/all"
 	"github.com/mrcruz117/al-service/api/http/api/debug"
+	"github.com/mrcruz117/al-service/api/http/api/mid"
 	"github.com/mrcruz117/al-service/api/http/api/mux"
+	"github.com/mrcruz117/al-service/api/http/api/routecfg"
 	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/metrics"
+	"github.com/mrcruz117/al-service/app/api/session"
+	"github.com/mrcruz117/al-service/business/api/delegate"
 	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/client"
+	"github.com/mrcruz117/al-service/business/core/client/stores/clientdb"
+	"github.com/mrcruz117/al-service/business/core/group"
+	"github.com/mrcruz117/al-service/business/core/group/stores/groupdb"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+	"github.com/mrcruz117/al-service/business/core/outbox/stores/outboxdb"
+	"github.com/mrcruz117/al-service/business/core/preference"
+	"github.com/mrcruz117/al-service/business/core/preference/stores/preferencedb"
+	"github.com/mrcruz117/al-service/business/core/registry"
+	"github.com/mrcruz117/al-service/business/core/registry/stores/registrydb"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usercache"
+	"github.com/mrcruz117/al-service/business/core/user/stores/userdb"
+	"github.com/mrcruz117/al-service/business/core/user/stores/useroutbox"
+	"github.com/mrcruz117/al-service/business/core/usertoken"
+	"github.com/mrcruz117/al-service/business/core/usertoken/stores/usertokendb"
+	"github.com/mrcruz117/al-service/foundation/cachestore"
+	"github.com/mrcruz117/al-service/foundation/events"
 	"github.com/mrcruz117/al-service/foundation/keystore"
+	"github.com/mrcruz117/al-service/foundation/keystore/kms"
+	"github.com/mrcruz117/al-service/foundation/keystore/vault"
 	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/notify"
+	"github.com/mrcruz117/al-service/foundation/queuemon"
+	"github.com/mrcruz117/al-service/foundation/warmup"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
@@ -62,28 +94,128 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	cfg := struct {
 		conf.Version
+		Registry struct {
+			Interval time.Duration `conf:"default:15s,help:how often this instance reports itself to the service registry"`
+		}
+		Log struct {
+			Format       string        `conf:"default:json,help:json for production or console for reading logs in a terminal"`
+			Level        string        `conf:"default:info,help:minimum level logged by packages without a level of their own"`
+			Levels       string        `conf:"help:per package minimum levels such as business/core/user=debug,foundation/web=info"`
+			File         string        `conf:"help:file logs are written to instead of stdout"`
+			MaxSize      int64         `conf:"default:104857600,help:bytes the log file grows to before it's rotated"`
+			MaxAge       time.Duration `conf:"default:168h,help:how long rotated log files are kept"`
+			Compress     bool          `conf:"default:true"`
+			AsyncBuffer  int           `conf:"default:0,help:logs buffered and written in the background, 0 writes them synchronously"`
+			AsyncPolicy  string        `conf:"default:block,help:block or drop logs when the buffer is full"`
+			SampleWindow time.Duration `conf:"default:1s"`
+			SampleDebug  int           `conf:"default:0,help:log 1 of every N identical debug messages per window"`
+			SampleInfo   int           `conf:"default:0,help:log 1 of every N identical info messages per window"`
+			SampleWarn   int           `conf:"default:0,help:log 1 of every N identical warn messages per window"`
+		}
 		Web struct {
 			ReadTimeout        time.Duration `conf:"default:5s"`
 			WriteTimeout       time.Duration `conf:"default:10s"`
 			IdleTimeout        time.Duration `conf:"default:120s"`
+			ReadHeaderTimeout  time.Duration `conf:"default:5s,help:time allowed to read the request headers, which bounds slow clients holding connections"`
+			MaxHeaderBytes     int           `conf:"default:1048576,help:largest request headers accepted"`
 			ShutdownTimeout    time.Duration `conf:"default:20s"`
+			DrainPeriod        time.Duration `conf:"default:20s,help:how long readiness fails before the server stops accepting connections"`
 			APIHost            string        `conf:"default:0.0.0.0:6000"`
 			DebugHost          string        `conf:"default:0.0.0.0:6100"`
+			WarmupRetry        time.Duration `conf:"default:2s"`
+			MaxInflight        int           `conf:"default:500"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
+			Experimental       []string      `conf:"help:capabilities whose experimental routes are enabled"`
 		}
 		Auth struct {
-			KeysFolder string `conf:"default:zarf/keys/"`
-			ActiveKID  string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
-			Issuer     string `conf:"default:service project"`
+			KeysFolder string        `conf:"default:zarf/keys/"`
+			KeyStore   string        `conf:"default:file,help:where the signing keys are held: file, vault or kms"`
+			RemoteKeys []string      `conf:"help:kid:name pairs mapping the kids to the keys held by vault or kms"`
+			KeyTTL     time.Duration `conf:"default:5m,help:how long the public keys of vault or kms are cached"`
+			ActiveKID  string        `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
+			TokenTTL   time.Duration `conf:"default:8h,help:how long the tokens issued by login are valid"`
+			ServiceTTL time.Duration `conf:"default:10m,help:how long the tokens issued to service clients are valid"`
+			Issuer     string        `conf:"default:service project"`
+			Claims     []string      `conf:"help:claim:attribute or claim:=value pairs adding claims such as department to the tokens issued to users"`
+			Providers  string        `conf:"help:json file listing the external oidc providers whose tokens are accepted"`
+			Policies   string        `conf:"help:directory holding authentication.rego and authorization.rego, the compiled in policies are used when empty"`
+			PolicyPoll time.Duration `conf:"default:30s,help:how often the policy directory is checked for changes"`
+		}
+		Sessions struct {
+			Clients []string      `conf:"help:client types given session tokens instead of jwts by login, such as web"`
+			Idle    time.Duration `conf:"default:30m,help:how long a session lives unused, every use extends it"`
+			MaxAge  time.Duration `conf:"default:24h,help:how long a session lives at most"`
+		}
+		Cache struct {
+			Addrs      []string `conf:"help:redis addresses, sessions are disabled when empty"`
+			MasterName string   `conf:"help:sentinel master name for failover"`
+			Password   string   `conf:"mask"`
+			DB         int      `conf:"default:0"`
+			PoolSize   int      `conf:"default:10"`
+		}
+		UserCache struct {
+			Size int           `conf:"default:10000,help:users kept in process"`
+			TTL  time.Duration `conf:"default:1m,help:how long a looked up user is cached, 0 reads the database for every lookup"`
+		}
+		Vault struct {
+			Addr      string
+			Token     string `conf:"mask"`
+			Mount     string `conf:"default:transit"`
+			Namespace string
+		}
+		KMS struct {
+			Region          string
+			AccessKeyID     string
+			SecretAccessKey string `conf:"mask"`
+			SessionToken    string `conf:"mask"`
+			Endpoint        string `conf:"help:kms endpoint, the regional one when empty"`
+		}
+		Services struct {
+			Secrets []string      `conf:"mask,help:service:secret pairs verifying the requests signed by other services"`
+			MaxSkew time.Duration `conf:"default:5m,help:largest clock difference allowed on a signed request"`
+		}
+		Account struct {
+			LinkBase   string        `conf:"default:http://localhost:3000/account"`
+			VerifyTTL  time.Duration `conf:"default:24h"`
+			ResetTTL   time.Duration `conf:"default:1h"`
+			RateLimit  int           `conf:"default:3"`
+			RateWindow time.Duration `conf:"default:1h"`
+		}
+		Preferences struct {
+			SchemaFile    string `conf:"help:json file declaring the preferences users can store, disabled when empty"`
+			MaxBytes      int    `conf:"default:16384"`
+			EncryptionKey string `conf:"mask,help:hex encoded 32 byte key for sensitive preferences"`
+		}
+		Notify struct {
+			QueueSize     int           `conf:"default:1000"`
+			Retries       int           `conf:"default:3"`
+			Backoff       time.Duration `conf:"default:5s"`
+			SMTPAddr      string        `conf:"help:smtp relay host:port, required unless log messages is set"`
+			SMTPFrom      string        `conf:"default:no-reply@example.com"`
+			SMTPUsername  string
+			SMTPPassword  string        `conf:"mask"`
+			LogMessages   bool          `conf:"default:false,help:log messages instead of delivering them, for development only"`
+			CheckInterval time.Duration `conf:"default:15s"`
+			MaxDepth      int64         `conf:"default:500"`
+			MaxAge        time.Duration `conf:"default:5m"`
+		}
+		Routes struct {
+			ConfigFile string `conf:"help:yaml file mapping route groups to their middleware"`
+		}
+		SCIM struct {
+			GroupRoles []string `conf:"default:admins:ADMIN;users:USER"`
 		}
 		DB struct {
-			User         string `conf:"default:postgres"`
-			Password     string `conf:"default:postgres,mask"`
-			HostPort     string `conf:"default:database-service.sales-system.svc.cluster.local"`
-			Name         string `conf:"default:postgres"`
-			MaxIdleConns int    `conf:"default:2"`
-			MaxOpenConns int    `conf:"default:0"`
-			DisableTLS   bool   `conf:"default:true"`
+			User            string        `conf:"default:postgres"`
+			Password        string        `conf:"default:postgres,mask"`
+			HostPort        string        `conf:"default:database-service.sales-system.svc.cluster.local"`
+			Name            string        `conf:"default:postgres"`
+			MaxIdleConns    int           `conf:"default:2"`
+			MaxOpenConns    int           `conf:"default:0"`
+			ConnMaxLifetime time.Duration `conf:"default:30m,help:how long a connection is kept before it is replaced or 0 to keep it"`
+			ConnMaxIdleTime time.Duration `conf:"default:5m,help:how long an idle connection is kept before it is closed or 0 to keep it"`
+			DisableTLS      bool          `conf:"default:true"`
+			Dialect         string        `conf:"default:postgres"`
 		}
 	}{
 		Version: conf.Version{
@@ -102,6 +234,78 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("parsing config: %w", err)
 	}
 
+	var logOut io.Writer = os.Stdout
+
+	if cfg.Log.File != "" {
+		file, err := logger.NewRotatingFile(cfg.Log.File, logger.RotateConfig{
+			MaxSize:  cfg.Log.MaxSize,
+			MaxAge:   cfg.Log.MaxAge,
+			Compress: cfg.Log.Compress,
+		})
+		if err != nil {
+			return fmt.Errorf("opening log file: %w", err)
+		}
+		defer file.Close()
+
+		logOut = file
+	}
+
+	if cfg.Log.AsyncBuffer > 0 {
+		policy, err := logger.ParsePolicy(cfg.Log.AsyncPolicy)
+		if err != nil {
+			return fmt.Errorf("parsing log policy: %w", err)
+		}
+
+		async := logger.NewAsyncWriter(logOut, logger.AsyncConfig{
+			Size:   cfg.Log.AsyncBuffer,
+			Policy: policy,
+		})
+		defer func() {
+			async.Close()
+			if n := async.Dropped(); n > 0 {
+				log.Warn(ctx, "shutdown", "status", "logs dropped while the buffer was full", "dropped", n)
+			}
+		}()
+
+		logOut = async
+	}
+
+	if logOut != os.Stdout {
+		log.SetOutput(logOut)
+
+		// Startup errors reported by main after run returns still need
+		// somewhere to go, and the buffered logs need to be written first.
+		defer log.SetOutput(os.Stdout)
+	}
+
+	format, err := logger.ParseFormat(cfg.Log.Format)
+	if err != nil {
+		return fmt.Errorf("parsing log format: %w", err)
+	}
+	log.SetFormat(format)
+
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return fmt.Errorf("parsing log level: %w", err)
+	}
+	log.SetLevel(level)
+
+	levels, err := logger.ParseLevels(cfg.Log.Levels)
+	if err != nil {
+		return fmt.Errorf("parsing log levels: %w", err)
+	}
+	log.SetLevels(levels)
+
+	log.SetCounter(func(service string, level logger.Level) {
+		metrics.AddLogs(service, level.String())
+	})
+
+	log.SetSampling(logger.Sampling{
+		logger.LevelDebug: {Every: cfg.Log.SampleDebug, Window: cfg.Log.SampleWindow},
+		logger.LevelInfo:  {Every: cfg.Log.SampleInfo, Window: cfg.Log.SampleWindow},
+		logger.LevelWarn:  {Every: cfg.Log.SampleWarn, Window: cfg.Log.SampleWindow},
+	})
+
 	// -------------------------------------------------------------------------
 	// App Starting
 
@@ -122,13 +326,16 @@ func run(ctx context.Context, log *logger.Logger) error {
 	log.Info(ctx, "startup", "status", "initializing database support", "hostport", cfg.DB.HostPort)
 
 	db, err := sqldb.Open(sqldb.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		HostPort:     cfg.DB.HostPort,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenConns,
-		DisableTLS:   cfg.DB.DisableTLS,
+		User:            cfg.DB.User,
+		Password:        cfg.DB.Password,
+		HostPort:        cfg.DB.HostPort,
+		Name:            cfg.DB.Name,
+		MaxIdleConns:    cfg.DB.MaxIdleConns,
+		MaxOpenConns:    cfg.DB.MaxOpenConns,
+		ConnMaxLifetime: cfg.DB.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DB.ConnMaxIdleTime,
+		DisableTLS:      cfg.DB.DisableTLS,
+		Dialect:         cfg.DB.Dialect,
 	})
 	if err != nil {
 		return fmt.Errorf("connecting to db: %w", err)
@@ -136,22 +343,245 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	defer db.Close()
 
+	// -------------------------------------------------------------------------
+	// Cache Support
+
+	log.Info(ctx, "startup", "status", "initializing cache support")
+
+	var cache *cachestore.Store
+	if len(cfg.Cache.Addrs) > 0 {
+		cache, err = cachestore.Open(log, cachestore.Config{
+			Addrs:      cfg.Cache.Addrs,
+			MasterName: cfg.Cache.MasterName,
+			Password:   cfg.Cache.Password,
+			DB:         cfg.Cache.DB,
+			PoolSize:   cfg.Cache.PoolSize,
+		})
+		if err != nil {
+			return fmt.Errorf("connecting to cache: %w", err)
+		}
+		defer cache.Close()
+	}
+
+	// -------------------------------------------------------------------------
+	// Create Business Packages
+
+	workerCtx, workerCancel := context.WithCancel(ctx)
+	defer workerCancel()
+
+	dlg := delegate.New(log)
+	beginner := sqldb.NewBeginner(db)
+
+	// The users provisioned here are added to the outbox like the ones the
+	// sales service creates. The sales service relays the outbox, so this
+	// core never publishes anything itself.
+	outboxCore := outbox.NewCore(log, outboxdb.NewStore(log, db), beginner, events.Discard, "auth")
+
+	var userStore user.Storer = useroutbox.NewStore(userdb.NewStore(log, db), outboxCore, beginner)
+	if cfg.UserCache.TTL > 0 {
+		cached := usercache.NewStore(log, userStore, usercache.Config{
+			Size:  cfg.UserCache.Size,
+			TTL:   cfg.UserCache.TTL,
+			Cache: cache,
+		})
+
+		go cached.Run(workerCtx)
+
+		userStore = cached
+	}
+
+	userCore := user.NewCore(log, dlg, userStore)
+	clientCore := client.NewCore(log, clientdb.NewStore(log, db))
+
+	groupRoles := make(map[string]string, len(cfg.SCIM.GroupRoles))
+	for _, binding := range cfg.SCIM.GroupRoles {
+		name, role, ok := strings.Cut(binding, ":")
+		if !ok {
+			return fmt.Errorf("invalid scim group role binding %q, expecting group:ROLE", binding)
+		}
+		groupRoles[name] = role
+	}
+
+	groupCore := group.NewCore(log, groupdb.NewStore(log, db), userCore, groupRoles)
+
+	tokenCore := usertoken.NewCore(log, usertokendb.NewStore(log, db), usertoken.Config{
+		TTL: map[usertoken.Purpose]time.Duration{
+			usertoken.VerifyEmail:   cfg.Account.VerifyTTL,
+			usertoken.ResetPassword: cfg.Account.ResetTTL,
+		},
+		RateLimit:  cfg.Account.RateLimit,
+		RateWindow: cfg.Account.RateWindow,
+	})
+
+	// -------------------------------------------------------------------------
+	// Initialize preference support
+
+	var prefCore *preference.Core
+	if cfg.Preferences.SchemaFile != "" {
+		log.Info(ctx, "startup", "status", "initializing preference support")
+
+		schema, err := preference.LoadSchema(cfg.Preferences.SchemaFile)
+		if err != nil {
+			return fmt.Errorf("loading preference schema: %w", err)
+		}
+
+		var key []byte
+		if cfg.Preferences.EncryptionKey != "" {
+			if key, err = hex.DecodeString(cfg.Preferences.EncryptionKey); err != nil {
+				return fmt.Errorf("decoding preference encryption key: %w", err)
+			}
+		}
+
+		prefCore, err = preference.NewCore(log, preferencedb.NewStore(log, db), preference.Config{
+			Schema:   schema,
+			MaxBytes: cfg.Preferences.MaxBytes,
+			Key:      key,
+		})
+		if err != nil {
+			return fmt.Errorf("constructing preference core: %w", err)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize notification support
+
+	log.Info(ctx, "startup", "status", "initializing notification support")
+
+	var sender notify.Sender
+	switch {
+	case cfg.Notify.SMTPAddr != "":
+		sender = notify.SMTPSender{
+			Addr:     cfg.Notify.SMTPAddr,
+			From:     cfg.Notify.SMTPFrom,
+			Username: cfg.Notify.SMTPUsername,
+			Password: cfg.Notify.SMTPPassword,
+		}
+
+	case cfg.Notify.LogMessages:
+		log.Warn(ctx, "startup", "status", "notifications are logged instead of delivered")
+		sender = notify.LogSender{Log: log}
+
+	default:
+		return fmt.Errorf("notify: an smtp addr is required, or log messages set for development")
+	}
+
+	notifyQueue := notify.NewQueue(log, sender, cfg.Notify.QueueSize, cfg.Notify.Retries, cfg.Notify.Backoff)
+
+	go notifyQueue.Run(workerCtx)
+
+	queueMon := queuemon.New(log)
+
+	queueMon.Register("notify", notifyQueue.Stats, queuemon.Thresholds{
+		Depth:     cfg.Notify.MaxDepth,
+		OldestAge: cfg.Notify.MaxAge,
+	})
+
+	go queueMon.Run(workerCtx, cfg.Notify.CheckInterval)
+
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
 
 	log.Info(ctx, "startup", "status", "initializing authentication support")
 
-	// Load the private keys files from disk. We can assume some system like
-	// Vault has created these files already. How that happens is not our
-	// concern.
-	ks := keystore.New()
-	if err := ks.LoadRSAKeys(os.DirFS(cfg.Auth.KeysFolder)); err != nil {
-		return fmt.Errorf("reading keys: %w", err)
+	var ks auth.KeyLookup
+	var keyCheck func(ctx context.Context) error
+
+	switch cfg.Auth.KeyStore {
+	case "file":
+
+		// Load the private keys files from disk. We can assume some system
+		// has created these files already. How that happens is not our
+		// concern.
+		fks := keystore.New()
+		if err := fks.LoadKeys(os.DirFS(cfg.Auth.KeysFolder)); err != nil {
+			return fmt.Errorf("reading keys: %w", err)
+		}
+		ks = fks
+
+	case "vault", "kms":
+
+		// The private keys stay in the key service, which signs the tokens.
+		var backend keystore.Backend
+
+		if cfg.Auth.KeyStore == "vault" {
+			backend, err = vault.New(vault.Config{
+				Addr:      cfg.Vault.Addr,
+				Token:     cfg.Vault.Token,
+				Mount:     cfg.Vault.Mount,
+				Namespace: cfg.Vault.Namespace,
+			})
+		} else {
+			backend, err = kms.New(kms.Config{
+				Region:          cfg.KMS.Region,
+				AccessKeyID:     cfg.KMS.AccessKeyID,
+				SecretAccessKey: cfg.KMS.SecretAccessKey,
+				SessionToken:    cfg.KMS.SessionToken,
+				Endpoint:        cfg.KMS.Endpoint,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("constructing %s keystore: %w", cfg.Auth.KeyStore, err)
+		}
+
+		keys := make(map[string]string, len(cfg.Auth.RemoteKeys))
+		for _, pair := range cfg.Auth.RemoteKeys {
+			kid, name, ok := strings.Cut(pair, ":")
+			if !ok || kid == "" || name == "" {
+				return fmt.Errorf("remote key must be in the form kid:name")
+			}
+			keys[kid] = name
+		}
+
+		rks, err := keystore.NewRemote(keystore.RemoteConfig{
+			Backend: backend,
+			Keys:    keys,
+			TTL:     cfg.Auth.KeyTTL,
+		})
+		if err != nil {
+			return fmt.Errorf("constructing %s keystore: %w", cfg.Auth.KeyStore, err)
+		}
+		ks = rks
+		keyCheck = rks.StatusCheck
+
+	default:
+		return fmt.Errorf("unknown keystore %q", cfg.Auth.KeyStore)
+	}
+
+	providers, err := auth.LoadProviders(cfg.Auth.Providers)
+	if err != nil {
+		return fmt.Errorf("loading oidc providers: %w", err)
+	}
+
+	claimMapping, err := auth.ParseClaimMapping(cfg.Auth.Claims)
+	if err != nil {
+		return fmt.Errorf("parsing claim mapping: %w", err)
 	}
 
 	authCfg := auth.Config{
-		Log:       log,
-		KeyLookup: ks,
+		Log:          log,
+		KeyLookup:    ks,
+		Issuer:       cfg.Auth.Issuer,
+		Providers:    providers,
+		ClaimMapping: claimMapping,
+		UserCore:     userCore,
+	}
+
+	var sessions *session.Store
+	if cache != nil {
+		sessions = session.New(session.Config{
+			Cache:  cache,
+			Idle:   cfg.Sessions.Idle,
+			MaxAge: cfg.Sessions.MaxAge,
+		})
+		authCfg.Sessions = sessions
+	}
+
+	if len(cfg.Sessions.Clients) > 0 && sessions == nil {
+		return errors.New("session clients are configured but there is no cache to store the sessions in")
+	}
+
+	if cfg.Auth.Policies != "" {
+		authCfg.Policies = os.DirFS(cfg.Auth.Policies)
 	}
 
 	ath, err := auth.New(authCfg)
@@ -159,16 +589,71 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("constructing auth: %w", err)
 	}
 
+	go ath.WatchPolicies(workerCtx, cfg.Auth.PolicyPoll)
+
 	// -------------------------------------------------------------------------
-	// Start Debug Service
+	// Initialize route middleware support
 
-	go func() {
-		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
+	routeCfg, err := routecfg.Load(cfg.Routes.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("loading route config: %w", err)
+	}
 
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
-			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
-		}
-	}()
+	routeMW := routecfg.Middleware(routeCfg, routecfg.Options{
+		Authorize: func(rule string) web.MidHandler {
+			return mid.AuthorizeLocal(ath, rule)
+		},
+	})
+
+	// -------------------------------------------------------------------------
+	// Initialize warmup support
+
+	log.Info(ctx, "startup", "status", "initializing warmup support")
+
+	logFunc := func(ctx context.Context, msg string, v ...any) {
+		log.Info(ctx, msg, v...)
+	}
+
+	wu := warmup.New(logFunc, cfg.Web.WarmupRetry)
+
+	wu.Add("database", func(ctx context.Context) error {
+		return sqldb.Prime(ctx, db, cfg.DB.MaxIdleConns)
+	})
+
+	wu.Add("opa", ath.Compile)
+
+	if keyCheck != nil {
+		wu.Add("keystore", keyCheck)
+	}
+
+	if cache != nil {
+		wu.Add("cache", cache.StatusCheck)
+	}
+
+	// -------------------------------------------------------------------------
+	// Start Registry Reporting
+
+	log.Info(ctx, "startup", "status", "initializing registry support")
+
+	registryCore := registry.NewCore(log, registrydb.NewStore(log, db))
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unavailable"
+	}
+
+	instance := registry.Instance{
+		ID:          uuid.New(),
+		Service:     "auth",
+		Build:       build,
+		Host:        host,
+		Address:     cfg.Web.APIHost,
+		DateStarted: time.Now(),
+	}
+
+	go registryCore.Run(workerCtx, instance, cfg.Registry.Interval, func(ctx context.Context) bool {
+		return wu.Ready() && sqldb.StatusCheck(ctx, db) == nil
+	})
 
 	// -------------------------------------------------------------------------
 	// Start API Service
@@ -178,20 +663,55 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	// -------------------------------------------------------------------------
+	// Initialize service signing support
+
+	serviceSecrets := make(map[string][]byte, len(cfg.Services.Secrets))
+	for _, pair := range cfg.Services.Secrets {
+		service, secret, ok := strings.Cut(pair, ":")
+		if !ok || service == "" || secret == "" {
+			return fmt.Errorf("service secret must be in the form service:secret")
+		}
+		serviceSecrets[service] = []byte(secret)
+	}
+
 	cfgMux := mux.Config{
-		Build: build,
-		Log:   log,
-		Auth:  ath,
-		DB:    db,
+		Name:            cfg.Desc,
+		Build:           build,
+		Log:             log,
+		Auth:            ath,
+		ActiveKID:       cfg.Auth.ActiveKID,
+		TokenTTL:        cfg.Auth.TokenTTL,
+		ServiceTTL:      cfg.Auth.ServiceTTL,
+		Sessions:        sessions,
+		SessionClients:  cfg.Sessions.Clients,
+		DB:              db,
+		UserCore:        userCore,
+		ClientCore:      clientCore,
+		GroupCore:       groupCore,
+		UserToken:       tokenCore,
+		Preference:      prefCore,
+		Notify:          notifyQueue,
+		LinkBase:        cfg.Account.LinkBase,
+		Warmup:          wu,
+		MaxInflight:     cfg.Web.MaxInflight,
+		Experimental:    cfg.Web.Experimental,
+		ServiceSecrets:  serviceSecrets,
+		ServiceMaxSkew:  cfg.Services.MaxSkew,
+		RouteMiddleware: routeMW,
 	}
 
+	webAPI := mux.WebAPI(cfgMux, all.Routes())
+
 	api := http.Server{
-		Addr:         cfg.Web.APIHost,
-		Handler:      mux.WebAPI(cfgMux, all.Routes()),
-		ReadTimeout:  cfg.Web.ReadTimeout,
-		WriteTimeout: cfg.Web.WriteTimeout,
-		IdleTimeout:  cfg.Web.IdleTimeout,
-		ErrorLog:     logger.NewStdLogger(log, logger.LevelError),
+		Addr:              cfg.Web.APIHost,
+		Handler:           webAPI,
+		ReadTimeout:       cfg.Web.ReadTimeout,
+		WriteTimeout:      cfg.Web.WriteTimeout,
+		IdleTimeout:       cfg.Web.IdleTimeout,
+		ReadHeaderTimeout: cfg.Web.ReadHeaderTimeout,
+		MaxHeaderBytes:    cfg.Web.MaxHeaderBytes,
+		ErrorLog:          logger.NewStdLogger(log, logger.LevelError),
 	}
 
 	serverErrors := make(chan error, 1)
@@ -202,6 +722,42 @@ func run(ctx context.Context, log *logger.Logger) error {
 		serverErrors <- api.ListenAndServe()
 	}()
 
+	// The readiness endpoint will not report ready until all the warmup
+	// hooks have completed.
+	warmupCtx, warmupCancel := context.WithCancel(ctx)
+	defer warmupCancel()
+
+	go func() {
+		if err := wu.Run(warmupCtx); err != nil {
+			log.Error(ctx, "warmup", "status", "warmup incomplete", "msg", err)
+			return
+		}
+
+		log.Info(ctx, "warmup", "status", "warmup complete")
+	}()
+
+	// -------------------------------------------------------------------------
+	// Start Debug Service
+
+	// The debug service is started once the api routes are bound so it can
+	// serve their documentation. Profiles and traces stream for as long as
+	// they are asked to, so only the headers are bounded.
+	dbg := http.Server{
+		Addr:              cfg.Web.DebugHost,
+		Handler:           debug.Mux(cfg.Build, mux.OpenAPI(webAPI, cfg.Desc, cfg.Build).Handler()),
+		ReadHeaderTimeout: cfg.Web.ReadHeaderTimeout,
+		MaxHeaderBytes:    cfg.Web.MaxHeaderBytes,
+		ErrorLog:          logger.NewStdLogger(log, logger.LevelError),
+	}
+
+	go func() {
+		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
+
+		if err := dbg.ListenAndServe(); err != nil {
+			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
+		}
+	}()
+
 	// -------------------------------------------------------------------------
 	// Shutdown
 
@@ -213,6 +769,19 @@ func run(ctx context.Context, log *logger.Logger) error {
 		log.Info(ctx, "shutdown", "status", "shutdown started", "signal", sig)
 		defer log.Info(ctx, "shutdown", "status", "shutdown complete", "signal", sig)
 
+		// Readiness fails for the drain period while the server keeps taking
+		// requests, which gives the probes time to take the instance out of
+		// the load balancer before connections are refused. A second signal
+		// cuts the period short.
+		wu.Drain()
+		log.Info(ctx, "shutdown", "status", "draining", "period", cfg.Web.DrainPeriod)
+
+		select {
+		case <-time.After(cfg.Web.DrainPeriod):
+		case sig := <-shutdown:
+			log.Info(ctx, "shutdown", "status", "drain cut short", "signal", sig)
+		}
+
 		ctx, cancel := context.WithTimeout(ctx, cfg.Web.ShutdownTimeout)
 		defer cancel()
 
@@ -3,8 +3,19 @@ package all
 
 import (
 	"github.com/mrcruz117/al-service/api/http/api/mux"
+	"github.com/mrcruz117/al-service/api/http/domain/adminapi"
+	"github.com/mrcruz117/al-service/api/http/domain/adminui"
+	"github.com/mrcruz117/al-service/api/http/domain/auditapi"
 	"github.com/mrcruz117/al-service/api/http/domain/checkapi"
+	"github.com/mrcruz117/al-service/api/http/domain/eventapi"
+	"github.com/mrcruz117/al-service/api/http/domain/orderapi"
+	"github.com/mrcruz117/al-service/api/http/domain/productapi"
+	"github.com/mrcruz117/al-service/api/http/domain/registryapi"
 	"github.com/mrcruz117/al-service/api/http/domain/testapi"
+	"github.com/mrcruz117/al-service/api/http/domain/userapi"
+	"github.com/mrcruz117/al-service/api/http/domain/vproductapi"
+	"github.com/mrcruz117/al-service/api/http/domain/webhookapi"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
@@ -19,13 +30,117 @@ type add struct{}
 // Add implements the RouterAdder interface.
 func (add) Add(app *web.App, cfg mux.Config) {
 	checkapi.Routes(app, checkapi.Config{
-		Build: cfg.Build,
-		Log:   cfg.Log,
-		DB:    cfg.DB,
+		Build:  cfg.Build,
+		Log:    cfg.Log,
+		DB:     cfg.DB,
+		Warmup: cfg.Warmup,
 	})
 
 	testapi.Routes(app, testapi.Config{
 		Log:        cfg.Log,
 		AuthClient: cfg.AuthClient,
+		Usage:      cfg.Usage,
+		APIKey:     cfg.APIKey,
+		Peers:      cfg.Peers,
+		Tenant:     cfg.Tenant,
+	})
+
+	adminapi.Routes(app, adminapi.Config{
+		Log:         cfg.Log,
+		AuthClient:  cfg.AuthClient,
+		Maintenance: cfg.Maintenance,
+		Usage:       cfg.Usage,
+		Audit:       cfg.Audit,
+		APIKey:      cfg.APIKey,
+		Tenant:      cfg.Tenant,
+		Feed:        cfg.Feed,
+		Events:      cfg.Events,
+		Posture:     cfg.Posture,
+		Subsystems:  cfg.Subsystems,
+	})
+
+	auditapi.Routes(app, auditapi.Config{
+		Log:             cfg.Log,
+		AuthClient:      cfg.AuthClient,
+		EntityAuditCore: cfg.EntityAuditCore,
+		Usage:           cfg.Usage,
+	})
+
+	eventapi.Routes(app, eventapi.Config{
+		Log:        cfg.Log,
+		AuthClient: cfg.AuthClient,
+		Feed:       cfg.Feed,
+		MaxWait:    cfg.PollMaxWait,
+		MaxEvents:  cfg.PollMaxEvents,
+		MaxPolls:   cfg.PollMaxPolls,
+		Subsystems: cfg.Subsystems,
+		Usage:      cfg.Usage,
+	})
+
+	userapi.Routes(app, userapi.Config{
+		Log:        cfg.Log,
+		AuthClient: cfg.AuthClient,
+		UserCore:   cfg.UserCore,
+		Tenant:     cfg.Tenant,
+		Usage:      cfg.Usage,
+	})
+
+	productapi.Routes(app, productapi.Config{
+		Log:         cfg.Log,
+		AuthClient:  cfg.AuthClient,
+		ProductCore: cfg.ProductCore,
+		Tenant:      cfg.Tenant,
+		Usage:       cfg.Usage,
+	})
+
+	vproductapi.Routes(app, vproductapi.Config{
+		Log:          cfg.Log,
+		AuthClient:   cfg.AuthClient,
+		VProductCore: cfg.VProductCore,
+		Tenant:       cfg.Tenant,
+		Usage:        cfg.Usage,
+	})
+
+	orderapi.Routes(app, orderapi.Config{
+		Log:        cfg.Log,
+		AuthClient: cfg.AuthClient,
+		OrderCore:  cfg.OrderCore,
+		Beginner:   sqldb.NewBeginner(cfg.DB),
+		Tenant:     cfg.Tenant,
+		Usage:      cfg.Usage,
+	})
+
+	registryapi.Routes(app, registryapi.Config{
+		Log:        cfg.Log,
+		AuthClient: cfg.AuthClient,
+		Registry:   cfg.Registry,
+		StaleAfter: cfg.StaleAfter,
+		Usage:      cfg.Usage,
+		Peers:      cfg.Peers,
+	})
+
+	webhookapi.Routes(app, webhookapi.Config{
+		Log:        cfg.Log,
+		Secrets:    cfg.Webhooks,
+		MaxAge:     cfg.WebhookMaxAge,
+		Subsystems: cfg.Subsystems,
+	})
+}
+
+// AdminRoutes constructs the adminAdd value which provides the implementation
+// of RouteAdder for the internal admin pages.
+func AdminRoutes() adminAdd {
+	return adminAdd{}
+}
+
+type adminAdd struct{}
+
+// Add implements the RouterAdder interface.
+func (adminAdd) Add(app *web.App, cfg mux.Config) {
+	adminui.Routes(app, adminui.Config{
+		Build:       cfg.Build,
+		Log:         cfg.Log,
+		AuthClient:  cfg.AuthClient,
+		Maintenance: cfg.Maintenance,
 	})
 }
@@ -0,0 +1,112 @@
+package all_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/api/cmd/services/sales/build/all"
+	"github.com/mrcruz117/al-service/api/http/api/mux"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/product/stores/productmem"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/business/core/usage/stores/usagemem"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usermem"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+)
+
+// Test_Usage makes a request to a domain route and checks its caller is
+// reported by the admin top consumer report.
+func Test_Usage(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+	ctx := context.Background()
+
+	subject := uuid.New()
+
+	// The auth service accepts every token as the one of an admin.
+	authSvc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/authenticate":
+			json.NewEncoder(w).Encode(authclient.AuthenticateResp{
+				UserID: subject,
+				Claims: auth.Claims{
+					RegisteredClaims: jwt.RegisteredClaims{Subject: subject.String()},
+					Roles:            []string{auth.RoleAdmin},
+				},
+			})
+
+		case "/auth/authorize":
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer authSvc.Close()
+
+	usageCore := usage.NewCore(log, usagemem.NewStore(), time.Hour)
+	userCore := user.NewCore(log, delegate.New(log), usermem.NewStore())
+
+	app := mux.WebAPI(mux.Config{
+		Log:         log,
+		AuthClient:  authclient.New(authSvc.URL, func(context.Context, string, ...any) {}),
+		Usage:       usageCore,
+		UserCore:    userCore,
+		ProductCore: product.NewCore(log, productmem.NewStore(), userCore),
+		Subsystems:  subsystem.New(),
+	}, all.Routes())
+
+	call := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Authorization", "Bearer token")
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Should get a 200 from %s, got %d: %s", path, w.Code, w.Body)
+		}
+
+		return w
+	}
+
+	call("/v1/products")
+
+	if err := usageCore.Flush(ctx); err != nil {
+		t.Fatalf("Should be able to flush the usage : %s", err)
+	}
+
+	var consumers []struct {
+		Subject  string `json:"subject"`
+		Route    string `json:"route"`
+		Requests int64  `json:"requests"`
+	}
+	if err := json.Unmarshal(call("/admin/usage").Body.Bytes(), &consumers); err != nil {
+		t.Fatalf("Should be able to decode the report : %s", err)
+	}
+
+	var found bool
+	for _, c := range consumers {
+		if c.Subject == subject.String() && strings.HasSuffix(c.Route, "/v1/products") {
+			found = c.Requests == 1
+		}
+	}
+
+	if !found {
+		t.Errorf("Should report the request to the products, got %+v", consumers)
+	}
+}
@@ -5,21 +5,73 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
+	"github.com/google/uuid"
 	"github.com/mrcruz117/al-service/api/cmd/services/sales/build/all"
 	"github.com/mrcruz117/al-service/api/http/api/debug"
+	"github.com/mrcruz117/al-service/api/http/api/mid"
 	"github.com/mrcruz117/al-service/api/http/api/mux"
+	"github.com/mrcruz117/al-service/api/http/api/routecfg"
+	"github.com/mrcruz117/al-service/app/api/adaptive"
+	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+	"github.com/mrcruz117/al-service/app/api/metrics"
+	"github.com/mrcruz117/al-service/app/api/posture"
+	"github.com/mrcruz117/al-service/business/api/archive"
+	"github.com/mrcruz117/al-service/business/api/delegate"
 	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/business/core/apikey/stores/apikeydb"
+	"github.com/mrcruz117/al-service/business/core/audit"
+	"github.com/mrcruz117/al-service/business/core/audit/stores/auditdb"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/business/core/entityaudit/stores/entityauditdb"
+	"github.com/mrcruz117/al-service/business/core/event"
+	"github.com/mrcruz117/al-service/business/core/event/stores/eventdb"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/order/stores/orderaudit"
+	"github.com/mrcruz117/al-service/business/core/order/stores/orderdb"
+	"github.com/mrcruz117/al-service/business/core/order/stores/orderoutbox"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+	"github.com/mrcruz117/al-service/business/core/outbox/stores/outboxdb"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/product/stores/productaudit"
+	"github.com/mrcruz117/al-service/business/core/product/stores/productdb"
+	"github.com/mrcruz117/al-service/business/core/registry"
+	"github.com/mrcruz117/al-service/business/core/registry/stores/registrydb"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/tenant/stores/tenantdb"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/business/core/usage/stores/usagedb"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/useraudit"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usercache"
+	"github.com/mrcruz117/al-service/business/core/user/stores/userdb"
+	"github.com/mrcruz117/al-service/business/core/user/stores/useroutbox"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct/stores/vproductdb"
+	"github.com/mrcruz117/al-service/foundation/cachestore"
+	"github.com/mrcruz117/al-service/foundation/events"
+	"github.com/mrcruz117/al-service/foundation/events/kafka"
+	"github.com/mrcruz117/al-service/foundation/events/nats"
+	"github.com/mrcruz117/al-service/foundation/feed"
 	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/queuemon"
+	"github.com/mrcruz117/al-service/foundation/reqsign"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+	"github.com/mrcruz117/al-service/foundation/warmup"
 	"github.com/mrcruz117/al-service/foundation/web"
+	"github.com/mrcruz117/al-service/foundation/worker"
 )
 
 var build = "develop"
@@ -28,6 +80,9 @@ func main() {
 	var log *logger.Logger
 
 	events := logger.Events{
+		Warn: logger.Throttle(20, time.Minute, func(ctx context.Context, r logger.Record) {
+			log.Info(ctx, "******* SEND ALERT *******", "reason", "warning burst", "msg", r.Message)
+		}),
 		Error: func(ctx context.Context, r logger.Record) {
 			log.Info(ctx, "******* SEND ALERT *******")
 		},
@@ -61,26 +116,148 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	cfg := struct {
 		conf.Version
+		Registry struct {
+			Interval   time.Duration `conf:"default:15s,help:how often this instance reports itself to the service registry"`
+			StaleAfter time.Duration `conf:"default:1m,help:how long an instance is listed after its last report"`
+		}
+		Log struct {
+			Format       string        `conf:"default:json,help:json for production or console for reading logs in a terminal"`
+			Level        string        `conf:"default:info,help:minimum level logged by packages without a level of their own"`
+			Levels       string        `conf:"help:per package minimum levels such as business/core/user=debug,foundation/web=info"`
+			File         string        `conf:"help:file logs are written to instead of stdout"`
+			MaxSize      int64         `conf:"default:104857600,help:bytes the log file grows to before it's rotated"`
+			MaxAge       time.Duration `conf:"default:168h,help:how long rotated log files are kept"`
+			Compress     bool          `conf:"default:true"`
+			AsyncBuffer  int           `conf:"default:0,help:logs buffered and written in the background, 0 writes them synchronously"`
+			AsyncPolicy  string        `conf:"default:block,help:block or drop logs when the buffer is full"`
+			SampleWindow time.Duration `conf:"default:1s"`
+			SampleDebug  int           `conf:"default:0,help:log 1 of every N identical debug messages per window"`
+			SampleInfo   int           `conf:"default:0,help:log 1 of every N identical info messages per window"`
+			SampleWarn   int           `conf:"default:0,help:log 1 of every N identical warn messages per window"`
+		}
 		Web struct {
 			ReadTimeout        time.Duration `conf:"default:5s"`
 			WriteTimeout       time.Duration `conf:"default:10s"`
 			IdleTimeout        time.Duration `conf:"default:120s"`
+			ReadHeaderTimeout  time.Duration `conf:"default:5s,help:time allowed to read the request headers, which bounds slow clients holding connections"`
+			MaxHeaderBytes     int           `conf:"default:1048576,help:largest request headers accepted"`
 			ShutdownTimeout    time.Duration `conf:"default:20s"`
+			DrainPeriod        time.Duration `conf:"default:20s,help:how long readiness fails before the server stops accepting connections"`
 			APIHost            string        `conf:"default:0.0.0.0:3000"`
 			DebugHost          string        `conf:"default:0.0.0.0:3010"`
+			AdminHost          string        `conf:"default:0.0.0.0:3020"`
+			WarmupRetry        time.Duration `conf:"default:2s"`
+			MaxInflight        int           `conf:"default:500"`
 			CORSAllowedOrigins []string      `conf:"default:*,mask"`
+			Experimental       []string      `conf:"help:capabilities whose experimental routes are enabled"`
+			PostureChanges     int           `conf:"default:50,help:number of runtime setting changes reported by the posture endpoint"`
+		}
+		Maintenance struct {
+			Enabled    bool          `conf:"default:false"`
+			RetryAfter time.Duration `conf:"default:60s"`
+		}
+		Usage struct {
+			Window        time.Duration `conf:"default:1h"`
+			FlushInterval time.Duration `conf:"default:30s"`
+		}
+		Audit struct {
+			BatchSize     int           `conf:"default:100"`
+			MaxPending    int           `conf:"default:10000"`
+			FlushInterval time.Duration `conf:"default:5s"`
+		}
+		Events struct {
+			BufferSize int           `conf:"default:1000,help:number of recent events retained for clients to catch up on"`
+			MaxWait    time.Duration `conf:"default:25s,help:longest a poll is held open waiting for events"`
+			MaxEvents  int           `conf:"default:100,help:most events returned to a client per poll"`
+			MaxPolls   int           `conf:"default:1000,help:polls held open at once, apart from the web max inflight"`
+		}
+		Broker struct {
+			Kind     string        `conf:"default:none,help:none, log, nats or kafka, where the domain events are published"`
+			URL      string        `conf:"help:nats://host:4222 for nats or the url of the REST proxy for kafka"`
+			Prefix   string        `conf:"default:sales.,help:prefix of the subject or topic of each event type"`
+			User     string        `conf:"help:user the broker is authenticated to as"`
+			Password string        `conf:"mask"`
+			Timeout  time.Duration `conf:"default:5s,help:how long publishing an event can take"`
+		}
+		Jobs struct {
+			Workers   int           `conf:"default:4,help:background jobs run at once"`
+			QueueSize int           `conf:"default:1000,help:background jobs waiting for a worker before more are refused"`
+			Timeout   time.Duration `conf:"default:1m,help:how long a background job can run"`
+		}
+		Outbox struct {
+			Interval  time.Duration `conf:"default:1s,help:how often the events waiting in the outbox are published"`
+			BatchSize int           `conf:"default:100,help:events published per transaction"`
+			Retention time.Duration `conf:"default:24h,help:how long published events are kept in the outbox"`
+		}
+		Archive struct {
+			OrderAge  time.Duration `conf:"default:8760h,help:age orders are moved to the archive at, 0 disables archiving"`
+			Interval  time.Duration `conf:"default:1h,help:how often old rows are moved to the archive"`
+			BatchSize int           `conf:"default:1000,help:rows moved per statement"`
+		}
+		Queue struct {
+			CheckInterval  time.Duration `conf:"default:15s"`
+			UsageMaxDepth  int64         `conf:"default:10000"`
+			UsageMaxLag    time.Duration `conf:"default:5m"`
+			UsageMaxAge    time.Duration `conf:"default:5m"`
+			AuditMaxDepth  int64         `conf:"default:5000"`
+			AuditMaxAge    time.Duration `conf:"default:1m"`
+			OutboxMaxDepth int64         `conf:"default:10000"`
+			OutboxMaxAge   time.Duration `conf:"default:5m,help:longest an event can wait to be published, such as while the broker is down"`
+			JobsMaxDepth   int64         `conf:"default:800"`
+		}
+		TLS struct {
+			CertFile     string `conf:"help:enables TLS on the api when set"`
+			KeyFile      string
+			ClientCAFile string   `conf:"help:CA bundle used to verify client certificates"`
+			Identities   []string `conf:"help:identity=ROLE|ROLE pairs mapping client certificates to roles"`
+		}
+		Webhook struct {
+			Secrets []string      `conf:"mask,help:partner:secret pairs used to verify inbound webhooks"`
+			MaxAge  time.Duration `conf:"default:5m,help:how far from now the signature timestamp of a webhook can be"`
+		}
+		Routes struct {
+			ConfigFile        string        `conf:"help:yaml file mapping route groups to their middleware"`
+			TimeoutPercentile float64       `conf:"default:0.99,help:latency percentile adaptive timeouts are derived from"`
+			TimeoutFactor     float64       `conf:"default:3,help:multiplier applied to the latency percentile"`
+			TimeoutMin        time.Duration `conf:"default:250ms"`
+			TimeoutSamples    int           `conf:"default:1000,help:recent latencies kept per route"`
+			TimeoutMinSamples int           `conf:"default:100,help:latencies needed before a route is tuned"`
+			TimeoutRecompute  time.Duration `conf:"default:30s"`
+		}
+		Cache struct {
+			Addrs      []string `conf:"help:redis addresses, shared rate limits are disabled when empty"`
+			MasterName string   `conf:"help:sentinel master name for failover"`
+			Password   string   `conf:"mask"`
+			DB         int      `conf:"default:0"`
+			PoolSize   int      `conf:"default:10"`
+		}
+		UserCache struct {
+			Size int           `conf:"default:10000,help:users kept in process"`
+			TTL  time.Duration `conf:"default:1m,help:how long a looked up user is cached, 0 reads the database for every lookup"`
 		}
 		Auth struct {
-			Host string `conf:"default:http://auth-service.sales-system.svc.cluster.local:6000"`
+			Host            string        `conf:"default:http://auth-service.sales-system.svc.cluster.local:6000"`
+			SigningIdentity string        `conf:"default:sales,help:name this service signs its calls to the auth service with"`
+			SigningSecret   string        `conf:"mask,help:secret shared with the auth service, calls are unsigned when empty"`
+			Issuer          string        `conf:"default:service project"`
+			JWKSRefresh     time.Duration `conf:"default:5m,help:how long the auth service's public keys are cached, 0 calls the auth service for every token"`
+			Timeout         time.Duration `conf:"default:2s,help:how long each call to the auth service can take"`
+			Retries         int           `conf:"default:3,help:how many times a call to the auth service is made when it can't be reached or fails with a 5xx"`
+			RetryBackoff    time.Duration `conf:"default:100ms,help:the longest wait before the first retry, doubled for each one after it"`
+			BreakerFailures int           `conf:"default:5,help:how many calls in a row can fail to reach the auth service before calls to it are stopped"`
+			BreakerCooldown time.Duration `conf:"default:10s,help:how long calls to the auth service are stopped for before one is let through to try it again"`
 		}
 		DB struct {
-			User         string `conf:"default:postgres"`
-			Password     string `conf:"default:postgres,mask"`
-			HostPort     string `conf:"default:database-service.sales-system.svc.cluster.local"`
-			Name         string `conf:"default:postgres"`
-			MaxIdleConns int    `conf:"default:2"`
-			MaxOpenConns int    `conf:"default:0"`
-			DisableTLS   bool   `conf:"default:true"`
+			User            string        `conf:"default:postgres"`
+			Password        string        `conf:"default:postgres,mask"`
+			HostPort        string        `conf:"default:database-service.sales-system.svc.cluster.local"`
+			Name            string        `conf:"default:postgres"`
+			MaxIdleConns    int           `conf:"default:2"`
+			MaxOpenConns    int           `conf:"default:0"`
+			ConnMaxLifetime time.Duration `conf:"default:30m,help:how long a connection is kept before it is replaced or 0 to keep it"`
+			ConnMaxIdleTime time.Duration `conf:"default:5m,help:how long an idle connection is kept before it is closed or 0 to keep it"`
+			DisableTLS      bool          `conf:"default:true"`
+			Dialect         string        `conf:"default:postgres"`
 		}
 	}{
 		Version: conf.Version{
@@ -99,6 +276,78 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("parsing config: %w", err)
 	}
 
+	var logOut io.Writer = os.Stdout
+
+	if cfg.Log.File != "" {
+		file, err := logger.NewRotatingFile(cfg.Log.File, logger.RotateConfig{
+			MaxSize:  cfg.Log.MaxSize,
+			MaxAge:   cfg.Log.MaxAge,
+			Compress: cfg.Log.Compress,
+		})
+		if err != nil {
+			return fmt.Errorf("opening log file: %w", err)
+		}
+		defer file.Close()
+
+		logOut = file
+	}
+
+	if cfg.Log.AsyncBuffer > 0 {
+		policy, err := logger.ParsePolicy(cfg.Log.AsyncPolicy)
+		if err != nil {
+			return fmt.Errorf("parsing log policy: %w", err)
+		}
+
+		async := logger.NewAsyncWriter(logOut, logger.AsyncConfig{
+			Size:   cfg.Log.AsyncBuffer,
+			Policy: policy,
+		})
+		defer func() {
+			async.Close()
+			if n := async.Dropped(); n > 0 {
+				log.Warn(ctx, "shutdown", "status", "logs dropped while the buffer was full", "dropped", n)
+			}
+		}()
+
+		logOut = async
+	}
+
+	if logOut != os.Stdout {
+		log.SetOutput(logOut)
+
+		// Startup errors reported by main after run returns still need
+		// somewhere to go, and the buffered logs need to be written first.
+		defer log.SetOutput(os.Stdout)
+	}
+
+	format, err := logger.ParseFormat(cfg.Log.Format)
+	if err != nil {
+		return fmt.Errorf("parsing log format: %w", err)
+	}
+	log.SetFormat(format)
+
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return fmt.Errorf("parsing log level: %w", err)
+	}
+	log.SetLevel(level)
+
+	levels, err := logger.ParseLevels(cfg.Log.Levels)
+	if err != nil {
+		return fmt.Errorf("parsing log levels: %w", err)
+	}
+	log.SetLevels(levels)
+
+	log.SetCounter(func(service string, level logger.Level) {
+		metrics.AddLogs(service, level.String())
+	})
+
+	log.SetSampling(logger.Sampling{
+		logger.LevelDebug: {Every: cfg.Log.SampleDebug, Window: cfg.Log.SampleWindow},
+		logger.LevelInfo:  {Every: cfg.Log.SampleInfo, Window: cfg.Log.SampleWindow},
+		logger.LevelWarn:  {Every: cfg.Log.SampleWarn, Window: cfg.Log.SampleWindow},
+	})
+
 	// -------------------------------------------------------------------------
 	// App Starting
 
@@ -119,13 +368,16 @@ func run(ctx context.Context, log *logger.Logger) error {
 	log.Info(ctx, "startup", "status", "initializing database support", "hostport", cfg.DB.HostPort)
 
 	db, err := sqldb.Open(sqldb.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		HostPort:     cfg.DB.HostPort,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenConns,
-		DisableTLS:   cfg.DB.DisableTLS,
+		User:            cfg.DB.User,
+		Password:        cfg.DB.Password,
+		HostPort:        cfg.DB.HostPort,
+		Name:            cfg.DB.Name,
+		MaxIdleConns:    cfg.DB.MaxIdleConns,
+		MaxOpenConns:    cfg.DB.MaxOpenConns,
+		ConnMaxLifetime: cfg.DB.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DB.ConnMaxIdleTime,
+		DisableTLS:      cfg.DB.DisableTLS,
+		Dialect:         cfg.DB.Dialect,
 	})
 	if err != nil {
 		return fmt.Errorf("connecting to db: %w", err)
@@ -141,16 +393,401 @@ func run(ctx context.Context, log *logger.Logger) error {
 	logFunc := func(ctx context.Context, msg string, v ...any) {
 		log.Info(ctx, msg, v...)
 	}
-	authClient := authclient.New(cfg.Auth.Host, logFunc)
+	authOpts := []func(cln *authclient.Client){
+		authclient.WithTimeout(cfg.Auth.Timeout),
+		authclient.WithRetries(cfg.Auth.Retries, cfg.Auth.RetryBackoff),
+		authclient.WithBreaker(cfg.Auth.BreakerFailures, cfg.Auth.BreakerCooldown),
+	}
+	if cfg.Auth.SigningSecret != "" {
+		authOpts = append(authOpts, authclient.WithSigner(reqsign.NewSigner(cfg.Auth.SigningIdentity, []byte(cfg.Auth.SigningSecret))))
+	}
+	if cfg.Auth.JWKSRefresh > 0 {
+		authOpts = append(authOpts, authclient.WithLocalAuthentication(cfg.Auth.Issuer, cfg.Auth.JWKSRefresh))
+	}
 
 	// -------------------------------------------------------------------------
-	// Start Debug Service
+	// Initialize webhook support
 
-	go func() {
-		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
+	webhookSecrets := make(map[string][]byte, len(cfg.Webhook.Secrets))
+	for _, pair := range cfg.Webhook.Secrets {
+		partner, secret, ok := strings.Cut(pair, ":")
+		if !ok || partner == "" || secret == "" {
+			return fmt.Errorf("webhook secret must be in the form partner:secret")
+		}
+		webhookSecrets[partner] = []byte(secret)
+	}
 
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
-			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
+	// -------------------------------------------------------------------------
+	// Initialize client certificate support
+
+	peers := make(map[string][]string, len(cfg.TLS.Identities))
+	for _, binding := range cfg.TLS.Identities {
+		identity, roles, ok := strings.Cut(binding, "=")
+		if !ok || identity == "" || roles == "" {
+			return fmt.Errorf("invalid tls identity %q, expecting identity=ROLE|ROLE", binding)
+		}
+		peers[identity] = strings.Split(roles, "|")
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize subsystem support
+
+	log.Info(ctx, "startup", "status", "initializing subsystem support")
+
+	subsystems := subsystem.New()
+
+	// -------------------------------------------------------------------------
+	// Initialize usage accounting support
+
+	log.Info(ctx, "startup", "status", "initializing usage accounting support")
+
+	usageCore := usage.NewCore(log, usagedb.NewStore(log, db), cfg.Usage.Window)
+
+	workerCtx, workerCancel := context.WithCancel(ctx)
+	defer workerCancel()
+
+	go usageCore.Run(workerCtx, cfg.Usage.FlushInterval, subsystems.Register("usage"))
+
+	// -------------------------------------------------------------------------
+	// Initialize audit support
+
+	log.Info(ctx, "startup", "status", "initializing audit support")
+
+	auditCore := audit.NewCore(log, auditdb.NewStore(log, db), cfg.Audit.BatchSize, cfg.Audit.MaxPending)
+
+	go auditCore.Run(workerCtx, cfg.Audit.FlushInterval, subsystems.Register("audit"))
+
+	// -------------------------------------------------------------------------
+	// Initialize api key support
+
+	log.Info(ctx, "startup", "status", "initializing api key support")
+
+	apiKeyCore := apikey.NewCore(log, apikeydb.NewStore(log, db))
+
+	// -------------------------------------------------------------------------
+	// Cache Support
+
+	log.Info(ctx, "startup", "status", "initializing cache support")
+
+	var cache *cachestore.Store
+	if len(cfg.Cache.Addrs) > 0 {
+		cache, err = cachestore.Open(log, cachestore.Config{
+			Addrs:      cfg.Cache.Addrs,
+			MasterName: cfg.Cache.MasterName,
+			Password:   cfg.Cache.Password,
+			DB:         cfg.Cache.DB,
+			PoolSize:   cfg.Cache.PoolSize,
+		})
+		if err != nil {
+			return fmt.Errorf("connecting to cache: %w", err)
+		}
+		defer cache.Close()
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize event publishing support
+
+	log.Info(ctx, "startup", "status", "initializing event publishing support", "kind", cfg.Broker.Kind)
+
+	var publisher events.Publisher
+	switch cfg.Broker.Kind {
+	case "none":
+		publisher = events.Discard
+
+	case "log":
+		publisher = events.Log(logFunc)
+
+	case "nats":
+		publisher, err = nats.New(nats.Config{
+			URL:           cfg.Broker.URL,
+			Name:          "sales",
+			User:          cfg.Broker.User,
+			Password:      cfg.Broker.Password,
+			SubjectPrefix: cfg.Broker.Prefix,
+			Timeout:       cfg.Broker.Timeout,
+		})
+
+	case "kafka":
+		publisher, err = kafka.New(kafka.Config{
+			URL:         cfg.Broker.URL,
+			User:        cfg.Broker.User,
+			Password:    cfg.Broker.Password,
+			TopicPrefix: cfg.Broker.Prefix,
+			Timeout:     cfg.Broker.Timeout,
+		})
+
+	default:
+		err = fmt.Errorf("unknown kind %q", cfg.Broker.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("constructing event publisher: %w", err)
+	}
+
+	publisher = events.Metered(publisher)
+	defer publisher.Close()
+
+	// -------------------------------------------------------------------------
+	// Initialize background job support
+
+	log.Info(ctx, "startup", "status", "initializing background job support", "workers", cfg.Jobs.Workers)
+
+	jobs := worker.New(log, "jobs", worker.Config{
+		Workers:   cfg.Jobs.Workers,
+		QueueSize: cfg.Jobs.QueueSize,
+		Timeout:   cfg.Jobs.Timeout,
+	})
+
+	// -------------------------------------------------------------------------
+	// Create Business Packages
+
+	dlg := delegate.New(log)
+	beginner := sqldb.NewBeginner(db)
+
+	entityAuditCore := entityaudit.NewCore(log, entityauditdb.NewStore(log, db))
+	outboxCore := outbox.NewCore(log, outboxdb.NewStore(log, db), beginner, publisher, "sales")
+
+	var userStore user.Storer = useroutbox.NewStore(useraudit.NewStore(userdb.NewStore(log, db), entityAuditCore), outboxCore, beginner)
+	if cfg.UserCache.TTL > 0 {
+		cached := usercache.NewStore(log, userStore, usercache.Config{
+			Size:  cfg.UserCache.Size,
+			TTL:   cfg.UserCache.TTL,
+			Cache: cache,
+		})
+
+		go cached.Run(workerCtx)
+
+		userStore = cached
+	}
+
+	userCore := user.NewCore(log, dlg, userStore)
+	productCore := product.NewCore(log, productaudit.NewStore(productdb.NewStore(log, db), entityAuditCore), userCore)
+	vproductCore := vproduct.NewCore(log, vproductdb.NewStore(log, db))
+	orderCore := order.NewCore(log, dlg, jobs, orderoutbox.NewStore(orderaudit.NewStore(orderdb.NewStore(log, db), entityAuditCore), outboxCore, beginner), userCore, productCore)
+
+	go outboxCore.Run(workerCtx, cfg.Outbox.Interval, cfg.Outbox.BatchSize, cfg.Outbox.Retention, subsystems.Register("outbox"))
+
+	// -------------------------------------------------------------------------
+	// Initialize archive support
+
+	// Archiving relies on the partitioning of Postgres, on MySQL the orders
+	// stay in the hot tables.
+	switch {
+	case cfg.Archive.OrderAge <= 0:
+		log.Info(ctx, "startup", "status", "archiving disabled")
+
+	case sqldb.DialectOf(db) != sqldb.Postgres:
+		log.Info(ctx, "startup", "status", "archiving not supported", "dialect", sqldb.DialectOf(db))
+
+	default:
+		log.Info(ctx, "startup", "status", "initializing archive support", "orderAge", cfg.Archive.OrderAge)
+
+		archiver, err := archive.New(log, db, cfg.Archive.BatchSize, archive.Table{
+			Name:       "orders",
+			TimeColumn: "date_created",
+			MaxAge:     cfg.Archive.OrderAge,
+			Children:   []archive.Child{{Name: "order_items", Key: "order_id"}},
+		})
+		if err != nil {
+			return fmt.Errorf("constructing archiver: %w", err)
+		}
+
+		go archiver.Run(workerCtx, cfg.Archive.Interval, subsystems.Register("archive"))
+	}
+
+	// The tokens verified locally are checked against the users, so the
+	// client is constructed once they can be queried.
+	authOpts = append(authOpts, authclient.WithUserCheck(userCore))
+	authClient := authclient.New(cfg.Auth.Host, logFunc, authOpts...)
+
+	// -------------------------------------------------------------------------
+	// Initialize tenant support
+
+	log.Info(ctx, "startup", "status", "initializing tenant support")
+
+	tenantCore := tenant.NewCore(log, tenantdb.NewStore(log, db))
+	eventCore := event.NewCore(log, eventdb.NewStore(log, db))
+
+	// -------------------------------------------------------------------------
+	// Initialize event feed support
+
+	log.Info(ctx, "startup", "status", "initializing event feed support")
+
+	eventFeed := feed.New(cfg.Events.BufferSize)
+
+	// -------------------------------------------------------------------------
+	// Initialize queue monitoring support
+
+	log.Info(ctx, "startup", "status", "initializing queue monitoring support")
+
+	queueMon := queuemon.New(log)
+
+	queueMon.Register("usage", usageCore.Stats, queuemon.Thresholds{
+		Depth:     cfg.Queue.UsageMaxDepth,
+		Lag:       cfg.Queue.UsageMaxLag,
+		OldestAge: cfg.Queue.UsageMaxAge,
+	})
+
+	queueMon.Register("audit", auditCore.Stats, queuemon.Thresholds{
+		Depth:     cfg.Queue.AuditMaxDepth,
+		OldestAge: cfg.Queue.AuditMaxAge,
+	})
+
+	queueMon.Register("outbox", outboxCore.Stats, queuemon.Thresholds{
+		Depth:     cfg.Queue.OutboxMaxDepth,
+		OldestAge: cfg.Queue.OutboxMaxAge,
+	})
+
+	queueMon.Register("jobs", jobs.Stats, queuemon.Thresholds{
+		Depth: cfg.Queue.JobsMaxDepth,
+	})
+
+	go queueMon.Run(workerCtx, cfg.Queue.CheckInterval)
+
+	// -------------------------------------------------------------------------
+	// Initialize route middleware support
+
+	log.Info(ctx, "startup", "status", "initializing route middleware support")
+
+	routeCfg, err := routecfg.Load(cfg.Routes.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("loading route config: %w", err)
+	}
+
+	tuner, err := adaptive.New(adaptive.Config{
+		Percentile: cfg.Routes.TimeoutPercentile,
+		Factor:     cfg.Routes.TimeoutFactor,
+		Min:        cfg.Routes.TimeoutMin,
+		Samples:    cfg.Routes.TimeoutSamples,
+		MinSamples: cfg.Routes.TimeoutMinSamples,
+	})
+	if err != nil {
+		return fmt.Errorf("constructing adaptive timeouts: %w", err)
+	}
+
+	go tuner.Run(workerCtx, cfg.Routes.TimeoutRecompute)
+
+	routeMW := routecfg.Middleware(routeCfg, routecfg.Options{
+		Authorize: func(rule string) web.MidHandler {
+			return mid.Authorize(log, authClient, rule)
+		},
+		Cache: cache,
+		Tuner: tuner,
+	})
+
+	// -------------------------------------------------------------------------
+	// Initialize warmup support
+
+	log.Info(ctx, "startup", "status", "initializing warmup support")
+
+	wu := warmup.New(logFunc, cfg.Web.WarmupRetry)
+
+	wu.Add("database", func(ctx context.Context) error {
+		return sqldb.Prime(ctx, db, cfg.DB.MaxIdleConns)
+	})
+
+	wu.Add("authclient", authClient.Ping)
+
+	if cache != nil {
+		wu.Add("cache", cache.StatusCheck)
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize posture support
+
+	log.Info(ctx, "startup", "status", "initializing posture support")
+
+	maint := maintenance.New(cfg.Maintenance.Enabled, cfg.Maintenance.RetryAfter, "/admin/")
+
+	pst := posture.New(cfg.Web.PostureChanges)
+
+	pst.Register("maintenance", func(ctx context.Context) any {
+		return map[string]any{
+			"enabled":    maint.Enabled(),
+			"retryAfter": maint.RetryAfter().String(),
+		}
+	})
+
+	pst.Register("experimental", func(ctx context.Context) any {
+		return cfg.Web.Experimental
+	})
+
+	pst.Register("rateLimitTiers", func(ctx context.Context) any {
+		tiers := make(map[string]string, len(routeCfg.Tiers))
+		for name, t := range routeCfg.Tiers {
+			tiers[name] = fmt.Sprintf("%d/%s", t.Requests, t.Window)
+		}
+		return tiers
+	})
+
+	pst.Register("policy", func(ctx context.Context) any {
+		return map[string]string{"revision": auth.PolicyRevision()}
+	})
+
+	pst.Register("logLevel", func(ctx context.Context) any {
+		return log.Level().String()
+	})
+
+	pst.Register("logLevels", func(ctx context.Context) any {
+		return log.Levels().String()
+	})
+
+	pst.Register("subsystems", func(ctx context.Context) any {
+		paused := make(map[string]bool)
+		for _, st := range subsystems.States() {
+			paused[st.Name] = st.Paused
+		}
+		return paused
+	})
+
+	// -------------------------------------------------------------------------
+	// Start Registry Reporting
+
+	log.Info(ctx, "startup", "status", "initializing registry support")
+
+	registryCore := registry.NewCore(log, registrydb.NewStore(log, db))
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unavailable"
+	}
+
+	instance := registry.Instance{
+		ID:          uuid.New(),
+		Service:     "sales",
+		Build:       build,
+		Host:        host,
+		Address:     cfg.Web.APIHost,
+		DateStarted: time.Now(),
+	}
+
+	go registryCore.Run(workerCtx, instance, cfg.Registry.Interval, func(ctx context.Context) bool {
+		return wu.Ready() && sqldb.StatusCheck(ctx, db) == nil
+	})
+
+	// -------------------------------------------------------------------------
+	// Start Log Level Toggle
+
+	// SIGHUP flips between info and debug logging so a production problem
+	// can be diagnosed without a redeploy.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+				level := logger.LevelDebug
+				if log.Level() == logger.LevelDebug {
+					level = logger.LevelInfo
+				}
+
+				log.SetLevel(level)
+				log.Info(ctx, "sighup", "status", "log level changed", "level", level.String())
+
+			case <-workerCtx.Done():
+				signal.Stop(hup)
+				return
+			}
 		}
 	}()
 
@@ -163,19 +800,58 @@ func run(ctx context.Context, log *logger.Logger) error {
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
 	cfgMux := mux.Config{
-		Build:      build,
-		Log:        log,
-		AuthClient: authClient,
-		DB:         db,
+		Name:            cfg.Desc,
+		Build:           build,
+		Log:             log,
+		AuthClient:      authClient,
+		DB:              db,
+		Warmup:          wu,
+		Maintenance:     maint,
+		Usage:           usageCore,
+		Audit:           auditCore,
+		APIKey:          apiKeyCore,
+		Tenant:          tenantCore,
+		UserCore:        userCore,
+		ProductCore:     productCore,
+		VProductCore:    vproductCore,
+		OrderCore:       orderCore,
+		EntityAuditCore: entityAuditCore,
+		Feed:            eventFeed,
+		Events:          eventCore,
+		Registry:        registryCore,
+		StaleAfter:      cfg.Registry.StaleAfter,
+		Posture:         pst,
+		Subsystems:      subsystems,
+		PollMaxWait:     cfg.Events.MaxWait,
+		PollMaxEvents:   cfg.Events.MaxEvents,
+		PollMaxPolls:    cfg.Events.MaxPolls,
+		MaxInflight:     cfg.Web.MaxInflight,
+		Experimental:    cfg.Web.Experimental,
+		Webhooks:        webhookSecrets,
+		WebhookMaxAge:   cfg.Webhook.MaxAge,
+		Peers:           peers,
+		RouteMiddleware: routeMW,
 	}
 
+	webAPI := mux.WebAPI(cfgMux, all.Routes())
+
 	api := http.Server{
-		Addr:         cfg.Web.APIHost,
-		Handler:      mux.WebAPI(cfgMux, all.Routes()),
-		ReadTimeout:  cfg.Web.ReadTimeout,
-		WriteTimeout: cfg.Web.WriteTimeout,
-		IdleTimeout:  cfg.Web.IdleTimeout,
-		ErrorLog:     logger.NewStdLogger(log, logger.LevelError),
+		Addr:              cfg.Web.APIHost,
+		Handler:           webAPI,
+		ReadTimeout:       cfg.Web.ReadTimeout,
+		WriteTimeout:      cfg.Web.WriteTimeout,
+		IdleTimeout:       cfg.Web.IdleTimeout,
+		ReadHeaderTimeout: cfg.Web.ReadHeaderTimeout,
+		MaxHeaderBytes:    cfg.Web.MaxHeaderBytes,
+		ErrorLog:          logger.NewStdLogger(log, logger.LevelError),
+	}
+
+	if cfg.TLS.CertFile != "" {
+		tlsCfg, err := web.ServerTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("configuring tls: %w", err)
+		}
+		api.TLSConfig = tlsCfg
 	}
 
 	serverErrors := make(chan error, 1)
@@ -183,9 +859,77 @@ func run(ctx context.Context, log *logger.Logger) error {
 	go func() {
 		log.Info(ctx, "startup", "status", "api router started", "host", api.Addr)
 
+		if api.TLSConfig != nil {
+			serverErrors <- api.ListenAndServeTLS("", "")
+			return
+		}
+
 		serverErrors <- api.ListenAndServe()
 	}()
 
+	// -------------------------------------------------------------------------
+	// Start Debug Service
+
+	// The debug service is started once the api routes are bound so it can
+	// serve their documentation. Profiles and traces stream for as long as
+	// they are asked to, so only the headers are bounded.
+	dbg := http.Server{
+		Addr:              cfg.Web.DebugHost,
+		Handler:           debug.Mux(cfg.Build, mux.OpenAPI(webAPI, cfg.Desc, cfg.Build).Handler()),
+		ReadHeaderTimeout: cfg.Web.ReadHeaderTimeout,
+		MaxHeaderBytes:    cfg.Web.MaxHeaderBytes,
+		ErrorLog:          logger.NewStdLogger(log, logger.LevelError),
+	}
+
+	go func() {
+		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
+
+		if err := dbg.ListenAndServe(); err != nil {
+			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
+		}
+	}()
+
+	// -------------------------------------------------------------------------
+	// Start Admin Service
+
+	// The admin pages are served on an internal port and must remain
+	// reachable while the service is in maintenance mode.
+	cfgAdminMux := cfgMux
+	cfgAdminMux.Maintenance = nil
+
+	admin := http.Server{
+		Addr:              cfg.Web.AdminHost,
+		Handler:           mux.WebAPI(cfgAdminMux, all.AdminRoutes()),
+		ReadTimeout:       cfg.Web.ReadTimeout,
+		WriteTimeout:      cfg.Web.WriteTimeout,
+		IdleTimeout:       cfg.Web.IdleTimeout,
+		ReadHeaderTimeout: cfg.Web.ReadHeaderTimeout,
+		MaxHeaderBytes:    cfg.Web.MaxHeaderBytes,
+		ErrorLog:          logger.NewStdLogger(log, logger.LevelError),
+	}
+
+	go func() {
+		log.Info(ctx, "startup", "status", "admin router started", "host", admin.Addr)
+
+		if err := admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error(ctx, "shutdown", "status", "admin router closed", "host", admin.Addr, "msg", err)
+		}
+	}()
+
+	// The readiness endpoint will not report ready until all the warmup
+	// hooks have completed.
+	warmupCtx, warmupCancel := context.WithCancel(ctx)
+	defer warmupCancel()
+
+	go func() {
+		if err := wu.Run(warmupCtx); err != nil {
+			log.Error(ctx, "warmup", "status", "warmup incomplete", "msg", err)
+			return
+		}
+
+		log.Info(ctx, "warmup", "status", "warmup complete")
+	}()
+
 	// -------------------------------------------------------------------------
 	// Shutdown
 
@@ -197,13 +941,45 @@ func run(ctx context.Context, log *logger.Logger) error {
 		log.Info(ctx, "shutdown", "status", "shutdown started", "signal", sig)
 		defer log.Info(ctx, "shutdown", "status", "shutdown complete", "signal", sig)
 
+		// Readiness fails for the drain period while the server keeps taking
+		// requests, which gives the probes time to take the instance out of
+		// the load balancer before connections are refused. A second signal
+		// cuts the period short.
+		wu.Drain()
+		log.Info(ctx, "shutdown", "status", "draining", "period", cfg.Web.DrainPeriod)
+
+		select {
+		case <-time.After(cfg.Web.DrainPeriod):
+		case sig := <-shutdown:
+			log.Info(ctx, "shutdown", "status", "drain cut short", "signal", sig)
+		}
+
 		ctx, cancel := context.WithTimeout(ctx, cfg.Web.ShutdownTimeout)
 		defer cancel()
 
+		if err := admin.Shutdown(ctx); err != nil {
+			admin.Close()
+		}
+
 		if err := api.Shutdown(ctx); err != nil {
 			api.Close()
 			return fmt.Errorf("could not stop server gracefully: %w", err)
 		}
+
+		// The requests are done, so no more jobs are submitted and the ones
+		// queued can finish before the database is closed.
+		if err := jobs.Shutdown(ctx); err != nil {
+			log.Error(ctx, "shutdown", "status", "draining background jobs", "msg", err)
+		}
+
+		workerCancel()
+		if err := usageCore.Flush(ctx); err != nil {
+			log.Error(ctx, "shutdown", "status", "flushing usage", "msg", err)
+		}
+
+		if err := auditCore.Flush(ctx); err != nil {
+			log.Error(ctx, "shutdown", "status", "flushing audit", "msg", err)
+		}
 	}
 
 	return nil
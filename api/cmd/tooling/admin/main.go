@@ -1,233 +1,273 @@
+// This program provides the admin tooling for the sales system. Every
+// command reports its result as a table for people or as JSON for scripts,
+// and exits with a stable code so pipelines can react to the outcome.
+//
+//	admin [-output table|json] [-yes] <command> [flags]
+//
+// Commands that change state ask for confirmation unless -yes is passed.
+// Without a terminal to ask on, they fail with the aborted exit code.
 package main
 
 import (
-	"bytes"
-	"context"
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	_ "embed"
-	"encoding/pem"
+	"bufio"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"time"
+	"strings"
+	"text/tabwriter"
+)
 
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/mrcruz117/al-service/business/api/migrate"
-	"github.com/mrcruz117/al-service/business/api/sqldb"
-	"github.com/open-policy-agent/opa/rego"
+// Exit codes reported by the tool. These are part of the tool's contract
+// with the scripts calling it and must not change.
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsage       = 2
+	exitAborted     = 3
+	exitUnavailable = 4
 )
 
-func main() {
-	err := Migrate()
-	if err != nil {
-		log.Fatalln(err)
-	}
+// exitError associates an error with the code the tool exits with.
+type exitError struct {
+	code int
+	err  error
 }
 
-// Migrate creates the schema in the database.
-func Migrate() error {
-	dbConfig := sqldb.Config{
-		User:         "postgres",
-		Password:     "postgres",
-		HostPort:     "database-service.sales-system.svc.cluster.local",
-		Name:         "postgres",
-		MaxIdleConns: 2,
-		MaxOpenConns: 0,
-		DisableTLS:   true,
-	}
-	db, err := sqldb.Open(dbConfig)
-	if err != nil {
-		return fmt.Errorf("connect database: %w", err)
-	}
-	defer db.Close()
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (e *exitError) Unwrap() error {
+	return e.err
+}
 
-	if err := migrate.Migrate(ctx, db); err != nil {
-		return fmt.Errorf("migrating database: %w", err)
-	}
-	fmt.Println("migrations complete")
+func usageErrorf(format string, args ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
 
-	if err := migrate.Seed(ctx, db); err != nil {
-		return fmt.Errorf("seeding database: %w", err)
-	}
+func unavailable(err error) error {
+	return &exitError{code: exitUnavailable, err: err}
+}
 
-	fmt.Println("seeding complete")
-	return nil
+// errAborted is returned when a confirmation was declined or couldn't be
+// asked for.
+var errAborted = &exitError{code: exitAborted, err: errors.New("aborted: confirmation declined, pass -yes to run non-interactively")}
+
+// command represents a subcommand of the tool.
+type command struct {
+	name  string
+	usage string
+	run   func(env *env, args []string) (result, error)
 }
 
-func GenToken() error {
-
-	// Generating a token requires defining a set of claims. In this applications
-	// case, we only care about defining the subject and the user in question and
-	// the roles they have on the database. This token will expire in a year.
-	//
-	// iss (issuer): Issuer of the JWT
-	// sub (subject): Subject of the JWT (the user)
-	// aud (audience): Recipient for which the JWT is intended
-	// exp (expiration time): Time after which the JWT expires
-	// nbf (not before time): Time before which the JWT must not be accepted for processing
-	// iat (issued at time): Time at which the JWT was issued; can be used to determine age of the JWT
-	// jti (JWT ID): Unique identifier; can be used to prevent the JWT from being replayed (allows a token to be used only once)
-	claims := struct {
-		jwt.RegisteredClaims
-		Roles []string
-	}{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   "38dc9d84-018b-4a15-b958-0b78af11c301",
-			Issuer:    "service project",
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)), // 1 year
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		},
-		Roles: []string{"ADMIN"},
-	}
-
-	method := jwt.GetSigningMethod(jwt.SigningMethodRS256.Name)
-	token := jwt.NewWithClaims(method, claims)
-
-	// kid is key ID
-	token.Header["kid"] = "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"
-
-	privateKeyPEM, err := os.ReadFile("zarf/keys/54bb2165-71e1-41a6-af3e-7da4a0e1e2c1.pem")
-	if err != nil {
-		return fmt.Errorf("reading private pem: %w", err)
+var commands = []command{
+	{name: "migrate", usage: "apply the schema migrations and seed data", run: migrateCmd},
+	{name: "seed", usage: "seed a database that has already been migrated", run: seedCmd},
+	{name: "useradd", usage: "create a user, such as the first admin", run: userAddCmd},
+	{name: "userdel", usage: "disable a user so it can no longer sign in", run: userDelCmd},
+	{name: "genkey", usage: "generate a private/public key pair for signing tokens", run: genKeyCmd},
+	{name: "gentoken", usage: "mint a signed token for a subject", run: genTokenCmd},
+	{name: "replay", usage: "deliver recorded domain events to a webhook target", run: replayCmd},
+}
+
+// env holds the settings shared by every command.
+type env struct {
+	stdin  io.Reader
+	stderr io.Writer
+	yes    bool
+}
+
+// confirm asks the operator to approve the action, unless approval was
+// given up front with -yes.
+func (e *env) confirm(format string, args ...any) error {
+	if e.yes {
+		return nil
 	}
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
-	if err != nil {
-		return fmt.Errorf("parsing private pem: %w", err)
+	if f, ok := e.stdin.(*os.File); ok {
+		if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return errAborted
+		}
 	}
 
-	str, err := token.SignedString(privateKey)
-	if err != nil {
-		return fmt.Errorf("signing token: %w", err)
+	fmt.Fprintf(e.stderr, format+" [y/N]: ", args...)
+
+	answer, _ := bufio.NewReader(e.stdin).ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		return errAborted
 	}
 
-	fmt.Printf("-----BEGIN TOKEN-----\n%s\n-----END TOKEN-----\n\n", str)
+	return nil
+}
 
-	// -------------------------------------------------------------------------
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	// Marshal the public key from the private key to PKIX.
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
-	if err != nil {
-		return fmt.Errorf("marshaling public key: %w", err)
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("output", "table", "output format: table or json")
+	yes := fs.Bool("yes", false, "approve every confirmation without asking")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: admin [-output table|json] [-yes] <command> [flags]\n\ncommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(stderr, "  %-10s %s\n", cmd.name, cmd.usage)
+		}
+		fmt.Fprintf(stderr, "\nflags:\n")
+		fs.PrintDefaults()
 	}
 
-	// Construct a PEM block for the public key.
-	publicBlock := pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: asn1Bytes,
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
 	}
 
-	// Write the public key to the public key file.
-	if err := pem.Encode(os.Stdout, &publicBlock); err != nil {
-		return fmt.Errorf("encoding to public file: %w", err)
+	p, err := newPrinter(*output, stdout, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
 	}
 
-	var b bytes.Buffer
-	if err := pem.Encode(&b, &publicBlock); err != nil {
-		return fmt.Errorf("encoding to public file: %w", err)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
 	}
 
-	// -------------------------------------------------------------------------
+	name := fs.Arg(0)
 
-	ctx := context.Background()
-	query := fmt.Sprintf("x = data.%s.%s", "service.rego", "auth")
+	idx := -1
+	for i, cmd := range commands {
+		if cmd.name == name {
+			idx = i
+		}
+	}
 
-	q, err := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", opaAuthentication),
-	).PrepareForEval(ctx)
-	if err != nil {
-		return err
+	if idx == -1 {
+		return p.error(usageErrorf("unknown command %q", name))
 	}
 
-	input := map[string]any{
-		"Key":   b.String(),
-		"Token": str,
-		"ISS":   "service project",
+	e := env{
+		stdin:  stdin,
+		stderr: stderr,
+		yes:    *yes,
 	}
 
-	results, err := q.Eval(ctx, rego.EvalInput(input))
+	res, err := commands[idx].run(&e, fs.Args()[1:])
 	if err != nil {
-		return fmt.Errorf("query: %w", err)
+		return p.error(err)
 	}
 
-	if len(results) == 0 {
-		return errors.New("no results")
+	if err := p.result(res); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
 	}
 
-	result, ok := results[0].Bindings["x"].(bool)
-	if !ok || !result {
-		return fmt.Errorf("bindings results[%v] ok[%v]", results, ok)
-	}
+	return exitOK
+}
 
-	fmt.Println("\nTOKEN VALIDATED!")
+// =============================================================================
 
-	return nil
+// field is a single named value in a result.
+type field struct {
+	key   string
+	value any
 }
 
-//go:embed rego/authentication.rego
-var opaAuthentication string
+// result represents the outcome of a command. The fields are reported in
+// the order they are listed.
+type result []field
+
+// MarshalJSON encodes the result as an object keeping the field order.
+func (r result) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+
+	for i, f := range r {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
 
-// GenKey creates an x509 private/public key for auth tokens.
-func GenKey() error {
+	b.WriteByte('}')
 
-	// Generate a new private key.
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		return fmt.Errorf("generating key: %w", err)
-	}
+	return []byte(b.String()), nil
+}
 
-	// Create a file for the private key information in PEM form.
-	privateFile, err := os.Create("private.pem")
-	if err != nil {
-		return fmt.Errorf("creating private file: %w", err)
-	}
-	defer privateFile.Close()
+// printer writes results and errors in the selected format.
+type printer struct {
+	json   bool
+	stdout io.Writer
+	stderr io.Writer
+}
 
-	// Construct a PEM block for the private key.
-	privateBlock := pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+func newPrinter(format string, stdout io.Writer, stderr io.Writer) (*printer, error) {
+	switch format {
+	case "table", "json":
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected table or json", format)
 	}
 
-	// Write the private key to the private key file.
-	if err := pem.Encode(privateFile, &privateBlock); err != nil {
-		return fmt.Errorf("encoding to private file: %w", err)
+	p := printer{
+		json:   format == "json",
+		stdout: stdout,
+		stderr: stderr,
 	}
 
-	// -------------------------------------------------------------------------
+	return &p, nil
+}
 
-	// Create a file for the public key information in PEM form.
-	publicFile, err := os.Create("public.pem")
-	if err != nil {
-		return fmt.Errorf("creating public file: %w", err)
+func (p *printer) result(res result) error {
+	if p.json {
+		return json.NewEncoder(p.stdout).Encode(res)
 	}
-	defer publicFile.Close()
 
-	// Marshal the public key from the private key to PKIX.
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
-	if err != nil {
-		return fmt.Errorf("marshaling public key: %w", err)
+	tw := tabwriter.NewWriter(p.stdout, 0, 4, 2, ' ', 0)
+	for _, f := range res {
+		value := f.value
+		if v, ok := value.([]string); ok {
+			value = strings.Join(v, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%v\n", strings.ToUpper(f.key), value)
 	}
 
-	// Construct a PEM block for the public key.
-	publicBlock := pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: asn1Bytes,
+	return tw.Flush()
+}
+
+// error reports the error and returns the exit code for it.
+func (p *printer) error(err error) int {
+	code := exitFailure
+
+	var ee *exitError
+	if errors.As(err, &ee) {
+		code = ee.code
 	}
 
-	// Write the public key to the public key file.
-	if err := pem.Encode(publicFile, &publicBlock); err != nil {
-		return fmt.Errorf("encoding to public file: %w", err)
+	if p.json {
+		json.NewEncoder(p.stderr).Encode(result{{"error", err.Error()}, {"exitCode", code}})
+		return code
 	}
 
-	fmt.Println("private and public key files generated")
+	fmt.Fprintln(p.stderr, "error:", err)
 
-	return nil
+	return code
 }
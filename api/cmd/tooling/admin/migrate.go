@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/migrate"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+)
+
+// migrateCmd creates the schema in the database and seeds it.
+func migrateCmd(e *env, args []string) (result, error) {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dbConfig := dbFlags(fs)
+	seed := fs.Bool("seed", true, "seed the database after migrating")
+	timeout := fs.Duration("timeout", 10*time.Second, "time allowed for the migration")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("migrate: %w", err)
+	}
+
+	if err := e.confirm("apply migrations to %s/%s?", dbConfig.HostPort, dbConfig.Name); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db, err := openDB(ctx, *dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if err := migrate.Migrate(ctx, db); err != nil {
+		return nil, fmt.Errorf("migrating database: %w", err)
+	}
+
+	if *seed {
+		if err := migrate.Seed(ctx, db); err != nil {
+			return nil, fmt.Errorf("seeding database: %w", err)
+		}
+	}
+
+	res := result{
+		{"database", dbConfig.HostPort + "/" + dbConfig.Name},
+		{"migrated", true},
+		{"seeded", *seed},
+	}
+
+	return res, nil
+}
+
+// seedCmd seeds a database the schema has already been created in, such as
+// one migrated with -seed=false or reset for a demo.
+func seedCmd(e *env, args []string) (result, error) {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dbConfig := dbFlags(fs)
+	timeout := fs.Duration("timeout", 10*time.Second, "time allowed for seeding")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("seed: %w", err)
+	}
+
+	if err := e.confirm("seed %s/%s?", dbConfig.HostPort, dbConfig.Name); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db, err := openDB(ctx, *dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if err := migrate.Seed(ctx, db); err != nil {
+		return nil, fmt.Errorf("seeding database: %w", err)
+	}
+
+	res := result{
+		{"database", dbConfig.HostPort + "/" + dbConfig.Name},
+		{"seeded", true},
+	}
+
+	return res, nil
+}
+
+// dbFlags registers the flags for connecting to the database and returns
+// the configuration they populate.
+func dbFlags(fs *flag.FlagSet) *sqldb.Config {
+	cfg := sqldb.Config{
+		MaxIdleConns: 2,
+		MaxOpenConns: 0,
+	}
+
+	fs.StringVar(&cfg.User, "db-user", "postgres", "database user")
+	fs.StringVar(&cfg.Password, "db-password", "postgres", "database password")
+	fs.StringVar(&cfg.HostPort, "db-host", "database-service.sales-system.svc.cluster.local", "database host and port")
+	fs.StringVar(&cfg.Name, "db-name", "postgres", "database name")
+	fs.StringVar(&cfg.Dialect, "db-dialect", "postgres", "database dialect: postgres or mysql")
+	fs.BoolVar(&cfg.DisableTLS, "db-disable-tls", true, "connect without TLS")
+
+	return &cfg
+}
+
+// openDB connects to the database and checks it's reachable.
+func openDB(ctx context.Context, cfg sqldb.Config) (*sqlx.DB, error) {
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return nil, unavailable(fmt.Errorf("connect database: %w", err))
+	}
+
+	if err := sqldb.StatusCheck(ctx, db); err != nil {
+		db.Close()
+		return nil, unavailable(fmt.Errorf("database status: %w", err))
+	}
+
+	return db, nil
+}
@@ -5,11 +5,41 @@ import rego.v1
 default auth := false
 
 auth if {
+	input.ALG != "EdDSA"
 	[valid, _, _] := verify_jwt
 	valid = true
 }
 
-verify_jwt := io.jwt.decode_verify(input.Token, {
+# OPA can't verify EdDSA signatures. The service verifies them, with the
+# algorithm pinned to the key's, before the policy is evaluated, so only the
+# claims are checked here.
+auth if {
+	input.ALG == "EdDSA"
+	input.Verified == true
+	[_, claims, _] := io.jwt.decode(input.Token)
+	claims.iss == input.ISS
+	audience(claims)
+}
+
+verify_jwt := io.jwt.decode_verify(input.Token, constraints)
+
+# The audience is only checked for tokens of external providers, the ones
+# minted by the service don't carry one.
+constraints := {
+	"cert": input.Key,
+	"alg": input.ALG,
+	"iss": input.ISS,
+	"aud": input.AUD,
+} if {
+	input.AUD
+} else := {
 	"cert": input.Key,
+	"alg": input.ALG,
 	"iss": input.ISS,
-})
\ No newline at end of file
+}
+
+audience(_) if not input.AUD
+
+audience(claims) if claims.aud == input.AUD
+
+audience(claims) if input.AUD in claims.aud
\ No newline at end of file
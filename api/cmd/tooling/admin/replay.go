@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/event"
+	"github.com/mrcruz117/al-service/business/core/event/stores/eventdb"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// replayCmd delivers recorded domain events to a webhook target, such as a
+// consumer rebuilding its projection after a bug.
+func replayCmd(e *env, args []string) (result, error) {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dbConfig := dbFlags(fs)
+	target := fs.String("target", "", "url the events are posted to")
+	secret := fs.String("secret", "", "secret the events are signed with in the X-Signature and X-Signature-Timestamp headers")
+	after := fs.Int64("after", 0, "replay the events after this sequence number, use the reported lastSeq to resume")
+	through := fs.Int64("through", 0, "replay the events up to and including this sequence number")
+	types := fs.String("types", "", "comma separated event types to replay, all when empty")
+	tenantID := fs.String("tenant", "", "only replay the events of this tenant id")
+	rate := fs.Float64("rate", 50, "events delivered per second, zero for no limit")
+	batch := fs.Int("batch", 100, "events read from the database at a time")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("replay: %w", err)
+	}
+
+	if *target == "" {
+		return nil, usageErrorf("replay: -target is required")
+	}
+
+	filter := event.QueryFilter{
+		After:   *after,
+		Through: *through,
+	}
+
+	if *types != "" {
+		filter.Types = strings.Split(*types, ",")
+	}
+
+	if *tenantID != "" {
+		id, err := uuid.Parse(*tenantID)
+		if err != nil {
+			return nil, usageErrorf("replay: -tenant: %w", err)
+		}
+		filter.TenantID = id
+	}
+
+	if err := e.confirm("replay events after %d to %s?", *after, *target); err != nil {
+		return nil, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	db, err := openDB(ctx, *dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	log := logger.New(logger.Discard, logger.LevelInfo, "ADMIN", nil)
+	core := event.NewCore(log, eventdb.NewStore(log, db))
+
+	cfg := event.ReplayConfig{
+		Filter:    filter,
+		BatchSize: *batch,
+		Rate:      *rate,
+		Progress: func(p event.Progress) {
+			fmt.Fprintf(e.stderr, "replayed %d events through seq %d in %s\n", p.Replayed, p.LastSeq, p.Elapsed.Round(time.Millisecond))
+		},
+	}
+
+	consumer := webhookConsumer{
+		client: &http.Client{Timeout: 10 * time.Second},
+		url:    *target,
+		secret: []byte(*secret),
+	}
+
+	prog, err := core.Replay(ctx, cfg, consumer)
+	if err != nil {
+		return nil, fmt.Errorf("replay stopped after seq %d, resume with -after %d: %w", prog.LastSeq, prog.LastSeq, err)
+	}
+
+	res := result{
+		{"replayed", prog.Replayed},
+		{"lastSeq", prog.LastSeq},
+		{"elapsed", prog.Elapsed.Round(time.Millisecond).String()},
+	}
+
+	return res, nil
+}
+
+// webhookConsumer posts every event to the url in the same form partners
+// deliver webhooks to the sales service.
+type webhookConsumer struct {
+	client *http.Client
+	url    string
+	secret []byte
+}
+
+func (wc webhookConsumer) Consume(ctx context.Context, evt event.Event) error {
+	payload := struct {
+		ID       string          `json:"id"`
+		Seq      int64           `json:"seq"`
+		Type     string          `json:"type"`
+		TenantID string          `json:"tenantID,omitempty"`
+		Data     json.RawMessage `json:"data"`
+		Time     string          `json:"time"`
+		Replayed bool            `json:"replayed"`
+	}{
+		ID:       evt.ID.String(),
+		Seq:      evt.Seq,
+		Type:     evt.Type,
+		Data:     evt.Data,
+		Time:     evt.DateCreated.UTC().Format(time.RFC3339Nano),
+		Replayed: true,
+	}
+
+	if evt.TenantID != uuid.Nil {
+		payload.TenantID = evt.TenantID.String()
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wc.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(wc.secret) > 0 {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Signature-Timestamp", ts)
+		req.Header.Set("X-Signature", mid.Sign(wc.secret, ts, body))
+	}
+
+	resp, err := wc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("post: target responded %s", resp.Status)
+	}
+
+	return nil
+}
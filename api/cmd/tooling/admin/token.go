@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	_ "embed"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// genTokenCmd mints a token signed with the private key for the kid and
+// validates it with the same policy the auth service uses.
+func genTokenCmd(e *env, args []string) (result, error) {
+	fs := flag.NewFlagSet("gentoken", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	keys := fs.String("keys", "zarf/keys", "directory holding the <kid>.pem private keys")
+	kid := fs.String("kid", "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1", "key id used to sign the token")
+	subject := fs.String("subject", "38dc9d84-018b-4a15-b958-0b78af11c301", "subject of the token (the user id)")
+	roles := fs.String("roles", "ADMIN", "comma separated roles granted by the token")
+	issuer := fs.String("issuer", "service project", "issuer of the token")
+	ttl := fs.Duration("ttl", 8760*time.Hour, "time until the token expires")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("gentoken: %w", err)
+	}
+
+	// Generating a token requires defining a set of claims. In this applications
+	// case, we only care about defining the subject and the user in question and
+	// the roles they have on the database.
+	//
+	// iss (issuer): Issuer of the JWT
+	// sub (subject): Subject of the JWT (the user)
+	// aud (audience): Recipient for which the JWT is intended
+	// exp (expiration time): Time after which the JWT expires
+	// nbf (not before time): Time before which the JWT must not be accepted for processing
+	// iat (issued at time): Time at which the JWT was issued; can be used to determine age of the JWT
+	// jti (JWT ID): Unique identifier; can be used to prevent the JWT from being replayed (allows a token to be used only once)
+	now := time.Now().UTC()
+	claims := struct {
+		jwt.RegisteredClaims
+		Roles []string
+	}{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   *subject,
+			Issuer:    *issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(*ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Roles: strings.Split(*roles, ","),
+	}
+
+	privateKeyPEM, err := os.ReadFile(filepath.Join(*keys, *kid+".pem"))
+	if err != nil {
+		return nil, fmt.Errorf("reading private pem: %w", err)
+	}
+
+	// The algorithm is the one of the key, like the auth service does.
+	privateKey, method, err := auth.ParsePrivateKeyPEM(string(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("parsing private pem: %w", err)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+
+	// kid is key ID
+	token.Header["kid"] = *kid
+
+	str, err := token.SignedString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("signing token: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key of type %T can't sign", privateKey)
+	}
+
+	// Marshal the public key from the private key to PKIX.
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("marshaling public key: %w", err)
+	}
+
+	// Construct a PEM block for the public key.
+	publicBlock := pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: asn1Bytes,
+	}
+
+	var b bytes.Buffer
+	if err := pem.Encode(&b, &publicBlock); err != nil {
+		return nil, fmt.Errorf("encoding to public file: %w", err)
+	}
+
+	if err := validateToken(str, b.String(), method, *issuer); err != nil {
+		return nil, fmt.Errorf("validating token: %w", err)
+	}
+
+	res := result{
+		{"token", str},
+		{"kid", *kid},
+		{"subject", *subject},
+		{"roles", claims.Roles},
+		{"expiresAt", claims.ExpiresAt.Format(time.RFC3339)},
+	}
+
+	return res, nil
+}
+
+//go:embed rego/authentication.rego
+var opaAuthentication string
+
+func validateToken(token string, publicKey string, method jwt.SigningMethod, issuer string) error {
+	ctx := context.Background()
+	query := fmt.Sprintf("x = data.%s.%s", "service.rego", "auth")
+
+	q, err := rego.New(
+		rego.Query(query),
+		rego.Module("policy.rego", opaAuthentication),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := map[string]any{
+		"Key":   publicKey,
+		"Token": token,
+		"ISS":   issuer,
+		"ALG":   method.Alg(),
+	}
+
+	// The policy leaves verifying EdDSA signatures to the caller.
+	if method == jwt.SigningMethodEdDSA {
+		key, _, err := auth.ParsePublicKeyPEM(publicKey)
+		if err != nil {
+			return fmt.Errorf("parsing public key: %w", err)
+		}
+
+		parser := jwt.NewParser(jwt.WithValidMethods([]string{method.Alg()}))
+		if _, err := parser.Parse(token, func(*jwt.Token) (any, error) { return key, nil }); err != nil {
+			return fmt.Errorf("verifying token: %w", err)
+		}
+		input["Verified"] = true
+	}
+
+	results, err := q.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if len(results) == 0 {
+		return errors.New("no results")
+	}
+
+	result, ok := results[0].Bindings["x"].(bool)
+	if !ok || !result {
+		return fmt.Errorf("bindings results[%v] ok[%v]", results, ok)
+	}
+
+	return nil
+}
+
+// genKeyCmd creates an x509 private/public key for auth tokens.
+func genKeyCmd(e *env, args []string) (result, error) {
+	fs := flag.NewFlagSet("genkey", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	privatePath := fs.String("private", "private.pem", "file the private key is written to")
+	publicPath := fs.String("public", "public.pem", "file the public key is written to")
+	alg := fs.String("alg", "RS256", "algorithm the key signs with: RS256, ES256 or EdDSA")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("genkey: %w", err)
+	}
+
+	for _, path := range []string{*privatePath, *publicPath} {
+		if _, err := os.Stat(path); err == nil {
+			if err := e.confirm("overwrite %s?", path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Generate a new private key of the type for the algorithm. RSA keys
+	// are written as PKCS1 like they always have been, the others as PKCS8.
+	var privateKey crypto.Signer
+	var privateBytes []byte
+
+	switch *alg {
+	case "RS256":
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("generating key: %w", err)
+		}
+		privateKey, privateBytes = key, x509.MarshalPKCS1PrivateKey(key)
+
+	case "ES256", "EdDSA":
+		var err error
+		if *alg == "ES256" {
+			privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		} else {
+			_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("generating key: %w", err)
+		}
+
+		privateBytes, err = x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling private key: %w", err)
+		}
+
+	default:
+		return nil, usageErrorf("genkey: unknown algorithm %q", *alg)
+	}
+
+	// Create a file for the private key information in PEM form.
+	privateFile, err := os.Create(*privatePath)
+	if err != nil {
+		return nil, fmt.Errorf("creating private file: %w", err)
+	}
+	defer privateFile.Close()
+
+	// Construct a PEM block for the private key.
+	privateBlock := pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: privateBytes,
+	}
+
+	// Write the private key to the private key file.
+	if err := pem.Encode(privateFile, &privateBlock); err != nil {
+		return nil, fmt.Errorf("encoding to private file: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Create a file for the public key information in PEM form.
+	publicFile, err := os.Create(*publicPath)
+	if err != nil {
+		return nil, fmt.Errorf("creating public file: %w", err)
+	}
+	defer publicFile.Close()
+
+	// Marshal the public key from the private key to PKIX.
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("marshaling public key: %w", err)
+	}
+
+	// Construct a PEM block for the public key.
+	publicBlock := pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: asn1Bytes,
+	}
+
+	// Write the public key to the public key file.
+	if err := pem.Encode(publicFile, &publicBlock); err != nil {
+		return nil, fmt.Errorf("encoding to public file: %w", err)
+	}
+
+	res := result{
+		{"private", *privatePath},
+		{"public", *publicPath},
+		{"alg", *alg},
+	}
+
+	return res, nil
+}
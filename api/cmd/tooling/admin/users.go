@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/userdb"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// userAddCmd creates a user, such as the first admin of a new environment.
+func userAddCmd(e *env, args []string) (result, error) {
+	fs := flag.NewFlagSet("useradd", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dbConfig := dbFlags(fs)
+	name := fs.String("name", "", "name of the user")
+	email := fs.String("email", "", "email the user signs in with")
+	roles := fs.String("roles", "ADMIN", "comma separated roles: ADMIN, USER")
+	department := fs.String("department", "", "department of the user")
+	password := fs.String("password", "", "password of the user, visible to other processes so prefer -password-stdin")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from the first line of stdin")
+	timeout := fs.Duration("timeout", 10*time.Second, "time allowed for the command")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("useradd: %w", err)
+	}
+
+	if *name == "" {
+		return nil, usageErrorf("useradd: -name is required")
+	}
+
+	addr, err := mail.ParseAddress(*email)
+	if err != nil {
+		return nil, usageErrorf("useradd: -email: %w", err)
+	}
+
+	rs := strings.Split(*roles, ",")
+	for _, role := range rs {
+		if role != "ADMIN" && role != "USER" {
+			return nil, usageErrorf("useradd: -roles: invalid role %q", role)
+		}
+	}
+
+	if *passwordStdin {
+		line, err := bufio.NewReader(e.stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return nil, usageErrorf("useradd: reading password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+
+		// The confirmation can't be read from stdin once the password
+		// has been.
+		e.yes = true
+	}
+
+	if *password == "" {
+		return nil, usageErrorf("useradd: -password or -password-stdin is required")
+	}
+
+	if err := e.confirm("create user %s with roles %s in %s/%s?", addr.Address, *roles, dbConfig.HostPort, dbConfig.Name); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db, err := openDB(ctx, *dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	usr, err := newUserCore(db).Create(ctx, user.NewUser{
+		Name:       *name,
+		Email:      *addr,
+		Roles:      rs,
+		Department: *department,
+		Password:   *password,
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrUniqueEmail) {
+			return nil, fmt.Errorf("useradd: a user with email %s already exists", addr.Address)
+		}
+		return nil, fmt.Errorf("useradd: %w", err)
+	}
+
+	res := result{
+		{"id", usr.ID.String()},
+		{"email", usr.Email.Address},
+		{"roles", strings.Join(usr.Roles, ",")},
+		{"enabled", usr.Enabled},
+	}
+
+	return res, nil
+}
+
+// userDelCmd disables a user so it can no longer sign in. The tokens and
+// sessions the user already holds stop being accepted too, since the
+// services check the user is enabled as they authenticate them. The user
+// is kept so what it owns and the audit trail of what it did still refer
+// to it.
+func userDelCmd(e *env, args []string) (result, error) {
+	fs := flag.NewFlagSet("userdel", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dbConfig := dbFlags(fs)
+	email := fs.String("email", "", "email of the user to disable")
+	timeout := fs.Duration("timeout", 10*time.Second, "time allowed for the command")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("userdel: %w", err)
+	}
+
+	addr, err := mail.ParseAddress(*email)
+	if err != nil {
+		return nil, usageErrorf("userdel: -email: %w", err)
+	}
+
+	if err := e.confirm("disable user %s in %s/%s?", addr.Address, dbConfig.HostPort, dbConfig.Name); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db, err := openDB(ctx, *dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	core := newUserCore(db)
+
+	usr, err := core.QueryByEmail(ctx, *addr)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return nil, fmt.Errorf("userdel: no user with email %s", addr.Address)
+		}
+		return nil, fmt.Errorf("userdel: %w", err)
+	}
+
+	if usr.Enabled {
+		enabled := false
+		if usr, err = core.Update(ctx, usr, user.UpdateUser{Enabled: &enabled}); err != nil {
+			return nil, fmt.Errorf("userdel: %w", err)
+		}
+	}
+
+	res := result{
+		{"id", usr.ID.String()},
+		{"email", usr.Email.Address},
+		{"enabled", usr.Enabled},
+	}
+
+	return res, nil
+}
+
+// newUserCore constructs the user core over the database. Nothing else
+// runs in the tool, so no domain is registered to act on the changes.
+func newUserCore(db *sqlx.DB) *user.Core {
+	log := logger.New(logger.Discard, logger.LevelInfo, "ADMIN", nil)
+	return user.NewCore(log, delegate.New(log), userdb.NewStore(log, db))
+}
@@ -0,0 +1,348 @@
+// This program walks the route definitions of every domain package and
+// exports the authentication and authorization attached to each route. It
+// fails when a route other than the health checks has no explicit auth
+// declaration.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// authenticators are the middleware functions that establish an identity.
+var authenticators = map[string]bool{
+	"Authenticate":           true,
+	"Bearer":                 true,
+	"Basic":                  true,
+	"ClientCert":             true,
+	"APIKey":                 true,
+	"Session":                true,
+	"VerifySignature":        true,
+	"VerifyServiceSignature": true,
+}
+
+// authorizers are the middleware functions that apply a rule, which is
+// always their last argument.
+var authorizers = map[string]bool{
+	"Authorize":             true,
+	"AuthorizeFailOpen":     true,
+	"AuthorizeLocal":        true,
+	"AuthorizeSubject":      true,
+	"AuthorizeSubjectLocal": true,
+}
+
+// health are the routes that are allowed to have no auth declaration.
+var health = map[string]bool{
+	"/liveness":  true,
+	"/readiness": true,
+}
+
+// Route represents the auth declarations of a single route.
+type Route struct {
+	Package        string   `json:"package"`
+	Method         string   `json:"method"`
+	Path           string   `json:"path"`
+	Authentication []string `json:"authentication"`
+	Rules          []string `json:"rules"`
+	Scopes         []string `json:"scopes"`
+	Public         bool     `json:"public"`
+	Health         bool     `json:"health"`
+	Experimental   string   `json:"experimental,omitempty"`
+}
+
+func (r Route) declared() bool {
+	return r.Health || r.Public || len(r.Authentication) > 0
+}
+
+func main() {
+	dir := flag.String("dir", "api/http/domain", "directory holding the domain packages")
+	format := flag.String("format", "json", "output format: json or csv")
+	flag.Parse()
+
+	if err := run(os.Stdout, *dir, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, dir string, format string) error {
+	routes, err := collect(dir)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(routes); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+
+	case "csv":
+		if err := writeCSV(w, routes); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	var missing []string
+	for _, r := range routes {
+		if !r.declared() {
+			missing = append(missing, fmt.Sprintf("  %s: %s %s", r.Package, r.Method, r.Path))
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.New("routes without an auth declaration:\n" + strings.Join(missing, "\n"))
+	}
+
+	return nil
+}
+
+func writeCSV(w io.Writer, routes []Route) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"package", "method", "path", "authentication", "rules", "scopes", "public", "health", "experimental"}); err != nil {
+		return err
+	}
+
+	for _, r := range routes {
+		rec := []string{
+			r.Package,
+			r.Method,
+			r.Path,
+			strings.Join(r.Authentication, ";"),
+			strings.Join(r.Rules, ";"),
+			strings.Join(r.Scopes, ";"),
+			strconv.FormatBool(r.Public),
+			strconv.FormatBool(r.Health),
+			r.Experimental,
+		}
+
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// =============================================================================
+
+func collect(dir string) ([]Route, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*", "route.go"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+
+	var routes []Route
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != "Routes" || fn.Body == nil {
+				continue
+			}
+
+			routes = append(routes, routesOf(f.Name.Name, fn.Body)...)
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes, nil
+}
+
+// declaration is what a middleware expression contributes to a route.
+type declaration struct {
+	authentication string
+	rule           string
+	scope          string
+	public         bool
+}
+
+func routesOf(pkg string, body *ast.BlockStmt) []Route {
+	vars := make(map[string]declaration)
+
+	var routes []Route
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return true
+			}
+
+			for i, lhs := range n.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+
+				if d, ok := declarationOf(n.Rhs[i]); ok {
+					vars[id.Name] = d
+				}
+			}
+
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+
+			// Experimental routes take the capability ahead of the pattern.
+			args := n.Args
+			var capability string
+
+			switch sel.Sel.Name {
+			case "HandleFunc", "HandleFuncNoMiddleware":
+			case "HandleFuncExperimental":
+				if len(args) == 0 {
+					return true
+				}
+				capability = exprString(args[0])
+				args = args[1:]
+			default:
+				return true
+			}
+
+			if len(args) < 2 {
+				return true
+			}
+
+			lit, ok := args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+
+			pattern, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+
+			method, path, found := strings.Cut(pattern, " ")
+			if !found {
+				method, path = "ANY", pattern
+			}
+
+			r := Route{
+				Package:        pkg,
+				Method:         method,
+				Path:           path,
+				Authentication: []string{},
+				Rules:          []string{},
+				Scopes:         []string{},
+				Health:         health[path],
+				Experimental:   capability,
+			}
+
+			for _, arg := range args[2:] {
+				d, ok := resolve(arg, vars)
+				if !ok {
+					continue
+				}
+
+				switch {
+				case d.public:
+					r.Public = true
+				case d.authentication != "":
+					r.Authentication = append(r.Authentication, d.authentication)
+				case d.rule != "":
+					r.Rules = append(r.Rules, d.rule)
+				case d.scope != "":
+					r.Scopes = append(r.Scopes, d.scope)
+				}
+			}
+
+			routes = append(routes, r)
+		}
+
+		return true
+	})
+
+	return routes
+}
+
+func resolve(expr ast.Expr, vars map[string]declaration) (declaration, bool) {
+	if id, ok := expr.(*ast.Ident); ok {
+		d, ok := vars[id.Name]
+		return d, ok
+	}
+
+	return declarationOf(expr)
+}
+
+func declarationOf(expr ast.Expr) (declaration, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return declaration{}, false
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return declaration{}, false
+	}
+
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "mid" {
+		return declaration{}, false
+	}
+
+	switch name := sel.Sel.Name; {
+	case name == "Public":
+		return declaration{public: true}, true
+
+	case authenticators[name]:
+		return declaration{authentication: name}, true
+
+	case authorizers[name] && len(call.Args) > 0:
+		return declaration{rule: exprString(call.Args[len(call.Args)-1])}, true
+
+	case name == "RequireScope" && len(call.Args) == 1:
+		return declaration{scope: exprString(call.Args[0])}, true
+	}
+
+	return declaration{}, false
+}
+
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	case *ast.BasicLit:
+		if s, err := strconv.Unquote(e.Value); err == nil {
+			return s
+		}
+		return e.Value
+	}
+
+	return "?"
+}
@@ -0,0 +1,149 @@
+// This program compares two sets of benchmark results produced by go test
+// -bench and fails when a benchmark got slower than the threshold allows. It
+// is meant to run next to benchstat, which gives the full statistical
+// picture, as a simple gate before a release.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// procs matches the GOMAXPROCS suffix go test adds to benchmark names.
+var procs = regexp.MustCompile(`-\d+$`)
+
+func main() {
+	threshold := flag.Float64("threshold", 10, "percentage a benchmark may regress before failing")
+	metric := flag.String("metric", "ns/op", "metric to compare: ns/op, B/op or allocs/op")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: benchgate [flags] base.txt new.txt")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(os.Stdout, flag.Arg(0), flag.Arg(1), *metric, *threshold); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, basePath string, newPath string, metric string, threshold float64) error {
+	base, err := load(basePath, metric)
+	if err != nil {
+		return fmt.Errorf("base: %w", err)
+	}
+
+	head, err := load(newPath, metric)
+	if err != nil {
+		return fmt.Errorf("new: %w", err)
+	}
+
+	names := make([]string, 0, len(head))
+	for name := range head {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var regressed []string
+	for _, name := range names {
+		old, exists := base[name]
+		if !exists {
+			fmt.Fprintf(w, "%-60s %12s %12.1f %8s\n", name, "-", median(head[name]), "new")
+			continue
+		}
+
+		o, n := median(old), median(head[name])
+
+		var delta float64
+		if o != 0 {
+			delta = (n - o) / o * 100
+		}
+
+		status := "ok"
+		if delta > threshold {
+			status = "FAIL"
+			regressed = append(regressed, fmt.Sprintf("  %s: %+.1f%%", name, delta))
+		}
+
+		fmt.Fprintf(w, "%-60s %12.1f %12.1f %+7.1f%% %s\n", name, o, n, delta, status)
+	}
+
+	if len(regressed) > 0 {
+		return fmt.Errorf("benchmarks regressed more than %.1f%% in %s:\n%s", threshold, metric, strings.Join(regressed, "\n"))
+	}
+
+	return nil
+}
+
+// load reads the results of every benchmark in the file for the metric. A
+// benchmark run with -count has a result per run.
+func load(path string, metric string) (map[string][]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	results := make(map[string][]float64)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		name := procs.ReplaceAllString(fields[0], "")
+
+		// After the name and iteration count the line is a list of
+		// value and unit pairs.
+		for i := 2; i+1 < len(fields); i += 2 {
+			if fields[i+1] != metric {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", name, err)
+			}
+
+			results[name] = append(results[name], v)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, errors.New("no benchmark results for " + metric)
+	}
+
+	return results, nil
+}
+
+// median is used rather than the mean so a single noisy run doesn't fail
+// the gate.
+func median(vs []float64) float64 {
+	s := slices.Clone(vs)
+	slices.Sort(s)
+
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
@@ -0,0 +1,603 @@
+// This program generates an in-memory implementation of a domain's Storer
+// interface backed by a memstore table, so business layer unit tests can run
+// without a database. It is meant to be run by go generate from inside the
+// in-memory store package, e.g. business/core/user/stores/usermem.
+//
+// Methods that follow the conventions of the database stores are generated:
+// Create, Update and Upsert of a single entity or a slice, Delete of an
+// entity, QueryBy<Field> lookups returning a single entity and
+// ExecuteUnderTransaction, which returns the store unchanged. Every other
+// method, or any method that needs different behavior, is implemented by
+// hand in the package and the generator leaves it alone.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	domain := flag.String("domain", "", "name of the domain package, e.g. user")
+	typ := flag.String("type", "", "entity type the store holds when no generated method identifies it")
+	key := flag.String("key", "ID", "field of the entity holding its primary key")
+	uniques := flag.String("unique", "", "comma separated unique constraints as Field[.Path][=ErrName]")
+	notFound := flag.String("notfound", "ErrNotFound", "domain error returned when a lookup finds nothing")
+	flag.Parse()
+
+	if err := run(*domain, *typ, *key, *uniques, *notFound); err != nil {
+		fmt.Fprintln(os.Stderr, "storegen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(domain string, typ string, key string, uniques string, notFound string) error {
+	if domain == "" {
+		return errors.New("domain is required")
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getwd: %w", err)
+	}
+
+	coreDir := filepath.Join(dir, "..", "..")
+
+	importPath, err := importPathOf(coreDir)
+	if err != nil {
+		return err
+	}
+
+	core, err := parseCore(coreDir)
+	if err != nil {
+		return err
+	}
+
+	if core.storer == nil {
+		return fmt.Errorf("no Storer interface in %s", coreDir)
+	}
+
+	manual, err := handWritten(dir)
+	if err != nil {
+		return err
+	}
+
+	g := generator{
+		domain:   domain,
+		core:     core,
+		key:      key,
+		notFound: notFound,
+		imports: map[string]bool{
+			"github.com/mrcruz117/al-service/business/api/memstore": true,
+			importPath: true,
+		},
+	}
+
+	for spec := range strings.SplitSeq(uniques, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+
+		path, errName, _ := strings.Cut(spec, "=")
+		if errName != "" && !core.vars[errName] {
+			return fmt.Errorf("unique %q: %s.%s is not declared", spec, domain, errName)
+		}
+		g.uniques = append(g.uniques, unique{path: path, err: errName})
+	}
+
+	if typ != "" {
+		if _, exists := core.structs[typ]; !exists {
+			return fmt.Errorf("type %s.%s is not declared", domain, typ)
+		}
+		g.entity = domain + "." + typ
+	}
+
+	src, err := g.generate(manual)
+	if err != nil {
+		return err
+	}
+
+	pkg := filepath.Base(dir)
+	name := filepath.Join(dir, pkg+"_gen.go")
+
+	if err := os.WriteFile(name, src, 0644); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// coreInfo is what the generator needs to know about the domain package.
+type coreInfo struct {
+	storer  *ast.InterfaceType
+	imports map[string]string
+	structs map[string]*ast.StructType
+	vars    map[string]bool
+}
+
+func parseCore(dir string) (coreInfo, error) {
+	info := coreInfo{
+		imports: make(map[string]string),
+		structs: make(map[string]*ast.StructType),
+		vars:    make(map[string]bool),
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return coreInfo{}, fmt.Errorf("glob: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return coreInfo{}, fmt.Errorf("parse: %w", err)
+		}
+
+		for _, imp := range f.Imports {
+			path := strings.Trim(imp.Path.Value, `"`)
+			name := filepath.Base(path)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			info.imports[name] = path
+		}
+
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					switch t := spec.Type.(type) {
+					case *ast.InterfaceType:
+						if spec.Name.Name == "Storer" {
+							info.storer = t
+						}
+
+					case *ast.StructType:
+						info.structs[spec.Name.Name] = t
+					}
+
+				case *ast.ValueSpec:
+					for _, n := range spec.Names {
+						info.vars[n.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// handWritten returns the methods already implemented on the Store in the
+// files of the package that are not generated.
+func handWritten(dir string) (map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+
+	methods := make(map[string]bool)
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_gen.go") || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv != nil {
+				methods[fn.Name.Name] = true
+			}
+		}
+	}
+
+	return methods, nil
+}
+
+// importPathOf works out the import path of the directory from the module
+// path in the nearest go.mod.
+func importPathOf(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("abs: %w", err)
+	}
+
+	for root := dir; ; root = filepath.Dir(root) {
+		f, err := os.Open(filepath.Join(root, "go.mod"))
+		if err == nil {
+			defer f.Close()
+
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if mod, ok := strings.CutPrefix(scanner.Text(), "module "); ok {
+					rel, err := filepath.Rel(root, dir)
+					if err != nil {
+						return "", fmt.Errorf("rel: %w", err)
+					}
+					return strings.TrimSpace(mod) + "/" + filepath.ToSlash(rel), nil
+				}
+			}
+
+			return "", fmt.Errorf("no module path in %s", f.Name())
+		}
+
+		if filepath.Dir(root) == root {
+			return "", errors.New("go.mod not found")
+		}
+	}
+}
+
+// =============================================================================
+
+type unique struct {
+	path string
+	err  string
+}
+
+type generator struct {
+	domain   string
+	core     coreInfo
+	key      string
+	notFound string
+	uniques  []unique
+	imports  map[string]bool
+	entity   string
+	methods  bytes.Buffer
+}
+
+// param represents a parameter of a Storer method after the context.
+type param struct {
+	name  string
+	typ   string
+	slice bool
+}
+
+func (g *generator) generate(manual map[string]bool) ([]byte, error) {
+	var missing []string
+
+	for _, m := range g.core.storer.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			continue
+		}
+
+		name := m.Names[0].Name
+		if manual[name] {
+			continue
+		}
+
+		if !g.method(name, ft) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("methods %s don't follow a known convention and must be implemented by hand", strings.Join(missing, ", "))
+	}
+
+	if g.entity == "" {
+		return nil, errors.New("no method identifies the entity type")
+	}
+
+	keyType, err := g.keyType()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by storegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %smem\n\n", g.domain)
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Standard library imports are grouped ahead of everything else.
+	sort.SliceStable(paths, func(i, j int) bool {
+		return isStd(paths[i]) && !isStd(paths[j])
+	})
+
+	b.WriteString("import (\n")
+	for i, path := range paths {
+		if i > 0 && isStd(paths[i-1]) && !isStd(path) {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%q\n", path)
+	}
+	b.WriteString(")\n\n")
+
+	table := fmt.Sprintf("*memstore.Table[%s, %s]", keyType, g.entity)
+
+	fmt.Fprintf(&b, "// Store manages the set of APIs for %s in-memory access.\n", g.domain)
+	fmt.Fprintf(&b, "type Store struct {\ntable %s\n}\n\n", table)
+
+	fmt.Fprintf(&b, "// NewStore constructs the api for in-memory access.\n")
+	fmt.Fprintf(&b, "func NewStore() *Store {\nreturn &Store{\ntable: memstore.New(\n")
+	fmt.Fprintf(&b, "func(v %s) %s { return v.%s },\n", g.entity, keyType, g.key)
+	for _, u := range g.uniques {
+		fmt.Fprintf(&b, "memstore.Unique[%s]{Key: func(v %s) any { return v.%s }", g.entity, g.entity, u.path)
+		if u.err != "" {
+			fmt.Fprintf(&b, ", Err: %s.%s", g.domain, u.err)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("),\n}\n}\n\n")
+
+	fmt.Fprintf(&b, "var _ %s.Storer = (*Store)(nil)\n", g.domain)
+
+	b.Write(g.methods.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format: %w\n%s", err, b.String())
+	}
+
+	return src, nil
+}
+
+// method writes the generated implementation of a Storer method and reports
+// whether the method followed one of the conventions.
+func (g *generator) method(name string, ft *ast.FuncType) bool {
+	params := g.params(ft)
+	results := g.results(ft)
+
+	switch {
+	case name == "Create" || name == "Update" || name == "Upsert":
+		if len(params) != 1 || len(results) != 1 || results[0] != "error" || !g.setEntity(params[0]) {
+			return false
+		}
+
+		g.imports["fmt"] = true
+
+		op := map[string]string{"Create": "Insert", "Update": "Update", "Upsert": "Upsert"}[name]
+		doc := map[string]string{"Create": "inserts %s into", "Update": "replaces %s in", "Upsert": "inserts or replaces %s in"}[name]
+
+		arg := params[0].name
+		if params[0].slice {
+			arg += "..."
+		}
+
+		noun := g.noun()
+		if params[0].slice {
+			noun = plural(noun)
+		}
+
+		g.writef("\n// %s %s the store.\n", name, fmt.Sprintf(doc, "the "+noun))
+		g.writef("func (s *Store) %s(ctx context.Context, %s %s) error {\n", name, params[0].name, params[0].typ)
+		g.writef("if err := s.table.%s(%s); err != nil {\nreturn fmt.Errorf(\"%s: %%w\", err)\n}\n\nreturn nil\n}\n", op, arg, strings.ToLower(op))
+
+	case name == "Delete":
+		if len(params) != 1 || params[0].slice || len(results) != 1 || results[0] != "error" || !g.setEntity(params[0]) {
+			return false
+		}
+
+		g.writef("\n// Delete removes the %s from the store.\n", g.noun())
+		g.writef("func (s *Store) Delete(ctx context.Context, %s %s) error {\n", params[0].name, params[0].typ)
+		g.writef("s.table.Delete(%s.%s)\n\nreturn nil\n}\n", params[0].name, g.key)
+
+	case strings.HasPrefix(name, "QueryBy"):
+		field := strings.TrimPrefix(name, "QueryBy")
+
+		if len(params) != 1 || params[0].slice || len(results) != 2 || results[1] != "error" || !g.setEntity(param{typ: results[0]}) {
+			return false
+		}
+
+		if !g.core.vars[g.notFound] {
+			return false
+		}
+
+		g.imports["errors"] = true
+		g.imports["fmt"] = true
+		g.imports["github.com/mrcruz117/al-service/business/api/sqldb"] = true
+
+		p := params[0].name
+
+		var lookup string
+		switch field {
+		case g.key:
+			lookup = fmt.Sprintf("s.table.Get(%s)", p)
+		default:
+			path := field
+			for _, u := range g.uniques {
+				if strings.HasPrefix(u.path, field+".") {
+					path = u.path
+				}
+			}
+			rest := strings.TrimPrefix(path, field)
+			lookup = fmt.Sprintf("s.table.First(func(v %s) bool {\nreturn v.%s == %s%s\n})", g.entity, path, p, rest)
+		}
+
+		g.writef("\n// %s gets the specified %s from the store.\n", name, g.noun())
+		g.writef("func (s *Store) %s(ctx context.Context, %s %s) (%s, error) {\n", name, p, params[0].typ, g.entity)
+		g.writef("v, err := %s\nif err != nil {\n", lookup)
+		g.writef("if errors.Is(err, sqldb.ErrDBNotFound) {\nreturn %s{}, fmt.Errorf(\"query: %%w\", %s.%s)\n}\n", g.entity, g.domain, g.notFound)
+		g.writef("return %s{}, fmt.Errorf(\"query: %%w\", err)\n}\n\nreturn v, nil\n}\n", g.entity)
+
+	case name == "ExecuteUnderTransaction":
+		if len(ft.Params.List) != 1 || len(results) != 2 || results[0] != g.domain+".Storer" || results[1] != "error" {
+			return false
+		}
+
+		f := ft.Params.List[0]
+		if len(f.Names) != 1 {
+			return false
+		}
+
+		// The tables have no transactions of their own, so the store takes
+		// part in one by writing straight through and a rollback undoes
+		// nothing.
+		g.writef("\n// ExecuteUnderTransaction returns the store itself, the in-memory tables\n")
+		g.writef("// apply every write as it is made.\n")
+		g.writef("func (s *Store) ExecuteUnderTransaction(%s %s) (%s.Storer, error) {\n", f.Names[0].Name, g.typeOf(f.Type), g.domain)
+		g.writef("return s, nil\n}\n")
+
+		return true
+
+	default:
+		return false
+	}
+
+	g.imports["context"] = true
+
+	return true
+}
+
+func (g *generator) writef(format string, args ...any) {
+	fmt.Fprintf(&g.methods, format, args...)
+}
+
+// setEntity records the entity type the store holds and reports whether
+// the parameter agrees with what was already recorded.
+func (g *generator) setEntity(p param) bool {
+	typ := strings.TrimPrefix(p.typ, "[]")
+
+	if _, exists := g.core.structs[strings.TrimPrefix(typ, g.domain+".")]; !exists {
+		return false
+	}
+
+	if g.entity == "" {
+		g.entity = typ
+	}
+
+	return g.entity == typ
+}
+
+func (g *generator) keyType() (string, error) {
+	st := g.core.structs[strings.TrimPrefix(g.entity, g.domain+".")]
+
+	for _, f := range st.Fields.List {
+		for _, n := range f.Names {
+			if n.Name == g.key {
+				return g.typeOf(f.Type), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("entity %s has no key field %s", g.entity, g.key)
+}
+
+// params returns the parameters of the method after the context.
+func (g *generator) params(ft *ast.FuncType) []param {
+	var params []param
+
+	for i, f := range ft.Params.List {
+		if i == 0 {
+			continue
+		}
+
+		_, slice := f.Type.(*ast.ArrayType)
+		for _, n := range f.Names {
+			params = append(params, param{name: n.Name, typ: g.typeOf(f.Type), slice: slice})
+		}
+	}
+
+	return params
+}
+
+func (g *generator) results(ft *ast.FuncType) []string {
+	if ft.Results == nil {
+		return nil
+	}
+
+	var results []string
+	for _, f := range ft.Results.List {
+		results = append(results, g.typeOf(f.Type))
+	}
+
+	return results
+}
+
+// noun returns the entity type as words for doc comments, e.g. "api key"
+// for APIKey.
+func (g *generator) noun() string {
+	name := strings.TrimPrefix(g.entity, g.domain+".")
+
+	var words []string
+	start := 0
+	for i := 1; i < len(name); i++ {
+		lowerNext := i+1 < len(name) && unicode.IsLower(rune(name[i+1]))
+		if unicode.IsUpper(rune(name[i])) && (unicode.IsLower(rune(name[i-1])) || lowerNext) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+
+	return strings.ToLower(strings.Join(words, " "))
+}
+
+func plural(noun string) string {
+	if base, ok := strings.CutSuffix(noun, "y"); ok {
+		return base + "ies"
+	}
+	return noun + "s"
+}
+
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// typeOf renders the type as it needs to be written in the generated
+// package, qualifying the domain's own types and recording the imports of
+// any other package.
+func (g *generator) typeOf(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if unicode.IsUpper(rune(t.Name[0])) {
+			return g.domain + "." + t.Name
+		}
+		return t.Name
+
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		if path, exists := g.core.imports[pkg]; exists {
+			g.imports[path] = true
+		}
+		return pkg + "." + t.Sel.Name
+
+	case *ast.ArrayType:
+		return "[]" + g.typeOf(t.Elt)
+
+	case *ast.StarExpr:
+		return "*" + g.typeOf(t.X)
+
+	case *ast.MapType:
+		return "map[" + g.typeOf(t.Key) + "]" + g.typeOf(t.Value)
+
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
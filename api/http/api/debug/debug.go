@@ -2,9 +2,13 @@
 package debug
 
 import (
+	"encoding/json"
 	"expvar"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/pprof"
+	"runtime/debug"
 
 	"github.com/arl/statsviz"
 )
@@ -12,8 +16,9 @@ import (
 // Mux registers all the debug routes from the standard library into a new mux
 // bypassing the use of the DefaultServerMux. Using the DefaultServerMux would
 // be a security risk since a dependency could inject a handler into our service
-// without us knowing it.
-func Mux() *http.ServeMux {
+// without us knowing it. The OpenAPI document of the api is served along with
+// a Swagger UI to browse it, when one is given.
+func Mux(build string, spec http.Handler) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -22,8 +27,81 @@ func Mux() *http.ServeMux {
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	mux.Handle("/debug/vars/", expvar.Handler())
+	mux.Handle("/debug/build", buildHandler(build))
+
+	if spec != nil {
+		mux.Handle("/debug/openapi.json", spec)
+		mux.Handle("/debug/swagger", swaggerHandler("/debug/openapi.json"))
+	}
 
 	statsviz.Register(mux)
 
 	return mux
 }
+
+// buildInfo describes the binary that is running, which tells what code a
+// profile was taken against.
+type buildInfo struct {
+	Build     string            `json:"build"`
+	GoVersion string            `json:"goVersion"`
+	Path      string            `json:"path"`
+	Settings  map[string]string `json:"settings"`
+	Deps      map[string]string `json:"deps"`
+}
+
+func buildHandler(build string) http.Handler {
+	info := buildInfo{
+		Build:    build,
+		Settings: map[string]string{},
+		Deps:     map[string]string{},
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = bi.GoVersion
+		info.Path = bi.Path
+
+		for _, s := range bi.Settings {
+			info.Settings[s.Key] = s.Value
+		}
+
+		for _, dep := range bi.Deps {
+			info.Deps[dep.Path] = dep.Version
+		}
+	}
+
+	data, _ := json.MarshalIndent(info, "", "  ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// swaggerUI is the page that loads Swagger UI to browse the document. The
+// assets come from a CDN so the service doesn't have to ship them, which is
+// fine on the debug port only operators reach.
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>API documentation</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = () => { SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" }); };
+  </script>
+</body>
+</html>
+`
+
+func swaggerHandler(specURL string) http.Handler {
+	page := fmt.Sprintf(swaggerUI, specURL)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, page)
+	})
+}
@@ -0,0 +1,31 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// APIKeyHeader is the header machine clients use to send their api key.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey validates authentication of machine clients via an api key.
+func APIKey(log *logger.Logger, core *apikey.Core) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.APIKey(ctx, log, core, r.Header.Get(APIKeyHeader), hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
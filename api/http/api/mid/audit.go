@@ -0,0 +1,86 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/audit"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// maxAuditedBody limits how much of a body will be read into memory to be
+// digested.
+const maxAuditedBody = 1 << 20
+
+// Audit records the request in the audit log. Only a digest of the body is
+// kept so sensitive payloads never reach the log. If no core is provided, no
+// middleware is applied.
+func Audit(core *audit.Core) web.MidHandler {
+	if core == nil {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			entry := audit.Entry{
+				Method: r.Method,
+				Path:   r.URL.Path,
+				Route:  r.Pattern,
+			}
+
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			if r.Body != nil && r.Body != http.NoBody {
+				body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditedBody+1))
+				if err != nil {
+					return errs.Newf(errs.InvalidArgument, "reading body: %s", err)
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+
+				switch {
+				case len(body) > maxAuditedBody:
+
+					// The request is refused without being read any further,
+					// but is still recorded as refused.
+					hdl = func(ctx context.Context) error {
+						return errs.Newf(errs.InvalidArgument, "body exceeds %d bytes", maxAuditedBody)
+					}
+
+				case len(body) > 0:
+					sum := sha256.Sum256(body)
+					entry.BodyDigest = hex.EncodeToString(sum[:])
+				}
+			}
+
+			return mid.Audit(ctx, core, entry, auditStatus(ctx), hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// auditStatus resolves the status code of the request. Errors are mapped the
+// same way the errors middleware will respond with them.
+func auditStatus(ctx context.Context) func(err error) int {
+	return func(err error) int {
+		if err == nil {
+			return web.GetValues(ctx).StatusCode
+		}
+
+		if errs.IsError(err) {
+			return errs.HTTPStatus(errs.GetError(err).Code)
+		}
+
+		return http.StatusInternalServerError
+	}
+}
@@ -3,10 +3,12 @@ package mid
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
 	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/user"
 	"github.com/mrcruz117/al-service/foundation/logger"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
@@ -45,15 +47,16 @@ func Bearer(ath *auth.Auth) web.MidHandler {
 	return m
 }
 
-// Basic processes basic authentication logic.
-func Basic(ath *auth.Auth) web.MidHandler {
+// Basic processes basic authentication logic. The claims are valid for the
+// ttl.
+func Basic(ath *auth.Auth, userCore *user.Core, ttl time.Duration) web.MidHandler {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			hdl := func(ctx context.Context) error {
 				return handler(ctx, w, r)
 			}
 
-			return mid.Basic(ctx, hdl)
+			return mid.Basic(ctx, ath, userCore, ttl, r.Header.Get("authorization"), hdl)
 		}
 
 		return h
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
 	"github.com/mrcruz117/al-service/app/api/mid"
 	"github.com/mrcruz117/al-service/foundation/logger"
@@ -26,3 +27,74 @@ func Authorize(log *logger.Logger, client *authclient.Client, rule string) web.M
 
 	return m
 }
+
+// AuthorizeFailOpen executes the authorize middleware functionality, letting
+// the request through when the auth service is unavailable.
+func AuthorizeFailOpen(log *logger.Logger, client *authclient.Client, rule string) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.AuthorizeFailOpen(ctx, log, client, rule, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeLocal executes the authorize middleware functionality in process.
+func AuthorizeLocal(ath *auth.Auth, rule string) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.AuthorizeLocal(ctx, ath, rule, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeSubject executes the authorize middleware functionality against
+// the user id held by the named path parameter.
+func AuthorizeSubject(log *logger.Logger, client *authclient.Client, param string, rule string) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.AuthorizeSubject(ctx, log, client, web.Param(r, param), rule, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeSubjectLocal executes the authorize middleware functionality in
+// process against the user id held by the named path parameter.
+func AuthorizeSubjectLocal(ath *auth.Auth, param string, rule string) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.AuthorizeSubjectLocal(ctx, ath, web.Param(r, param), rule, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,28 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// CachePolicy sets the Cache-Control header on every response. Handlers can
+// still replace it. If no policy is provided, no middleware is applied.
+func CachePolicy(policy string) web.MidHandler {
+	if policy == "" {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Cache-Control", policy)
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
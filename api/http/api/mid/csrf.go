@@ -0,0 +1,68 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Names used to carry the csrf token between the client and the server.
+const (
+	CSRFCookie = "csrf_token"
+	CSRFField  = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CSRF protects state changing requests from cross site request forgery. A
+// token cookie is issued to any client that doesn't have one and must be
+// echoed back in a form field or header.
+func CSRF() web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			var issued string
+			if cookie, err := r.Cookie(CSRFCookie); err == nil {
+				issued = cookie.Value
+			}
+
+			if issued == "" {
+				token, err := mid.NewCSRFToken()
+				if err != nil {
+					return errs.New(errs.Internal, err)
+				}
+
+				http.SetCookie(w, &http.Cookie{
+					Name:     CSRFCookie,
+					Value:    token,
+					Path:     "/",
+					HttpOnly: true,
+					Secure:   r.TLS != nil,
+					SameSite: http.SameSiteStrictMode,
+				})
+
+				// A client without a token can only be making its first
+				// request so any state change will be rejected.
+				if r.Method == http.MethodGet || r.Method == http.MethodHead {
+					issued = token
+				}
+			}
+
+			submitted := r.Header.Get(CSRFHeader)
+			if submitted == "" {
+				submitted = r.PostFormValue(CSRFField)
+			}
+
+			return mid.CSRF(ctx, r.Method, issued, submitted, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
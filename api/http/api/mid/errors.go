@@ -3,6 +3,7 @@ package mid
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/mrcruz117/al-service/app/api/errs"
 	"github.com/mrcruz117/al-service/app/api/mid"
@@ -10,29 +11,6 @@ import (
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
-var codeStatus [17]int
-
-// init maps out the error codes to http status codes.
-func init() {
-	codeStatus[errs.OK.Value()] = http.StatusOK
-	codeStatus[errs.Canceled.Value()] = http.StatusGatewayTimeout
-	codeStatus[errs.Unknown.Value()] = http.StatusInternalServerError
-	codeStatus[errs.InvalidArgument.Value()] = http.StatusBadRequest
-	codeStatus[errs.DeadlineExceeded.Value()] = http.StatusGatewayTimeout
-	codeStatus[errs.NotFound.Value()] = http.StatusNotFound
-	codeStatus[errs.AlreadyExists.Value()] = http.StatusConflict
-	codeStatus[errs.PermissionDenied.Value()] = http.StatusForbidden
-	codeStatus[errs.ResourceExhausted.Value()] = http.StatusTooManyRequests
-	codeStatus[errs.FailedPrecondition.Value()] = http.StatusBadRequest
-	codeStatus[errs.Aborted.Value()] = http.StatusConflict
-	codeStatus[errs.OutOfRange.Value()] = http.StatusBadRequest
-	codeStatus[errs.Unimplemented.Value()] = http.StatusNotImplemented
-	codeStatus[errs.Internal.Value()] = http.StatusInternalServerError
-	codeStatus[errs.Unavailable.Value()] = http.StatusServiceUnavailable
-	codeStatus[errs.DataLoss.Value()] = http.StatusInternalServerError
-	codeStatus[errs.Unauthenticated.Value()] = http.StatusUnauthorized
-}
-
 // Errors executes the errors middleware functionality
 func Errors(log *logger.Logger) web.MidHandler {
 	m := func(handler web.Handler) web.Handler {
@@ -41,8 +19,10 @@ func Errors(log *logger.Logger) web.MidHandler {
 				return handler(ctx, w, r)
 			}
 			if err := mid.Errors(ctx, log, hdl); err != nil {
-				errs := err.(errs.Error)
-				if err := web.Respond(ctx, w, errs, codeStatus[errs.Code.Value()]); err != nil {
+				appErr := err.(errs.Error)
+				env := errs.NewEnvelope(appErr, web.GetTraceID(ctx), time.Now())
+
+				if err := web.Respond(ctx, w, env, errs.HTTPStatus(appErr.Code)); err != nil {
 					return err
 				}
 			}
@@ -0,0 +1,40 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// MaxInflight rejects requests with a 503 once n handlers are running
+// concurrently. Requests to the routes with one of the exempt patterns are
+// not counted, for routes given a limit of their own. If n is not positive,
+// no middleware is applied.
+func MaxInflight(n int, exempt ...string) web.MidHandler {
+	if n <= 0 {
+		return nil
+	}
+
+	sem := make(chan struct{}, n)
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if slices.Contains(exempt, r.Pattern) {
+				return handler(ctx, w, r)
+			}
+
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.MaxInflight(ctx, sem, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,37 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Maintenance short-circuits requests with a 503 while the service is in
+// maintenance mode. If no mode is provided, no middleware is applied.
+func Maintenance(mode *maintenance.Mode) web.MidHandler {
+	if mode == nil {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			if mode.Blocked(r.URL.Path) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(mode.RetryAfter().Seconds())))
+			}
+
+			return mid.Maintenance(ctx, mode, r.URL.Path, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,525 @@
+package mid_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/auth/authtest"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	appmid "github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/audit"
+	"github.com/mrcruz117/al-service/business/core/audit/stores/auditmem"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/tenant/stores/tenantmem"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/reqsign"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Test_BearerAuthorize checks the tokens the admin rule lets through, with
+// tokens issued in process rather than by the auth service.
+func Test_BearerAuthorize(t *testing.T) {
+	a := authtest.New(t)
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", web.GetTraceID)
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.Errors(log))
+
+	app.HandleFunc("GET /admin", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}, mid.Bearer(a.Auth), mid.AuthorizeLocal(a.Auth, auth.RuleAdminOnly))
+
+	subject := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"admin", "Bearer " + a.Token(t, subject, "ADMIN"), http.StatusNoContent},
+		{"user", "Bearer " + a.Token(t, subject, "USER"), http.StatusUnauthorized},
+		{"unknown-kid", "Bearer " + a.TokenWith(t, "other", auth.Claims{Roles: []string{"ADMIN"}}), http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("Should get status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+// Test_Audit checks a request is recorded with the status it got, including
+// one refused by the authorization after it, and a body too large to digest
+// is refused without reaching the handler.
+func Test_Audit(t *testing.T) {
+	a := authtest.New(t)
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	store := auditmem.NewStore()
+	core := audit.NewCore(log, store, 100, 100)
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.Errors(log))
+
+	var handled string
+	app.HandleFunc("PUT /admin", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		handled = string(body)
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}, mid.Bearer(a.Auth), mid.Audit(core), mid.AuthorizeLocal(a.Auth, auth.RuleAdminOnly))
+
+	subject := uuid.NewString()
+
+	tests := []struct {
+		name    string
+		roles   string
+		body    string
+		status  int
+		handled string
+	}{
+		{"admin", "ADMIN", `{"mode":"on"}`, http.StatusNoContent, `{"mode":"on"}`},
+		{"user", "USER", `{"mode":"on"}`, http.StatusUnauthorized, ""},
+		{"oversized", "ADMIN", strings.Repeat("x", 1<<20+1), http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled = ""
+
+			r := httptest.NewRequest(http.MethodPut, "/admin", strings.NewReader(tt.body))
+			r.Header.Set("Authorization", "Bearer "+a.Token(t, subject, tt.roles))
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			if handled != tt.handled {
+				t.Errorf("Should hand the handler the body %.20q, got %.20q", tt.handled, handled)
+			}
+
+			if err := core.Flush(context.Background()); err != nil {
+				t.Fatalf("Should be able to flush the audit log : %s", err)
+			}
+
+			entries := store.Query()
+			if len(entries) == 0 {
+				t.Fatalf("Should record the request")
+			}
+
+			entry := entries[len(entries)-1]
+			if entry.StatusCode != tt.status || entry.Subject != subject {
+				t.Errorf("Should record the request of %s with status %d, got %s with %d", subject, tt.status, entry.Subject, entry.StatusCode)
+			}
+		})
+	}
+}
+
+// Test_ClientCert checks a service authenticated by its client certificate
+// is granted the scopes of the roles of its identity, so it passes the same
+// rules and scopes a token holding those roles does.
+func Test_ClientCert(t *testing.T) {
+	a := authtest.New(t)
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	peers := map[string][]string{
+		"billing.internal":  {"ADMIN"},
+		"shipping.internal": {"USER"},
+		"relay.internal":    {"RELAY"},
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.Errors(log))
+
+	var claims auth.Claims
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		claims = appmid.GetClaims(ctx)
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app.HandleFunc("GET /admin", handler, mid.ClientCert(peers), mid.AuthorizeLocal(a.Auth, auth.RuleAdminOnly), mid.RequireScope(auth.ScopeAdmin))
+	app.HandleFunc("GET /sales", handler, mid.ClientCert(peers), mid.RequireScope(auth.ScopeSalesRead))
+
+	tests := []struct {
+		name     string
+		path     string
+		identity string
+		status   int
+	}{
+		{"admin", "/admin", "billing.internal", http.StatusNoContent},
+		{"scoped", "/sales", "shipping.internal", http.StatusNoContent},
+		{"not-admin", "/admin", "shipping.internal", http.StatusUnauthorized},
+		{"no-scope", "/sales", "relay.internal", http.StatusForbidden},
+		{"unknown", "/sales", "other.internal", http.StatusUnauthorized},
+		{"no-certificate", "/sales", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims = auth.Claims{}
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.identity != "" {
+				cert := x509.Certificate{DNSNames: []string{tt.identity}}
+				r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{&cert}}}
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+
+			if w.Code == http.StatusNoContent {
+				want := auth.ScopesFor(peers[tt.identity])
+				if strings.Join(claims.Permissions, " ") != strings.Join(want, " ") {
+					t.Errorf("Should grant the scopes %v of the roles, got %v", want, claims.Permissions)
+				}
+			}
+		})
+	}
+}
+
+// Test_MaxInflight checks the requests held open by an exempt route don't
+// take the slots of the limit, which still sheds the other routes.
+func Test_MaxInflight(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.Errors(log), mid.MaxInflight(1, "GET /poll"))
+
+	release := make(chan struct{})
+	held := make(chan struct{}, 2)
+
+	hold := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		held <- struct{}{}
+		<-release
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app.HandleFunc("GET /poll", hold)
+	app.HandleFunc("GET /hold", hold)
+	app.HandleFunc("GET /other", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	})
+
+	call := func(path string) int {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	var wg sync.WaitGroup
+	start := func(path string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			call(path)
+		}()
+		<-held
+	}
+
+	start("/poll")
+	start("/poll")
+
+	if status := call("/other"); status != http.StatusNoContent {
+		t.Errorf("Should not count the polls held open, got %d", status)
+	}
+
+	start("/hold")
+
+	if status := call("/other"); status != http.StatusServiceUnavailable {
+		t.Errorf("Should shed a request once the limit is reached, got %d", status)
+	}
+
+	close(release)
+	wg.Wait()
+}
+
+// Test_Tenant checks an authenticated request is scoped to the tenant of
+// its claims whatever the header or the host say, and the header only names
+// the tenant of a request that isn't authenticated.
+func Test_Tenant(t *testing.T) {
+	a := authtest.New(t)
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", web.GetTraceID)
+	ctx := context.Background()
+
+	core := tenant.NewCore(log, tenantmem.NewStore())
+
+	acme, err := core.Create(ctx, tenant.NewTenant{Slug: "acme", Name: "Acme"})
+	if err != nil {
+		t.Fatalf("Should be able to create a tenant : %s", err)
+	}
+
+	other, err := core.Create(ctx, tenant.NewTenant{Slug: "other", Name: "Other"})
+	if err != nil {
+		t.Fatalf("Should be able to create a tenant : %s", err)
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.Errors(log))
+
+	// The handlers respond with the slugs of the tenants whose data the
+	// request can access, none standing for the data of no tenant.
+	access := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		var slugs string
+		for _, tnt := range []tenant.Tenant{acme, other, {Slug: "none"}} {
+			if tenant.CheckAccess(ctx, tnt.ID) == nil {
+				slugs += tnt.Slug + " "
+			}
+		}
+
+		return web.Respond(ctx, w, strings.TrimSpace(slugs), http.StatusOK)
+	}
+
+	app.HandleFunc("GET /tenant", access, mid.Bearer(a.Auth), mid.Tenant(core))
+	app.HandleFunc("GET /public", access, mid.Tenant(core))
+
+	token := func(role string, tenantID string) string {
+		return "Bearer " + a.TokenWith(t, authtest.DefaultKID, auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()},
+			Roles:            []string{role},
+			Tenant:           tenantID,
+		})
+	}
+
+	user := token("USER", acme.ID.String())
+
+	tests := []struct {
+		name   string
+		path   string
+		host   string
+		auth   string
+		header string
+		status int
+		access string
+	}{
+		{"claim", "/tenant", "", user, "", http.StatusOK, "acme"},
+		{"claim-header-id", "/tenant", "", user, acme.ID.String(), http.StatusOK, "acme"},
+		{"claim-header-slug", "/tenant", "", user, "acme", http.StatusOK, "acme"},
+		{"claim-subdomain", "/tenant", "other.example.com", user, "", http.StatusOK, "acme"},
+		{"claim-header-other", "/tenant", "", user, other.ID.String(), http.StatusForbidden, ""},
+		{"claim-header-unknown", "/tenant", "", user, "nope", http.StatusForbidden, ""},
+		{"no-claim-header", "/tenant", "", token("USER", ""), "acme", http.StatusForbidden, ""},
+		{"no-claim-subdomain", "/tenant", "acme.example.com", token("USER", ""), "", http.StatusOK, ""},
+		{"no-claim-admin", "/tenant", "", token("ADMIN", ""), "", http.StatusOK, "acme other none"},
+		{"public-header", "/public", "", "", "other", http.StatusOK, "other"},
+		{"public-subdomain", "/public", "acme.example.com", "", "", http.StatusOK, "acme"},
+		{"public-none", "/public", "", "", "", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			if tt.header != "" {
+				r.Header.Set(mid.TenantHeader, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+
+			if tt.status == http.StatusOK && w.Body.String() != `"`+tt.access+`"` {
+				t.Errorf("Should access the data of %q, got %s", tt.access, w.Body)
+			}
+		})
+	}
+}
+
+// Test_VerifyServiceSignature checks only the requests signed by a service
+// holding a secret reach the handler, unchanged and recently signed.
+func Test_VerifyServiceSignature(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", web.GetTraceID)
+
+	secrets := map[string][]byte{"sales": []byte("sales-secret")}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.Errors(log))
+
+	app.HandleFunc("POST /signed", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}, mid.VerifyServiceSignature(secrets, time.Minute))
+
+	const body = `{"rule":"ADMIN_ONLY"}`
+
+	signed := func(secret string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/signed", strings.NewReader(body))
+		if err := reqsign.NewSigner("sales", []byte(secret)).Sign(r); err != nil {
+			t.Fatalf("Should be able to sign the request : %s", err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		req    func() *http.Request
+		status int
+	}{
+		{"signed", func() *http.Request { return signed("sales-secret") }, http.StatusNoContent},
+		{"unsigned", func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/signed", strings.NewReader(body))
+		}, http.StatusUnauthorized},
+		{"wrong-key", func() *http.Request { return signed("not-the-secret") }, http.StatusUnauthorized},
+		{"tampered-body", func() *http.Request {
+			r := signed("sales-secret")
+			r.Body = io.NopCloser(strings.NewReader(`{"rule":"ANY"}`))
+			return r
+		}, http.StatusUnauthorized},
+		{"replayed", func() *http.Request {
+			r := signed("sales-secret")
+			r.Header.Set(reqsign.TimestampHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+			return r
+		}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, tt.req())
+
+			if w.Code != tt.status {
+				t.Errorf("Should get status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+// Test_VerifySignature checks a webhook is only accepted when signed by
+// the partner named in the path, recently and the first time it is sent.
+func Test_VerifySignature(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", web.GetTraceID)
+
+	secrets := map[string][]byte{"acme": []byte("acme-secret")}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, mid.Errors(log))
+
+	app.HandleFunc("POST /webhooks/{partner}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}, mid.VerifySignature(secrets, 5*time.Minute))
+
+	const body = `{"id":"1","type":"ping"}`
+
+	type delivery struct {
+		partner   string
+		secret    string
+		timestamp string
+		body      string
+	}
+
+	send := func(d delivery) int {
+		r := httptest.NewRequest(http.MethodPost, "/webhooks/"+d.partner, strings.NewReader(d.body))
+		r.Header.Set(mid.SignatureTimestampHeader, d.timestamp)
+		r.Header.Set(mid.SignatureHeader, appmid.Sign([]byte(d.secret), d.timestamp, []byte(body)))
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	unix := func(at time.Time) string {
+		return strconv.FormatInt(at.Unix(), 10)
+	}
+
+	now := time.Now()
+	valid := delivery{"acme", "acme-secret", unix(now), body}
+
+	if status := send(valid); status != http.StatusNoContent {
+		t.Fatalf("Should accept the signed webhook, got %d", status)
+	}
+
+	tests := []struct {
+		name string
+		d    delivery
+	}{
+		{"replayed", valid},
+		{"bad-signature", delivery{"acme", "not-the-secret", unix(now), body}},
+		{"unknown-partner", delivery{"other", "acme-secret", unix(now), body}},
+		{"tampered-body", delivery{"acme", "acme-secret", unix(now.Add(time.Second)), `{"id":"2","type":"ping"}`}},
+		{"expired", delivery{"acme", "acme-secret", unix(now.Add(-time.Hour)), body}},
+		{"future", delivery{"acme", "acme-secret", unix(now.Add(time.Hour)), body}},
+		{"no-timestamp", delivery{"acme", "acme-secret", "", body}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := send(tt.d); status != http.StatusUnauthorized {
+				t.Errorf("Should refuse the webhook, got %d", status)
+			}
+		})
+	}
+
+	// The same webhook signed again is a new delivery.
+	if status := send(delivery{"acme", "acme-secret", unix(now.Add(2 * time.Second)), body}); status != http.StatusNoContent {
+		t.Errorf("Should accept the webhook signed again, got %d", status)
+	}
+}
+
+// Benchmark_Chain measures the middleware every request of the services
+// goes through, for a request that succeeds and one that fails. The logs are
+// encoded but thrown away so their cost is included.
+func Benchmark_Chain(b *testing.B) {
+	log := logger.New(io.Discard, logger.LevelInfo, "BENCH", web.GetTraceID)
+
+	app := web.NewApp(
+		func(context.Context, string, ...any) {},
+		mid.Logger(log),
+		mid.Errors(log),
+		mid.Metrics(),
+		mid.Panics(),
+	)
+
+	app.HandleFunc("GET /ok", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return web.Respond(ctx, w, struct {
+			Status string `json:"status"`
+		}{Status: "ok"}, http.StatusOK)
+	})
+
+	app.HandleFunc("GET /fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errs.Newf(errs.NotFound, "not found")
+	})
+
+	for _, path := range []string{"/ok", "/fail"} {
+		b.Run(path[1:], func(b *testing.B) {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				app.ServeHTTP(httptest.NewRecorder(), r)
+			}
+		})
+	}
+}
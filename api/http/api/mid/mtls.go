@@ -0,0 +1,27 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// ClientCert validates authentication of services via the client
+// certificate presented on the TLS connection.
+func ClientCert(identities map[string][]string) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.ClientCert(ctx, r.TLS, identities, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
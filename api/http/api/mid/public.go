@@ -0,0 +1,12 @@
+package mid
+
+import (
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Public declares that a route intentionally requires no authentication. It
+// adds no behavior to the handler and exists so the authorization matrix can
+// tell a deliberate public route from a forgotten one.
+func Public() web.MidHandler {
+	return nil
+}
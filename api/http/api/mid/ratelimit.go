@@ -0,0 +1,42 @@
+package mid
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/app/api/ratelimit"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// RateLimit limits requests per authenticated subject, falling back to the
+// client address for anonymous requests. If no limiter is provided, no
+// middleware is applied.
+func RateLimit(limiter ratelimit.Limiter) web.MidHandler {
+	if limiter == nil {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := mid.GetClaims(ctx).Subject
+			if key == "" {
+				key = r.RemoteAddr
+				if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+					key = host
+				}
+			}
+
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.RateLimit(ctx, limiter, key, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,49 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/reqsign"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// VerifyServiceSignature validates the signature headers added by the
+// calling service's reqsign.Signer. If no secrets are configured, no
+// middleware is applied.
+func VerifyServiceSignature(secrets map[string][]byte, maxSkew time.Duration) web.MidHandler {
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+			if err != nil {
+				return errs.New(errs.InvalidArgument, fmt.Errorf("reading body: %w", err))
+			}
+
+			if len(body) > maxSignedBody {
+				return errs.Newf(errs.InvalidArgument, "body exceeds %d bytes", maxSignedBody)
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.VerifyServiceSignature(ctx, secrets, maxSkew, reqsign.FromRequest(r, body), hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
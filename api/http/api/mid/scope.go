@@ -0,0 +1,34 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// RequireScope executes the scope middleware functionality. A scope that
+// isn't in the catalog is a programming error, so it panics when the routes
+// are bound rather than rejecting every request.
+func RequireScope(scope string) web.MidHandler {
+	if !auth.IsScope(scope) {
+		panic(fmt.Sprintf("mid: unknown scope %q", scope))
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.RequireScope(ctx, scope, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,38 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// SessionCookie is the name of the cookie holding the session token.
+const SessionCookie = "session"
+
+// Session validates authentication via the auth service using the token
+// stored in the session cookie. Clients without a session are redirected
+// to the login page.
+func Session(log *logger.Logger, client *authclient.Client, loginURL string) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			cookie, err := r.Cookie(SessionCookie)
+			if err != nil || cookie.Value == "" {
+				return web.Redirect(ctx, w, r, loginURL, http.StatusSeeOther)
+			}
+
+			return mid.Authenticate(ctx, log, client, "Bearer "+cookie.Value, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
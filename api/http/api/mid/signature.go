@@ -0,0 +1,57 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Headers carrying the request signature and the unix time it was made at.
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// maxSignedBody limits how much of a signed body will be read into memory.
+const maxSignedBody = 1 << 20
+
+// VerifySignature validates the X-Signature header against the body and the
+// X-Signature-Timestamp header using the secret of the partner named by the
+// {partner} path parameter. A request signed more than maxAge away from now,
+// or sent again, is refused. The body is restored so the handler can decode
+// it.
+func VerifySignature(secrets map[string][]byte, maxAge time.Duration) web.MidHandler {
+	replays := mid.NewReplays(maxAge)
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+			if err != nil {
+				return errs.New(errs.InvalidArgument, fmt.Errorf("reading body: %w", err))
+			}
+
+			if len(body) > maxSignedBody {
+				return errs.Newf(errs.InvalidArgument, "body exceeds %d bytes", maxSignedBody)
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.VerifySignature(ctx, secrets, replays, web.Param(r, "partner"), r.Header.Get(SignatureTimestampHeader), r.Header.Get(SignatureHeader), body, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
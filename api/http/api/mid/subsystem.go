@@ -0,0 +1,32 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Paused responds with a 503 while the subsystem is paused so callers retry
+// later. If no switch is provided, no middleware is applied.
+func Paused(sw *subsystem.Switch) web.MidHandler {
+	if sw == nil {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.Paused(ctx, sw, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
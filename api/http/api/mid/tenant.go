@@ -0,0 +1,31 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// TenantHeader is the header clients use to name the tenant they act for.
+const TenantHeader = "X-Tenant-ID"
+
+// Tenant resolves the tenant of the request and scopes the context to it.
+// It must run after authentication so the claims can be consulted.
+func Tenant(core *tenant.Core) web.MidHandler {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.Tenant(ctx, core, r.Header.Get(TenantHeader), r.Host, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,60 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/adaptive"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Timeout bounds how long the handler can run. If the timeout is not
+// positive, no middleware is applied.
+func Timeout(timeout time.Duration) web.MidHandler {
+	if timeout <= 0 {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.Timeout(ctx, timeout, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AdaptiveTimeout bounds how long the handler can run using the timeout
+// derived from the route's observed latency, with max as the upper bound.
+// Without a tuner this behaves like Timeout.
+func AdaptiveTimeout(tuner *adaptive.Tuner, max time.Duration) web.MidHandler {
+	if tuner == nil {
+		return Timeout(max)
+	}
+
+	if max <= 0 {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, w, r)
+			}
+
+			return mid.AdaptiveTimeout(ctx, tuner, r.Pattern, max, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
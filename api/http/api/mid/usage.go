@@ -0,0 +1,56 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Usage records usage for the route against the authenticated client. If
+// no core is provided, no middleware is applied.
+func Usage(core *usage.Core) web.MidHandler {
+	if core == nil {
+		return nil
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			cw := countWriter{ResponseWriter: w}
+
+			hdl := func(ctx context.Context) error {
+				return handler(ctx, &cw, r)
+			}
+
+			bytes := func() int64 {
+				return max(r.ContentLength, 0) + cw.bytes
+			}
+
+			return mid.Usage(ctx, core, r.Pattern, bytes, hdl)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// countWriter counts the number of bytes written in the response.
+type countWriter struct {
+	http.ResponseWriter
+	bytes int64
+}
+
+func (cw *countWriter) Write(b []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(b)
+	cw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the underlying writer so http.ResponseController can reach
+// it.
+func (cw *countWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -4,22 +4,86 @@ package mux
 
 import (
 	"context"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/api/http/domain/eventapi"
 	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+	"github.com/mrcruz117/al-service/app/api/posture"
+	"github.com/mrcruz117/al-service/app/api/session"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/business/core/audit"
+	"github.com/mrcruz117/al-service/business/core/client"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/business/core/event"
+	"github.com/mrcruz117/al-service/business/core/group"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/preference"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/registry"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/usertoken"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+	"github.com/mrcruz117/al-service/foundation/feed"
 	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/notify"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+	"github.com/mrcruz117/al-service/foundation/warmup"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Build      string
-	Log        *logger.Logger
-	Auth       *auth.Auth
-	AuthClient *authclient.Client
-	DB         *sqlx.DB
+	Name            string
+	Build           string
+	Log             *logger.Logger
+	Auth            *auth.Auth
+	ActiveKID       string
+	TokenTTL        time.Duration
+	ServiceTTL      time.Duration
+	Sessions        *session.Store
+	SessionClients  []string
+	AuthClient      *authclient.Client
+	DB              *sqlx.DB
+	Warmup          *warmup.Warmup
+	Maintenance     *maintenance.Mode
+	Usage           *usage.Core
+	Audit           *audit.Core
+	APIKey          *apikey.Core
+	UserCore        *user.Core
+	ClientCore      *client.Core
+	ProductCore     *product.Core
+	OrderCore       *order.Core
+	VProductCore    *vproduct.Core
+	EntityAuditCore *entityaudit.Core
+	GroupCore       *group.Core
+	Tenant          *tenant.Core
+	UserToken       *usertoken.Core
+	Preference      *preference.Core
+	Notify          *notify.Queue
+	Feed            *feed.Feed
+	Events          *event.Core
+	Registry        *registry.Core
+	StaleAfter      time.Duration
+	Posture         *posture.Posture
+	Subsystems      *subsystem.Set
+	PollMaxWait     time.Duration
+	PollMaxEvents   int
+	PollMaxPolls    int
+	LinkBase        string
+	MaxInflight     int
+	Experimental    []string
+	Webhooks        map[string][]byte
+	WebhookMaxAge   time.Duration
+	Peers           map[string][]string
+	ServiceSecrets  map[string][]byte
+	ServiceMaxSkew  time.Duration
+	RouteMiddleware func(pattern string) []web.MidHandler
 }
 
 // RouteAdder defines behavior that sets the routes to bind for an instance
@@ -41,9 +105,19 @@ func WebAPI(cfg Config, routeAdder RouteAdder) *web.App {
 		mid.Errors(cfg.Log),
 		mid.Metrics(),
 		mid.Panics(),
+		mid.MaxInflight(cfg.MaxInflight, eventapi.PollRoute),
+		mid.Maintenance(cfg.Maintenance),
 	)
 
+	app.EnableExperimental(cfg.Experimental...)
+
+	if cfg.RouteMiddleware != nil {
+		app.UseRouteMiddleware(cfg.RouteMiddleware)
+	}
+
 	routeAdder.Add(app, cfg)
 
+	handleOpenAPI(app, cfg.Name, cfg.Build)
+
 	return app
 }
@@ -0,0 +1,53 @@
+package mux
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/foundation/openapi"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// OpenAPI builds the OpenAPI document of the routes registered with the
+// app. Routes without a method, like the ones serving files, aren't part of
+// the api and are left out.
+func OpenAPI(app *web.App, title string, version string) *openapi.Document {
+	doc := openapi.New(title, version, errs.Envelope{})
+
+	for _, route := range app.Routes() {
+		method, path, found := strings.Cut(route.Pattern, " ")
+		if !found {
+			continue
+		}
+
+		path = strings.TrimSuffix(path, "{$}")
+		path = strings.ReplaceAll(path, "...}", "}")
+
+		doc.Add(method, path, route.Doc)
+	}
+
+	return doc
+}
+
+// handleOpenAPI serves the OpenAPI document of the app. The document is
+// built on the first request, once every route has been registered.
+func handleOpenAPI(app *web.App, title string, version string) {
+	doc := sync.OnceValue(func() http.Handler {
+		return OpenAPI(app, title, version).Handler()
+	})
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		doc().ServeHTTP(w, r)
+		return nil
+	}
+
+	app.HandleFuncNoMiddleware("GET /openapi.json", h)
+	app.Document("GET /openapi.json", openapi.Operation{
+		Summary: "The OpenAPI description of this api",
+		Tags:    []string{"docs"},
+		Public:  true,
+	})
+}
@@ -0,0 +1,220 @@
+// Package routecfg provides support for configuring the middleware applied to
+// groups of routes at startup. Operators tune the protection of each group per
+// environment, such as the authorization rule, the rate limit tier, the
+// timeout and the cache policy, without a code change.
+//
+// A configuration looks like:
+//
+//	tiers:
+//	  standard:
+//	    requests: 600
+//	    window: 1m
+//	groups:
+//	  - name: admin
+//	    prefix: /admin/
+//	    rule: rule_admin_only
+//	    rateLimit: standard
+//	    timeout: 5s
+//	    cache: no-store
+//
+// A group marked adaptive derives the timeout of each of its routes from the
+// latency the route is observed to have, with timeout as the upper bound.
+//
+// A route belongs to the group with the longest prefix matching its path.
+package routecfg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/adaptive"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/ratelimit"
+	"github.com/mrcruz117/al-service/foundation/cachestore"
+	"github.com/mrcruz117/al-service/foundation/web"
+	"gopkg.in/yaml.v3"
+)
+
+// rules are the authorization rules a group can require. Rules that need a
+// target user can't be expressed for a whole group.
+var rules = []string{
+	auth.RuleAny,
+	auth.RuleAdminOnly,
+	auth.RuleUserOnly,
+}
+
+// Tier represents a rate limit shared by the groups that reference it.
+type Tier struct {
+	Requests int           `yaml:"requests"`
+	Window   time.Duration `yaml:"window"`
+}
+
+// Group represents the middleware applied to the routes under the prefix.
+type Group struct {
+	Name      string        `yaml:"name"`
+	Prefix    string        `yaml:"prefix"`
+	Rule      string        `yaml:"rule"`
+	RateLimit string        `yaml:"rateLimit"`
+	Timeout   time.Duration `yaml:"timeout"`
+	Adaptive  bool          `yaml:"adaptive"`
+	Cache     string        `yaml:"cache"`
+}
+
+// Config represents the middleware configuration of a service.
+type Config struct {
+	Tiers  map[string]Tier `yaml:"tiers"`
+	Groups []Group         `yaml:"groups"`
+}
+
+// Load reads the configuration from the file. An empty path returns an empty
+// configuration.
+func Load(file string) (Config, error) {
+	if file == "" {
+		return Config{}, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Config{}, fmt.Errorf("read: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes and validates the configuration.
+func Parse(data []byte) (Config, error) {
+	var cfg Config
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("decode: %w", err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (cfg Config) validate() error {
+	for name, t := range cfg.Tiers {
+		if t.Requests <= 0 || t.Window <= 0 {
+			return fmt.Errorf("tier[%s]: requests and window must be positive", name)
+		}
+	}
+
+	names := make(map[string]bool, len(cfg.Groups))
+
+	for _, g := range cfg.Groups {
+		switch {
+		case g.Name == "":
+			return fmt.Errorf("group with prefix %q: name is required", g.Prefix)
+		case names[g.Name]:
+			return fmt.Errorf("group[%s]: defined more than once", g.Name)
+		case !strings.HasPrefix(g.Prefix, "/"):
+			return fmt.Errorf("group[%s]: prefix must begin with /", g.Name)
+		case g.Rule != "" && !slices.Contains(rules, g.Rule):
+			return fmt.Errorf("group[%s]: unknown rule %q", g.Name, g.Rule)
+		case g.Timeout < 0:
+			return fmt.Errorf("group[%s]: timeout can't be negative", g.Name)
+		case g.Adaptive && g.Timeout == 0:
+			return fmt.Errorf("group[%s]: adaptive requires a timeout to bound it", g.Name)
+		}
+
+		if g.RateLimit != "" {
+			if _, exists := cfg.Tiers[g.RateLimit]; !exists {
+				return fmt.Errorf("group[%s]: unknown rate limit tier %q", g.Name, g.RateLimit)
+			}
+		}
+
+		names[g.Name] = true
+	}
+
+	return nil
+}
+
+// Options provides the systems the middleware is built from.
+type Options struct {
+
+	// Authorize constructs the middleware enforcing a rule, which differs
+	// between a service calling the auth service and the auth service itself.
+	Authorize func(rule string) web.MidHandler
+
+	// Cache, when set, shares the rate limit counters across instances.
+	Cache *cachestore.Store
+
+	// Tuner, when set, derives the timeouts of the adaptive groups.
+	Tuner *adaptive.Tuner
+}
+
+// Middleware interprets the configuration and returns the function that
+// resolves the middleware for a route pattern. Every route in a group shares
+// the group's rate limit allowance.
+func Middleware(cfg Config, opts Options) func(pattern string) []web.MidHandler {
+	type stack struct {
+		prefix string
+		mw     []web.MidHandler
+	}
+
+	stacks := make([]stack, 0, len(cfg.Groups))
+
+	for _, g := range cfg.Groups {
+		var mw []web.MidHandler
+
+		if g.Rule != "" && opts.Authorize != nil {
+			mw = append(mw, opts.Authorize(g.Rule))
+		}
+
+		if g.RateLimit != "" {
+			tier := cfg.Tiers[g.RateLimit]
+
+			var limiter ratelimit.Limiter = ratelimit.NewLocal(tier.Requests, tier.Window)
+			if opts.Cache != nil {
+				limiter = ratelimit.NewShared(opts.Cache, "ratelimit:"+g.Name+":", tier.Requests, tier.Window)
+			}
+
+			mw = append(mw, mid.RateLimit(limiter))
+		}
+
+		timeout := mid.Timeout(g.Timeout)
+		if g.Adaptive {
+			timeout = mid.AdaptiveTimeout(opts.Tuner, g.Timeout)
+		}
+
+		mw = append(mw, timeout, mid.CachePolicy(g.Cache))
+
+		stacks = append(stacks, stack{prefix: g.Prefix, mw: mw})
+	}
+
+	// Longest prefix first so the most specific group wins.
+	slices.SortStableFunc(stacks, func(a, b stack) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+
+	f := func(pattern string) []web.MidHandler {
+		path := pattern
+		if _, p, found := strings.Cut(pattern, " "); found {
+			path = p
+		}
+
+		for _, s := range stacks {
+			if strings.HasPrefix(path, s.prefix) {
+				return s.mw
+			}
+		}
+
+		return nil
+	}
+
+	return f
+}
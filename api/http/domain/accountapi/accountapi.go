@@ -0,0 +1,285 @@
+// Package accountapi maintains the web based api for the self service
+// account flows of email verification and password resets.
+package accountapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/app/api/session"
+	"github.com/mrcruz117/al-service/business/core/preference"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/usertoken"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/notify"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log       *logger.Logger
+	userCore  *user.Core
+	tokenCore *usertoken.Core
+	prefCore  *preference.Core
+	sessions  *session.Store
+	notify    *notify.Queue
+	linkBase  string
+}
+
+func newAPI(log *logger.Logger, userCore *user.Core, tokenCore *usertoken.Core, prefCore *preference.Core, sessions *session.Store, notify *notify.Queue, linkBase string) *api {
+	return &api{
+		log:       log,
+		userCore:  userCore,
+		tokenCore: tokenCore,
+		prefCore:  prefCore,
+		sessions:  sessions,
+		notify:    notify,
+		linkBase:  linkBase,
+	}
+}
+
+func (api *api) requestVerifyEmail(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	usr, err := api.userCore.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	if usr.EmailVerified {
+		return errs.Newf(errs.FailedPrecondition, "email is already verified")
+	}
+
+	token, err := api.tokenCore.Issue(ctx, usr.ID, usertoken.VerifyEmail)
+	if err != nil {
+		if errors.Is(err, usertoken.ErrRateLimited) {
+			return errs.New(errs.ResourceExhausted, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	msg := notify.Message{
+		To:      usr.Email.Address,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Confirm your email address by visiting the link below.\n\n%s\n", api.link("verify-email", token)),
+	}
+
+	if err := api.notify.Enqueue(msg); err != nil {
+		return errs.New(errs.Unavailable, err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+func (api *api) confirmVerifyEmail(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var ct confirmToken
+	if err := web.Decode(r, &ct); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	tkn, err := api.tokenCore.Consume(ctx, usertoken.VerifyEmail, ct.Token)
+	if err != nil {
+		return tokenError(err)
+	}
+
+	usr, err := api.userCore.QueryByID(ctx, tkn.UserID)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	verified := true
+	if _, err := api.userCore.Update(ctx, usr, user.UpdateUser{EmailVerified: &verified}); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// requestPasswordReset always reports success so the endpoint can't be used
+// to discover which email addresses have accounts.
+func (api *api) requestPasswordReset(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var rr resetRequest
+	if err := web.Decode(r, &rr); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := api.sendPasswordReset(ctx, rr.Email); err != nil {
+		api.log.Info(ctx, "account", "status", "password reset not sent", "msg", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+func (api *api) sendPasswordReset(ctx context.Context, email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return err
+	}
+
+	usr, err := api.userCore.QueryByEmail(ctx, *addr)
+	if err != nil {
+		return err
+	}
+
+	if !usr.Enabled {
+		return user.ErrUserDisabled
+	}
+
+	token, err := api.tokenCore.Issue(ctx, usr.ID, usertoken.ResetPassword)
+	if err != nil {
+		return err
+	}
+
+	msg := notify.Message{
+		To:      usr.Email.Address,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("A password reset was requested for your account. Choose a new password by visiting the link below. If you didn't request this you can ignore this email.\n\n%s\n", api.link("reset-password", token)),
+	}
+
+	return api.notify.Enqueue(msg)
+}
+
+func (api *api) confirmPasswordReset(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var rc resetConfirm
+	if err := web.Decode(r, &rc); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	tkn, err := api.tokenCore.Consume(ctx, usertoken.ResetPassword, rc.Token)
+	if err != nil {
+		return tokenError(err)
+	}
+
+	usr, err := api.userCore.QueryByID(ctx, tkn.UserID)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	if !usr.Enabled {
+		return errs.New(errs.FailedPrecondition, user.ErrUserDisabled)
+	}
+
+	if _, err := api.userCore.Update(ctx, usr, user.UpdateUser{Password: &rc.Password}); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	// Any other reset links still sitting in the user's inbox are now stale.
+	if err := api.tokenCore.RevokeAll(ctx, usr.ID, usertoken.ResetPassword); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	// Whoever knew the old password may still be signed in with it.
+	if api.sessions != nil {
+		n, err := api.sessions.RevokeUser(ctx, usr.ID.String())
+		if err != nil {
+			return errs.New(errs.Internal, err)
+		}
+
+		api.log.Info(ctx, "password reset: sessions revoked", "userID", usr.ID, "revoked", n)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+func (api *api) link(path string, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", api.linkBase, path, url.QueryEscape(token))
+}
+
+func tokenError(err error) error {
+	switch {
+	case errors.Is(err, usertoken.ErrInvalid),
+		errors.Is(err, usertoken.ErrExpired),
+		errors.Is(err, usertoken.ErrUsed):
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	return errs.New(errs.Internal, err)
+}
+
+func (api *api) queryPreferences(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.prefCore == nil {
+		return errs.Newf(errs.Unimplemented, "preferences are not enabled")
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	prefs, err := api.prefCore.Query(ctx, userID)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return api.respondPreferences(ctx, w, prefs)
+}
+
+// savePreferences replaces the user's settings. The client has to send the
+// version it last read in If-Match, or "0" for a user with no settings, so
+// two devices saving at once can't silently overwrite each other.
+func (api *api) savePreferences(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.prefCore == nil {
+		return errs.Newf(errs.Unimplemented, "preferences are not enabled")
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	tag, ok := web.IfMatch(r)
+	if !ok {
+		return errs.Newf(errs.FailedPrecondition, "If-Match with the current version is required")
+	}
+
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 0 {
+		return errs.Newf(errs.InvalidArgument, "If-Match: %q is not a version", tag)
+	}
+
+	var sp savePreferences
+	if err := web.Decode(r, &sp); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prefs, err := api.prefCore.Save(ctx, userID, sp.Preferences, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, preference.ErrInvalid), errors.Is(err, preference.ErrTooLarge):
+			return errs.New(errs.InvalidArgument, err)
+		case errors.Is(err, preference.ErrVersionConflict):
+			return errs.New(errs.Aborted, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return api.respondPreferences(ctx, w, prefs)
+}
+
+func (api *api) respondPreferences(ctx context.Context, w http.ResponseWriter, prefs preference.Preferences) error {
+	resp := preferences{
+		Version:     prefs.Version,
+		Preferences: prefs.Values,
+	}
+
+	if !prefs.DateUpdated.IsZero() {
+		resp.DateUpdated = prefs.DateUpdated.Format(time.RFC3339)
+	}
+
+	w.Header().Set("ETag", web.ETag(strconv.Itoa(prefs.Version)))
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
@@ -0,0 +1,87 @@
+package accountapi
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+// minPasswordLength is the shortest password accepted on a reset.
+const minPasswordLength = 8
+
+// confirmToken represents a token presented to confirm an email address.
+type confirmToken struct {
+	Token string `json:"token"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (ct confirmToken) Validate() error {
+	var fe errs.FieldErrors
+
+	if ct.Token == "" {
+		fe.Add("token", "is required")
+	}
+
+	return fe.ToError()
+}
+
+// resetRequest represents a request for a password reset link.
+type resetRequest struct {
+	Email string `json:"email"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (rr resetRequest) Validate() error {
+	var fe errs.FieldErrors
+
+	if _, err := mail.ParseAddress(rr.Email); err != nil {
+		fe.Add("email", err.Error())
+	}
+
+	return fe.ToError()
+}
+
+// resetConfirm represents a token presented with the new password.
+type resetConfirm struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (rc resetConfirm) Validate() error {
+	var fe errs.FieldErrors
+
+	if rc.Token == "" {
+		fe.Add("token", "is required")
+	}
+
+	if len(rc.Password) < minPasswordLength {
+		fe.Add("password", fmt.Sprintf("must be at least %d characters", minPasswordLength))
+	}
+
+	return fe.ToError()
+}
+
+// preferences represents the settings document of a user.
+type preferences struct {
+	Version     int            `json:"version"`
+	Preferences map[string]any `json:"preferences"`
+	DateUpdated string         `json:"dateUpdated,omitempty"`
+}
+
+// savePreferences represents the document replacing a user's settings.
+type savePreferences struct {
+	Preferences map[string]any `json:"preferences"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (sp savePreferences) Validate() error {
+	var fe errs.FieldErrors
+
+	if sp.Preferences == nil {
+		fe.Add("preferences", "is required")
+	}
+
+	return fe.ToError()
+}
@@ -0,0 +1,40 @@
+package accountapi
+
+import (
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/session"
+	"github.com/mrcruz117/al-service/business/core/preference"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/usertoken"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/notify"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	Auth       *auth.Auth
+	UserCore   *user.Core
+	TokenCore  *usertoken.Core
+	Preference *preference.Core
+	Sessions   *session.Store
+	Notify     *notify.Queue
+	LinkBase   string
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	bearer := mid.Bearer(cfg.Auth)
+	public := mid.Public()
+
+	api := newAPI(cfg.Log, cfg.UserCore, cfg.TokenCore, cfg.Preference, cfg.Sessions, cfg.Notify, cfg.LinkBase)
+
+	app.HandleFunc("POST /account/verify-email/request", api.requestVerifyEmail, bearer)
+	app.HandleFunc("POST /account/verify-email/confirm", api.confirmVerifyEmail, public)
+	app.HandleFunc("POST /account/password-reset/request", api.requestPasswordReset, public)
+	app.HandleFunc("POST /account/password-reset/confirm", api.confirmPasswordReset, public)
+	app.HandleFunc("GET /account/preferences", api.queryPreferences, bearer)
+	app.HandleFunc("PUT /account/preferences", api.savePreferences, bearer)
+}
@@ -0,0 +1,741 @@
+// Package adminapi maintains the web based api for operational administration.
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/expand"
+	"github.com/mrcruz117/al-service/app/api/fields"
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/app/api/posture"
+	"github.com/mrcruz117/al-service/business/api/precondition"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/business/core/event"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/feed"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log         *logger.Logger
+	authClient  *authclient.Client
+	maintenance *maintenance.Mode
+	usage       *usage.Core
+	apiKey      *apikey.Core
+	tenant      *tenant.Core
+	feed        *feed.Feed
+	events      *event.Core
+	posture     *posture.Posture
+	subsystems  *subsystem.Set
+}
+
+func newAPI(log *logger.Logger, authClient *authclient.Client, maintenance *maintenance.Mode, usage *usage.Core, apiKey *apikey.Core, tenant *tenant.Core, feed *feed.Feed, events *event.Core, posture *posture.Posture, subsystems *subsystem.Set) *api {
+	return &api{
+		log:         log,
+		authClient:  authClient,
+		maintenance: maintenance,
+		usage:       usage,
+		apiKey:      apiKey,
+		tenant:      tenant,
+		feed:        feed,
+		events:      events,
+		posture:     posture,
+		subsystems:  subsystems,
+	}
+}
+
+// publish notifies the clients polling the feed of the event and records it
+// so it can be replayed later. Failing to record the event doesn't fail the
+// request since the change itself was already made.
+func (api *api) publish(ctx context.Context, typ string, tenantID uuid.UUID, data any) {
+	api.feed.Publish(typ, tenantID.String(), data)
+
+	if api.events == nil {
+		return
+	}
+
+	if _, err := api.events.Record(ctx, typ, tenantID, data); err != nil {
+		api.log.Error(ctx, "recording event", "type", typ, "tenantID", tenantID, "msg", err)
+	}
+}
+
+// change represents a setting changed at runtime.
+type change struct {
+	Time    string `json:"time"`
+	Setting string `json:"setting"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// postureSnapshot represents the operational state of the service.
+type postureSnapshot struct {
+	Time     string         `json:"time"`
+	Sections map[string]any `json:"sections"`
+	Changes  []change       `json:"changes"`
+}
+
+// queryPosture returns a single diagnostic snapshot of the service for
+// on-call engineers.
+func (api *api) queryPosture(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	snap := api.posture.Snapshot(ctx)
+
+	changes := make([]change, len(snap.Changes))
+	for i, c := range snap.Changes {
+		changes[i] = change{
+			Time:    c.Time.Format(time.RFC3339),
+			Setting: c.Setting,
+			From:    c.From,
+			To:      c.To,
+			Subject: c.Subject,
+		}
+	}
+
+	resp := postureSnapshot{
+		Time:     snap.Time.Format(time.RFC3339),
+		Sections: snap.Sections,
+		Changes:  changes,
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// maintenanceStatus represents the maintenance state of the service.
+type maintenanceStatus struct {
+	Enabled    bool   `json:"enabled"`
+	RetryAfter string `json:"retryAfter"`
+}
+
+func (api *api) queryMaintenance(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	status := maintenanceStatus{
+		Enabled:    api.maintenance.Enabled(),
+		RetryAfter: api.maintenance.RetryAfter().String(),
+	}
+
+	return web.Respond(ctx, w, status, http.StatusOK)
+}
+
+func (api *api) setMaintenance(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.FailedPrecondition, err)
+	}
+
+	prev := api.maintenance.Enabled()
+
+	switch req.Enabled {
+	case true:
+		api.maintenance.Enable()
+	default:
+		api.maintenance.Disable()
+	}
+
+	api.log.Info(ctx, "maintenance", "enabled", req.Enabled)
+	api.posture.RecordChange("maintenance", strconv.FormatBool(prev), strconv.FormatBool(req.Enabled), mid.GetClaims(ctx).Subject)
+
+	status := maintenanceStatus{
+		Enabled:    api.maintenance.Enabled(),
+		RetryAfter: api.maintenance.RetryAfter().String(),
+	}
+
+	return web.Respond(ctx, w, status, http.StatusOK)
+}
+
+// logLevel represents the minimum level the service is logging at and the
+// packages logging at a different level.
+type logLevel struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages,omitempty"`
+}
+
+func (api *api) toLogLevel() logLevel {
+	pkgs := api.log.Levels()
+
+	resp := logLevel{
+		Level:    api.log.Level().String(),
+		Packages: make(map[string]string, len(pkgs)),
+	}
+
+	for path, level := range pkgs {
+		resp.Packages[path] = level.String()
+	}
+
+	return resp
+}
+
+func (api *api) queryLogLevel(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return web.Respond(ctx, w, api.toLogLevel(), http.StatusOK)
+}
+
+// setLogLevel changes the log level without a restart so debug logs can be
+// turned on while diagnosing a problem and off again afterwards.
+func (api *api) setLogLevel(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var req logLevel
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prev := api.log.Level()
+	api.log.SetLevel(level)
+
+	api.log.Info(ctx, "log level changed", "from", prev.String(), "to", level.String())
+	api.posture.RecordChange("logLevel", prev.String(), level.String(), mid.GetClaims(ctx).Subject)
+
+	return web.Respond(ctx, w, api.toLogLevel(), http.StatusOK)
+}
+
+// setPackageLogLevel changes the level of a single package, such as
+// business/core/user, so one domain can be made more verbose without
+// flooding the logs with every other package.
+func (api *api) setPackageLogLevel(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	path := strings.Trim(web.Param(r, "path"), "/")
+
+	var req struct {
+		Level string `json:"level"`
+	}
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	pkgs := api.log.Levels()
+
+	prev := api.log.Level().String()
+	if l, exists := pkgs[path]; exists {
+		prev = l.String()
+	}
+
+	pkgs[path] = level
+	api.log.SetLevels(pkgs)
+
+	api.log.Info(ctx, "log level changed", "package", path, "from", prev, "to", level.String())
+	api.posture.RecordChange("logLevel."+path, prev, level.String(), mid.GetClaims(ctx).Subject)
+
+	return web.Respond(ctx, w, api.toLogLevel(), http.StatusOK)
+}
+
+// deletePackageLogLevel returns a package to the service's log level.
+func (api *api) deletePackageLogLevel(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	path := strings.Trim(web.Param(r, "path"), "/")
+
+	pkgs := api.log.Levels()
+
+	prev, exists := pkgs[path]
+	if !exists {
+		return errs.Newf(errs.NotFound, "package %q has no log level", path)
+	}
+
+	delete(pkgs, path)
+	api.log.SetLevels(pkgs)
+
+	level := api.log.Level()
+
+	api.log.Info(ctx, "log level changed", "package", path, "from", prev.String(), "to", level.String())
+	api.posture.RecordChange("logLevel."+path, prev.String(), level.String(), mid.GetClaims(ctx).Subject)
+
+	return web.Respond(ctx, w, api.toLogLevel(), http.StatusOK)
+}
+
+// subsystemStatus represents whether a subsystem is paused.
+type subsystemStatus struct {
+	Name   string `json:"name"`
+	Paused bool   `json:"paused"`
+	Since  string `json:"since"`
+}
+
+func toSubsystemStatus(st subsystem.State) subsystemStatus {
+	return subsystemStatus{
+		Name:   st.Name,
+		Paused: st.Paused,
+		Since:  st.Since.Format(time.RFC3339),
+	}
+}
+
+func (api *api) querySubsystems(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	states := api.subsystems.States()
+
+	resp := make([]subsystemStatus, len(states))
+	for i, st := range states {
+		resp[i] = toSubsystemStatus(st)
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// setSubsystem pauses or resumes a single subsystem so an incident in one
+// pipeline can be contained while the rest of the service keeps serving.
+func (api *api) setSubsystem(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := web.Param(r, "name")
+
+	sw, exists := api.subsystems.Lookup(name)
+	if !exists {
+		return errs.Newf(errs.NotFound, "subsystem %q does not exist", name)
+	}
+
+	var req struct {
+		Paused bool `json:"paused"`
+	}
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prev := sw.Paused()
+
+	switch req.Paused {
+	case true:
+		sw.Pause()
+	default:
+		sw.Resume()
+	}
+
+	api.log.Info(ctx, "subsystem", "name", name, "paused", req.Paused)
+	api.posture.RecordChange("subsystem."+name, strconv.FormatBool(prev), strconv.FormatBool(req.Paused), mid.GetClaims(ctx).Subject)
+
+	return web.Respond(ctx, w, toSubsystemStatus(sw.State()), http.StatusOK)
+}
+
+// consumer represents the usage of a single client against a route.
+type consumer struct {
+	Subject   string  `json:"subject"`
+	Route     string  `json:"route"`
+	Since     string  `json:"since"`
+	Requests  int64   `json:"requests"`
+	Bytes     int64   `json:"bytes"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+}
+
+func (api *api) queryUsage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.usage == nil {
+		return errs.Newf(errs.Unimplemented, "usage accounting is not enabled")
+	}
+
+	mask, err := fields.Parse(r.URL.Query().Get("fields"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := mask.Validate(consumer{}); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	filter := usage.QueryFilter{
+		Route: r.URL.Query().Get("route"),
+		Since: time.Now().Add(-24 * time.Hour),
+	}
+
+	if v := r.URL.Query().Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "since: %s", err)
+		}
+		filter.Since = since
+	}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "limit: %s", err)
+		}
+		filter.Limit = limit
+	}
+
+	usages, err := api.usage.QueryTop(ctx, filter)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	consumers := make([]consumer, len(usages))
+	for i, u := range usages {
+		consumers[i] = consumer{
+			Subject:   u.Subject,
+			Route:     u.Route,
+			Since:     u.WindowStart.Format(time.RFC3339),
+			Requests:  u.Requests,
+			Bytes:     u.Bytes,
+			Errors:    u.Errors,
+			ErrorRate: u.ErrorRate(),
+		}
+	}
+
+	resp, err := mask.Apply(consumers)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// newAPIKey represents the information needed to issue an api key.
+type newAPIKey struct {
+	Tenant string   `json:"tenant"`
+	Name   string   `json:"name"`
+	Roles  []string `json:"roles"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (nk newAPIKey) Validate() error {
+	var fe errs.FieldErrors
+
+	if nk.Name == "" {
+		fe.Add("name", "is required")
+	}
+
+	for i, role := range nk.Roles {
+		if role != "ADMIN" && role != "USER" {
+			fe.Add(fmt.Sprintf("roles.%d", i), fmt.Sprintf("invalid role %q", role))
+		}
+	}
+
+	return fe.ToError()
+}
+
+// issuedAPIKey represents a newly issued api key. The key is only ever
+// returned at issue time.
+type issuedAPIKey struct {
+	ID          string   `json:"id"`
+	Tenant      string   `json:"tenant,omitempty"`
+	Name        string   `json:"name"`
+	Key         string   `json:"key"`
+	Roles       []string `json:"roles"`
+	DateCreated string   `json:"dateCreated"`
+}
+
+func (api *api) createAPIKey(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nk newAPIKey
+	if err := web.Decode(r, &nk); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var tenantID uuid.UUID
+	if nk.Tenant != "" {
+		tnt, err := api.tenant.Resolve(ctx, nk.Tenant)
+		if err != nil {
+			if errors.Is(err, tenant.ErrNotFound) {
+				return errs.New(errs.NotFound, err)
+			}
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		tenantID = tnt.ID
+	}
+
+	key, plain, err := api.apiKey.Create(ctx, apikey.NewAPIKey{
+		TenantID: tenantID,
+		Name:     nk.Name,
+		Roles:    nk.Roles,
+	})
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "apikey issued", "keyID", key.ID, "name", key.Name)
+
+	resp := issuedAPIKey{
+		ID:          key.ID.String(),
+		Name:        key.Name,
+		Key:         plain,
+		Roles:       key.Roles,
+		DateCreated: key.DateCreated.Format(time.RFC3339),
+	}
+
+	if key.TenantID != uuid.Nil {
+		resp.Tenant = key.TenantID.String()
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusCreated)
+}
+
+// upsertTenant creates or replaces the tenant with the ID chosen by the
+// client. Sending If-None-Match: * only allows the tenant to be created.
+func (api *api) upsertTenant(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := web.ParseClientID(web.Param(r, "tenant_id"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var nt newTenant
+	if err := web.Decode(r, &nt); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	tnt, created, err := api.tenant.Upsert(ctx, tenantID, tenant.NewTenant{
+		Slug: nt.Slug,
+		Name: nt.Name,
+	}, web.IfNoneMatchAny(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, tenant.ErrInvalidSlug):
+			return errs.New(errs.InvalidArgument, err)
+		case errors.Is(err, tenant.ErrUniqueSlug), errors.Is(err, tenant.ErrExists):
+			return errs.New(errs.AlreadyExists, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	resp := toTenantInfo(tnt)
+
+	if created {
+		api.log.Info(ctx, "tenant created", "tenantID", tnt.ID, "slug", tnt.Slug)
+		api.publish(ctx, "tenant.created", tnt.ID, resp)
+		return web.Respond(ctx, w, resp, http.StatusCreated)
+	}
+
+	api.publish(ctx, "tenant.updated", tnt.ID, resp)
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// apiKeyInfo represents an issued api key without its secret.
+type apiKeyInfo struct {
+	ID          string          `json:"id"`
+	Tenant      string          `json:"tenant,omitempty"`
+	Name        string          `json:"name"`
+	Prefix      string          `json:"prefix"`
+	Roles       []string        `json:"roles"`
+	Enabled     bool            `json:"enabled"`
+	DateCreated string          `json:"dateCreated"`
+	Embedded    *apiKeyEmbedded `json:"embedded,omitempty"`
+}
+
+// apiKeyEmbedded holds the related resources requested with ?expand=.
+type apiKeyEmbedded struct {
+	Tenant *tenantInfo `json:"tenant,omitempty"`
+}
+
+func (api *api) queryAPIKeyByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	keyID, err := uuid.Parse(web.Param(r, "key_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "key_id: %s", err)
+	}
+
+	tree, err := schema.Parse(ctx, "apikey", r.URL.Query().Get("expand"), expand.DefaultMaxDepth, api.authorize)
+	if err != nil {
+		return err
+	}
+
+	key, err := api.apiKey.QueryByID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	resp := apiKeyInfo{
+		ID:          key.ID.String(),
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Roles:       key.Roles,
+		Enabled:     key.Enabled,
+		DateCreated: key.DateCreated.Format(time.RFC3339),
+	}
+
+	if key.TenantID != uuid.Nil {
+		resp.Tenant = key.TenantID.String()
+	}
+
+	if tree.Has("tenant") && key.TenantID != uuid.Nil {
+		tnt, exists, err := api.tenantLoader().Load(ctx, key.TenantID)
+		if err != nil {
+			return errs.New(errs.Internal, err)
+		}
+
+		if exists {
+			info := toTenantInfo(tnt)
+			resp.Embedded = &apiKeyEmbedded{Tenant: &info}
+		}
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+func (api *api) disableAPIKey(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	keyID, err := uuid.Parse(r.PathValue("key_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "key_id: %s", err)
+	}
+
+	if err := api.apiKey.Disable(ctx, keyID); err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "apikey disabled", "keyID", keyID)
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// newTenant represents the information needed to create a tenant.
+type newTenant struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (nt newTenant) Validate() error {
+	var fe errs.FieldErrors
+
+	if nt.Slug == "" {
+		fe.Add("slug", "is required")
+	}
+
+	if nt.Name == "" {
+		fe.Add("name", "is required")
+	}
+
+	return fe.ToError()
+}
+
+// tenantInfo represents a tenant of the system.
+type tenantInfo struct {
+	ID          string `json:"id"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Enabled     bool   `json:"enabled"`
+	DateCreated string `json:"dateCreated"`
+}
+
+// updateTenant represents the changes to apply to a tenant. Expect holds the
+// state the tenant must still be in for the changes to apply.
+type updateTenant struct {
+	Name    *string       `json:"name"`
+	Enabled *bool         `json:"enabled"`
+	Expect  *expectTenant `json:"expect"`
+}
+
+// expectTenant represents the expected state of a tenant.
+type expectTenant struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (ut updateTenant) Validate() error {
+	var fe errs.FieldErrors
+
+	if ut.Name != nil && *ut.Name == "" {
+		fe.Add("name", "can't be empty")
+	}
+
+	return fe.ToError()
+}
+
+// patchTenant applies a change to a tenant. Transitions can be made
+// conditional on the current state with the expect field, or on the tenant
+// not having changed since it was read with If-Unmodified-Since.
+func (api *api) patchTenant(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := uuid.Parse(web.Param(r, "tenant_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "tenant_id: %s", err)
+	}
+
+	var ut updateTenant
+	if err := web.Decode(r, &ut); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var exp tenant.Expect
+
+	if ut.Expect != nil {
+		exp.Enabled = ut.Expect.Enabled
+	}
+
+	if v := r.Header.Get("If-Unmodified-Since"); v != "" {
+		since, err := http.ParseTime(v)
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "if-unmodified-since: %s", err)
+		}
+
+		// The header only carries whole seconds, so anything updated within
+		// the same second is still considered unmodified.
+		before := since.Add(time.Second)
+		exp.UpdatedBefore = &before
+	}
+
+	tnt, err := api.tenant.UpdateIf(ctx, tenantID, tenant.UpdateTenant{
+		Name:    ut.Name,
+		Enabled: ut.Enabled,
+	}, exp)
+	if err != nil {
+		var pe *precondition.Error
+		switch {
+		case errors.Is(err, tenant.ErrNotFound):
+			return errs.New(errs.NotFound, err)
+		case errors.As(err, &pe):
+			return errs.New(errs.FailedPrecondition, err)
+		case errors.Is(err, tenant.ErrChanged):
+			return errs.New(errs.Aborted, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "tenant updated", "tenantID", tnt.ID, "enabled", tnt.Enabled)
+
+	resp := toTenantInfo(tnt)
+	api.publish(ctx, "tenant.updated", tnt.ID, resp)
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+func toTenantInfo(tnt tenant.Tenant) tenantInfo {
+	return tenantInfo{
+		ID:          tnt.ID.String(),
+		Slug:        tnt.Slug,
+		Name:        tnt.Name,
+		Enabled:     tnt.Enabled,
+		DateCreated: tnt.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func (api *api) createTenant(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nt newTenant
+	if err := web.Decode(r, &nt); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	tnt, err := api.tenant.Create(ctx, tenant.NewTenant{
+		Slug: nt.Slug,
+		Name: nt.Name,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, tenant.ErrInvalidSlug):
+			return errs.New(errs.InvalidArgument, err)
+		case errors.Is(err, tenant.ErrUniqueSlug):
+			return errs.New(errs.AlreadyExists, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "tenant created", "tenantID", tnt.ID, "slug", tnt.Slug)
+
+	resp := toTenantInfo(tnt)
+	api.publish(ctx, "tenant.created", tnt.ID, resp)
+
+	return web.Respond(ctx, w, resp, http.StatusCreated)
+}
@@ -0,0 +1,54 @@
+package adminapi
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/expand"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+)
+
+// schema declares the relations of the admin resources that can be
+// requested with ?expand=.
+var schema = expand.Schema{
+	"apikey": {
+		"tenant": {Resource: "tenant", Rule: auth.RuleAdminOnly},
+	},
+}
+
+// authorize checks the caller against the rule guarding an expansion.
+func (api *api) authorize(ctx context.Context, rule string) error {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return api.authClient.Authorize(ctx, authclient.Authorize{
+		Claims: mid.GetClaims(ctx),
+		UserID: userID,
+		Rule:   rule,
+	})
+}
+
+// tenantLoader constructs a loader that fetches tenants in batches for the
+// life of a request.
+func (api *api) tenantLoader() *expand.Loader[uuid.UUID, tenant.Tenant] {
+	fetch := func(ctx context.Context, tenantIDs []uuid.UUID) (map[uuid.UUID]tenant.Tenant, error) {
+		tnts, err := api.tenant.QueryByIDs(ctx, tenantIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		m := make(map[uuid.UUID]tenant.Tenant, len(tnts))
+		for _, tnt := range tnts {
+			m[tnt.ID] = tnt
+		}
+
+		return m, nil
+	}
+
+	return expand.NewLoader(fetch)
+}
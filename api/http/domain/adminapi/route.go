@@ -0,0 +1,62 @@
+package adminapi
+
+import (
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+	"github.com/mrcruz117/al-service/app/api/posture"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/business/core/audit"
+	"github.com/mrcruz117/al-service/business/core/event"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/feed"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log         *logger.Logger
+	AuthClient  *authclient.Client
+	Maintenance *maintenance.Mode
+	Usage       *usage.Core
+	Audit       *audit.Core
+	APIKey      *apikey.Core
+	Tenant      *tenant.Core
+	Feed        *feed.Feed
+	Events      *event.Core
+	Posture     *posture.Posture
+	Subsystems  *subsystem.Set
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	scpAdmin := mid.RequireScope(auth.ScopeAdmin)
+
+	usg := mid.Usage(cfg.Usage)
+	aud := mid.Audit(cfg.Audit)
+
+	api := newAPI(cfg.Log, cfg.AuthClient, cfg.Maintenance, cfg.Usage, cfg.APIKey, cfg.Tenant, cfg.Feed, cfg.Events, cfg.Posture, cfg.Subsystems)
+
+	app.HandleFunc("GET /admin/posture", api.queryPosture, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("GET /admin/maintenance", api.queryMaintenance, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("PUT /admin/maintenance", api.setMaintenance, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("GET /admin/subsystems", api.querySubsystems, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("PUT /admin/subsystems/{name}", api.setSubsystem, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("GET /admin/loglevel", api.queryLogLevel, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("PUT /admin/loglevel", api.setLogLevel, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("PUT /admin/loglevel/{path...}", api.setPackageLogLevel, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("DELETE /admin/loglevel/{path...}", api.deletePackageLogLevel, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("GET /admin/usage", api.queryUsage, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("POST /admin/apikeys", api.createAPIKey, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("GET /admin/apikeys/{key_id}", api.queryAPIKeyByID, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("DELETE /admin/apikeys/{key_id}", api.disableAPIKey, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("POST /admin/tenants", api.createTenant, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("PUT /admin/tenants/{tenant_id}", api.upsertTenant, authen, aud, athAdminOnly, scpAdmin, usg)
+	app.HandleFunc("PATCH /admin/tenants/{tenant_id}", api.patchTenant, authen, aud, athAdminOnly, scpAdmin, usg)
+}
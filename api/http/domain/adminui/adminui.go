@@ -0,0 +1,195 @@
+// Package adminui maintains the server rendered pages for internal admin tools.
+package adminui
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	httpmid "github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	build       string
+	log         *logger.Logger
+	authClient  *authclient.Client
+	maintenance *maintenance.Mode
+	renderer    *web.Renderer
+}
+
+func newAPI(build string, log *logger.Logger, authClient *authclient.Client, maintenance *maintenance.Mode, renderer *web.Renderer) *api {
+	return &api{
+		build:       build,
+		log:         log,
+		authClient:  authClient,
+		maintenance: maintenance,
+		renderer:    renderer,
+	}
+}
+
+// page represents the data every page has access to.
+type page struct {
+	Title   string
+	CSRF    string
+	Subject string
+	Error   string
+	Data    any
+}
+
+func (api *api) render(ctx context.Context, w http.ResponseWriter, name string, p page, statusCode int) error {
+	p.CSRF = mid.GetCSRFToken(ctx)
+	p.Subject = mid.GetClaims(ctx).Subject
+
+	return api.renderer.Render(ctx, w, name, p, statusCode)
+}
+
+func (api *api) login(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return api.render(ctx, w, "login", page{Title: "Login"}, http.StatusOK)
+}
+
+func (api *api) loginSubmit(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	token := strings.TrimSpace(r.PostFormValue("token"))
+
+	resp, err := api.authClient.Authenticate(ctx, "Bearer "+token)
+	if err != nil {
+		api.log.Info(ctx, "adminui: login failed", "msg", err)
+		return api.render(ctx, w, "login", page{Title: "Login", Error: "invalid token"}, http.StatusUnauthorized)
+	}
+
+	expires := time.Now().Add(8 * time.Hour)
+	if resp.Claims.ExpiresAt != nil {
+		expires = resp.Claims.ExpiresAt.Time
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     httpmid.SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return web.Redirect(ctx, w, r, "/", http.StatusSeeOther)
+}
+
+func (api *api) logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, &http.Cookie{
+		Name:     httpmid.SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return web.Redirect(ctx, w, r, "/login", http.StatusSeeOther)
+}
+
+func (api *api) index(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unavailable"
+	}
+
+	data := struct {
+		Build       string
+		Host        string
+		Maintenance bool
+		Roles       []string
+	}{
+		Build:       api.build,
+		Host:        host,
+		Maintenance: api.maintenance != nil && api.maintenance.Enabled(),
+		Roles:       mid.GetClaims(ctx).Roles,
+	}
+
+	return api.render(ctx, w, "index", page{Title: "Dashboard", Data: data}, http.StatusOK)
+}
+
+// simulation represents the input and result of a policy simulation.
+type simulation struct {
+	Rules     []string
+	Rule      string
+	Roles     string
+	SubjectID string
+	UserID    string
+	Evaluated bool
+	Allowed   bool
+	Reason    string
+}
+
+var rules = []string{
+	auth.RuleAny,
+	auth.RuleAdminOnly,
+	auth.RuleUserOnly,
+	auth.RuleAdminOrSubject,
+}
+
+func (api *api) policy(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	sim := simulation{
+		Rules: rules,
+		Rule:  auth.RuleAny,
+		Roles: "USER",
+	}
+
+	return api.render(ctx, w, "policy", page{Title: "Policy Simulation", Data: sim}, http.StatusOK)
+}
+
+func (api *api) policySubmit(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	sim := simulation{
+		Rules:     rules,
+		Rule:      r.PostFormValue("rule"),
+		Roles:     r.PostFormValue("roles"),
+		SubjectID: strings.TrimSpace(r.PostFormValue("subject")),
+		UserID:    strings.TrimSpace(r.PostFormValue("userID")),
+	}
+
+	p := page{Title: "Policy Simulation", Data: &sim}
+
+	userID, err := uuid.Parse(sim.UserID)
+	if err != nil {
+		p.Error = "target user id must be a uuid"
+		return api.render(ctx, w, "policy", p, http.StatusBadRequest)
+	}
+
+	var roles []string
+	for role := range strings.SplitSeq(sim.Roles, ",") {
+		if role = strings.ToUpper(strings.TrimSpace(role)); role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	authorize := authclient.Authorize{
+		Claims: auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject: sim.SubjectID,
+			},
+			Roles: roles,
+		},
+		UserID: userID,
+		Rule:   sim.Rule,
+	}
+
+	sim.Evaluated = true
+	sim.Allowed = true
+
+	if err := api.authClient.Authorize(ctx, authorize); err != nil {
+		sim.Allowed = false
+		sim.Reason = err.Error()
+	}
+
+	return api.render(ctx, w, "policy", p, http.StatusOK)
+}
@@ -0,0 +1,54 @@
+package adminui
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Build       string
+	Log         *logger.Logger
+	AuthClient  *authclient.Client
+	Maintenance *maintenance.Mode
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	funcs := template.FuncMap{
+		"join": strings.Join,
+	}
+
+	// The templates are embedded in the binary so a failure here can only
+	// be a programming error.
+	renderer, err := web.NewRenderer(templates, "templates/layout.tmpl", "templates/*.tmpl", funcs)
+	if err != nil {
+		panic(fmt.Sprintf("adminui: parsing templates: %s", err))
+	}
+
+	public := mid.Public()
+	csrf := mid.CSRF()
+	session := mid.Session(cfg.Log, cfg.AuthClient, "/login")
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+
+	api := newAPI(cfg.Build, cfg.Log, cfg.AuthClient, cfg.Maintenance, renderer)
+
+	app.HandleFunc("GET /login", api.login, csrf, public)
+	app.HandleFunc("POST /login", api.loginSubmit, csrf, public)
+	app.HandleFunc("POST /logout", api.logout, csrf, public)
+	app.HandleFunc("GET /{$}", api.index, csrf, session, athAdminOnly)
+	app.HandleFunc("GET /policy", api.policy, csrf, session, athAdminOnly)
+	app.HandleFunc("POST /policy", api.policySubmit, csrf, session, athAdminOnly)
+}
@@ -0,0 +1,55 @@
+// Package auditapi maintains the web based api for reading the entity audit
+// trail.
+package auditapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	entityAuditCore *entityaudit.Core
+}
+
+func newAPI(entityAuditCore *entityaudit.Core) *api {
+	return &api{
+		entityAuditCore: entityAuditCore,
+	}
+}
+
+func (api *api) query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qp := r.URL.Query()
+
+	pg, err := page.Parse(qp.Get("page"), qp.Get("rows"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	orderBy, err := parseOrderBy(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	chgs, err := api.entityAuditCore.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	total, err := api.entityAuditCore.Count(ctx, filter)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, query.NewResult(toAppChanges(chgs), total, pg), http.StatusOK)
+}
@@ -0,0 +1,67 @@
+package auditapi
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+)
+
+// orderByFields maps the names clients order by to the change fields.
+var orderByFields = map[string]string{
+	"id":          entityaudit.OrderByID,
+	"entity":      entityaudit.OrderByEntity,
+	"actor":       entityaudit.OrderByActor,
+	"dateCreated": entityaudit.OrderByDateCreated,
+}
+
+// parseFilter reads the filter of a list request from the query string.
+func parseFilter(qp url.Values) (entityaudit.QueryFilter, error) {
+	var fe errs.FieldErrors
+	var filter entityaudit.QueryFilter
+
+	if v := qp.Get("entity"); v != "" {
+		filter.Entity = &v
+	}
+
+	if v := qp.Get("entityID"); v != "" {
+		filter.EntityID = &v
+	}
+
+	if v := qp.Get("actor"); v != "" {
+		filter.Actor = &v
+	}
+
+	if v := qp.Get("startCreatedDate"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fe.Add("startCreatedDate", "must be an RFC 3339 time")
+		}
+		filter.StartCreatedDate = &t
+	}
+
+	if v := qp.Get("endCreatedDate"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fe.Add("endCreatedDate", "must be an RFC 3339 time")
+		}
+		filter.EndCreatedDate = &t
+	}
+
+	if err := fe.ToError(); err != nil {
+		return entityaudit.QueryFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return entityaudit.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseOrderBy reads the order of a list request from the query string.
+func parseOrderBy(qp url.Values) (order.By, error) {
+	return order.Parse(orderByFields, qp.Get("orderBy"), entityaudit.DefaultOrderBy)
+}
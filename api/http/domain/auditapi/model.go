@@ -0,0 +1,43 @@
+package auditapi
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+)
+
+// appChange represents a change to an entity in the api. The snapshots are
+// left out when the entity didn't exist before or after the change.
+type appChange struct {
+	ID          string          `json:"id"`
+	Entity      string          `json:"entity"`
+	EntityID    string          `json:"entityID"`
+	Action      string          `json:"action"`
+	Actor       string          `json:"actor"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	DateCreated string          `json:"dateCreated"`
+}
+
+func toAppChange(chg entityaudit.Change) appChange {
+	return appChange{
+		ID:          chg.ID.String(),
+		Entity:      chg.Entity,
+		EntityID:    chg.EntityID,
+		Action:      chg.Action,
+		Actor:       chg.Actor,
+		Before:      chg.Before,
+		After:       chg.After,
+		DateCreated: chg.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppChanges(chgs []entityaudit.Change) []appChange {
+	items := make([]appChange, len(chgs))
+	for i, chg := range chgs {
+		items[i] = toAppChange(chg)
+	}
+
+	return items
+}
@@ -0,0 +1,31 @@
+package auditapi
+
+import (
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log             *logger.Logger
+	AuthClient      *authclient.Client
+	EntityAuditCore *entityaudit.Core
+	Usage           *usage.Core
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	scpAdmin := mid.RequireScope(auth.ScopeAdmin)
+	usg := mid.Usage(cfg.Usage)
+
+	api := newAPI(cfg.EntityAuditCore)
+
+	app.HandleFunc("GET /admin/changes", api.query, authen, athAdminOnly, scpAdmin, usg)
+}
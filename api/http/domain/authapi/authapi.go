@@ -3,22 +3,55 @@ package authapi
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
 	"github.com/mrcruz117/al-service/app/api/errs"
 	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/app/api/session"
+	"github.com/mrcruz117/al-service/business/core/client"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
 type api struct {
-	auth *auth.Auth
+	log            *logger.Logger
+	auth           *auth.Auth
+	userCore       *user.Core
+	activeKID      string
+	tokenTTL       time.Duration
+	sessions       *session.Store
+	sessionClients map[string]bool
+	clientCore     *client.Core
+	serviceTTL     time.Duration
 }
 
-func newAPI(auth *auth.Auth) *api {
+func newAPI(log *logger.Logger, auth *auth.Auth, userCore *user.Core, activeKID string, tokenTTL time.Duration, sessions *session.Store, sessionClients []string, clientCore *client.Core, serviceTTL time.Duration) *api {
+	clients := make(map[string]bool, len(sessionClients))
+	for _, c := range sessionClients {
+		clients[c] = true
+	}
+
 	return &api{
-		auth: auth,
+		log:            log,
+		auth:           auth,
+		userCore:       userCore,
+		activeKID:      activeKID,
+		tokenTTL:       tokenTTL,
+		sessions:       sessions,
+		sessionClients: clients,
+		clientCore:     clientCore,
+		serviceTTL:     serviceTTL,
 	}
 }
 
@@ -30,6 +63,18 @@ func (api *api) token(ctx context.Context, w http.ResponseWriter, r *http.Reques
 
 	claims := mid.GetClaims(ctx)
 
+	// A token can be limited to some of the scopes of the user, such as a
+	// read only token for a reporting job.
+	if scope := r.URL.Query().Get("scope"); scope != "" {
+		perms := strings.Fields(scope)
+		for _, p := range perms {
+			if !claims.HasScope(p) {
+				return errs.Newf(errs.PermissionDenied, "scope %q is not granted", p)
+			}
+		}
+		claims.Permissions = perms
+	}
+
 	tkn, err := api.auth.GenerateToken(kid, claims)
 
 	if err != nil {
@@ -45,6 +90,339 @@ func (api *api) token(ctx context.Context, w http.ResponseWriter, r *http.Reques
 	return web.Respond(ctx, w, token, http.StatusOK)
 }
 
+// login represents the credentials of a user signing in.
+type login struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Client   string `json:"client"`
+}
+
+// loginToken represents the token issued to a user that signed in. Kind is
+// jwt or session.
+type loginToken struct {
+	Token     string `json:"token"`
+	Kind      string `json:"kind"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// login verifies the email and password of a user and issues a token signed
+// with the active key, so clients don't have to send the password on every
+// request the way basic auth does. The client types configured for sessions
+// get an opaque session token instead, which can be revoked.
+func (api *api) login(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var req login
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil || req.Password == "" {
+		return errs.Newf(errs.Unauthenticated, "invalid email or password")
+	}
+
+	usr, err := api.userCore.Authenticate(ctx, *addr, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrAuthenticationFailure), errors.Is(err, user.ErrUserDisabled):
+
+			// Which of the two failed is only logged so the response
+			// doesn't reveal whether the account exists.
+			api.log.Info(ctx, "login failed", "email", addr.Address, "msg", err)
+			return errs.Newf(errs.Unauthenticated, "invalid email or password")
+
+		default:
+			return errs.Newf(errs.Internal, "login: %s", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	expires := now.Add(api.tokenTTL)
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   usr.ID.String(),
+			Issuer:    api.auth.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(expires),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Roles:       usr.Roles,
+		Permissions: auth.ScopesFor(usr.Roles),
+	}
+	api.auth.MapClaims(&claims, usr.Attributes())
+
+	if api.sessions != nil && api.sessionClients[req.Client] {
+
+		// The session decides when it expires, the claims don't.
+		claims.ExpiresAt = nil
+
+		ses, err := api.sessions.Create(ctx, claims, req.Client)
+		if err != nil {
+			return errs.New(errs.Internal, err)
+		}
+
+		resp := loginToken{
+			Token:     ses.Token,
+			Kind:      "session",
+			ExpiresAt: ses.ExpiresAt.Format(time.RFC3339),
+		}
+
+		return web.Respond(ctx, w, resp, http.StatusOK)
+	}
+
+	tkn, err := api.auth.GenerateToken(api.activeKID, claims)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	resp := loginToken{
+		Token:     tkn,
+		Kind:      "jwt",
+		ExpiresAt: expires.Format(time.RFC3339),
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// logout ends the session of the token the request was made with. A JWT
+// can't be revoked and is left to expire.
+func (api *api) logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	token := strings.TrimPrefix(r.Header.Get("authorization"), "Bearer ")
+
+	if api.sessions != nil && strings.HasPrefix(token, auth.SessionPrefix) {
+		if err := api.sessions.Revoke(ctx, token); err != nil && !errors.Is(err, session.ErrNotFound) {
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// revokedSessions represents the number of sessions that were ended.
+type revokedSessions struct {
+	Revoked int `json:"revoked"`
+}
+
+// revokeSessions ends every session of the user, such as when an account
+// is compromised or the user signs out everywhere.
+func (api *api) revokeSessions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.sessions == nil {
+		return errs.Newf(errs.FailedPrecondition, "sessions are not enabled")
+	}
+
+	n, err := api.sessions.RevokeUser(ctx, web.Param(r, "user_id"))
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "sessions revoked", "userID", web.Param(r, "user_id"), "revoked", n, "by", mid.GetClaims(ctx).Subject)
+
+	return web.Respond(ctx, w, revokedSessions{Revoked: n}, http.StatusOK)
+}
+
+// serviceToken represents the token issued with the client credentials
+// grant, in the form of an OAuth 2.0 token response.
+type serviceToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// clientToken implements the client credentials grant. A service
+// authenticates as itself, with the credentials in the form or with basic
+// auth, and is issued a short lived token with the SERVICE role and the
+// scopes of the client, or the subset of them asked for.
+func (api *api) clientToken(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.clientCore == nil {
+		return errs.Newf(errs.FailedPrecondition, "service clients are not enabled")
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if grant := r.PostForm.Get("grant_type"); grant != "client_credentials" {
+		return errs.Newf(errs.InvalidArgument, "unsupported grant_type %q", grant)
+	}
+
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	clientID, err := uuid.Parse(id)
+	if err != nil || secret == "" {
+		return errs.Newf(errs.Unauthenticated, "invalid client credentials")
+	}
+
+	cln, err := api.clientCore.Authenticate(ctx, clientID, secret)
+	if err != nil {
+		switch {
+		case errors.Is(err, client.ErrInvalidCredentials), errors.Is(err, client.ErrDisabled):
+			api.log.Info(ctx, "client authentication failed", "clientID", clientID, "msg", err)
+			return errs.Newf(errs.Unauthenticated, "invalid client credentials")
+
+		default:
+			return errs.Newf(errs.Internal, "client token: %s", err)
+		}
+	}
+
+	scopes := cln.Scopes
+	if scope := r.PostForm.Get("scope"); scope != "" {
+		scopes = strings.Fields(scope)
+		for _, s := range scopes {
+			if !slices.Contains(cln.Scopes, s) {
+				return errs.Newf(errs.PermissionDenied, "scope %q is not granted", s)
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   cln.ID.String(),
+			Issuer:    api.auth.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(api.serviceTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Roles:       []string{auth.RoleService},
+		Permissions: scopes,
+	}
+
+	tkn, err := api.auth.GenerateToken(api.activeKID, claims)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	resp := serviceToken{
+		AccessToken: tkn,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(api.serviceTTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// newClient represents the information needed to register a service client.
+type newClient struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (nc newClient) Validate() error {
+	var fe errs.FieldErrors
+
+	if nc.Name == "" {
+		fe.Add("name", "is required")
+	}
+
+	for i, scope := range nc.Scopes {
+		if !auth.IsScope(scope) {
+			fe.Add(fmt.Sprintf("scopes.%d", i), fmt.Sprintf("invalid scope %q", scope))
+		}
+	}
+
+	return fe.ToError()
+}
+
+// clientInfo represents a service client without its secret.
+type clientInfo struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Secret      string   `json:"secret,omitempty"`
+	Scopes      []string `json:"scopes"`
+	Enabled     bool     `json:"enabled"`
+	DateCreated string   `json:"dateCreated"`
+}
+
+func toClientInfo(cln client.Client) clientInfo {
+	return clientInfo{
+		ID:          cln.ID.String(),
+		Name:        cln.Name,
+		Scopes:      cln.Scopes,
+		Enabled:     cln.Enabled,
+		DateCreated: cln.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// createClient registers a service client. The secret is only ever returned
+// in this response.
+func (api *api) createClient(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.clientCore == nil {
+		return errs.Newf(errs.FailedPrecondition, "service clients are not enabled")
+	}
+
+	var nc newClient
+	if err := web.Decode(r, &nc); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cln, secret, err := api.clientCore.Create(ctx, client.NewClient{
+		Name:   nc.Name,
+		Scopes: nc.Scopes,
+	})
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "client registered", "clientID", cln.ID, "name", cln.Name, "by", mid.GetClaims(ctx).Subject)
+
+	resp := toClientInfo(cln)
+	resp.Secret = secret
+
+	return web.Respond(ctx, w, resp, http.StatusCreated)
+}
+
+func (api *api) queryClientByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.clientCore == nil {
+		return errs.Newf(errs.FailedPrecondition, "service clients are not enabled")
+	}
+
+	clientID, err := uuid.Parse(web.Param(r, "client_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "client_id: %s", err)
+	}
+
+	cln, err := api.clientCore.QueryByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toClientInfo(cln), http.StatusOK)
+}
+
+// disableClient stops the client being issued tokens. The tokens it holds
+// are left to expire, which the short ttl keeps brief.
+func (api *api) disableClient(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if api.clientCore == nil {
+		return errs.Newf(errs.FailedPrecondition, "service clients are not enabled")
+	}
+
+	clientID, err := uuid.Parse(web.Param(r, "client_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "client_id: %s", err)
+	}
+
+	if err := api.clientCore.Disable(ctx, clientID); err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "client disabled", "clientID", clientID, "by", mid.GetClaims(ctx).Subject)
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
 func (api *api) authenticate(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	// The middleware is actually handling the authentication. So if the code
 	// gets to this handler, authentication passed.
@@ -74,3 +452,45 @@ func (api *api) authorize(ctx context.Context, w http.ResponseWriter, r *http.Re
 
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
+
+// jwks publishes the public keys so other services can verify tokens
+// themselves rather than calling authenticate for every request.
+func (api *api) jwks(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	jwks, err := api.auth.JWKS()
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	return web.Respond(ctx, w, jwks, http.StatusOK)
+}
+
+// policyStatus represents the revision of the policies being enforced.
+type policyStatus struct {
+	Revision string `json:"revision"`
+}
+
+func (api *api) queryPolicies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return web.Respond(ctx, w, policyStatus{Revision: api.auth.PolicyRevision()}, http.StatusOK)
+}
+
+// reloadPolicies starts enforcing the policies as they are in the policy
+// directory now rather than waiting for the watcher to notice the change.
+func (api *api) reloadPolicies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	prev := api.auth.PolicyRevision()
+
+	if err := api.auth.ReloadPolicies(ctx); err != nil {
+		return errs.New(errs.FailedPrecondition, err)
+	}
+
+	rev := api.auth.PolicyRevision()
+	api.log.Info(ctx, "policies reloaded", "from", prev, "to", rev, "subject", mid.GetClaims(ctx).Subject)
+
+	return web.Respond(ctx, w, policyStatus{Revision: rev}, http.StatusOK)
+}
+
+// scopes publishes the catalog of scopes a token can be limited to.
+func (api *api) scopes(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return web.Respond(ctx, w, auth.Catalog(), http.StatusOK)
+}
@@ -1,24 +1,68 @@
 package authapi
 
 import (
+	"time"
+
 	"github.com/mrcruz117/al-service/api/http/api/mid"
 	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/session"
+	"github.com/mrcruz117/al-service/business/core/client"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Auth *auth.Auth
+	Log      *logger.Logger
+	Auth     *auth.Auth
+	UserCore *user.Core
+
+	// ActiveKID is the key the tokens issued by login are signed with and
+	// TokenTTL how long they, and the tokens issued for Basic auth, are
+	// valid.
+	ActiveKID string
+	TokenTTL  time.Duration
+
+	// Sessions, when set, issues opaque session tokens instead of JWTs to
+	// the SessionClients types of client logging in.
+	Sessions       *session.Store
+	SessionClients []string
+
+	// ClientCore holds the service clients that are issued tokens with the
+	// client credentials grant, valid for ServiceTTL.
+	ClientCore *client.Core
+	ServiceTTL time.Duration
+
+	// ServiceSecrets, when set, requires the calls made by other services
+	// to be signed by one of them.
+	ServiceSecrets map[string][]byte
+	ServiceMaxSkew time.Duration
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	bearer := mid.Bearer(cfg.Auth)
-	basic := mid.Basic(cfg.Auth)
+	basic := mid.Basic(cfg.Auth, cfg.UserCore, cfg.TokenTTL)
+	public := mid.Public()
+	signed := mid.VerifyServiceSignature(cfg.ServiceSecrets, cfg.ServiceMaxSkew)
+	athAdminOnly := mid.AuthorizeLocal(cfg.Auth, auth.RuleAdminOnly)
+	athAdminOrSubject := mid.AuthorizeSubjectLocal(cfg.Auth, "user_id", auth.RuleAdminOrSubject)
 
-	api := newAPI(cfg.Auth)
+	api := newAPI(cfg.Log, cfg.Auth, cfg.UserCore, cfg.ActiveKID, cfg.TokenTTL, cfg.Sessions, cfg.SessionClients, cfg.ClientCore, cfg.ServiceTTL)
 
 	app.HandleFunc("GET /auth/token/{kid}", api.token, basic)
-	app.HandleFunc("GET /auth/authenticate", api.authenticate, bearer)
-	app.HandleFunc("POST /auth/authorize", api.authorize)
+	app.HandleFunc("POST /auth/login", api.login, public)
+	app.HandleFunc("POST /auth/logout", api.logout, bearer)
+	app.HandleFunc("POST /auth/token", api.clientToken, public)
+	app.HandleFunc("POST /auth/clients", api.createClient, bearer, athAdminOnly)
+	app.HandleFunc("GET /auth/clients/{client_id}", api.queryClientByID, bearer, athAdminOnly)
+	app.HandleFunc("DELETE /auth/clients/{client_id}", api.disableClient, bearer, athAdminOnly)
+	app.HandleFunc("DELETE /auth/sessions/{user_id}", api.revokeSessions, bearer, athAdminOrSubject)
+	app.HandleFunc("GET /auth/authenticate", api.authenticate, signed, bearer)
+	app.HandleFunc("POST /auth/authorize", api.authorize, signed, public)
+	app.HandleFunc("GET /auth/.well-known/jwks.json", api.jwks, public)
+	app.HandleFunc("GET /auth/scopes", api.scopes, public)
+	app.HandleFunc("GET /auth/policies", api.queryPolicies, bearer, athAdminOnly)
+	app.HandleFunc("POST /auth/policies/reload", api.reloadPolicies, bearer, athAdminOnly)
 }
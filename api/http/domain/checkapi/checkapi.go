@@ -11,40 +11,64 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/mrcruz117/al-service/business/api/sqldb"
 	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/warmup"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
 type api struct {
-	build string
-	log   *logger.Logger
-	db    *sqlx.DB
+	build   string
+	log     *logger.Logger
+	db      *sqlx.DB
+	warmup  *warmup.Warmup
+	started time.Time
 }
 
-func newAPI(build string, log *logger.Logger, db *sqlx.DB) *api {
+func newAPI(build string, log *logger.Logger, db *sqlx.DB, warmup *warmup.Warmup) *api {
 	return &api{
-		build: build,
-		db:    db,
-		log:   log,
+		build:   build,
+		db:      db,
+		log:     log,
+		warmup:  warmup,
+		started: time.Now(),
 	}
 }
 
+// readinessTimeout bounds how long the database has to answer before the
+// service is reported as not ready.
+const readinessTimeout = time.Second
+
+// readiness reports whether the service can take traffic. A dependency that
+// can't be reached is named in the response with a 503, so the load balancer
+// stops routing to the instance until it recovers. A service shutting down
+// responds with a 503 too, for as long as it drains.
 func (api *api) readiness(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
 	defer cancel()
 
-	status := "ok"
+	data := struct {
+		Status     string `json:"status"`
+		Dependency string `json:"dependency,omitempty"`
+	}{
+		Status: "ok",
+	}
 	statusCode := http.StatusOK
 
-	if err := sqldb.StatusCheck(ctx, api.db); err != nil {
-		status = "db not ready"
-		statusCode = http.StatusInternalServerError
-		api.log.Info(ctx, "readiness failure", "status", status)
-	}
+	switch {
+	case api.warmup.Draining():
+		data.Status = "shutting down"
+		statusCode = http.StatusServiceUnavailable
 
-	data := struct {
-		Status string `json:"status"`
-	}{
-		Status: status,
+	case !api.warmup.Ready():
+		data.Status = "warming up"
+		statusCode = http.StatusServiceUnavailable
+
+	default:
+		if err := sqldb.StatusCheck(ctx, api.db); err != nil {
+			data.Status = "not ready"
+			data.Dependency = "database"
+			statusCode = http.StatusServiceUnavailable
+			api.log.Info(ctx, "readiness failure", "dependency", data.Dependency, "ERROR", err)
+		}
 	}
 
 	return web.Respond(ctx, w, data, statusCode)
@@ -65,6 +89,8 @@ func (api *api) liveness(ctx context.Context, w http.ResponseWriter, r *http.Req
 		Node       string `json:"node,omitempty"`
 		Namespace  string `json:"namespace,omitempty"`
 		GOMAXPROCS int    `json:"GOMAXPROCS,omitempty"`
+		Uptime     string `json:"uptime,omitempty"`
+		Started    string `json:"started,omitempty"`
 	}{
 		Status:     "up",
 		Build:      api.build,
@@ -74,6 +100,8 @@ func (api *api) liveness(ctx context.Context, w http.ResponseWriter, r *http.Req
 		Node:       os.Getenv("KUBERNETES_NODE_NAME"),
 		Namespace:  os.Getenv("KUBERNETES_NAMESPACE"),
 		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Uptime:     time.Since(api.started).Truncate(time.Second).String(),
+		Started:    api.started.UTC().Format(time.RFC3339),
 	}
 
 	// This handler provides a free timer loop.
@@ -3,20 +3,28 @@ package checkapi
 import (
 	"github.com/jmoiron/sqlx"
 	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/openapi"
+	"github.com/mrcruz117/al-service/foundation/warmup"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Build string
-	Log   *logger.Logger
-	DB    *sqlx.DB
+	Build  string
+	Log    *logger.Logger
+	DB     *sqlx.DB
+	Warmup *warmup.Warmup
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
-	api := newAPI(cfg.Build, cfg.Log, cfg.DB)
+	api := newAPI(cfg.Build, cfg.Log, cfg.DB, cfg.Warmup)
 
 	app.HandleFuncNoMiddleware("GET /liveness", api.liveness)
 	app.HandleFuncNoMiddleware("GET /readiness", api.readiness)
+
+	tags := []string{"health"}
+
+	app.Document("GET /liveness", openapi.Operation{Summary: "Report the service is running", Tags: tags, Public: true})
+	app.Document("GET /readiness", openapi.Operation{Summary: "Report whether the service can take traffic", Tags: tags, Public: true})
 }
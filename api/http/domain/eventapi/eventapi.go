@@ -0,0 +1,130 @@
+// Package eventapi maintains the web based api for following the events of
+// the system.
+package eventapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/feed"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log       *logger.Logger
+	feed      *feed.Feed
+	maxWait   time.Duration
+	maxEvents int
+}
+
+func newAPI(log *logger.Logger, feed *feed.Feed, maxWait time.Duration, maxEvents int) *api {
+	return &api{
+		log:       log,
+		feed:      feed,
+		maxWait:   maxWait,
+		maxEvents: maxEvents,
+	}
+}
+
+// event represents an event delivered to a client.
+type event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data any    `json:"data"`
+	Time string `json:"time"`
+}
+
+// eventPage represents the events returned by a poll. Cursor is passed on
+// the next poll to resume after the last event seen. Missed is set when the
+// client fell so far behind that events were dropped before it read them.
+type eventPage struct {
+	Events []event `json:"events"`
+	Cursor string  `json:"cursor"`
+	Missed bool    `json:"missed,omitempty"`
+}
+
+// poll holds the request open until events after the cursor are available
+// or the wait time runs out, for clients on networks that buffer streaming
+// responses. Without a cursor the client starts from the latest event.
+func (api *api) poll(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cursor := api.feed.Head()
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		c, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "cursor: %s", err)
+		}
+		cursor = c
+	}
+
+	wait := api.maxWait
+	if v := r.URL.Query().Get("wait"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return errs.Newf(errs.InvalidArgument, "wait: must be a positive duration")
+		}
+		wait = min(d, api.maxWait)
+	}
+
+	limit := api.maxEvents
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return errs.Newf(errs.InvalidArgument, "limit: must be a positive number")
+		}
+		limit = min(n, api.maxEvents)
+	}
+
+	// The wait can outlast the server's write timeout, so the deadline is
+	// extended for this response only.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(wait + 5*time.Second)); err != nil {
+		api.log.Info(ctx, "poll: extending write deadline", "msg", err)
+	}
+
+	tenantID := mid.GetClaims(ctx).Tenant
+	match := func(evt feed.Event) bool {
+		return evt.TenantID == "" || evt.TenantID == tenantID
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	for {
+		page := api.feed.Read(cursor, limit, match)
+		if len(page.Events) > 0 || page.Missed {
+			return web.Respond(ctx, w, toEventPage(page), http.StatusOK)
+		}
+		cursor = page.Cursor
+
+		if err := api.feed.Wait(waitCtx, cursor); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return web.Respond(ctx, w, toEventPage(page), http.StatusOK)
+			}
+			return err
+		}
+	}
+}
+
+func toEventPage(page feed.Page) eventPage {
+	events := make([]event, len(page.Events))
+	for i, evt := range page.Events {
+		events[i] = event{
+			ID:   strconv.FormatUint(evt.Seq, 10),
+			Type: evt.Type,
+			Data: evt.Data,
+			Time: evt.Time.Format(time.RFC3339Nano),
+		}
+	}
+
+	return eventPage{
+		Events: events,
+		Cursor: strconv.FormatUint(page.Cursor, 10),
+		Missed: page.Missed,
+	}
+}
@@ -0,0 +1,44 @@
+package eventapi
+
+import (
+	"time"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/feed"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	AuthClient *authclient.Client
+	Feed       *feed.Feed
+	MaxWait    time.Duration
+	MaxEvents  int
+	MaxPolls   int
+	Subsystems *subsystem.Set
+	Usage      *usage.Core
+}
+
+// PollRoute is the pattern of the long poll. A poll waits for events for up
+// to the max wait, so it is limited by the max polls instead of the limit of
+// the service, where waiting clients would take every slot.
+const PollRoute = "GET /v1/events/poll"
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	athAny := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAny)
+	paused := mid.Paused(cfg.Subsystems.Register("events"))
+	usg := mid.Usage(cfg.Usage)
+	maxPolls := mid.MaxInflight(cfg.MaxPolls)
+
+	api := newAPI(cfg.Log, cfg.Feed, cfg.MaxWait, cfg.MaxEvents)
+
+	app.HandleFunc(PollRoute, api.poll, maxPolls, authen, athAny, paused, usg)
+}
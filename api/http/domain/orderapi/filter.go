@@ -0,0 +1,96 @@
+package orderapi
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/order"
+)
+
+// orderByFields maps the names clients order by to the order fields.
+var orderByFields = map[string]string{
+	"id":          order.OrderByID,
+	"userID":      order.OrderByUserID,
+	"status":      order.OrderByStatus,
+	"total":       order.OrderByTotal,
+	"dateCreated": order.OrderByDateCreated,
+}
+
+// parseFilter reads the filter of a list request from the query string.
+// Dates are in RFC 3339 form.
+func parseFilter(qp url.Values) (order.QueryFilter, error) {
+	var fe errs.FieldErrors
+	var filter order.QueryFilter
+
+	if v := qp.Get("id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			fe.Add("id", "must be a valid id")
+		}
+		filter.ID = &id
+	}
+
+	if v := qp.Get("userID"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			fe.Add("userID", "must be a valid id")
+		}
+		filter.UserID = &id
+	}
+
+	if v := qp.Get("status"); v != "" {
+		status := order.Status(v)
+		filter.Status = &status
+	}
+
+	if v := qp.Get("startCreatedDate"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fe.Add("startCreatedDate", "must be an RFC 3339 time")
+		}
+		filter.StartCreatedDate = &t
+	}
+
+	if v := qp.Get("endCreatedDate"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fe.Add("endCreatedDate", "must be an RFC 3339 time")
+		}
+		filter.EndCreatedDate = &t
+	}
+
+	if v := qp.Get("withDeleted"); v != "" {
+		withDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			fe.Add("withDeleted", "must be true or false")
+		}
+		filter.WithDeleted = withDeleted
+	}
+
+	if v := qp.Get("withArchived"); v != "" {
+		withArchived, err := strconv.ParseBool(v)
+		if err != nil {
+			fe.Add("withArchived", "must be true or false")
+		}
+		filter.WithArchived = withArchived
+	}
+
+	if err := fe.ToError(); err != nil {
+		return order.QueryFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return order.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseOrderBy reads the order of a list request from the query string.
+func parseOrderBy(qp url.Values) (orderby.By, error) {
+	return orderby.Parse(orderByFields, qp.Get("orderBy"), order.DefaultOrderBy)
+}
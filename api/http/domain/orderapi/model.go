@@ -0,0 +1,156 @@
+package orderapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/order"
+)
+
+// appOrder represents an order in the api.
+type appOrder struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userID"`
+	Status      string    `json:"status"`
+	Items       []appItem `json:"items"`
+	Total       float64   `json:"total"`
+	Version     int       `json:"version"`
+	DateCreated string    `json:"dateCreated"`
+	DateUpdated string    `json:"dateUpdated"`
+	DateDeleted string    `json:"dateDeleted,omitempty"`
+}
+
+// appItem represents a line of an order in the api.
+type appItem struct {
+	ProductID string  `json:"productID"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+func toAppOrder(ord order.Order) appOrder {
+	items := make([]appItem, len(ord.Items))
+	for i, item := range ord.Items {
+		items[i] = appItem{
+			ProductID: item.ProductID.String(),
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+		}
+	}
+
+	app := appOrder{
+		ID:          ord.ID.String(),
+		UserID:      ord.UserID.String(),
+		Status:      string(ord.Status),
+		Items:       items,
+		Total:       ord.Total,
+		Version:     ord.Version,
+		DateCreated: ord.DateCreated.Format(time.RFC3339),
+		DateUpdated: ord.DateUpdated.Format(time.RFC3339),
+	}
+
+	if !ord.DateDeleted.IsZero() {
+		app.DateDeleted = ord.DateDeleted.Format(time.RFC3339)
+	}
+
+	return app
+}
+
+func toAppOrders(ords []order.Order) []appOrder {
+	items := make([]appOrder, len(ords))
+	for i, ord := range ords {
+		items[i] = toAppOrder(ord)
+	}
+
+	return items
+}
+
+// newOrder represents the information needed to place an order. The order
+// is placed for the caller.
+type newOrder struct {
+	Items []newItem `json:"items"`
+}
+
+// newItem represents a line of a new order.
+type newItem struct {
+	ProductID string `json:"productID"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (no newOrder) Validate() error {
+	var fe errs.FieldErrors
+
+	if len(no.Items) == 0 {
+		fe.Add("items", "must have at least one item")
+	}
+
+	for i, item := range no.Items {
+		if _, err := uuid.Parse(item.ProductID); err != nil {
+			fe.Add(fmt.Sprintf("items[%d].productID", i), "must be a valid id")
+		}
+
+		if item.Quantity < 1 {
+			fe.Add(fmt.Sprintf("items[%d].quantity", i), "must be at least 1")
+		}
+	}
+
+	return fe.ToError()
+}
+
+func toCoreExpect(exp *expectOrder) order.Expect {
+	var ce order.Expect
+
+	if exp != nil && exp.Status != nil {
+		status := order.Status(*exp.Status)
+		ce.Status = &status
+	}
+
+	return ce
+}
+
+func toCoreNewItems(items []newItem) []order.NewItem {
+	nis := make([]order.NewItem, len(items))
+	for i, item := range items {
+		nis[i] = order.NewItem{
+			ProductID: uuid.MustParse(item.ProductID),
+			Quantity:  item.Quantity,
+		}
+	}
+
+	return nis
+}
+
+// transition represents a change to the status of an order. Expect, when
+// set, only applies the change if the order is in the expected state.
+type transition struct {
+	Status string       `json:"status"`
+	Expect *expectOrder `json:"expect"`
+}
+
+// expectOrder represents the expected state of an order.
+type expectOrder struct {
+	Status *string `json:"status"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (t transition) Validate() error {
+	var fe errs.FieldErrors
+
+	switch order.Status(t.Status) {
+	case order.StatusPaid, order.StatusShipped, order.StatusDelivered, order.StatusCancelled:
+	default:
+		fe.Add("status", "must be one of paid, shipped, delivered or cancelled")
+	}
+
+	if t.Expect != nil && t.Expect.Status != nil {
+		switch order.Status(*t.Expect.Status) {
+		case order.StatusPending, order.StatusPaid, order.StatusShipped, order.StatusDelivered, order.StatusCancelled:
+		default:
+			fe.Add("expect.status", "must be one of pending, paid, shipped, delivered or cancelled")
+		}
+	}
+
+	return fe.ToError()
+}
@@ -0,0 +1,273 @@
+// Package orderapi maintains the web based api for order access.
+package orderapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/precondition"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log        *logger.Logger
+	authClient *authclient.Client
+	orderCore  *order.Core
+	beginner   transaction.Beginner
+}
+
+func newAPI(log *logger.Logger, authClient *authclient.Client, orderCore *order.Core, beginner transaction.Beginner) *api {
+	return &api{
+		log:        log,
+		authClient: authClient,
+		orderCore:  orderCore,
+		beginner:   beginner,
+	}
+}
+
+func (api *api) query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qp := r.URL.Query()
+
+	pg, err := page.Parse(qp.Get("page"), qp.Get("rows"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	orderBy, err := parseOrderBy(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ords, err := api.orderCore.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	total, err := api.orderCore.Count(ctx, filter)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, query.NewResult(toAppOrders(ords), total, pg), http.StatusOK)
+}
+
+func (api *api) queryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ord, err := api.order(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := api.authorize(ctx, ord, auth.RuleAdminOrSubject); err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppOrder(ord), http.StatusOK)
+}
+
+func (api *api) queryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "user_id: %s", err)
+	}
+
+	ords, err := api.orderCore.QueryByUserID(ctx, userID)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toAppOrders(ords), http.StatusOK)
+}
+
+func (api *api) create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var no newOrder
+	if err := web.Decode(r, &no); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	// The user and products are checked in the same transaction the order
+	// and its audit records are written in.
+	var ord order.Order
+	f := func(tx transaction.Transaction) error {
+		orderCore, err := api.orderCore.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		ord, err = orderCore.Create(ctx, order.NewOrder{
+			UserID: userID,
+			Items:  toCoreNewItems(no.Items),
+		})
+
+		return err
+	}
+
+	if err := transaction.Execute(ctx, api.beginner, f); err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound), errors.Is(err, order.ErrUserDisabled):
+			return errs.Newf(errs.FailedPrecondition, "orders can only be placed by enabled users")
+		case errors.Is(err, tenant.ErrNoTenant):
+			return errs.New(errs.PermissionDenied, err)
+		case errors.Is(err, product.ErrNotFound):
+			return errs.New(errs.FailedPrecondition, err)
+		default:
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppOrder(ord), http.StatusCreated)
+}
+
+// transition moves an order to a new status. Only an admin moves an order
+// along, the user that placed it may only cancel it.
+func (api *api) transition(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var t transition
+	if err := web.Decode(r, &t); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ord, err := api.order(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	status := order.Status(t.Status)
+
+	rule := auth.RuleAdminOnly
+	if status == order.StatusCancelled {
+		rule = auth.RuleAdminOrSubject
+	}
+
+	if err := api.authorize(ctx, ord, rule); err != nil {
+		return err
+	}
+
+	ord, err = api.orderCore.TransitionIf(ctx, ord, status, toCoreExpect(t.Expect))
+	if err != nil {
+		var pe *precondition.Error
+		switch {
+		case errors.As(err, &pe), errors.Is(err, order.ErrInvalidTransition):
+			return errs.New(errs.FailedPrecondition, err)
+		case errors.Is(err, order.ErrVersionConflict):
+			return errs.New(errs.Aborted, err)
+		case errors.Is(err, order.ErrNotFound):
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toAppOrder(ord), http.StatusOK)
+}
+
+// delete soft deletes the order. An If-Match with the version of the order
+// makes the delete fail if the order was changed since the caller read it.
+func (api *api) delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ord, err := api.order(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if tag, ok := web.IfMatch(r); ok {
+		version, err := strconv.Atoi(tag)
+		if err != nil || version < 1 {
+			return errs.Newf(errs.InvalidArgument, "If-Match: %q is not a version", tag)
+		}
+
+		if version != ord.Version {
+			return errs.Newf(errs.Aborted, "version %d: %s", version, order.ErrVersionConflict)
+		}
+	}
+
+	if err := api.orderCore.Delete(ctx, ord); err != nil {
+		switch {
+		case errors.Is(err, order.ErrVersionConflict):
+			return errs.New(errs.Aborted, err)
+		case errors.Is(err, order.ErrNotFound):
+			return errs.New(errs.NotFound, err)
+		default:
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+func (api *api) restore(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	orderID, err := uuid.Parse(web.Param(r, "order_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "order_id: %s", err)
+	}
+
+	ord, err := api.orderCore.Restore(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toAppOrder(ord), http.StatusOK)
+}
+
+// =============================================================================
+
+// order loads the order named by the path.
+func (api *api) order(ctx context.Context, r *http.Request) (order.Order, error) {
+	orderID, err := uuid.Parse(web.Param(r, "order_id"))
+	if err != nil {
+		return order.Order{}, errs.Newf(errs.InvalidArgument, "order_id: %s", err)
+	}
+
+	ord, err := api.orderCore.QueryByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			return order.Order{}, errs.New(errs.NotFound, err)
+		}
+		return order.Order{}, errs.New(errs.Internal, err)
+	}
+
+	return ord, nil
+}
+
+// authorize checks the rule against the user that placed the order, which
+// can't be decided until the order is loaded.
+func (api *api) authorize(ctx context.Context, ord order.Order, rule string) error {
+	err := api.authClient.Authorize(ctx, authclient.Authorize{
+		Claims: mid.GetClaims(ctx),
+		UserID: ord.UserID,
+		Rule:   rule,
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, authclient.ErrUnavailable):
+		return errs.New(errs.Unavailable, err)
+	default:
+		return errs.New(errs.PermissionDenied, err)
+	}
+}
@@ -0,0 +1,59 @@
+package orderapi
+
+import (
+	"net/http"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/openapi"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	AuthClient *authclient.Client
+	OrderCore  *order.Core
+	Beginner   transaction.Beginner
+	Tenant     *tenant.Core
+	Usage      *usage.Core
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	tnt := mid.Tenant(cfg.Tenant)
+	athAny := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAny)
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	athSubject := mid.AuthorizeSubject(cfg.Log, cfg.AuthClient, "user_id", auth.RuleAdminOrSubject)
+	scpRead := mid.RequireScope(auth.ScopeSalesRead)
+	scpWrite := mid.RequireScope(auth.ScopeSalesWrite)
+	usg := mid.Usage(cfg.Usage)
+
+	api := newAPI(cfg.Log, cfg.AuthClient, cfg.OrderCore, cfg.Beginner)
+
+	app.HandleFunc("GET /v1/orders", api.query, authen, tnt, athAdminOnly, scpRead, usg)
+	app.HandleFunc("GET /v1/orders/{order_id}", api.queryByID, authen, tnt, athAny, scpRead, usg)
+	app.HandleFunc("GET /v1/users/{user_id}/orders", api.queryByUserID, authen, tnt, athSubject, scpRead, usg)
+	app.HandleFunc("POST /v1/orders", api.create, authen, tnt, athAny, scpWrite, usg)
+	app.HandleFunc("PUT /v1/orders/{order_id}/status", api.transition, authen, tnt, athAny, scpWrite, usg)
+	app.HandleFunc("DELETE /v1/orders/{order_id}", api.delete, authen, tnt, athAdminOnly, scpWrite, usg)
+	app.HandleFunc("POST /v1/orders/{order_id}/restore", api.restore, authen, tnt, athAdminOnly, scpWrite, usg)
+
+	tags := []string{"orders"}
+
+	app.Document("GET /v1/orders", openapi.Operation{Summary: "List orders", Tags: tags, Response: query.Result[appOrder]{}})
+	app.Document("GET /v1/orders/{order_id}", openapi.Operation{Summary: "Get an order", Tags: tags, Response: appOrder{}})
+	app.Document("GET /v1/users/{user_id}/orders", openapi.Operation{Summary: "List the orders of a user", Tags: tags, Response: query.Result[appOrder]{}})
+	app.Document("POST /v1/orders", openapi.Operation{Summary: "Place an order", Tags: tags, Request: newOrder{}, Response: appOrder{}, Status: http.StatusCreated})
+	app.Document("PUT /v1/orders/{order_id}/status", openapi.Operation{Summary: "Change the status of an order", Tags: tags, Request: transition{}, Response: appOrder{}})
+	app.Document("DELETE /v1/orders/{order_id}", openapi.Operation{Summary: "Delete an order", Tags: tags, Status: http.StatusNoContent})
+	app.Document("POST /v1/orders/{order_id}/restore", openapi.Operation{Summary: "Restore a deleted order", Tags: tags, Response: appOrder{}})
+}
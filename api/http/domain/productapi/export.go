@@ -0,0 +1,129 @@
+package productapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// exporter writes products in one of the formats they can be exported in.
+type exporter interface {
+	write(prd appProduct) error
+	flush() error
+}
+
+// exportFormat describes a format products can be exported in.
+type exportFormat struct {
+	contentType string
+	extension   string
+	new         func(w io.Writer) exporter
+}
+
+// exportFormats maps the names of the formats clients ask for to each
+// format.
+var exportFormats = map[string]exportFormat{
+	"csv": {
+		contentType: "text/csv; charset=utf-8",
+		extension:   "csv",
+		new:         newCSVExporter,
+	},
+	"ndjson": {
+		contentType: "application/x-ndjson",
+		extension:   "ndjson",
+		new:         newNDJSONExporter,
+	},
+}
+
+// parseExportFormat returns the format named by the query string, csv when
+// none is named.
+func parseExportFormat(name string) (exportFormat, error) {
+	if name == "" {
+		name = "csv"
+	}
+
+	format, exists := exportFormats[name]
+	if !exists {
+		return exportFormat{}, fmt.Errorf("format %q is not supported, use csv or ndjson", name)
+	}
+
+	return format, nil
+}
+
+// =============================================================================
+
+// csvHeader names the columns of a CSV export after the fields of the json
+// api.
+var csvHeader = []string{"id", "userID", "name", "cost", "quantity", "version", "dateCreated", "dateUpdated", "dateDeleted"}
+
+type csvExporter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVExporter(w io.Writer) exporter {
+	return &csvExporter{w: csv.NewWriter(w)}
+}
+
+func (e *csvExporter) write(prd appProduct) error {
+	if !e.wroteHeader {
+		if err := e.w.Write(csvHeader); err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+		e.wroteHeader = true
+	}
+
+	record := []string{
+		prd.ID,
+		prd.UserID,
+		prd.Name,
+		strconv.FormatFloat(prd.Cost, 'f', 2, 64),
+		strconv.Itoa(prd.Quantity),
+		strconv.Itoa(prd.Version),
+		prd.DateCreated,
+		prd.DateUpdated,
+		prd.DateDeleted,
+	}
+
+	if err := e.w.Write(record); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+func (e *csvExporter) flush() error {
+	// An empty export still gets its header.
+	if !e.wroteHeader {
+		if err := e.w.Write(csvHeader); err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+		e.wroteHeader = true
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// =============================================================================
+
+type ndjsonExporter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONExporter(w io.Writer) exporter {
+	return &ndjsonExporter{enc: json.NewEncoder(w)}
+}
+
+func (e *ndjsonExporter) write(prd appProduct) error {
+	if err := e.enc.Encode(prd); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	return nil
+}
+
+func (e *ndjsonExporter) flush() error {
+	return nil
+}
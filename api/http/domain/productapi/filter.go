@@ -0,0 +1,85 @@
+package productapi
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+// orderByFields maps the names clients order by to the product fields.
+var orderByFields = map[string]string{
+	"id":       product.OrderByID,
+	"userID":   product.OrderByUserID,
+	"name":     product.OrderByName,
+	"cost":     product.OrderByCost,
+	"quantity": product.OrderByQuantity,
+}
+
+// parseFilter reads the filter of a list request from the query string.
+func parseFilter(qp url.Values) (product.QueryFilter, error) {
+	var fe errs.FieldErrors
+	var filter product.QueryFilter
+
+	if v := qp.Get("id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			fe.Add("id", "must be a valid id")
+		}
+		filter.ID = &id
+	}
+
+	if v := qp.Get("userID"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			fe.Add("userID", "must be a valid id")
+		}
+		filter.UserID = &id
+	}
+
+	if v := qp.Get("name"); v != "" {
+		filter.Name = &v
+	}
+
+	if v := qp.Get("minCost"); v != "" {
+		cost, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			fe.Add("minCost", "must be a number")
+		}
+		filter.MinCost = &cost
+	}
+
+	if v := qp.Get("maxCost"); v != "" {
+		cost, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			fe.Add("maxCost", "must be a number")
+		}
+		filter.MaxCost = &cost
+	}
+
+	if v := qp.Get("withDeleted"); v != "" {
+		withDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			fe.Add("withDeleted", "must be true or false")
+		}
+		filter.WithDeleted = withDeleted
+	}
+
+	if err := fe.ToError(); err != nil {
+		return product.QueryFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return product.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseOrderBy reads the order of a list request from the query string.
+func parseOrderBy(qp url.Values) (order.By, error) {
+	return order.Parse(orderByFields, qp.Get("orderBy"), product.DefaultOrderBy)
+}
@@ -0,0 +1,131 @@
+package productapi
+
+import (
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+// appProduct represents a product in the api.
+type appProduct struct {
+	ID          string  `json:"id"`
+	UserID      string  `json:"userID"`
+	Name        string  `json:"name"`
+	Cost        float64 `json:"cost"`
+	Quantity    int     `json:"quantity"`
+	Version     int     `json:"version"`
+	DateCreated string  `json:"dateCreated"`
+	DateUpdated string  `json:"dateUpdated"`
+	DateDeleted string  `json:"dateDeleted,omitempty"`
+}
+
+func toAppProduct(prd product.Product) appProduct {
+	app := appProduct{
+		ID:          prd.ID.String(),
+		UserID:      prd.UserID.String(),
+		Name:        prd.Name,
+		Cost:        prd.Cost,
+		Quantity:    prd.Quantity,
+		Version:     prd.Version,
+		DateCreated: prd.DateCreated.Format(time.RFC3339),
+		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+	}
+
+	if !prd.DateDeleted.IsZero() {
+		app.DateDeleted = prd.DateDeleted.Format(time.RFC3339)
+	}
+
+	return app
+}
+
+func toAppProducts(prds []product.Product) []appProduct {
+	items := make([]appProduct, len(prds))
+	for i, prd := range prds {
+		items[i] = toAppProduct(prd)
+	}
+
+	return items
+}
+
+// appSearchResult represents a product that matched a search. Highlight is
+// the name as HTML with the matching words wrapped in mark elements.
+type appSearchResult struct {
+	appProduct
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+func toAppSearchResults(results []product.SearchResult) []appSearchResult {
+	items := make([]appSearchResult, len(results))
+	for i, res := range results {
+		items[i] = appSearchResult{
+			appProduct: toAppProduct(res.Product),
+			Rank:       res.Rank,
+			Highlight:  res.Highlight,
+		}
+	}
+
+	return items
+}
+
+// newProduct represents the information needed to add a product. The
+// product is owned by the caller.
+type newProduct struct {
+	Name     string  `json:"name"`
+	Cost     float64 `json:"cost"`
+	Quantity int     `json:"quantity"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (np newProduct) Validate() error {
+	var fe errs.FieldErrors
+
+	if np.Name == "" {
+		fe.Add("name", "is required")
+	}
+
+	if np.Cost < 0 {
+		fe.Add("cost", "must not be negative")
+	}
+
+	if np.Quantity < 1 {
+		fe.Add("quantity", "must be at least 1")
+	}
+
+	return fe.ToError()
+}
+
+// putProduct represents a product created or replaced with an ID chosen by
+// the client. Version, when set, is the version of the product the
+// replacement was made against, it is rejected if the product has changed
+// since.
+type putProduct struct {
+	Name     string  `json:"name"`
+	Cost     float64 `json:"cost"`
+	Quantity int     `json:"quantity"`
+	Version  *int    `json:"version"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (pp putProduct) Validate() error {
+	var fe errs.FieldErrors
+
+	if pp.Name == "" {
+		fe.Add("name", "is required")
+	}
+
+	if pp.Cost < 0 {
+		fe.Add("cost", "must not be negative")
+	}
+
+	if pp.Quantity < 1 {
+		fe.Add("quantity", "must be at least 1")
+	}
+
+	if pp.Version != nil && *pp.Version < 1 {
+		fe.Add("version", "must be at least 1")
+	}
+
+	return fe.ToError()
+}
@@ -0,0 +1,337 @@
+// Package productapi maintains the web based api for product access.
+package productapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log         *logger.Logger
+	authClient  *authclient.Client
+	productCore *product.Core
+}
+
+func newAPI(log *logger.Logger, authClient *authclient.Client, productCore *product.Core) *api {
+	return &api{
+		log:         log,
+		authClient:  authClient,
+		productCore: productCore,
+	}
+}
+
+func (api *api) query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qp := r.URL.Query()
+
+	pg, err := page.Parse(qp.Get("page"), qp.Get("rows"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	orderBy, err := parseOrderBy(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if filter.WithDeleted {
+		if err := api.authorize(ctx, uuid.Nil, auth.RuleAdminOnly); err != nil {
+			return err
+		}
+	}
+
+	prds, err := api.productCore.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	total, err := api.productCore.Count(ctx, filter)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, query.NewResult(toAppProducts(prds), total, pg), http.StatusOK)
+}
+
+// export streams every product matching the filter as CSV or NDJSON, as
+// asked for by the format parameter. The products are read and sent a batch
+// at a time so the export can be any size.
+func (api *api) export(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qp := r.URL.Query()
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	format, err := parseExportFormat(qp.Get("format"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	stream := func(w io.Writer, flush func() error) error {
+		exp := format.new(w)
+
+		f := func(prds []product.Product) error {
+			for _, prd := range prds {
+				if err := exp.write(toAppProduct(prd)); err != nil {
+					return err
+				}
+			}
+
+			if err := exp.flush(); err != nil {
+				return err
+			}
+
+			return flush()
+		}
+
+		if err := api.productCore.Export(ctx, filter, f); err != nil {
+			return err
+		}
+
+		return exp.flush()
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format.extension))
+
+	if err := web.Stream(ctx, w, format.contentType, http.StatusOK, stream); err != nil {
+		// The status has been sent by now, so the client is left with a
+		// truncated export and the error can only be logged.
+		api.log.Error(ctx, "productapi", "status", "exporting products", "msg", err)
+	}
+
+	return nil
+}
+
+// search finds the products whose name matches the q parameter, best match
+// first.
+func (api *api) search(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qp := r.URL.Query()
+
+	pg, err := page.Parse(qp.Get("page"), qp.Get("rows"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	q := qp.Get("q")
+
+	results, err := api.productCore.Search(ctx, q, pg)
+	if err != nil {
+		if errors.Is(err, product.ErrInvalidQuery) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	total, err := api.productCore.SearchCount(ctx, q)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, query.NewResult(toAppSearchResults(results), total, pg), http.StatusOK)
+}
+
+func (api *api) queryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	prd, err := api.product(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppProduct(prd), http.StatusOK)
+}
+
+func (api *api) create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var np newProduct
+	if err := web.Decode(r, &np); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	prd, err := api.productCore.Create(ctx, product.NewProduct{
+		UserID:   userID,
+		Name:     np.Name,
+		Cost:     np.Cost,
+		Quantity: np.Quantity,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound), errors.Is(err, product.ErrUserDisabled):
+			return errs.Newf(errs.FailedPrecondition, "products can only be added by enabled users")
+		case errors.Is(err, tenant.ErrNoTenant):
+			return errs.New(errs.PermissionDenied, err)
+		default:
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppProduct(prd), http.StatusCreated)
+}
+
+// upsert creates the product with the ID chosen by the client, owned by the
+// caller, or replaces the product that already has it when the caller owns
+// it or is an admin. Sending If-None-Match: * only allows the product to be
+// created.
+func (api *api) upsert(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	productID, err := web.ParseClientID(web.Param(r, "product_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "product_id: %s", err)
+	}
+
+	var pp putProduct
+	if err := web.Decode(r, &pp); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	createOnly := web.IfNoneMatchAny(r)
+
+	prd, err := api.productCore.QueryByID(ctx, productID)
+	switch {
+	case err == nil && !createOnly:
+		if err := api.authorize(ctx, prd.UserID, auth.RuleAdminOrSubject); err != nil {
+			return err
+		}
+
+	case err != nil && !errors.Is(err, product.ErrNotFound):
+		return errs.New(errs.Internal, err)
+	}
+
+	prd, created, err := api.productCore.Upsert(ctx, productID, product.UpsertProduct{
+		NewProduct: product.NewProduct{
+			UserID:   userID,
+			Name:     pp.Name,
+			Cost:     pp.Cost,
+			Quantity: pp.Quantity,
+		},
+		Version:    pp.Version,
+		CreateOnly: createOnly,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrExists) && createOnly:
+			return errs.New(errs.PreconditionFailed, err)
+		case errors.Is(err, product.ErrExists):
+			return errs.New(errs.AlreadyExists, err)
+		case errors.Is(err, product.ErrVersionConflict):
+			return errs.New(errs.Aborted, err)
+		case errors.Is(err, user.ErrNotFound), errors.Is(err, product.ErrUserDisabled):
+			return errs.Newf(errs.FailedPrecondition, "products can only be added by enabled users")
+		case errors.Is(err, tenant.ErrNoTenant):
+			return errs.New(errs.PermissionDenied, err)
+		default:
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	if created {
+		return web.Respond(ctx, w, toAppProduct(prd), http.StatusCreated)
+	}
+
+	return web.Respond(ctx, w, toAppProduct(prd), http.StatusOK)
+}
+
+func (api *api) delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	prd, err := api.product(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := api.authorize(ctx, prd.UserID, auth.RuleAdminOrSubject); err != nil {
+		return err
+	}
+
+	if err := api.productCore.Delete(ctx, prd); err != nil {
+		if errors.Is(err, product.ErrVersionConflict) {
+			return errs.New(errs.Aborted, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+func (api *api) restore(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	productID, err := uuid.Parse(web.Param(r, "product_id"))
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "product_id: %s", err)
+	}
+
+	prd, err := api.productCore.Restore(ctx, productID)
+	if err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toAppProduct(prd), http.StatusOK)
+}
+
+// =============================================================================
+
+// product loads the product named by the path.
+func (api *api) product(ctx context.Context, r *http.Request) (product.Product, error) {
+	productID, err := uuid.Parse(web.Param(r, "product_id"))
+	if err != nil {
+		return product.Product{}, errs.Newf(errs.InvalidArgument, "product_id: %s", err)
+	}
+
+	prd, err := api.productCore.QueryByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			return product.Product{}, errs.New(errs.NotFound, err)
+		}
+		return product.Product{}, errs.New(errs.Internal, err)
+	}
+
+	return prd, nil
+}
+
+// authorize checks the rule against the user, the owner of the product for
+// the subject rules. It is used when the rule depends on what the request
+// loads or asks for, such as changing a product or listing deleted ones.
+func (api *api) authorize(ctx context.Context, userID uuid.UUID, rule string) error {
+	err := api.authClient.Authorize(ctx, authclient.Authorize{
+		Claims: mid.GetClaims(ctx),
+		UserID: userID,
+		Rule:   rule,
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, authclient.ErrUnavailable):
+		return errs.New(errs.Unavailable, err)
+	default:
+		return errs.New(errs.PermissionDenied, err)
+	}
+}
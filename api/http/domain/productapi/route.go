@@ -0,0 +1,58 @@
+package productapi
+
+import (
+	"net/http"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/openapi"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log         *logger.Logger
+	AuthClient  *authclient.Client
+	ProductCore *product.Core
+	Tenant      *tenant.Core
+	Usage       *usage.Core
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	tnt := mid.Tenant(cfg.Tenant)
+	athAny := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAny)
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	scpRead := mid.RequireScope(auth.ScopeSalesRead)
+	scpWrite := mid.RequireScope(auth.ScopeSalesWrite)
+	usg := mid.Usage(cfg.Usage)
+
+	api := newAPI(cfg.Log, cfg.AuthClient, cfg.ProductCore)
+
+	app.HandleFunc("GET /v1/products", api.query, authen, tnt, athAny, scpRead, usg)
+	app.HandleFunc("GET /v1/products/search", api.search, authen, tnt, athAny, scpRead, usg)
+	app.HandleFunc("GET /v1/products/export", api.export, authen, tnt, athAdminOnly, scpRead, usg)
+	app.HandleFunc("GET /v1/products/{product_id}", api.queryByID, authen, tnt, athAny, scpRead, usg)
+	app.HandleFunc("POST /v1/products", api.create, authen, tnt, athAny, scpWrite, usg)
+	app.HandleFunc("PUT /v1/products/{product_id}", api.upsert, authen, tnt, athAny, scpWrite, usg)
+	app.HandleFunc("DELETE /v1/products/{product_id}", api.delete, authen, tnt, athAny, scpWrite, usg)
+	app.HandleFunc("POST /v1/products/{product_id}/restore", api.restore, authen, tnt, athAdminOnly, scpWrite, usg)
+
+	tags := []string{"products"}
+
+	app.Document("GET /v1/products", openapi.Operation{Summary: "List products", Tags: tags, Response: query.Result[appProduct]{}})
+	app.Document("GET /v1/products/search", openapi.Operation{Summary: "Search products by name", Tags: tags, Response: query.Result[appSearchResult]{}})
+	app.Document("GET /v1/products/export", openapi.Operation{Summary: "Export products as CSV or NDJSON", Tags: tags})
+	app.Document("GET /v1/products/{product_id}", openapi.Operation{Summary: "Get a product", Tags: tags, Response: appProduct{}})
+	app.Document("POST /v1/products", openapi.Operation{Summary: "Add a product", Tags: tags, Request: newProduct{}, Response: appProduct{}, Status: http.StatusCreated})
+	app.Document("PUT /v1/products/{product_id}", openapi.Operation{Summary: "Create or replace a product", Tags: tags, Request: putProduct{}, Response: appProduct{}})
+	app.Document("DELETE /v1/products/{product_id}", openapi.Operation{Summary: "Delete a product", Tags: tags, Status: http.StatusNoContent})
+	app.Document("POST /v1/products/{product_id}/restore", openapi.Operation{Summary: "Restore a deleted product", Tags: tags, Response: appProduct{}})
+}
@@ -0,0 +1,89 @@
+// Package registryapi maintains the web based api for viewing the service
+// instances running in the environment.
+package registryapi
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/registry"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	registry   *registry.Core
+	staleAfter time.Duration
+}
+
+func newAPI(registry *registry.Core, staleAfter time.Duration) *api {
+	return &api{
+		registry:   registry,
+		staleAfter: staleAfter,
+	}
+}
+
+// instance represents a running instance of a service.
+type instance struct {
+	ID          string `json:"id"`
+	Build       string `json:"build"`
+	Host        string `json:"host"`
+	Address     string `json:"address"`
+	Healthy     bool   `json:"healthy"`
+	DateStarted string `json:"dateStarted"`
+	LastSeen    string `json:"lastSeen"`
+}
+
+// service represents the live instances of a service and the builds they
+// are running.
+type service struct {
+	Name      string     `json:"name"`
+	Builds    []string   `json:"builds"`
+	Healthy   int        `json:"healthy"`
+	Instances []instance `json:"instances"`
+}
+
+func (api *api) query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	insts, err := api.registry.QueryLive(ctx, api.staleAfter)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toServices(insts), http.StatusOK)
+}
+
+// toServices groups the instances, which are ordered by service, into
+// their services.
+func toServices(insts []registry.Instance) []service {
+	services := []service{}
+
+	for _, inst := range insts {
+		if len(services) == 0 || services[len(services)-1].Name != inst.Service {
+			services = append(services, service{Name: inst.Service})
+		}
+
+		svc := &services[len(services)-1]
+
+		if !slices.Contains(svc.Builds, inst.Build) {
+			svc.Builds = append(svc.Builds, inst.Build)
+		}
+
+		if inst.Healthy {
+			svc.Healthy++
+		}
+
+		svc.Instances = append(svc.Instances, instance{
+			ID:          inst.ID.String(),
+			Build:       inst.Build,
+			Host:        inst.Host,
+			Address:     inst.Address,
+			Healthy:     inst.Healthy,
+			DateStarted: inst.DateStarted.Format(time.RFC3339),
+			LastSeen:    inst.LastSeen.Format(time.RFC3339),
+		})
+	}
+
+	return services
+}
@@ -0,0 +1,40 @@
+package registryapi
+
+import (
+	"time"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/business/core/registry"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	AuthClient *authclient.Client
+	Registry   *registry.Core
+	StaleAfter time.Duration
+	Usage      *usage.Core
+	Peers      map[string][]string
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	clientCert := mid.ClientCert(cfg.Peers)
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	scpAdmin := mid.RequireScope(auth.ScopeAdmin)
+	usg := mid.Usage(cfg.Usage)
+
+	api := newAPI(cfg.Registry, cfg.StaleAfter)
+
+	app.HandleFunc("GET /v1/services", api.query, authen, athAdminOnly, scpAdmin, usg)
+
+	// The services look each other up authenticated by the client
+	// certificate of their connection.
+	app.HandleFunc("GET /v1/peer/services", api.query, clientCert, athAdminOnly, scpAdmin, usg)
+}
@@ -0,0 +1,183 @@
+package scimapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/group"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+// Set of schema URNs defined by RFC 7643 and RFC 7644.
+const (
+	schemaUser  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup = "urn:ietf:params:scim:schemas:core:2.0:Group"
+)
+
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created"`
+	LastModified string `json:"lastModified"`
+	Location     string `json:"location"`
+}
+
+type name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type email struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// =============================================================================
+
+// scimUser represents a user resource as exchanged with an identity provider.
+type scimUser struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []email  `json:"emails,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	Password    string   `json:"password,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (su scimUser) Validate() error {
+	var fe errs.FieldErrors
+
+	if su.UserName == "" {
+		fe.Add("userName", "is required")
+	}
+
+	return fe.ToError()
+}
+
+// displayName picks the best available name for the user.
+func (su scimUser) displayName() string {
+	switch {
+	case su.DisplayName != "":
+		return su.DisplayName
+	case su.Name != nil && su.Name.Formatted != "":
+		return su.Name.Formatted
+	case su.Name != nil && (su.Name.GivenName != "" || su.Name.FamilyName != ""):
+		return su.Name.GivenName + " " + su.Name.FamilyName
+	}
+
+	return su.UserName
+}
+
+func toSCIMUser(usr user.User) scimUser {
+	active := usr.Enabled
+
+	return scimUser{
+		Schemas:     []string{schemaUser},
+		ID:          usr.ID.String(),
+		UserName:    usr.Email.Address,
+		DisplayName: usr.Name,
+		Name: &name{
+			Formatted: usr.Name,
+		},
+		Emails: []email{
+			{Value: usr.Email.Address, Primary: true},
+		},
+		Active: &active,
+		Roles:  usr.Roles,
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      usr.DateCreated.Format(time.RFC3339),
+			LastModified: usr.DateUpdated.Format(time.RFC3339),
+			Location:     fmt.Sprintf("/scim/v2/Users/%s", usr.ID),
+		},
+	}
+}
+
+// =============================================================================
+
+// scimGroup represents a group resource as exchanged with an identity
+// provider.
+type scimGroup struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []member `json:"members,omitempty"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (sg scimGroup) Validate() error {
+	var fe errs.FieldErrors
+
+	if sg.DisplayName == "" {
+		fe.Add("displayName", "is required")
+	}
+
+	return fe.ToError()
+}
+
+func toSCIMGroup(grp group.Group) scimGroup {
+	members := make([]member, len(grp.Members))
+	for i, userID := range grp.Members {
+		members[i] = member{Value: userID.String()}
+	}
+
+	return scimGroup{
+		Schemas:     []string{schemaGroup},
+		ID:          grp.ID.String(),
+		ExternalID:  grp.ExternalID,
+		DisplayName: grp.DisplayName,
+		Members:     members,
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      grp.DateCreated.Format(time.RFC3339),
+			LastModified: grp.DateUpdated.Format(time.RFC3339),
+			Location:     fmt.Sprintf("/scim/v2/Groups/%s", grp.ID),
+		},
+	}
+}
+
+// =============================================================================
+
+// patchOp represents a SCIM PATCH request.
+type patchOp struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []operation `json:"Operations"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (p patchOp) Validate() error {
+	var fe errs.FieldErrors
+
+	if len(p.Operations) == 0 {
+		fe.Add("Operations", "is required")
+	}
+
+	for i, op := range p.Operations {
+		switch op.Op {
+		case "add", "Add", "replace", "Replace", "remove", "Remove":
+		default:
+			fe.Add(fmt.Sprintf("Operations.%d.op", i), fmt.Sprintf("unsupported op %q", op.Op))
+		}
+	}
+
+	return fe.ToError()
+}
+
+type operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
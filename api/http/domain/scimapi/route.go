@@ -0,0 +1,36 @@
+package scimapi
+
+import (
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/business/core/group"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log       *logger.Logger
+	Auth      *auth.Auth
+	UserCore  *user.Core
+	GroupCore *group.Core
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	bearer := mid.Bearer(cfg.Auth)
+	athAdminOnly := mid.AuthorizeLocal(cfg.Auth, auth.RuleAdminOnly)
+
+	api := newAPI(cfg.Log, cfg.UserCore, cfg.GroupCore)
+
+	app.HandleFunc("POST /scim/v2/Users", api.createUser, bearer, athAdminOnly)
+	app.HandleFunc("GET /scim/v2/Users/{id}", api.queryUserByID, bearer, athAdminOnly)
+	app.HandleFunc("PATCH /scim/v2/Users/{id}", api.updateUser, bearer, athAdminOnly)
+	app.HandleFunc("DELETE /scim/v2/Users/{id}", api.deactivateUser, bearer, athAdminOnly)
+
+	app.HandleFunc("POST /scim/v2/Groups", api.createGroup, bearer, athAdminOnly)
+	app.HandleFunc("GET /scim/v2/Groups/{id}", api.queryGroupByID, bearer, athAdminOnly)
+	app.HandleFunc("PATCH /scim/v2/Groups/{id}", api.updateGroup, bearer, athAdminOnly)
+	app.HandleFunc("DELETE /scim/v2/Groups/{id}", api.deleteGroup, bearer, athAdminOnly)
+}
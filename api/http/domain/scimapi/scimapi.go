@@ -0,0 +1,450 @@
+// Package scimapi maintains the web based api for SCIM 2.0 provisioning so
+// identity providers can manage users and their group based roles.
+package scimapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/group"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log       *logger.Logger
+	userCore  *user.Core
+	groupCore *group.Core
+}
+
+func newAPI(log *logger.Logger, userCore *user.Core, groupCore *group.Core) *api {
+	return &api{
+		log:       log,
+		userCore:  userCore,
+		groupCore: groupCore,
+	}
+}
+
+func (api *api) createUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var su scimUser
+	if err := web.Decode(r, &su); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	addr, err := mail.ParseAddress(su.UserName)
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "userName: %s", err)
+	}
+
+	// Provisioned users normally sign in through the identity provider, so
+	// a random password is set when none is supplied.
+	password := su.Password
+	if password == "" {
+		if password, err = randomPassword(); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	nu := user.NewUser{
+		Name:     su.displayName(),
+		Email:    *addr,
+		Roles:    []string{},
+		Password: password,
+	}
+
+	usr, err := api.userCore.Create(ctx, nu)
+	if err != nil {
+		if errors.Is(err, user.ErrUniqueEmail) {
+			return errs.New(errs.AlreadyExists, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	if su.Active != nil && !*su.Active {
+		active := false
+		if usr, err = api.userCore.Update(ctx, usr, user.UpdateUser{Enabled: &active}); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	api.log.Info(ctx, "scim", "status", "user provisioned", "userID", usr.ID)
+
+	return web.Respond(ctx, w, toSCIMUser(usr), http.StatusCreated)
+}
+
+func (api *api) queryUserByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := api.queryUser(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toSCIMUser(usr), http.StatusOK)
+}
+
+func (api *api) updateUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var p patchOp
+	if err := web.Decode(r, &p); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := api.queryUser(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	var uu user.UpdateUser
+
+	for _, op := range p.Operations {
+		if strings.EqualFold(op.Op, "remove") {
+			continue
+		}
+
+		attrs := map[string]any{op.Path: op.Value}
+		if op.Path == "" {
+			m, ok := op.Value.(map[string]any)
+			if !ok {
+				return errs.Newf(errs.InvalidArgument, "value must be an object when no path is given")
+			}
+			attrs = m
+		}
+
+		for path, value := range attrs {
+			if err := applyUserAttr(&uu, path, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	usr, err = api.userCore.Update(ctx, usr, uu)
+	if err != nil {
+		if errors.Is(err, user.ErrUniqueEmail) {
+			return errs.New(errs.AlreadyExists, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toSCIMUser(usr), http.StatusOK)
+}
+
+// deactivateUser handles the DELETE of a user. Users are disabled rather
+// than removed so their history is retained.
+func (api *api) deactivateUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := api.queryUser(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	active := false
+	if _, err := api.userCore.Update(ctx, usr, user.UpdateUser{Enabled: &active}); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	api.log.Info(ctx, "scim", "status", "user deprovisioned", "userID", usr.ID)
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+
+func (api *api) createGroup(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var sg scimGroup
+	if err := web.Decode(r, &sg); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	members, err := parseMembers(sg.Members)
+	if err != nil {
+		return err
+	}
+
+	ng := group.NewGroup{
+		DisplayName: sg.DisplayName,
+		ExternalID:  sg.ExternalID,
+		Members:     members,
+	}
+
+	grp, err := api.groupCore.Create(ctx, ng)
+	if err != nil {
+		return groupError(err)
+	}
+
+	api.log.Info(ctx, "scim", "status", "group provisioned", "groupID", grp.ID, "role", grp.Role)
+
+	return web.Respond(ctx, w, toSCIMGroup(grp), http.StatusCreated)
+}
+
+func (api *api) queryGroupByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	grp, err := api.queryGroup(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toSCIMGroup(grp), http.StatusOK)
+}
+
+// memberFilter matches the path form used to remove a single member.
+var memberFilter = regexp.MustCompile(`^members\[value eq "([^"]+)"\]$`)
+
+func (api *api) updateGroup(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var p patchOp
+	if err := web.Decode(r, &p); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	grp, err := api.queryGroup(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	var ug group.UpdateGroup
+	members := slices.Clone(grp.Members)
+
+	for _, op := range p.Operations {
+		switch {
+		case strings.EqualFold(op.Path, "members"):
+			ids, err := parseMemberValue(op.Value)
+			if err != nil {
+				return err
+			}
+
+			switch strings.ToLower(op.Op) {
+			case "add":
+				members = addMembers(members, ids)
+			case "remove":
+				members = removeMembers(members, ids)
+				if op.Value == nil {
+					members = []uuid.UUID{}
+				}
+			case "replace":
+				members = ids
+			}
+
+		case memberFilter.MatchString(op.Path) && strings.EqualFold(op.Op, "remove"):
+			id, err := uuid.Parse(memberFilter.FindStringSubmatch(op.Path)[1])
+			if err != nil {
+				return errs.Newf(errs.InvalidArgument, "member: %s", err)
+			}
+			members = removeMembers(members, []uuid.UUID{id})
+
+		case strings.EqualFold(op.Path, "displayName"):
+			s, ok := op.Value.(string)
+			if !ok {
+				return errs.Newf(errs.InvalidArgument, "displayName must be a string")
+			}
+			ug.DisplayName = &s
+
+		case strings.EqualFold(op.Path, "externalId"):
+			s, ok := op.Value.(string)
+			if !ok {
+				return errs.Newf(errs.InvalidArgument, "externalId must be a string")
+			}
+			ug.ExternalID = &s
+
+		case op.Path == "":
+			m, ok := op.Value.(map[string]any)
+			if !ok {
+				return errs.Newf(errs.InvalidArgument, "value must be an object when no path is given")
+			}
+
+			if s, ok := m["displayName"].(string); ok {
+				ug.DisplayName = &s
+			}
+
+			if v, exists := m["members"]; exists {
+				ids, err := parseMemberValue(v)
+				if err != nil {
+					return err
+				}
+				members = ids
+			}
+		}
+	}
+
+	ug.Members = members
+
+	grp, err = api.groupCore.Update(ctx, grp, ug)
+	if err != nil {
+		return groupError(err)
+	}
+
+	return web.Respond(ctx, w, toSCIMGroup(grp), http.StatusOK)
+}
+
+func (api *api) deleteGroup(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	grp, err := api.queryGroup(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := api.groupCore.Delete(ctx, grp); err != nil {
+		return groupError(err)
+	}
+
+	api.log.Info(ctx, "scim", "status", "group deprovisioned", "groupID", grp.ID)
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+
+func (api *api) queryUser(ctx context.Context, r *http.Request) (user.User, error) {
+	userID, err := uuid.Parse(web.Param(r, "id"))
+	if err != nil {
+		return user.User{}, errs.Newf(errs.InvalidArgument, "id: %s", err)
+	}
+
+	usr, err := api.userCore.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return user.User{}, errs.New(errs.NotFound, err)
+		}
+		return user.User{}, errs.New(errs.Internal, err)
+	}
+
+	return usr, nil
+}
+
+func (api *api) queryGroup(ctx context.Context, r *http.Request) (group.Group, error) {
+	groupID, err := uuid.Parse(web.Param(r, "id"))
+	if err != nil {
+		return group.Group{}, errs.Newf(errs.InvalidArgument, "id: %s", err)
+	}
+
+	grp, err := api.groupCore.QueryByID(ctx, groupID)
+	if err != nil {
+		return group.Group{}, groupError(err)
+	}
+
+	return grp, nil
+}
+
+func groupError(err error) error {
+	switch {
+	case errors.Is(err, group.ErrNotFound), errors.Is(err, user.ErrNotFound):
+		return errs.New(errs.NotFound, err)
+	case errors.Is(err, group.ErrUniqueDisplayName):
+		return errs.New(errs.AlreadyExists, err)
+	}
+
+	return errs.New(errs.Internal, err)
+}
+
+// applyUserAttr records the change of a single user attribute. Attributes we
+// don't store are ignored since identity providers send many of them.
+func applyUserAttr(uu *user.UpdateUser, path string, value any) error {
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := parseBool(value)
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "active: %s", err)
+		}
+		uu.Enabled = &active
+
+	case "displayname", "name.formatted":
+		s, ok := value.(string)
+		if !ok {
+			return errs.Newf(errs.InvalidArgument, "%s must be a string", path)
+		}
+		uu.Name = &s
+
+	case "username":
+		s, ok := value.(string)
+		if !ok {
+			return errs.Newf(errs.InvalidArgument, "userName must be a string")
+		}
+
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "userName: %s", err)
+		}
+		uu.Email = addr
+	}
+
+	return nil
+}
+
+// parseBool accepts both JSON booleans and the string form some identity
+// providers send.
+func parseBool(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.ToLower(v))
+	}
+
+	return false, errors.New("must be a boolean")
+}
+
+func parseMembers(ms []member) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(ms))
+	for _, m := range ms {
+		id, err := uuid.Parse(m.Value)
+		if err != nil {
+			return nil, errs.Newf(errs.InvalidArgument, "member: %s", err)
+		}
+		ids = addMembers(ids, []uuid.UUID{id})
+	}
+
+	return ids, nil
+}
+
+func parseMemberValue(value any) ([]uuid.UUID, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	items, ok := value.([]any)
+	if !ok {
+		return nil, errs.Newf(errs.InvalidArgument, "members must be a list")
+	}
+
+	ms := make([]member, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, errs.Newf(errs.InvalidArgument, "member must be an object")
+		}
+
+		v, _ := m["value"].(string)
+		ms = append(ms, member{Value: v})
+	}
+
+	return parseMembers(ms)
+}
+
+func addMembers(members []uuid.UUID, ids []uuid.UUID) []uuid.UUID {
+	for _, id := range ids {
+		if !slices.Contains(members, id) {
+			members = append(members, id)
+		}
+	}
+
+	return members
+}
+
+func removeMembers(members []uuid.UUID, ids []uuid.UUID) []uuid.UUID {
+	return slices.DeleteFunc(members, func(id uuid.UUID) bool {
+		return slices.Contains(ids, id)
+	})
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -4,6 +4,9 @@ import (
 	"github.com/mrcruz117/al-service/api/http/api/mid"
 	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
 	"github.com/mrcruz117/al-service/foundation/logger"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
@@ -12,16 +15,30 @@ import (
 type Config struct {
 	Log        *logger.Logger
 	AuthClient *authclient.Client
+	Usage      *usage.Core
+	Peers      map[string][]string
+	Tenant     *tenant.Core
+	APIKey     *apikey.Core
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
 	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	public := mid.Public()
+	usg := mid.Usage(cfg.Usage)
+	apiKey := mid.APIKey(cfg.Log, cfg.APIKey)
+	clientCert := mid.ClientCert(cfg.Peers)
+	tnt := mid.Tenant(cfg.Tenant)
 
 	api := newAPI()
 
-	app.HandleFunc("GET /testerror", api.testError)
-	app.HandleFunc("GET /testpanic", api.testPanic)
-	app.HandleFunc("GET /testauth", api.testAuth, authen, athAdminOnly)
+	app.HandleFunc("GET /testerror", api.testError, public)
+	app.HandleFunc("GET /testpanic", api.testPanic, public)
+	app.HandleFunc("GET /testauth", api.testAuth, authen, athAdminOnly, usg)
+	app.HandleFunc("GET /testapikey", api.testAuth, apiKey, athAdminOnly, usg)
+	app.HandleFunc("GET /testtenant", api.testTenant, authen, tnt, usg)
+	app.HandleFunc("GET /testmtls", api.testAuth, clientCert, athAdminOnly, usg)
+
+	app.HandleFuncExperimental("test", "GET /testexperimental", api.testAuth, authen, athAdminOnly, usg)
 }
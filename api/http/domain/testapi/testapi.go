@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
 	"github.com/mrcruz117/al-service/foundation/web"
 )
 
@@ -52,3 +53,20 @@ func (api *api) testAuth(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	return web.Respond(ctx, w, status, http.StatusOK)
 }
+
+func (api *api) testTenant(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := mid.GetTenantID(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	status := struct {
+		Status string
+		Tenant string
+	}{
+		Status: "OK",
+		Tenant: tenantID.String(),
+	}
+
+	return web.Respond(ctx, w, status, http.StatusOK)
+}
@@ -0,0 +1,92 @@
+package userapi
+
+import (
+	"net/mail"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+// orderByFields maps the names clients order by to the user fields.
+var orderByFields = map[string]string{
+	"id":          user.OrderByID,
+	"name":        user.OrderByName,
+	"email":       user.OrderByEmail,
+	"enabled":     user.OrderByEnabled,
+	"dateCreated": user.OrderByDateCreated,
+}
+
+// parseFilter reads the filter of a list request from the query string.
+func parseFilter(qp url.Values) (user.QueryFilter, error) {
+	var fe errs.FieldErrors
+	var filter user.QueryFilter
+
+	if v := qp.Get("id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			fe.Add("id", "must be a valid id")
+		}
+		filter.ID = &id
+	}
+
+	if v := qp.Get("name"); v != "" {
+		filter.Name = &v
+	}
+
+	if v := qp.Get("email"); v != "" {
+		addr, err := mail.ParseAddress(v)
+		if err != nil {
+			fe.Add("email", "must be a valid email")
+		} else {
+			filter.Email = addr
+		}
+	}
+
+	if v := qp.Get("department"); v != "" {
+		filter.Department = &v
+	}
+
+	if v := qp.Get("enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			fe.Add("enabled", "must be true or false")
+		}
+		filter.Enabled = &enabled
+	}
+
+	if v := qp.Get("startCreatedDate"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fe.Add("startCreatedDate", "must be an RFC 3339 time")
+		}
+		filter.StartCreatedDate = &t
+	}
+
+	if v := qp.Get("endCreatedDate"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fe.Add("endCreatedDate", "must be an RFC 3339 time")
+		}
+		filter.EndCreatedDate = &t
+	}
+
+	if err := fe.ToError(); err != nil {
+		return user.QueryFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return user.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseOrderBy reads the order of a list request from the query string.
+func parseOrderBy(qp url.Values) (order.By, error) {
+	return order.Parse(orderByFields, qp.Get("orderBy"), user.DefaultOrderBy)
+}
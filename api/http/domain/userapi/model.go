@@ -0,0 +1,232 @@
+package userapi
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+// minPasswordLength is the shortest password a user can be given.
+const minPasswordLength = 8
+
+// appUser represents a user in the api. The password hash is never
+// returned.
+type appUser struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"emailVerified"`
+	Roles         []string `json:"roles"`
+	Department    string   `json:"department"`
+	Enabled       bool     `json:"enabled"`
+	Version       int      `json:"version"`
+	DateCreated   string   `json:"dateCreated"`
+	DateUpdated   string   `json:"dateUpdated"`
+}
+
+func toAppUser(usr user.User) appUser {
+	return appUser{
+		ID:            usr.ID.String(),
+		Name:          usr.Name,
+		Email:         usr.Email.Address,
+		EmailVerified: usr.EmailVerified,
+		Roles:         usr.Roles,
+		Department:    usr.Department,
+		Enabled:       usr.Enabled,
+		Version:       usr.Version,
+		DateCreated:   usr.DateCreated.Format(time.RFC3339),
+		DateUpdated:   usr.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppUsers(usrs []user.User) []appUser {
+	items := make([]appUser, len(usrs))
+	for i, usr := range usrs {
+		items[i] = toAppUser(usr)
+	}
+
+	return items
+}
+
+// newUser represents the information needed to add a user.
+type newUser struct {
+	Name            string   `json:"name"`
+	Email           string   `json:"email"`
+	Roles           []string `json:"roles"`
+	Department      string   `json:"department"`
+	Password        string   `json:"password"`
+	PasswordConfirm string   `json:"passwordConfirm"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (nu newUser) Validate() error {
+	var fe errs.FieldErrors
+
+	if nu.Name == "" {
+		fe.Add("name", "is required")
+	}
+
+	if _, err := mail.ParseAddress(nu.Email); err != nil {
+		fe.Add("email", err.Error())
+	}
+
+	if len(nu.Roles) == 0 {
+		fe.Add("roles", "is required")
+	}
+	validateRoles(&fe, nu.Roles)
+
+	if len(nu.Password) < minPasswordLength {
+		fe.Add("password", fmt.Sprintf("must be at least %d characters", minPasswordLength))
+	}
+
+	if nu.PasswordConfirm != nu.Password {
+		fe.Add("passwordConfirm", "must match password")
+	}
+
+	return fe.ToError()
+}
+
+func toCoreNewUser(nu newUser) (user.NewUser, error) {
+	addr, err := mail.ParseAddress(nu.Email)
+	if err != nil {
+		return user.NewUser{}, fmt.Errorf("parse email: %w", err)
+	}
+
+	usr := user.NewUser{
+		Name:       nu.Name,
+		Email:      *addr,
+		Roles:      nu.Roles,
+		Department: nu.Department,
+		Password:   nu.Password,
+	}
+
+	return usr, nil
+}
+
+// updateUser represents the changes to a user. Fields that are left out are
+// unchanged. Changing the roles or whether the user is enabled is only
+// allowed for admins. Version is the version of the user the changes were
+// made against, the update is rejected if the user has changed since.
+type updateUser struct {
+	Name            *string  `json:"name"`
+	Email           *string  `json:"email"`
+	Roles           []string `json:"roles"`
+	Department      *string  `json:"department"`
+	Password        *string  `json:"password"`
+	PasswordConfirm *string  `json:"passwordConfirm"`
+	Enabled         *bool    `json:"enabled"`
+	Version         *int     `json:"version"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (uu updateUser) Validate() error {
+	var fe errs.FieldErrors
+
+	if uu.Name != nil && *uu.Name == "" {
+		fe.Add("name", "must not be empty")
+	}
+
+	if uu.Email != nil {
+		if _, err := mail.ParseAddress(*uu.Email); err != nil {
+			fe.Add("email", err.Error())
+		}
+	}
+
+	if uu.Roles != nil && len(uu.Roles) == 0 {
+		fe.Add("roles", "must not be empty")
+	}
+	validateRoles(&fe, uu.Roles)
+
+	if uu.Password != nil {
+		if len(*uu.Password) < minPasswordLength {
+			fe.Add("password", fmt.Sprintf("must be at least %d characters", minPasswordLength))
+		}
+
+		if uu.PasswordConfirm == nil || *uu.PasswordConfirm != *uu.Password {
+			fe.Add("passwordConfirm", "must match password")
+		}
+	}
+
+	if uu.Version != nil && *uu.Version < 1 {
+		fe.Add("version", "must be at least 1")
+	}
+
+	return fe.ToError()
+}
+
+// changesAccess reports whether the update changes what the user is
+// allowed to do.
+func (uu updateUser) changesAccess() bool {
+	return uu.Roles != nil || uu.Enabled != nil
+}
+
+func toCoreUpdateUser(uu updateUser) (user.UpdateUser, error) {
+	upd := user.UpdateUser{
+		Name:       uu.Name,
+		Roles:      uu.Roles,
+		Department: uu.Department,
+		Password:   uu.Password,
+		Enabled:    uu.Enabled,
+		Version:    uu.Version,
+	}
+
+	if uu.Email != nil {
+		addr, err := mail.ParseAddress(*uu.Email)
+		if err != nil {
+			return user.UpdateUser{}, fmt.Errorf("parse email: %w", err)
+		}
+		upd.Email = addr
+	}
+
+	return upd, nil
+}
+
+// validateRoles checks every role is one the service knows.
+func validateRoles(fe *errs.FieldErrors, roles []string) {
+	for i, role := range roles {
+		if role != "ADMIN" && role != "USER" {
+			fe.Add(fmt.Sprintf("roles.%d", i), fmt.Sprintf("invalid role %q", role))
+		}
+	}
+}
+
+// updateProfile represents the changes users make to their own account.
+// Changing the password must be confirmed with the current one.
+type updateProfile struct {
+	Name            *string `json:"name"`
+	Email           *string `json:"email"`
+	Department      *string `json:"department"`
+	CurrentPassword *string `json:"currentPassword"`
+	Password        *string `json:"password"`
+	PasswordConfirm *string `json:"passwordConfirm"`
+	Version         *int    `json:"version"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (up updateProfile) Validate() error {
+	if err := up.toUpdateUser().Validate(); err != nil {
+		return err
+	}
+
+	var fe errs.FieldErrors
+
+	if up.Password != nil && (up.CurrentPassword == nil || *up.CurrentPassword == "") {
+		fe.Add("currentPassword", "is required to change the password")
+	}
+
+	return fe.ToError()
+}
+
+func (up updateProfile) toUpdateUser() updateUser {
+	return updateUser{
+		Name:            up.Name,
+		Email:           up.Email,
+		Department:      up.Department,
+		Password:        up.Password,
+		PasswordConfirm: up.PasswordConfirm,
+		Version:         up.Version,
+	}
+}
@@ -0,0 +1,57 @@
+package userapi
+
+import (
+	"net/http"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/openapi"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	AuthClient *authclient.Client
+	UserCore   *user.Core
+	Tenant     *tenant.Core
+	Usage      *usage.Core
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	tnt := mid.Tenant(cfg.Tenant)
+	athAny := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAny)
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	athSubject := mid.AuthorizeSubject(cfg.Log, cfg.AuthClient, "user_id", auth.RuleAdminOrSubject)
+	scpRead := mid.RequireScope(auth.ScopeUsersRead)
+	scpWrite := mid.RequireScope(auth.ScopeUsersWrite)
+	usg := mid.Usage(cfg.Usage)
+
+	api := newAPI(cfg.Log, cfg.AuthClient, cfg.UserCore)
+
+	app.HandleFunc("GET /v1/users", api.query, authen, tnt, athAdminOnly, scpRead, usg)
+	app.HandleFunc("GET /v1/users/me", api.queryMe, authen, tnt, athAny, scpRead, usg)
+	app.HandleFunc("PUT /v1/users/me", api.updateMe, authen, tnt, athAny, scpWrite, usg)
+	app.HandleFunc("GET /v1/users/{user_id}", api.queryByID, authen, tnt, athSubject, scpRead, usg)
+	app.HandleFunc("POST /v1/users", api.create, authen, tnt, athAdminOnly, scpWrite, usg)
+	app.HandleFunc("PUT /v1/users/{user_id}", api.update, authen, tnt, athSubject, scpWrite, usg)
+	app.HandleFunc("DELETE /v1/users/{user_id}", api.delete, authen, tnt, athAdminOnly, scpWrite, usg)
+
+	tags := []string{"users"}
+
+	app.Document("GET /v1/users", openapi.Operation{Summary: "List users", Tags: tags, Response: query.Result[appUser]{}})
+	app.Document("GET /v1/users/me", openapi.Operation{Summary: "Get the account of the caller", Tags: tags, Response: appUser{}})
+	app.Document("PUT /v1/users/me", openapi.Operation{Summary: "Update the account of the caller", Tags: tags, Request: updateProfile{}, Response: appUser{}})
+	app.Document("GET /v1/users/{user_id}", openapi.Operation{Summary: "Get a user", Tags: tags, Response: appUser{}})
+	app.Document("POST /v1/users", openapi.Operation{Summary: "Add a user", Tags: tags, Request: newUser{}, Response: appUser{}, Status: http.StatusCreated})
+	app.Document("PUT /v1/users/{user_id}", openapi.Operation{Summary: "Update a user", Tags: tags, Request: updateUser{}, Response: appUser{}})
+	app.Document("DELETE /v1/users/{user_id}", openapi.Operation{Summary: "Delete a user", Tags: tags, Status: http.StatusNoContent})
+}
@@ -0,0 +1,256 @@
+// Package userapi maintains the web based api for user access.
+package userapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log        *logger.Logger
+	authClient *authclient.Client
+	userCore   *user.Core
+}
+
+func newAPI(log *logger.Logger, authClient *authclient.Client, userCore *user.Core) *api {
+	return &api{
+		log:        log,
+		authClient: authClient,
+		userCore:   userCore,
+	}
+}
+
+func (api *api) query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qp := r.URL.Query()
+
+	pg, err := page.Parse(qp.Get("page"), qp.Get("rows"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	orderBy, err := parseOrderBy(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usrs, err := api.userCore.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	total, err := api.userCore.Count(ctx, filter)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, query.NewResult(toAppUsers(usrs), total, pg), http.StatusOK)
+}
+
+func (api *api) queryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := api.user(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+func (api *api) create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nu newUser
+	if err := web.Decode(r, &nu); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cnu, err := toCoreNewUser(nu)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := api.userCore.Create(ctx, cnu)
+	if err != nil {
+		if errors.Is(err, user.ErrUniqueEmail) {
+			return errs.New(errs.AlreadyExists, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusCreated)
+}
+
+// update changes the user named by the path. Users can change their own
+// details, but only an admin can change their roles or disable them.
+func (api *api) update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var uu updateUser
+	if err := web.Decode(r, &uu); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if uu.changesAccess() {
+		if err := api.authorize(ctx, auth.RuleAdminOnly); err != nil {
+			return err
+		}
+	}
+
+	cuu, err := toCoreUpdateUser(uu)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := api.user(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	usr, err = api.userCore.Update(ctx, usr, cuu)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrUniqueEmail):
+			return errs.New(errs.AlreadyExists, err)
+		case errors.Is(err, user.ErrVersionConflict):
+			return errs.New(errs.Aborted, err)
+		default:
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+func (api *api) delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := api.user(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := api.userCore.Delete(ctx, usr); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// queryMe returns the account of the caller.
+func (api *api) queryMe(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := api.me(ctx)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// updateMe changes the account of the caller. A new password is only taken
+// along with the current one, so a token that was left signed in can't be
+// used to take the account over.
+func (api *api) updateMe(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var up updateProfile
+	if err := web.Decode(r, &up); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cuu, err := toCoreUpdateUser(up.toUpdateUser())
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := api.me(ctx)
+	if err != nil {
+		return err
+	}
+
+	if up.Password != nil {
+		if err := api.userCore.CheckPassword(usr, *up.CurrentPassword); err != nil {
+			var fe errs.FieldErrors
+			fe.Add("currentPassword", "is incorrect")
+			return errs.New(errs.InvalidArgument, fe.ToError())
+		}
+	}
+
+	usr, err = api.userCore.Update(ctx, usr, cuu)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrUniqueEmail):
+			return errs.New(errs.AlreadyExists, err)
+		case errors.Is(err, user.ErrVersionConflict):
+			return errs.New(errs.Aborted, err)
+		default:
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// =============================================================================
+
+// me loads the user the claims of the caller were issued to.
+func (api *api) me(ctx context.Context) (user.User, error) {
+	userID, err := uuid.Parse(mid.GetClaims(ctx).Subject)
+	if err != nil {
+		return user.User{}, errs.Newf(errs.Unauthenticated, "subject: %s", err)
+	}
+
+	usr, err := api.userCore.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return user.User{}, errs.New(errs.NotFound, err)
+		}
+		return user.User{}, errs.New(errs.Internal, err)
+	}
+
+	return usr, nil
+}
+
+// user loads the user named by the path.
+func (api *api) user(ctx context.Context, r *http.Request) (user.User, error) {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return user.User{}, errs.Newf(errs.InvalidArgument, "user_id: %s", err)
+	}
+
+	usr, err := api.userCore.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return user.User{}, errs.New(errs.NotFound, err)
+		}
+		return user.User{}, errs.New(errs.Internal, err)
+	}
+
+	return usr, nil
+}
+
+// authorize checks the rule against the claims of the caller. It is used
+// when the rule depends on what the request asks for, such as changing the
+// roles of a user.
+func (api *api) authorize(ctx context.Context, rule string) error {
+	err := api.authClient.Authorize(ctx, authclient.Authorize{
+		Claims: mid.GetClaims(ctx),
+		Rule:   rule,
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, authclient.ErrUnavailable):
+		return errs.New(errs.Unavailable, err)
+	default:
+		return errs.New(errs.Unauthenticated, err)
+	}
+}
@@ -0,0 +1,43 @@
+package vproductapi
+
+import (
+	"time"
+
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+)
+
+// appProduct represents a product and its owner in the api.
+type appProduct struct {
+	ID          string  `json:"id"`
+	UserID      string  `json:"userID"`
+	Name        string  `json:"name"`
+	Cost        float64 `json:"cost"`
+	Quantity    int     `json:"quantity"`
+	Version     int     `json:"version"`
+	DateCreated string  `json:"dateCreated"`
+	DateUpdated string  `json:"dateUpdated"`
+	UserName    string  `json:"userName"`
+}
+
+func toAppProduct(prd vproduct.Product) appProduct {
+	return appProduct{
+		ID:          prd.ID.String(),
+		UserID:      prd.UserID.String(),
+		Name:        prd.Name,
+		Cost:        prd.Cost,
+		Quantity:    prd.Quantity,
+		Version:     prd.Version,
+		DateCreated: prd.DateCreated.Format(time.RFC3339),
+		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+		UserName:    prd.UserName,
+	}
+}
+
+func toAppProducts(prds []vproduct.Product) []appProduct {
+	items := make([]appProduct, len(prds))
+	for i, prd := range prds {
+		items[i] = toAppProduct(prd)
+	}
+
+	return items
+}
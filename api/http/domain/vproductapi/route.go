@@ -0,0 +1,38 @@
+package vproductapi
+
+import (
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/authclient"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/openapi"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log          *logger.Logger
+	AuthClient   *authclient.Client
+	VProductCore *vproduct.Core
+	Tenant       *tenant.Core
+	Usage        *usage.Core
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	tnt := mid.Tenant(cfg.Tenant)
+	athAdminOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+	scpRead := mid.RequireScope(auth.ScopeSalesRead)
+	usg := mid.Usage(cfg.Usage)
+
+	api := newAPI(cfg.VProductCore)
+
+	app.HandleFunc("GET /v1/vproducts", api.query, authen, tnt, athAdminOnly, scpRead, usg)
+
+	app.Document("GET /v1/vproducts", openapi.Operation{Summary: "List products with the names of their owners", Tags: []string{"products"}, Response: query.Result[appProduct]{}})
+}
@@ -0,0 +1,55 @@
+// Package vproductapi maintains the web based api for reading products
+// along with the users who own them.
+package vproductapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/query"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	vproductCore *vproduct.Core
+}
+
+func newAPI(vproductCore *vproduct.Core) *api {
+	return &api{
+		vproductCore: vproductCore,
+	}
+}
+
+func (api *api) query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qp := r.URL.Query()
+
+	pg, err := page.Parse(qp.Get("page"), qp.Get("rows"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	orderBy, err := parseOrderBy(qp)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prds, err := api.vproductCore.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	total, err := api.vproductCore.Count(ctx, filter)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return web.Respond(ctx, w, query.NewResult(toAppProducts(prds), total, pg), http.StatusOK)
+}
@@ -0,0 +1,28 @@
+package webhookapi
+
+import (
+	"time"
+
+	"github.com/mrcruz117/al-service/api/http/api/mid"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	Secrets    map[string][]byte
+	MaxAge     time.Duration
+	Subsystems *subsystem.Set
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	signed := mid.VerifySignature(cfg.Secrets, cfg.MaxAge)
+	paused := mid.Paused(cfg.Subsystems.Register("webhooks"))
+
+	api := newAPI(cfg.Log)
+
+	app.HandleFunc("POST /webhooks/{partner}", api.receive, signed, paused)
+}
@@ -0,0 +1,62 @@
+// Package webhookapi maintains the web based api for receiving webhooks
+// from partners.
+package webhookapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+type api struct {
+	log *logger.Logger
+}
+
+func newAPI(log *logger.Logger) *api {
+	return &api{
+		log: log,
+	}
+}
+
+// event represents the payload a partner delivers.
+type event struct {
+	ID   string         `json:"id"`
+	Type string         `json:"type"`
+	Data map[string]any `json:"data"`
+}
+
+// Validate checks the event has the fields required to process it.
+func (e event) Validate() error {
+	var fe errs.FieldErrors
+
+	if e.ID == "" {
+		fe.Add("id", "is required")
+	}
+
+	if e.Type == "" {
+		fe.Add("type", "is required")
+	}
+
+	return fe.ToError()
+}
+
+func (api *api) receive(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var evt event
+	if err := web.Decode(r, &evt); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	api.log.Info(ctx, "webhook received", "partner", mid.GetPartner(ctx), "id", evt.ID, "type", evt.Type)
+
+	resp := struct {
+		ID string `json:"id"`
+	}{
+		ID: evt.ID,
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusAccepted)
+}
@@ -0,0 +1,186 @@
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Auth is a client of the auth api.
+type Auth struct {
+	*client
+}
+
+// NewAuth constructs a client of the auth api at the url.
+func NewAuth(url string, options ...Option) *Auth {
+	return &Auth{client: newClient(url, options...)}
+}
+
+// Token represents a token issued by the auth api. Kind is jwt or session,
+// and Scope lists the scopes a client credentials token was granted.
+type Token struct {
+	Token     string
+	Kind      string
+	Scope     []string
+	ExpiresAt time.Time
+}
+
+// Login signs the user in and returns the token issued for it. The client
+// names the kind of application signing in, which decides whether a jwt
+// or a revocable session is issued.
+func (a *Auth) Login(ctx context.Context, email string, password string, client string) (Token, error) {
+	req := struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Client   string `json:"client"`
+	}{email, password, client}
+
+	var resp struct {
+		Token     string    `json:"token"`
+		Kind      string    `json:"kind"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+
+	if err := a.call(ctx, http.MethodPost, "/auth/login", nil, req, &resp); err != nil {
+		return Token{}, err
+	}
+
+	return Token{Token: resp.Token, Kind: resp.Kind, ExpiresAt: resp.ExpiresAt}, nil
+}
+
+// Logout ends the session of the token the client is configured with.
+func (a *Auth) Logout(ctx context.Context) error {
+	return a.call(ctx, http.MethodPost, "/auth/logout", nil, nil, nil)
+}
+
+// ClientToken is issued a token for a service with the client credentials
+// grant. The token holds every scope of the client unless some are asked
+// for.
+func (a *Auth) ClientToken(ctx context.Context, clientID string, secret string, scopes ...string) (Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/x-www-form-urlencoded")
+	headers.Set("Authorization", "Basic "+basicAuth(clientID, secret))
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Scope       string `json:"scope"`
+	}
+
+	if err := a.send(ctx, http.MethodPost, a.url+"/auth/token", headers, []byte(form.Encode()), &resp); err != nil {
+		return Token{}, err
+	}
+
+	tkn := Token{
+		Token:     resp.AccessToken,
+		Kind:      "jwt",
+		Scope:     strings.Fields(resp.Scope),
+		ExpiresAt: time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}
+
+	return tkn, nil
+}
+
+func basicAuth(username string, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// Scope represents an entry of the catalog of scopes a token can be
+// limited to.
+type Scope struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Scopes returns the catalog of scopes.
+func (a *Auth) Scopes(ctx context.Context) ([]Scope, error) {
+	var scopes []Scope
+	if err := a.call(ctx, http.MethodGet, "/auth/scopes", nil, nil, &scopes); err != nil {
+		return nil, err
+	}
+
+	return scopes, nil
+}
+
+// =============================================================================
+
+// TokenSource provides the token requests are authenticated with.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a token source for a token obtained some other way.
+type StaticToken string
+
+// Token implements the TokenSource interface.
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// refreshEarly is how long before it expires a token is replaced, which
+// leaves the request it's sent with time to reach the api.
+const refreshEarly = time.Minute
+
+// cachedSource holds on to the token it's issued until it's about to
+// expire, so a token isn't asked for on every request.
+type cachedSource struct {
+	mu    sync.Mutex
+	issue func(ctx context.Context) (Token, error)
+	tkn   Token
+}
+
+// Token implements the TokenSource interface.
+func (s *cachedSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tkn.Token != "" && time.Until(s.tkn.ExpiresAt) > refreshEarly {
+		return s.tkn.Token, nil
+	}
+
+	tkn, err := s.issue(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.tkn = tkn
+
+	return tkn.Token, nil
+}
+
+// LoginTokens is a token source that signs the user in, and signs in again
+// shortly before the token it was issued expires.
+func LoginTokens(auth *Auth, email string, password string, client string) TokenSource {
+	return &cachedSource{
+		issue: func(ctx context.Context) (Token, error) {
+			tkn, err := auth.Login(ctx, email, password, client)
+			if err != nil {
+				return Token{}, fmt.Errorf("login: %w", err)
+			}
+			return tkn, nil
+		},
+	}
+}
+
+// ClientTokens is a token source for a service, issued tokens with the
+// client credentials grant as the previous one is about to expire.
+func ClientTokens(auth *Auth, clientID string, secret string, scopes ...string) TokenSource {
+	return &cachedSource{
+		issue: func(ctx context.Context) (Token, error) {
+			tkn, err := auth.ClientToken(ctx, clientID, secret, scopes...)
+			if err != nil {
+				return Token{}, fmt.Errorf("client token: %w", err)
+			}
+			return tkn, nil
+		},
+	}
+}
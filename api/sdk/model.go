@@ -0,0 +1,139 @@
+package sdk
+
+import "time"
+
+// User represents a user of the sales api. The password is never returned.
+type User struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"emailVerified"`
+	Roles         []string  `json:"roles"`
+	Department    string    `json:"department"`
+	Enabled       bool      `json:"enabled"`
+	Version       int       `json:"version"`
+	DateCreated   time.Time `json:"dateCreated"`
+	DateUpdated   time.Time `json:"dateUpdated"`
+}
+
+// NewUser contains the information needed to create a user.
+type NewUser struct {
+	Name            string   `json:"name"`
+	Email           string   `json:"email"`
+	Roles           []string `json:"roles"`
+	Department      string   `json:"department,omitempty"`
+	Password        string   `json:"password"`
+	PasswordConfirm string   `json:"passwordConfirm"`
+}
+
+// UpdateUser contains the changes to a user, where nil fields are left as
+// they are. Version, when set, fails the update if the user was changed
+// since it was read. Only an admin can change the roles or enabled.
+type UpdateUser struct {
+	Name            *string  `json:"name,omitempty"`
+	Email           *string  `json:"email,omitempty"`
+	Roles           []string `json:"roles,omitempty"`
+	Department      *string  `json:"department,omitempty"`
+	Password        *string  `json:"password,omitempty"`
+	PasswordConfirm *string  `json:"passwordConfirm,omitempty"`
+	Enabled         *bool    `json:"enabled,omitempty"`
+	Version         *int     `json:"version,omitempty"`
+}
+
+// UpdateProfile contains the changes the signed in user makes to itself.
+// Changing the email or password needs the current password.
+type UpdateProfile struct {
+	Name            *string `json:"name,omitempty"`
+	Email           *string `json:"email,omitempty"`
+	Department      *string `json:"department,omitempty"`
+	CurrentPassword *string `json:"currentPassword,omitempty"`
+	Password        *string `json:"password,omitempty"`
+	PasswordConfirm *string `json:"passwordConfirm,omitempty"`
+	Version         *int    `json:"version,omitempty"`
+}
+
+// Product represents a product of the sales api. DateDeleted is zero for a
+// product that wasn't deleted.
+type Product struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userID"`
+	Name        string    `json:"name"`
+	Cost        float64   `json:"cost"`
+	Quantity    int       `json:"quantity"`
+	Version     int       `json:"version"`
+	DateCreated time.Time `json:"dateCreated"`
+	DateUpdated time.Time `json:"dateUpdated"`
+	DateDeleted time.Time `json:"dateDeleted"`
+}
+
+// NewProduct contains the information needed to create a product.
+type NewProduct struct {
+	Name     string  `json:"name"`
+	Cost     float64 `json:"cost"`
+	Quantity int     `json:"quantity"`
+}
+
+// PutProduct contains the product to create or replace. Version, when set,
+// is the version of the product being replaced, the replacement fails if it
+// has changed since.
+type PutProduct struct {
+	Name     string  `json:"name"`
+	Cost     float64 `json:"cost"`
+	Quantity int     `json:"quantity"`
+	Version  *int    `json:"version,omitempty"`
+}
+
+// UserProduct represents a product along with the name of the user that
+// owns it.
+type UserProduct struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userID"`
+	Name        string    `json:"name"`
+	Cost        float64   `json:"cost"`
+	Quantity    int       `json:"quantity"`
+	Version     int       `json:"version"`
+	DateCreated time.Time `json:"dateCreated"`
+	DateUpdated time.Time `json:"dateUpdated"`
+	UserName    string    `json:"userName"`
+}
+
+// These are the statuses an order moves through.
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderDelivered = "delivered"
+	OrderCancelled = "cancelled"
+)
+
+// Order represents an order of the sales api. The items keep the price of
+// the product when the order was placed.
+type Order struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userID"`
+	Status      string    `json:"status"`
+	Items       []Item    `json:"items"`
+	Total       float64   `json:"total"`
+	Version     int       `json:"version"`
+	DateCreated time.Time `json:"dateCreated"`
+	DateUpdated time.Time `json:"dateUpdated"`
+	DateDeleted time.Time `json:"dateDeleted"`
+}
+
+// Item represents a line of an order.
+type Item struct {
+	ProductID string  `json:"productID"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+// NewOrder contains the information needed to place an order.
+type NewOrder struct {
+	Items []NewItem `json:"items"`
+}
+
+// NewItem is a product and how many of it to order.
+type NewItem struct {
+	ProductID string `json:"productID"`
+	Quantity  int    `json:"quantity"`
+}
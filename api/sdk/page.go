@@ -0,0 +1,99 @@
+package sdk
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// maxRows is the most rows the api returns in a page, which the iterators
+// ask for to make as few requests as they can.
+const maxRows = 100
+
+// Page represents a page of a list request. Total is the number of items
+// across every page.
+type Page[T any] struct {
+	Items       []T `json:"items"`
+	Total       int `json:"total"`
+	Page        int `json:"page"`
+	RowsPerPage int `json:"rowsPerPage"`
+}
+
+// More reports whether there are pages after this one.
+func (p Page[T]) More() bool {
+	return p.Page*p.RowsPerPage < p.Total
+}
+
+// ListOptions selects the page of a list request and what is in it. Filter
+// holds the query parameters the list filters on, such as name or minCost
+// for products, and OrderBy is a field and direction such as "name,DESC".
+// The defaults of the api are used for what is left empty.
+type ListOptions struct {
+	Page    int
+	Rows    int
+	OrderBy string
+	Filter  url.Values
+}
+
+func (o ListOptions) values() url.Values {
+	v := url.Values{}
+	for key, values := range o.Filter {
+		v[key] = values
+	}
+
+	if o.Page > 0 {
+		v.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.Rows > 0 {
+		v.Set("rows", strconv.Itoa(o.Rows))
+	}
+	if o.OrderBy != "" {
+		v.Set("orderBy", o.OrderBy)
+	}
+
+	return v
+}
+
+func list[T any](ctx context.Context, c *client, path string, opts ListOptions) (Page[T], error) {
+	var pg Page[T]
+	if err := c.call(ctx, http.MethodGet, path, opts.values(), nil, &pg); err != nil {
+		return Page[T]{}, err
+	}
+
+	return pg, nil
+}
+
+// all walks every page of the list from the one in the options, yielding
+// the items one at a time and stopping at the first error. Items added or
+// removed while it walks can shift others between pages, so an item can be
+// missed or seen twice.
+func all[T any](ctx context.Context, c *client, path string, opts ListOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		opts.Page = max(opts.Page, 1)
+		if opts.Rows == 0 {
+			opts.Rows = maxRows
+		}
+
+		for {
+			pg, err := list[T](ctx, c, path, opts)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range pg.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(pg.Items) == 0 || !pg.More() {
+				return
+			}
+			opts.Page++
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package sdk
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// Sales is a client of the sales api.
+type Sales struct {
+	*client
+}
+
+// NewSales constructs a client of the sales api at the url. Every route of
+// the api needs a token, so WithToken or WithTokenSource is expected among
+// the options.
+func NewSales(url string, options ...Option) *Sales {
+	return &Sales{client: newClient(url, options...)}
+}
+
+func (s *Sales) get(ctx context.Context, path string, v any) error {
+	return s.call(ctx, http.MethodGet, path, nil, nil, v)
+}
+
+// =============================================================================
+
+// QueryProducts returns a page of the products.
+func (s *Sales) QueryProducts(ctx context.Context, opts ListOptions) (Page[Product], error) {
+	return list[Product](ctx, s.client, "/v1/products", opts)
+}
+
+// Products iterates over every product from the page in the options.
+func (s *Sales) Products(ctx context.Context, opts ListOptions) iter.Seq2[Product, error] {
+	return all[Product](ctx, s.client, "/v1/products", opts)
+}
+
+// QueryProductByID returns the product with the id.
+func (s *Sales) QueryProductByID(ctx context.Context, productID string) (Product, error) {
+	var prd Product
+	if err := s.get(ctx, "/v1/products/"+url.PathEscape(productID), &prd); err != nil {
+		return Product{}, err
+	}
+
+	return prd, nil
+}
+
+// CreateProduct adds a product owned by the signed in user.
+func (s *Sales) CreateProduct(ctx context.Context, np NewProduct) (Product, error) {
+	var prd Product
+	if err := s.call(ctx, http.MethodPost, "/v1/products", nil, np, &prd); err != nil {
+		return Product{}, err
+	}
+
+	return prd, nil
+}
+
+// PutProduct creates the product with the id, or replaces it when it
+// already exists.
+func (s *Sales) PutProduct(ctx context.Context, productID string, pp PutProduct) (Product, error) {
+	var prd Product
+	if err := s.call(ctx, http.MethodPut, "/v1/products/"+url.PathEscape(productID), nil, pp, &prd); err != nil {
+		return Product{}, err
+	}
+
+	return prd, nil
+}
+
+// DeleteProduct deletes the product with the id.
+func (s *Sales) DeleteProduct(ctx context.Context, productID string) error {
+	return s.call(ctx, http.MethodDelete, "/v1/products/"+url.PathEscape(productID), nil, nil, nil)
+}
+
+// QueryUserProducts returns a page of the products along with the names of
+// the users that own them.
+func (s *Sales) QueryUserProducts(ctx context.Context, opts ListOptions) (Page[UserProduct], error) {
+	return list[UserProduct](ctx, s.client, "/v1/vproducts", opts)
+}
+
+// UserProducts iterates over every product along with the name of the user
+// that owns it, from the page in the options.
+func (s *Sales) UserProducts(ctx context.Context, opts ListOptions) iter.Seq2[UserProduct, error] {
+	return all[UserProduct](ctx, s.client, "/v1/vproducts", opts)
+}
+
+// =============================================================================
+
+// QueryUsers returns a page of the users.
+func (s *Sales) QueryUsers(ctx context.Context, opts ListOptions) (Page[User], error) {
+	return list[User](ctx, s.client, "/v1/users", opts)
+}
+
+// Users iterates over every user from the page in the options.
+func (s *Sales) Users(ctx context.Context, opts ListOptions) iter.Seq2[User, error] {
+	return all[User](ctx, s.client, "/v1/users", opts)
+}
+
+// QueryUserByID returns the user with the id.
+func (s *Sales) QueryUserByID(ctx context.Context, userID string) (User, error) {
+	var usr User
+	if err := s.get(ctx, "/v1/users/"+url.PathEscape(userID), &usr); err != nil {
+		return User{}, err
+	}
+
+	return usr, nil
+}
+
+// Me returns the signed in user.
+func (s *Sales) Me(ctx context.Context) (User, error) {
+	var usr User
+	if err := s.get(ctx, "/v1/users/me", &usr); err != nil {
+		return User{}, err
+	}
+
+	return usr, nil
+}
+
+// UpdateMe changes the profile of the signed in user.
+func (s *Sales) UpdateMe(ctx context.Context, up UpdateProfile) (User, error) {
+	var usr User
+	if err := s.call(ctx, http.MethodPut, "/v1/users/me", nil, up, &usr); err != nil {
+		return User{}, err
+	}
+
+	return usr, nil
+}
+
+// CreateUser adds a user.
+func (s *Sales) CreateUser(ctx context.Context, nu NewUser) (User, error) {
+	var usr User
+	if err := s.call(ctx, http.MethodPost, "/v1/users", nil, nu, &usr); err != nil {
+		return User{}, err
+	}
+
+	return usr, nil
+}
+
+// UpdateUser changes the user with the id.
+func (s *Sales) UpdateUser(ctx context.Context, userID string, uu UpdateUser) (User, error) {
+	var usr User
+	if err := s.call(ctx, http.MethodPut, "/v1/users/"+url.PathEscape(userID), nil, uu, &usr); err != nil {
+		return User{}, err
+	}
+
+	return usr, nil
+}
+
+// DeleteUser deletes the user with the id.
+func (s *Sales) DeleteUser(ctx context.Context, userID string) error {
+	return s.call(ctx, http.MethodDelete, "/v1/users/"+url.PathEscape(userID), nil, nil, nil)
+}
+
+// =============================================================================
+
+// QueryOrders returns a page of the orders of every user.
+func (s *Sales) QueryOrders(ctx context.Context, opts ListOptions) (Page[Order], error) {
+	return list[Order](ctx, s.client, "/v1/orders", opts)
+}
+
+// Orders iterates over the orders of every user from the page in the
+// options.
+func (s *Sales) Orders(ctx context.Context, opts ListOptions) iter.Seq2[Order, error] {
+	return all[Order](ctx, s.client, "/v1/orders", opts)
+}
+
+// QueryUserOrders returns every order of the user with the id, which the
+// api doesn't page.
+func (s *Sales) QueryUserOrders(ctx context.Context, userID string) ([]Order, error) {
+	var ords []Order
+	if err := s.get(ctx, "/v1/users/"+url.PathEscape(userID)+"/orders", &ords); err != nil {
+		return nil, err
+	}
+
+	return ords, nil
+}
+
+// QueryOrderByID returns the order with the id.
+func (s *Sales) QueryOrderByID(ctx context.Context, orderID string) (Order, error) {
+	var ord Order
+	if err := s.get(ctx, "/v1/orders/"+url.PathEscape(orderID), &ord); err != nil {
+		return Order{}, err
+	}
+
+	return ord, nil
+}
+
+// CreateOrder places an order for the signed in user.
+func (s *Sales) CreateOrder(ctx context.Context, no NewOrder) (Order, error) {
+	var ord Order
+	if err := s.call(ctx, http.MethodPost, "/v1/orders", nil, no, &ord); err != nil {
+		return Order{}, err
+	}
+
+	return ord, nil
+}
+
+// TransitionOrder moves the order with the id to the status.
+func (s *Sales) TransitionOrder(ctx context.Context, orderID string, status string) (Order, error) {
+	req := struct {
+		Status string `json:"status"`
+	}{status}
+
+	var ord Order
+	if err := s.call(ctx, http.MethodPut, "/v1/orders/"+url.PathEscape(orderID)+"/status", nil, req, &ord); err != nil {
+		return Order{}, err
+	}
+
+	return ord, nil
+}
+
+// DeleteOrder deletes the order with the id.
+func (s *Sales) DeleteOrder(ctx context.Context, orderID string) error {
+	return s.call(ctx, http.MethodDelete, "/v1/orders/"+url.PathEscape(orderID), nil, nil, nil)
+}
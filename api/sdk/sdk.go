@@ -0,0 +1,244 @@
+// Package sdk provides typed clients for the sales and auth apis, so
+// services calling them don't need to build the requests by hand. The
+// package follows the v1 routes of the apis and is versioned with them:
+// a breaking change to the routes is a new major version of the package.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Version is the version of the package, sent in the User-Agent header.
+const Version = "1.0.0"
+
+// maxBackoff caps the wait between two attempts of a request.
+const maxBackoff = 5 * time.Second
+
+// Option configures a client.
+type Option func(c *client)
+
+// WithHTTPClient sets the http client requests are made with, which is
+// http.DefaultClient otherwise.
+func WithHTTPClient(http *http.Client) Option {
+	return func(c *client) {
+		c.http = http
+	}
+}
+
+// WithTokenSource authenticates every request with a token from the
+// source.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *client) {
+		c.tokens = ts
+	}
+}
+
+// WithToken authenticates every request with the token.
+func WithToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithRetries retries the requests that are safe to repeat, the ones that
+// don't create anything, when the api couldn't be reached or failed with a
+// 5xx. A request is made up to attempts times, waiting a random time of up
+// to backoff before the second attempt and up to twice as long again before
+// each one after it.
+func WithRetries(attempts int, backoff time.Duration) Option {
+	return func(c *client) {
+		c.attempts = max(attempts, 1)
+		c.backoff = backoff
+	}
+}
+
+// client makes the requests shared by the clients of every api.
+type client struct {
+	url      string
+	http     *http.Client
+	tokens   TokenSource
+	attempts int
+	backoff  time.Duration
+}
+
+func newClient(baseURL string, options ...Option) *client {
+	c := client{
+		url:      strings.TrimSuffix(baseURL, "/"),
+		http:     http.DefaultClient,
+		attempts: 1,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return &c
+}
+
+// call sends the body as json and decodes the response into v, which is
+// skipped when v is nil or there is no content.
+func (c *client) call(ctx context.Context, method string, path string, query url.Values, body any, v any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+	}
+
+	endpoint := c.url + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	headers := http.Header{}
+	if data != nil {
+		headers.Set("Content-Type", "application/json")
+	}
+
+	return c.send(ctx, method, endpoint, headers, data, v)
+}
+
+// send makes the request, retrying it when it's safe to and it failed in a
+// way another attempt could fix.
+func (c *client) send(ctx context.Context, method string, endpoint string, headers http.Header, body []byte, v any) error {
+	attempts := c.attempts
+	if method == http.MethodPost {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := c.do(ctx, method, endpoint, headers, body, v)
+		if !retry || attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+
+		wait := c.backoff
+		for i := 1; i < attempt && wait < maxBackoff; i++ {
+			wait *= 2
+		}
+		wait = min(wait, maxBackoff)
+
+		if wait > 0 {
+			wait = rand.N(wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+
+		case <-timer.C:
+		}
+	}
+}
+
+// do makes a single attempt of the request and reports whether it's worth
+// making another.
+func (c *client) do(ctx context.Context, method string, endpoint string, headers http.Header, body []byte, v any) (bool, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, r)
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "al-service-sdk/"+Version)
+
+	if c.tokens != nil {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return false, fmt.Errorf("token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("%s %s: %w", method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode >= http.StatusInternalServerError, decodeError(resp.StatusCode, data)
+	}
+
+	if v == nil || resp.StatusCode == http.StatusNoContent || len(data) == 0 {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return false, nil
+}
+
+// =============================================================================
+
+// Error represents a request the api failed. Code is the name of the
+// error code such as not_found, and Fields lists the fields a request
+// failed validation on.
+type Error struct {
+	StatusCode int          `json:"-"`
+	Code       string       `json:"code"`
+	Message    string       `json:"message"`
+	Fields     []FieldError `json:"fields,omitempty"`
+	TraceID    string       `json:"trace_id"`
+}
+
+// FieldError is a field a request failed validation on.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Error implements the error interface.
+func (err *Error) Error() string {
+	if err.Code == "" {
+		return fmt.Sprintf("status %d: %s", err.StatusCode, err.Message)
+	}
+	return fmt.Sprintf("%s: %s", err.Code, err.Message)
+}
+
+// IsNotFound reports whether the error is the api reporting that what was
+// asked for doesn't exist.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+func decodeError(statusCode int, data []byte) error {
+	var env struct {
+		Error Error `json:"error"`
+	}
+
+	if err := json.Unmarshal(data, &env); err != nil || env.Error.Message == "" {
+		return &Error{StatusCode: statusCode, Message: strings.TrimSpace(string(data))}
+	}
+
+	env.Error.StatusCode = statusCode
+
+	return &env.Error
+}
@@ -0,0 +1,100 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrcruz117/al-service/api/sdk"
+)
+
+func Test_Products(t *testing.T) {
+	const total = 5
+
+	var logins atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /auth/login", func(w http.ResponseWriter, r *http.Request) {
+		logins.Add(1)
+		json.NewEncoder(w).Encode(map[string]string{
+			"token":     "tkn",
+			"kind":      "jwt",
+			"expiresAt": time.Now().Add(time.Hour).Format(time.RFC3339),
+		})
+	})
+	mux.HandleFunc("GET /v1/products", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tkn" {
+			t.Errorf("authorization: got %q", r.Header.Get("Authorization"))
+		}
+
+		pg, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		rows, _ := strconv.Atoi(r.URL.Query().Get("rows"))
+
+		var items []sdk.Product
+		for i := (pg - 1) * rows; i < min(pg*rows, total); i++ {
+			items = append(items, sdk.Product{ID: strconv.Itoa(i)})
+		}
+
+		json.NewEncoder(w).Encode(sdk.Page[sdk.Product]{Items: items, Total: total, Page: pg, RowsPerPage: rows})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	auth := sdk.NewAuth(srv.URL)
+	sales := sdk.NewSales(srv.URL, sdk.WithTokenSource(sdk.LoginTokens(auth, "admin@example.com", "gophers", "")))
+
+	var ids []string
+	for prd, err := range sales.Products(context.Background(), sdk.ListOptions{Rows: 2}) {
+		if err != nil {
+			t.Fatalf("products: %s", err)
+		}
+		ids = append(ids, prd.ID)
+	}
+
+	if len(ids) != total {
+		t.Errorf("products: got %v, want %d", ids, total)
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("logins: got %d, want 1", n)
+	}
+}
+
+func Test_Error(t *testing.T) {
+	var calls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/products/{product_id}", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"not_found","message":"product not found","trace_id":"abc"}}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	sales := sdk.NewSales(srv.URL, sdk.WithToken("tkn"), sdk.WithRetries(3, time.Millisecond))
+
+	_, err := sales.QueryProductByID(context.Background(), "missing")
+
+	var apiErr *sdk.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error: got %v, want *sdk.Error", err)
+	}
+	if apiErr.Code != "not_found" || apiErr.TraceID != "abc" || !sdk.IsNotFound(err) {
+		t.Errorf("error: got %+v", apiErr)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("calls: got %d, want 2: a 404 should not be retried", n)
+	}
+}
@@ -0,0 +1,190 @@
+// Package adaptive provides support for deriving per route timeouts from
+// the latency each route is observed to have, instead of guessing a static
+// value for every route.
+package adaptive
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config represents the settings used to tune the timeouts.
+type Config struct {
+
+	// Percentile of the observed latencies the timeout is derived from,
+	// e.g. 0.99.
+	Percentile float64
+
+	// Factor the percentile is multiplied by to produce the timeout.
+	Factor float64
+
+	// Min is the shortest timeout that will be derived.
+	Min time.Duration
+
+	// Samples is the number of recent latencies kept per route.
+	Samples int
+
+	// MinSamples is the number of latencies a route needs before its
+	// timeout is tuned. Until then the configured maximum is used.
+	MinSamples int
+}
+
+// route holds the recent latencies of a route and the derived timeout.
+type route struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+
+	timeout atomic.Int64
+	vars    *expvar.Map
+}
+
+// Tuner tracks latencies and recomputes the timeout of every route.
+type Tuner struct {
+	cfg    Config
+	mu     sync.RWMutex
+	routes map[string]*route
+	vars   *expvar.Map
+}
+
+// New constructs a Tuner that publishes its metrics under the
+// "adaptive_timeouts" expvar key.
+func New(cfg Config) (*Tuner, error) {
+	switch {
+	case cfg.Samples <= 0:
+		return nil, fmt.Errorf("samples must be positive, got %d", cfg.Samples)
+	case cfg.Percentile <= 0 || cfg.Percentile > 1:
+		return nil, fmt.Errorf("percentile must be greater than 0 and at most 1, got %v", cfg.Percentile)
+	case cfg.Factor <= 0:
+		return nil, fmt.Errorf("factor must be positive, got %v", cfg.Factor)
+	}
+
+	vars, ok := expvar.Get("adaptive_timeouts").(*expvar.Map)
+	if !ok {
+		vars = expvar.NewMap("adaptive_timeouts")
+	}
+
+	return &Tuner{
+		cfg:    cfg,
+		routes: make(map[string]*route),
+		vars:   vars,
+	}, nil
+}
+
+// Observe records the latency of a request to the route.
+func (t *Tuner) Observe(name string, d time.Duration) {
+	rt := t.route(name)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if len(rt.samples) < t.cfg.Samples {
+		rt.samples = append(rt.samples, d)
+		return
+	}
+
+	rt.samples[rt.next] = d
+	rt.next = (rt.next + 1) % t.cfg.Samples
+}
+
+// Timeout returns the timeout for the route bounded by max. A route that
+// hasn't been tuned yet gets max.
+func (t *Tuner) Timeout(name string, max time.Duration) time.Duration {
+	t.mu.RLock()
+	rt, exists := t.routes[name]
+	t.mu.RUnlock()
+
+	if !exists {
+		return max
+	}
+
+	timeout := time.Duration(rt.timeout.Load())
+	if timeout <= 0 || timeout > max {
+		return max
+	}
+
+	return timeout
+}
+
+// Recompute derives the timeout of every route from its recent latencies.
+func (t *Tuner) Recompute() {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, rt := range t.routes {
+		rt.mu.Lock()
+		samples := slices.Clone(rt.samples)
+		rt.mu.Unlock()
+
+		if len(samples) < t.cfg.MinSamples || len(samples) == 0 {
+			continue
+		}
+
+		slices.Sort(samples)
+
+		idx := int(math.Ceil(t.cfg.Percentile*float64(len(samples)))) - 1
+		idx = min(max(idx, 0), len(samples)-1)
+		pct := samples[idx]
+
+		timeout := max(time.Duration(float64(pct)*t.cfg.Factor), t.cfg.Min)
+		rt.timeout.Store(int64(timeout))
+
+		rt.vars.Set("percentile_ms", intVar(pct.Milliseconds()))
+		rt.vars.Set("timeout_ms", intVar(timeout.Milliseconds()))
+		rt.vars.Set("samples", intVar(int64(len(samples))))
+	}
+}
+
+// Run recomputes the timeouts at the specified interval until the context
+// is canceled.
+func (t *Tuner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Recompute()
+		}
+	}
+}
+
+func (t *Tuner) route(name string) *route {
+	t.mu.RLock()
+	rt, exists := t.routes[name]
+	t.mu.RUnlock()
+
+	if exists {
+		return rt
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if rt, exists := t.routes[name]; exists {
+		return rt
+	}
+
+	rt = &route{
+		samples: make([]time.Duration, 0, t.cfg.Samples),
+		vars:    new(expvar.Map),
+	}
+	t.routes[name] = rt
+	t.vars.Set(name, rt.vars)
+
+	return rt
+}
+
+func intVar(v int64) *expvar.Int {
+	var i expvar.Int
+	i.Set(v)
+	return &i
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// algorithms are the signing algorithms tokens can be signed with. Which one
+// a token uses is decided by the type of the key it names, never by the alg
+// in its header, so a token can't pick a weaker algorithm or have the public
+// key used as an HMAC secret.
+var algorithms = []string{
+	jwt.SigningMethodRS256.Name,
+	jwt.SigningMethodES256.Name,
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// SigningMethod returns the signing method for a public or private RSA,
+// ECDSA P-256 or Ed25519 key: RS256, ES256 or EdDSA.
+func SigningMethod(key any) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+
+	case *ecdsa.PublicKey:
+		return ecdsaMethod(k.Curve)
+
+	case *ecdsa.PrivateKey:
+		return ecdsaMethod(k.Curve)
+
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	if curve != elliptic.P256() {
+		return nil, fmt.Errorf("unsupported curve %s, only P-256 is supported", curve.Params().Name)
+	}
+
+	return jwt.SigningMethodES256, nil
+}
+
+// ParsePublicKeyPEM parses a PEM encoded PKIX or PKCS1 public key along
+// with the signing method it is used with.
+func ParsePublicKeyPEM(publicPEM string) (crypto.PublicKey, jwt.SigningMethod, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, nil, errors.New("invalid key: key must be PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		rsaKey, rsaErr := x509.ParsePKCS1PublicKey(block.Bytes)
+		if rsaErr != nil {
+			return nil, nil, fmt.Errorf("parsing public key: %w", err)
+		}
+		key = rsaKey
+	}
+
+	method, err := SigningMethod(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, method, nil
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded PKCS1, PKCS8 or SEC 1 private key
+// along with the signing method it is used with.
+func ParsePrivateKeyPEM(privatePEM string) (crypto.PrivateKey, jwt.SigningMethod, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, nil, errors.New("invalid key: key must be PEM encoded")
+	}
+
+	var key any
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, nil, errors.New("invalid key: key must be a PKCS1, PKCS8 or SEC 1 private key")
+			}
+		}
+	}
+
+	method, err := SigningMethod(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, method, nil
+}
@@ -7,10 +7,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"strings"
+	"sync"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/user"
 	"github.com/mrcruz117/al-service/foundation/logger"
 	"github.com/open-policy-agent/opa/rego"
 )
@@ -18,10 +21,20 @@ import (
 // ErrForbidden is returned when a auth issue is identified.
 var ErrForbidden = errors.New("attempted action is not allowed")
 
+// ErrUserDisabled is returned when the token is valid but the user it was
+// issued to has since been disabled.
+var ErrUserDisabled = errors.New("user disabled")
+
 // Claims represents the authorization claims transmitted via a JWT.
 type Claims struct {
 	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
+	Tenant      string   `json:"tenant,omitempty"`
+
+	// Extra holds the claims a deployment maps from the user record, see
+	// ClaimMapping.
+	Extra map[string]string `json:"ext,omitempty"`
 }
 
 // HasRole checks if the specified role exists.
@@ -42,54 +55,168 @@ type KeyLookup interface {
 	PublicKey(kid string) (key string, err error)
 }
 
+// KeySigner declares the behavior of a KeyLookup that signs tokens itself,
+// such as one backed by Vault or a KMS, so the private key is never handed
+// to the service. The signature must be the JWS signature for the alg,
+// which is the one of the kid's public key.
+type KeySigner interface {
+	Sign(kid string, alg string, message []byte) (signature []byte, err error)
+}
+
+// SessionPrefix starts every opaque session token, which tells them apart
+// from JWTs.
+const SessionPrefix = "ses_"
+
+// Sessions declares the behavior for looking up the claims of an opaque
+// session token. A session that doesn't exist or has expired is an error.
+type Sessions interface {
+	Lookup(ctx context.Context, token string) (Claims, error)
+}
+
 // Config represents information required to initialize auth.
 type Config struct {
 	Log       *logger.Logger
 	KeyLookup KeyLookup
 	Issuer    string
+
+	// Providers are the external identity providers whose tokens are
+	// accepted as well.
+	Providers []Provider
+
+	// Policies, when set, holds the authentication.rego and
+	// authorization.rego files to enforce instead of the compiled in ones,
+	// which allows them to be reloaded.
+	Policies fs.FS
+
+	// Sessions, when set, resolves the session tokens issued instead of
+	// JWTs to some clients.
+	Sessions Sessions
+
+	// ClaimMapping declares the extra claims put in the tokens issued to
+	// users.
+	ClaimMapping ClaimMapping
+
+	// UserCore, when set, is checked for every token issued to a user so a
+	// user that was disabled stops being authenticated straight away
+	// rather than when their token expires.
+	UserCore *user.Core
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Auth struct {
+	log       *logger.Logger
 	keyLookup KeyLookup
-	method    jwt.SigningMethod
 	parser    *jwt.Parser
 	issuer    string
+	mu        sync.RWMutex
+	policy    *policy
+	policyFS  fs.FS
+	providers map[string]*provider
+	sessions  Sessions
+	mapping   ClaimMapping
+	userCore  *user.Core
 }
 
 // New creates an Auth to support authentication/authorization.
 func New(cfg Config) (*Auth, error) {
 	a := Auth{
+		log:       cfg.Log,
 		keyLookup: cfg.KeyLookup,
-		method:    jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
-		parser:    jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
+		parser:    jwt.NewParser(jwt.WithValidMethods(algorithms)),
 		issuer:    cfg.Issuer,
+		policy:    newPolicy(regoAuthentication, regoAuthorization),
+		policyFS:  cfg.Policies,
+		providers: make(map[string]*provider, len(cfg.Providers)),
+		sessions:  cfg.Sessions,
+		mapping:   cfg.ClaimMapping,
+		userCore:  cfg.UserCore,
+	}
+
+	if cfg.Policies != nil {
+		p, err := readPolicy(cfg.Policies)
+		if err != nil {
+			return nil, err
+		}
+		a.policy = p
+	}
+
+	for _, p := range cfg.Providers {
+		if p.Issuer == cfg.Issuer {
+			return nil, fmt.Errorf("provider[%s]: issuer is the service's own", p.Issuer)
+		}
+
+		prv, err := newProvider(p)
+		if err != nil {
+			return nil, fmt.Errorf("provider[%s]: %w", p.Issuer, err)
+		}
+
+		a.providers[p.Issuer] = prv
 	}
 
 	return &a, nil
 }
 
+// Compile prepares every known policy rule ahead of time so the first
+// requests don't pay the cost of compiling the rego code.
+func (a *Auth) Compile(ctx context.Context) error {
+	for _, rule := range rules {
+		if _, err := a.prepareQuery(ctx, rule); err != nil {
+			return fmt.Errorf("compile rule[%s]: %w", rule, err)
+		}
+	}
+
+	return nil
+}
+
 // Issuer provides the configured issuer used to authenticate tokens.
 func (a *Auth) Issuer() string {
 	return a.issuer
 }
 
-// GenerateToken generates a signed JWT token string representing the user Claims.
+// GenerateToken generates a signed JWT token string representing the user
+// Claims. The token is signed with the algorithm of the kid's key.
 func (a *Auth) GenerateToken(kid string, claims Claims) (string, error) {
-	token := jwt.NewWithClaims(a.method, claims)
-	token.Header["kid"] = kid
+	if signer, ok := a.keyLookup.(KeySigner); ok {
+		publicKeyPEM, err := a.keyLookup.PublicKey(kid)
+		if err != nil {
+			return "", fmt.Errorf("public key: %w", err)
+		}
+
+		_, method, err := ParsePublicKeyPEM(publicKeyPEM)
+		if err != nil {
+			return "", fmt.Errorf("parsing public pem: %w", err)
+		}
+
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+
+		str, err := token.SigningString()
+		if err != nil {
+			return "", fmt.Errorf("encoding token: %w", err)
+		}
+
+		sig, err := signer.Sign(kid, method.Alg(), []byte(str))
+		if err != nil {
+			return "", fmt.Errorf("signing token: %w", err)
+		}
+
+		return str + "." + jwt.EncodeSegment(sig), nil
+	}
 
 	privateKeyPEM, err := a.keyLookup.PrivateKey(kid)
 	if err != nil {
 		return "", fmt.Errorf("private key: %w", err)
 	}
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+	privateKey, method, err := ParsePrivateKeyPEM(privateKeyPEM)
 	if err != nil {
 		return "", fmt.Errorf("parsing private pem: %w", err)
 	}
 
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
 	str, err := token.SignedString(privateKey)
 	if err != nil {
 		return "", fmt.Errorf("signing token: %w", err)
@@ -105,6 +232,25 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		return Claims{}, errors.New("expected authorization header format: Bearer <token>")
 	}
 
+	// Session tokens are only known to the store that issued them, so
+	// without one they're treated like a token of an unknown issuer.
+	if strings.HasPrefix(parts[1], SessionPrefix) {
+		if a.sessions == nil {
+			return Claims{}, fmt.Errorf("session token: %w", ErrUnknownIssuer)
+		}
+
+		claims, err := a.sessions.Lookup(ctx, parts[1])
+		if err != nil {
+			return Claims{}, fmt.Errorf("session: %w", err)
+		}
+
+		if err := a.isUserEnabled(ctx, claims); err != nil {
+			return Claims{}, fmt.Errorf("user not enabled : %w", err)
+		}
+
+		return claims, nil
+	}
+
 	var claims Claims
 	token, _, err := a.parser.ParseUnverified(parts[1], &claims)
 	if err != nil {
@@ -121,26 +267,75 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		return Claims{}, fmt.Errorf("kid malformed: %w", err)
 	}
 
-	pem, err := a.keyLookup.PublicKey(kid)
+	// Tokens of an external provider are verified with its keys and
+	// audience.
+	keyLookup := a.keyLookup
+	input := map[string]any{
+		"Token": parts[1],
+		"ISS":   a.issuer,
+	}
+
+	prv, federated := a.providers[claims.Issuer]
+	switch {
+	case federated:
+		keyLookup = prv.keys
+		input["ISS"] = prv.Issuer
+		input["AUD"] = prv.Audience
+
+	case claims.Issuer != a.issuer:
+		return Claims{}, fmt.Errorf("issuer[%s]: %w", claims.Issuer, ErrUnknownIssuer)
+	}
+
+	pem, err := keyLookup.PublicKey(kid)
 	if err != nil {
 		return Claims{}, fmt.Errorf("failed to fetch public key: %w", err)
 	}
+	input["Key"] = pem
 
-	input := map[string]any{
-		"Key":   pem,
-		"Token": parts[1],
-		"ISS":   a.issuer,
+	// The algorithm is pinned to the one of the key.
+	publicKey, method, err := ParsePublicKeyPEM(pem)
+	if err != nil {
+		return Claims{}, fmt.Errorf("parsing public key: %w", err)
+	}
+
+	if token.Method.Alg() != method.Alg() {
+		return Claims{}, fmt.Errorf("token signed with %s, the key requires %s", token.Method.Alg(), method.Alg())
 	}
+	input["ALG"] = method.Alg()
+
+	// OPA can't verify EdDSA signatures, so they are verified here and the
+	// policy only checks the claims.
+	if method == jwt.SigningMethodEdDSA {
+		parser := jwt.NewParser(jwt.WithValidMethods([]string{method.Alg()}))
+		keyFunc := func(*jwt.Token) (any, error) {
+			return publicKey, nil
+		}
 
-	if err := a.opaPolicyEvaluation(ctx, regoAuthentication, RuleAuthenticate, input); err != nil {
+		if _, err := parser.ParseWithClaims(parts[1], &Claims{}, keyFunc); err != nil {
+			return Claims{}, fmt.Errorf("authentication failed : %w", err)
+		}
+		input["Verified"] = true
+	}
+
+	if err := a.opaPolicyEvaluation(ctx, RuleAuthenticate, input); err != nil {
 		return Claims{}, fmt.Errorf("authentication failed : %w", err)
 	}
 
-	// Check the database for this user to verify they are still enabled.
+	// The users of a provider are managed by the provider, so only the
+	// users of this service are checked.
+	if federated {
+		claims, err = prv.claims(parts[1], claims)
+		if err != nil {
+			return Claims{}, fmt.Errorf("provider[%s]: %w", prv.Issuer, err)
+		}
+
+		return claims, nil
+	}
 
-	// if err := a.isUserEnabled(ctx, claims); err != nil {
-	// 	return Claims{}, fmt.Errorf("user not enabled : %w", err)
-	// }
+	// Check the database for this user to verify they are still enabled.
+	if err := a.isUserEnabled(ctx, claims); err != nil {
+		return Claims{}, fmt.Errorf("user not enabled : %w", err)
+	}
 
 	return claims, nil
 }
@@ -153,9 +348,10 @@ func (a *Auth) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, r
 		"Roles":   claims.Roles,
 		"Subject": claims.Subject,
 		"UserID":  userID,
+		"Extra":   claims.Extra,
 	}
 
-	if err := a.opaPolicyEvaluation(ctx, regoAuthorization, rule, input); err != nil {
+	if err := a.opaPolicyEvaluation(ctx, rule, input); err != nil {
 		return fmt.Errorf("rego evaluation failed : %w", err)
 	}
 
@@ -164,13 +360,8 @@ func (a *Auth) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, r
 
 // opaPolicyEvaluation asks opa to evaluate the token against the specified token
 // policy and public key.
-func (a *Auth) opaPolicyEvaluation(ctx context.Context, regoScript string, rule string, input any) error {
-	query := fmt.Sprintf("x = data.%s.%s", opaPackage, rule)
-
-	q, err := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", regoScript),
-	).PrepareForEval(ctx)
+func (a *Auth) opaPolicyEvaluation(ctx context.Context, rule string, input any) error {
+	q, err := a.prepareQuery(ctx, rule)
 	if err != nil {
 		return err
 	}
@@ -192,26 +383,62 @@ func (a *Auth) opaPolicyEvaluation(ctx context.Context, regoScript string, rule
 	return nil
 }
 
-// isUserEnabled hits the database and checks the user is not disabled. If the
-// no database connection was provided, this check is skipped.
-// func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
-// 	if a.userBus == nil {
-// 		return nil
-// 	}
+// prepareQuery returns the prepared query for the specified rule from the
+// current policy, compiling and caching it on first use.
+func (a *Auth) prepareQuery(ctx context.Context, rule string) (rego.PreparedEvalQuery, error) {
+	a.mu.RLock()
+	p := a.policy
+	q, exists := p.queries[rule]
+	a.mu.RUnlock()
 
-// 	userID, err := uuid.Parse(claims.Subject)
-// 	if err != nil {
-// 		return fmt.Errorf("parse user: %w", err)
-// 	}
+	if exists {
+		return q, nil
+	}
 
-// 	usr, err := a.userBus.QueryByID(ctx, userID)
-// 	if err != nil {
-// 		return fmt.Errorf("query user: %w", err)
-// 	}
+	q, err := prepare(ctx, p.script(rule), rule)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, err
+	}
+
+	// The query is cached with the policy it was compiled from, even if
+	// that policy was replaced in the meantime.
+	a.mu.Lock()
+	p.queries[rule] = q
+	a.mu.Unlock()
+
+	return q, nil
+}
 
-// 	if !usr.Enabled {
-// 		return fmt.Errorf("user disabled")
-// 	}
+func prepare(ctx context.Context, regoScript string, rule string) (rego.PreparedEvalQuery, error) {
+	query := fmt.Sprintf("x = data.%s.%s", opaPackage, rule)
+
+	return rego.New(
+		rego.Query(query),
+		rego.Module("policy.rego", regoScript),
+	).PrepareForEval(ctx)
+}
 
-// 	return nil
-// }
+// isUserEnabled hits the database and checks the user is not disabled. If
+// no user core was provided, this check is skipped. The tokens issued to
+// service clients aren't about a user and are left alone.
+func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
+	if a.userCore == nil || claims.HasRole(RoleService) {
+		return nil
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("parse user: %w", err)
+	}
+
+	usr, err := a.userCore.QueryByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("query user: %w", err)
+	}
+
+	if !usr.Enabled {
+		return ErrUserDisabled
+	}
+
+	return nil
+}
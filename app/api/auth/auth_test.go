@@ -3,14 +3,28 @@ package auth_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
 	"runtime/debug"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/auth/authtest"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usermem"
 	"github.com/mrcruz117/al-service/foundation/logger"
 )
 
@@ -232,6 +246,278 @@ func Test_Auth(t *testing.T) {
 	}
 }
 
+func Benchmark_Authenticate(b *testing.B) {
+	a, token := newBench(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := a.Authenticate(ctx, "Bearer "+token); err != nil {
+			b.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+	}
+}
+
+func Benchmark_Authorize(b *testing.B) {
+	a, token := newBench(b)
+	ctx := context.Background()
+
+	claims, err := a.Authenticate(ctx, "Bearer "+token)
+	if err != nil {
+		b.Fatalf("Should be able to authenticate the claims : %s", err)
+	}
+
+	userID := uuid.MustParse(claims.Subject)
+
+	for _, rule := range []string{auth.RuleAny, auth.RuleAdminOnly, auth.RuleAdminOrSubject} {
+		b.Run(rule, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := a.Authorize(ctx, claims, userID, rule); err != nil {
+					b.Fatalf("Should be able to authorize the claims : %s", err)
+				}
+			}
+		})
+	}
+}
+
+// Test_DisabledUser disables a user the way the admin userdel command does
+// and checks neither the token they already hold nor their password gets
+// them in any longer.
+func Test_DisabledUser(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+	ctx := context.Background()
+
+	userCore := user.NewCore(log, delegate.New(log), usermem.NewStore())
+
+	usr, err := userCore.Create(ctx, user.NewUser{
+		Name:     "Leaving",
+		Email:    mail.Address{Address: "leaving@example.com"},
+		Roles:    []string{"USER"},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	authority := authtest.New(t)
+
+	a, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: authority.Keys,
+		Issuer:    authtest.Issuer,
+		UserCore:  userCore,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	bearer := "Bearer " + authority.Token(t, usr.ID.String(), "USER")
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("leaving@example.com:gophers"))
+	handler := func(ctx context.Context) error { return nil }
+
+	if _, err := a.Authenticate(ctx, bearer); err != nil {
+		t.Fatalf("Should be able to authenticate an enabled user : %s", err)
+	}
+
+	if err := mid.Basic(ctx, a, userCore, time.Minute, basic, handler); err != nil {
+		t.Fatalf("Should be able to use basic auth as an enabled user : %s", err)
+	}
+
+	enabled := false
+	if _, err := userCore.Update(ctx, usr, user.UpdateUser{Enabled: &enabled}); err != nil {
+		t.Fatalf("Should be able to disable the user : %s", err)
+	}
+
+	if _, err := a.Authenticate(ctx, bearer); !errors.Is(err, auth.ErrUserDisabled) {
+		t.Errorf("Should reject the token of a disabled user, got %v", err)
+	}
+
+	if err := mid.Basic(ctx, a, userCore, time.Minute, basic, handler); err == nil {
+		t.Error("Should reject basic auth of a disabled user")
+	}
+}
+
+// Test_TenantClaim checks the tokens issued to a user carry the tenant of
+// the user, which a claim mapping can't replace.
+func Test_TenantClaim(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+	tenantID := uuid.New()
+
+	userCore := user.NewCore(log, delegate.New(log), usermem.NewStore())
+
+	_, err := userCore.Create(tenant.WithID(context.Background(), tenantID), user.NewUser{
+		Name:       "Tenant",
+		Email:      mail.Address{Address: "tenant@example.com"},
+		Roles:      []string{"USER"},
+		Department: "sales",
+		Password:   "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	if _, err := auth.ParseClaimMapping([]string{"tenant:department"}); err == nil {
+		t.Error("Should not be able to map the tenant claim")
+	}
+
+	authority := authtest.New(t)
+
+	var claims auth.Claims
+	handler := func(ctx context.Context) error {
+		claims = mid.GetClaims(ctx)
+		return nil
+	}
+
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("tenant@example.com:gophers"))
+	if err := mid.Basic(context.Background(), authority.Auth, userCore, time.Minute, basic, handler); err != nil {
+		t.Fatalf("Should be able to use basic auth : %s", err)
+	}
+
+	if claims.Tenant != tenantID.String() {
+		t.Errorf("Should issue the tenant of the user, got %q, exp %q", claims.Tenant, tenantID)
+	}
+}
+
+// Test_FederatedClaims checks a token of an external provider is mapped to
+// the subject, roles and tenant the provider is configured with, whatever
+// the token itself claims.
+func Test_FederatedClaims(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	idp := authtest.New(t, "idp")
+
+	pem, err := idp.Keys.PublicKey("idp")
+	if err != nil {
+		t.Fatalf("Should be able to get the public key : %s", err)
+	}
+
+	jwk, err := auth.NewJWK("idp", pem)
+	if err != nil {
+		t.Fatalf("Should be able to convert the public key : %s", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(auth.JWKS{Keys: []auth.JWK{jwk}})
+	}))
+	defer srv.Close()
+
+	const (
+		mapped   = "https://mapped.example.com"
+		unmapped = "https://unmapped.example.com"
+	)
+
+	tenantID := uuid.NewString()
+
+	a, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: authtest.New(t).Keys,
+		Issuer:    authtest.Issuer,
+		Providers: []auth.Provider{
+			{Issuer: mapped, JWKSURL: srv.URL, Audience: "sales", Roles: map[string]string{"sales-admin": "ADMIN", "staff": "USER"}, Tenant: tenantID},
+			{Issuer: unmapped, JWKSURL: srv.URL, Audience: "sales"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	// The token claims a subject that is the id of a user of the service,
+	// roles of the service and a tenant of its own.
+	subject := uuid.NewString()
+
+	token := func(issuer string) string {
+		return "Bearer " + idp.TokenWith(t, "idp", auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:   issuer,
+				Subject:  subject,
+				Audience: jwt.ClaimStrings{"sales"},
+			},
+			Roles:       []string{"ADMIN", "staff"},
+			Permissions: []string{auth.ScopeAdmin},
+			Tenant:      uuid.NewString(),
+		})
+	}
+
+	tests := []struct {
+		name   string
+		issuer string
+		roles  []string
+		tenant string
+	}{
+		{"mapped", mapped, []string{"USER"}, tenantID},
+		{"unmapped", unmapped, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := a.Authenticate(context.Background(), token(tt.issuer))
+			if err != nil {
+				t.Fatalf("Should be able to authenticate the token of the provider : %s", err)
+			}
+
+			exp := uuid.NewSHA1(uuid.NameSpaceURL, []byte(tt.issuer+"#"+subject)).String()
+			if claims.Subject != exp {
+				t.Errorf("Should namespace the subject with the issuer, got %s, exp %s", claims.Subject, exp)
+			}
+
+			if !slices.Equal(claims.Roles, tt.roles) {
+				t.Errorf("Should only keep the mapped roles, got %v, exp %v", claims.Roles, tt.roles)
+			}
+
+			if claims.HasScope(auth.ScopeAdmin) {
+				t.Error("Should not keep the permissions of the token")
+			}
+
+			if claims.Tenant != tt.tenant {
+				t.Errorf("Should only keep the tenant of the provider, got %q, exp %q", claims.Tenant, tt.tenant)
+			}
+		})
+	}
+
+	_, err = auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: authtest.New(t).Keys,
+		Issuer:    authtest.Issuer,
+		Providers: []auth.Provider{{Issuer: mapped, JWKSURL: srv.URL, Audience: "sales", Roles: map[string]string{"root": "ROOT"}}},
+	})
+	if err == nil {
+		t.Error("Should not be able to map a role to one the service doesn't have")
+	}
+}
+
+// newBench constructs an authenticator that logs nowhere and a token for an
+// admin to exercise it with.
+func newBench(b *testing.B) (*auth.Auth, string) {
+	b.Helper()
+
+	a, err := auth.New(auth.Config{
+		Log:       logger.New(logger.Discard, logger.LevelInfo, "BENCH", nil),
+		KeyLookup: &keyStore{},
+		Issuer:    "service project",
+	})
+	if err != nil {
+		b.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "service project",
+			Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: []string{"ADMIN"},
+	}
+
+	token, err := a.GenerateToken(kid, claims)
+	if err != nil {
+		b.Fatalf("Should be able to generate a JWT : %s", err)
+	}
+
+	return a, token
+}
+
 func newUnit(t *testing.T) (*logger.Logger, func()) {
 	var buf bytes.Buffer
 	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "00000000-0000-0000-0000-000000000000" })
@@ -0,0 +1,199 @@
+// Package authtest issues signed tokens for the tests of the middleware and
+// handlers. The keys are generated in memory when a test starts, so the
+// tests need neither the auth service nor the key files of an environment.
+package authtest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// These are the issuer of the tokens and the kid they are signed with
+// unless another is asked for.
+const (
+	Issuer     = "authtest"
+	DefaultKID = "authtest"
+)
+
+// TTL is how long the tokens issued are valid.
+const TTL = time.Hour
+
+// Authority holds the keys tokens are signed with and an authenticator
+// that accepts them, to hand the middleware under test.
+type Authority struct {
+	Auth *auth.Auth
+	Keys *Keys
+}
+
+// New constructs an authority with a key for each kid, or for DefaultKID
+// when none are given. The test fails if the keys can't be generated.
+func New(t testing.TB, kids ...string) *Authority {
+	t.Helper()
+
+	if len(kids) == 0 {
+		kids = []string{DefaultKID}
+	}
+
+	keys := Keys{
+		keys: make(map[string]key),
+		kids: kids,
+	}
+
+	for _, kid := range kids {
+		if err := keys.Add(kid); err != nil {
+			t.Fatalf("authtest: adding key %s: %s", kid, err)
+		}
+	}
+
+	a, err := auth.New(auth.Config{
+		Log:       logger.New(io.Discard, logger.LevelError, "AUTHTEST", nil),
+		KeyLookup: &keys,
+		Issuer:    Issuer,
+	})
+	if err != nil {
+		t.Fatalf("authtest: constructing auth: %s", err)
+	}
+
+	return &Authority{Auth: a, Keys: &keys}
+}
+
+// Token returns a token for the subject with the roles, signed with the
+// first kid. The token holds the scopes the roles are granted, like the
+// ones the auth service issues.
+func (a *Authority) Token(t testing.TB, subject string, roles ...string) string {
+	t.Helper()
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: subject,
+		},
+		Roles:       roles,
+		Permissions: auth.ScopesFor(roles),
+	}
+
+	return a.TokenWith(t, a.Keys.kids[0], claims)
+}
+
+// TokenWith returns the claims signed with the kid, which can be one the
+// authority doesn't have a key for, to test a token from another issuer
+// is refused. The issuer and the times of the token are filled in when
+// they are left empty.
+func (a *Authority) TokenWith(t testing.TB, kid string, claims auth.Claims) string {
+	t.Helper()
+
+	now := time.Now().UTC()
+
+	if claims.Issuer == "" {
+		claims.Issuer = Issuer
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(TTL))
+	}
+
+	if _, err := a.Keys.PrivateKey(kid); err != nil {
+		if err := a.Keys.Add(kid); err != nil {
+			t.Fatalf("authtest: adding key %s: %s", kid, err)
+		}
+		defer a.Keys.Remove(kid)
+	}
+
+	token, err := a.Auth.GenerateToken(kid, claims)
+	if err != nil {
+		t.Fatalf("authtest: generating token: %s", err)
+	}
+
+	return token
+}
+
+// =============================================================================
+
+// key is a generated key pair in PEM form.
+type key struct {
+	privatePEM string
+	publicPEM  string
+}
+
+// Keys is an in-memory auth.KeyLookup of generated Ed25519 keys, which are
+// quick to generate. It is safe for concurrent use.
+type Keys struct {
+	mu   sync.RWMutex
+	keys map[string]key
+	kids []string
+}
+
+// Add generates a key for the kid, replacing the one it had, such as to
+// test tokens signed with a rotated key are refused.
+func (k *Keys) Add(kid string) error {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[kid] = key{
+		privatePEM: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		publicPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}
+
+	return nil
+}
+
+// Remove deletes the key of the kid.
+func (k *Keys) Remove(kid string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.keys, kid)
+}
+
+// PrivateKey implements the auth.KeyLookup interface.
+func (k *Keys) PrivateKey(kid string) (string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, found := k.keys[kid]
+	if !found {
+		return "", errors.New("kid lookup failed")
+	}
+
+	return key.privatePEM, nil
+}
+
+// PublicKey implements the auth.KeyLookup interface.
+func (k *Keys) PublicKey(kid string) (string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, found := k.keys[kid]
+	if !found {
+		return "", errors.New("kid lookup failed")
+	}
+
+	return key.publicPEM, nil
+}
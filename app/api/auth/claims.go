@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// These are the extra claims with typed accessors. Any other name can be
+// mapped and read with Claim.
+const (
+	ClaimDepartment = "department"
+	ClaimRegion     = "region"
+	ClaimTenant     = "tenant"
+)
+
+// reservedClaims can't be mapped since the service sets them itself.
+var reservedClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "roles", "permissions", "tenant", "ext"}
+
+// ClaimMapping declares the extra claims put in the tokens issued to users,
+// keyed by the name of the claim. The value names the attribute of the user
+// record the claim is taken from, such as department, or is a fixed value
+// when it starts with =, such as =eu-west for the region of a deployment.
+// The claims are set in Extra. The tenant claim can't be mapped, it is
+// always the tenant the user belongs to.
+type ClaimMapping map[string]string
+
+// ParseClaimMapping parses the claim:source pairs of a configuration into
+// a mapping.
+func ParseClaimMapping(pairs []string) (ClaimMapping, error) {
+	mapping := make(ClaimMapping, len(pairs))
+	for _, pair := range pairs {
+		claim, source, ok := strings.Cut(pair, ":")
+		if !ok || claim == "" || source == "" {
+			return nil, fmt.Errorf("claim mapping %q must be in the form claim:attribute or claim:=value", pair)
+		}
+
+		if slices.Contains(reservedClaims, claim) {
+			return nil, fmt.Errorf("claim %q is set by the service and can't be mapped", claim)
+		}
+
+		mapping[claim] = source
+	}
+
+	return mapping, nil
+}
+
+// MapClaims adds the tenant of the user and the claims of the mapping to the
+// claims, taking them from the attributes of the user they are issued to. A
+// claim whose attribute is empty is left out.
+func (a *Auth) MapClaims(claims *Claims, attrs map[string]string) {
+	claims.Tenant = attrs[ClaimTenant]
+
+	for claim, source := range a.mapping {
+		value, fixed := strings.CutPrefix(source, "=")
+		if !fixed {
+			value = attrs[source]
+		}
+
+		if value == "" {
+			continue
+		}
+
+		if claims.Extra == nil {
+			claims.Extra = make(map[string]string, len(a.mapping))
+		}
+		claims.Extra[claim] = value
+	}
+}
+
+// Claim returns the value of the named extra claim.
+func (c Claims) Claim(name string) (string, bool) {
+	v, exists := c.Extra[name]
+	return v, exists
+}
+
+// Department returns the department claim, empty when it isn't mapped.
+func (c Claims) Department() string {
+	return c.Extra[ClaimDepartment]
+}
+
+// Region returns the region claim, empty when it isn't mapped.
+func (c Claims) Region() string {
+	return c.Extra[ClaimRegion]
+}
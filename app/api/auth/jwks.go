@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PublicKeySet declares the behavior of a KeyLookup that can list its public
+// keys so they can be published as a JWKS.
+type PublicKeySet interface {
+	PublicKeys() map[string]string
+}
+
+// JWK represents a single public key in the JSON Web Key format. RSA keys
+// carry n and e, EC keys crv, x and y, and OKP (Ed25519) keys crv and x.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS represents a set of public keys other services can use to verify
+// the tokens signed by this service.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK converts a PEM encoded RSA, ECDSA P-256 or Ed25519 public key to a
+// JWK.
+func NewJWK(kid string, publicPEM string) (JWK, error) {
+	key, method, err := ParsePublicKeyPEM(publicPEM)
+	if err != nil {
+		return JWK{}, fmt.Errorf("parsing public pem: %w", err)
+	}
+
+	jwk := JWK{
+		Kid: kid,
+		Use: "sig",
+		Alg: method.Alg(),
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+
+	case *ecdsa.PublicKey:
+		ecdhKey, err := k.ECDH()
+		if err != nil {
+			return JWK{}, fmt.Errorf("converting public key: %w", err)
+		}
+
+		// The uncompressed point is 0x04 followed by x and y.
+		point := ecdhKey.Bytes()[1:]
+		size := len(point) / 2
+
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(point[:size])
+		jwk.Y = base64.RawURLEncoding.EncodeToString(point[size:])
+
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(k)
+	}
+
+	return jwk, nil
+}
+
+// PublicPEM converts the JWK back to the PEM encoded public key the
+// KeyLookup interface works with.
+func (k JWK) PublicPEM() (string, error) {
+	var key any
+
+	switch {
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return "", fmt.Errorf("decoding modulus: %w", err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return "", fmt.Errorf("decoding exponent: %w", err)
+		}
+
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return "", errors.New("exponent out of range")
+		}
+
+		key = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(exp.Int64()),
+		}
+
+	case k.Kty == "EC" && k.Crv == "P-256":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return "", fmt.Errorf("decoding x: %w", err)
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return "", fmt.Errorf("decoding y: %w", err)
+		}
+
+		// Parsing the point checks it is on the curve.
+		point := append(append([]byte{4}, x...), y...)
+		ecdhKey, err := ecdh.P256().NewPublicKey(point)
+		if err != nil {
+			return "", fmt.Errorf("invalid point: %w", err)
+		}
+		key = ecdhKey
+
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return "", fmt.Errorf("decoding x: %w", err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return "", errors.New("invalid ed25519 key size")
+		}
+		key = ed25519.PublicKey(x)
+
+	default:
+		return "", fmt.Errorf("unsupported key type %q %q", k.Kty, k.Crv)
+	}
+
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("marshaling public key: %w", err)
+	}
+
+	block := pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: asn1Bytes,
+	}
+
+	return string(pem.EncodeToMemory(&block)), nil
+}
+
+// JWKS returns the public keys of every key the service can sign tokens
+// with, ordered by kid.
+func (a *Auth) JWKS() (JWKS, error) {
+	set, ok := a.keyLookup.(PublicKeySet)
+	if !ok {
+		return JWKS{}, errors.New("key lookup can't list its public keys")
+	}
+
+	keys := set.PublicKeys()
+
+	jwks := JWKS{
+		Keys: make([]JWK, 0, len(keys)),
+	}
+
+	for kid, publicPEM := range keys {
+		jwk, err := NewJWK(kid, publicPEM)
+		if err != nil {
+			return JWKS{}, fmt.Errorf("kid[%s]: %w", kid, err)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	slices.SortFunc(jwks.Keys, func(a JWK, b JWK) int {
+		return strings.Compare(a.Kid, b.Kid)
+	})
+
+	return jwks, nil
+}
+
+// =============================================================================
+
+// jwksTimeout bounds fetching the keys since the key lookup has no context
+// of its own to inherit a deadline from.
+const jwksTimeout = 10 * time.Second
+
+// FetchJWKSFn represents a function that retrieves the current set of public
+// keys from wherever they are published.
+type FetchJWKSFn func(ctx context.Context) (JWKS, error)
+
+// KeyCache implements the KeyLookup interface with public keys published as
+// a JWKS by another party. The keys are fetched again once they are older
+// than the ttl, or when a token names a kid that isn't known yet, which is
+// how a rotated key is picked up. Keys that aren't signing keys of a
+// supported type are skipped.
+type KeyCache struct {
+	fetch     FetchJWKSFn
+	ttl       time.Duration
+	mu        sync.Mutex
+	keys      map[string]string
+	fetched   time.Time
+	attempted time.Time
+}
+
+// NewKeyCache constructs a KeyCache that retrieves the keys with fetch.
+func NewKeyCache(fetch FetchJWKSFn, ttl time.Duration) *KeyCache {
+	return &KeyCache{
+		fetch: fetch,
+		ttl:   ttl,
+	}
+}
+
+// PrivateKey implements the KeyLookup interface. A JWKS never contains
+// private keys so this always fails.
+func (kc *KeyCache) PrivateKey(kid string) (string, error) {
+	return "", errors.New("private keys are not available from a jwks")
+}
+
+// PublicKey implements the KeyLookup interface. An unknown kid only causes
+// a fetch once per tenth of the ttl, so tokens with made up kids can't be
+// used to flood whoever publishes the keys.
+func (kc *KeyCache) PublicKey(kid string) (string, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	now := time.Now()
+
+	key, exists := kc.keys[kid]
+	stale := now.Sub(kc.fetched) >= kc.ttl
+
+	if (exists && !stale) || now.Sub(kc.attempted) < kc.ttl/10 {
+		if !exists {
+			return "", errors.New("kid lookup failed")
+		}
+		return key, nil
+	}
+
+	kc.attempted = now
+
+	if err := kc.refresh(); err != nil {
+
+		// A stale key is better than failing every token while the keys
+		// can't be fetched.
+		if exists {
+			return key, nil
+		}
+		return "", fmt.Errorf("kid lookup failed: %w", err)
+	}
+
+	key, exists = kc.keys[kid]
+	if !exists {
+		return "", errors.New("kid lookup failed")
+	}
+
+	return key, nil
+}
+
+func (kc *KeyCache) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksTimeout)
+	defer cancel()
+
+	jwks, err := kc.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+
+	keys := make(map[string]string, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		publicPEM, err := jwk.PublicPEM()
+		if err != nil {
+			continue
+		}
+
+		keys[jwk.Kid] = publicPEM
+	}
+
+	kc.keys = keys
+	kc.fetched = time.Now()
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// ErrUnknownIssuer is returned when a token was issued by neither this
+// service nor one of the configured providers.
+var ErrUnknownIssuer = errors.New("token issuer is not trusted")
+
+// oidcClient is used to discover the providers and fetch their keys.
+var oidcClient = http.Client{
+	Timeout: jwksTimeout,
+}
+
+// Provider represents an external OIDC identity provider, such as Keycloak
+// or Auth0, whose tokens are accepted in addition to the ones this service
+// mints.
+type Provider struct {
+
+	// Issuer must match the iss claim of the tokens exactly. Unless JWKSURL
+	// is set, the keys are discovered from the issuer's openid
+	// configuration.
+	Issuer  string `json:"issuer"`
+	JWKSURL string `json:"jwks_url,omitempty"`
+
+	// Audience must be one of the aud claims of the tokens, which is the id
+	// the service is registered under with the provider.
+	Audience string `json:"audience"`
+
+	// RolesClaim is the dotted path to the roles in the token, such as
+	// realm_access.roles for Keycloak. The default is roles.
+	RolesClaim string `json:"roles_claim,omitempty"`
+
+	// Roles maps the roles of the provider, as found at RolesClaim, to the
+	// roles of this service, such as sales-admin to ADMIN. A role that
+	// isn't mapped is dropped, so without a mapping the identities of the
+	// provider have no roles.
+	Roles map[string]string `json:"roles,omitempty"`
+
+	// Tenant, when set, is the tenant every identity of the provider
+	// belongs to. A tenant named by the token itself is dropped.
+	Tenant string `json:"tenant,omitempty"`
+
+	// Refresh is how long the keys are cached. The default is five minutes.
+	Refresh time.Duration `json:"-"`
+}
+
+// LoadProviders reads the JSON encoded list of providers from the file. An
+// empty path means there are none.
+func LoadProviders(path string) ([]Provider, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading providers: %w", err)
+	}
+
+	// Durations are written like "5m" rather than in nanoseconds.
+	var raw []struct {
+		Provider
+		Refresh string `json:"refresh,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding providers: %w", err)
+	}
+
+	providers := make([]Provider, len(raw))
+	for i, r := range raw {
+		providers[i] = r.Provider
+
+		if r.Refresh != "" {
+			d, err := time.ParseDuration(r.Refresh)
+			if err != nil {
+				return nil, fmt.Errorf("provider[%s]: refresh: %w", r.Issuer, err)
+			}
+			providers[i].Refresh = d
+		}
+	}
+
+	return providers, nil
+}
+
+// provider holds the keys of a configured provider.
+type provider struct {
+	Provider
+	keys    *KeyCache
+	mu      sync.Mutex
+	jwksURL string
+}
+
+func newProvider(p Provider) (*provider, error) {
+	if p.Issuer == "" {
+		return nil, errors.New("issuer is required")
+	}
+
+	// Without an audience any token the provider issues for any of its
+	// clients would be accepted.
+	if p.Audience == "" {
+		return nil, errors.New("audience is required")
+	}
+
+	if p.RolesClaim == "" {
+		p.RolesClaim = "roles"
+	}
+
+	for from, to := range p.Roles {
+		if _, exists := roleScopes[to]; !exists {
+			return nil, fmt.Errorf("role %q is mapped to %q, which isn't a role of the service", from, to)
+		}
+	}
+
+	if p.Refresh <= 0 {
+		p.Refresh = 5 * time.Minute
+	}
+
+	prv := provider{
+		Provider: p,
+		jwksURL:  p.JWKSURL,
+	}
+	prv.keys = NewKeyCache(prv.fetch, p.Refresh)
+
+	return &prv, nil
+}
+
+func (p *provider) fetch(ctx context.Context) (JWKS, error) {
+	url, err := p.discover(ctx)
+	if err != nil {
+		return JWKS{}, err
+	}
+
+	var jwks JWKS
+	if err := get(ctx, url, &jwks); err != nil {
+		return JWKS{}, fmt.Errorf("jwks: %w", err)
+	}
+
+	return jwks, nil
+}
+
+// discover returns the location of the provider's keys, looking it up in
+// the openid configuration the first time.
+func (p *provider) discover(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.jwksURL != "" {
+		return p.jwksURL, nil
+	}
+
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+
+	url := strings.TrimSuffix(p.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := get(ctx, url, &doc); err != nil {
+		return "", fmt.Errorf("discovery: %w", err)
+	}
+
+	if doc.Issuer != p.Issuer {
+		return "", fmt.Errorf("discovery: issuer %q doesn't match %q", doc.Issuer, p.Issuer)
+	}
+
+	if doc.JWKSURI == "" {
+		return "", errors.New("discovery: no jwks_uri")
+	}
+
+	p.jwksURL = doc.JWKSURI
+
+	return p.jwksURL, nil
+}
+
+// claims maps the verified token of the provider to the claims of this
+// service. The subject is always mapped to a uuid derived from the issuer
+// and the provider's subject, so an external identity always has the same
+// user id and can't pass for a user of this service. Only the roles and the
+// tenant the provider is configured with are kept, the permissions are the
+// scopes of those roles.
+func (p *provider) claims(token string, claims Claims) (Claims, error) {
+	var raw jwt.MapClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &raw); err != nil {
+		return Claims{}, fmt.Errorf("error parsing token: %w", err)
+	}
+
+	external, err := rolesAt(raw, p.RolesClaim)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	var roles []string
+	for _, role := range external {
+		if mapped, exists := p.Roles[role]; exists && !slices.Contains(roles, mapped) {
+			roles = append(roles, mapped)
+		}
+	}
+
+	claims.Subject = uuid.NewSHA1(uuid.NameSpaceURL, []byte(p.Issuer+"#"+claims.Subject)).String()
+	claims.Roles = roles
+	claims.Permissions = ScopesFor(roles)
+	claims.Tenant = p.Tenant
+	claims.Extra = nil
+
+	return claims, nil
+}
+
+func rolesAt(raw map[string]any, path string) ([]string, error) {
+	var v any = raw
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		v = m[key]
+	}
+
+	switch vs := v.(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		return strings.Fields(vs), nil
+
+	case []any:
+		roles := make([]string, 0, len(vs))
+		for _, r := range vs {
+			role, ok := r.(string)
+			if !ok {
+				return nil, fmt.Errorf("roles claim %s holds a %T", path, r)
+			}
+			roles = append(roles, role)
+		}
+		return roles, nil
+
+	default:
+		return nil, fmt.Errorf("roles claim %s holds a %T", path, v)
+	}
+}
+
+func get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request error: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := oidcClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d from %s", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
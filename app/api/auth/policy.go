@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// The files a policy directory must hold.
+const (
+	authenticationFile = "authentication.rego"
+	authorizationFile  = "authorization.rego"
+)
+
+// policy is a set of rego scripts along with the queries prepared from them.
+// Once a policy is in use only its queries change, as rules are compiled.
+type policy struct {
+	authentication string
+	authorization  string
+	revision       string
+	queries        map[string]rego.PreparedEvalQuery
+}
+
+func newPolicy(authentication string, authorization string) *policy {
+	return &policy{
+		authentication: authentication,
+		authorization:  authorization,
+		revision:       revision(authentication, authorization),
+		queries:        make(map[string]rego.PreparedEvalQuery),
+	}
+}
+
+// script returns the rego code the rule is defined in.
+func (p *policy) script(rule string) string {
+	if rule == RuleAuthenticate {
+		return p.authentication
+	}
+
+	return p.authorization
+}
+
+func readPolicy(fsys fs.FS) (*policy, error) {
+	authentication, err := fs.ReadFile(fsys, authenticationFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+
+	authorization, err := fs.ReadFile(fsys, authorizationFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+
+	return newPolicy(string(authentication), string(authorization)), nil
+}
+
+func revision(authentication string, authorization string) string {
+	sum := sha256.Sum256([]byte(authentication + authorization))
+	return hex.EncodeToString(sum[:6])
+}
+
+// =============================================================================
+
+// PolicyRevision returns a short digest of the policies currently enforced so
+// deployments and reloads can be told apart by the rules they enforce.
+func (a *Auth) PolicyRevision() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.policy.revision
+}
+
+// ReloadPolicies reads the policies from the configured directory again and
+// starts enforcing them once every rule compiles. On any error the current
+// policies stay in place.
+func (a *Auth) ReloadPolicies(ctx context.Context) error {
+	if a.policyFS == nil {
+		return errors.New("the policies are compiled in and can't be reloaded")
+	}
+
+	p, err := readPolicy(a.policyFS)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		q, err := prepare(ctx, p.script(rule), rule)
+		if err != nil {
+			return fmt.Errorf("compile rule[%s]: %w", rule, err)
+		}
+		p.queries[rule] = q
+	}
+
+	a.mu.Lock()
+	a.policy = p
+	a.mu.Unlock()
+
+	return nil
+}
+
+// WatchPolicies checks the policy directory for changes on every interval
+// and reloads the policies when their content changed. It returns once the
+// context is canceled.
+func (a *Auth) WatchPolicies(ctx context.Context, interval time.Duration) {
+	if a.policyFS == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p, err := readPolicy(a.policyFS)
+			if err != nil {
+				a.logError(ctx, "policy watch", "msg", err)
+				continue
+			}
+
+			prev := a.PolicyRevision()
+			if p.revision == prev {
+				continue
+			}
+
+			if err := a.ReloadPolicies(ctx); err != nil {
+				a.logError(ctx, "policy watch", "revision", p.revision, "msg", err)
+				continue
+			}
+
+			a.logInfo(ctx, "policy watch", "status", "policies reloaded", "from", prev, "to", a.PolicyRevision())
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *Auth) logInfo(ctx context.Context, msg string, args ...any) {
+	if a.log != nil {
+		a.log.Info(ctx, msg, args...)
+	}
+}
+
+func (a *Auth) logError(ctx context.Context, msg string, args ...any) {
+	if a.log != nil {
+		a.log.Error(ctx, msg, args...)
+	}
+}
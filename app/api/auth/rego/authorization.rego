@@ -10,11 +10,15 @@ default rule_user_only := false
 
 default rule_admin_or_subject := false
 
+default rule_service_only := false
+
 role_user := "USER"
 
 role_admin := "ADMIN"
 
-role_all := {role_admin, role_user}
+role_service := "SERVICE"
+
+role_all := {role_admin, role_user, role_service}
 
 rule_any if {
 	claim_roles := {role | some role in input.Roles}
@@ -34,6 +38,12 @@ rule_user_only if {
 	count(input_user) > 0
 }
 
+rule_service_only if {
+	claim_roles := {role | some role in input.Roles}
+	input_service := {role_service} & claim_roles
+	count(input_service) > 0
+}
+
 rule_admin_or_subject if {
 	claim_roles := {role | some role in input.Roles}
 	input_admin := {role_admin} & claim_roles
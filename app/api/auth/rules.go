@@ -11,8 +11,20 @@ const (
 	RuleAdminOnly      = "rule_admin_only"
 	RuleUserOnly       = "rule_user_only"
 	RuleAdminOrSubject = "rule_admin_or_subject"
+	RuleServiceOnly    = "rule_service_only"
 )
 
+// rules are the rules compiled ahead of time. Any other rule is compiled
+// from the authorization policy on first use.
+var rules = []string{
+	RuleAuthenticate,
+	RuleAny,
+	RuleAdminOnly,
+	RuleUserOnly,
+	RuleAdminOrSubject,
+	RuleServiceOnly,
+}
+
 // Package name of our rego code.
 const (
 	opaPackage string = "service.rego"
@@ -26,3 +38,17 @@ var (
 	//go:embed rego/authorization.rego
 	regoAuthorization string
 )
+
+// PolicyRevision returns a short digest of the policies compiled into the
+// binary so deployments can be told apart by the rules they enforce.
+func PolicyRevision() string {
+	return revision(regoAuthentication, regoAuthorization)
+}
+
+// RoleAdmin is the role of the users that administer the service, who
+// aren't bound to a tenant.
+const RoleAdmin = "ADMIN"
+
+// RoleService is the role of the tokens issued to service clients with the
+// client credentials grant.
+const RoleService = "SERVICE"
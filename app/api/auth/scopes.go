@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"slices"
+)
+
+// These are the scopes a token can be limited to. A scope names a resource
+// and what can be done with it.
+const (
+	ScopeSalesRead  = "sales:read"
+	ScopeSalesWrite = "sales:write"
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+	ScopeAdmin      = "admin"
+)
+
+// Scope represents an entry of the scope catalog.
+type Scope struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// catalog is every scope in the order it's documented.
+var catalog = []Scope{
+	{ScopeSalesRead, "read products, orders and the other sales resources"},
+	{ScopeSalesWrite, "create and change the sales resources"},
+	{ScopeUsersRead, "read user accounts"},
+	{ScopeUsersWrite, "create and change user accounts"},
+	{ScopeAdmin, "use the operational administration api"},
+}
+
+// roleScopes are the scopes a role is granted when a token is issued. A
+// user is granted users:write to change their own account, the rules of
+// the user routes keep them from changing anyone else's.
+var roleScopes = map[string][]string{
+	"ADMIN": {ScopeSalesRead, ScopeSalesWrite, ScopeUsersRead, ScopeUsersWrite, ScopeAdmin},
+	"USER":  {ScopeSalesRead, ScopeSalesWrite, ScopeUsersRead, ScopeUsersWrite},
+}
+
+// Catalog returns every scope a token can hold.
+func Catalog() []Scope {
+	return slices.Clone(catalog)
+}
+
+// IsScope reports whether the scope is part of the catalog.
+func IsScope(scope string) bool {
+	return slices.ContainsFunc(catalog, func(s Scope) bool {
+		return s.Name == scope
+	})
+}
+
+// ScopesFor returns the scopes granted by the roles, sorted.
+func ScopesFor(roles []string) []string {
+	var scopes []string
+	for _, role := range roles {
+		scopes = append(scopes, roleScopes[role]...)
+	}
+
+	slices.Sort(scopes)
+
+	return slices.Compact(scopes)
+}
+
+// HasScope checks if the claims grant the specified scope. Tokens issued
+// before the permissions claim existed are granted the scopes of their
+// roles.
+func (c Claims) HasScope(scope string) bool {
+	perms := c.Permissions
+	if perms == nil {
+		perms = ScopesFor(c.Roles)
+	}
+
+	return slices.Contains(perms, scope)
+}
@@ -5,11 +5,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net"
 	"net/http"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/reqsign"
 )
 
 // This provides a default client configuration, but it's recommended
@@ -31,28 +38,67 @@ var defaultClient = http.Client{
 	},
 }
 
+// ErrUnavailable is returned when the auth service couldn't be reached or
+// failed to handle a call, after every retry.
+var ErrUnavailable = errors.New("auth service unavailable")
+
+// ErrCircuitOpen is returned without calling the auth service while the
+// breaker is open. It's an ErrUnavailable.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit open", ErrUnavailable)
+
 // Logger represents a function that has user logging context.
 type Logger func(ctx context.Context, msg string, v ...any)
 
 // Client represents a client that can talk to the auth service.
 type Client struct {
-	url  string
-	log  Logger
-	http *http.Client
+	url    string
+	log    Logger
+	http   *http.Client
+	signer *reqsign.Signer
+	local  *auth.Auth
+	issuer string
+	ttl    time.Duration
+	users  *user.Core
+
+	attempts int
+	backoff  time.Duration
+	timeout  time.Duration
+	breaker  *breaker
 }
 
 // New constructs an Auth that can be used to talk with the auth service.
 func New(url string, log Logger, options ...func(cln *Client)) *Client {
 	cln := Client{
-		url:  url,
-		log:  log,
-		http: &defaultClient,
+		url:      url,
+		log:      log,
+		http:     &defaultClient,
+		attempts: 1,
 	}
 
 	for _, option := range options {
 		option(&cln)
 	}
 
+	if cln.signer != nil {
+		client := *cln.http
+		client.Transport = cln.signer.Transport(client.Transport)
+		cln.http = &client
+	}
+
+	if cln.ttl > 0 {
+
+		// The auth package can't fail to construct. Should that change,
+		// the client keeps calling the auth service for every token.
+		local, err := auth.New(auth.Config{
+			KeyLookup: auth.NewKeyCache(cln.JWKS, cln.ttl),
+			Issuer:    cln.issuer,
+			UserCore:  cln.users,
+		})
+		if err == nil {
+			cln.local = local
+		}
+	}
+
 	return &cln
 }
 
@@ -64,8 +110,76 @@ func WithClient(http *http.Client) func(cln *Client) {
 	}
 }
 
-// Authenticate calls the auth service to authenticate the user.
+// WithSigner signs every request made to the auth service so it can verify
+// which service is calling it.
+func WithSigner(signer *reqsign.Signer) func(cln *Client) {
+	return func(cln *Client) {
+		cln.signer = signer
+	}
+}
+
+// WithLocalAuthentication verifies tokens with the public keys the auth
+// service publishes instead of calling it for every request. The keys are
+// cached for the ttl. Authorization is still decided by the auth service.
+func WithLocalAuthentication(issuer string, ttl time.Duration) func(cln *Client) {
+	return func(cln *Client) {
+		cln.issuer = issuer
+		cln.ttl = ttl
+	}
+}
+
+// WithUserCheck checks the users the tokens verified locally were issued
+// to are still enabled, like the auth service does for the tokens it
+// verifies.
+func WithUserCheck(userCore *user.Core) func(cln *Client) {
+	return func(cln *Client) {
+		cln.users = userCore
+	}
+}
+
+// WithRetries retries the calls that fail because the auth service couldn't
+// be reached or returned a 5xx, such as while it restarts. A call is made up
+// to attempts times, waiting a random time of up to backoff before the
+// second attempt and up to twice as long again before each one after it.
+func WithRetries(attempts int, backoff time.Duration) func(cln *Client) {
+	return func(cln *Client) {
+		cln.attempts = max(attempts, 1)
+		cln.backoff = backoff
+	}
+}
+
+// WithTimeout limits how long each attempt of a call can take. The deadline
+// of the context the call is made with still applies to the call as a
+// whole.
+func WithTimeout(timeout time.Duration) func(cln *Client) {
+	return func(cln *Client) {
+		cln.timeout = timeout
+	}
+}
+
+// WithBreaker stops calling the auth service for the cooldown once threshold
+// calls in a row failed to get an answer from it, returning ErrCircuitOpen
+// instead. A single call is then let through, which closes the breaker if
+// it succeeds.
+func WithBreaker(threshold int, cooldown time.Duration) func(cln *Client) {
+	return func(cln *Client) {
+		cln.breaker = newBreaker(max(threshold, 1), cooldown)
+	}
+}
+
+// Authenticate authenticates the user, calling the auth service unless the
+// client verifies tokens locally.
 func (cln *Client) Authenticate(ctx context.Context, authorization string) (AuthenticateResp, error) {
+	if cln.local != nil {
+		resp, err := cln.authenticateLocal(ctx, authorization)
+
+		// Tokens of the external providers and session tokens are left to
+		// the auth service, which knows about them.
+		if !errors.Is(err, auth.ErrUnknownIssuer) {
+			return resp, err
+		}
+	}
+
 	endpoint := fmt.Sprintf("%s/auth/authenticate", cln.url)
 
 	headers := map[string]string{
@@ -80,6 +194,25 @@ func (cln *Client) Authenticate(ctx context.Context, authorization string) (Auth
 	return resp, nil
 }
 
+func (cln *Client) authenticateLocal(ctx context.Context, authorization string) (AuthenticateResp, error) {
+	claims, err := cln.local.Authenticate(ctx, authorization)
+	if err != nil {
+		return AuthenticateResp{}, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return AuthenticateResp{}, fmt.Errorf("parsing subject: %w", err)
+	}
+
+	resp := AuthenticateResp{
+		UserID: userID,
+		Claims: claims,
+	}
+
+	return resp, nil
+}
+
 // Authorize calls the auth service to authorize the user.
 func (cln *Client) Authorize(ctx context.Context, auth Authorize) error {
 	endpoint := fmt.Sprintf("%s/auth/authorize", cln.url)
@@ -89,20 +222,123 @@ func (cln *Client) Authorize(ctx context.Context, auth Authorize) error {
 		return fmt.Errorf("encoding error: %w", err)
 	}
 
-	if err := cln.rawRequest(ctx, http.MethodPost, endpoint, nil, &b, nil); err != nil {
+	if err := cln.rawRequest(ctx, http.MethodPost, endpoint, nil, b.Bytes(), nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// JWKS calls the auth service for the public keys it signs tokens with.
+func (cln *Client) JWKS(ctx context.Context) (auth.JWKS, error) {
+	endpoint := fmt.Sprintf("%s/auth/.well-known/jwks.json", cln.url)
+
+	var jwks auth.JWKS
+	if err := cln.rawRequest(ctx, http.MethodGet, endpoint, nil, nil, &jwks); err != nil {
+		return auth.JWKS{}, err
+	}
+
+	return jwks, nil
+}
+
+// Ping calls the auth service readiness endpoint to establish a connection
+// with the service and verify it is available.
+func (cln *Client) Ping(ctx context.Context) error {
+	endpoint := fmt.Sprintf("%s/readiness", cln.url)
+
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := cln.rawRequest(ctx, http.MethodGet, endpoint, nil, nil, &resp); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (cln *Client) rawRequest(ctx context.Context, method string, url string, headers map[string]string, r io.Reader, v any) error {
+// maxBackoff caps the wait between two attempts however many there are.
+const maxBackoff = 5 * time.Second
+
+func (cln *Client) rawRequest(ctx context.Context, method string, url string, headers map[string]string, body []byte, v any) error {
 	cln.log(ctx, "authclient: rawRequest: started", "method", method, "url", url)
 	defer cln.log(ctx, "authclient: rawRequest: completed")
 
+	if cln.breaker == nil {
+		return cln.retry(ctx, method, url, headers, body, v)
+	}
+
+	if !cln.breaker.allow() {
+		return ErrCircuitOpen
+	}
+
+	err := cln.retry(ctx, method, url, headers, body, v)
+
+	// A call the caller gave up on says nothing about the auth service.
+	if ctx.Err() != nil {
+		cln.breaker.abandon()
+		return err
+	}
+
+	cln.breaker.done(errors.Is(err, ErrUnavailable))
+
+	return err
+}
+
+func (cln *Client) retry(ctx context.Context, method string, url string, headers map[string]string, body []byte, v any) error {
+	for attempt := 1; ; attempt++ {
+		retry, err := cln.do(ctx, method, url, headers, body, v)
+		if retry {
+			err = fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+
+		// An attempt that timed out is retried, the caller giving up isn't.
+		if !retry || attempt >= cln.attempts || ctx.Err() != nil {
+			return err
+		}
+
+		// Full jitter spreads out the retries of every request that failed
+		// at the same time, so the auth service isn't hit by all of them at
+		// once as it comes back.
+		wait := cln.backoff
+		for i := 1; i < attempt && wait < maxBackoff; i++ {
+			wait *= 2
+		}
+		wait = min(wait, maxBackoff)
+
+		if wait > 0 {
+			wait = rand.N(wait)
+		}
+
+		cln.log(ctx, "authclient: rawRequest: retrying", "attempt", attempt, "wait", wait, "msg", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+
+		case <-timer.C:
+		}
+	}
+}
+
+// do makes a single attempt of the request and reports whether it's worth
+// making another.
+func (cln *Client) do(ctx context.Context, method string, url string, headers map[string]string, body []byte, v any) (bool, error) {
+	if cln.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cln.timeout)
+		defer cancel()
+	}
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, url, r)
 	if err != nil {
-		return fmt.Errorf("create request error: %w", err)
+		return false, fmt.Errorf("create request error: %w", err)
 	}
 
 	req.Header.Set("Cache-Control", "no-cache")
@@ -115,37 +351,39 @@ func (cln *Client) rawRequest(ctx context.Context, method string, url string, he
 
 	resp, err := cln.http.Do(req)
 	if err != nil {
-		return fmt.Errorf("do: error: %w", err)
+		return true, fmt.Errorf("do: error: %w", err)
 	}
 	defer resp.Body.Close()
 
 	cln.log(ctx, "authclient: rawRequest", "statuscode", resp.StatusCode)
 
 	if resp.StatusCode == http.StatusNoContent {
-		return nil
+		return false, nil
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("copy error: %w", err)
+		return true, fmt.Errorf("copy error: %w", err)
 	}
 
-	switch resp.StatusCode {
-
-	case http.StatusOK:
+	switch {
+	case resp.StatusCode == http.StatusOK:
 		if err := json.Unmarshal(data, v); err != nil {
-			return fmt.Errorf("failed: response: %s, decoding error: %w ", string(data), err)
+			return false, fmt.Errorf("failed: response: %s, decoding error: %w ", string(data), err)
 		}
-		return nil
+		return false, nil
 
-	case http.StatusUnauthorized:
-		var err Error
-		if err := json.Unmarshal(data, &err); err != nil {
-			return fmt.Errorf("failed: response: %s, decoding error: %w ", string(data), err)
+	case resp.StatusCode == http.StatusUnauthorized:
+		var env envelope
+		if err := json.Unmarshal(data, &env); err != nil {
+			return false, fmt.Errorf("failed: response: %s, decoding error: %w ", string(data), err)
 		}
-		return err
+		return false, env.Error
+
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return true, fmt.Errorf("failed: response: %s", string(data))
 
 	default:
-		return fmt.Errorf("failed: response: %s", string(data))
+		return false, fmt.Errorf("failed: response: %s", string(data))
 	}
 }
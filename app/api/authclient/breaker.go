@@ -0,0 +1,108 @@
+package authclient
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/metrics"
+)
+
+// The states of the breaker. Closed lets every call through, open rejects
+// them all, and half-open lets a single call through to probe whether the
+// auth service has recovered.
+const (
+	stateClosed   = "closed"
+	stateOpen     = "open"
+	stateHalfOpen = "half-open"
+)
+
+// breaker stops calling the auth service once it has failed threshold
+// times in a row, so requests fail straight away while it's down rather
+// than each holding a goroutine through every retry and timeout.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	metrics.SetAuthBreakerState(stateClosed)
+
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     stateClosed,
+	}
+}
+
+// allow reports whether a call can be made now.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			metrics.AddAuthBreakerRejected()
+			return false
+		}
+		b.setState(stateHalfOpen)
+		b.probing = true
+		return true
+
+	case stateHalfOpen:
+		if b.probing {
+			metrics.AddAuthBreakerRejected()
+			return false
+		}
+		b.probing = true
+		return true
+
+	default:
+		return true
+	}
+}
+
+// done records the outcome of a call that was allowed. A call fails when
+// the auth service couldn't be reached or failed itself, a response it
+// chose to give, such as a denial, is a success.
+func (b *breaker) done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if !failed {
+		b.failures = 0
+		b.setState(stateClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(stateOpen)
+		metrics.AddAuthBreakerOpened()
+	}
+}
+
+// abandon records a call that was allowed but given up on by the caller,
+// leaving the state as it was.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *breaker) setState(state string) {
+	if b.state != state {
+		b.state = state
+		metrics.SetAuthBreakerState(state)
+	}
+}
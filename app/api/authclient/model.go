@@ -7,7 +7,14 @@ import (
 
 // Error represents an error in the system.
 type Error struct {
+	Code    string `json:"code"`
 	Message string `json:"message"`
+	TraceID string `json:"trace_id"`
+}
+
+// envelope represents the body of a failed response from the auth service.
+type envelope struct {
+	Error Error `json:"error"`
 }
 
 // Error implements the error interface.
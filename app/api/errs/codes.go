@@ -103,6 +103,11 @@ var (
 	// Unauthenticated indicates the request does not have valid
 	// authentication credentials for the operation.
 	Unauthenticated = ErrCode{value: 16}
+
+	// PreconditionFailed indicates a condition the client sent with the
+	// request, such as If-None-Match: *, doesn't hold for the resource.
+	// Unlike FailedPrecondition the client asked for the check itself.
+	PreconditionFailed = ErrCode{value: 17}
 )
 
 // ErrCode represents an error code in the system.
@@ -162,9 +167,10 @@ var codeNumbers = map[string]ErrCode{
 	"unavailable":         Unavailable,
 	"data_loss":           DataLoss,
 	"unauthenticated":     Unauthenticated,
+	"precondition_failed": PreconditionFailed,
 }
 
-var codeNames [17]string
+var codeNames [18]string
 
 func init() {
 	codeNames[OK.value] = "ok"
@@ -184,4 +190,5 @@ func init() {
 	codeNames[Unavailable.value] = "unavailable"
 	codeNames[DataLoss.value] = "data_loss"
 	codeNames[Unauthenticated.value] = "unauthenticated"
+	codeNames[PreconditionFailed.value] = "precondition_failed"
 }
@@ -0,0 +1,28 @@
+package errs
+
+import "time"
+
+// Envelope is the body sent to the client when a request fails. The shape is
+// kept stable so clients can always find the error under the same key.
+type Envelope struct {
+	Error Detail `json:"error"`
+}
+
+// Detail represents an error along with the information needed to find the
+// request it belongs to.
+type Detail struct {
+	Error     `json:",inline"`
+	TraceID   string    `json:"trace_id"`
+	Timestamp time.Time `json:"ts"`
+}
+
+// NewEnvelope wraps the error for the request with the specified trace id.
+func NewEnvelope(err Error, traceID string, now time.Time) Envelope {
+	return Envelope{
+		Error: Detail{
+			Error:     err,
+			TraceID:   traceID,
+			Timestamp: now.UTC(),
+		},
+	}
+}
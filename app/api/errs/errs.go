@@ -8,15 +8,21 @@ import (
 
 // Error represents an error in the system.
 type Error struct {
-	Code    ErrCode `json:"code"`
-	Message string  `json:"message"`
+	Code          ErrCode        `json:"code"`
+	Message       string         `json:"message"`
+	Fields        FieldErrors    `json:"fields,omitempty"`
+	Preconditions []Precondition `json:"preconditions,omitempty"`
 }
 
-// New constructs an error based on an app error.
+// New constructs an error based on an app error. Field level failures and
+// precondition violations the error carries are kept so they're part of the
+// response, and a unique violation raised as Internal is reported as Aborted.
 func New(code ErrCode, err error) Error {
 	return Error{
-		Code:    code,
-		Message: err.Error(),
+		Code:          codeOf(code, err),
+		Message:       err.Error(),
+		Fields:        fieldsOf(err),
+		Preconditions: preconditionsOf(err),
 	}
 }
 
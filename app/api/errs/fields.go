@@ -0,0 +1,72 @@
+package errs
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// FieldError is used to indicate an error with a specific request field.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// FieldErrors represents a collection of field errors. It's returned by
+// model validation so clients get feedback for every field at once.
+type FieldErrors []FieldError
+
+// Add records a failure for the specified field.
+func (fe *FieldErrors) Add(field string, reason string) {
+	*fe = append(*fe, FieldError{Field: field, Reason: reason})
+}
+
+// ToError returns the collection as an error, or nil when there are no
+// failures so it can be returned directly by a Validate method.
+func (fe FieldErrors) ToError() error {
+	if len(fe) == 0 {
+		return nil
+	}
+
+	return fe
+}
+
+// Error implements the error interface.
+func (fe FieldErrors) Error() string {
+	var b strings.Builder
+	for i, f := range fe {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(f.Field)
+		b.WriteString(": ")
+		b.WriteString(f.Reason)
+	}
+
+	return b.String()
+}
+
+// fieldsOf extracts the field level failures carried by the error. Values
+// that couldn't be decoded into the model are reported against the field
+// they were found in.
+func fieldsOf(err error) FieldErrors {
+	var fe FieldErrors
+	if errors.As(err, &fe) {
+		return fe
+	}
+
+	var se *json.SemanticError
+	if errors.As(err, &se) && se.JSONPointer != "" {
+		field := strings.ReplaceAll(strings.TrimPrefix(string(se.JSONPointer), "/"), "/", ".")
+
+		reason := "invalid value"
+		if se.GoType != nil {
+			reason = "must be of type " + se.GoType.String()
+		}
+
+		return FieldErrors{{Field: field, Reason: reason}}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package errs
+
+import "net/http"
+
+var httpStatus [18]int
+
+// init maps out the error codes to http status codes.
+func init() {
+	httpStatus[OK.value] = http.StatusOK
+	httpStatus[Canceled.value] = http.StatusGatewayTimeout
+	httpStatus[Unknown.value] = http.StatusInternalServerError
+	httpStatus[InvalidArgument.value] = http.StatusBadRequest
+	httpStatus[DeadlineExceeded.value] = http.StatusGatewayTimeout
+	httpStatus[NotFound.value] = http.StatusNotFound
+	httpStatus[AlreadyExists.value] = http.StatusConflict
+	httpStatus[PermissionDenied.value] = http.StatusForbidden
+	httpStatus[ResourceExhausted.value] = http.StatusTooManyRequests
+	httpStatus[FailedPrecondition.value] = http.StatusBadRequest
+	httpStatus[Aborted.value] = http.StatusConflict
+	httpStatus[OutOfRange.value] = http.StatusBadRequest
+	httpStatus[Unimplemented.value] = http.StatusNotImplemented
+	httpStatus[Internal.value] = http.StatusInternalServerError
+	httpStatus[Unavailable.value] = http.StatusServiceUnavailable
+	httpStatus[DataLoss.value] = http.StatusInternalServerError
+	httpStatus[Unauthenticated.value] = http.StatusUnauthorized
+	httpStatus[PreconditionFailed.value] = http.StatusPreconditionFailed
+}
+
+// HTTPStatus returns the http status code a response carrying the error code
+// is sent with. Codes that aren't known map to an internal server error.
+func HTTPStatus(code ErrCode) int {
+	if code.value < 0 || code.value >= len(httpStatus) {
+		return http.StatusInternalServerError
+	}
+
+	return httpStatus[code.value]
+}
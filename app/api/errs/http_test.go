@@ -0,0 +1,47 @@
+package errs_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+func Test_HTTPStatus(t *testing.T) {
+	tests := []struct {
+		code   errs.ErrCode
+		status int
+	}{
+		{errs.OK, http.StatusOK},
+		{errs.Canceled, http.StatusGatewayTimeout},
+		{errs.Unknown, http.StatusInternalServerError},
+		{errs.InvalidArgument, http.StatusBadRequest},
+		{errs.DeadlineExceeded, http.StatusGatewayTimeout},
+		{errs.NotFound, http.StatusNotFound},
+		{errs.AlreadyExists, http.StatusConflict},
+		{errs.PermissionDenied, http.StatusForbidden},
+		{errs.ResourceExhausted, http.StatusTooManyRequests},
+		{errs.FailedPrecondition, http.StatusBadRequest},
+		{errs.Aborted, http.StatusConflict},
+		{errs.OutOfRange, http.StatusBadRequest},
+		{errs.Unimplemented, http.StatusNotImplemented},
+		{errs.Internal, http.StatusInternalServerError},
+		{errs.Unavailable, http.StatusServiceUnavailable},
+		{errs.DataLoss, http.StatusInternalServerError},
+		{errs.Unauthenticated, http.StatusUnauthorized},
+		{errs.PreconditionFailed, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			if got := errs.HTTPStatus(tt.code); got != tt.status {
+				t.Errorf("Should get status %d for %s, got %d", tt.status, tt.code, got)
+			}
+		})
+	}
+
+	var unset errs.ErrCode
+	if got := errs.HTTPStatus(unset); got != http.StatusOK {
+		t.Errorf("Should map the zero code to %d, got %d", http.StatusOK, got)
+	}
+}
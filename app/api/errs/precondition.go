@@ -0,0 +1,35 @@
+package errs
+
+import (
+	"errors"
+
+	"github.com/mrcruz117/al-service/business/api/precondition"
+)
+
+// Precondition describes a part of the current state that didn't match what
+// the client expected, so the client can refresh and retry its workflow.
+type Precondition struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// preconditionsOf extracts the violations carried by a rejected conditional
+// write.
+func preconditionsOf(err error) []Precondition {
+	var pe *precondition.Error
+	if !errors.As(err, &pe) {
+		return nil
+	}
+
+	pcs := make([]Precondition, len(pe.Violations))
+	for i, v := range pe.Violations {
+		pcs[i] = Precondition{
+			Field:    v.Field,
+			Expected: v.Expected,
+			Actual:   v.Actual,
+		}
+	}
+
+	return pcs
+}
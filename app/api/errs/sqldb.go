@@ -0,0 +1,23 @@
+package errs
+
+import (
+	"errors"
+
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+)
+
+// codeOf reports an internal error caused by a unique violation or a stale
+// version as Aborted. The write raced another one for the same row, which
+// the client can recover from by reading the current state and trying
+// again.
+func codeOf(code ErrCode, err error) ErrCode {
+	if code != Internal {
+		return code
+	}
+
+	if errors.Is(err, sqldb.ErrDBDuplicatedEntry) || errors.Is(err, sqldb.ErrDBVersionConflict) {
+		return Aborted
+	}
+
+	return code
+}
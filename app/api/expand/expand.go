@@ -0,0 +1,130 @@
+// Package expand provides support for embedding related resources in a
+// response, such as ?expand=user,items.product, so clients don't need a
+// round trip per relation.
+package expand
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+// DefaultMaxDepth is the number of nested expansions allowed when the
+// caller doesn't specify one.
+const DefaultMaxDepth = 3
+
+// Relation describes a related resource that can be expanded.
+type Relation struct {
+
+	// Resource is the name of the related resource in the schema, used to
+	// find the relations that can be expanded beneath it.
+	Resource string
+
+	// Rule is the authorization rule the caller must pass to expand the
+	// relation. An empty rule means no additional check.
+	Rule string
+}
+
+// Schema maps each resource to the relations that can be expanded from it.
+type Schema map[string]map[string]Relation
+
+// AuthorizeFn checks the caller passes the specified rule.
+type AuthorizeFn func(ctx context.Context, rule string) error
+
+// Tree represents the set of relations requested for expansion. Each
+// relation holds the expansions requested beneath it.
+type Tree map[string]Tree
+
+// Has reports whether the relation was requested.
+func (t Tree) Has(name string) bool {
+	_, exists := t[name]
+	return exists
+}
+
+// Child returns the expansions requested beneath the relation.
+func (t Tree) Child(name string) Tree {
+	return t[name]
+}
+
+// Parse reads a comma separated list of relation paths for the resource and
+// checks them against the schema. Rejected paths are reported with
+// InvalidArgument and relations the caller isn't allowed to see with
+// PermissionDenied.
+func (s Schema) Parse(ctx context.Context, resource string, expand string, maxDepth int, authorize AuthorizeFn) (Tree, error) {
+	expand = strings.TrimSpace(expand)
+	if expand == "" {
+		return nil, nil
+	}
+
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	tree := make(Tree)
+
+	for path := range strings.SplitSeq(expand, ",") {
+		path = strings.TrimSpace(path)
+
+		names := strings.Split(path, ".")
+		if len(names) > maxDepth {
+			return nil, errs.Newf(errs.InvalidArgument, "expand: %q is nested more than %d levels", path, maxDepth)
+		}
+
+		node := tree
+		for _, name := range names {
+			if name == "" {
+				return nil, errs.Newf(errs.InvalidArgument, "expand: invalid path %q", path)
+			}
+
+			child, exists := node[name]
+			if !exists {
+				child = make(Tree)
+				node[name] = child
+			}
+			node = child
+		}
+	}
+
+	visited := map[string]bool{resource: true}
+	if err := s.check(ctx, resource, tree, "", visited, authorize); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// check walks the tree validating every relation. A resource can only
+// appear once in a path so user.groups.members can't loop back on itself.
+func (s Schema) check(ctx context.Context, resource string, tree Tree, prefix string, visited map[string]bool, authorize AuthorizeFn) error {
+	for _, name := range slices.Sorted(maps.Keys(tree)) {
+		path := prefix + name
+
+		rel, exists := s[resource][name]
+		if !exists {
+			return errs.Newf(errs.InvalidArgument, "expand: %q can't be expanded", path)
+		}
+
+		if visited[rel.Resource] {
+			return errs.Newf(errs.InvalidArgument, "expand: %q leads back to %s", path, rel.Resource)
+		}
+
+		if rel.Rule != "" {
+			if err := authorize(ctx, rel.Rule); err != nil {
+				return errs.Newf(errs.PermissionDenied, "expand: %q: %s", path, err)
+			}
+		}
+
+		visited[rel.Resource] = true
+		err := s.check(ctx, rel.Resource, tree[name], path+".", visited, authorize)
+		delete(visited, rel.Resource)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
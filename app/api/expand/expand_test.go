@@ -0,0 +1,102 @@
+package expand_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/expand"
+)
+
+var schema = expand.Schema{
+	"order": {
+		"user":  {Resource: "user"},
+		"items": {Resource: "item"},
+	},
+	"item": {
+		"product": {Resource: "product"},
+		"order":   {Resource: "order"},
+	},
+	"user": {
+		"orders":   {Resource: "order"},
+		"sessions": {Resource: "session", Rule: "admin"},
+	},
+}
+
+func authorize(ctx context.Context, rule string) error {
+	if rule == "admin" {
+		return errors.New("not an admin")
+	}
+	return nil
+}
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		expand string
+		code   errs.ErrCode
+	}{
+		{"", errs.OK},
+		{"user,items.product", errs.OK},
+		{"items.prod", errs.InvalidArgument},
+		{"items..product", errs.InvalidArgument},
+		{"items.order", errs.InvalidArgument},
+		{"user.orders", errs.InvalidArgument},
+		{"user.sessions", errs.PermissionDenied},
+		{"items.product.a.b", errs.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expand, func(t *testing.T) {
+			tree, err := schema.Parse(context.Background(), "order", tt.expand, 0, authorize)
+
+			code := errs.OK
+			if err != nil {
+				code = errs.GetError(err).Code
+			}
+
+			if code != tt.code {
+				t.Fatalf("Should get code %s for %q, got %s: %v", tt.code, tt.expand, code, err)
+			}
+
+			if tt.expand == "user,items.product" && (!tree.Has("user") || !tree.Child("items").Has("product")) {
+				t.Errorf("Should get the requested expansions, got %v", tree)
+			}
+		})
+	}
+}
+
+func Test_Loader(t *testing.T) {
+	var calls [][]int
+
+	ldr := expand.NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls = append(calls, keys)
+
+		m := make(map[int]string)
+		for _, k := range keys {
+			if k != 3 {
+				m[k] = "v"
+			}
+		}
+		return m, nil
+	})
+
+	ctx := context.Background()
+
+	if _, err := ldr.LoadMany(ctx, []int{1, 2, 2, 3}); err != nil {
+		t.Fatalf("Should be able to load: %s", err)
+	}
+
+	v, exists, err := ldr.Load(ctx, 3)
+	if err != nil || exists || v != "" {
+		t.Errorf("Should remember a missing key, got %q %t %v", v, exists, err)
+	}
+
+	if _, exists, _ := ldr.Load(ctx, 1); !exists {
+		t.Errorf("Should get a loaded key")
+	}
+
+	if len(calls) != 1 || len(calls[0]) != 3 {
+		t.Errorf("Should fetch the unique keys in one batch, got %v", calls)
+	}
+}
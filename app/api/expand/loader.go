@@ -0,0 +1,89 @@
+package expand
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchFn fetches the values for a set of keys in one call. Keys with no
+// value are left out of the result.
+type BatchFn[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader fetches related resources in batches and remembers them for the
+// life of the loader, so a resource referenced by many items of a response
+// is only fetched once. A Loader is meant to live for a single request.
+type Loader[K comparable, V any] struct {
+	fetch BatchFn[K, V]
+	mu    sync.Mutex
+	cache map[K]V
+	miss  map[K]bool
+}
+
+// NewLoader constructs a loader that uses the batch function to fetch
+// values it hasn't seen.
+func NewLoader[K comparable, V any](fetch BatchFn[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		cache: make(map[K]V),
+		miss:  make(map[K]bool),
+	}
+}
+
+// LoadMany returns the values for the keys, fetching the ones not already
+// loaded in a single batch.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var pending []K
+	seen := make(map[K]bool, len(keys))
+
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if _, exists := l.cache[key]; !exists && !l.miss[key] {
+			pending = append(pending, key)
+		}
+	}
+
+	if len(pending) > 0 {
+		values, err := l.fetch(ctx, pending)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+
+		for _, key := range pending {
+			v, exists := values[key]
+			if !exists {
+				l.miss[key] = true
+				continue
+			}
+			l.cache[key] = v
+		}
+	}
+
+	result := make(map[K]V, len(seen))
+	for key := range seen {
+		if v, exists := l.cache[key]; exists {
+			result[key] = v
+		}
+	}
+
+	return result, nil
+}
+
+// Load returns the value for the key and reports whether it exists.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	values, err := l.LoadMany(ctx, []K{key})
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	v, exists := values[key]
+	return v, exists, nil
+}
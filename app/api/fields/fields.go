@@ -0,0 +1,252 @@
+// Package fields provides support for sparse fieldsets so clients can ask for
+// only the attributes of a response they need, such as ?fields=id,name,meta.created.
+package fields
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+// Mask represents the set of fields to keep in a response. A field with no
+// children keeps its whole value. A nil Mask keeps everything.
+type Mask map[string]Mask
+
+// Parse reads a comma separated list of field paths, where nested fields are
+// joined with a dot.
+func Parse(s string) (Mask, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	mask := make(Mask)
+
+	for path := range strings.SplitSeq(s, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, errs.FieldErrors{{Field: "fields", Reason: "empty field name"}}
+		}
+
+		node := mask
+		names := strings.Split(path, ".")
+
+		for i, name := range names {
+			if name == "" {
+				return nil, errs.FieldErrors{{Field: "fields", Reason: fmt.Sprintf("invalid field %q", path)}}
+			}
+
+			child, exists := node[name]
+			last := i == len(names)-1
+
+			switch {
+			case last:
+				// Asking for a field keeps all of it, even if some of its
+				// children were listed as well.
+				node[name] = nil
+
+			case exists && child == nil:
+				// The whole field was already requested.
+				node = nil
+
+			case !exists:
+				child = make(Mask)
+				node[name] = child
+				node = child
+
+			default:
+				node = child
+			}
+
+			if node == nil {
+				break
+			}
+		}
+	}
+
+	return mask, nil
+}
+
+// Validate checks every field in the mask exists in the response model.
+func (m Mask) Validate(model any) error {
+	var fe errs.FieldErrors
+	validate(m, reflect.TypeOf(model), "", &fe)
+
+	return fe.ToError()
+}
+
+func validate(m Mask, t reflect.Type, prefix string, fe *errs.FieldErrors) {
+	t = elem(t)
+
+	// Maps and interfaces can hold any key, so their contents can't be
+	// checked ahead of time.
+	if t == nil || t.Kind() == reflect.Map || t.Kind() == reflect.Interface {
+		return
+	}
+
+	if t.Kind() != reflect.Struct {
+		for _, name := range slices.Sorted(maps.Keys(m)) {
+			fe.Add("fields", fmt.Sprintf("unknown field %q", prefix+name))
+		}
+		return
+	}
+
+	known := jsonFields(t)
+
+	for _, name := range slices.Sorted(maps.Keys(m)) {
+		child := m[name]
+
+		ft, exists := known[name]
+		if !exists {
+			fe.Add("fields", fmt.Sprintf("unknown field %q", prefix+name))
+			continue
+		}
+
+		if child != nil {
+			validate(child, ft, prefix+name+".", fe)
+		}
+	}
+}
+
+// Apply encodes the value and removes every field not in the mask. The
+// order of the remaining fields is kept.
+func (m Mask) Apply(v any) (jsontext.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	if m == nil {
+		return data, nil
+	}
+
+	var b bytes.Buffer
+	dec := jsontext.NewDecoder(bytes.NewReader(data))
+	enc := jsontext.NewEncoder(&b)
+
+	if err := filter(dec, enc, m); err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+
+	return bytes.TrimSpace(b.Bytes()), nil
+}
+
+func filter(dec *jsontext.Decoder, enc *jsontext.Encoder, m Mask) error {
+	switch dec.PeekKind() {
+	case '{':
+		if err := copyToken(dec, enc); err != nil {
+			return err
+		}
+
+		for dec.PeekKind() != '}' {
+			name, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+
+			child, keep := m[name.String()]
+			if !keep {
+				if err := dec.SkipValue(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := enc.WriteToken(name); err != nil {
+				return err
+			}
+
+			if child == nil {
+				if err := copyValue(dec, enc); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := filter(dec, enc, child); err != nil {
+				return err
+			}
+		}
+
+		return copyToken(dec, enc)
+
+	case '[':
+		if err := copyToken(dec, enc); err != nil {
+			return err
+		}
+
+		// The mask applies to every element of a collection.
+		for dec.PeekKind() != ']' {
+			if err := filter(dec, enc, m); err != nil {
+				return err
+			}
+		}
+
+		return copyToken(dec, enc)
+	}
+
+	return copyValue(dec, enc)
+}
+
+func copyToken(dec *jsontext.Decoder, enc *jsontext.Encoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+
+	return enc.WriteToken(tok)
+}
+
+func copyValue(dec *jsontext.Decoder, enc *jsontext.Encoder) error {
+	val, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+
+	return enc.WriteValue(val)
+}
+
+// elem returns the type held by pointers and collections.
+func elem(t reflect.Type) reflect.Type {
+	for t != nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+
+	return nil
+}
+
+// jsonFields returns the encoded names of the fields of the struct type.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+
+		fields[name] = f.Type
+	}
+
+	return fields
+}
@@ -0,0 +1,86 @@
+package fields_test
+
+import (
+	"testing"
+
+	"github.com/mrcruz117/al-service/app/api/fields"
+)
+
+type meta struct {
+	Created string `json:"created"`
+	Updated string `json:"updated"`
+}
+
+type item struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Meta  meta     `json:"meta"`
+	Tags  []meta   `json:"tags"`
+	Extra any      `json:"extra"`
+	Skip  string   `json:"-"`
+	Refs  []string `json:"refs,omitempty"`
+}
+
+func Test_Apply(t *testing.T) {
+	v := []item{
+		{
+			ID:    "1",
+			Name:  "one",
+			Meta:  meta{Created: "c", Updated: "u"},
+			Tags:  []meta{{Created: "tc", Updated: "tu"}},
+			Extra: map[string]int{"a": 1, "b": 2},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		fields string
+		want   string
+	}{
+		{"none", "", `[{"id":"1","name":"one","meta":{"created":"c","updated":"u"},"tags":[{"created":"tc","updated":"tu"}],"extra":{"a":1,"b":2}}]`},
+		{"top", "name,id", `[{"id":"1","name":"one"}]`},
+		{"nested", "id,meta.updated", `[{"id":"1","meta":{"updated":"u"}}]`},
+		{"collection", "tags.created", `[{"tags":[{"created":"tc"}]}]`},
+		{"whole", "meta.created,meta", `[{"meta":{"created":"c","updated":"u"}}]`},
+		{"dynamic", "extra.b", `[{"extra":{"b":2}}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask, err := fields.Parse(tt.fields)
+			if err != nil {
+				t.Fatalf("Should be able to parse %q: %s", tt.fields, err)
+			}
+
+			if err := mask.Validate(item{}); err != nil {
+				t.Fatalf("Should be able to validate %q: %s", tt.fields, err)
+			}
+
+			got, err := mask.Apply(v)
+			if err != nil {
+				t.Fatalf("Should be able to apply %q: %s", tt.fields, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Should get %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Invalid(t *testing.T) {
+	tests := []string{"id,", "meta..created", "unknown", "Skip", "meta.unknown", "id.value"}
+
+	for _, fs := range tests {
+		t.Run(fs, func(t *testing.T) {
+			mask, err := fields.Parse(fs)
+			if err == nil {
+				err = mask.Validate(item{})
+			}
+
+			if err == nil {
+				t.Errorf("Should not accept %q", fs)
+			}
+		})
+	}
+}
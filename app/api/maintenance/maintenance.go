@@ -0,0 +1,65 @@
+// Package maintenance provides support for placing a service into maintenance
+// mode at runtime.
+package maintenance
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Mode maintains the maintenance state for a service. It is safe for
+// concurrent use.
+type Mode struct {
+	enabled    atomic.Bool
+	retryAfter time.Duration
+	exempt     []string
+}
+
+// New constructs a Mode with the initial state. Requests for paths that begin
+// with any of the exempt prefixes are never blocked.
+func New(enabled bool, retryAfter time.Duration, exempt ...string) *Mode {
+	m := Mode{
+		retryAfter: retryAfter,
+		exempt:     append([]string{"/liveness", "/readiness"}, exempt...),
+	}
+	m.enabled.Store(enabled)
+
+	return &m
+}
+
+// Enable places the service into maintenance mode.
+func (m *Mode) Enable() {
+	m.enabled.Store(true)
+}
+
+// Disable takes the service out of maintenance mode.
+func (m *Mode) Disable() {
+	m.enabled.Store(false)
+}
+
+// Enabled reports whether the service is in maintenance mode.
+func (m *Mode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// RetryAfter returns how long clients should wait before trying again.
+func (m *Mode) RetryAfter() time.Duration {
+	return m.retryAfter
+}
+
+// Blocked reports whether a request for the specified path should be
+// rejected because the service is in maintenance mode.
+func (m *Mode) Blocked(path string) bool {
+	if !m.Enabled() {
+		return false
+	}
+
+	for _, prefix := range m.exempt {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
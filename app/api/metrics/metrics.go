@@ -5,6 +5,7 @@ import (
 	"context"
 	"expvar"
 	"runtime"
+	"sync"
 )
 
 // This holds the single instance of the metrics value needed for
@@ -20,6 +21,10 @@ type metrics struct {
 	requests   *expvar.Int
 	errors     *expvar.Int
 	panics     *expvar.Int
+	shed       *expvar.Int
+	logs       *expvar.Map
+	logsMu     sync.Mutex
+	breaker    *expvar.Map
 }
 
 // init constructs the metrics value that will be used to capture metrics.
@@ -32,6 +37,9 @@ func init() {
 		requests:   expvar.NewInt("requests"),
 		errors:     expvar.NewInt("errors"),
 		panics:     expvar.NewInt("panics"),
+		shed:       expvar.NewInt("shed"),
+		logs:       expvar.NewMap("logs"),
+		breaker:    expvar.NewMap("auth_breaker"),
 	}
 }
 
@@ -85,3 +93,66 @@ func AddPanics(ctx context.Context) int64 {
 
 	return 0
 }
+
+// AddShed increments the shed requests metric by 1.
+func AddShed(ctx context.Context) int64 {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		v.shed.Add(1)
+		return v.shed.Value()
+	}
+
+	return 0
+}
+
+// AddLogs increments the number of logs written by the service at the level
+// by 1. Unlike the other metrics it doesn't need a context since most logs
+// are written outside of a request.
+func AddLogs(service string, level string) {
+	levels, ok := m.logs.Get(service).(*expvar.Map)
+	if !ok {
+		levels = m.serviceLogs(service)
+	}
+
+	levels.Add(level, 1)
+}
+
+func (m *metrics) serviceLogs(service string) *expvar.Map {
+	m.logsMu.Lock()
+	defer m.logsMu.Unlock()
+
+	if levels, ok := m.logs.Get(service).(*expvar.Map); ok {
+		return levels
+	}
+
+	levels := new(expvar.Map)
+	m.logs.Set(service, levels)
+
+	return levels
+}
+
+// SetAuthBreakerState records the state of the circuit breaker around the
+// calls to the auth service. Like the logs metric it doesn't need a context
+// since the breaker changes state outside of any one request.
+func SetAuthBreakerState(state string) {
+	var v expvar.String
+	v.Set(state)
+	m.breaker.Set("state", &v)
+}
+
+// AddAuthBreakerOpened increments the number of times the breaker opened
+// by 1.
+func AddAuthBreakerOpened() {
+	m.breaker.Add("opened", 1)
+}
+
+// AddAuthBreakerRejected increments the number of calls the breaker
+// rejected by 1.
+func AddAuthBreakerRejected() {
+	m.breaker.Add("rejected", 1)
+}
+
+// AddAuthFailOpen increments the number of requests let through without
+// being authorized because the auth service was unavailable by 1.
+func AddAuthFailOpen() {
+	m.breaker.Add("fail_open", 1)
+}
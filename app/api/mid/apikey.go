@@ -0,0 +1,54 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// APIKeyIssuer is the issuer set on the synthetic claims of machine clients.
+const APIKeyIssuer = "apikey"
+
+// APIKey validates the api key of a machine client and injects synthetic
+// claims so the authorization middleware works the same as it does for JWTs.
+func APIKey(ctx context.Context, log *logger.Logger, core *apikey.Core, key string, handler Handler) error {
+	if key == "" {
+		return errs.Newf(errs.Unauthenticated, "apikey: missing api key")
+	}
+
+	ak, err := core.Authenticate(ctx, key)
+	if err != nil {
+		if errors.Is(err, apikey.ErrInvalidKey) || errors.Is(err, apikey.ErrDisabled) {
+			return errs.New(errs.Unauthenticated, err)
+		}
+
+		log.Error(ctx, "apikey", "msg", err)
+		return errs.Newf(errs.Internal, "apikey: unable to authenticate")
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  ak.ID.String(),
+			Issuer:   APIKeyIssuer,
+			IssuedAt: jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles:       ak.Roles,
+		Permissions: auth.ScopesFor(ak.Roles),
+	}
+
+	if ak.TenantID != uuid.Nil {
+		claims.Tenant = ak.TenantID.String()
+	}
+
+	ctx = setUserID(ctx, ak.ID)
+	ctx = setClaims(ctx, claims)
+
+	return handler(ctx)
+}
@@ -0,0 +1,32 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/business/core/audit"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Audit records who made the request described by the entry and the status
+// code it resulted in. It must run after authentication for the claims to be
+// known. The status function resolves the code for requests that fail since
+// the error hasn't been responded to yet.
+func Audit(ctx context.Context, core *audit.Core, entry audit.Entry, status func(err error) int, handler Handler) error {
+	err := handler(ctx)
+
+	entry.Subject = GetClaims(ctx).Subject
+	if entry.Subject == "" {
+		entry.Subject = audit.Anonymous
+	}
+
+	if tenantID, terr := GetTenantID(ctx); terr == nil {
+		entry.TenantID = tenantID
+	}
+
+	entry.TraceID = web.GetTraceID(ctx)
+	entry.StatusCode = status(err)
+
+	core.Record(entry)
+
+	return err
+}
@@ -3,7 +3,9 @@ package mid
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/mail"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
 	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/user"
 	"github.com/mrcruz117/al-service/foundation/logger"
 )
 
@@ -19,7 +22,7 @@ import (
 func Authenticate(ctx context.Context, log *logger.Logger, client *authclient.Client, authorization string, handler Handler) error {
 	resp, err := client.Authenticate(ctx, authorization)
 	if err != nil {
-		return errs.New(errs.Unauthenticated, err)
+		return authError(err)
 	}
 
 	ctx = setUserID(ctx, resp.UserID)
@@ -50,24 +53,42 @@ func Bearer(ctx context.Context, ath *auth.Auth, authorization string, handler H
 	return handler(ctx)
 }
 
-// Basic processes basic authentication logic.
-func Basic(ctx context.Context, handler Handler) error {
-	claims := auth.Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   "38dc9d84-018b-4a15-b958-0b78af11c301",
-			Issuer:    "service project",
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		},
-		Roles: []string{"ADMIN"},
+// Basic processes basic authentication logic. The claims are valid for the
+// ttl, which is how long a token issued from them is valid.
+func Basic(ctx context.Context, ath *auth.Auth, userCore *user.Core, ttl time.Duration, authorization string, handler Handler) error {
+	email, pass, ok := parseBasicAuth(authorization)
+	if !ok {
+		return errs.Newf(errs.Unauthenticated, "invalid Basic auth")
 	}
 
-	subjectID, err := uuid.Parse(claims.Subject)
+	addr, err := mail.ParseAddress(email)
 	if err != nil {
-		return errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
+		return errs.New(errs.Unauthenticated, err)
 	}
 
-	ctx = setUserID(ctx, subjectID)
+	usr, err := userCore.Authenticate(ctx, *addr, pass)
+	if err != nil {
+		if errors.Is(err, user.ErrAuthenticationFailure) || errors.Is(err, user.ErrUserDisabled) {
+			return errs.New(errs.Unauthenticated, err)
+		}
+		return errs.Newf(errs.Internal, "authenticate: %s", err)
+	}
+
+	now := time.Now().UTC()
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   usr.ID.String(),
+			Issuer:    ath.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Roles:       usr.Roles,
+		Permissions: auth.ScopesFor(usr.Roles),
+	}
+	ath.MapClaims(&claims, usr.Attributes())
+
+	ctx = setUserID(ctx, usr.ID)
 	ctx = setClaims(ctx, claims)
 
 	return handler(ctx)
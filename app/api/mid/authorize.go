@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/auth"
 	"github.com/mrcruz117/al-service/app/api/authclient"
 	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/metrics"
 	"github.com/mrcruz117/al-service/foundation/logger"
 )
 
@@ -26,8 +29,100 @@ func Authorize(ctx context.Context, log *logger.Logger, client *authclient.Clien
 	}
 
 	if err := client.Authorize(ctx, auth); err != nil {
+		return authError(err)
+	}
+
+	return handler(ctx)
+}
+
+// AuthorizeFailOpen executes the specified rule like Authorize, but lets the
+// request through when the auth service is unavailable. It's only meant for
+// low risk routes, where serving a request that should have been denied is
+// better than failing it. The caller must still have been authenticated.
+func AuthorizeFailOpen(ctx context.Context, log *logger.Logger, client *authclient.Client, rule string, handler Handler) error {
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	auth := authclient.Authorize{
+		Claims: GetClaims(ctx),
+		UserID: userID,
+		Rule:   rule,
+	}
+
+	if err := client.Authorize(ctx, auth); err != nil {
+		if !errors.Is(err, authclient.ErrUnavailable) {
+			return authError(err)
+		}
+
+		log.Warn(ctx, "authorize: failing open", "rule", rule, "userID", userID, "msg", err)
+		metrics.AddAuthFailOpen()
+	}
+
+	return handler(ctx)
+}
+
+// AuthorizeLocal executes the specified rule in process. It's used by the
+// auth service itself, which owns the policies and shouldn't call itself.
+func AuthorizeLocal(ctx context.Context, ath *auth.Auth, rule string, handler Handler) error {
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := ath.Authorize(ctx, GetClaims(ctx), userID, rule); err != nil {
 		return errs.New(errs.Unauthenticated, err)
 	}
 
 	return handler(ctx)
 }
+
+// AuthorizeSubject executes the specified rule against the user the request
+// is about, such as the user id taken from the path, rather than the user
+// making the request. This is what lets RuleAdminOrSubject tell an admin or
+// the owner of the resource from everyone else.
+func AuthorizeSubject(ctx context.Context, log *logger.Logger, client *authclient.Client, id string, rule string, handler Handler) error {
+	subjectID, err := uuid.Parse(id)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, ErrInvalidID)
+	}
+
+	auth := authclient.Authorize{
+		Claims: GetClaims(ctx),
+		UserID: subjectID,
+		Rule:   rule,
+	}
+
+	if err := client.Authorize(ctx, auth); err != nil {
+		return authError(err)
+	}
+
+	return handler(ctx)
+}
+
+// AuthorizeSubjectLocal executes the specified rule against the user the
+// request is about in process.
+func AuthorizeSubjectLocal(ctx context.Context, ath *auth.Auth, id string, rule string, handler Handler) error {
+	subjectID, err := uuid.Parse(id)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, ErrInvalidID)
+	}
+
+	if err := ath.Authorize(ctx, GetClaims(ctx), subjectID, rule); err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	return handler(ctx)
+}
+
+// authError reports a call to the auth service that failed. The request is
+// denied either way, but an auth service that couldn't give an answer isn't
+// reported as the caller not being allowed.
+func authError(err error) error {
+	if errors.Is(err, authclient.ErrUnavailable) {
+		return errs.New(errs.Unavailable, err)
+	}
+
+	return errs.New(errs.Unauthenticated, err)
+}
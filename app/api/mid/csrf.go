@@ -0,0 +1,41 @@
+package mid
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+// NewCSRFToken generates a random token for use in csrf protection.
+func NewCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating csrf token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CSRF implements the double submit pattern. For any method that can change
+// state, the token submitted with the request must match the token issued to
+// the client. The issued token is made available to the handler for
+// rendering into forms.
+func CSRF(ctx context.Context, method string, issued string, submitted string, handler Handler) error {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+
+	default:
+		if issued == "" || subtle.ConstantTimeCompare([]byte(issued), []byte(submitted)) != 1 {
+			return errs.Newf(errs.PermissionDenied, "csrf: token missing or invalid")
+		}
+	}
+
+	ctx = setCSRFToken(ctx, issued)
+
+	return handler(ctx)
+}
@@ -16,11 +16,11 @@ func Errors(ctx context.Context, log *logger.Logger, handler Handler) error {
 		return nil
 	}
 
-	log.Error(ctx, "message", "ERROR", err.Error())
+	log.Error(ctx, "message", "ERROR", err)
 
 	if errs.IsError(err) {
 		return errs.GetError(err)
 	}
 
-	return errs.Newf(errs.Unknown, errs.Unknown.String())
+	return errs.Newf(errs.Unknown, "%s", errs.Unknown.String())
 }
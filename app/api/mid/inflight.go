@@ -0,0 +1,23 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/metrics"
+)
+
+// MaxInflight limits the number of handlers running concurrently to the
+// capacity of the semaphore. Requests beyond that are shed immediately.
+func MaxInflight(ctx context.Context, sem chan struct{}, handler Handler) error {
+	select {
+	case sem <- struct{}{}:
+	default:
+		metrics.AddShed(ctx)
+		return errs.Newf(errs.Unavailable, "server is at capacity, try again later")
+	}
+
+	defer func() { <-sem }()
+
+	return handler(ctx)
+}
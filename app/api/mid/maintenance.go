@@ -0,0 +1,18 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/maintenance"
+)
+
+// Maintenance rejects the request when the service is in maintenance mode
+// and the path is not exempt.
+func Maintenance(ctx context.Context, mode *maintenance.Mode, path string, handler Handler) error {
+	if mode.Blocked(path) {
+		return errs.Newf(errs.Unavailable, "service is in maintenance mode")
+	}
+
+	return handler(ctx)
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/business/core/tenant"
 )
 
 // Handler represents the handler function that needs to be called.
@@ -17,9 +19,16 @@ type ctxKey int
 const (
 	claimKey ctxKey = iota + 1
 	userIDKey
+	csrfKey
+	partnerKey
+	peerKey
+	serviceKey
 )
 
+// setClaims also makes the subject the actor of the changes recorded in the
+// entity audit trail.
 func setClaims(ctx context.Context, claims auth.Claims) context.Context {
+	ctx = entityaudit.WithActor(ctx, claims.Subject)
 	return context.WithValue(ctx, claimKey, claims)
 }
 
@@ -45,3 +54,75 @@ func GetUserID(ctx context.Context) (uuid.UUID, error) {
 
 	return v, nil
 }
+
+func setCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfKey, token)
+}
+
+// GetCSRFToken returns the csrf token from the context.
+func GetCSRFToken(ctx context.Context) string {
+	v, ok := ctx.Value(csrfKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return v
+}
+
+func setPartner(ctx context.Context, partner string) context.Context {
+	return context.WithValue(ctx, partnerKey, partner)
+}
+
+// GetPartner returns the partner that signed the request from the context.
+func GetPartner(ctx context.Context) string {
+	v, ok := ctx.Value(partnerKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return v
+}
+
+func setPeer(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, peerKey, identity)
+}
+
+// GetPeer returns the identity of the service that authenticated with a
+// client certificate from the context.
+func GetPeer(ctx context.Context) string {
+	v, ok := ctx.Value(peerKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return v
+}
+
+func setService(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, serviceKey, service)
+}
+
+// GetService returns the internal service that signed the request from the
+// context.
+func GetService(ctx context.Context) string {
+	v, ok := ctx.Value(serviceKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return v
+}
+
+func setTenantID(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return tenant.WithID(ctx, tenantID)
+}
+
+// GetTenantID returns the tenant the request is scoped to from the context.
+func GetTenantID(ctx context.Context) (uuid.UUID, error) {
+	v, ok := tenant.IDFromContext(ctx)
+	if !ok {
+		return uuid.UUID{}, errors.New("tenant id not found in context")
+	}
+
+	return v, nil
+}
@@ -0,0 +1,20 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/app/api/ratelimit"
+)
+
+// RateLimit rejects the request once the key has used up its allowance. The
+// request is let through when the limiter can't be reached so an outage of
+// the shared store doesn't take the api down with it.
+func RateLimit(ctx context.Context, limiter ratelimit.Limiter, key string, handler Handler) error {
+	allowed, err := limiter.Allow(ctx, key)
+	if err == nil && !allowed {
+		return errs.Newf(errs.ResourceExhausted, "rate limit exceeded, try again later")
+	}
+
+	return handler(ctx)
+}
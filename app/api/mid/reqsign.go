@@ -0,0 +1,22 @@
+package mid
+
+import (
+	"context"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/foundation/reqsign"
+)
+
+// VerifyServiceSignature validates the request was signed by one of the
+// internal services holding a secret, within the allowed clock skew.
+func VerifyServiceSignature(ctx context.Context, secrets map[string][]byte, maxSkew time.Duration, msg reqsign.Message, handler Handler) error {
+	service, err := reqsign.Verify(secrets, msg, time.Now(), maxSkew)
+	if err != nil {
+		return errs.Newf(errs.Unauthenticated, "service signature: %s", err)
+	}
+
+	ctx = setService(ctx, service)
+
+	return handler(ctx)
+}
@@ -0,0 +1,17 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+// RequireScope rejects the request unless the claims of the caller grant the
+// specified scope. It must run after one of the authentication middleware.
+func RequireScope(ctx context.Context, scope string, handler Handler) error {
+	if !GetClaims(ctx).HasScope(scope) {
+		return errs.Newf(errs.PermissionDenied, "scope %q is required", scope)
+	}
+
+	return handler(ctx)
+}
@@ -0,0 +1,107 @@
+package mid
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+// SignaturePrefix identifies the algorithm used to produce a signature.
+const SignaturePrefix = "sha256="
+
+// Sign produces the signature for the body sent at the unix timestamp using
+// the secret in the same form VerifySignature expects it.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Replays remembers the signatures accepted within the max age, so a
+// request captured and sent again while its timestamp is still recent is
+// refused. A signature is forgotten once its timestamp is too old to be
+// accepted anyway. Each instance remembers only what it accepted itself.
+type Replays struct {
+	maxAge time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewReplays constructs a Replays accepting timestamps up to maxAge away
+// from now.
+func NewReplays(maxAge time.Duration) *Replays {
+	return &Replays{
+		maxAge: maxAge,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// add records the signature made at the time, returning false when it was
+// already accepted.
+func (r *Replays) add(signature string, signed time.Time, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for sig, at := range r.seen {
+		if now.Sub(at) > r.maxAge {
+			delete(r.seen, sig)
+		}
+	}
+
+	if _, exists := r.seen[signature]; exists {
+		return false
+	}
+
+	r.seen[signature] = signed
+
+	return true
+}
+
+// VerifySignature validates the body was signed with the secret shared with
+// the partner at the timestamp, within the max age of the replays and only
+// once. The signature is the hex encoded HMAC-SHA256 of the timestamp and
+// the body joined by a dot.
+func VerifySignature(ctx context.Context, secrets map[string][]byte, replays *Replays, partner string, timestamp string, signature string, body []byte, handler Handler) error {
+	secret, exists := secrets[partner]
+	if !exists {
+		return errs.Newf(errs.Unauthenticated, "signature: unknown partner %q", partner)
+	}
+
+	if !strings.HasPrefix(signature, SignaturePrefix) {
+		return errs.Newf(errs.Unauthenticated, "signature: expected format %s<hex>", SignaturePrefix)
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errs.Newf(errs.Unauthenticated, "signature: timestamp must be in unix seconds")
+	}
+
+	now := time.Now()
+	signed := time.Unix(ts, 0)
+
+	if now.Sub(signed).Abs() > replays.maxAge {
+		return errs.Newf(errs.Unauthenticated, "signature: timestamp is outside the allowed %s", replays.maxAge)
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return errs.Newf(errs.Unauthenticated, "signature: invalid signature")
+	}
+
+	if !replays.add(partner+" "+signature, signed, now) {
+		return errs.Newf(errs.Unauthenticated, "signature: request was already received")
+	}
+
+	ctx = setPartner(ctx, partner)
+
+	return handler(ctx)
+}
@@ -0,0 +1,17 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+)
+
+// Paused rejects the request while the subsystem that serves it is paused.
+func Paused(ctx context.Context, sw *subsystem.Switch, handler Handler) error {
+	if sw.Paused() {
+		return errs.Newf(errs.Unavailable, "%s is paused", sw.Name())
+	}
+
+	return handler(ctx)
+}
@@ -0,0 +1,103 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/app/api/errs"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+)
+
+// Tenant resolves the tenant of the request and scopes the context to it so
+// the stores only see that tenant's data. An authenticated request is
+// scoped to the tenant of its claims and nothing else, a header naming
+// another tenant is rejected rather than trusted. The header, followed by
+// the subdomain of the host, only resolves the tenant of a request that
+// isn't authenticated. An admin whose claims name no tenant isn't scoped to
+// one, while any other caller without a tenant is denied access to the data
+// of every tenant rather than given access to all of it.
+func Tenant(ctx context.Context, core *tenant.Core, header string, host string, handler Handler) error {
+	var ref string
+
+	switch claims := GetClaims(ctx); {
+	case claims.Subject != "":
+		if header != "" && !sameTenant(ctx, core, header, claims.Tenant) {
+			return errs.Newf(errs.PermissionDenied, "tenant: request is not allowed for tenant %q", header)
+		}
+
+		if claims.Tenant == "" {
+			if claims.HasRole(auth.RoleAdmin) {
+				return handler(ctx)
+			}
+
+			return handler(tenant.Deny(ctx))
+		}
+
+		ref = claims.Tenant
+
+	case header != "":
+		ref = header
+
+	default:
+		ref = subdomain(host)
+	}
+
+	if ref == "" {
+		return errs.Newf(errs.InvalidArgument, "tenant: unable to resolve the tenant of the request")
+	}
+
+	tnt, err := core.Resolve(ctx, ref)
+	if err != nil {
+		switch {
+		case errors.Is(err, tenant.ErrNotFound):
+			return errs.Newf(errs.NotFound, "tenant: %q does not exist", ref)
+		case errors.Is(err, tenant.ErrDisabled):
+			return errs.Newf(errs.PermissionDenied, "tenant: %q is disabled", ref)
+		}
+		return errs.Newf(errs.Internal, "tenant: %s", err)
+	}
+
+	ctx = setTenantID(ctx, tnt.ID)
+
+	return handler(ctx)
+}
+
+// sameTenant reports whether the tenant named by the reference, its id or
+// its slug, is the one with the claimed id.
+func sameTenant(ctx context.Context, core *tenant.Core, ref string, claimed string) bool {
+	if claimed == "" {
+		return false
+	}
+
+	if ref == claimed {
+		return true
+	}
+
+	tnt, err := core.Resolve(ctx, ref)
+	if err != nil {
+		return false
+	}
+
+	return tnt.ID.String() == claimed
+}
+
+// subdomain returns the left most label of a host with at least three labels.
+func subdomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 3 || labels[0] == "www" {
+		return ""
+	}
+
+	return labels[0]
+}
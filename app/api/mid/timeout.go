@@ -0,0 +1,37 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/adaptive"
+	"github.com/mrcruz117/al-service/app/api/errs"
+)
+
+// Timeout bounds the time the handler has to complete. Handlers observe the
+// deadline through the context they are given.
+func Timeout(ctx context.Context, timeout time.Duration, handler Handler) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := handler(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && !errs.IsError(err) {
+		return errs.Newf(errs.DeadlineExceeded, "request timed out")
+	}
+
+	return err
+}
+
+// AdaptiveTimeout bounds the time the handler has to complete using the
+// timeout the tuner derived for the route, never exceeding max. The time the
+// handler took is fed back into the tuner.
+func AdaptiveTimeout(ctx context.Context, tuner *adaptive.Tuner, route string, max time.Duration, handler Handler) error {
+	start := time.Now()
+
+	err := Timeout(ctx, tuner.Timeout(route, max), handler)
+
+	tuner.Observe(route, time.Since(start))
+
+	return err
+}
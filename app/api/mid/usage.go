@@ -0,0 +1,17 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/business/core/usage"
+)
+
+// Usage records the request against the subject of the claims for usage
+// accounting. It must run after authentication for the claims to be known.
+func Usage(ctx context.Context, core *usage.Core, route string, bytes func() int64, handler Handler) error {
+	err := handler(ctx)
+
+	core.Record(GetClaims(ctx).Subject, route, bytes(), err != nil)
+
+	return err
+}
@@ -0,0 +1,97 @@
+// Package posture provides support for collecting a snapshot of the
+// operational state of a service, such as which features are enabled and
+// what was changed at runtime, so on-call engineers can see it in one place.
+package posture
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ProbeFn returns the current state of one part of the service.
+type ProbeFn func(ctx context.Context) any
+
+// Change represents a setting that was changed while the service is running.
+type Change struct {
+	Time    time.Time
+	Setting string
+	From    string
+	To      string
+	Subject string
+}
+
+// Snapshot represents the operational state of the service at a point in
+// time.
+type Snapshot struct {
+	Time     time.Time
+	Sections map[string]any
+	Changes  []Change
+}
+
+// Posture maintains the registered probes and the most recent changes.
+type Posture struct {
+	mu         sync.Mutex
+	probes     map[string]ProbeFn
+	changes    []Change
+	maxChanges int
+}
+
+// New constructs a Posture that remembers up to maxChanges changes.
+func New(maxChanges int) *Posture {
+	return &Posture{
+		probes:     make(map[string]ProbeFn),
+		maxChanges: maxChanges,
+	}
+}
+
+// Register adds a probe reported under the specified section name.
+func (p *Posture) Register(name string, fn ProbeFn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.probes[name] = fn
+}
+
+// RecordChange remembers a runtime change to a setting, dropping the oldest
+// change once the limit is reached.
+func (p *Posture) RecordChange(setting string, from string, to string, subject string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.changes = append(p.changes, Change{
+		Time:    time.Now().UTC(),
+		Setting: setting,
+		From:    from,
+		To:      to,
+		Subject: subject,
+	})
+
+	if n := len(p.changes) - p.maxChanges; n > 0 {
+		p.changes = slices.Delete(p.changes, 0, n)
+	}
+}
+
+// Snapshot executes every probe and returns their results together with the
+// recent changes, newest first.
+func (p *Posture) Snapshot(ctx context.Context) Snapshot {
+	p.mu.Lock()
+	probes := maps.Clone(p.probes)
+	changes := slices.Clone(p.changes)
+	p.mu.Unlock()
+
+	slices.Reverse(changes)
+
+	sections := make(map[string]any, len(probes))
+	for name, fn := range probes {
+		sections[name] = fn(ctx)
+	}
+
+	return Snapshot{
+		Time:     time.Now().UTC(),
+		Sections: sections,
+		Changes:  changes,
+	}
+}
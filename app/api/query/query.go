@@ -0,0 +1,24 @@
+// Package query provides support for the responses of list requests.
+package query
+
+import "github.com/mrcruz117/al-service/business/api/page"
+
+// Result is the envelope every list handler returns a page of items in.
+// Total is the number of items across every page, so a client can work out
+// how many pages there are from it and the rows per page.
+type Result[T any] struct {
+	Items       []T `json:"items"`
+	Total       int `json:"total"`
+	Page        int `json:"page"`
+	RowsPerPage int `json:"rowsPerPage"`
+}
+
+// NewResult constructs the envelope for the page of items.
+func NewResult[T any](items []T, total int, pg page.Page) Result[T] {
+	return Result[T]{
+		Items:       items,
+		Total:       total,
+		Page:        pg.Number(),
+		RowsPerPage: pg.RowsPerPage(),
+	}
+}
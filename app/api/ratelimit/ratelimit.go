@@ -0,0 +1,106 @@
+// Package ratelimit provides support for limiting how many requests a client
+// can make within a fixed window.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/cachestore"
+)
+
+// Limiter decides whether a request for the key is allowed.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+}
+
+// =============================================================================
+
+type bucket struct {
+	start time.Time
+	count int
+}
+
+// Local counts requests in memory. Each instance of the service enforces the
+// limit on its own.
+type Local struct {
+	requests int
+	window   time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*bucket
+	swept   time.Time
+}
+
+// NewLocal constructs a limiter allowing the number of requests per window
+// for each key.
+func NewLocal(requests int, window time.Duration) *Local {
+	return &Local{
+		requests: requests,
+		window:   window,
+		windows:  make(map[string]*bucket),
+		swept:    time.Now(),
+	}
+}
+
+// Allow implements the Limiter interface.
+func (l *Local) Allow(ctx context.Context, key string) (bool, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop the windows that have expired so idle keys don't accumulate.
+	if now.Sub(l.swept) > l.window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) > l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.swept = now
+	}
+
+	w, exists := l.windows[key]
+	if !exists || now.Sub(w.start) > l.window {
+		w = &bucket{start: now}
+		l.windows[key] = w
+	}
+
+	w.count++
+
+	return w.count <= l.requests, nil
+}
+
+// =============================================================================
+
+// Shared counts requests in the cache store so the limit holds across every
+// instance of the service.
+type Shared struct {
+	store    *cachestore.Store
+	prefix   string
+	requests int
+	window   time.Duration
+}
+
+// NewShared constructs a limiter allowing the number of requests per window
+// for each key. Keys are namespaced with the prefix.
+func NewShared(store *cachestore.Store, prefix string, requests int, window time.Duration) *Shared {
+	return &Shared{
+		store:    store,
+		prefix:   prefix,
+		requests: requests,
+		window:   window,
+	}
+}
+
+// Allow implements the Limiter interface.
+func (s *Shared) Allow(ctx context.Context, key string) (bool, error) {
+	n, err := s.store.IncrWindow(ctx, s.prefix+key, s.window)
+	if err != nil {
+		return false, fmt.Errorf("incrwindow: %w", err)
+	}
+
+	return n <= int64(s.requests), nil
+}
@@ -0,0 +1,229 @@
+// Package session provides support for opaque session tokens stored in the
+// cache store. Unlike a JWT a session can be revoked before it expires, and
+// because the store is shared, a revoked session is rejected by every
+// instance of the service at once.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mrcruz117/al-service/app/api/auth"
+	"github.com/mrcruz117/al-service/foundation/cachestore"
+)
+
+// ErrNotFound is returned when a session doesn't exist, has expired or was
+// revoked.
+var ErrNotFound = errors.New("session not found")
+
+// Config is the required properties to use the store.
+type Config struct {
+	Cache *cachestore.Store
+
+	// Idle is how long a session lives without being used. Every use
+	// extends it by as much again, up to MaxAge after it was created.
+	Idle   time.Duration
+	MaxAge time.Duration
+}
+
+// Session represents a newly created session.
+type Session struct {
+	ID        string
+	Token     string
+	Client    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// record is what is stored for a session.
+type record struct {
+	Claims    auth.Claims `json:"claims"`
+	Client    string      `json:"client"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Store manages the sessions in the cache store.
+type Store struct {
+	cache  *cachestore.Store
+	idle   time.Duration
+	maxAge time.Duration
+}
+
+// New constructs a session store.
+func New(cfg Config) *Store {
+	return &Store{
+		cache:  cfg.Cache,
+		idle:   cfg.Idle,
+		maxAge: cfg.MaxAge,
+	}
+}
+
+// Create starts a session for the claims issued to a client of the type.
+func (s *Store) Create(ctx context.Context, claims auth.Claims, client string) (Session, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return Session{}, fmt.Errorf("generating token: %w", err)
+	}
+
+	token := auth.SessionPrefix + base64.RawURLEncoding.EncodeToString(b)
+	id := sessionID(token)
+	now := time.Now().UTC()
+
+	rec := record{
+		Claims:    claims,
+		Client:    client,
+		CreatedAt: now,
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return Session{}, fmt.Errorf("encoding session: %w", err)
+	}
+
+	ttl := min(s.idle, s.maxAge)
+
+	if err := s.cache.Set(ctx, sessionKey(id), data, ttl); err != nil {
+		return Session{}, fmt.Errorf("storing session: %w", err)
+	}
+
+	// The sessions of a user are indexed so they can all be revoked when
+	// the user signs out everywhere or is disabled. The index outlives
+	// every session it holds.
+	user := userKey(claims.Subject)
+	cln := s.cache.Client()
+	if err := cln.SAdd(ctx, user, id).Err(); err != nil {
+		return Session{}, fmt.Errorf("indexing session: %w", err)
+	}
+	if err := cln.Expire(ctx, user, s.maxAge).Err(); err != nil {
+		return Session{}, fmt.Errorf("indexing session: %w", err)
+	}
+
+	ses := Session{
+		ID:        id,
+		Token:     token,
+		Client:    client,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	return ses, nil
+}
+
+// Lookup implements the auth.Sessions interface. A session that is found is
+// extended by the idle time.
+func (s *Store) Lookup(ctx context.Context, token string) (auth.Claims, error) {
+	key := sessionKey(sessionID(token))
+
+	rec, err := s.record(ctx, key)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	remaining := time.Until(rec.CreatedAt.Add(s.maxAge))
+	if remaining <= 0 {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			return auth.Claims{}, fmt.Errorf("expiring session: %w", err)
+		}
+		return auth.Claims{}, ErrNotFound
+	}
+
+	ttl := min(s.idle, remaining)
+
+	if err := s.cache.Expire(ctx, key, ttl); err != nil {
+		if errors.Is(err, cachestore.ErrNotFound) {
+			return auth.Claims{}, ErrNotFound
+		}
+		return auth.Claims{}, fmt.Errorf("extending session: %w", err)
+	}
+
+	return rec.Claims, nil
+}
+
+// Revoke ends the session of the token.
+func (s *Store) Revoke(ctx context.Context, token string) error {
+	id := sessionID(token)
+	key := sessionKey(id)
+
+	rec, err := s.record(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+
+	if err := s.cache.Client().SRem(ctx, userKey(rec.Claims.Subject), id).Err(); err != nil {
+		return fmt.Errorf("unindexing session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser ends every session of the user and returns how many there
+// were.
+func (s *Store) RevokeUser(ctx context.Context, subject string) (int, error) {
+	user := userKey(subject)
+
+	ids, err := s.cache.Client().SMembers(ctx, user).Result()
+	if err != nil {
+		return 0, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	keys := make([]string, 0, len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, sessionKey(id))
+	}
+	keys = append(keys, user)
+
+	n, err := s.cache.Client().Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("deleting sessions: %w", err)
+	}
+
+	// The index itself was one of the keys deleted when it existed.
+	if len(ids) > 0 {
+		n--
+	}
+
+	return int(n), nil
+}
+
+func (s *Store) record(ctx context.Context, key string) (record, error) {
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, cachestore.ErrNotFound) {
+			return record{}, ErrNotFound
+		}
+		return record{}, fmt.Errorf("reading session: %w", err)
+	}
+
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return record{}, fmt.Errorf("decoding session: %w", err)
+	}
+
+	return rec, nil
+}
+
+// sessionID identifies a session by the hash of its token, so the tokens
+// themselves are never stored.
+func sessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sessionKey(id string) string {
+	return "session:" + id
+}
+
+func userKey(subject string) string {
+	return "session:user:" + subject
+}
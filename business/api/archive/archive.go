@@ -0,0 +1,305 @@
+// Package archive provides support for moving historical rows out of hot
+// tables into monthly partitioned archive tables. A read-through view unions
+// the hot and archived rows for the occasional historical query. Archiving
+// relies on declarative partitioning and is only supported on Postgres.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+)
+
+// Table describes a hot table that should be archived.
+type Table struct {
+
+	// Name of the hot table, e.g. orders.
+	Name string
+
+	// TimeColumn is the timestamp column used to determine the age of a row
+	// and to partition the archive.
+	TimeColumn string
+
+	// MaxAge is the age after which a row is moved to the archive.
+	MaxAge time.Duration
+
+	// Children are the tables whose rows belong to a row of the table and
+	// are deleted with it by a cascading foreign key. They are moved along
+	// with the row.
+	Children []Child
+}
+
+// Child describes a table whose rows refer to a row of the archived table by
+// Key, a column both tables have. Its archive isn't partitioned since it has
+// no time of its own.
+type Child struct {
+	Name string
+	Key  string
+}
+
+// ArchiveName returns the name of the archive table.
+func (c Child) ArchiveName() string {
+	return c.Name + "_archive"
+}
+
+// HistoryName returns the name of the read-through view combining the hot
+// and archived rows.
+func (c Child) HistoryName() string {
+	return c.Name + "_history"
+}
+
+// ArchiveName returns the name of the partitioned archive table.
+func (t Table) ArchiveName() string {
+	return t.Name + "_archive"
+}
+
+// HistoryName returns the name of the read-through view combining the hot
+// and archived rows.
+func (t Table) HistoryName() string {
+	return t.Name + "_history"
+}
+
+var validIdent = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+func (t Table) validate() error {
+	if !validIdent.MatchString(t.Name) {
+		return fmt.Errorf("invalid table name %q", t.Name)
+	}
+
+	if !validIdent.MatchString(t.TimeColumn) {
+		return fmt.Errorf("invalid time column %q", t.TimeColumn)
+	}
+
+	if t.MaxAge <= 0 {
+		return fmt.Errorf("max age must be positive for table %q", t.Name)
+	}
+
+	for _, c := range t.Children {
+		if !validIdent.MatchString(c.Name) {
+			return fmt.Errorf("invalid child table name %q", c.Name)
+		}
+
+		if !validIdent.MatchString(c.Key) {
+			return fmt.Errorf("invalid key %q of child table %q", c.Key, c.Name)
+		}
+	}
+
+	return nil
+}
+
+// Archiver moves rows from the registered tables into their archives.
+type Archiver struct {
+	log       *logger.Logger
+	db        *sqlx.DB
+	batchSize int
+	tables    []Table
+}
+
+// New constructs an Archiver that moves at most batchSize rows per statement
+// to keep locks and transactions short.
+func New(log *logger.Logger, db *sqlx.DB, batchSize int, tables ...Table) (*Archiver, error) {
+	if d := sqldb.DialectOf(db); d != sqldb.Postgres {
+		return nil, fmt.Errorf("archiving is not supported on %s", d)
+	}
+
+	for _, t := range tables {
+		if err := t.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	a := Archiver{
+		log:       log,
+		db:        db,
+		batchSize: batchSize,
+		tables:    tables,
+	}
+
+	return &a, nil
+}
+
+// Run archives the registered tables at the specified interval until the
+// context is canceled. While the switch is paused nothing is moved.
+func (a *Archiver) Run(ctx context.Context, interval time.Duration, sw *subsystem.Switch) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+
+		if sw.Paused() {
+			continue
+		}
+
+		if _, err := a.Archive(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			a.log.Error(ctx, "archive", "status", "archive failed", "msg", err)
+		}
+	}
+}
+
+// Archive moves every row older than the table's max age into the archive
+// and returns the number of rows moved per table.
+func (a *Archiver) Archive(ctx context.Context) (map[string]int64, error) {
+	moved := make(map[string]int64, len(a.tables))
+
+	for _, t := range a.tables {
+		n, err := a.archiveTable(ctx, t, time.Now().UTC().Add(-t.MaxAge))
+		moved[t.Name] = n
+		if err != nil {
+			return moved, fmt.Errorf("table[%s]: %w", t.Name, err)
+		}
+
+		if n > 0 {
+			a.log.Info(ctx, "archive", "status", "rows archived", "table", t.Name, "rows", n)
+		}
+	}
+
+	return moved, nil
+}
+
+func (a *Archiver) archiveTable(ctx context.Context, t Table, cutoff time.Time) (int64, error) {
+	if err := a.ensureArchive(ctx, t); err != nil {
+		return 0, err
+	}
+
+	var oldest *time.Time
+	q := fmt.Sprintf(`SELECT MIN(%s) FROM %s WHERE %s < $1`, t.TimeColumn, t.Name, t.TimeColumn)
+	if err := a.db.QueryRowContext(ctx, q, cutoff).Scan(&oldest); err != nil {
+		return 0, fmt.Errorf("oldest row: %w", err)
+	}
+
+	if oldest == nil {
+		return 0, nil
+	}
+
+	var total int64
+
+	// Walk the months between the oldest row and the cutoff so every batch
+	// lands in a single partition.
+	for month := monthStart(*oldest); month.Before(cutoff); month = month.AddDate(0, 1, 0) {
+		if err := a.ensurePartition(ctx, t, month); err != nil {
+			return total, err
+		}
+
+		end := month.AddDate(0, 1, 0)
+		if end.After(cutoff) {
+			end = cutoff
+		}
+
+		for {
+			n, err := a.moveBatch(ctx, t, month, end)
+			total += n
+			if err != nil {
+				return total, err
+			}
+
+			if n < int64(a.batchSize) {
+				break
+			}
+		}
+	}
+
+	return total, nil
+}
+
+// moveBatch moves a batch of rows and the rows of the children that belong
+// to them in a single statement. The children are deleted and copied by the
+// statement itself, so the cascade of the delete of their rows, which runs
+// at the end of it, finds nothing left to remove.
+func (a *Archiver) moveBatch(ctx context.Context, t Table, from time.Time, to time.Time) (int64, error) {
+	q := fmt.Sprintf(`
+	WITH moved AS (
+		DELETE FROM %[1]s
+		WHERE ctid IN (
+			SELECT ctid FROM %[1]s
+			WHERE %[2]s >= $1 AND %[2]s < $2
+			LIMIT $3
+		)
+		RETURNING *
+	)`, t.Name, t.TimeColumn)
+
+	for i, c := range t.Children {
+		q += fmt.Sprintf(`,
+	moved_%[1]d AS (
+		DELETE FROM %[2]s
+		WHERE %[3]s IN (SELECT %[3]s FROM moved)
+		RETURNING *
+	),
+	archived_%[1]d AS (
+		INSERT INTO %[4]s SELECT * FROM moved_%[1]d
+	)`, i, c.Name, c.Key, c.ArchiveName())
+	}
+
+	q += fmt.Sprintf(`
+	INSERT INTO %s SELECT * FROM moved`, t.ArchiveName())
+
+	res, err := a.db.ExecContext(ctx, q, from, to, a.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("move batch: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return n, nil
+}
+
+// ensureArchive creates the partitioned archive table, the archives of the
+// children and the read-through views if they don't exist.
+func (a *Archiver) ensureArchive(ctx context.Context, t Table) error {
+	q := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY RANGE (%s)`, t.ArchiveName(), t.Name, t.TimeColumn)
+	if _, err := a.db.ExecContext(ctx, q); err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+
+	q = fmt.Sprintf(`CREATE OR REPLACE VIEW %s AS SELECT * FROM %s UNION ALL SELECT * FROM %s`, t.HistoryName(), t.Name, t.ArchiveName())
+	if _, err := a.db.ExecContext(ctx, q); err != nil {
+		return fmt.Errorf("create history view: %w", err)
+	}
+
+	for _, c := range t.Children {
+		q := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS)`, c.ArchiveName(), c.Name)
+		if _, err := a.db.ExecContext(ctx, q); err != nil {
+			return fmt.Errorf("create archive[%s]: %w", c.Name, err)
+		}
+
+		q = fmt.Sprintf(`CREATE OR REPLACE VIEW %s AS SELECT * FROM %s UNION ALL SELECT * FROM %s`, c.HistoryName(), c.Name, c.ArchiveName())
+		if _, err := a.db.ExecContext(ctx, q); err != nil {
+			return fmt.Errorf("create history view[%s]: %w", c.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *Archiver) ensurePartition(ctx context.Context, t Table, month time.Time) error {
+	name := fmt.Sprintf("%s_%04d_%02d", t.ArchiveName(), month.Year(), month.Month())
+
+	q := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+		name, t.ArchiveName(), month.Format(time.DateOnly), month.AddDate(0, 1, 0).Format(time.DateOnly))
+
+	if _, err := a.db.ExecContext(ctx, q); err != nil {
+		return fmt.Errorf("create partition[%s]: %w", name, err)
+	}
+
+	return nil
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
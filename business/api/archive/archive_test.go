@@ -0,0 +1,122 @@
+package archive_test
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mrcruz117/al-service/business/api/archive"
+	"github.com/mrcruz117/al-service/business/api/dbtest"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Run(m))
+}
+
+// Test_Archive ages an order past the max age, archives it and checks it
+// is left out of the hot queries but read back with its items through the
+// archive.
+func Test_Archive(t *testing.T) {
+	test := dbtest.New(t)
+	ctx := context.Background()
+
+	usr, err := test.Core.User.QueryByEmail(ctx, mail.Address{Address: "admin@example.com"})
+	if err != nil {
+		t.Fatalf("Should be able to query the seeded admin : %s", err)
+	}
+
+	prd, err := test.Core.Product.Create(ctx, product.NewProduct{UserID: usr.ID, Name: "Widget", Cost: 10, Quantity: 5})
+	if err != nil {
+		t.Fatalf("Should be able to create a product : %s", err)
+	}
+
+	newOrder := func() order.Order {
+		t.Helper()
+
+		ord, err := test.Core.Order.Create(ctx, order.NewOrder{
+			UserID: usr.ID,
+			Items:  []order.NewItem{{ProductID: prd.ID, Quantity: 1}, {ProductID: prd.ID, Quantity: 2}},
+		})
+		if err != nil {
+			t.Fatalf("Should be able to place an order : %s", err)
+		}
+
+		return ord
+	}
+
+	old := newOrder()
+	recent := newOrder()
+
+	if _, err := test.DB.ExecContext(ctx, `UPDATE orders SET date_created = $1 WHERE order_id = $2`, time.Now().UTC().AddDate(-2, 0, 0), old.ID); err != nil {
+		t.Fatalf("Should be able to age the order : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	archiver, err := archive.New(test.Log, test.DB, 100, archive.Table{
+		Name:       "orders",
+		TimeColumn: "date_created",
+		MaxAge:     365 * 24 * time.Hour,
+		Children:   []archive.Child{{Name: "order_items", Key: "order_id"}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the archiver : %s", err)
+	}
+
+	moved, err := archiver.Archive(ctx)
+	if err != nil {
+		t.Fatalf("Should be able to archive : %s", err)
+	}
+	if moved["orders"] != 1 {
+		t.Errorf("Should move the old order only, got %d moved", moved["orders"])
+	}
+
+	var items int
+	if err := test.DB.GetContext(ctx, &items, `SELECT count(1) FROM order_items_archive WHERE order_id = $1`, old.ID); err != nil {
+		t.Fatalf("Should be able to count the archived items : %s", err)
+	}
+	if items != 2 {
+		t.Errorf("Should move the items along with the order, got %d archived", items)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if _, err := test.Core.Order.QueryByID(ctx, old.ID); !errors.Is(err, order.ErrNotFound) {
+		t.Errorf("Should not find the archived order in the hot table, got %v", err)
+	}
+
+	if _, err := test.Core.Order.QueryByID(ctx, recent.ID); err != nil {
+		t.Errorf("Should still find the recent order : %s", err)
+	}
+
+	ords, err := test.Core.Order.Query(ctx, order.QueryFilter{ID: &old.ID, WithArchived: true}, order.DefaultOrderBy, page.MustParse("1", "10"))
+	if err != nil {
+		t.Fatalf("Should be able to query through the archive : %s", err)
+	}
+	if len(ords) != 1 || len(ords[0].Items) != 2 || ords[0].Total != old.Total {
+		t.Fatalf("Should read the archived order back with its items, got %+v", ords)
+	}
+
+	count, err := test.Core.Order.Count(ctx, order.QueryFilter{WithArchived: true})
+	if err != nil {
+		t.Fatalf("Should be able to count through the archive : %s", err)
+	}
+	if count != 2 {
+		t.Errorf("Should count the hot and archived orders, got %d", count)
+	}
+
+	// Archiving again finds nothing left to move.
+	moved, err = archiver.Archive(ctx)
+	if err != nil {
+		t.Fatalf("Should be able to archive again : %s", err)
+	}
+	if moved["orders"] != 0 {
+		t.Errorf("Should have nothing left to move, got %d moved", moved["orders"])
+	}
+}
@@ -0,0 +1,30 @@
+// Package credential provides support for the secrets handed out to
+// callers, such as client secrets, api keys and single use tokens, of which
+// only a hash is stored.
+package credential
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// Generate returns n bytes from a cryptographically secure source, base64url
+// encoded without padding.
+func Generate(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash returns the hex encoded SHA-256 of the secret. Unlike a password, a
+// secret made by Generate has enough entropy that a fast hash is sufficient
+// for storage.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
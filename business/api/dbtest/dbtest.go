@@ -0,0 +1,249 @@
+// Package dbtest starts a disposable Postgres for the tests of the stores
+// and cores. A package runs one container for all its tests, and each test
+// gets a database of its own in it, migrated and seeded, which is dropped
+// when the test ends. Docker must be installed; the tests are skipped when
+// it isn't.
+//
+// A package using it runs its tests through Run:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(dbtest.Run(m))
+//	}
+package dbtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/api/migrate"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/order/stores/orderdb"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/product/stores/productdb"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/tenant/stores/tenantdb"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/userdb"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct/stores/vproductdb"
+	"github.com/mrcruz117/al-service/foundation/docker"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// image is the Postgres the tests run against, the one the service is
+// deployed with.
+const image = "postgres:17.5"
+
+// startTimeout bounds how long Postgres has to accept connections once the
+// container is started, and how long a test has to set its database up.
+const startTimeout = time.Minute
+
+// The container of the package, or why there is none.
+var (
+	db      docker.Container
+	skipped string
+)
+
+// Run starts the database container, runs the tests and removes the
+// container, returning the exit code of the tests.
+func Run(m *testing.M) int {
+	name := fmt.Sprintf("dbtest-%d", os.Getpid())
+
+	c, err := docker.StartContainer(image, name, "5432", []string{"-e", "POSTGRES_PASSWORD=postgres"}, nil)
+	if err != nil {
+		if errors.Is(err, docker.ErrNotInstalled) {
+			skipped = err.Error()
+			return m.Run()
+		}
+
+		fmt.Fprintln(os.Stderr, "dbtest:", err)
+		return 1
+	}
+	defer docker.StopContainer(c.Name)
+
+	db = c
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+
+	// Each check gets a few seconds so a connection refused while Postgres
+	// starts is retried rather than waited on.
+	ready := func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		conn, err := open(ctx, "postgres")
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	if err := docker.WaitReady(ctx, c, ready); err != nil {
+		fmt.Fprintln(os.Stderr, "dbtest:", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+// =============================================================================
+
+// Test is the database of a test and the cores constructed over it. Log
+// holds what the cores logged, which is printed when the test fails.
+type Test struct {
+	DB   *sqlx.DB
+	Log  *logger.Logger
+	Core Cores
+}
+
+// Cores are the cores backed by the database stores. The caches and the
+// audit trail the services wrap the stores in are left out, so a test sees
+// what the stores do.
+type Cores struct {
+	User     *user.Core
+	Product  *product.Core
+	Order    *order.Core
+	VProduct *vproduct.Core
+	Tenant   *tenant.Core
+}
+
+// New creates a database for the test, migrated and seeded, and drops it
+// when the test ends.
+func New(t *testing.T) *Test {
+	t.Helper()
+
+	if skipped != "" {
+		t.Skip("dbtest:", skipped)
+	}
+	if db.Name == "" {
+		t.Fatal("dbtest: the tests of the package must be run with dbtest.Run")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+
+	master, err := open(ctx, "postgres")
+	if err != nil {
+		t.Fatalf("dbtest: %s", err)
+	}
+	defer master.Close()
+
+	name := "test_" + randomHex()
+	if _, err := master.ExecContext(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("dbtest: creating database: %s", err)
+	}
+
+	tdb, err := open(ctx, name)
+	if err != nil {
+		t.Fatalf("dbtest: %s", err)
+	}
+
+	if err := migrate.Migrate(ctx, tdb); err != nil {
+		t.Fatalf("dbtest: migrating: %s", err)
+	}
+
+	if err := migrate.Seed(ctx, tdb); err != nil {
+		t.Fatalf("dbtest: seeding: %s", err)
+	}
+
+	var buf syncBuffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	t.Cleanup(func() {
+		tdb.Close()
+
+		if t.Failed() {
+			t.Logf("******************** LOGS (%s) ********************\n%s", t.Name(), buf.String())
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		master, err := open(ctx, "postgres")
+		if err != nil {
+			t.Errorf("dbtest: %s", err)
+			return
+		}
+		defer master.Close()
+
+		if _, err := master.ExecContext(ctx, "DROP DATABASE "+name+" WITH (FORCE)"); err != nil {
+			t.Errorf("dbtest: dropping database: %s", err)
+		}
+	})
+
+	dlg := delegate.New(log)
+	userCore := user.NewCore(log, dlg, userdb.NewStore(log, tdb))
+	productCore := product.NewCore(log, productdb.NewStore(log, tdb), userCore)
+
+	test := Test{
+		DB:  tdb,
+		Log: log,
+		Core: Cores{
+			User:     userCore,
+			Product:  productCore,
+			Order:    order.NewCore(log, dlg, nil, orderdb.NewStore(log, tdb), userCore, productCore),
+			VProduct: vproduct.NewCore(log, vproductdb.NewStore(log, tdb)),
+			Tenant:   tenant.NewCore(log, tenantdb.NewStore(log, tdb)),
+		},
+	}
+
+	return &test
+}
+
+// open connects to the named database of the container and checks it
+// takes queries.
+func open(ctx context.Context, name string) (*sqlx.DB, error) {
+	conn, err := sqldb.Open(sqldb.Config{
+		User:       "postgres",
+		Password:   "postgres",
+		HostPort:   db.HostPort,
+		Name:       name,
+		DisableTLS: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening database %s: %w", name, err)
+	}
+
+	if err := sqldb.StatusCheck(ctx, conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("database %s status: %w", name, err)
+	}
+
+	return conn, nil
+}
+
+func randomHex() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// syncBuffer is a buffer the cores can log to from more than one goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
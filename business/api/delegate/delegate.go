@@ -0,0 +1,74 @@
+// Package delegate provides the ability to make function calls between core
+// packages when an import is not possible. A core calls out when an action
+// happens to its data and the cores that depend on it register to be told,
+// so the calls run against the direction of the imports.
+package delegate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Data represents the action that happened in a domain. RawParams holds the
+// parameters of the action encoded as JSON by the domain that calls it.
+type Data struct {
+	Domain    string
+	Action    string
+	RawParams []byte
+}
+
+// Func represents a function that is called for an action.
+type Func func(ctx context.Context, data Data) error
+
+// Delegate manages the set of functions to call for each action.
+type Delegate struct {
+	log   *logger.Logger
+	mu    sync.RWMutex
+	funcs map[string]map[string][]Func
+}
+
+// New constructs a delegate for the core packages to share.
+func New(log *logger.Logger) *Delegate {
+	return &Delegate{
+		log:   log,
+		funcs: make(map[string]map[string][]Func),
+	}
+}
+
+// Register adds the function to be called for the action of the domain.
+func (d *Delegate) Register(domain string, action string, fn Func) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	actions, exists := d.funcs[domain]
+	if !exists {
+		actions = make(map[string][]Func)
+		d.funcs[domain] = actions
+	}
+
+	actions[action] = append(actions[action], fn)
+}
+
+// Call runs the functions registered for the action in the order they were
+// registered. Every function is called even when one before it fails, and
+// the errors are returned together.
+func (d *Delegate) Call(ctx context.Context, data Data) error {
+	d.mu.RLock()
+	funcs := d.funcs[data.Domain][data.Action]
+	d.mu.RUnlock()
+
+	d.log.Info(ctx, "delegate call", "domain", data.Domain, "action", data.Action, "funcs", len(funcs))
+
+	var errs []error
+	for i, fn := range funcs {
+		if err := fn(ctx, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s[%d]: %w", data.Domain, data.Action, i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,326 @@
+// Package memstore provides support for in-memory tables that behave like
+// the database for the purpose of business layer unit tests. Missing rows
+// and duplicate keys are reported with the same errors the sqldb package
+// translates database errors into, so the in-memory stores can map them the
+// way the database stores do.
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+)
+
+// InTenant reports whether a row of the tenant can be seen from the context,
+// like the condition sqldb.ScopeTenant adds for the database stores.
+func InTenant(ctx context.Context, tenantID uuid.UUID) bool {
+	return tenant.CheckAccess(ctx, tenantID) == nil
+}
+
+// Unique represents a unique constraint on a table. Err, when set, is
+// wrapped along with sqldb.ErrDBDuplicatedEntry when the constraint is
+// violated.
+type Unique[T any] struct {
+	Key func(v T) any
+	Err error
+}
+
+// Query represents the rows to select from a table. A nil Match selects
+// every row, a nil Less keeps the rows in the order they were inserted and
+// a zero Limit returns every row after the offset.
+type Query[T any] struct {
+	Match  func(v T) bool
+	Less   func(a T, b T) bool
+	Offset int
+	Limit  int
+}
+
+// Table is a set of rows identified by a primary key. Rows are stored as
+// given, so slices and maps inside a row are shared with the caller. It is
+// safe for concurrent use.
+type Table[K comparable, T any] struct {
+	mu      sync.RWMutex
+	key     func(v T) K
+	uniques []Unique[T]
+	rows    map[K]T
+	order   []K
+}
+
+// New constructs an empty table whose primary key is returned by key.
+func New[K comparable, T any](key func(v T) K, uniques ...Unique[T]) *Table[K, T] {
+	return &Table[K, T]{
+		key:     key,
+		uniques: uniques,
+		rows:    make(map[K]T),
+	}
+}
+
+// Insert adds the rows to the table. Like a single INSERT statement either
+// every row is added or none are.
+func (t *Table[K, T]) Insert(vs ...T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	batch := make(map[K]struct{}, len(vs))
+	for _, v := range vs {
+		k := t.key(v)
+
+		if _, exists := t.rows[k]; exists {
+			return fmt.Errorf("insert: %w", sqldb.ErrDBDuplicatedEntry)
+		}
+		if _, exists := batch[k]; exists {
+			return fmt.Errorf("insert: %w", sqldb.ErrDBDuplicatedEntry)
+		}
+		batch[k] = struct{}{}
+
+		if err := t.checkUnique(v, vs); err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+	}
+
+	for _, v := range vs {
+		k := t.key(v)
+		t.rows[k] = v
+		t.order = append(t.order, k)
+	}
+
+	return nil
+}
+
+// Update replaces the rows that exist in the table. Like an UPDATE statement
+// rows that don't exist are ignored.
+func (t *Table[K, T]) Update(vs ...T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, v := range vs {
+		if err := t.checkUnique(v, vs); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+	}
+
+	for _, v := range vs {
+		k := t.key(v)
+		if _, exists := t.rows[k]; exists {
+			t.rows[k] = v
+		}
+	}
+
+	return nil
+}
+
+// Upsert adds the rows that don't exist and replaces the ones that do.
+func (t *Table[K, T]) Upsert(vs ...T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, v := range vs {
+		if err := t.checkUnique(v, vs); err != nil {
+			return fmt.Errorf("upsert: %w", err)
+		}
+	}
+
+	for _, v := range vs {
+		k := t.key(v)
+		if _, exists := t.rows[k]; !exists {
+			t.order = append(t.order, k)
+		}
+		t.rows[k] = v
+	}
+
+	return nil
+}
+
+// UpsertFunc adds the row if it doesn't exist, otherwise it replaces the
+// existing row with the result of merge, like an INSERT with an ON CONFLICT
+// DO UPDATE clause.
+func (t *Table[K, T]) UpsertFunc(v T, merge func(existing T) T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := t.key(v)
+
+	existing, exists := t.rows[k]
+	if exists {
+		v = merge(existing)
+	}
+
+	if err := t.checkUnique(v, nil); err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	if !exists {
+		t.order = append(t.order, k)
+	}
+	t.rows[k] = v
+
+	return nil
+}
+
+// UpdateFunc replaces every row that matches with the result of fn and
+// returns the number of rows affected, like an UPDATE with a WHERE clause.
+// The primary key of a row must not be changed by fn and unique constraints
+// are not checked.
+func (t *Table[K, T]) UpdateFunc(match func(v T) bool, fn func(v T) T) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var n int
+	for _, k := range t.order {
+		if v := t.rows[k]; match(v) {
+			t.rows[k] = fn(v)
+			n++
+		}
+	}
+
+	return n
+}
+
+// UpdateIf replaces the row with the same key when match reports true for
+// the stored row, like an UPDATE guarded by the version of the row. When
+// the row doesn't exist or doesn't match sqldb.ErrDBVersionConflict is
+// returned, which is what sqldb.NamedExecVersioned reports.
+func (t *Table[K, T]) UpdateIf(v T, match func(existing T) bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := t.key(v)
+
+	existing, exists := t.rows[k]
+	if !exists || !match(existing) {
+		return sqldb.ErrDBVersionConflict
+	}
+
+	if err := t.checkUnique(v, nil); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	t.rows[k] = v
+
+	return nil
+}
+
+// Delete removes the rows with the specified keys. Like a DELETE statement
+// keys that don't exist are ignored.
+func (t *Table[K, T]) Delete(ks ...K) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, k := range ks {
+		delete(t.rows, k)
+	}
+
+	t.order = slices.DeleteFunc(t.order, func(k K) bool {
+		_, exists := t.rows[k]
+		return !exists
+	})
+}
+
+// Get returns the row with the specified key.
+func (t *Table[K, T]) Get(k K) (T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	v, exists := t.rows[k]
+	if !exists {
+		var zero T
+		return zero, fmt.Errorf("get: %w", sqldb.ErrDBNotFound)
+	}
+
+	return v, nil
+}
+
+// First returns the first row, in insertion order, that matches.
+func (t *Table[K, T]) First(match func(v T) bool) (T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, k := range t.order {
+		if v := t.rows[k]; match(v) {
+			return v, nil
+		}
+	}
+
+	var zero T
+	return zero, fmt.Errorf("first: %w", sqldb.ErrDBNotFound)
+}
+
+// Query returns the rows selected by the query. The result is never nil.
+func (t *Table[K, T]) Query(q Query[T]) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := make([]T, 0, len(t.order))
+	for _, k := range t.order {
+		if v := t.rows[k]; q.Match == nil || q.Match(v) {
+			rows = append(rows, v)
+		}
+	}
+
+	if q.Less != nil {
+		slices.SortStableFunc(rows, func(a T, b T) int {
+			switch {
+			case q.Less(a, b):
+				return -1
+			case q.Less(b, a):
+				return 1
+			default:
+				return 0
+			}
+		})
+	}
+
+	if q.Offset >= len(rows) {
+		return []T{}
+	}
+	rows = rows[max(q.Offset, 0):]
+
+	if q.Limit > 0 && q.Limit < len(rows) {
+		rows = rows[:q.Limit]
+	}
+
+	return rows
+}
+
+// Count returns the number of rows that match.
+func (t *Table[K, T]) Count(match func(v T) bool) int {
+	return len(t.Query(Query[T]{Match: match}))
+}
+
+// checkUnique reports whether writing v would violate a unique constraint
+// against the other rows in the table or the batch being written with it.
+func (t *Table[K, T]) checkUnique(v T, batch []T) error {
+	k := t.key(v)
+
+	for _, u := range t.uniques {
+		uk := u.Key(v)
+
+		conflict := func(other T) bool {
+			return t.key(other) != k && u.Key(other) == uk
+		}
+
+		if slices.ContainsFunc(batch, conflict) || t.containsFunc(conflict) {
+			if u.Err != nil {
+				return fmt.Errorf("%w: %w", sqldb.ErrDBDuplicatedEntry, u.Err)
+			}
+			return sqldb.ErrDBDuplicatedEntry
+		}
+	}
+
+	return nil
+}
+
+func (t *Table[K, T]) containsFunc(f func(v T) bool) bool {
+	for _, v := range t.rows {
+		if f(v) {
+			return true
+		}
+	}
+
+	return false
+}
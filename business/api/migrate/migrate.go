@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/ardanlabs/darwin/v3"
+	"github.com/ardanlabs/darwin/v3/dialects/mysql"
 	"github.com/ardanlabs/darwin/v3/dialects/postgres"
 	"github.com/ardanlabs/darwin/v3/drivers/generic"
 	"github.com/jmoiron/sqlx"
@@ -21,6 +22,18 @@ var (
 
 	//go:embed sql/seed.sql
 	seedDoc string
+
+	//go:embed sql/mysql/migrate.sql
+	mysqlMigrateDoc string
+
+	//go:embed sql/mysql/seed.sql
+	mysqlSeedDoc string
+
+	// The delete document is shared by both dialects. MySQL connections run
+	// with ANSI_QUOTES, so the quoted usage table name works there too.
+	//
+	//go:embed sql/delete.sql
+	deleteDoc string
 )
 
 // Migrate attempts to bring the database up to date with the migrations
@@ -30,23 +43,48 @@ func Migrate(ctx context.Context, db *sqlx.DB) error {
 		return fmt.Errorf("status check database: %w", err)
 	}
 
-	driver, err := generic.New(db.DB, postgres.Dialect{})
+	var dialect generic.Dialect = postgres.Dialect{}
+	doc := migrateDoc
+
+	if sqldb.DialectOf(db) == sqldb.MySQL {
+		dialect = mysql.Dialect{}
+		doc = mysqlMigrateDoc
+	}
+
+	driver, err := generic.New(db.DB, dialect)
 	if err != nil {
 		return fmt.Errorf("construct darwin driver: %w", err)
 	}
 
-	d := darwin.New(driver, darwin.ParseMigrations(migrateDoc))
+	d := darwin.New(driver, darwin.ParseMigrations(doc))
 	return d.Migrate()
 }
 
 // Seed runs the seed document defined in this package against db. The queries
 // are run in a transaction and rolled back if any fail.
-func Seed(ctx context.Context, db *sqlx.DB) (err error) {
+func Seed(ctx context.Context, db *sqlx.DB) error {
+	doc := seedDoc
+	if sqldb.DialectOf(db) == sqldb.MySQL {
+		doc = mysqlSeedDoc
+	}
+
+	return execDoc(ctx, db, doc)
+}
+
+// DeleteAll removes the rows of every table, leaving the schema and the
+// record of the migrations applied, so tests can start over from an empty
+// or freshly seeded database. The queries are run in a transaction and
+// rolled back if any fail.
+func DeleteAll(ctx context.Context, db *sqlx.DB) error {
+	return execDoc(ctx, db, deleteDoc)
+}
+
+func execDoc(ctx context.Context, db *sqlx.DB, doc string) (err error) {
 	if err := sqldb.StatusCheck(ctx, db); err != nil {
 		return fmt.Errorf("status check database: %w", err)
 	}
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -62,7 +100,7 @@ func Seed(ctx context.Context, db *sqlx.DB) (err error) {
 		}
 	}()
 
-	if _, err := tx.Exec(seedDoc); err != nil {
+	if _, err := tx.ExecContext(ctx, doc); err != nil {
 		return fmt.Errorf("exec: %w", err)
 	}
 
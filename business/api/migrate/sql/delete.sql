@@ -0,0 +1,20 @@
+DELETE FROM order_items_archive;
+DELETE FROM orders_archive;
+DELETE FROM order_items;
+DELETE FROM orders;
+DELETE FROM products;
+DELETE FROM homes;
+DELETE FROM scim_group_members;
+DELETE FROM scim_groups;
+DELETE FROM user_tokens;
+DELETE FROM user_preferences;
+DELETE FROM api_keys;
+DELETE FROM tenants;
+DELETE FROM users;
+DELETE FROM "usage";
+DELETE FROM audit_log;
+DELETE FROM entity_audit;
+DELETE FROM domain_events;
+DELETE FROM outbox;
+DELETE FROM service_instances;
+DELETE FROM service_clients;
@@ -63,4 +63,312 @@ CREATE TABLE homes (
 
     PRIMARY KEY (home_id),
     FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
-);
\ No newline at end of file
+);
+
+-- Version: 1.05
+-- Description: Create table usage
+CREATE TABLE usage (
+    subject      TEXT      NOT NULL,
+    route        TEXT      NOT NULL,
+    window_start TIMESTAMP NOT NULL,
+    requests     BIGINT    NOT NULL,
+    bytes        BIGINT    NOT NULL,
+    errors       BIGINT    NOT NULL,
+
+    PRIMARY KEY (subject, route, window_start)
+);
+
+-- Version: 1.06
+-- Description: Create table api_keys
+CREATE TABLE api_keys (
+    api_key_id   UUID        NOT NULL,
+    name         TEXT        NOT NULL,
+    prefix       TEXT UNIQUE NOT NULL,
+    key_hash     TEXT        NOT NULL,
+    roles        TEXT[]      NOT NULL,
+    enabled      BOOLEAN     NOT NULL,
+    date_created TIMESTAMP   NOT NULL,
+    date_updated TIMESTAMP   NOT NULL,
+
+    PRIMARY KEY (api_key_id)
+);
+
+-- Version: 1.07
+-- Description: Create tables for provisioned groups
+CREATE TABLE scim_groups (
+    group_id     UUID        NOT NULL,
+    display_name TEXT UNIQUE NOT NULL,
+    external_id  TEXT        NULL,
+    role         TEXT        NOT NULL,
+    date_created TIMESTAMP   NOT NULL,
+    date_updated TIMESTAMP   NOT NULL,
+
+    PRIMARY KEY (group_id)
+);
+
+CREATE TABLE scim_group_members (
+    group_id UUID NOT NULL,
+    user_id  UUID NOT NULL,
+
+    PRIMARY KEY (group_id, user_id),
+    FOREIGN KEY (group_id) REFERENCES scim_groups(group_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.08
+-- Description: Create table tenants and scope api keys
+CREATE TABLE tenants (
+    tenant_id    UUID        NOT NULL,
+    slug         TEXT UNIQUE NOT NULL,
+    name         TEXT        NOT NULL,
+    enabled      BOOLEAN     NOT NULL,
+    date_created TIMESTAMP   NOT NULL,
+    date_updated TIMESTAMP   NOT NULL,
+
+    PRIMARY KEY (tenant_id)
+);
+
+ALTER TABLE api_keys ADD COLUMN tenant_id UUID NULL REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+
+-- Version: 1.09
+-- Description: Create table user_tokens and track verified emails
+CREATE TABLE user_tokens (
+    token_id     UUID        NOT NULL,
+    user_id      UUID        NOT NULL,
+    purpose      TEXT        NOT NULL,
+    token_hash   TEXT UNIQUE NOT NULL,
+    expires_at   TIMESTAMP   NOT NULL,
+    used_at      TIMESTAMP   NULL,
+    date_created TIMESTAMP   NOT NULL,
+
+    PRIMARY KEY (token_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX user_tokens_user_purpose_idx ON user_tokens (user_id, purpose, date_created);
+
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT false;
+
+-- Version: 1.10
+-- Description: Create table audit_log
+CREATE TABLE audit_log (
+    audit_id     UUID      NOT NULL,
+    subject      TEXT      NOT NULL,
+    tenant_id    UUID      NULL,
+    method       TEXT      NOT NULL,
+    path         TEXT      NOT NULL,
+    route        TEXT      NOT NULL,
+    body_digest  TEXT      NULL,
+    status_code  INT       NOT NULL,
+    trace_id     TEXT      NOT NULL,
+    date_created TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (audit_id)
+);
+
+CREATE INDEX audit_log_subject_idx ON audit_log (subject, date_created);
+
+-- Version: 1.11
+-- Description: Create table user_preferences
+CREATE TABLE user_preferences (
+    user_id      UUID      NOT NULL,
+    document     TEXT      NOT NULL,
+    version      INT       NOT NULL,
+    date_updated TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.12
+-- Description: Create table domain_events
+CREATE TABLE domain_events (
+    seq          BIGSERIAL NOT NULL,
+    event_id     UUID      NOT NULL,
+    type         TEXT      NOT NULL,
+    tenant_id    UUID      NULL,
+    data         TEXT      NOT NULL,
+    date_created TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (seq),
+    UNIQUE (event_id)
+);
+
+CREATE INDEX domain_events_type_idx ON domain_events (type, seq);
+
+-- Version: 1.13
+-- Description: Create table service_instances
+CREATE TABLE service_instances (
+    instance_id  UUID      NOT NULL,
+    service      TEXT      NOT NULL,
+    build        TEXT      NOT NULL,
+    host         TEXT      NOT NULL,
+    address      TEXT      NOT NULL,
+    healthy      BOOLEAN   NOT NULL,
+    date_started TIMESTAMP NOT NULL,
+    last_seen    TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (instance_id)
+);
+
+CREATE INDEX service_instances_last_seen_idx ON service_instances (last_seen);
+
+-- Version: 1.14
+-- Description: Create table service_clients
+CREATE TABLE service_clients (
+    client_id    UUID      NOT NULL,
+    name         TEXT      NOT NULL,
+    secret_hash  TEXT      NOT NULL,
+    scopes       TEXT[]    NOT NULL,
+    enabled      BOOLEAN   NOT NULL,
+    date_created TIMESTAMP NOT NULL,
+    date_updated TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (client_id)
+);
+
+-- Version: 1.15
+-- Description: Create tables orders and order_items
+CREATE TABLE orders (
+    order_id     UUID           NOT NULL,
+    user_id      UUID           NOT NULL,
+    status       TEXT           NOT NULL,
+    total        NUMERIC(12, 2) NOT NULL,
+    date_created TIMESTAMP      NOT NULL,
+    date_updated TIMESTAMP      NOT NULL,
+
+    PRIMARY KEY (order_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX orders_user_id_idx ON orders (user_id);
+
+CREATE TABLE order_items (
+    order_id   UUID           NOT NULL,
+    line       INT            NOT NULL,
+    product_id UUID           NOT NULL,
+    quantity   INT            NOT NULL,
+    price      NUMERIC(10, 2) NOT NULL,
+
+    PRIMARY KEY (order_id, line),
+    FOREIGN KEY (order_id) REFERENCES orders(order_id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(product_id)
+);
+
+-- Version: 1.16
+-- Description: Add version columns to users and products
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- Version: 1.17
+-- Description: Add deleted_at columns to products and orders
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP NULL;
+ALTER TABLE orders ADD COLUMN deleted_at TIMESTAMP NULL;
+
+-- Version: 1.18
+-- Description: Create table entity_audit
+CREATE TABLE entity_audit (
+    change_id    UUID      NOT NULL,
+    entity       TEXT      NOT NULL,
+    entity_id    TEXT      NOT NULL,
+    action       TEXT      NOT NULL,
+    actor        TEXT      NOT NULL,
+    before_data  TEXT      NULL,
+    after_data   TEXT      NULL,
+    date_created TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (change_id)
+);
+
+CREATE INDEX entity_audit_entity_idx ON entity_audit (entity, entity_id, date_created);
+CREATE INDEX entity_audit_actor_idx ON entity_audit (actor, date_created);
+
+-- Version: 1.19
+-- Description: Add full text search on product names
+ALTER TABLE products ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', name)) STORED;
+CREATE INDEX products_search_idx ON products USING GIN (search);
+
+-- Version: 1.20
+-- Description: Create view view_products joining products with their owners
+CREATE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+    p.name,
+    p.cost,
+    p.quantity,
+    p.version,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.deleted_at IS NULL;
+
+-- Version: 1.21
+-- Description: Scope users, products and orders to tenants
+ALTER TABLE users ADD COLUMN tenant_id UUID NULL REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+ALTER TABLE products ADD COLUMN tenant_id UUID NULL REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+ALTER TABLE orders ADD COLUMN tenant_id UUID NULL REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+
+CREATE INDEX users_tenant_idx ON users (tenant_id);
+CREATE INDEX products_tenant_idx ON products (tenant_id);
+CREATE INDEX orders_tenant_idx ON orders (tenant_id);
+
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+    p.name,
+    p.cost,
+    p.quantity,
+    p.version,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    p.tenant_id
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.deleted_at IS NULL;
+
+-- Version: 1.22
+-- Description: Create table outbox
+CREATE TABLE outbox (
+    seq            BIGSERIAL NOT NULL,
+    message_id     UUID      NOT NULL,
+    type           TEXT      NOT NULL,
+    version        INT       NOT NULL,
+    event_key      TEXT      NOT NULL,
+    data           TEXT      NOT NULL,
+    attempts       INT       NOT NULL DEFAULT 0,
+    last_error     TEXT      NULL,
+    date_created   TIMESTAMP NOT NULL,
+    date_published TIMESTAMP NULL,
+
+    PRIMARY KEY (seq),
+    UNIQUE (message_id)
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (seq) WHERE date_published IS NULL;
+CREATE INDEX outbox_published_idx ON outbox (date_published) WHERE date_published IS NOT NULL;
+
+-- Version: 1.23
+-- Description: Add version column to orders
+ALTER TABLE orders ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- Version: 1.24
+-- Description: Create the archive of orders and their read-through views
+CREATE TABLE orders_archive (LIKE orders INCLUDING DEFAULTS) PARTITION BY RANGE (date_created);
+CREATE TABLE order_items_archive (LIKE order_items INCLUDING DEFAULTS);
+
+CREATE INDEX orders_archive_order_id_idx ON orders_archive (order_id);
+CREATE INDEX order_items_archive_order_id_idx ON order_items_archive (order_id);
+
+CREATE VIEW orders_history AS SELECT * FROM orders UNION ALL SELECT * FROM orders_archive;
+CREATE VIEW order_items_history AS SELECT * FROM order_items UNION ALL SELECT * FROM order_items_archive;
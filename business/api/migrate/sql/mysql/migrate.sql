@@ -0,0 +1,367 @@
+-- Version: 1.01
+-- Description: Create table users
+CREATE TABLE users (
+	user_id       CHAR(36)     NOT NULL,
+	name          TEXT         NOT NULL,
+	email         VARCHAR(255) NOT NULL,
+	roles         TEXT         NOT NULL,
+	password_hash TEXT         NOT NULL,
+    department    TEXT         NULL,
+    enabled       BOOLEAN      NOT NULL,
+	date_created  DATETIME(6)  NOT NULL,
+	date_updated  DATETIME(6)  NOT NULL,
+
+	PRIMARY KEY (user_id),
+	UNIQUE KEY (email)
+);
+
+-- Version: 1.02
+-- Description: Create table products
+CREATE TABLE products (
+	product_id   CHAR(36)       NOT NULL,
+    user_id      CHAR(36)       NOT NULL,
+	name         TEXT           NOT NULL,
+    cost         NUMERIC(10, 2) NOT NULL,
+	quantity     INT            NOT NULL,
+	date_created DATETIME(6)    NOT NULL,
+	date_updated DATETIME(6)    NOT NULL,
+
+	PRIMARY KEY (product_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.03
+-- Description: Add products view.
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id;
+
+-- Version: 1.04
+-- Description: Create table homes
+CREATE TABLE homes (
+    home_id       CHAR(36)    NOT NULL,
+    type          TEXT        NOT NULL,
+    user_id       CHAR(36)    NOT NULL,
+    address_1     TEXT        NOT NULL,
+    address_2     TEXT        NULL,
+    zip_code      TEXT        NOT NULL,
+    city          TEXT        NOT NULL,
+    state         TEXT        NOT NULL,
+    country       TEXT        NOT NULL,
+    date_created  DATETIME(6) NOT NULL,
+    date_updated  DATETIME(6) NOT NULL,
+
+    PRIMARY KEY (home_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.05
+-- Description: Create table usage
+CREATE TABLE "usage" (
+    subject      VARCHAR(255) NOT NULL,
+    route        VARCHAR(255) NOT NULL,
+    window_start DATETIME(6)  NOT NULL,
+    requests     BIGINT       NOT NULL,
+    bytes        BIGINT       NOT NULL,
+    errors       BIGINT       NOT NULL,
+
+    PRIMARY KEY (subject, route, window_start)
+);
+
+-- Version: 1.06
+-- Description: Create table api_keys
+CREATE TABLE api_keys (
+    api_key_id   CHAR(36)     NOT NULL,
+    name         TEXT         NOT NULL,
+    prefix       VARCHAR(64)  NOT NULL,
+    key_hash     TEXT         NOT NULL,
+    roles        TEXT         NOT NULL,
+    enabled      BOOLEAN      NOT NULL,
+    date_created DATETIME(6)  NOT NULL,
+    date_updated DATETIME(6)  NOT NULL,
+
+    PRIMARY KEY (api_key_id),
+    UNIQUE KEY (prefix)
+);
+
+-- Version: 1.07
+-- Description: Create tables for provisioned groups
+CREATE TABLE scim_groups (
+    group_id     CHAR(36)     NOT NULL,
+    display_name VARCHAR(255) NOT NULL,
+    external_id  TEXT         NULL,
+    role         TEXT         NOT NULL,
+    date_created DATETIME(6)  NOT NULL,
+    date_updated DATETIME(6)  NOT NULL,
+
+    PRIMARY KEY (group_id),
+    UNIQUE KEY (display_name)
+);
+
+CREATE TABLE scim_group_members (
+    group_id CHAR(36) NOT NULL,
+    user_id  CHAR(36) NOT NULL,
+
+    PRIMARY KEY (group_id, user_id),
+    FOREIGN KEY (group_id) REFERENCES scim_groups(group_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.08
+-- Description: Create table tenants and scope api keys
+CREATE TABLE tenants (
+    tenant_id    CHAR(36)    NOT NULL,
+    slug         VARCHAR(63) NOT NULL,
+    name         TEXT        NOT NULL,
+    enabled      BOOLEAN     NOT NULL,
+    date_created DATETIME(6) NOT NULL,
+    date_updated DATETIME(6) NOT NULL,
+
+    PRIMARY KEY (tenant_id),
+    UNIQUE KEY (slug)
+);
+
+ALTER TABLE api_keys ADD COLUMN tenant_id CHAR(36) NULL;
+ALTER TABLE api_keys ADD FOREIGN KEY (tenant_id) REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+
+-- Version: 1.09
+-- Description: Create table user_tokens and track verified emails
+CREATE TABLE user_tokens (
+    token_id     CHAR(36)    NOT NULL,
+    user_id      CHAR(36)    NOT NULL,
+    purpose      VARCHAR(32) NOT NULL,
+    token_hash   CHAR(64)    NOT NULL,
+    expires_at   DATETIME(6) NOT NULL,
+    used_at      DATETIME(6) NULL,
+    date_created DATETIME(6) NOT NULL,
+
+    PRIMARY KEY (token_id),
+    UNIQUE KEY (token_hash),
+    KEY (user_id, purpose, date_created),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT false;
+
+-- Version: 1.10
+-- Description: Create table audit_log
+CREATE TABLE audit_log (
+    audit_id     CHAR(36)     NOT NULL,
+    subject      VARCHAR(255) NOT NULL,
+    tenant_id    CHAR(36)     NULL,
+    method       VARCHAR(16)  NOT NULL,
+    path         TEXT         NOT NULL,
+    route        TEXT         NOT NULL,
+    body_digest  CHAR(64)     NULL,
+    status_code  INT          NOT NULL,
+    trace_id     VARCHAR(64)  NOT NULL,
+    date_created DATETIME(6)  NOT NULL,
+
+    PRIMARY KEY (audit_id),
+    KEY (subject, date_created)
+);
+
+-- Version: 1.11
+-- Description: Create table user_preferences
+CREATE TABLE user_preferences (
+    user_id      CHAR(36)    NOT NULL,
+    document     MEDIUMTEXT  NOT NULL,
+    version      INT         NOT NULL,
+    date_updated DATETIME(6) NOT NULL,
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.12
+-- Description: Create table domain_events
+CREATE TABLE domain_events (
+    seq          BIGINT       NOT NULL AUTO_INCREMENT,
+    event_id     CHAR(36)     NOT NULL,
+    type         VARCHAR(128) NOT NULL,
+    tenant_id    CHAR(36)     NULL,
+    data         MEDIUMTEXT   NOT NULL,
+    date_created DATETIME(6)  NOT NULL,
+
+    PRIMARY KEY (seq),
+    UNIQUE KEY (event_id),
+    KEY (type, seq)
+);
+
+-- Version: 1.13
+-- Description: Create table service_instances
+CREATE TABLE service_instances (
+    instance_id  CHAR(36)     NOT NULL,
+    service      VARCHAR(64)  NOT NULL,
+    build        VARCHAR(128) NOT NULL,
+    host         VARCHAR(255) NOT NULL,
+    address      VARCHAR(255) NOT NULL,
+    healthy      BOOLEAN      NOT NULL,
+    date_started DATETIME(6)  NOT NULL,
+    last_seen    DATETIME(6)  NOT NULL,
+
+    PRIMARY KEY (instance_id),
+    KEY (last_seen)
+);
+
+-- Version: 1.14
+-- Description: Create table service_clients
+CREATE TABLE service_clients (
+    client_id    CHAR(36)    NOT NULL,
+    name         TEXT        NOT NULL,
+    secret_hash  TEXT        NOT NULL,
+    scopes       TEXT        NOT NULL,
+    enabled      BOOLEAN     NOT NULL,
+    date_created DATETIME(6) NOT NULL,
+    date_updated DATETIME(6) NOT NULL,
+
+    PRIMARY KEY (client_id)
+);
+
+-- Version: 1.15
+-- Description: Create tables orders and order_items
+CREATE TABLE orders (
+    order_id     CHAR(36)       NOT NULL,
+    user_id      CHAR(36)       NOT NULL,
+    status       VARCHAR(32)    NOT NULL,
+    total        NUMERIC(12, 2) NOT NULL,
+    date_created DATETIME(6)    NOT NULL,
+    date_updated DATETIME(6)    NOT NULL,
+
+    PRIMARY KEY (order_id),
+    KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE order_items (
+    order_id   CHAR(36)       NOT NULL,
+    line       INT            NOT NULL,
+    product_id CHAR(36)       NOT NULL,
+    quantity   INT            NOT NULL,
+    price      NUMERIC(10, 2) NOT NULL,
+
+    PRIMARY KEY (order_id, line),
+    FOREIGN KEY (order_id) REFERENCES orders(order_id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(product_id)
+);
+
+-- Version: 1.16
+-- Description: Add version columns to users and products
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- Version: 1.17
+-- Description: Add deleted_at columns to products and orders
+ALTER TABLE products ADD COLUMN deleted_at DATETIME(6) NULL;
+ALTER TABLE orders ADD COLUMN deleted_at DATETIME(6) NULL;
+
+-- Version: 1.18
+-- Description: Create table entity_audit
+CREATE TABLE entity_audit (
+    change_id    CHAR(36)     NOT NULL,
+    entity       VARCHAR(64)  NOT NULL,
+    entity_id    VARCHAR(64)  NOT NULL,
+    action       VARCHAR(16)  NOT NULL,
+    actor        VARCHAR(255) NOT NULL,
+    before_data  MEDIUMTEXT   NULL,
+    after_data   MEDIUMTEXT   NULL,
+    date_created DATETIME(6)  NOT NULL,
+
+    PRIMARY KEY (change_id),
+    KEY (entity, entity_id, date_created),
+    KEY (actor, date_created)
+);
+
+-- Version: 1.19
+-- Description: Add full text search on product names
+CREATE FULLTEXT INDEX products_search_idx ON products (name);
+
+-- Version: 1.20
+-- Description: Create view view_products joining products with their owners
+CREATE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+    p.name,
+    p.cost,
+    p.quantity,
+    p.version,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.deleted_at IS NULL;
+
+-- Version: 1.21
+-- Description: Scope users, products and orders to tenants
+ALTER TABLE users ADD COLUMN tenant_id CHAR(36) NULL;
+ALTER TABLE users ADD FOREIGN KEY (tenant_id) REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+ALTER TABLE products ADD COLUMN tenant_id CHAR(36) NULL;
+ALTER TABLE products ADD FOREIGN KEY (tenant_id) REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+ALTER TABLE orders ADD COLUMN tenant_id CHAR(36) NULL;
+ALTER TABLE orders ADD FOREIGN KEY (tenant_id) REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+    p.name,
+    p.cost,
+    p.quantity,
+    p.version,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    p.tenant_id
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.deleted_at IS NULL;
+
+-- Version: 1.22
+-- Description: Create table outbox
+CREATE TABLE outbox (
+    seq            BIGINT       NOT NULL AUTO_INCREMENT,
+    message_id     CHAR(36)     NOT NULL,
+    type           VARCHAR(128) NOT NULL,
+    version        INT          NOT NULL,
+    event_key      VARCHAR(128) NOT NULL,
+    data           MEDIUMTEXT   NOT NULL,
+    attempts       INT          NOT NULL DEFAULT 0,
+    last_error     TEXT         NULL,
+    date_created   DATETIME(6)  NOT NULL,
+    date_published DATETIME(6)  NULL,
+
+    PRIMARY KEY (seq),
+    UNIQUE KEY (message_id),
+    KEY (date_published, seq)
+);
+
+-- Version: 1.23
+-- Description: Add version column to orders
+ALTER TABLE orders ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- Version: 1.24
+-- Description: Create the archive of orders and their read-through views
+CREATE TABLE orders_archive LIKE orders;
+CREATE TABLE order_items_archive LIKE order_items;
+
+CREATE VIEW orders_history AS SELECT * FROM orders UNION ALL SELECT * FROM orders_archive;
+CREATE VIEW order_items_history AS SELECT * FROM order_items UNION ALL SELECT * FROM order_items_archive;
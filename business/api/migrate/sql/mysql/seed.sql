@@ -0,0 +1,64 @@
+INSERT IGNORE INTO
+    users (
+        user_id,
+        name,
+        email,
+        roles,
+        password_hash,
+        department,
+        enabled,
+        date_created,
+        date_updated
+    )
+VALUES
+    (
+        '5cf37266-3473-4006-984f-9325122678b7',
+        'Admin Gopher',
+        'admin@example.com',
+        '{ADMIN}',
+        '$2a$10$1ggfMVZV6Js0ybvJufLRUOWHS5f6KneuP0XwwHpJ8L8ipdry9f2/a',
+        NULL,
+        true,
+        '2019-03-24 00:00:00',
+        '2019-03-24 00:00:00'
+    ),
+    (
+        '45b5fbd3-755f-4379-8f07-a58d4a30fa2f',
+        'User Gopher',
+        'user@example.com',
+        '{USER}',
+        '$2a$10$9/XASPKBbJKVfCAZKDH.UuhsuALDr5vVm6VrYA9VFR8rccK86C1hW',
+        NULL,
+        true,
+        '2019-03-24 00:00:00',
+        '2019-03-24 00:00:00'
+    );
+INSERT IGNORE INTO
+    products (
+        product_id,
+        user_id,
+        name,
+        cost,
+        quantity,
+        date_created,
+        date_updated
+    )
+VALUES
+    (
+        'a2b0639f-2cc6-44b8-b97b-15d69dbb511e',
+        '45b5fbd3-755f-4379-8f07-a58d4a30fa2f',
+        'Comic Books',
+        50.00,
+        42,
+        '2019-03-24 00:00:00',
+        '2019-03-24 00:00:00'
+    ),
+    (
+        '72f8b983-3eb4-48db-9ed0-e45cc6bd716b',
+        '45b5fbd3-755f-4379-8f07-a58d4a30fa2f',
+        'McDonalds Toys',
+        75.00,
+        120,
+        '2019-03-24 00:00:00',
+        '2019-03-24 00:00:00'
+    );
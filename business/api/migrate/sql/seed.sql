@@ -32,4 +32,33 @@ VALUES
         true,
         '2019-03-24 00:00:00',
         '2019-03-24 00:00:00'
-    ) ON CONFLICT DO NOTHING;
\ No newline at end of file
+    ) ON CONFLICT DO NOTHING;
+INSERT INTO
+    products (
+        product_id,
+        user_id,
+        name,
+        cost,
+        quantity,
+        date_created,
+        date_updated
+    )
+VALUES
+    (
+        'a2b0639f-2cc6-44b8-b97b-15d69dbb511e',
+        '45b5fbd3-755f-4379-8f07-a58d4a30fa2f',
+        'Comic Books',
+        50.00,
+        42,
+        '2019-03-24 00:00:00',
+        '2019-03-24 00:00:00'
+    ),
+    (
+        '72f8b983-3eb4-48db-9ed0-e45cc6bd716b',
+        '45b5fbd3-755f-4379-8f07-a58d4a30fa2f',
+        'McDonalds Toys',
+        75.00,
+        120,
+        '2019-03-24 00:00:00',
+        '2019-03-24 00:00:00'
+    ) ON CONFLICT DO NOTHING;
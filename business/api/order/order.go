@@ -0,0 +1,62 @@
+// Package order provides support for describing the ordering of data.
+package order
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Set of directions for data ordering.
+const (
+	ASC  = "ASC"
+	DESC = "DESC"
+)
+
+var directions = map[string]string{
+	ASC:  "ASC",
+	DESC: "DESC",
+}
+
+// By represents a field used to order by and direction. The field is one of
+// the order by names the domain declares, never a column, so a store maps it
+// to SQL through its own allowlist.
+type By struct {
+	Field     string
+	Direction string
+}
+
+// NewBy constructs a new By value with no checks.
+func NewBy(field string, direction string) By {
+	return By{
+		Field:     field,
+		Direction: direction,
+	}
+}
+
+// Parse constructs a By value by parsing a string in the form of
+// "field[,direction]". The field must be a key of the mappings, which
+// translate the names clients use to the domain's order by names. The
+// default is returned for an empty string.
+func Parse(fieldMappings map[string]string, orderBy string, defaultOrder By) (By, error) {
+	if orderBy == "" {
+		return defaultOrder, nil
+	}
+
+	name, dir, _ := strings.Cut(orderBy, ",")
+
+	field, exists := fieldMappings[strings.TrimSpace(name)]
+	if !exists {
+		return By{}, fmt.Errorf("unknown order field %q", name)
+	}
+
+	direction := ASC
+	if dir != "" {
+		d, exists := directions[strings.ToUpper(strings.TrimSpace(dir))]
+		if !exists {
+			return By{}, fmt.Errorf("unknown direction %q", dir)
+		}
+		direction = d
+	}
+
+	return NewBy(field, direction), nil
+}
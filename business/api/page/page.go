@@ -0,0 +1,75 @@
+// Package page provides support for query paging.
+package page
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// These bound the rows a page can hold.
+const (
+	DefaultRows = 10
+	MaxRows     = 100
+)
+
+// Page represents the requested page and rows per page. Pages are numbered
+// from 1.
+type Page struct {
+	number int
+	rows   int
+}
+
+// Parse parses the strings into a page, within the bounds above. An empty
+// string leaves the first page or the default rows.
+func Parse(page string, rowsPerPage string) (Page, error) {
+	number := 1
+	if page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return Page{}, fmt.Errorf("page: must be a number of at least 1")
+		}
+		number = n
+	}
+
+	rows := DefaultRows
+	if rowsPerPage != "" {
+		n, err := strconv.Atoi(rowsPerPage)
+		if err != nil || n < 1 || n > MaxRows {
+			return Page{}, fmt.Errorf("rows: must be a number between 1 and %d", MaxRows)
+		}
+		rows = n
+	}
+
+	return Page{number: number, rows: rows}, nil
+}
+
+// MustParse creates a page from the strings and panics if they are out of
+// bounds. It is meant for tests and fixed queries.
+func MustParse(page string, rowsPerPage string) Page {
+	pg, err := Parse(page, rowsPerPage)
+	if err != nil {
+		panic(err)
+	}
+
+	return pg
+}
+
+// String implements the stringer interface.
+func (p Page) String() string {
+	return fmt.Sprintf("page: %d rows: %d", p.number, p.rows)
+}
+
+// Number returns the page number.
+func (p Page) Number() int {
+	return p.number
+}
+
+// RowsPerPage returns the rows per page.
+func (p Page) RowsPerPage() int {
+	return p.rows
+}
+
+// Offset returns the number of rows before the page.
+func (p Page) Offset() int {
+	return (p.number - 1) * p.rows
+}
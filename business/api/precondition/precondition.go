@@ -0,0 +1,64 @@
+// Package precondition provides support for conditional writes that only
+// apply when the stored state still matches what the caller expects.
+package precondition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation describes a part of the stored state that didn't match the
+// caller's expectation.
+type Violation struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+// Error is returned when a conditional write is rejected. It lists every
+// expectation that didn't hold so the caller can decide how to recover.
+type Error struct {
+	Violations []Violation
+}
+
+// Add records a violation if the expected and actual values differ.
+func (e *Error) Add(field string, expected any, actual any) {
+	exp := fmt.Sprint(expected)
+	act := fmt.Sprint(actual)
+
+	if exp == act {
+		return
+	}
+
+	e.Violations = append(e.Violations, Violation{
+		Field:    field,
+		Expected: exp,
+		Actual:   act,
+	})
+}
+
+// ToError returns the error, or nil when nothing was violated.
+func (e *Error) ToError() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("precondition failed")
+
+	for i, v := range e.Violations {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s is %s, expected %s", v.Field, v.Actual, v.Expected)
+	}
+
+	return b.String()
+}
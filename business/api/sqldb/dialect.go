@@ -0,0 +1,63 @@
+package sqldb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect represents the flavor of SQL spoken by a database.
+type Dialect string
+
+// The set of dialects supported by the stores.
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+)
+
+// ParseDialect converts the name of a dialect into a Dialect.
+func ParseDialect(name string) (Dialect, error) {
+	switch d := Dialect(strings.ToLower(name)); d {
+	case "", Postgres:
+		return Postgres, nil
+	case MySQL:
+		return MySQL, nil
+	}
+
+	return "", fmt.Errorf("unknown dialect %q", name)
+}
+
+// DialectOf returns the dialect of the open database connection or of the
+// transaction running on it.
+func DialectOf(db sqlx.ExtContext) Dialect {
+	if db.DriverName() == "mysql" {
+		return MySQL
+	}
+
+	return Postgres
+}
+
+// Upsert returns the clause that turns an INSERT into an update of the
+// listed assignments when a row with the same conflict columns exists. MySQL
+// resolves the conflict from the table's unique keys so the columns are only
+// used by Postgres.
+func (d Dialect) Upsert(conflict []string, assignments ...string) string {
+	set := strings.Join(assignments, ",\n\t\t")
+
+	if d == MySQL {
+		return "ON DUPLICATE KEY UPDATE\n\t\t" + set
+	}
+
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET\n\t\t%s", strings.Join(conflict, ", "), set)
+}
+
+// Excluded references the value of the column that was proposed for
+// insertion inside an Upsert assignment.
+func (d Dialect) Excluded(column string) string {
+	if d == MySQL {
+		return "VALUES(" + column + ")"
+	}
+
+	return "EXCLUDED." + column
+}
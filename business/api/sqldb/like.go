@@ -0,0 +1,12 @@
+package sqldb
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern returns the LIKE pattern that matches values containing
+// s, with the wildcards in s escaped so they match literally. Both dialects
+// use the backslash as the default escape character.
+func ContainsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
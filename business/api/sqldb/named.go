@@ -0,0 +1,111 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// The helpers below run a query with :name parameters taken from the data,
+// which is a struct with db tags or a map[string]any. A parameter bound to
+// a slice is expanded for use with IN. The db can be a *sqlx.DB or a
+// *sqlx.Tx, and the placeholders are rebound for its dialect. Errors are
+// passed through TranslateError so stores can test for the package error
+// variables.
+
+// NamedExecContext executes a statement that doesn't return rows.
+func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) error {
+	q, args, err := bind(db, query, data)
+	if err != nil {
+		return err
+	}
+
+	log.Debugc(ctx, 4, "database.NamedExecContext", "query", q)
+
+	if _, err := db.ExecContext(ctx, q, args...); err != nil {
+		return TranslateError(err)
+	}
+
+	return nil
+}
+
+// NamedQuerySlice runs a query and scans every row into the slice.
+func NamedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest *[]T) error {
+	q, args, err := bind(db, query, data)
+	if err != nil {
+		return err
+	}
+
+	log.Debugc(ctx, 4, "database.NamedQuerySlice", "query", q)
+
+	rows, err := db.QueryxContext(ctx, q, args...)
+	if err != nil {
+		return TranslateError(err)
+	}
+	defer rows.Close()
+
+	var slice []T
+	for rows.Next() {
+		v := new(T)
+		if err := rows.StructScan(v); err != nil {
+			return fmt.Errorf("structscan: %w", err)
+		}
+		slice = append(slice, *v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return TranslateError(err)
+	}
+
+	*dest = slice
+
+	return nil
+}
+
+// NamedQueryStruct runs a query and scans the first row into the struct.
+// ErrDBNotFound is returned when there are no rows.
+func NamedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest any) error {
+	q, args, err := bind(db, query, data)
+	if err != nil {
+		return err
+	}
+
+	log.Debugc(ctx, 4, "database.NamedQueryStruct", "query", q)
+
+	rows, err := db.QueryxContext(ctx, q, args...)
+	if err != nil {
+		return TranslateError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return TranslateError(err)
+		}
+		return ErrDBNotFound
+	}
+
+	if err := rows.StructScan(dest); err != nil {
+		return fmt.Errorf("structscan: %w", err)
+	}
+
+	return nil
+}
+
+// bind converts the named query to one with positional placeholders for the
+// dialect of the db.
+func bind(db sqlx.ExtContext, query string, data any) (string, []any, error) {
+	q, args, err := sqlx.Named(query, data)
+	if err != nil {
+		return "", nil, fmt.Errorf("named: %w", err)
+	}
+
+	q, args, err = sqlx.In(q, args...)
+	if err != nil {
+		return "", nil, fmt.Errorf("in: %w", err)
+	}
+
+	return db.Rebind(q), args, nil
+}
@@ -0,0 +1,20 @@
+package sqldb
+
+// Tables whose rows are soft deleted carry a nullable deleted_at column
+// that is set instead of removing the row, so the history of what was
+// deleted and when is kept. A soft deleted row is left out of every query
+// unless the caller asks for deleted rows as well, and it is restored by
+// clearing the column.
+
+// NotDeleted is the condition that leaves out soft deleted rows.
+const NotDeleted = "deleted_at IS NULL"
+
+// ExcludeDeleted adds NotDeleted to the conditions of a WHERE clause unless
+// deleted rows were asked for.
+func ExcludeDeleted(wc []string, withDeleted bool) []string {
+	if withDeleted {
+		return wc
+	}
+
+	return append(wc, NotDeleted)
+}
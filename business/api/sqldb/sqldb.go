@@ -5,9 +5,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/url"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 )
@@ -19,13 +22,48 @@ const (
 	undefinedTable  = "42P01"
 )
 
+// MySQL server error numbers.
+// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	mysqlDuplicateEntry = 1062
+	mysqlNoSuchTable    = 1146
+)
+
 // Set of error variables for CRUD operations.
 var (
 	ErrDBNotFound        = sql.ErrNoRows
 	ErrDBDuplicatedEntry = errors.New("duplicated entry")
 	ErrUndefinedTable    = errors.New("undefined table")
+	ErrDBVersionConflict = errors.New("version conflict")
 )
 
+// TranslateError maps the driver specific errors for the conditions the
+// stores care about onto the package error variables. Any other error is
+// returned as is.
+func TranslateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return ErrDBDuplicatedEntry
+		case undefinedTable:
+			return ErrUndefinedTable
+		}
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case mysqlDuplicateEntry:
+			return ErrDBDuplicatedEntry
+		case mysqlNoSuchTable:
+			return ErrUndefinedTable
+		}
+	}
+
+	return err
+}
+
 // Config is the required properties to use the database.
 type Config struct {
 	User         string
@@ -33,13 +71,45 @@ type Config struct {
 	HostPort     string
 	Name         string
 	Schema       string
+	Dialect      string
 	MaxIdleConns int
 	MaxOpenConns int
 	DisableTLS   bool
+
+	// ConnMaxLifetime and ConnMaxIdleTime close connections that are older
+	// or have been idle longer, so the pool follows a failover or a load
+	// balancer change. Zero keeps connections open.
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
 }
 
 // Open knows how to open a database connection based on the configuration.
 func Open(cfg Config) (*sqlx.DB, error) {
+	dialect, err := ParseDialect(cfg.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var db *sqlx.DB
+
+	switch dialect {
+	case MySQL:
+		db, err = sqlx.Open("mysql", mysqlDSN(cfg))
+	default:
+		db, err = sqlx.Open("pgx", postgresDSN(cfg))
+	}
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+func postgresDSN(cfg Config) string {
 	sslMode := "require"
 	if cfg.DisableTLS {
 		sslMode = "disable"
@@ -60,14 +130,38 @@ func Open(cfg Config) (*sqlx.DB, error) {
 		RawQuery: q.Encode(),
 	}
 
-	db, err := sqlx.Open("pgx", u.String())
-	if err != nil {
-		return nil, err
+	return u.String()
+}
+
+func mysqlDSN(cfg Config) string {
+	tls := "true"
+	if cfg.DisableTLS {
+		tls = "false"
 	}
-	db.SetMaxIdleConns(cfg.MaxIdleConns)
-	db.SetMaxOpenConns(cfg.MaxOpenConns)
 
-	return db, nil
+	name := cfg.Name
+	if cfg.Schema != "" {
+		name = cfg.Schema
+	}
+
+	mc := mysql.NewConfig()
+	mc.User = cfg.User
+	mc.Passwd = cfg.Password
+	mc.Net = "tcp"
+	mc.Addr = cfg.HostPort
+	mc.DBName = name
+	mc.TLSConfig = tls
+	mc.ParseTime = true
+	mc.Loc = time.UTC
+	mc.MultiStatements = true
+
+	// The stores quote identifiers with double quotes the way Postgres does,
+	// so MySQL needs to be told to treat them as identifiers too.
+	mc.Params = map[string]string{
+		"sql_mode": "CONCAT(@@sql_mode, ',ANSI_QUOTES')",
+	}
+
+	return mc.FormatDSN()
 }
 
 // StatusCheck returns nil if it can successfully talk to the database. It
@@ -103,3 +197,28 @@ func StatusCheck(ctx context.Context, db *sqlx.DB) error {
 	var tmp bool
 	return db.QueryRowContext(ctx, q).Scan(&tmp)
 }
+
+// Prime establishes up to n connections in the pool so the first requests
+// served don't pay the cost of connecting to the database.
+func Prime(ctx context.Context, db *sqlx.DB, n int) error {
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	for range n {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("conn: %w", err)
+		}
+		conns = append(conns, conn)
+
+		if err := conn.PingContext(ctx); err != nil {
+			return fmt.Errorf("ping: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package sqldb_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+)
+
+func Test_ScopeTenant(t *testing.T) {
+	tenantID := uuid.New()
+
+	data := map[string]any{}
+	wc := sqldb.ScopeTenant([]string{"product_id = :product_id"}, data, tenantID)
+
+	exp := "\n\tWHERE\n\t\tproduct_id = :product_id AND\n\t\ttenant_id = :scope_tenant_id"
+	if got := sqldb.Where(wc); got != exp {
+		t.Errorf("Should limit the statement to the tenant, got %q", got)
+	}
+
+	if data["scope_tenant_id"] != tenantID {
+		t.Errorf("Should bind the tenant, got %v", data["scope_tenant_id"])
+	}
+
+	data = map[string]any{}
+	if got := sqldb.Where(sqldb.ScopeTenant(nil, data, uuid.Nil)); got != "" || len(data) != 0 {
+		t.Errorf("Should not limit the statement for no tenant, got %q with %v", got, data)
+	}
+}
+
+// Benchmark_TranslateError measures the error mapping every failed write
+// goes through, for the errors it translates and one it passes through.
+func Benchmark_TranslateError(b *testing.B) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"postgres", fmt.Errorf("namedexeccontext: %w", &pgconn.PgError{Code: "23505"})},
+		{"mysql", fmt.Errorf("namedexeccontext: %w", &mysql.MySQLError{Number: 1062})},
+		{"other", errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				sqldb.TranslateError(tt.err)
+			}
+		})
+	}
+}
+
+// Benchmark_Upsert measures building the upsert clause the stores add to
+// their inserts.
+func Benchmark_Upsert(b *testing.B) {
+	for _, d := range []sqldb.Dialect{sqldb.Postgres, sqldb.MySQL} {
+		b.Run(string(d), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				d.Upsert([]string{"subject", "route", "window_start"},
+					"requests = usage.requests + "+d.Excluded("requests"),
+					"bytes = usage.bytes + "+d.Excluded("bytes"),
+					"errors = usage.errors + "+d.Excluded("errors"),
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package sqldb
+
+import "github.com/google/uuid"
+
+// Tables whose rows belong to a tenant carry a nullable tenant_id column.
+// A statement run for a tenant only sees that tenant's rows, while one run
+// for no tenant, such as by a background job or a service with a single
+// tenant, sees every row including the ones that belong to no tenant.
+
+// ScopeTenant adds the condition that limits a statement to the rows of the
+// tenant to the conditions of a WHERE clause, and the tenant to the data it
+// is bound with as scope_tenant_id. Nothing is added for uuid.Nil, which
+// stands for no tenant.
+func ScopeTenant(wc []string, data map[string]any, tenantID uuid.UUID) []string {
+	if tenantID == uuid.Nil {
+		return wc
+	}
+
+	data["scope_tenant_id"] = tenantID
+
+	return append(wc, "tenant_id = :scope_tenant_id")
+}
@@ -0,0 +1,61 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+)
+
+// DBBeginner implements the transaction.Beginner interface for the
+// database.
+type DBBeginner struct {
+	db *sqlx.DB
+}
+
+// NewBeginner constructs a value that begins transactions on the database.
+func NewBeginner(db *sqlx.DB) *DBBeginner {
+	return &DBBeginner{
+		db: db,
+	}
+}
+
+// Begin implements the transaction.Beginner interface.
+func (b *DBBeginner) Begin(ctx context.Context) (transaction.Transaction, error) {
+	return b.db.BeginTxx(ctx, nil)
+}
+
+// GetExtContext returns the sqlx value a store runs its statements with to
+// take part in the transaction. The transaction must have been begun by a
+// DBBeginner.
+func GetExtContext(tx transaction.Transaction) (sqlx.ExtContext, error) {
+	ec, ok := transaction.Unwrap(tx).(sqlx.ExtContext)
+	if !ok {
+		return nil, fmt.Errorf("transaction value (%T) is not a database transaction", tx)
+	}
+
+	return ec, nil
+}
+
+// WithinTran runs fn with a transaction so a store can make several writes
+// atomically. When the db is already a transaction, because the store is
+// under one, fn takes part in it and the owner of the transaction decides
+// whether it commits.
+func WithinTran(ctx context.Context, db sqlx.ExtContext, fn func(ec sqlx.ExtContext) error) error {
+	sdb, ok := db.(*sqlx.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	f := func(tx transaction.Transaction) error {
+		ec, err := GetExtContext(tx)
+		if err != nil {
+			return err
+		}
+
+		return fn(ec)
+	}
+
+	return transaction.Execute(ctx, NewBeginner(sdb), f)
+}
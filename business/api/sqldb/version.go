@@ -0,0 +1,48 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Tables that are updated with optimistic concurrency carry an INT version
+// column that starts at 1 and is incremented by every update, next to the
+// date_updated column. The caller bumps the version of the row it read and
+// the UPDATE only applies when the stored row is still at the version
+// before it:
+//
+//	UPDATE products SET ..., "version" = :version
+//	WHERE product_id = :product_id AND "version" = :version - 1
+//
+// A row that was changed, or deleted, since it was read is left alone and
+// the statement affects no rows.
+
+// NamedExecVersioned executes an UPDATE that follows the version convention
+// and returns ErrDBVersionConflict when it affects no rows.
+func NamedExecVersioned(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) error {
+	q, args, err := bind(db, query, data)
+	if err != nil {
+		return err
+	}
+
+	log.Debugc(ctx, 4, "database.NamedExecVersioned", "query", q)
+
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rowsaffected: %w", err)
+	}
+
+	if n == 0 {
+		return ErrDBVersionConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package sqldb
+
+import "strings"
+
+// Where joins the conditions of a WHERE clause into the clause, which is
+// empty when there are no conditions.
+func Where(wc []string) string {
+	if len(wc) == 0 {
+		return ""
+	}
+
+	return "\n\tWHERE\n\t\t" + strings.Join(wc, " AND\n\t\t")
+}
@@ -0,0 +1,98 @@
+// Package transaction provides support for running the work of several
+// business cores in a single database transaction, without the layers
+// above the stores knowing what database it is.
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Transaction represents a value that can commit or rollback a transaction.
+type Transaction interface {
+	Commit() error
+	Rollback() error
+}
+
+// Beginner represents a value that can begin a transaction.
+type Beginner interface {
+	Begin(ctx context.Context) (Transaction, error)
+}
+
+// Execute begins a transaction and runs fn with it. The transaction is
+// committed when fn succeeds and rolled back when it fails, so the cores fn
+// moves under the transaction with their ExecuteUnderTransaction methods
+// change the data together or not at all. The functions registered with
+// AfterCommit run once the commit succeeds.
+func Execute(ctx context.Context, bgn Beginner, fn func(tx Transaction) error) error {
+	tx, err := bgn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	htx := hooked{Transaction: tx}
+
+	if err := fn(&htx); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rerr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	for _, f := range htx.afterCommit() {
+		f()
+	}
+
+	return nil
+}
+
+// AfterCommit registers fn to run once the transaction Execute handed out
+// commits, for work that must only see committed changes, such as removing
+// them from a cache. fn never runs when the transaction rolls back. For a
+// transaction that wasn't begun by Execute, fn runs right away.
+func AfterCommit(tx Transaction, fn func()) {
+	htx, ok := tx.(*hooked)
+	if !ok {
+		fn()
+		return
+	}
+
+	htx.mu.Lock()
+	defer htx.mu.Unlock()
+
+	htx.after = append(htx.after, fn)
+}
+
+// Unwrap returns the transaction the Beginner began for the one Execute
+// handed out, for the stores that need the transaction of their database.
+func Unwrap(tx Transaction) Transaction {
+	if htx, ok := tx.(*hooked); ok {
+		return htx.Transaction
+	}
+
+	return tx
+}
+
+// =============================================================================
+
+// hooked is the transaction Execute hands out, which keeps the functions
+// registered to run after it commits.
+type hooked struct {
+	Transaction
+
+	mu    sync.Mutex
+	after []func()
+}
+
+func (h *hooked) afterCommit() []func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.after
+}
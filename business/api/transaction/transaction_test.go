@@ -0,0 +1,79 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mrcruz117/al-service/business/api/transaction"
+)
+
+// Test_AfterCommit checks the functions registered on the transaction run
+// only once it commits, and right away for one not begun by Execute.
+func Test_AfterCommit(t *testing.T) {
+	ctx := context.Background()
+	errFn := errors.New("fn failed")
+
+	tests := []struct {
+		name      string
+		fnErr     error
+		commitErr error
+		ran       bool
+	}{
+		{"committed", nil, nil, true},
+		{"rolled-back", errFn, nil, false},
+		{"commit-failed", nil, errors.New("commit failed"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bgn := beginner{tx: &fakeTx{commitErr: tt.commitErr}}
+
+			var ran bool
+			err := transaction.Execute(ctx, bgn, func(tx transaction.Transaction) error {
+				if transaction.Unwrap(tx) != bgn.tx {
+					t.Errorf("Should unwrap to the transaction that was begun")
+				}
+
+				transaction.AfterCommit(tx, func() { ran = true })
+
+				if ran {
+					t.Errorf("Should not run before the transaction commits")
+				}
+
+				return tt.fnErr
+			})
+
+			if (err != nil) != (tt.fnErr != nil || tt.commitErr != nil) {
+				t.Errorf("Should fail only when fn or the commit fails, got %v", err)
+			}
+			if ran != tt.ran {
+				t.Errorf("Should run after the commit %t, got %t", tt.ran, ran)
+			}
+		})
+	}
+
+	var ran bool
+	transaction.AfterCommit(&fakeTx{}, func() { ran = true })
+
+	if !ran {
+		t.Errorf("Should run right away for a transaction not begun by Execute")
+	}
+}
+
+// =============================================================================
+
+type fakeTx struct {
+	commitErr error
+}
+
+func (tx *fakeTx) Commit() error   { return tx.commitErr }
+func (tx *fakeTx) Rollback() error { return nil }
+
+type beginner struct {
+	tx *fakeTx
+}
+
+func (b beginner) Begin(context.Context) (transaction.Transaction, error) {
+	return b.tx, nil
+}
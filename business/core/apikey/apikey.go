@@ -0,0 +1,134 @@
+// Package apikey provides support for issuing and authenticating the API keys
+// used by machine clients.
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/credential"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("api key not found")
+	ErrInvalidKey = errors.New("api key is invalid")
+	ErrDisabled   = errors.New("api key is disabled")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, key APIKey) error
+	Update(ctx context.Context, key APIKey) error
+	QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error)
+	QueryByPrefix(ctx context.Context, prefix string) (APIKey, error)
+}
+
+// Core manages the set of APIs for api key access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewCore constructs a core for api key access.
+func NewCore(log *logger.Logger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Create issues a new api key. The plain text key is returned only once and
+// can't be recovered afterwards.
+func (c *Core) Create(ctx context.Context, nk NewAPIKey) (APIKey, string, error) {
+	prefix, err := credential.Generate(6)
+	if err != nil {
+		return APIKey{}, "", fmt.Errorf("prefix: %w", err)
+	}
+
+	secret, err := credential.Generate(32)
+	if err != nil {
+		return APIKey{}, "", fmt.Errorf("secret: %w", err)
+	}
+
+	now := time.Now()
+
+	key := APIKey{
+		ID:          uuid.New(),
+		TenantID:    nk.TenantID,
+		Name:        nk.Name,
+		Prefix:      prefix,
+		Hash:        credential.Hash(secret),
+		Roles:       nk.Roles,
+		Enabled:     true,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.storer.Create(ctx, key); err != nil {
+		return APIKey{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return key, prefix + "." + secret, nil
+}
+
+// Disable revokes the specified api key.
+func (c *Core) Disable(ctx context.Context, keyID uuid.UUID) error {
+	key, err := c.storer.QueryByID(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("query: keyID[%s]: %w", keyID, err)
+	}
+
+	key.Enabled = false
+	key.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, key); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the api key by the specified ID.
+func (c *Core) QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error) {
+	key, err := c.storer.QueryByID(ctx, keyID)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("query: keyID[%s]: %w", keyID, err)
+	}
+
+	return key, nil
+}
+
+// Authenticate validates the plain text key and returns the matching enabled
+// api key.
+func (c *Core) Authenticate(ctx context.Context, plain string) (APIKey, error) {
+	prefix, secret, ok := strings.Cut(plain, ".")
+	if !ok || prefix == "" || secret == "" {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	key, err := c.storer.QueryByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return APIKey{}, ErrInvalidKey
+		}
+		return APIKey{}, fmt.Errorf("query: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(credential.Hash(secret))) != 1 {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	if !key.Enabled {
+		return APIKey{}, ErrDisabled
+	}
+
+	return key, nil
+}
@@ -0,0 +1,28 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey represents a key issued to a machine client. Only the hash of the
+// key is ever stored. A key without a tenant is valid for every tenant.
+type APIKey struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	Name        string
+	Prefix      string
+	Hash        string
+	Roles       []string
+	Enabled     bool
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewAPIKey contains information needed to issue a new key.
+type NewAPIKey struct {
+	TenantID uuid.UUID
+	Name     string
+	Roles    []string
+}
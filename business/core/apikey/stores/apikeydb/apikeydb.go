@@ -0,0 +1,123 @@
+// Package apikeydb contains api key related CRUD functionality.
+package apikeydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for api key database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new api key into the database.
+func (s *Store) Create(ctx context.Context, key apikey.APIKey) error {
+	const q = `
+	INSERT INTO api_keys
+		(api_key_id, tenant_id, name, prefix, key_hash, roles, enabled, date_created, date_updated)
+	VALUES
+		(:api_key_id, :tenant_id, :name, :prefix, :key_hash, :roles, :enabled, :date_created, :date_updated)`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces an api key document in the database.
+func (s *Store) Update(ctx context.Context, key apikey.APIKey) error {
+	const q = `
+	UPDATE
+		api_keys
+	SET
+		"name" = :name,
+		"roles" = :roles,
+		"enabled" = :enabled,
+		"date_updated" = :date_updated
+	WHERE
+		api_key_id = :api_key_id`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified api key from the database.
+func (s *Store) QueryByID(ctx context.Context, keyID uuid.UUID) (apikey.APIKey, error) {
+	const q = `
+	SELECT
+		api_key_id, tenant_id, name, prefix, key_hash, roles, enabled, date_created, date_updated
+	FROM
+		api_keys
+	WHERE
+		api_key_id = ?`
+
+	filter, args := tenantFilter(ctx, keyID)
+
+	var dbKey dbAPIKey
+	if err := s.db.GetContext(ctx, &dbKey, s.db.Rebind(q+filter), args...); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return apikey.APIKey{}, fmt.Errorf("getcontext: %w", apikey.ErrNotFound)
+		}
+		return apikey.APIKey{}, fmt.Errorf("getcontext: %w", err)
+	}
+
+	return toCoreAPIKey(dbKey), nil
+}
+
+// QueryByPrefix gets the api key with the specified prefix from the database.
+// When the context is scoped to a tenant only keys of that tenant or keys
+// valid for every tenant are returned.
+func (s *Store) QueryByPrefix(ctx context.Context, prefix string) (apikey.APIKey, error) {
+	const q = `
+	SELECT
+		api_key_id, tenant_id, name, prefix, key_hash, roles, enabled, date_created, date_updated
+	FROM
+		api_keys
+	WHERE
+		prefix = ?`
+
+	filter, args := tenantFilter(ctx, prefix)
+
+	var dbKey dbAPIKey
+	if err := s.db.GetContext(ctx, &dbKey, s.db.Rebind(q+filter), args...); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return apikey.APIKey{}, fmt.Errorf("getcontext: %w", apikey.ErrNotFound)
+		}
+		return apikey.APIKey{}, fmt.Errorf("getcontext: %w", err)
+	}
+
+	return toCoreAPIKey(dbKey), nil
+}
+
+// tenantFilter restricts a query to the keys visible to the tenant the
+// context is scoped to.
+func tenantFilter(ctx context.Context, args ...any) (string, []any) {
+	tenantID, ok := tenant.IDFromContext(ctx)
+	if !ok {
+		return "", args
+	}
+
+	return " AND (tenant_id IS NULL OR tenant_id = ?)", append(args, tenantID)
+}
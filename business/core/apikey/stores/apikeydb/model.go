@@ -0,0 +1,52 @@
+package apikeydb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/sqldb/dbarray"
+	"github.com/mrcruz117/al-service/business/core/apikey"
+)
+
+type dbAPIKey struct {
+	ID          uuid.UUID      `db:"api_key_id"`
+	TenantID    uuid.NullUUID  `db:"tenant_id"`
+	Name        string         `db:"name"`
+	Prefix      string         `db:"prefix"`
+	Hash        string         `db:"key_hash"`
+	Roles       dbarray.String `db:"roles"`
+	Enabled     bool           `db:"enabled"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+}
+
+func toDBAPIKey(key apikey.APIKey) dbAPIKey {
+	return dbAPIKey{
+		ID: key.ID,
+		TenantID: uuid.NullUUID{
+			UUID:  key.TenantID,
+			Valid: key.TenantID != uuid.Nil,
+		},
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Hash:        key.Hash,
+		Roles:       key.Roles,
+		Enabled:     key.Enabled,
+		DateCreated: key.DateCreated.UTC(),
+		DateUpdated: key.DateUpdated.UTC(),
+	}
+}
+
+func toCoreAPIKey(dbKey dbAPIKey) apikey.APIKey {
+	return apikey.APIKey{
+		ID:          dbKey.ID,
+		TenantID:    dbKey.TenantID.UUID,
+		Name:        dbKey.Name,
+		Prefix:      dbKey.Prefix,
+		Hash:        dbKey.Hash,
+		Roles:       dbKey.Roles,
+		Enabled:     dbKey.Enabled,
+		DateCreated: dbKey.DateCreated.In(time.Local),
+		DateUpdated: dbKey.DateUpdated.In(time.Local),
+	}
+}
@@ -0,0 +1,5 @@
+// Package apikeymem contains an in-memory implementation of the api key
+// store for unit tests.
+package apikeymem
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain apikey -unique Prefix
@@ -0,0 +1,172 @@
+// Package audit provides support for recording who did what and when. Entries
+// are buffered in memory and written to storage in batches so auditing never
+// adds latency to a request.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/queuemon"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+)
+
+// Anonymous is the subject recorded when a request carries no claims.
+const Anonymous = "anonymous"
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, entries []Entry) error
+}
+
+// Core manages the set of APIs for audit access.
+type Core struct {
+	log        *logger.Logger
+	storer     Storer
+	batchSize  int
+	maxPending int
+	full       chan struct{}
+
+	mu        sync.Mutex
+	pending   []Entry
+	dropped   int64
+	lastFlush time.Time
+}
+
+// NewCore constructs a core for audit api access. A flush is triggered early
+// once batchSize entries are pending and entries are dropped once maxPending
+// is reached so a storage outage can't exhaust memory.
+func NewCore(log *logger.Logger, storer Storer, batchSize int, maxPending int) *Core {
+	return &Core{
+		log:        log,
+		storer:     storer,
+		batchSize:  batchSize,
+		maxPending: max(maxPending, batchSize),
+		full:       make(chan struct{}, 1),
+		lastFlush:  time.Now(),
+	}
+}
+
+// Record queues the entry to be written with the next batch.
+func (c *Core) Record(entry Entry) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+
+	if entry.DateCreated.IsZero() {
+		entry.DateCreated = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.pending) >= c.maxPending {
+		c.dropped++
+		return
+	}
+
+	c.pending = append(c.pending, entry)
+
+	if len(c.pending) >= c.batchSize {
+		select {
+		case c.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes the pending entries to storage. If the write fails, the
+// entries are put back so they can be retried.
+func (c *Core) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	pending := c.pending
+	dropped := c.dropped
+	c.pending = nil
+	c.dropped = 0
+	c.mu.Unlock()
+
+	if dropped > 0 {
+		c.log.Error(ctx, "audit", "status", "entries dropped", "dropped", dropped)
+	}
+
+	for len(pending) > 0 {
+		n := min(len(pending), c.batchSize)
+
+		if err := c.storer.Create(ctx, pending[:n]); err != nil {
+			c.restore(pending)
+			return fmt.Errorf("create: %w", err)
+		}
+
+		pending = pending[n:]
+	}
+
+	c.mu.Lock()
+	c.lastFlush = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Stats reports the state of the entries waiting to be written for
+// monitoring.
+func (c *Core) Stats(ctx context.Context) (queuemon.Stats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := queuemon.Stats{
+		Depth: int64(len(c.pending)),
+		Lag:   time.Since(c.lastFlush),
+	}
+
+	if len(c.pending) > 0 {
+		stats.OldestAge = time.Since(c.pending[0].DateCreated)
+	}
+
+	return stats, nil
+}
+
+// Run flushes the pending entries at the specified interval, or sooner when a
+// full batch is waiting, until the context is canceled. While the switch is
+// paused entries stay pending, up to the limit, and nothing is written.
+func (c *Core) Run(ctx context.Context, interval time.Duration, sw *subsystem.Switch) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		case <-c.full:
+		}
+
+		if sw.Paused() {
+			continue
+		}
+
+		if err := c.Flush(ctx); err != nil {
+			c.log.Error(ctx, "audit", "status", "flush failed", "msg", err)
+		}
+	}
+}
+
+// restore puts entries that failed to be written back in front of anything
+// recorded since, keeping as many as the limit allows.
+func (c *Core) restore(entries []Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := append(entries, c.pending...)
+	if over := len(merged) - c.maxPending; over > 0 {
+		c.dropped += int64(over)
+		merged = merged[over:]
+	}
+
+	c.pending = merged
+}
@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry represents a single audited request.
+type Entry struct {
+	ID          uuid.UUID
+	Subject     string
+	TenantID    uuid.UUID
+	Method      string
+	Path        string
+	Route       string
+	BodyDigest  string
+	StatusCode  int
+	TraceID     string
+	DateCreated time.Time
+}
@@ -0,0 +1,45 @@
+// Package auditdb contains audit related CRUD functionality.
+package auditdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/core/audit"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for audit database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts the batch of entries into the database with a single
+// statement.
+func (s *Store) Create(ctx context.Context, entries []audit.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	const q = `
+	INSERT INTO audit_log
+		(audit_id, subject, tenant_id, method, path, route, body_digest, status_code, trace_id, date_created)
+	VALUES
+		(:audit_id, :subject, :tenant_id, :method, :path, :route, :body_digest, :status_code, :trace_id, :date_created)`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBEntries(entries)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package auditdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/audit"
+)
+
+type dbEntry struct {
+	ID          uuid.UUID      `db:"audit_id"`
+	Subject     string         `db:"subject"`
+	TenantID    uuid.NullUUID  `db:"tenant_id"`
+	Method      string         `db:"method"`
+	Path        string         `db:"path"`
+	Route       string         `db:"route"`
+	BodyDigest  sql.NullString `db:"body_digest"`
+	StatusCode  int            `db:"status_code"`
+	TraceID     string         `db:"trace_id"`
+	DateCreated time.Time      `db:"date_created"`
+}
+
+func toDBEntries(entries []audit.Entry) []dbEntry {
+	dbEntries := make([]dbEntry, len(entries))
+	for i, e := range entries {
+		dbEntries[i] = dbEntry{
+			ID:      e.ID,
+			Subject: e.Subject,
+			TenantID: uuid.NullUUID{
+				UUID:  e.TenantID,
+				Valid: e.TenantID != uuid.Nil,
+			},
+			Method: e.Method,
+			Path:   e.Path,
+			Route:  e.Route,
+			BodyDigest: sql.NullString{
+				String: e.BodyDigest,
+				Valid:  e.BodyDigest != "",
+			},
+			StatusCode:  e.StatusCode,
+			TraceID:     e.TraceID,
+			DateCreated: e.DateCreated.UTC(),
+		}
+	}
+
+	return dbEntries
+}
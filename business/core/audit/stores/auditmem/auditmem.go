@@ -0,0 +1,16 @@
+// Package auditmem contains an in-memory implementation of the audit store
+// for unit tests.
+package auditmem
+
+import (
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/core/audit"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain audit
+
+// Query returns every entry written so far in the order they were written,
+// so tests can assert on what was audited.
+func (s *Store) Query() []audit.Entry {
+	return s.table.Query(memstore.Query[audit.Entry]{})
+}
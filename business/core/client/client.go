@@ -0,0 +1,121 @@
+// Package client provides support for registering the machine clients that
+// authenticate with their own credentials rather than on behalf of a user.
+package client
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/credential"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound           = errors.New("client not found")
+	ErrInvalidCredentials = errors.New("client credentials are invalid")
+	ErrDisabled           = errors.New("client is disabled")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, cln Client) error
+	Update(ctx context.Context, cln Client) error
+	QueryByID(ctx context.Context, clientID uuid.UUID) (Client, error)
+}
+
+// Core manages the set of APIs for client access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewCore constructs a core for client access.
+func NewCore(log *logger.Logger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Create registers a new client. The plain text secret is returned only once
+// and can't be recovered afterwards.
+func (c *Core) Create(ctx context.Context, nc NewClient) (Client, string, error) {
+	secret, err := credential.Generate(32)
+	if err != nil {
+		return Client{}, "", fmt.Errorf("secret: %w", err)
+	}
+
+	now := time.Now()
+
+	cln := Client{
+		ID:          uuid.New(),
+		Name:        nc.Name,
+		SecretHash:  credential.Hash(secret),
+		Scopes:      nc.Scopes,
+		Enabled:     true,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.storer.Create(ctx, cln); err != nil {
+		return Client{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return cln, secret, nil
+}
+
+// Disable revokes the specified client. Tokens already issued to it stay
+// valid until they expire, which is why they are short lived.
+func (c *Core) Disable(ctx context.Context, clientID uuid.UUID) error {
+	cln, err := c.storer.QueryByID(ctx, clientID)
+	if err != nil {
+		return fmt.Errorf("query: clientID[%s]: %w", clientID, err)
+	}
+
+	cln.Enabled = false
+	cln.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, cln); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the client by the specified ID.
+func (c *Core) QueryByID(ctx context.Context, clientID uuid.UUID) (Client, error) {
+	cln, err := c.storer.QueryByID(ctx, clientID)
+	if err != nil {
+		return Client{}, fmt.Errorf("query: clientID[%s]: %w", clientID, err)
+	}
+
+	return cln, nil
+}
+
+// Authenticate validates the credentials and returns the matching enabled
+// client.
+func (c *Core) Authenticate(ctx context.Context, clientID uuid.UUID, secret string) (Client, error) {
+	cln, err := c.storer.QueryByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Client{}, ErrInvalidCredentials
+		}
+		return Client{}, fmt.Errorf("query: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(cln.SecretHash), []byte(credential.Hash(secret))) != 1 {
+		return Client{}, ErrInvalidCredentials
+	}
+
+	if !cln.Enabled {
+		return Client{}, ErrDisabled
+	}
+
+	return cln, nil
+}
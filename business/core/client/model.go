@@ -0,0 +1,26 @@
+package client
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client represents a machine client, such as another service, that obtains
+// tokens with the client credentials grant. Only the hash of the secret is
+// ever stored.
+type Client struct {
+	ID          uuid.UUID
+	Name        string
+	SecretHash  string
+	Scopes      []string
+	Enabled     bool
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewClient contains information needed to register a client.
+type NewClient struct {
+	Name   string
+	Scopes []string
+}
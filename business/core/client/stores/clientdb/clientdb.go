@@ -0,0 +1,84 @@
+// Package clientdb contains client related CRUD functionality.
+package clientdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/client"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for client database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new client into the database.
+func (s *Store) Create(ctx context.Context, cln client.Client) error {
+	const q = `
+	INSERT INTO service_clients
+		(client_id, name, secret_hash, scopes, enabled, date_created, date_updated)
+	VALUES
+		(:client_id, :name, :secret_hash, :scopes, :enabled, :date_created, :date_updated)`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBClient(cln)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a client document in the database.
+func (s *Store) Update(ctx context.Context, cln client.Client) error {
+	const q = `
+	UPDATE
+		service_clients
+	SET
+		"name" = :name,
+		"scopes" = :scopes,
+		"enabled" = :enabled,
+		"date_updated" = :date_updated
+	WHERE
+		client_id = :client_id`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBClient(cln)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified client from the database.
+func (s *Store) QueryByID(ctx context.Context, clientID uuid.UUID) (client.Client, error) {
+	const q = `
+	SELECT
+		client_id, name, secret_hash, scopes, enabled, date_created, date_updated
+	FROM
+		service_clients
+	WHERE
+		client_id = ?`
+
+	var dbCln dbClient
+	if err := s.db.GetContext(ctx, &dbCln, s.db.Rebind(q), clientID); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return client.Client{}, fmt.Errorf("getcontext: %w", client.ErrNotFound)
+		}
+		return client.Client{}, fmt.Errorf("getcontext: %w", err)
+	}
+
+	return toCoreClient(dbCln), nil
+}
@@ -0,0 +1,43 @@
+package clientdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/sqldb/dbarray"
+	"github.com/mrcruz117/al-service/business/core/client"
+)
+
+type dbClient struct {
+	ID          uuid.UUID      `db:"client_id"`
+	Name        string         `db:"name"`
+	SecretHash  string         `db:"secret_hash"`
+	Scopes      dbarray.String `db:"scopes"`
+	Enabled     bool           `db:"enabled"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+}
+
+func toDBClient(cln client.Client) dbClient {
+	return dbClient{
+		ID:          cln.ID,
+		Name:        cln.Name,
+		SecretHash:  cln.SecretHash,
+		Scopes:      cln.Scopes,
+		Enabled:     cln.Enabled,
+		DateCreated: cln.DateCreated.UTC(),
+		DateUpdated: cln.DateUpdated.UTC(),
+	}
+}
+
+func toCoreClient(dbCln dbClient) client.Client {
+	return client.Client{
+		ID:          dbCln.ID,
+		Name:        dbCln.Name,
+		SecretHash:  dbCln.SecretHash,
+		Scopes:      dbCln.Scopes,
+		Enabled:     dbCln.Enabled,
+		DateCreated: dbCln.DateCreated.In(time.Local),
+		DateUpdated: dbCln.DateUpdated.In(time.Local),
+	}
+}
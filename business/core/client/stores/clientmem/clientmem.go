@@ -0,0 +1,5 @@
+// Package clientmem contains an in-memory implementation of the client
+// store for unit tests.
+package clientmem
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain client
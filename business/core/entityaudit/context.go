@@ -0,0 +1,24 @@
+package entityaudit
+
+import "context"
+
+type ctxKey int
+
+const actorKey ctxKey = 1
+
+// WithActor returns a copy of the context that records changes made with it
+// as made by the actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFromContext returns the actor changes made with the context are
+// recorded for, System when none was set.
+func ActorFromContext(ctx context.Context) string {
+	v, ok := ctx.Value(actorKey).(string)
+	if !ok || v == "" {
+		return System
+	}
+
+	return v
+}
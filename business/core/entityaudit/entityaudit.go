@@ -0,0 +1,120 @@
+// Package entityaudit provides support for recording the changes made to
+// the entities of the system, with snapshots of what an entity looked like
+// before and after each change. The changes are recorded by decorators
+// around the domain stores.
+package entityaudit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// System is the actor recorded for changes made outside of a request.
+const System = "system"
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, chg Change) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]Change, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Core manages the set of APIs for entity audit access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewCore constructs a core for entity audit api access.
+func NewCore(log *logger.Logger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls, so the changes are
+// recorded only when the transaction commits.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	core := Core{
+		log:    c.log,
+		storer: storer,
+	}
+
+	return &core, nil
+}
+
+// Record writes the change to the entity, made by the actor of the context.
+// The snapshots are encoded as JSON and a nil one is left empty. The change
+// has already been made when it is recorded, so a failure to record it is
+// logged rather than returned.
+func (c *Core) Record(ctx context.Context, entity string, entityID string, action string, before any, after any) {
+	chg := Change{
+		ID:          uuid.New(),
+		Entity:      entity,
+		EntityID:    entityID,
+		Action:      action,
+		Actor:       ActorFromContext(ctx),
+		DateCreated: time.Now(),
+	}
+
+	var err error
+	if chg.Before, err = snapshot(before); err != nil {
+		c.log.Error(ctx, "entityaudit", "status", "encoding before", "entity", entity, "entityID", entityID, "msg", err)
+		return
+	}
+
+	if chg.After, err = snapshot(after); err != nil {
+		c.log.Error(ctx, "entityaudit", "status", "encoding after", "entity", entity, "entityID", entityID, "msg", err)
+		return
+	}
+
+	if err := c.storer.Create(ctx, chg); err != nil {
+		c.log.Error(ctx, "entityaudit", "status", "recording change", "entity", entity, "entityID", entityID, "msg", err)
+	}
+}
+
+// Query retrieves a page of changes matching the filter, in the order asked
+// for.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]Change, error) {
+	chgs, err := c.storer.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return chgs, nil
+}
+
+// Count returns the total number of changes matching the filter.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	n, err := c.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+
+	return n, nil
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,29 @@
+package entityaudit
+
+import (
+	"errors"
+	"time"
+)
+
+// QueryFilter holds the available fields a query can be filtered on. Fields
+// that are nil aren't filtered on.
+type QueryFilter struct {
+	Entity           *string
+	EntityID         *string
+	Actor            *string
+	StartCreatedDate *time.Time
+	EndCreatedDate   *time.Time
+}
+
+// Validate checks the filter is considered clean before it is used.
+func (qf QueryFilter) Validate() error {
+	if qf.EntityID != nil && qf.Entity == nil {
+		return errors.New("entity id filter requires an entity filter")
+	}
+
+	if qf.StartCreatedDate != nil && qf.EndCreatedDate != nil && qf.EndCreatedDate.Before(*qf.StartCreatedDate) {
+		return errors.New("end created date must not be before start created date")
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package entityaudit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of actions a change can record.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// Change represents a single change made to an entity, with snapshots of
+// the entity before and after it. Before is empty when the entity was
+// created and After when it was hard deleted.
+type Change struct {
+	ID          uuid.UUID
+	Entity      string
+	EntityID    string
+	Action      string
+	Actor       string
+	Before      json.RawMessage
+	After       json.RawMessage
+	DateCreated time.Time
+}
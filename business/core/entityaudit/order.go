@@ -0,0 +1,14 @@
+package entityaudit
+
+import "github.com/mrcruz117/al-service/business/api/order"
+
+// DefaultOrderBy represents the default way we sort, newest change first.
+var DefaultOrderBy = order.NewBy(OrderByDateCreated, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID          = "id"
+	OrderByEntity      = "entity"
+	OrderByActor       = "actor"
+	OrderByDateCreated = "date_created"
+)
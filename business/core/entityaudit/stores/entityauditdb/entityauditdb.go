@@ -0,0 +1,110 @@
+// Package entityauditdb contains entity audit related CRUD functionality.
+package entityauditdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for entity audit database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (entityaudit.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new change into the database.
+func (s *Store) Create(ctx context.Context, chg entityaudit.Change) error {
+	const q = `
+	INSERT INTO entity_audit
+		(change_id, entity, entity_id, action, actor, before_data, after_data, date_created)
+	VALUES
+		(:change_id, :entity, :entity_id, :action, :actor, :before_data, :after_data, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBChange(chg)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a page of changes from the database.
+func (s *Store) Query(ctx context.Context, filter entityaudit.QueryFilter, orderBy order.By, pg page.Page) ([]entityaudit.Change, error) {
+	data := map[string]any{
+		"offset":        pg.Offset(),
+		"rows_per_page": pg.RowsPerPage(),
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+	SELECT
+		change_id, entity, entity_id, action, actor, before_data, after_data, date_created
+	FROM
+		entity_audit` + applyFilter(filter, data) + `
+	ORDER BY
+		` + orderByClause + `
+	LIMIT :rows_per_page OFFSET :offset`
+
+	var dbChgs []dbChange
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbChgs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreChangeSlice(dbChgs), nil
+}
+
+// Count returns the total number of changes in the database matching the
+// filter.
+func (s *Store) Count(ctx context.Context, filter entityaudit.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	q := `
+	SELECT
+		count(1) AS "count"
+	FROM
+		entity_audit` + applyFilter(filter, data)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
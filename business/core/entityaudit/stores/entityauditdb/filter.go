@@ -0,0 +1,45 @@
+package entityauditdb
+
+import (
+	"strings"
+
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+)
+
+// applyFilter returns the WHERE clause for the filter and adds the values of
+// its parameters to the data. The clause is empty when nothing is filtered
+// on.
+func applyFilter(filter entityaudit.QueryFilter, data map[string]any) string {
+	var wc []string
+
+	if filter.Entity != nil {
+		data["entity"] = *filter.Entity
+		wc = append(wc, "entity = :entity")
+	}
+
+	if filter.EntityID != nil {
+		data["entity_id"] = *filter.EntityID
+		wc = append(wc, "entity_id = :entity_id")
+	}
+
+	if filter.Actor != nil {
+		data["actor"] = *filter.Actor
+		wc = append(wc, "actor = :actor")
+	}
+
+	if filter.StartCreatedDate != nil {
+		data["start_date_created"] = filter.StartCreatedDate.UTC()
+		wc = append(wc, "date_created >= :start_date_created")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_date_created"] = filter.EndCreatedDate.UTC()
+		wc = append(wc, "date_created <= :end_date_created")
+	}
+
+	if len(wc) == 0 {
+		return ""
+	}
+
+	return "\n\tWHERE\n\t\t" + strings.Join(wc, " AND\n\t\t")
+}
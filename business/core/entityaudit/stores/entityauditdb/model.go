@@ -0,0 +1,70 @@
+package entityauditdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+)
+
+type dbChange struct {
+	ID          uuid.UUID      `db:"change_id"`
+	Entity      string         `db:"entity"`
+	EntityID    string         `db:"entity_id"`
+	Action      string         `db:"action"`
+	Actor       string         `db:"actor"`
+	Before      sql.NullString `db:"before_data"`
+	After       sql.NullString `db:"after_data"`
+	DateCreated time.Time      `db:"date_created"`
+}
+
+func toDBChange(chg entityaudit.Change) dbChange {
+	return dbChange{
+		ID:       chg.ID,
+		Entity:   chg.Entity,
+		EntityID: chg.EntityID,
+		Action:   chg.Action,
+		Actor:    chg.Actor,
+		Before: sql.NullString{
+			String: string(chg.Before),
+			Valid:  len(chg.Before) > 0,
+		},
+		After: sql.NullString{
+			String: string(chg.After),
+			Valid:  len(chg.After) > 0,
+		},
+		DateCreated: chg.DateCreated.UTC(),
+	}
+}
+
+func toCoreChange(dbChg dbChange) entityaudit.Change {
+	chg := entityaudit.Change{
+		ID:          dbChg.ID,
+		Entity:      dbChg.Entity,
+		EntityID:    dbChg.EntityID,
+		Action:      dbChg.Action,
+		Actor:       dbChg.Actor,
+		DateCreated: dbChg.DateCreated.In(time.Local),
+	}
+
+	if dbChg.Before.Valid {
+		chg.Before = json.RawMessage(dbChg.Before.String)
+	}
+
+	if dbChg.After.Valid {
+		chg.After = json.RawMessage(dbChg.After.String)
+	}
+
+	return chg
+}
+
+func toCoreChangeSlice(dbChanges []dbChange) []entityaudit.Change {
+	chgs := make([]entityaudit.Change, len(dbChanges))
+	for i, dbChg := range dbChanges {
+		chgs[i] = toCoreChange(dbChg)
+	}
+
+	return chgs
+}
@@ -0,0 +1,35 @@
+package entityauditdb
+
+import (
+	"fmt"
+
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+)
+
+var orderByFields = map[string]string{
+	entityaudit.OrderByID:          "change_id",
+	entityaudit.OrderByEntity:      "entity",
+	entityaudit.OrderByActor:       "actor",
+	entityaudit.OrderByDateCreated: "date_created",
+}
+
+// orderByClause translates the order to SQL, breaking ties by id so pages
+// don't overlap.
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	if orderBy.Direction != order.ASC && orderBy.Direction != order.DESC {
+		return "", fmt.Errorf("direction %q does not exist", orderBy.Direction)
+	}
+
+	clause := by + " " + orderBy.Direction
+	if by != "change_id" {
+		clause += ", change_id"
+	}
+
+	return clause, nil
+}
@@ -0,0 +1,93 @@
+// Package entityauditmem contains an in-memory implementation of the entity
+// audit store for unit tests.
+package entityauditmem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain entityaudit
+
+// Query retrieves a page of the changes matching the filter, ordered like
+// the database store.
+func (s *Store) Query(ctx context.Context, filter entityaudit.QueryFilter, orderBy order.By, pg page.Page) ([]entityaudit.Change, error) {
+	less, err := lessBy(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	chgs := s.table.Query(memstore.Query[entityaudit.Change]{
+		Match:  match(filter),
+		Less:   less,
+		Offset: pg.Offset(),
+		Limit:  pg.RowsPerPage(),
+	})
+
+	return chgs, nil
+}
+
+// Count returns the total number of changes matching the filter.
+func (s *Store) Count(ctx context.Context, filter entityaudit.QueryFilter) (int, error) {
+	return s.table.Count(match(filter)), nil
+}
+
+func match(filter entityaudit.QueryFilter) func(entityaudit.Change) bool {
+	return func(chg entityaudit.Change) bool {
+		switch {
+		case filter.Entity != nil && chg.Entity != *filter.Entity:
+			return false
+		case filter.EntityID != nil && chg.EntityID != *filter.EntityID:
+			return false
+		case filter.Actor != nil && chg.Actor != *filter.Actor:
+			return false
+		case filter.StartCreatedDate != nil && chg.DateCreated.Before(*filter.StartCreatedDate):
+			return false
+		case filter.EndCreatedDate != nil && chg.DateCreated.After(*filter.EndCreatedDate):
+			return false
+		}
+
+		return true
+	}
+}
+
+// lessBy orders like the database store, where rows that tie are ordered
+// by id ascending whatever the direction.
+func lessBy(orderBy order.By) (func(a entityaudit.Change, b entityaudit.Change) bool, error) {
+	var compare func(a entityaudit.Change, b entityaudit.Change) int
+
+	switch orderBy.Field {
+	case entityaudit.OrderByID:
+		compare = func(a entityaudit.Change, b entityaudit.Change) int {
+			return strings.Compare(a.ID.String(), b.ID.String())
+		}
+	case entityaudit.OrderByEntity:
+		compare = func(a entityaudit.Change, b entityaudit.Change) int { return strings.Compare(a.Entity, b.Entity) }
+	case entityaudit.OrderByActor:
+		compare = func(a entityaudit.Change, b entityaudit.Change) int { return strings.Compare(a.Actor, b.Actor) }
+	case entityaudit.OrderByDateCreated:
+		compare = func(a entityaudit.Change, b entityaudit.Change) int { return a.DateCreated.Compare(b.DateCreated) }
+	default:
+		return nil, fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	less := func(a entityaudit.Change, b entityaudit.Change) bool {
+		c := compare(a, b)
+		if orderBy.Direction == order.DESC {
+			c = -c
+		}
+
+		if c == 0 {
+			return a.ID.String() < b.ID.String()
+		}
+		return c < 0
+	}
+
+	return less, nil
+}
@@ -0,0 +1,68 @@
+// Package event provides support for recording domain events durably and
+// replaying them into a consumer, such as to rebuild a projection after a bug
+// or to redeliver to a webhook target.
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, evt Event) error
+	Query(ctx context.Context, filter QueryFilter, limit int) ([]Event, error)
+}
+
+// Core manages the set of APIs for event access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewCore constructs a core for event api access.
+func NewCore(log *logger.Logger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Record stores an event of the specified type with the data encoded as
+// JSON. A nil tenant id records an event that isn't scoped to a tenant.
+func (c *Core) Record(ctx context.Context, typ string, tenantID uuid.UUID, data any) (Event, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal: %w", err)
+	}
+
+	evt := Event{
+		ID:          uuid.New(),
+		Type:        typ,
+		TenantID:    tenantID,
+		Data:        b,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, evt); err != nil {
+		return Event{}, fmt.Errorf("create: %w", err)
+	}
+
+	return evt, nil
+}
+
+// Query returns up to limit events matching the filter.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, limit int) ([]Event, error) {
+	evts, err := c.storer.Query(ctx, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return evts, nil
+}
@@ -0,0 +1,37 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event represents something that happened in a domain, kept so it can be
+// replayed later.
+type Event struct {
+	Seq         int64
+	ID          uuid.UUID
+	Type        string
+	TenantID    uuid.UUID
+	Data        json.RawMessage
+	DateCreated time.Time
+}
+
+// QueryFilter selects the events to return. Events are always returned in
+// the order they were recorded.
+type QueryFilter struct {
+
+	// After only returns the events recorded after this sequence number.
+	After int64
+
+	// Through, when positive, only returns events up to and including this
+	// sequence number.
+	Through int64
+
+	// Types, when set, only returns the events of these types.
+	Types []string
+
+	// TenantID, when set, only returns the events of this tenant.
+	TenantID uuid.UUID
+}
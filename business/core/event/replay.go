@@ -0,0 +1,100 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Consumer represents the destination events are replayed into.
+type Consumer interface {
+	Consume(ctx context.Context, evt Event) error
+}
+
+// ConsumerFunc is an adapter to allow a function to be used as a Consumer.
+type ConsumerFunc func(ctx context.Context, evt Event) error
+
+// Consume calls f(ctx, evt).
+func (f ConsumerFunc) Consume(ctx context.Context, evt Event) error {
+	return f(ctx, evt)
+}
+
+// Progress reports how far a replay has come. LastSeq can be passed as
+// the filter's After to resume a replay that stopped.
+type Progress struct {
+	Replayed int
+	LastSeq  int64
+	Elapsed  time.Duration
+}
+
+// ReplayConfig represents the settings of a replay.
+type ReplayConfig struct {
+
+	// Filter selects the events to replay.
+	Filter QueryFilter
+
+	// BatchSize is the number of events read from storage at a time.
+	BatchSize int
+
+	// Rate limits the events delivered per second. Zero doesn't limit.
+	Rate float64
+
+	// Progress, when set, is called after every batch.
+	Progress func(Progress)
+}
+
+// Replay delivers the events matching the filter to the consumer in the
+// order they were recorded. It stops at the first event the consumer fails
+// to take, reporting the progress made up to that event.
+func (c *Core) Replay(ctx context.Context, cfg ReplayConfig, consumer Consumer) (Progress, error) {
+	start := time.Now()
+	filter := cfg.Filter
+	batchSize := max(cfg.BatchSize, 1)
+
+	var prog Progress
+	prog.LastSeq = filter.After
+
+	var tick <-chan time.Time
+	if cfg.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		filter.After = prog.LastSeq
+
+		evts, err := c.storer.Query(ctx, filter, batchSize)
+		if err != nil {
+			return prog, fmt.Errorf("query: %w", err)
+		}
+
+		for _, evt := range evts {
+			if tick != nil {
+				select {
+				case <-ctx.Done():
+					return prog, ctx.Err()
+				case <-tick:
+				}
+			}
+
+			if err := consumer.Consume(ctx, evt); err != nil {
+				prog.Elapsed = time.Since(start)
+				return prog, fmt.Errorf("consume[%d]: %w", evt.Seq, err)
+			}
+
+			prog.Replayed++
+			prog.LastSeq = evt.Seq
+		}
+
+		prog.Elapsed = time.Since(start)
+
+		if cfg.Progress != nil && len(evts) > 0 {
+			cfg.Progress(prog)
+		}
+
+		if len(evts) < batchSize {
+			return prog, nil
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package eventdb contains event related CRUD functionality.
+package eventdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/core/event"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for event database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new event into the database. The sequence number is
+// assigned by the database.
+func (s *Store) Create(ctx context.Context, evt event.Event) error {
+	const q = `
+	INSERT INTO domain_events
+		(event_id, type, tenant_id, data, date_created)
+	VALUES
+		(:event_id, :type, :tenant_id, :data, :date_created)`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBEvent(evt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves up to limit events matching the filter in sequence order.
+func (s *Store) Query(ctx context.Context, filter event.QueryFilter, limit int) ([]event.Event, error) {
+	where := []string{"seq > ?"}
+	args := []any{filter.After}
+
+	if filter.Through > 0 {
+		where = append(where, "seq <= ?")
+		args = append(args, filter.Through)
+	}
+
+	if len(filter.Types) > 0 {
+		where = append(where, "type IN (?)")
+		args = append(args, filter.Types)
+	}
+
+	if filter.TenantID != uuid.Nil {
+		where = append(where, "tenant_id = ?")
+		args = append(args, filter.TenantID)
+	}
+
+	args = append(args, limit)
+
+	q := `
+	SELECT
+		seq, event_id, type, tenant_id, data, date_created
+	FROM
+		domain_events
+	WHERE
+		` + strings.Join(where, " AND ") + `
+	ORDER BY
+		seq
+	LIMIT ?`
+
+	query, args, err := sqlx.In(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("in: %w", err)
+	}
+
+	var dbEvts []dbEvent
+	if err := s.db.SelectContext(ctx, &dbEvts, s.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("selectcontext: %w", err)
+	}
+
+	evts := make([]event.Event, len(dbEvts))
+	for i, dbEvt := range dbEvts {
+		evts[i] = toCoreEvent(dbEvt)
+	}
+
+	return evts, nil
+}
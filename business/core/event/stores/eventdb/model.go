@@ -0,0 +1,41 @@
+package eventdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/event"
+)
+
+type dbEvent struct {
+	Seq         int64         `db:"seq"`
+	ID          uuid.UUID     `db:"event_id"`
+	Type        string        `db:"type"`
+	TenantID    uuid.NullUUID `db:"tenant_id"`
+	Data        string        `db:"data"`
+	DateCreated time.Time     `db:"date_created"`
+}
+
+func toDBEvent(evt event.Event) dbEvent {
+	return dbEvent{
+		ID:   evt.ID,
+		Type: evt.Type,
+		TenantID: uuid.NullUUID{
+			UUID:  evt.TenantID,
+			Valid: evt.TenantID != uuid.Nil,
+		},
+		Data:        string(evt.Data),
+		DateCreated: evt.DateCreated.UTC(),
+	}
+}
+
+func toCoreEvent(dbEvt dbEvent) event.Event {
+	return event.Event{
+		Seq:         dbEvt.Seq,
+		ID:          dbEvt.ID,
+		Type:        dbEvt.Type,
+		TenantID:    dbEvt.TenantID.UUID,
+		Data:        []byte(dbEvt.Data),
+		DateCreated: dbEvt.DateCreated.In(time.Local),
+	}
+}
@@ -0,0 +1,78 @@
+// Package eventmem contains an in-memory implementation of the event store
+// for unit tests. Sequence numbers are assigned by the store, like the
+// database does, so it is written by hand rather than generated.
+package eventmem
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/core/event"
+)
+
+// Store manages the set of APIs for event in-memory access.
+type Store struct {
+	mu    sync.Mutex
+	seq   int64
+	table *memstore.Table[int64, event.Event]
+}
+
+// NewStore constructs the api for in-memory access.
+func NewStore() *Store {
+	return &Store{
+		table: memstore.New(
+			func(evt event.Event) int64 { return evt.Seq },
+			memstore.Unique[event.Event]{Key: func(evt event.Event) any { return evt.ID }},
+		),
+	}
+}
+
+var _ event.Storer = (*Store)(nil)
+
+// Create inserts the event into the store with the next sequence number.
+func (s *Store) Create(ctx context.Context, evt event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Like a sequence in the database, a number isn't reused when the
+	// insert fails.
+	s.seq++
+	evt.Seq = s.seq
+
+	if err := s.table.Insert(evt); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves up to limit events matching the filter in sequence order.
+func (s *Store) Query(ctx context.Context, filter event.QueryFilter, limit int) ([]event.Event, error) {
+	match := func(evt event.Event) bool {
+		switch {
+		case evt.Seq <= filter.After:
+			return false
+		case filter.Through > 0 && evt.Seq > filter.Through:
+			return false
+		case len(filter.Types) > 0 && !slices.Contains(filter.Types, evt.Type):
+			return false
+		case filter.TenantID != uuid.Nil && evt.TenantID != filter.TenantID:
+			return false
+		}
+		return true
+	}
+
+	evts := s.table.Query(memstore.Query[event.Event]{
+		Match: match,
+		Less: func(a event.Event, b event.Event) bool {
+			return a.Seq < b.Seq
+		},
+		Limit: limit,
+	})
+
+	return evts, nil
+}
@@ -0,0 +1,179 @@
+// Package group provides business access to the groups that bind users to
+// roles.
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound          = errors.New("group not found")
+	ErrUniqueDisplayName = errors.New("display name is not unique")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, grp Group) error
+	Update(ctx context.Context, grp Group) error
+	Delete(ctx context.Context, grp Group) error
+	QueryByID(ctx context.Context, groupID uuid.UUID) (Group, error)
+	QueryByMember(ctx context.Context, userID uuid.UUID) ([]Group, error)
+}
+
+// Core manages the set of APIs for group access.
+type Core struct {
+	log      *logger.Logger
+	storer   Storer
+	userCore *user.Core
+	bindings map[string]string
+}
+
+// NewCore constructs a core for group api access. The bindings map a group
+// display name, compared case insensitively, to the role its members hold.
+func NewCore(log *logger.Logger, storer Storer, userCore *user.Core, bindings map[string]string) *Core {
+	b := make(map[string]string, len(bindings))
+	for name, role := range bindings {
+		b[strings.ToLower(name)] = role
+	}
+
+	return &Core{
+		log:      log,
+		storer:   storer,
+		userCore: userCore,
+		bindings: b,
+	}
+}
+
+// Create adds a new group to the system and grants its role to the members.
+func (c *Core) Create(ctx context.Context, ng NewGroup) (Group, error) {
+	now := time.Now()
+
+	grp := Group{
+		ID:          uuid.New(),
+		DisplayName: ng.DisplayName,
+		ExternalID:  ng.ExternalID,
+		Role:        c.bindings[strings.ToLower(ng.DisplayName)],
+		Members:     ng.Members,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.storer.Create(ctx, grp); err != nil {
+		return Group{}, fmt.Errorf("create: %w", err)
+	}
+
+	if err := c.syncRoles(ctx, grp.Members); err != nil {
+		return Group{}, fmt.Errorf("sync roles: %w", err)
+	}
+
+	return grp, nil
+}
+
+// Update modifies information about a group and recalculates the roles of
+// every user that joined or left it.
+func (c *Core) Update(ctx context.Context, grp Group, ug UpdateGroup) (Group, error) {
+	affected := slices.Clone(grp.Members)
+
+	if ug.DisplayName != nil {
+		grp.DisplayName = *ug.DisplayName
+		grp.Role = c.bindings[strings.ToLower(grp.DisplayName)]
+	}
+
+	if ug.ExternalID != nil {
+		grp.ExternalID = *ug.ExternalID
+	}
+
+	if ug.Members != nil {
+		grp.Members = ug.Members
+		affected = append(affected, ug.Members...)
+	}
+
+	grp.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, grp); err != nil {
+		return Group{}, fmt.Errorf("update: %w", err)
+	}
+
+	if err := c.syncRoles(ctx, affected); err != nil {
+		return Group{}, fmt.Errorf("sync roles: %w", err)
+	}
+
+	return grp, nil
+}
+
+// Delete removes the specified group and revokes its role from the members.
+func (c *Core) Delete(ctx context.Context, grp Group) error {
+	if err := c.storer.Delete(ctx, grp); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	if err := c.syncRoles(ctx, grp.Members); err != nil {
+		return fmt.Errorf("sync roles: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the group by the specified ID.
+func (c *Core) QueryByID(ctx context.Context, groupID uuid.UUID) (Group, error) {
+	grp, err := c.storer.QueryByID(ctx, groupID)
+	if err != nil {
+		return Group{}, fmt.Errorf("query: groupID[%s]: %w", groupID, err)
+	}
+
+	return grp, nil
+}
+
+// =============================================================================
+
+// syncRoles sets the roles of each user to the roles bound to the groups the
+// user is a member of.
+func (c *Core) syncRoles(ctx context.Context, userIDs []uuid.UUID) error {
+	userIDs = slices.Clone(userIDs)
+	slices.SortFunc(userIDs, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, userID := range slices.Compact(userIDs) {
+		groups, err := c.storer.QueryByMember(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("query member: userID[%s]: %w", userID, err)
+		}
+
+		roles := []string{}
+		for _, grp := range groups {
+			if grp.Role != "" && !slices.Contains(roles, grp.Role) {
+				roles = append(roles, grp.Role)
+			}
+		}
+		slices.Sort(roles)
+
+		usr, err := c.userCore.QueryByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if slices.Equal(usr.Roles, roles) {
+			continue
+		}
+
+		if _, err := c.userCore.Update(ctx, usr, user.UpdateUser{Roles: roles}); err != nil {
+			return err
+		}
+
+		c.log.Info(ctx, "group", "status", "roles updated", "userID", userID, "roles", roles)
+	}
+
+	return nil
+}
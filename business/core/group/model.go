@@ -0,0 +1,34 @@
+package group
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group represents a set of users provisioned by an identity provider. The
+// role is derived from the display name and granted to every member.
+type Group struct {
+	ID          uuid.UUID
+	DisplayName string
+	ExternalID  string
+	Role        string
+	Members     []uuid.UUID
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewGroup contains information needed to create a new group.
+type NewGroup struct {
+	DisplayName string
+	ExternalID  string
+	Members     []uuid.UUID
+}
+
+// UpdateGroup contains information needed to update a group. Fields that
+// are nil are left unchanged and Members replaces the full membership.
+type UpdateGroup struct {
+	DisplayName *string
+	ExternalID  *string
+	Members     []uuid.UUID
+}
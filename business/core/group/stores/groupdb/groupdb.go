@@ -0,0 +1,208 @@
+// Package groupdb contains group related CRUD functionality.
+package groupdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/group"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for group database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new group and its members into the database.
+func (s *Store) Create(ctx context.Context, grp group.Group) error {
+	const q = `
+	INSERT INTO scim_groups
+		(group_id, display_name, external_id, role, date_created, date_updated)
+	VALUES
+		(:group_id, :display_name, :external_id, :role, :date_created, :date_updated)`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExecContext(ctx, q, toDBGroup(grp)); err != nil {
+		if errors.Is(sqldb.TranslateError(err), sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", group.ErrUniqueDisplayName)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	if err := insertMembers(ctx, tx, grp); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a group document and its members in the database.
+func (s *Store) Update(ctx context.Context, grp group.Group) error {
+	const q = `
+	UPDATE
+		scim_groups
+	SET
+		"display_name" = :display_name,
+		"external_id" = :external_id,
+		"role" = :role,
+		"date_updated" = :date_updated
+	WHERE
+		group_id = :group_id`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExecContext(ctx, q, toDBGroup(grp)); err != nil {
+		if errors.Is(sqldb.TranslateError(err), sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", group.ErrUniqueDisplayName)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM scim_group_members WHERE group_id = ?`), grp.ID); err != nil {
+		return fmt.Errorf("delete members: %w", err)
+	}
+
+	if err := insertMembers(ctx, tx, grp); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a group and its members from the database.
+func (s *Store) Delete(ctx context.Context, grp group.Group) error {
+	const q = `
+	DELETE FROM
+		scim_groups
+	WHERE
+		group_id = ?`
+
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind(q), grp.ID); err != nil {
+		return fmt.Errorf("execcontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified group and its members from the database.
+func (s *Store) QueryByID(ctx context.Context, groupID uuid.UUID) (group.Group, error) {
+	const q = `
+	SELECT
+		group_id, display_name, external_id, role, date_created, date_updated
+	FROM
+		scim_groups
+	WHERE
+		group_id = ?`
+
+	var dbGrp dbGroup
+	if err := s.db.GetContext(ctx, &dbGrp, s.db.Rebind(q), groupID); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return group.Group{}, fmt.Errorf("getcontext: %w", group.ErrNotFound)
+		}
+		return group.Group{}, fmt.Errorf("getcontext: %w", err)
+	}
+
+	members, err := s.queryMembers(ctx, groupID)
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	return toCoreGroup(dbGrp, members), nil
+}
+
+// QueryByMember gets the groups the specified user is a member of. The
+// members of the returned groups are not loaded.
+func (s *Store) QueryByMember(ctx context.Context, userID uuid.UUID) ([]group.Group, error) {
+	const q = `
+	SELECT
+		g.group_id, g.display_name, g.external_id, g.role, g.date_created, g.date_updated
+	FROM
+		scim_groups AS g
+	JOIN
+		scim_group_members AS m ON m.group_id = g.group_id
+	WHERE
+		m.user_id = ?`
+
+	var dbGrps []dbGroup
+	if err := s.db.SelectContext(ctx, &dbGrps, s.db.Rebind(q), userID); err != nil {
+		return nil, fmt.Errorf("selectcontext: %w", err)
+	}
+
+	grps := make([]group.Group, len(dbGrps))
+	for i, dbGrp := range dbGrps {
+		grps[i] = toCoreGroup(dbGrp, nil)
+	}
+
+	return grps, nil
+}
+
+// =============================================================================
+
+func (s *Store) queryMembers(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	const q = `
+	SELECT
+		group_id, user_id
+	FROM
+		scim_group_members
+	WHERE
+		group_id = ?`
+
+	var dbMembers []dbMember
+	if err := s.db.SelectContext(ctx, &dbMembers, s.db.Rebind(q), groupID); err != nil {
+		return nil, fmt.Errorf("selectcontext: %w", err)
+	}
+
+	members := make([]uuid.UUID, len(dbMembers))
+	for i, m := range dbMembers {
+		members[i] = m.UserID
+	}
+
+	return members, nil
+}
+
+func insertMembers(ctx context.Context, tx *sqlx.Tx, grp group.Group) error {
+	const q = `
+	INSERT INTO scim_group_members
+		(group_id, user_id)
+	VALUES
+		(:group_id, :user_id)`
+
+	for _, userID := range grp.Members {
+		if _, err := tx.NamedExecContext(ctx, q, dbMember{GroupID: grp.ID, UserID: userID}); err != nil {
+			return fmt.Errorf("insert member[%s]: %w", userID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package groupdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/group"
+)
+
+type dbGroup struct {
+	ID          uuid.UUID      `db:"group_id"`
+	DisplayName string         `db:"display_name"`
+	ExternalID  sql.NullString `db:"external_id"`
+	Role        string         `db:"role"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+}
+
+type dbMember struct {
+	GroupID uuid.UUID `db:"group_id"`
+	UserID  uuid.UUID `db:"user_id"`
+}
+
+func toDBGroup(grp group.Group) dbGroup {
+	return dbGroup{
+		ID:          grp.ID,
+		DisplayName: grp.DisplayName,
+		ExternalID: sql.NullString{
+			String: grp.ExternalID,
+			Valid:  grp.ExternalID != "",
+		},
+		Role:        grp.Role,
+		DateCreated: grp.DateCreated.UTC(),
+		DateUpdated: grp.DateUpdated.UTC(),
+	}
+}
+
+func toCoreGroup(dbGrp dbGroup, members []uuid.UUID) group.Group {
+	return group.Group{
+		ID:          dbGrp.ID,
+		DisplayName: dbGrp.DisplayName,
+		ExternalID:  dbGrp.ExternalID.String,
+		Role:        dbGrp.Role,
+		Members:     members,
+		DateCreated: dbGrp.DateCreated.In(time.Local),
+		DateUpdated: dbGrp.DateUpdated.In(time.Local),
+	}
+}
@@ -0,0 +1,30 @@
+// Package groupmem contains an in-memory implementation of the group store
+// for unit tests.
+package groupmem
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/core/group"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain group -unique DisplayName=ErrUniqueDisplayName
+
+// QueryByMember retrieves the groups the user is a member of. Like the
+// database store, the members of the groups are not loaded.
+func (s *Store) QueryByMember(ctx context.Context, userID uuid.UUID) ([]group.Group, error) {
+	grps := s.table.Query(memstore.Query[group.Group]{
+		Match: func(grp group.Group) bool {
+			return slices.Contains(grp.Members, userID)
+		},
+	})
+
+	for i := range grps {
+		grps[i].Members = nil
+	}
+
+	return grps, nil
+}
@@ -0,0 +1,125 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+// DomainName represents the name of this domain for delegate calls.
+const DomainName = "order"
+
+// Set of delegate actions the order core calls.
+const (
+	// ActionPlaced is called after an order is placed, with the parameters
+	// in an ActionPlacedParms.
+	ActionPlaced = "placed"
+)
+
+// ActionPlacedParms represents the parameters of the placed action. It
+// describes the order as it was placed, priced and pending.
+type ActionPlacedParms struct {
+	OrderID  uuid.UUID          `json:"orderID"`
+	TenantID uuid.UUID          `json:"tenantID,omitzero"`
+	UserID   uuid.UUID          `json:"userID"`
+	Total    float64            `json:"total"`
+	Items    []ActionPlacedItem `json:"items"`
+}
+
+// ActionPlacedItem represents a line of the order in an ActionPlacedParms.
+type ActionPlacedItem struct {
+	ProductID uuid.UUID `json:"productID"`
+	Quantity  int       `json:"quantity"`
+	Price     float64   `json:"price"`
+}
+
+// ActionPlacedData constructs the data for the placed action.
+func ActionPlacedData(ord Order) delegate.Data {
+	items := make([]ActionPlacedItem, len(ord.Items))
+	for i, itm := range ord.Items {
+		items[i] = ActionPlacedItem{
+			ProductID: itm.ProductID,
+			Quantity:  itm.Quantity,
+			Price:     itm.Price,
+		}
+	}
+
+	params := ActionPlacedParms{
+		OrderID:  ord.ID,
+		TenantID: ord.TenantID,
+		UserID:   ord.UserID,
+		Total:    ord.Total,
+		Items:    items,
+	}
+
+	// Encoding a struct of plain values can't fail.
+	rawParams, _ := json.Marshal(params)
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    ActionPlaced,
+		RawParams: rawParams,
+	}
+}
+
+// =============================================================================
+
+func (c *Core) registerDelegateFunctions(d *delegate.Delegate) {
+	d.Register(user.DomainName, user.ActionUpdated, c.userUpdated)
+}
+
+// userUpdated cancels the pending orders of a user that was disabled, since
+// they can no longer be paid for. Orders that were paid are left for an
+// admin to refund. A user can have many orders, so they are cancelled in
+// the background unless the jobs pool can't take it.
+func (c *Core) userUpdated(ctx context.Context, data delegate.Data) error {
+	var params user.ActionUpdatedParms
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return fmt.Errorf("expected an encoded %T: %w", params, err)
+	}
+
+	if params.Enabled {
+		return nil
+	}
+
+	job := func(ctx context.Context) error {
+		return c.cancelPending(ctx, params.UserID)
+	}
+
+	if c.jobs == nil {
+		return job(ctx)
+	}
+
+	if err := c.jobs.Submit(ctx, "order.cancelpending", job); err != nil {
+		c.log.Info(ctx, "order", "status", "cancelling pending orders in the request", "userID", params.UserID, "msg", err)
+		return job(ctx)
+	}
+
+	return nil
+}
+
+// cancelPending cancels the pending orders of the user.
+func (c *Core) cancelPending(ctx context.Context, userID uuid.UUID) error {
+	ords, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("querybyuserid: userID[%s]: %w", userID, err)
+	}
+
+	for _, ord := range ords {
+		if ord.Status != StatusPending {
+			continue
+		}
+
+		if _, err := c.Transition(ctx, ord, StatusCancelled); err != nil {
+			return fmt.Errorf("transition: orderID[%s]: %w", ord.ID, err)
+		}
+
+		c.log.Info(ctx, "order", "status", "cancelled for disabled user", "orderID", ord.ID, "userID", userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package order
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on. Fields
+// that are nil aren't filtered on. Soft deleted orders are only included
+// WithDeleted, and orders moved to the archive only WithArchived.
+type QueryFilter struct {
+	ID               *uuid.UUID
+	UserID           *uuid.UUID
+	Status           *Status
+	StartCreatedDate *time.Time
+	EndCreatedDate   *time.Time
+	WithDeleted      bool
+	WithArchived     bool
+}
+
+// Validate checks the filter is considered clean before it is used.
+func (qf QueryFilter) Validate() error {
+	if qf.Status != nil && !slices.Contains(statuses, *qf.Status) {
+		return errors.New("status filter is not a known status")
+	}
+
+	if qf.StartCreatedDate != nil && qf.EndCreatedDate != nil && qf.EndCreatedDate.Before(*qf.StartCreatedDate) {
+		return errors.New("end created date must not be before start created date")
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package order
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of statuses an order moves through.
+const (
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusCancelled Status = "cancelled"
+)
+
+// Status represents where an order is in its lifecycle.
+type Status string
+
+// statuses lists every status in lifecycle order.
+var statuses = []Status{StatusPending, StatusPaid, StatusShipped, StatusDelivered, StatusCancelled}
+
+// transitions lists the statuses each status can move to. An order can be
+// cancelled until it ships, and delivered and cancelled orders are final.
+var transitions = map[Status][]Status{
+	StatusPending: {StatusPaid, StatusCancelled},
+	StatusPaid:    {StatusShipped, StatusCancelled},
+	StatusShipped: {StatusDelivered},
+}
+
+// Order represents an order placed by a user. TenantID is uuid.Nil for an
+// order that belongs to no tenant and DateDeleted is zero unless the order
+// was soft deleted. Version starts at 1 and is bumped by every change.
+type Order struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	UserID      uuid.UUID
+	Status      Status
+	Items       []Item
+	Total       float64
+	Version     int
+	DateCreated time.Time
+	DateUpdated time.Time
+	DateDeleted time.Time
+}
+
+// Expect contains the state an order must be in for a change to apply.
+// Fields that are nil aren't checked.
+type Expect struct {
+	Status *Status
+}
+
+// Item represents a line of an order. The price is the cost of the product
+// when the order was placed.
+type Item struct {
+	ProductID uuid.UUID
+	Quantity  int
+	Price     float64
+}
+
+// NewOrder is what we require from clients when placing an Order.
+type NewOrder struct {
+	UserID uuid.UUID
+	Items  []NewItem
+}
+
+// NewItem is a line of a NewOrder.
+type NewItem struct {
+	ProductID uuid.UUID
+	Quantity  int
+}
@@ -0,0 +1,339 @@
+// Package order provides business access to order data in the system.
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/precondition"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/worker"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound          = errors.New("order not found")
+	ErrUserDisabled      = errors.New("user disabled")
+	ErrNoItems           = errors.New("order has no items")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrVersionConflict   = errors.New("order was changed by another request")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data. Create must write the order and its items together.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, ord Order) error
+	Update(ctx context.Context, ord Order) error
+	Delete(ctx context.Context, ord Order) error
+	Restore(ctx context.Context, orderID uuid.UUID, dateUpdated time.Time) error
+	Query(ctx context.Context, filter QueryFilter, orderBy orderby.By, pg page.Page) ([]Order, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, orderID uuid.UUID) (Order, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Order, error)
+}
+
+// Core manages the set of APIs for order access.
+type Core struct {
+	log         *logger.Logger
+	delegate    *delegate.Delegate
+	jobs        *worker.Pool
+	storer      Storer
+	userCore    *user.Core
+	productCore *product.Core
+}
+
+// NewCore constructs a core for order api access. The core registers with
+// the delegate for the user actions it acts on, and acts on them in the
+// background with the jobs pool. With a nil pool it acts on them before the
+// delegate call returns.
+func NewCore(log *logger.Logger, delegate *delegate.Delegate, jobs *worker.Pool, storer Storer, userCore *user.Core, productCore *product.Core) *Core {
+	c := Core{
+		log:         log,
+		delegate:    delegate,
+		jobs:        jobs,
+		storer:      storer,
+		userCore:    userCore,
+		productCore: productCore,
+	}
+
+	c.registerDelegateFunctions(delegate)
+
+	return &c
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls. The user and product
+// cores are moved under it too, so the checks made while placing an order
+// read what the transaction sees.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userCore, err := c.userCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	productCore, err := c.productCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	core := Core{
+		log:         c.log,
+		delegate:    c.delegate,
+		jobs:        c.jobs,
+		storer:      storer,
+		userCore:    userCore,
+		productCore: productCore,
+	}
+
+	return &core, nil
+}
+
+// Create places a new order for the user, who must exist and be enabled.
+// Each item is priced at the current cost of its product and the order
+// starts out pending. The order belongs to the tenant the context is scoped
+// to, if any, and so must the user and the products.
+func (c *Core) Create(ctx context.Context, no NewOrder) (Order, error) {
+	tenantID, err := tenant.OwnerFromContext(ctx)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if len(no.Items) == 0 {
+		return Order{}, ErrNoItems
+	}
+
+	usr, err := c.userCore.QueryByID(ctx, no.UserID)
+	if err != nil {
+		return Order{}, fmt.Errorf("user.querybyid: %s: %w", no.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return Order{}, ErrUserDisabled
+	}
+
+	items := make([]Item, len(no.Items))
+	for i, ni := range no.Items {
+		prd, err := c.productCore.QueryByID(ctx, ni.ProductID)
+		if err != nil {
+			return Order{}, fmt.Errorf("product.querybyid: %s: %w", ni.ProductID, err)
+		}
+
+		items[i] = Item{
+			ProductID: prd.ID,
+			Quantity:  ni.Quantity,
+			Price:     prd.Cost,
+		}
+	}
+
+	now := time.Now()
+
+	ord := Order{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		UserID:      no.UserID,
+		Status:      StatusPending,
+		Items:       items,
+		Total:       Total(items),
+		Version:     1,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.storer.Create(ctx, ord); err != nil {
+		return Order{}, fmt.Errorf("create: %w", err)
+	}
+
+	if err := c.delegate.Call(ctx, ActionPlacedData(ord)); err != nil {
+		c.log.Error(ctx, "order", "status", "delegate call failed", "action", ActionPlaced, "orderID", ord.ID, "msg", err)
+	}
+
+	return ord, nil
+}
+
+// Transition moves the order to the status, which must be one the order's
+// current status can move to.
+func (c *Core) Transition(ctx context.Context, ord Order, status Status) (Order, error) {
+	return c.TransitionIf(ctx, ord, status, Expect{})
+}
+
+// TransitionIf moves the order to the status like Transition, only if the
+// order is in the expected state. The move only applies while the stored
+// order is still at the version it was read at, which the same statement
+// that writes it checks, so of two requests racing to move an order only
+// one wins. The request that loses gets a *precondition.Error describing
+// the current state.
+func (c *Core) TransitionIf(ctx context.Context, ord Order, status Status, exp Expect) (Order, error) {
+	if err := checkTenant(ctx, ord); err != nil {
+		return Order{}, fmt.Errorf("update: orderID[%s]: %w", ord.ID, err)
+	}
+
+	if exp.Status != nil {
+		var pe precondition.Error
+		pe.Add("status", *exp.Status, ord.Status)
+
+		if err := pe.ToError(); err != nil {
+			return Order{}, fmt.Errorf("update: orderID[%s]: %w", ord.ID, err)
+		}
+	}
+
+	if !CanTransition(ord.Status, status) {
+		return Order{}, fmt.Errorf("%s to %s: %w", ord.Status, status, ErrInvalidTransition)
+	}
+
+	from := ord
+
+	ord.Status = status
+	ord.Version++
+	ord.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, ord); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return Order{}, c.changed(ctx, from)
+		}
+		return Order{}, fmt.Errorf("update: %w", err)
+	}
+
+	return ord, nil
+}
+
+// Delete soft deletes the specified order, which keeps its items and can
+// be restored. The delete only applies if the order is still at the version
+// it was read at, otherwise ErrVersionConflict is returned.
+func (c *Core) Delete(ctx context.Context, ord Order) error {
+	if err := checkTenant(ctx, ord); err != nil {
+		return fmt.Errorf("delete: orderID[%s]: %w", ord.ID, err)
+	}
+
+	now := time.Now()
+
+	ord.Version++
+	ord.DateUpdated = now
+	ord.DateDeleted = now
+
+	if err := c.storer.Delete(ctx, ord); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Restore brings back a soft deleted order. Restoring an order that isn't
+// deleted changes nothing.
+func (c *Core) Restore(ctx context.Context, orderID uuid.UUID) (Order, error) {
+	if err := c.storer.Restore(ctx, orderID, time.Now()); err != nil {
+		return Order{}, fmt.Errorf("restore: orderID[%s]: %w", orderID, err)
+	}
+
+	return c.QueryByID(ctx, orderID)
+}
+
+// Query retrieves a page of orders matching the filter, in the order
+// asked for.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy orderby.By, pg page.Page) ([]Order, error) {
+	ords, err := c.storer.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return ords, nil
+}
+
+// Count returns the total number of orders matching the filter.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	n, err := c.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+
+	return n, nil
+}
+
+// QueryByID finds the order by the specified ID.
+func (c *Core) QueryByID(ctx context.Context, orderID uuid.UUID) (Order, error) {
+	ord, err := c.storer.QueryByID(ctx, orderID)
+	if err != nil {
+		return Order{}, fmt.Errorf("query: orderID[%s]: %w", orderID, err)
+	}
+
+	if err := checkTenant(ctx, ord); err != nil {
+		return Order{}, fmt.Errorf("query: orderID[%s]: %w", orderID, err)
+	}
+
+	return ord, nil
+}
+
+// QueryByUserID finds the orders placed by the specified user.
+func (c *Core) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Order, error) {
+	ords, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return ords, nil
+}
+
+// changed reports how the order differs from the one that was read, after
+// a write that expected it unchanged affected nothing.
+func (c *Core) changed(ctx context.Context, ord Order) error {
+	cur, err := c.storer.QueryByID(ctx, ord.ID)
+	if err != nil {
+		return fmt.Errorf("query: orderID[%s]: %w", ord.ID, err)
+	}
+
+	var pe precondition.Error
+	pe.Add("status", ord.Status, cur.Status)
+	pe.Add("version", ord.Version, cur.Version)
+
+	if err := pe.ToError(); err != nil {
+		return fmt.Errorf("update: orderID[%s]: %w", ord.ID, err)
+	}
+
+	return fmt.Errorf("update: orderID[%s]: %w", ord.ID, ErrVersionConflict)
+}
+
+// checkTenant denies access to an order of another tenant than the one the
+// context is scoped to by reporting it as not found.
+func checkTenant(ctx context.Context, ord Order) error {
+	if err := tenant.CheckAccess(ctx, ord.TenantID); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// CanTransition reports whether an order can move from one status to the
+// other.
+func CanTransition(from Status, to Status) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// Total computes the total of the items. The sum is kept in cents so it
+// doesn't pick up floating point error as lines are added.
+func Total(items []Item) float64 {
+	var cents int64
+	for _, item := range items {
+		cents += int64(math.Round(item.Price*100)) * int64(item.Quantity)
+	}
+
+	return float64(cents) / 100
+}
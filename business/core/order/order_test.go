@@ -0,0 +1,313 @@
+package order_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/precondition"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/order/stores/ordermem"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/product/stores/productmem"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usermem"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Test_TenantIsolation places an order in one tenant and checks another
+// tenant, or a caller denied every tenant, can neither see nor use the
+// user, product and order involved, while a context scoped to no tenant
+// sees them all.
+func Test_TenantIsolation(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+	dlg := delegate.New(log)
+
+	userCore := user.NewCore(log, dlg, usermem.NewStore())
+	productCore := product.NewCore(log, productmem.NewStore(), userCore)
+	orderCore := order.NewCore(log, dlg, nil, ordermem.NewStore(), userCore, productCore)
+
+	ctxA := tenant.WithID(context.Background(), uuid.New())
+	ctxB := tenant.WithID(context.Background(), uuid.New())
+	denied := tenant.Deny(context.Background())
+	unscoped := context.Background()
+
+	usr, err := userCore.Create(ctxA, user.NewUser{
+		Name:     "Tenant A",
+		Email:    mail.Address{Address: "a@example.com"},
+		Roles:    []string{"USER"},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	prd, err := productCore.Create(ctxA, product.NewProduct{UserID: usr.ID, Name: "Widget", Cost: 10, Quantity: 1})
+	if err != nil {
+		t.Fatalf("Should be able to create a product : %s", err)
+	}
+
+	ord, err := orderCore.Create(ctxA, order.NewOrder{
+		UserID: usr.ID,
+		Items:  []order.NewItem{{ProductID: prd.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to place an order : %s", err)
+	}
+
+	tenantA, _ := tenant.IDFromContext(ctxA)
+	if usr.TenantID != tenantA || prd.TenantID != tenantA || ord.TenantID != tenantA {
+		t.Fatalf("Should create the data in the tenant of the context, got %s, %s and %s", usr.TenantID, prd.TenantID, ord.TenantID)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Reads leave out what belongs to other tenants, while writes of data
+	// read in another tenant are refused by the cores. Both report the data
+	// as not found.
+	crossTenant := func(what string, err error, notFound error) {
+		t.Helper()
+
+		if !errors.Is(err, notFound) {
+			t.Errorf("Should not be able to %s of another tenant, got %v", what, err)
+		}
+	}
+
+	refused := func(what string, err error, notFound error) {
+		t.Helper()
+
+		if !errors.Is(err, notFound) || !errors.Is(err, tenant.ErrCrossTenant) {
+			t.Errorf("Should be refused to %s of another tenant, got %v", what, err)
+		}
+	}
+
+	_, err = userCore.QueryByID(ctxB, usr.ID)
+	crossTenant("query the user", err, user.ErrNotFound)
+
+	_, err = userCore.QueryByEmail(ctxB, usr.Email)
+	crossTenant("query the user by email", err, user.ErrNotFound)
+
+	_, err = productCore.QueryByID(ctxB, prd.ID)
+	crossTenant("query the product", err, product.ErrNotFound)
+
+	_, err = orderCore.QueryByID(ctxB, ord.ID)
+	crossTenant("query the order", err, order.ErrNotFound)
+
+	name := "Stolen"
+	_, err = productCore.Update(ctxB, prd, product.UpdateProduct{Name: &name})
+	refused("update the product", err, product.ErrNotFound)
+
+	err = productCore.Delete(ctxB, prd)
+	refused("delete the product", err, product.ErrNotFound)
+
+	_, err = orderCore.Transition(ctxB, ord, order.StatusPaid)
+	refused("change the order", err, order.ErrNotFound)
+
+	err = userCore.Delete(ctxB, usr)
+	refused("delete the user", err, user.ErrNotFound)
+
+	_, err = orderCore.Create(ctxB, order.NewOrder{
+		UserID: usr.ID,
+		Items:  []order.NewItem{{ProductID: prd.ID, Quantity: 1}},
+	})
+	crossTenant("place an order for the user", err, user.ErrNotFound)
+
+	// A caller denied every tenant sees nothing and can't add anything,
+	// not even for data of no tenant.
+
+	_, err = productCore.QueryByID(denied, prd.ID)
+	crossTenant("query the product", err, product.ErrNotFound)
+
+	_, err = orderCore.QueryByID(denied, ord.ID)
+	crossTenant("query the order", err, order.ErrNotFound)
+
+	err = productCore.Delete(denied, prd)
+	refused("delete the product", err, product.ErrNotFound)
+
+	_, err = orderCore.Transition(denied, ord, order.StatusPaid)
+	refused("change the order", err, order.ErrNotFound)
+
+	if _, err := productCore.Create(denied, product.NewProduct{UserID: usr.ID, Name: "Widget", Cost: 10, Quantity: 1}); !errors.Is(err, tenant.ErrNoTenant) {
+		t.Errorf("Should not be able to add a product without a tenant, got %v", err)
+	}
+
+	_, err = orderCore.Create(denied, order.NewOrder{
+		UserID: usr.ID,
+		Items:  []order.NewItem{{ProductID: prd.ID, Quantity: 1}},
+	})
+	if !errors.Is(err, tenant.ErrNoTenant) {
+		t.Errorf("Should not be able to place an order without a tenant, got %v", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	pg := page.MustParse("1", "10")
+
+	counts := []struct {
+		name  string
+		count func(ctx context.Context) (int, error)
+	}{
+		{"users", func(ctx context.Context) (int, error) { return userCore.Count(ctx, user.QueryFilter{}) }},
+		{"products", func(ctx context.Context) (int, error) { return productCore.Count(ctx, product.QueryFilter{}) }},
+		{"orders", func(ctx context.Context) (int, error) { return orderCore.Count(ctx, order.QueryFilter{}) }},
+		{"searched products", func(ctx context.Context) (int, error) { return productCore.SearchCount(ctx, "widget") }},
+	}
+
+	for _, tt := range counts {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []struct {
+				name string
+				ctx  context.Context
+				exp  int
+			}{
+				{"same tenant", ctxA, 1},
+				{"other tenant", ctxB, 0},
+				{"denied", denied, 0},
+				{"no tenant", unscoped, 1},
+			} {
+				n, err := tt.count(c.ctx)
+				if err != nil {
+					t.Fatalf("Should be able to count the %s : %s", tt.name, err)
+				}
+
+				if n != c.exp {
+					t.Errorf("Should count %d %s for the %s, got %d", c.exp, tt.name, c.name, n)
+				}
+			}
+		})
+	}
+
+	ords, err := orderCore.Query(ctxB, order.QueryFilter{UserID: &usr.ID}, order.DefaultOrderBy, pg)
+	if err != nil {
+		t.Fatalf("Should be able to query the orders : %s", err)
+	}
+
+	if len(ords) != 0 {
+		t.Errorf("Should not list the orders of another tenant, got %d", len(ords))
+	}
+
+	prds, err := productCore.QueryByUserID(denied, usr.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the products : %s", err)
+	}
+
+	if len(prds) != 0 {
+		t.Errorf("Should not list the products of any tenant when denied, got %d", len(prds))
+	}
+
+	prds, err = productCore.Query(unscoped, product.QueryFilter{}, orderby.NewBy(product.OrderByID, orderby.ASC), pg)
+	if err != nil {
+		t.Fatalf("Should be able to query the products : %s", err)
+	}
+
+	if len(prds) != 1 {
+		t.Errorf("Should list the products of every tenant with no tenant, got %d", len(prds))
+	}
+}
+
+// Test_CompetingTransitions moves the same order to different statuses at
+// once and checks only one of the moves wins, while the others are told
+// what the order was changed to.
+func Test_CompetingTransitions(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+	dlg := delegate.New(log)
+
+	userCore := user.NewCore(log, dlg, usermem.NewStore())
+	productCore := product.NewCore(log, productmem.NewStore(), userCore)
+	orderCore := order.NewCore(log, dlg, nil, ordermem.NewStore(), userCore, productCore)
+
+	ctx := context.Background()
+
+	usr, err := userCore.Create(ctx, user.NewUser{
+		Name:     "Racer",
+		Email:    mail.Address{Address: "racer@example.com"},
+		Roles:    []string{"USER"},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	prd, err := productCore.Create(ctx, product.NewProduct{UserID: usr.ID, Name: "Widget", Cost: 10, Quantity: 1})
+	if err != nil {
+		t.Fatalf("Should be able to create a product : %s", err)
+	}
+
+	ord, err := orderCore.Create(ctx, order.NewOrder{
+		UserID: usr.ID,
+		Items:  []order.NewItem{{ProductID: prd.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to place an order : %s", err)
+	}
+
+	// Every request read the order while it was pending.
+	const racers = 10
+
+	var wg sync.WaitGroup
+	results := make([]error, racers)
+
+	for i := range racers {
+		status := order.StatusPaid
+		if i%2 == 1 {
+			status = order.StatusCancelled
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, results[i] = orderCore.Transition(ctx, ord, status)
+		}()
+	}
+
+	wg.Wait()
+
+	var won int
+	for _, err := range results {
+		if err == nil {
+			won++
+			continue
+		}
+
+		var pe *precondition.Error
+		if !errors.As(err, &pe) {
+			t.Errorf("Should report the order changed to a losing request, got %v", err)
+			continue
+		}
+
+		if pe.Violations[0].Field != "status" || pe.Violations[0].Expected != string(order.StatusPending) {
+			t.Errorf("Should report the status the order was read at, got %+v", pe.Violations)
+		}
+	}
+
+	if won != 1 {
+		t.Fatalf("Should let exactly one transition win, got %d", won)
+	}
+
+	cur, err := orderCore.QueryByID(ctx, ord.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the order : %s", err)
+	}
+
+	if cur.Status == order.StatusPending || cur.Version != ord.Version+1 {
+		t.Errorf("Should have moved the order once, got %s at version %d", cur.Status, cur.Version)
+	}
+
+	// A request expecting a status the order is no longer in is refused
+	// before it tries to write.
+	pending := order.StatusPending
+	_, err = orderCore.TransitionIf(ctx, cur, order.StatusCancelled, order.Expect{Status: &pending})
+
+	var pe *precondition.Error
+	if !errors.As(err, &pe) {
+		t.Errorf("Should refuse a transition expecting another status, got %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package order
+
+import orderby "github.com/mrcruz117/al-service/business/api/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = orderby.NewBy(OrderByID, orderby.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID          = "id"
+	OrderByUserID      = "user_id"
+	OrderByStatus      = "status"
+	OrderByTotal       = "total"
+	OrderByDateCreated = "date_created"
+)
@@ -0,0 +1,135 @@
+// Package orderaudit contains an order store that records the changes
+// written through it in the entity audit trail.
+package orderaudit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/business/core/order"
+)
+
+// entity is the name the changes to orders are recorded under.
+const entity = "order"
+
+// Store wraps an order store and records every successful change.
+type Store struct {
+	storer    order.Storer
+	auditCore *entityaudit.Core
+}
+
+// NewStore constructs the api for audited access.
+func NewStore(storer order.Storer, auditCore *entityaudit.Core) *Store {
+	return &Store{
+		storer:    storer,
+		auditCore: auditCore,
+	}
+}
+
+var _ order.Storer = (*Store)(nil)
+
+// ExecuteUnderTransaction constructs a new Store value with the wrapped
+// store and the audit trail under the transaction, so a change and its
+// record commit or roll back together.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (order.Storer, error) {
+	storer, err := s.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	auditCore, err := s.auditCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:    storer,
+		auditCore: auditCore,
+	}
+
+	return &store, nil
+}
+
+// Create inserts the order and records it.
+func (s *Store) Create(ctx context.Context, ord order.Order) error {
+	if err := s.storer.Create(ctx, ord); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, ord.ID.String(), entityaudit.ActionCreate, nil, ord)
+
+	return nil
+}
+
+// Update replaces the order and records what it was replaced from.
+func (s *Store) Update(ctx context.Context, ord order.Order) error {
+	before := s.current(ctx, ord.ID)
+
+	if err := s.storer.Update(ctx, ord); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, ord.ID.String(), entityaudit.ActionUpdate, before, ord)
+
+	return nil
+}
+
+// Delete deletes the order and records what it was before.
+func (s *Store) Delete(ctx context.Context, ord order.Order) error {
+	before := s.current(ctx, ord.ID)
+
+	if err := s.storer.Delete(ctx, ord); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, ord.ID.String(), entityaudit.ActionDelete, before, ord)
+
+	return nil
+}
+
+// Restore restores the order and records what it was restored to.
+func (s *Store) Restore(ctx context.Context, orderID uuid.UUID, dateUpdated time.Time) error {
+	if err := s.storer.Restore(ctx, orderID, dateUpdated); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, orderID.String(), entityaudit.ActionRestore, nil, s.current(ctx, orderID))
+
+	return nil
+}
+
+// Query implements the order.Storer interface.
+func (s *Store) Query(ctx context.Context, filter order.QueryFilter, orderBy orderby.By, pg page.Page) ([]order.Order, error) {
+	return s.storer.Query(ctx, filter, orderBy, pg)
+}
+
+// Count implements the order.Storer interface.
+func (s *Store) Count(ctx context.Context, filter order.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID implements the order.Storer interface.
+func (s *Store) QueryByID(ctx context.Context, orderID uuid.UUID) (order.Order, error) {
+	return s.storer.QueryByID(ctx, orderID)
+}
+
+// QueryByUserID implements the order.Storer interface.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]order.Order, error) {
+	return s.storer.QueryByUserID(ctx, userID)
+}
+
+// current reads the stored order for a snapshot, nil when it can't be
+// read.
+func (s *Store) current(ctx context.Context, orderID uuid.UUID) any {
+	ord, err := s.storer.QueryByID(ctx, orderID)
+	if err != nil {
+		return nil
+	}
+
+	return ord
+}
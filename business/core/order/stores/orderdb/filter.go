@@ -0,0 +1,73 @@
+package orderdb
+
+import (
+	"context"
+
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+)
+
+// applyFilter returns the WHERE clause for the filter, limited to the tenant
+// the context is scoped to, and adds the values of its parameters to the
+// data. The clause is empty when nothing is filtered on.
+func applyFilter(ctx context.Context, filter order.QueryFilter, data map[string]any) string {
+	var wc []string
+
+	if filter.ID != nil {
+		data["order_id"] = *filter.ID
+		wc = append(wc, "order_id = :order_id")
+	}
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Status != nil {
+		data["status"] = string(*filter.Status)
+		wc = append(wc, "status = :status")
+	}
+
+	if filter.StartCreatedDate != nil {
+		data["start_date_created"] = filter.StartCreatedDate.UTC()
+		wc = append(wc, "date_created >= :start_date_created")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_date_created"] = filter.EndCreatedDate.UTC()
+		wc = append(wc, "date_created <= :end_date_created")
+	}
+
+	wc = sqldb.ExcludeDeleted(wc, filter.WithDeleted)
+	wc = scopeTenant(ctx, wc, data)
+
+	return sqldb.Where(wc)
+}
+
+// scopeTenant limits the conditions to the orders of the tenant the context
+// is scoped to.
+func scopeTenant(ctx context.Context, wc []string, data map[string]any) []string {
+	tenantID, _ := tenant.IDFromContext(ctx)
+	return sqldb.ScopeTenant(wc, data, tenantID)
+}
+
+// ordersTable returns the table the orders are queried from, the view that
+// reads through to the archive when the filter asks for archived orders.
+func ordersTable(filter order.QueryFilter) string {
+	if filter.WithArchived {
+		return "orders_history"
+	}
+
+	return "orders"
+}
+
+// itemsTable returns the table the items of the orders queried are loaded
+// from.
+func itemsTable(filter order.QueryFilter) string {
+	if filter.WithArchived {
+		return "order_items_history"
+	}
+
+	return "order_items"
+}
@@ -0,0 +1,93 @@
+package orderdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/order"
+)
+
+type dbOrder struct {
+	ID          uuid.UUID     `db:"order_id"`
+	TenantID    uuid.NullUUID `db:"tenant_id"`
+	UserID      uuid.UUID     `db:"user_id"`
+	Status      string        `db:"status"`
+	Total       float64       `db:"total"`
+	Version     int           `db:"version"`
+	DateCreated time.Time     `db:"date_created"`
+	DateUpdated time.Time     `db:"date_updated"`
+	DateDeleted sql.NullTime  `db:"deleted_at"`
+}
+
+type dbItem struct {
+	OrderID   uuid.UUID `db:"order_id"`
+	Line      int       `db:"line"`
+	ProductID uuid.UUID `db:"product_id"`
+	Quantity  int       `db:"quantity"`
+	Price     float64   `db:"price"`
+}
+
+func toDBOrder(ord order.Order) dbOrder {
+	return dbOrder{
+		ID: ord.ID,
+		TenantID: uuid.NullUUID{
+			UUID:  ord.TenantID,
+			Valid: ord.TenantID != uuid.Nil,
+		},
+		UserID:      ord.UserID,
+		Status:      string(ord.Status),
+		Total:       ord.Total,
+		Version:     ord.Version,
+		DateCreated: ord.DateCreated.UTC(),
+		DateUpdated: ord.DateUpdated.UTC(),
+		DateDeleted: sql.NullTime{
+			Time:  ord.DateDeleted.UTC(),
+			Valid: !ord.DateDeleted.IsZero(),
+		},
+	}
+}
+
+func toDBItems(ord order.Order) []dbItem {
+	items := make([]dbItem, len(ord.Items))
+	for i, item := range ord.Items {
+		items[i] = dbItem{
+			OrderID:   ord.ID,
+			Line:      i + 1,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+		}
+	}
+
+	return items
+}
+
+func toCoreOrder(dbOrd dbOrder, dbItems []dbItem) order.Order {
+	items := make([]order.Item, len(dbItems))
+	for i, dbItm := range dbItems {
+		items[i] = order.Item{
+			ProductID: dbItm.ProductID,
+			Quantity:  dbItm.Quantity,
+			Price:     dbItm.Price,
+		}
+	}
+
+	ord := order.Order{
+		ID:          dbOrd.ID,
+		TenantID:    dbOrd.TenantID.UUID,
+		UserID:      dbOrd.UserID,
+		Status:      order.Status(dbOrd.Status),
+		Items:       items,
+		Total:       dbOrd.Total,
+		Version:     dbOrd.Version,
+		DateCreated: dbOrd.DateCreated.In(time.Local),
+		DateUpdated: dbOrd.DateUpdated.In(time.Local),
+	}
+
+	if dbOrd.DateDeleted.Valid {
+		ord.DateDeleted = dbOrd.DateDeleted.Time.In(time.Local)
+	}
+
+	return ord
+}
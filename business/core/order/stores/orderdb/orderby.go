@@ -0,0 +1,36 @@
+package orderdb
+
+import (
+	"fmt"
+
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/order"
+)
+
+var orderByFields = map[string]string{
+	order.OrderByID:          "order_id",
+	order.OrderByUserID:      "user_id",
+	order.OrderByStatus:      "status",
+	order.OrderByTotal:       "total",
+	order.OrderByDateCreated: "date_created",
+}
+
+// orderByClause translates the order to SQL, breaking ties by id so pages
+// don't overlap.
+func orderByClause(orderBy orderby.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	if orderBy.Direction != orderby.ASC && orderBy.Direction != orderby.DESC {
+		return "", fmt.Errorf("direction %q does not exist", orderBy.Direction)
+	}
+
+	clause := by + " " + orderBy.Direction
+	if by != "order_id" {
+		clause += ", order_id"
+	}
+
+	return clause, nil
+}
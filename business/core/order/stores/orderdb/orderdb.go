@@ -0,0 +1,305 @@
+// Package orderdb contains order related CRUD functionality.
+package orderdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for order database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (order.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new order and its items into the database. They are
+// written in a transaction of their own unless the store is already under
+// one.
+func (s *Store) Create(ctx context.Context, ord order.Order) error {
+	const q = `
+	INSERT INTO orders
+		(order_id, tenant_id, user_id, status, total, version, date_created, date_updated)
+	VALUES
+		(:order_id, :tenant_id, :user_id, :status, :total, :version, :date_created, :date_updated)`
+
+	const qi = `
+	INSERT INTO order_items
+		(order_id, line, product_id, quantity, price)
+	VALUES
+		(:order_id, :line, :product_id, :quantity, :price)`
+
+	f := func(ec sqlx.ExtContext) error {
+		if err := sqldb.NamedExecContext(ctx, s.log, ec, q, toDBOrder(ord)); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+
+		for _, item := range toDBItems(ord) {
+			if err := sqldb.NamedExecContext(ctx, s.log, ec, qi, item); err != nil {
+				return fmt.Errorf("insert item[%d]: %w", item.Line, err)
+			}
+		}
+
+		return nil
+	}
+
+	return sqldb.WithinTran(ctx, s.db, f)
+}
+
+// Update replaces the status of an order in the database if it is still
+// at the version before the one of the order. The items of an order don't
+// change once it is placed.
+func (s *Store) Update(ctx context.Context, ord order.Order) error {
+	const q = `
+	UPDATE
+		orders
+	SET
+		"status" = :status,
+		"version" = :version,
+		"date_updated" = :date_updated
+	WHERE
+		order_id = :order_id AND
+		"version" = :version - 1 AND
+		deleted_at IS NULL`
+
+	if err := sqldb.NamedExecVersioned(ctx, s.log, s.db, q, toDBOrder(ord)); err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("namedexecversioned: %w: %w", order.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("namedexecversioned: %w", err)
+	}
+
+	return nil
+}
+
+// Delete marks the order identified by a given ID as deleted if it is
+// still at the version before the one of the order. The items are left as
+// they are.
+func (s *Store) Delete(ctx context.Context, ord order.Order) error {
+	const q = `
+	UPDATE
+		orders
+	SET
+		"version" = :version,
+		"date_updated" = :date_updated,
+		"deleted_at" = :deleted_at
+	WHERE
+		order_id = :order_id AND
+		"version" = :version - 1 AND
+		deleted_at IS NULL`
+
+	if err := sqldb.NamedExecVersioned(ctx, s.log, s.db, q, toDBOrder(ord)); err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("namedexecversioned: %w: %w", order.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("namedexecversioned: %w", err)
+	}
+
+	return nil
+}
+
+// Restore clears the deletion of the order identified by a given ID.
+func (s *Store) Restore(ctx context.Context, orderID uuid.UUID, dateUpdated time.Time) error {
+	data := map[string]any{
+		"order_id":     orderID.String(),
+		"date_updated": dateUpdated.UTC(),
+	}
+
+	wc := scopeTenant(ctx, []string{"order_id = :order_id", "deleted_at IS NOT NULL"}, data)
+
+	q := `
+	UPDATE
+		orders
+	SET
+		"version" = "version" + 1,
+		"date_updated" = :date_updated,
+		"deleted_at" = NULL` + sqldb.Where(wc)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a page of orders from the database.
+func (s *Store) Query(ctx context.Context, filter order.QueryFilter, orderBy orderby.By, pg page.Page) ([]order.Order, error) {
+	data := map[string]any{
+		"offset":        pg.Offset(),
+		"rows_per_page": pg.RowsPerPage(),
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+	SELECT
+		order_id, tenant_id, user_id, status, total, version, date_created, date_updated, deleted_at
+	FROM
+		` + ordersTable(filter) + applyFilter(ctx, filter, data) + `
+	ORDER BY
+		` + orderByClause + `
+	LIMIT :rows_per_page OFFSET :offset`
+
+	var dbOrds []dbOrder
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbOrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return s.withItems(ctx, dbOrds, itemsTable(filter))
+}
+
+// Count returns the total number of orders in the database matching the
+// filter.
+func (s *Store) Count(ctx context.Context, filter order.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	q := `
+	SELECT
+		count(1) AS "count"
+	FROM
+		` + ordersTable(filter) + applyFilter(ctx, filter, data)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID finds the order identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, orderID uuid.UUID) (order.Order, error) {
+	data := map[string]any{
+		"order_id": orderID.String(),
+	}
+
+	wc := scopeTenant(ctx, []string{"order_id = :order_id", sqldb.NotDeleted}, data)
+
+	q := `
+	SELECT
+		order_id, tenant_id, user_id, status, total, version, date_created, date_updated, deleted_at
+	FROM
+		orders` + sqldb.Where(wc)
+
+	var dbOrd dbOrder
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbOrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return order.Order{}, fmt.Errorf("namedquerystruct: %w", order.ErrNotFound)
+		}
+		return order.Order{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	ords, err := s.withItems(ctx, []dbOrder{dbOrd}, "order_items")
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	return ords[0], nil
+}
+
+// QueryByUserID finds the orders placed by the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]order.Order, error) {
+	data := map[string]any{
+		"user_id": userID.String(),
+	}
+
+	wc := scopeTenant(ctx, []string{"user_id = :user_id", sqldb.NotDeleted}, data)
+
+	q := `
+	SELECT
+		order_id, tenant_id, user_id, status, total, version, date_created, date_updated, deleted_at
+	FROM
+		orders` + sqldb.Where(wc) + `
+	ORDER BY
+		order_id`
+
+	var dbOrds []dbOrder
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbOrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return s.withItems(ctx, dbOrds, "order_items")
+}
+
+// withItems loads the items of the orders from the table in a single query
+// and converts them to core orders.
+func (s *Store) withItems(ctx context.Context, dbOrds []dbOrder, table string) ([]order.Order, error) {
+	if len(dbOrds) == 0 {
+		return []order.Order{}, nil
+	}
+
+	orderIDs := make([]string, len(dbOrds))
+	for i, dbOrd := range dbOrds {
+		orderIDs[i] = dbOrd.ID.String()
+	}
+
+	data := map[string]any{
+		"order_ids": orderIDs,
+	}
+
+	q := `
+	SELECT
+		order_id, line, product_id, quantity, price
+	FROM
+		` + table + `
+	WHERE
+		order_id IN (:order_ids)
+	ORDER BY
+		order_id, line`
+
+	var dbItems []dbItem
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbItems); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	byOrder := make(map[uuid.UUID][]dbItem, len(dbOrds))
+	for _, dbItm := range dbItems {
+		byOrder[dbItm.OrderID] = append(byOrder[dbItm.OrderID], dbItm)
+	}
+
+	ords := make([]order.Order, len(dbOrds))
+	for i, dbOrd := range dbOrds {
+		ords[i] = toCoreOrder(dbOrd, byOrder[dbOrd.ID])
+	}
+
+	return ords, nil
+}
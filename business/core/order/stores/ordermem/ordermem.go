@@ -0,0 +1,184 @@
+// Package ordermem contains an in-memory implementation of the order store
+// for unit tests.
+package ordermem
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/order"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain order
+
+// Update replaces the order in the store if it is still at the version
+// before the one of the order.
+func (s *Store) Update(ctx context.Context, ord order.Order) error {
+	err := s.table.UpdateIf(ord, func(existing order.Order) bool {
+		return existing.Version == ord.Version-1 && existing.DateDeleted.IsZero()
+	})
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("update: %w: %w", order.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete marks the order as deleted if it is still at the version before
+// the one of the order.
+func (s *Store) Delete(ctx context.Context, ord order.Order) error {
+	err := s.table.UpdateIf(ord, func(existing order.Order) bool {
+		return existing.Version == ord.Version-1 && existing.DateDeleted.IsZero() && memstore.InTenant(ctx, existing.TenantID)
+	})
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("delete: %w: %w", order.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Restore clears the deletion of the specified order.
+func (s *Store) Restore(ctx context.Context, orderID uuid.UUID, dateUpdated time.Time) error {
+	match := func(ord order.Order) bool {
+		return ord.ID == orderID && !ord.DateDeleted.IsZero() && memstore.InTenant(ctx, ord.TenantID)
+	}
+
+	s.table.UpdateFunc(match, func(ord order.Order) order.Order {
+		ord.Version++
+		ord.DateUpdated = dateUpdated
+		ord.DateDeleted = time.Time{}
+		return ord
+	})
+
+	return nil
+}
+
+// QueryByID gets the specified order from the store, unless it was deleted
+// or belongs to another tenant.
+func (s *Store) QueryByID(ctx context.Context, orderID uuid.UUID) (order.Order, error) {
+	ord, err := s.table.Get(orderID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return order.Order{}, fmt.Errorf("query: %w", order.ErrNotFound)
+		}
+		return order.Order{}, fmt.Errorf("query: %w", err)
+	}
+
+	if !ord.DateDeleted.IsZero() || !memstore.InTenant(ctx, ord.TenantID) {
+		return order.Order{}, fmt.Errorf("query: %w", order.ErrNotFound)
+	}
+
+	return ord, nil
+}
+
+// Query retrieves a page of the orders matching the filter, ordered like
+// the database store.
+func (s *Store) Query(ctx context.Context, filter order.QueryFilter, orderBy orderby.By, pg page.Page) ([]order.Order, error) {
+	less, err := lessBy(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	ords := s.table.Query(memstore.Query[order.Order]{
+		Match:  match(ctx, filter),
+		Less:   less,
+		Offset: pg.Offset(),
+		Limit:  pg.RowsPerPage(),
+	})
+
+	return ords, nil
+}
+
+// Count returns the total number of orders matching the filter.
+func (s *Store) Count(ctx context.Context, filter order.QueryFilter) (int, error) {
+	return s.table.Count(match(ctx, filter)), nil
+}
+
+// QueryByUserID finds the orders placed by the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]order.Order, error) {
+	ords := s.table.Query(memstore.Query[order.Order]{
+		Match: func(ord order.Order) bool {
+			return ord.UserID == userID && ord.DateDeleted.IsZero() && memstore.InTenant(ctx, ord.TenantID)
+		},
+		Less: byID,
+	})
+
+	return ords, nil
+}
+
+func byID(a order.Order, b order.Order) bool {
+	return a.ID.String() < b.ID.String()
+}
+
+func match(ctx context.Context, filter order.QueryFilter) func(order.Order) bool {
+	return func(ord order.Order) bool {
+		switch {
+		case !memstore.InTenant(ctx, ord.TenantID):
+			return false
+		case !filter.WithDeleted && !ord.DateDeleted.IsZero():
+			return false
+		case filter.ID != nil && ord.ID != *filter.ID:
+			return false
+		case filter.UserID != nil && ord.UserID != *filter.UserID:
+			return false
+		case filter.Status != nil && ord.Status != *filter.Status:
+			return false
+		case filter.StartCreatedDate != nil && ord.DateCreated.Before(*filter.StartCreatedDate):
+			return false
+		case filter.EndCreatedDate != nil && ord.DateCreated.After(*filter.EndCreatedDate):
+			return false
+		}
+
+		return true
+	}
+}
+
+// lessBy orders like the database store, where rows that tie are ordered
+// by id ascending whatever the direction.
+func lessBy(orderBy orderby.By) (func(a order.Order, b order.Order) bool, error) {
+	var compare func(a order.Order, b order.Order) int
+
+	switch orderBy.Field {
+	case order.OrderByID:
+		compare = func(a order.Order, b order.Order) int { return strings.Compare(a.ID.String(), b.ID.String()) }
+	case order.OrderByUserID:
+		compare = func(a order.Order, b order.Order) int { return strings.Compare(a.UserID.String(), b.UserID.String()) }
+	case order.OrderByStatus:
+		compare = func(a order.Order, b order.Order) int { return strings.Compare(string(a.Status), string(b.Status)) }
+	case order.OrderByTotal:
+		compare = func(a order.Order, b order.Order) int { return cmp.Compare(a.Total, b.Total) }
+	case order.OrderByDateCreated:
+		compare = func(a order.Order, b order.Order) int { return a.DateCreated.Compare(b.DateCreated) }
+	default:
+		return nil, fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	less := func(a order.Order, b order.Order) bool {
+		c := compare(a, b)
+		if orderBy.Direction == orderby.DESC {
+			c = -c
+		}
+
+		if c == 0 {
+			return byID(a, b)
+		}
+		return c < 0
+	}
+
+	return less, nil
+}
@@ -0,0 +1,142 @@
+// Package orderoutbox contains an order store that adds the events about
+// the orders written through it to the outbox, in the same transaction as
+// the write.
+package orderoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	orderby "github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/order"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+)
+
+// The event added when an order is placed. Its data is the parameters of
+// the placed action, the version is bumped when they change in a way a
+// consumer would notice.
+const (
+	eventPlaced        = "order.placed"
+	eventPlacedVersion = 1
+)
+
+// Store wraps an order store and adds an event to the outbox for every
+// order placed.
+type Store struct {
+	storer     order.Storer
+	outboxCore *outbox.Core
+	beginner   transaction.Beginner
+}
+
+// NewStore constructs the api for access that adds events to the outbox. An
+// order placed outside of a transaction is stored in one begun by the
+// beginner, orders are usually placed in the transaction of the request.
+func NewStore(storer order.Storer, outboxCore *outbox.Core, beginner transaction.Beginner) *Store {
+	return &Store{
+		storer:     storer,
+		outboxCore: outboxCore,
+		beginner:   beginner,
+	}
+}
+
+var _ order.Storer = (*Store)(nil)
+
+// ExecuteUnderTransaction constructs a new Store value with the wrapped
+// store and the outbox under the transaction, so an order and its event
+// commit or roll back together.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (order.Storer, error) {
+	return s.underTransaction(tx)
+}
+
+// Create inserts the order and adds the order.placed event.
+func (s *Store) Create(ctx context.Context, ord order.Order) error {
+	if s.beginner == nil {
+		return s.create(ctx, ord)
+	}
+
+	f := func(tx transaction.Transaction) error {
+		store, err := s.underTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		return store.create(ctx, ord)
+	}
+
+	return transaction.Execute(ctx, s.beginner, f)
+}
+
+// Update implements the order.Storer interface.
+func (s *Store) Update(ctx context.Context, ord order.Order) error {
+	return s.storer.Update(ctx, ord)
+}
+
+// Delete implements the order.Storer interface.
+func (s *Store) Delete(ctx context.Context, ord order.Order) error {
+	return s.storer.Delete(ctx, ord)
+}
+
+// Restore implements the order.Storer interface.
+func (s *Store) Restore(ctx context.Context, orderID uuid.UUID, dateUpdated time.Time) error {
+	return s.storer.Restore(ctx, orderID, dateUpdated)
+}
+
+// Query implements the order.Storer interface.
+func (s *Store) Query(ctx context.Context, filter order.QueryFilter, orderBy orderby.By, pg page.Page) ([]order.Order, error) {
+	return s.storer.Query(ctx, filter, orderBy, pg)
+}
+
+// Count implements the order.Storer interface.
+func (s *Store) Count(ctx context.Context, filter order.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID implements the order.Storer interface.
+func (s *Store) QueryByID(ctx context.Context, orderID uuid.UUID) (order.Order, error) {
+	return s.storer.QueryByID(ctx, orderID)
+}
+
+// QueryByUserID implements the order.Storer interface.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]order.Order, error) {
+	return s.storer.QueryByUserID(ctx, userID)
+}
+
+// underTransaction constructs the store under the transaction. It has no
+// beginner, the owner of the transaction decides whether it commits.
+func (s *Store) underTransaction(tx transaction.Transaction) (*Store, error) {
+	storer, err := s.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxCore, err := s.outboxCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:     storer,
+		outboxCore: outboxCore,
+	}
+
+	return &store, nil
+}
+
+func (s *Store) create(ctx context.Context, ord order.Order) error {
+	if err := s.storer.Create(ctx, ord); err != nil {
+		return err
+	}
+
+	data := json.RawMessage(order.ActionPlacedData(ord).RawParams)
+
+	if err := s.outboxCore.Add(ctx, eventPlaced, eventPlacedVersion, ord.ID.String(), data); err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message represents an event waiting in the outbox to be published. Seq
+// orders the messages as they were added. DatePublished is zero until the
+// message is published, and Attempts and LastError tell what happened to
+// the publishes that failed.
+type Message struct {
+	Seq           int64
+	ID            uuid.UUID
+	Type          string
+	Version       int
+	Key           string
+	Data          json.RawMessage
+	Attempts      int
+	LastError     string
+	DateCreated   time.Time
+	DatePublished time.Time
+}
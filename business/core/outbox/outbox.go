@@ -0,0 +1,246 @@
+// Package outbox provides support for delivering domain events reliably. An
+// event is added to the outbox in the same transaction as the write it is
+// about, so it is stored if and only if the write commits, and a relay
+// publishes the pending events to the broker afterwards. An event is
+// published at least once; one whose publish is acknowledged but not
+// marked is published again, so consumers dedupe on the event id.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/foundation/events"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/queuemon"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data. QueryPending locks the messages it returns when under a
+// transaction, so relays running in several instances skip the messages
+// another one is publishing.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, msg Message) error
+	QueryPending(ctx context.Context, limit int) ([]Message, error)
+	MarkPublished(ctx context.Context, msgIDs []uuid.UUID, published time.Time) error
+	MarkFailed(ctx context.Context, msgID uuid.UUID, reason string) error
+	DeletePublished(ctx context.Context, before time.Time) error
+	Pending(ctx context.Context) (count int, oldest time.Time, err error)
+}
+
+// Core manages the set of APIs for outbox access.
+type Core struct {
+	log      *logger.Logger
+	storer   Storer
+	beginner transaction.Beginner
+	pub      events.Publisher
+	source   string
+
+	mu        sync.Mutex
+	lastRelay time.Time
+}
+
+// NewCore constructs a core for outbox api access. The relay publishes the
+// events to the publisher, naming the source as the service they come
+// from, and runs each batch in a transaction begun by the beginner.
+func NewCore(log *logger.Logger, storer Storer, beginner transaction.Beginner, pub events.Publisher, source string) *Core {
+	return &Core{
+		log:       log,
+		storer:    storer,
+		beginner:  beginner,
+		pub:       pub,
+		source:    source,
+		lastRelay: time.Now(),
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls, so an event added is
+// stored only when the transaction commits.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	core := Core{
+		log:      c.log,
+		storer:   storer,
+		beginner: c.beginner,
+		pub:      c.pub,
+		source:   c.source,
+	}
+
+	return &core, nil
+}
+
+// Add stores an event of the type at the version of its schema, with the
+// data encoded as JSON. The key groups the events a consumer must see in
+// order, such as the id of the user the event is about. Unlike the audit
+// trail, a failure is returned so the write the event is about fails too.
+func (c *Core) Add(ctx context.Context, typ string, version int, key string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	msg := Message{
+		ID:          uuid.New(),
+		Type:        typ,
+		Version:     version,
+		Key:         key,
+		Data:        b,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, msg); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	return nil
+}
+
+// Relay publishes up to limit pending messages in the order they were
+// added and marks the ones published. It stops at the first message that
+// fails to publish, which stays pending with the reason recorded, so the
+// events after it aren't published ahead of it. The number of messages
+// published is returned.
+func (c *Core) Relay(ctx context.Context, limit int) (int, error) {
+	var published int
+	var pubErr error
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		msgs, err := storer.QueryPending(ctx, limit)
+		if err != nil {
+			return fmt.Errorf("querypending: %w", err)
+		}
+
+		var ids []uuid.UUID
+		for _, msg := range msgs {
+			if err := c.pub.Publish(ctx, toEvent(msg, c.source)); err != nil {
+				pubErr = fmt.Errorf("publish: msgID[%s]: %w", msg.ID, err)
+
+				if err := storer.MarkFailed(ctx, msg.ID, err.Error()); err != nil {
+					return fmt.Errorf("markfailed: msgID[%s]: %w", msg.ID, err)
+				}
+				break
+			}
+
+			ids = append(ids, msg.ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := storer.MarkPublished(ctx, ids, time.Now()); err != nil {
+			return fmt.Errorf("markpublished: %w", err)
+		}
+
+		published = len(ids)
+
+		return nil
+	}
+
+	if err := transaction.Execute(ctx, c.beginner, f); err != nil {
+		return 0, err
+	}
+
+	if pubErr == nil {
+		c.mu.Lock()
+		c.lastRelay = time.Now()
+		c.mu.Unlock()
+	}
+
+	return published, pubErr
+}
+
+// Run relays the pending messages at the interval until the context is
+// canceled, draining the outbox a batch at a time. Published messages are
+// deleted once they are older than the retention. While the switch is
+// paused messages stay pending.
+func (c *Core) Run(ctx context.Context, interval time.Duration, batch int, retention time.Duration, sw *subsystem.Switch) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+
+		if sw.Paused() {
+			continue
+		}
+
+		for {
+			n, err := c.Relay(ctx, batch)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					c.log.Error(ctx, "outbox", "status", "relay failed", "published", n, "msg", err)
+				}
+				break
+			}
+
+			if n < batch {
+				break
+			}
+		}
+
+		if err := c.storer.DeletePublished(ctx, time.Now().Add(-retention)); err != nil && !errors.Is(err, context.Canceled) {
+			c.log.Error(ctx, "outbox", "status", "deleting published", "msg", err)
+		}
+	}
+}
+
+// Stats reports the messages waiting to be published for monitoring. Lag is
+// how long ago a relay last published without failing, which grows while
+// the broker is down.
+func (c *Core) Stats(ctx context.Context) (queuemon.Stats, error) {
+	count, oldest, err := c.storer.Pending(ctx)
+	if err != nil {
+		return queuemon.Stats{}, fmt.Errorf("pending: %w", err)
+	}
+
+	c.mu.Lock()
+	lastRelay := c.lastRelay
+	c.mu.Unlock()
+
+	stats := queuemon.Stats{
+		Depth: int64(count),
+		Lag:   time.Since(lastRelay),
+	}
+
+	if count > 0 {
+		stats.OldestAge = time.Since(oldest)
+	}
+
+	return stats, nil
+}
+
+func toEvent(msg Message, source string) events.Event {
+	return events.Event{
+		ID:      msg.ID.String(),
+		Type:    msg.Type,
+		Version: msg.Version,
+		Source:  source,
+		Key:     msg.Key,
+		Time:    msg.DateCreated.UTC(),
+		Data:    msg.Data,
+	}
+}
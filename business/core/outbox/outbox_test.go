@@ -0,0 +1,99 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+	"github.com/mrcruz117/al-service/business/core/outbox/stores/outboxmem"
+	"github.com/mrcruz117/al-service/foundation/events"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Test_Relay adds events while the broker is down and checks none are lost
+// or published out of order once it is back.
+func Test_Relay(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+	ctx := context.Background()
+
+	pub := broker{down: true}
+	core := outbox.NewCore(log, outboxmem.NewStore(), beginner{}, &pub, "test")
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := core.Add(ctx, "user.created", 1, key, map[string]string{"userID": key}); err != nil {
+			t.Fatalf("Should be able to add an event : %s", err)
+		}
+	}
+
+	n, err := core.Relay(ctx, 2)
+	if err == nil || n != 0 {
+		t.Fatalf("Should fail to publish while the broker is down, got %d published, %v", n, err)
+	}
+
+	stats, err := core.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Should be able to get the stats : %s", err)
+	}
+	if stats.Depth != 3 {
+		t.Errorf("Should keep every event pending, got %d", stats.Depth)
+	}
+
+	pub.down = false
+
+	if n, err := core.Relay(ctx, 2); err != nil || n != 2 {
+		t.Fatalf("Should publish a batch once the broker is back, got %d published, %v", n, err)
+	}
+
+	if n, err := core.Relay(ctx, 2); err != nil || n != 1 {
+		t.Fatalf("Should publish the rest, got %d published, %v", n, err)
+	}
+
+	if n, err := core.Relay(ctx, 2); err != nil || n != 0 {
+		t.Fatalf("Should have nothing left to publish, got %d published, %v", n, err)
+	}
+
+	var keys string
+	for _, evt := range pub.published {
+		keys += evt.Key
+
+		if evt.Type != "user.created" || evt.Version != 1 || evt.Source != "test" {
+			t.Errorf("Should publish the event as added, got %+v", evt)
+		}
+	}
+
+	if keys != "abc" {
+		t.Errorf("Should publish the events in the order they were added, got %q", keys)
+	}
+}
+
+// broker records the events published, or fails while it's down.
+type broker struct {
+	down      bool
+	published []events.Event
+}
+
+func (b *broker) Publish(ctx context.Context, evt events.Event) error {
+	if b.down {
+		return errors.New("broker is down")
+	}
+
+	b.published = append(b.published, evt)
+
+	return nil
+}
+
+func (b *broker) Close() error { return nil }
+
+// beginner begins transactions that do nothing, the in-memory store applies
+// every write as it is made.
+type beginner struct{}
+
+func (beginner) Begin(ctx context.Context) (transaction.Transaction, error) { return tx{}, nil }
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
@@ -0,0 +1,53 @@
+package outboxdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+)
+
+type dbMessage struct {
+	Seq           int64          `db:"seq"`
+	ID            uuid.UUID      `db:"message_id"`
+	Type          string         `db:"type"`
+	Version       int            `db:"version"`
+	Key           string         `db:"event_key"`
+	Data          string         `db:"data"`
+	Attempts      int            `db:"attempts"`
+	LastError     sql.NullString `db:"last_error"`
+	DateCreated   time.Time      `db:"date_created"`
+	DatePublished sql.NullTime   `db:"date_published"`
+}
+
+func toDBMessage(msg outbox.Message) dbMessage {
+	return dbMessage{
+		ID:          msg.ID,
+		Type:        msg.Type,
+		Version:     msg.Version,
+		Key:         msg.Key,
+		Data:        string(msg.Data),
+		DateCreated: msg.DateCreated.UTC(),
+	}
+}
+
+func toCoreMessage(dbMsg dbMessage) outbox.Message {
+	msg := outbox.Message{
+		Seq:         dbMsg.Seq,
+		ID:          dbMsg.ID,
+		Type:        dbMsg.Type,
+		Version:     dbMsg.Version,
+		Key:         dbMsg.Key,
+		Data:        []byte(dbMsg.Data),
+		Attempts:    dbMsg.Attempts,
+		LastError:   dbMsg.LastError.String,
+		DateCreated: dbMsg.DateCreated.In(time.Local),
+	}
+
+	if dbMsg.DatePublished.Valid {
+		msg.DatePublished = dbMsg.DatePublished.Time.In(time.Local)
+	}
+
+	return msg
+}
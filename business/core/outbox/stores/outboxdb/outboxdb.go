@@ -0,0 +1,186 @@
+// Package outboxdb contains outbox related CRUD functionality.
+package outboxdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for outbox database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (outbox.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new message into the database. The sequence number is
+// assigned by the database.
+func (s *Store) Create(ctx context.Context, msg outbox.Message) error {
+	const q = `
+	INSERT INTO outbox
+		(message_id, type, version, event_key, data, date_created)
+	VALUES
+		(:message_id, :type, :version, :event_key, :data, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMessage(msg)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryPending retrieves up to limit messages that haven't been published,
+// in the order they were added. The rows are locked until the transaction
+// ends and rows another transaction has locked are skipped.
+func (s *Store) QueryPending(ctx context.Context, limit int) ([]outbox.Message, error) {
+	data := map[string]any{
+		"limit": limit,
+	}
+
+	const q = `
+	SELECT
+		seq, message_id, type, version, event_key, data, attempts, last_error, date_created, date_published
+	FROM
+		outbox
+	WHERE
+		date_published IS NULL
+	ORDER BY
+		seq
+	LIMIT :limit
+	FOR UPDATE SKIP LOCKED`
+
+	var dbMsgs []dbMessage
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbMsgs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	msgs := make([]outbox.Message, len(dbMsgs))
+	for i, dbMsg := range dbMsgs {
+		msgs[i] = toCoreMessage(dbMsg)
+	}
+
+	return msgs, nil
+}
+
+// MarkPublished records the messages were published.
+func (s *Store) MarkPublished(ctx context.Context, msgIDs []uuid.UUID, published time.Time) error {
+	ids := make([]string, len(msgIDs))
+	for i, id := range msgIDs {
+		ids[i] = id.String()
+	}
+
+	data := map[string]any{
+		"message_ids":    ids,
+		"date_published": published.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		outbox
+	SET
+		date_published = :date_published
+	WHERE
+		message_id IN (:message_ids)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt to publish the message.
+func (s *Store) MarkFailed(ctx context.Context, msgID uuid.UUID, reason string) error {
+	data := map[string]any{
+		"message_id": msgID.String(),
+		"last_error": reason,
+	}
+
+	const q = `
+	UPDATE
+		outbox
+	SET
+		attempts = attempts + 1,
+		last_error = :last_error
+	WHERE
+		message_id = :message_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePublished removes the messages published before the time.
+func (s *Store) DeletePublished(ctx context.Context, before time.Time) error {
+	data := map[string]any{
+		"before": before.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		outbox
+	WHERE
+		date_published < :before`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Pending returns the number of messages that haven't been published and
+// when the oldest of them was added.
+func (s *Store) Pending(ctx context.Context) (int, time.Time, error) {
+	const q = `
+	SELECT
+		COUNT(*) AS count, MIN(date_created) AS oldest
+	FROM
+		outbox
+	WHERE
+		date_published IS NULL`
+
+	var pending struct {
+		Count  int          `db:"count"`
+		Oldest sql.NullTime `db:"oldest"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, struct{}{}, &pending); err != nil {
+		return 0, time.Time{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return pending.Count, pending.Oldest.Time.In(time.Local), nil
+}
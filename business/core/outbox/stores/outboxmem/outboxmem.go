@@ -0,0 +1,127 @@
+// Package outboxmem contains an in-memory implementation of the outbox
+// store for unit tests. Sequence numbers are assigned by the store, like
+// the database does, so it is written by hand rather than generated.
+package outboxmem
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+)
+
+// Store manages the set of APIs for outbox in-memory access.
+type Store struct {
+	mu    sync.Mutex
+	seq   int64
+	table *memstore.Table[uuid.UUID, outbox.Message]
+}
+
+// NewStore constructs the api for in-memory access.
+func NewStore() *Store {
+	return &Store{
+		table: memstore.New(
+			func(msg outbox.Message) uuid.UUID { return msg.ID },
+		),
+	}
+}
+
+var _ outbox.Storer = (*Store)(nil)
+
+// ExecuteUnderTransaction returns the store itself, the in-memory tables
+// apply every write as it is made.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (outbox.Storer, error) {
+	return s, nil
+}
+
+// Create inserts the message into the store with the next sequence number.
+func (s *Store) Create(ctx context.Context, msg outbox.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	msg.Seq = s.seq
+
+	if err := s.table.Insert(msg); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+// QueryPending retrieves up to limit messages that haven't been published
+// in sequence order. Nothing is locked, the tests run a single relay.
+func (s *Store) QueryPending(ctx context.Context, limit int) ([]outbox.Message, error) {
+	msgs := s.table.Query(memstore.Query[outbox.Message]{
+		Match: pending,
+		Less: func(a outbox.Message, b outbox.Message) bool {
+			return a.Seq < b.Seq
+		},
+		Limit: limit,
+	})
+
+	return msgs, nil
+}
+
+// MarkPublished records the messages were published.
+func (s *Store) MarkPublished(ctx context.Context, msgIDs []uuid.UUID, published time.Time) error {
+	s.table.UpdateFunc(
+		func(msg outbox.Message) bool { return slices.Contains(msgIDs, msg.ID) },
+		func(msg outbox.Message) outbox.Message {
+			msg.DatePublished = published
+			return msg
+		},
+	)
+
+	return nil
+}
+
+// MarkFailed records a failed attempt to publish the message.
+func (s *Store) MarkFailed(ctx context.Context, msgID uuid.UUID, reason string) error {
+	s.table.UpdateFunc(
+		func(msg outbox.Message) bool { return msg.ID == msgID },
+		func(msg outbox.Message) outbox.Message {
+			msg.Attempts++
+			msg.LastError = reason
+			return msg
+		},
+	)
+
+	return nil
+}
+
+// DeletePublished removes the messages published before the time.
+func (s *Store) DeletePublished(ctx context.Context, before time.Time) error {
+	msgs := s.table.Query(memstore.Query[outbox.Message]{
+		Match: func(msg outbox.Message) bool {
+			return !msg.DatePublished.IsZero() && msg.DatePublished.Before(before)
+		},
+	})
+
+	for _, msg := range msgs {
+		s.table.Delete(msg.ID)
+	}
+
+	return nil
+}
+
+// Pending returns the number of messages that haven't been published and
+// when the oldest of them was added.
+func (s *Store) Pending(ctx context.Context) (int, time.Time, error) {
+	msgs, _ := s.QueryPending(ctx, 0)
+	if len(msgs) == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	return len(msgs), msgs[0].DateCreated, nil
+}
+
+func pending(msg outbox.Message) bool {
+	return msg.DatePublished.IsZero()
+}
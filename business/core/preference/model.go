@@ -0,0 +1,17 @@
+package preference
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Preferences represents the settings document a user keeps with the
+// system. Version increases with every change and is used to reject writes
+// based on a stale copy of the document.
+type Preferences struct {
+	UserID      uuid.UUID
+	Values      map[string]any
+	Version     int
+	DateUpdated time.Time
+}
@@ -0,0 +1,258 @@
+// Package preference provides business access to the settings users keep
+// with the system, so frontends can store what they need without a column
+// per setting.
+package preference
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound        = errors.New("preferences not found")
+	ErrVersionConflict = errors.New("preferences were changed by another request")
+	ErrTooLarge        = errors.New("preferences document is too large")
+	ErrInvalid         = errors.New("preferences don't match the schema")
+)
+
+// encPrefix marks a value that was encrypted before it was stored.
+const encPrefix = "enc:v1:"
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, prefs Preferences, document string) error
+	Update(ctx context.Context, prefs Preferences, document string, version int) error
+	QueryByUserID(ctx context.Context, userID uuid.UUID) (Preferences, string, error)
+}
+
+// Config represents the settings for storing preferences.
+type Config struct {
+
+	// Schema declares the preferences that can be stored.
+	Schema Schema
+
+	// MaxBytes is the largest encoded document that can be stored.
+	MaxBytes int
+
+	// Key is the 32 byte AES key used to encrypt sensitive preferences. It's
+	// only required when the schema has sensitive preferences.
+	Key []byte
+}
+
+// Core manages the set of APIs for preference access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+	cfg    Config
+	aead   cipher.AEAD
+}
+
+// NewCore constructs a core for preference api access.
+func NewCore(log *logger.Logger, storer Storer, cfg Config) (*Core, error) {
+	c := Core{
+		log:    log,
+		storer: storer,
+		cfg:    cfg,
+	}
+
+	if cfg.Schema.hasSensitive() {
+		block, err := aes.NewCipher(cfg.Key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key: %w", err)
+		}
+
+		if len(cfg.Key) != 32 {
+			return nil, fmt.Errorf("encryption key: must be 32 bytes, got %d", len(cfg.Key))
+		}
+
+		if c.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, fmt.Errorf("gcm: %w", err)
+		}
+	}
+
+	return &c, nil
+}
+
+// Query returns the preferences of the user. A user who never stored any
+// gets an empty document at version 0.
+func (c *Core) Query(ctx context.Context, userID uuid.UUID) (Preferences, error) {
+	prefs, document, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Preferences{UserID: userID, Values: map[string]any{}}, nil
+		}
+		return Preferences{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	if prefs.Values, err = c.decode(userID, document); err != nil {
+		return Preferences{}, fmt.Errorf("decode: userID[%s]: %w", userID, err)
+	}
+
+	return prefs, nil
+}
+
+// Save replaces the preferences of the user if the stored document is still
+// at the specified version, which is 0 for a user who has none yet.
+func (c *Core) Save(ctx context.Context, userID uuid.UUID, values map[string]any, version int) (Preferences, error) {
+	if err := c.validate(values); err != nil {
+		return Preferences{}, err
+	}
+
+	document, err := c.encode(userID, values)
+	if err != nil {
+		return Preferences{}, fmt.Errorf("encode: %w", err)
+	}
+
+	if c.cfg.MaxBytes > 0 && len(document) > c.cfg.MaxBytes {
+		return Preferences{}, fmt.Errorf("%w: %d bytes, limit is %d", ErrTooLarge, len(document), c.cfg.MaxBytes)
+	}
+
+	prefs := Preferences{
+		UserID:      userID,
+		Values:      values,
+		Version:     version + 1,
+		DateUpdated: time.Now(),
+	}
+
+	if version == 0 {
+		err = c.storer.Create(ctx, prefs, document)
+	} else {
+		err = c.storer.Update(ctx, prefs, document, version)
+	}
+
+	if err != nil {
+		return Preferences{}, fmt.Errorf("save: userID[%s]: %w", userID, err)
+	}
+
+	return prefs, nil
+}
+
+// validate checks every value is declared by the schema with the right type.
+func (c *Core) validate(values map[string]any) error {
+	var problems []string
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		f, exists := c.cfg.Schema[name]
+		switch {
+		case !exists:
+			problems = append(problems, fmt.Sprintf("%s: is not a known preference", name))
+		case !f.check(values[name]):
+			problems = append(problems, fmt.Sprintf("%s: must be of type %s", name, f.Type))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// encode marshals the document, encrypting the sensitive values. The user ID
+// is bound to every encrypted value so it can't be moved to another user's
+// document.
+func (c *Core) encode(userID uuid.UUID, values map[string]any) (string, error) {
+	stored := make(map[string]any, len(values))
+
+	for name, v := range values {
+		if !c.cfg.Schema[name].Sensitive || v == nil {
+			stored[name] = v
+			continue
+		}
+
+		enc, err := c.encrypt(userID, v)
+		if err != nil {
+			return "", fmt.Errorf("encrypt[%s]: %w", name, err)
+		}
+		stored[name] = enc
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// decode unmarshals the document, decrypting the sensitive values.
+func (c *Core) decode(userID uuid.UUID, document string) (map[string]any, error) {
+	var values map[string]any
+	if err := json.Unmarshal([]byte(document), &values); err != nil {
+		return nil, err
+	}
+
+	for name, v := range values {
+		s, ok := v.(string)
+		if !ok || !c.cfg.Schema[name].Sensitive || !strings.HasPrefix(s, encPrefix) {
+			continue
+		}
+
+		dec, err := c.decrypt(userID, s)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt[%s]: %w", name, err)
+		}
+		values[name] = dec
+	}
+
+	return values, nil
+}
+
+func (c *Core) encrypt(userID uuid.UUID, v any) (string, error) {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plain, userID[:])
+
+	return encPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *Core) decrypt(userID uuid.UUID, s string) (any, error) {
+	if c.aead == nil {
+		return nil, errors.New("no encryption key configured")
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(s, encPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	n := c.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	plain, err := c.aead.Open(nil, sealed[:n], sealed[n:], userID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var v any
+	if err := json.Unmarshal(plain, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
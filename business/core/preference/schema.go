@@ -0,0 +1,83 @@
+package preference
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Type represents the kind of value a preference holds.
+type Type string
+
+// Set of types a preference can have.
+const (
+	String Type = "string"
+	Number Type = "number"
+	Bool   Type = "bool"
+	Object Type = "object"
+	Array  Type = "array"
+)
+
+// Field describes a preference that can be stored. Sensitive preferences are
+// encrypted before they are written to the database.
+type Field struct {
+	Type      Type `json:"type"`
+	Sensitive bool `json:"sensitive"`
+}
+
+// Schema declares the preferences that can be stored, keyed by name.
+type Schema map[string]Field
+
+// LoadSchema reads the schema from the specified json file.
+func LoadSchema(file string) (Schema, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	for name, f := range schema {
+		switch f.Type {
+		case String, Number, Bool, Object, Array:
+		default:
+			return nil, fmt.Errorf("preference[%s]: unknown type %q", name, f.Type)
+		}
+	}
+
+	return schema, nil
+}
+
+// hasSensitive reports whether any preference needs to be encrypted.
+func (s Schema) hasSensitive() bool {
+	for _, f := range s {
+		if f.Sensitive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check validates the value against the type of the field.
+func (f Field) check(v any) bool {
+	switch v.(type) {
+	case nil:
+		return true
+	case string:
+		return f.Type == String
+	case float64, json.Number:
+		return f.Type == Number
+	case bool:
+		return f.Type == Bool
+	case map[string]any:
+		return f.Type == Object
+	case []any:
+		return f.Type == Array
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package preferencedb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/preference"
+)
+
+type dbPreferences struct {
+	UserID      uuid.UUID `db:"user_id"`
+	Document    string    `db:"document"`
+	Version     int       `db:"version"`
+	DateUpdated time.Time `db:"date_updated"`
+}
+
+func toDBPreferences(prefs preference.Preferences, document string) dbPreferences {
+	return dbPreferences{
+		UserID:      prefs.UserID,
+		Document:    document,
+		Version:     prefs.Version,
+		DateUpdated: prefs.DateUpdated.UTC(),
+	}
+}
+
+func toCorePreferences(dbPrefs dbPreferences) preference.Preferences {
+	return preference.Preferences{
+		UserID:      dbPrefs.UserID,
+		Version:     dbPrefs.Version,
+		DateUpdated: dbPrefs.DateUpdated.In(time.Local),
+	}
+}
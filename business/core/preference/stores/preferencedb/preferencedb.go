@@ -0,0 +1,101 @@
+// Package preferencedb contains user preference related CRUD functionality.
+package preferencedb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/preference"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for preference database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts the first version of a user's preferences into the
+// database. A user who already has preferences gets ErrVersionConflict.
+func (s *Store) Create(ctx context.Context, prefs preference.Preferences, document string) error {
+	const q = `
+	INSERT INTO user_preferences
+		(user_id, document, version, date_updated)
+	VALUES
+		(:user_id, :document, :version, :date_updated)`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBPreferences(prefs, document)); err != nil {
+		if errors.Is(sqldb.TranslateError(err), sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", preference.ErrVersionConflict)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a user's preferences in the database if they are still at
+// the specified version.
+func (s *Store) Update(ctx context.Context, prefs preference.Preferences, document string, version int) error {
+	const q = `
+	UPDATE
+		user_preferences
+	SET
+		"document" = ?,
+		"version" = ?,
+		"date_updated" = ?
+	WHERE
+		user_id = ? AND "version" = ?`
+
+	dbPrefs := toDBPreferences(prefs, document)
+
+	res, err := s.db.ExecContext(ctx, s.db.Rebind(q), dbPrefs.Document, dbPrefs.Version, dbPrefs.DateUpdated, dbPrefs.UserID, version)
+	if err != nil {
+		return fmt.Errorf("execcontext: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rowsaffected: %w", err)
+	}
+
+	if n == 0 {
+		return fmt.Errorf("execcontext: %w", preference.ErrVersionConflict)
+	}
+
+	return nil
+}
+
+// QueryByUserID gets the preferences of the specified user from the
+// database along with the stored document.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) (preference.Preferences, string, error) {
+	const q = `
+	SELECT
+		user_id, document, version, date_updated
+	FROM
+		user_preferences
+	WHERE
+		user_id = ?`
+
+	var dbPrefs dbPreferences
+	if err := s.db.GetContext(ctx, &dbPrefs, s.db.Rebind(q), userID); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return preference.Preferences{}, "", fmt.Errorf("getcontext: %w", preference.ErrNotFound)
+		}
+		return preference.Preferences{}, "", fmt.Errorf("getcontext: %w", err)
+	}
+
+	return toCorePreferences(dbPrefs), dbPrefs.Document, nil
+}
@@ -0,0 +1,80 @@
+// Package preferencemem contains an in-memory implementation of the
+// preference store for unit tests. The store keeps the document next to the
+// preferences, which doesn't follow the conventions storegen knows, so it is
+// written by hand.
+package preferencemem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/preference"
+)
+
+// row represents the preferences of a user as they are stored.
+type row struct {
+	prefs    preference.Preferences
+	document string
+}
+
+// Store manages the set of APIs for preference in-memory access.
+type Store struct {
+	table *memstore.Table[uuid.UUID, row]
+}
+
+// NewStore constructs the api for in-memory access.
+func NewStore() *Store {
+	return &Store{
+		table: memstore.New(func(r row) uuid.UUID { return r.prefs.UserID }),
+	}
+}
+
+var _ preference.Storer = (*Store)(nil)
+
+// Create inserts the first version of a user's preferences into the store.
+func (s *Store) Create(ctx context.Context, prefs preference.Preferences, document string) error {
+	if err := s.table.Insert(row{prefs: prefs, document: document}); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("insert: %w", preference.ErrVersionConflict)
+		}
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a user's preferences in the store if they are still at
+// the specified version.
+func (s *Store) Update(ctx context.Context, prefs preference.Preferences, document string, version int) error {
+	match := func(r row) bool {
+		return r.prefs.UserID == prefs.UserID && r.prefs.Version == version
+	}
+
+	update := func(r row) row {
+		return row{prefs: prefs, document: document}
+	}
+
+	if n := s.table.UpdateFunc(match, update); n == 0 {
+		return fmt.Errorf("update: %w", preference.ErrVersionConflict)
+	}
+
+	return nil
+}
+
+// QueryByUserID gets the preferences of the specified user from the store
+// along with the stored document.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) (preference.Preferences, string, error) {
+	r, err := s.table.Get(userID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return preference.Preferences{}, "", fmt.Errorf("query: %w", preference.ErrNotFound)
+		}
+		return preference.Preferences{}, "", fmt.Errorf("query: %w", err)
+	}
+
+	return r.prefs, r.document, nil
+}
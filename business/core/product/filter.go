@@ -0,0 +1,36 @@
+package product
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on. Fields
+// that are nil aren't filtered on. Soft deleted products are only included
+// WithDeleted.
+type QueryFilter struct {
+	ID          *uuid.UUID
+	UserID      *uuid.UUID
+	Name        *string
+	MinCost     *float64
+	MaxCost     *float64
+	WithDeleted bool
+
+	// AfterID only returns the products with a greater id, for reading
+	// through them in id order.
+	AfterID *uuid.UUID
+}
+
+// Validate checks the filter is considered clean before it is used.
+func (qf QueryFilter) Validate() error {
+	if qf.Name != nil && *qf.Name == "" {
+		return errors.New("name filter must not be empty")
+	}
+
+	if qf.MinCost != nil && qf.MaxCost != nil && *qf.MaxCost < *qf.MinCost {
+		return errors.New("max cost must not be less than min cost")
+	}
+
+	return nil
+}
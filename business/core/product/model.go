@@ -0,0 +1,61 @@
+package product
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product represents an individual product. TenantID is uuid.Nil for a
+// product that belongs to no tenant and DateDeleted is zero unless the
+// product was soft deleted.
+type Product struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	UserID      uuid.UUID
+	Name        string
+	Cost        float64
+	Quantity    int
+	Version     int
+	DateCreated time.Time
+	DateUpdated time.Time
+	DateDeleted time.Time
+}
+
+// NewProduct is what we require from clients when adding a Product.
+type NewProduct struct {
+	UserID   uuid.UUID
+	Name     string
+	Cost     float64
+	Quantity int
+}
+
+// UpdateProduct defines what information may be provided to modify an
+// existing Product. Fields that are nil are left unchanged. Version, when
+// set, is the version of the product the change was made against.
+type UpdateProduct struct {
+	Name     *string
+	Cost     *float64
+	Quantity *int
+	Version  *int
+}
+
+// UpsertProduct defines what is required to create a product with the ID
+// the client chose or replace the product that already has it. The user
+// only becomes the owner when the product is created. Version, when set, is
+// the version of the product the replacement was made against, and
+// CreateOnly refuses to replace a product that exists.
+type UpsertProduct struct {
+	NewProduct
+	Version    *int
+	CreateOnly bool
+}
+
+// SearchResult represents a product that matched a search. A higher Rank
+// is a better match and Highlight is the name of the product as HTML, with
+// the words that matched the search wrapped in mark elements.
+type SearchResult struct {
+	Product   Product
+	Rank      float64
+	Highlight string
+}
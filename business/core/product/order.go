@@ -0,0 +1,15 @@
+package product
+
+import "github.com/mrcruz117/al-service/business/api/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByID, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID       = "id"
+	OrderByUserID   = "user_id"
+	OrderByName     = "name"
+	OrderByCost     = "cost"
+	OrderByQuantity = "quantity"
+)
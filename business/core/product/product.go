@@ -0,0 +1,386 @@
+// Package product provides business access to product data in the system.
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound        = errors.New("product not found")
+	ErrUserDisabled    = errors.New("user disabled")
+	ErrVersionConflict = errors.New("product was changed by another request")
+	ErrExists          = errors.New("product already exists")
+	ErrInvalidID       = errors.New("product id must not be nil")
+	ErrInvalidQuery    = errors.New("search query must have a word to search for")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, prd Product) error
+	Update(ctx context.Context, prd Product) error
+	Upsert(ctx context.Context, prd Product) error
+	Delete(ctx context.Context, prd Product) error
+	Restore(ctx context.Context, productID uuid.UUID, dateUpdated time.Time) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]Product, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, productID uuid.UUID) (Product, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error)
+	Search(ctx context.Context, query string, pg page.Page) ([]SearchResult, error)
+	SearchCount(ctx context.Context, query string) (int, error)
+}
+
+// Core manages the set of APIs for product access.
+type Core struct {
+	log      *logger.Logger
+	storer   Storer
+	userCore *user.Core
+}
+
+// NewCore constructs a core for product api access.
+func NewCore(log *logger.Logger, storer Storer, userCore *user.Core) *Core {
+	return &Core{
+		log:      log,
+		storer:   storer,
+		userCore: userCore,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls, including the ones the
+// user core makes.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userCore, err := c.userCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	core := Core{
+		log:      c.log,
+		storer:   storer,
+		userCore: userCore,
+	}
+
+	return &core, nil
+}
+
+// Create adds a new product to the system. The product is owned by the
+// user, who must exist and be enabled, and belongs to the tenant the
+// context is scoped to, if any.
+func (c *Core) Create(ctx context.Context, np NewProduct) (Product, error) {
+	tenantID, err := tenant.OwnerFromContext(ctx)
+	if err != nil {
+		return Product{}, err
+	}
+
+	usr, err := c.userCore.QueryByID(ctx, np.UserID)
+	if err != nil {
+		return Product{}, fmt.Errorf("user.querybyid: %s: %w", np.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return Product{}, ErrUserDisabled
+	}
+
+	now := time.Now()
+
+	prd := Product{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		UserID:      np.UserID,
+		Name:        np.Name,
+		Cost:        np.Cost,
+		Quantity:    np.Quantity,
+		Version:     1,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.storer.Create(ctx, prd); err != nil {
+		return Product{}, fmt.Errorf("create: %w", err)
+	}
+
+	return prd, nil
+}
+
+// Update modifies information about a product. The update only applies if
+// the product is still at the version it was read at, and at the expected
+// version when one is given, otherwise ErrVersionConflict is returned.
+func (c *Core) Update(ctx context.Context, prd Product, up UpdateProduct) (Product, error) {
+	if err := checkTenant(ctx, prd); err != nil {
+		return Product{}, fmt.Errorf("update: productID[%s]: %w", prd.ID, err)
+	}
+
+	if up.Version != nil && *up.Version != prd.Version {
+		return Product{}, fmt.Errorf("update: version[%d] expected[%d]: %w", prd.Version, *up.Version, ErrVersionConflict)
+	}
+
+	if up.Name != nil {
+		prd.Name = *up.Name
+	}
+
+	if up.Cost != nil {
+		prd.Cost = *up.Cost
+	}
+
+	if up.Quantity != nil {
+		prd.Quantity = *up.Quantity
+	}
+
+	prd.Version++
+	prd.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, prd); err != nil {
+		return Product{}, fmt.Errorf("update: %w", err)
+	}
+
+	return prd, nil
+}
+
+// Upsert creates the product with the ID supplied by the caller or replaces
+// the name, cost and quantity of the product that already has it, so a sync
+// job can apply the same request any number of times. The returned flag
+// reports whether the product was created. ErrExists is returned for a
+// product that exists when only its creation was asked for, and for an ID
+// taken by a product the context can't see.
+func (c *Core) Upsert(ctx context.Context, productID uuid.UUID, up UpsertProduct) (Product, bool, error) {
+	if productID == uuid.Nil {
+		return Product{}, false, ErrInvalidID
+	}
+
+	tenantID, err := tenant.OwnerFromContext(ctx)
+	if err != nil {
+		return Product{}, false, err
+	}
+
+	now := time.Now()
+
+	prd, err := c.storer.QueryByID(ctx, productID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		usr, err := c.userCore.QueryByID(ctx, up.UserID)
+		if err != nil {
+			return Product{}, false, fmt.Errorf("user.querybyid: %s: %w", up.UserID, err)
+		}
+
+		if !usr.Enabled {
+			return Product{}, false, ErrUserDisabled
+		}
+
+		prd = Product{
+			ID:          productID,
+			TenantID:    tenantID,
+			UserID:      up.UserID,
+			Name:        up.Name,
+			Cost:        up.Cost,
+			Quantity:    up.Quantity,
+			Version:     1,
+			DateCreated: now,
+			DateUpdated: now,
+		}
+
+		// A product at version 1 never replaces a stored one, so a conflict
+		// means another request created it first or the ID is taken by a
+		// product in another tenant or deleted.
+		if err := c.storer.Upsert(ctx, prd); err != nil {
+			if errors.Is(err, ErrVersionConflict) {
+				return Product{}, false, fmt.Errorf("create: productID[%s]: %w", productID, ErrExists)
+			}
+			return Product{}, false, fmt.Errorf("create: %w", err)
+		}
+
+		return prd, true, nil
+
+	case err != nil:
+		return Product{}, false, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	if up.CreateOnly {
+		return Product{}, false, fmt.Errorf("create: productID[%s]: %w", productID, ErrExists)
+	}
+
+	if up.Version != nil && *up.Version != prd.Version {
+		return Product{}, false, fmt.Errorf("replace: version[%d] expected[%d]: %w", prd.Version, *up.Version, ErrVersionConflict)
+	}
+
+	prd.Name = up.Name
+	prd.Cost = up.Cost
+	prd.Quantity = up.Quantity
+	prd.Version++
+	prd.DateUpdated = now
+
+	if err := c.storer.Upsert(ctx, prd); err != nil {
+		return Product{}, false, fmt.Errorf("replace: %w", err)
+	}
+
+	return prd, false, nil
+}
+
+// Delete soft deletes the specified product. It is left out of queries
+// from then on but its orders keep referring to it, and it can be restored.
+func (c *Core) Delete(ctx context.Context, prd Product) error {
+	if err := checkTenant(ctx, prd); err != nil {
+		return fmt.Errorf("delete: productID[%s]: %w", prd.ID, err)
+	}
+
+	now := time.Now()
+
+	prd.Version++
+	prd.DateUpdated = now
+	prd.DateDeleted = now
+
+	if err := c.storer.Delete(ctx, prd); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Restore brings back a soft deleted product. Restoring a product that
+// isn't deleted changes nothing.
+func (c *Core) Restore(ctx context.Context, productID uuid.UUID) (Product, error) {
+	if err := c.storer.Restore(ctx, productID, time.Now()); err != nil {
+		return Product{}, fmt.Errorf("restore: productID[%s]: %w", productID, err)
+	}
+
+	return c.QueryByID(ctx, productID)
+}
+
+// Query retrieves a page of products matching the filter, in the order
+// asked for.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]Product, error) {
+	prds, err := c.storer.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return prds, nil
+}
+
+// Count returns the total number of products matching the filter.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	n, err := c.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+
+	return n, nil
+}
+
+// Export delivers every product matching the filter to fn in id order, a
+// batch at a time. Each batch is read after the last id of the one before
+// rather than at an offset, so the set can be of any size and products
+// added while it runs don't shift the batches. It stops at the first error
+// fn returns.
+func (c *Core) Export(ctx context.Context, filter QueryFilter, fn func(prds []Product) error) error {
+	pg := page.MustParse("1", strconv.Itoa(page.MaxRows))
+	orderBy := order.NewBy(OrderByID, order.ASC)
+
+	for {
+		prds, err := c.storer.Query(ctx, filter, orderBy, pg)
+		if err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+
+		if len(prds) > 0 {
+			if err := fn(prds); err != nil {
+				return err
+			}
+		}
+
+		if len(prds) < pg.RowsPerPage() {
+			return nil
+		}
+
+		lastID := prds[len(prds)-1].ID
+		filter.AfterID = &lastID
+	}
+}
+
+// Search retrieves a page of the products whose name matches the query,
+// best match first. Deleted products are left out.
+func (c *Core) Search(ctx context.Context, query string, pg page.Page) ([]SearchResult, error) {
+	terms, err := searchTerms(query)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.storer.Search(ctx, query, pg)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	for i := range results {
+		results[i].Highlight = highlight(results[i].Product.Name, terms)
+	}
+
+	return results, nil
+}
+
+// SearchCount returns the total number of products whose name matches the
+// query.
+func (c *Core) SearchCount(ctx context.Context, query string) (int, error) {
+	if _, err := searchTerms(query); err != nil {
+		return 0, err
+	}
+
+	n, err := c.storer.SearchCount(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("searchcount: %w", err)
+	}
+
+	return n, nil
+}
+
+// QueryByID finds the product by the specified ID.
+func (c *Core) QueryByID(ctx context.Context, productID uuid.UUID) (Product, error) {
+	prd, err := c.storer.QueryByID(ctx, productID)
+	if err != nil {
+		return Product{}, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	if err := checkTenant(ctx, prd); err != nil {
+		return Product{}, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	return prd, nil
+}
+
+// QueryByUserID finds the products owned by the specified user.
+func (c *Core) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error) {
+	prds, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return prds, nil
+}
+
+// checkTenant denies access to a product of another tenant than the one the
+// context is scoped to by reporting it as not found.
+func checkTenant(ctx context.Context, prd Product) error {
+	if err := tenant.CheckAccess(ctx, prd.TenantID); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	return nil
+}
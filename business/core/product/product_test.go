@@ -0,0 +1,123 @@
+package product_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/product/stores/productmem"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usermem"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Test_Upsert creates a product with an ID chosen by the caller, replaces
+// it, and checks a request that may only create it, or one made in another
+// tenant, is refused once the ID is taken.
+func Test_Upsert(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	userCore := user.NewCore(log, delegate.New(log), usermem.NewStore())
+	productCore := product.NewCore(log, productmem.NewStore(), userCore)
+
+	ctxA := tenant.WithID(context.Background(), uuid.New())
+	ctxB := tenant.WithID(context.Background(), uuid.New())
+
+	newUser := func(ctx context.Context, email string) user.User {
+		t.Helper()
+
+		usr, err := userCore.Create(ctx, user.NewUser{
+			Name:     "Gopher",
+			Email:    mail.Address{Address: email},
+			Roles:    []string{"USER"},
+			Password: "gophers",
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a user : %s", err)
+		}
+
+		return usr
+	}
+
+	usrA := newUser(ctxA, "a@example.com")
+	usrB := newUser(ctxB, "b@example.com")
+
+	productID := uuid.New()
+
+	up := product.UpsertProduct{
+		NewProduct: product.NewProduct{UserID: usrA.ID, Name: "Widget", Cost: 10, Quantity: 1},
+	}
+
+	// -------------------------------------------------------------------------
+
+	if _, _, err := productCore.Upsert(ctxA, uuid.Nil, up); !errors.Is(err, product.ErrInvalidID) {
+		t.Errorf("Should refuse a nil id, got %v", err)
+	}
+
+	prd, created, err := productCore.Upsert(ctxA, productID, up)
+	if err != nil {
+		t.Fatalf("Should be able to create the product : %s", err)
+	}
+	if !created || prd.ID != productID || prd.Version != 1 {
+		t.Errorf("Should create the product with the id at version 1, got created %t, %s at version %d", created, prd.ID, prd.Version)
+	}
+
+	// -------------------------------------------------------------------------
+
+	up.Name = "Gadget"
+	up.Version = &prd.Version
+
+	prd, created, err = productCore.Upsert(ctxA, productID, up)
+	if err != nil {
+		t.Fatalf("Should be able to replace the product : %s", err)
+	}
+	if created || prd.Name != "Gadget" || prd.Version != 2 {
+		t.Errorf("Should replace the product, got created %t, %q at version %d", created, prd.Name, prd.Version)
+	}
+
+	got, err := productCore.QueryByID(ctxA, productID)
+	if err != nil {
+		t.Fatalf("Should be able to retrieve the product : %s", err)
+	}
+	if got.Name != "Gadget" || got.Version != 2 {
+		t.Errorf("Should store the replacement, got %q at version %d", got.Name, got.Version)
+	}
+
+	// The version sent is now out of date.
+	stale := 1
+	up.Version = &stale
+
+	if _, _, err := productCore.Upsert(ctxA, productID, up); !errors.Is(err, product.ErrVersionConflict) {
+		t.Errorf("Should refuse a replacement of an older version, got %v", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	up.Version = nil
+	up.CreateOnly = true
+
+	if _, _, err := productCore.Upsert(ctxA, productID, up); !errors.Is(err, product.ErrExists) {
+		t.Errorf("Should refuse to only create a product that exists, got %v", err)
+	}
+
+	up.UserID = usrB.ID
+	up.CreateOnly = false
+
+	if _, _, err := productCore.Upsert(ctxB, productID, up); !errors.Is(err, product.ErrExists) {
+		t.Errorf("Should refuse an id taken in another tenant, got %v", err)
+	}
+
+	got, err = productCore.QueryByID(ctxA, productID)
+	if err != nil {
+		t.Fatalf("Should be able to retrieve the product : %s", err)
+	}
+	if got.Name != "Gadget" || got.Version != 2 || got.UserID != usrA.ID {
+		t.Errorf("Should leave the product as it was, got %+v", got)
+	}
+}
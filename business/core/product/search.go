@@ -0,0 +1,89 @@
+package product
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxSearchLength bounds the length of a search query.
+const maxSearchLength = 200
+
+// SearchTerms splits the text into the lower cased words that are searched
+// for, in the order they first appear.
+func SearchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	for _, w := range words {
+		if !slices.Contains(terms, w) {
+			terms = append(terms, w)
+		}
+	}
+
+	return terms
+}
+
+// searchTerms validates the query and returns its terms.
+func searchTerms(query string) ([]string, error) {
+	if len(query) > maxSearchLength {
+		return nil, fmt.Errorf("query is longer than %d bytes: %w", maxSearchLength, ErrInvalidQuery)
+	}
+
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, ErrInvalidQuery
+	}
+
+	return terms, nil
+}
+
+// MatchesTerm reports whether the word matches the search term, which is
+// when the word starts with it, so shirt matches shirts.
+func MatchesTerm(word string, term string) bool {
+	return strings.HasPrefix(strings.ToLower(word), term)
+}
+
+// highlight escapes the text for HTML and wraps each word that matches one
+// of the terms in a mark element. The database stores rank with their own
+// stemming, so a result can match without a word being marked.
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	for len(text) > 0 {
+		i := strings.IndexFunc(text, isWord)
+		if i < 0 {
+			b.WriteString(html.EscapeString(text))
+			break
+		}
+		b.WriteString(html.EscapeString(text[:i]))
+		text = text[i:]
+
+		j := strings.IndexFunc(text, func(r rune) bool { return !isWord(r) })
+		if j < 0 {
+			j = len(text)
+		}
+		word := text[:j]
+		text = text[j:]
+
+		matched := slices.ContainsFunc(terms, func(term string) bool {
+			return MatchesTerm(word, term)
+		})
+
+		if matched {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+			continue
+		}
+		b.WriteString(html.EscapeString(word))
+	}
+
+	return b.String()
+}
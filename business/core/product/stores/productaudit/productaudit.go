@@ -0,0 +1,164 @@
+// Package productaudit contains a product store that records the changes
+// written through it in the entity audit trail.
+package productaudit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+// entity is the name the changes to products are recorded under.
+const entity = "product"
+
+// Store wraps a product store and records every successful change.
+type Store struct {
+	storer    product.Storer
+	auditCore *entityaudit.Core
+}
+
+// NewStore constructs the api for audited access.
+func NewStore(storer product.Storer, auditCore *entityaudit.Core) *Store {
+	return &Store{
+		storer:    storer,
+		auditCore: auditCore,
+	}
+}
+
+var _ product.Storer = (*Store)(nil)
+
+// ExecuteUnderTransaction constructs a new Store value with the wrapped
+// store and the audit trail under the transaction, so a change and its
+// record commit or roll back together.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (product.Storer, error) {
+	storer, err := s.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	auditCore, err := s.auditCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:    storer,
+		auditCore: auditCore,
+	}
+
+	return &store, nil
+}
+
+// Create inserts the product and records it.
+func (s *Store) Create(ctx context.Context, prd product.Product) error {
+	if err := s.storer.Create(ctx, prd); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, prd.ID.String(), entityaudit.ActionCreate, nil, prd)
+
+	return nil
+}
+
+// Update replaces the product and records what it was replaced from.
+func (s *Store) Update(ctx context.Context, prd product.Product) error {
+	before := s.current(ctx, prd.ID)
+
+	if err := s.storer.Update(ctx, prd); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, prd.ID.String(), entityaudit.ActionUpdate, before, prd)
+
+	return nil
+}
+
+// Upsert creates or replaces the product and records it as a create or
+// as an update of what it was before.
+func (s *Store) Upsert(ctx context.Context, prd product.Product) error {
+	before := s.current(ctx, prd.ID)
+
+	if err := s.storer.Upsert(ctx, prd); err != nil {
+		return err
+	}
+
+	action := entityaudit.ActionUpdate
+	if before == nil {
+		action = entityaudit.ActionCreate
+	}
+
+	s.auditCore.Record(ctx, entity, prd.ID.String(), action, before, prd)
+
+	return nil
+}
+
+// Delete deletes the product and records what it was before.
+func (s *Store) Delete(ctx context.Context, prd product.Product) error {
+	before := s.current(ctx, prd.ID)
+
+	if err := s.storer.Delete(ctx, prd); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, prd.ID.String(), entityaudit.ActionDelete, before, prd)
+
+	return nil
+}
+
+// Restore restores the product and records what it was restored to.
+func (s *Store) Restore(ctx context.Context, productID uuid.UUID, dateUpdated time.Time) error {
+	if err := s.storer.Restore(ctx, productID, dateUpdated); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, productID.String(), entityaudit.ActionRestore, nil, s.current(ctx, productID))
+
+	return nil
+}
+
+// Query implements the product.Storer interface.
+func (s *Store) Query(ctx context.Context, filter product.QueryFilter, orderBy order.By, pg page.Page) ([]product.Product, error) {
+	return s.storer.Query(ctx, filter, orderBy, pg)
+}
+
+// Count implements the product.Storer interface.
+func (s *Store) Count(ctx context.Context, filter product.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID implements the product.Storer interface.
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (product.Product, error) {
+	return s.storer.QueryByID(ctx, productID)
+}
+
+// QueryByUserID implements the product.Storer interface.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]product.Product, error) {
+	return s.storer.QueryByUserID(ctx, userID)
+}
+
+// Search implements the product.Storer interface.
+func (s *Store) Search(ctx context.Context, query string, pg page.Page) ([]product.SearchResult, error) {
+	return s.storer.Search(ctx, query, pg)
+}
+
+// SearchCount implements the product.Storer interface.
+func (s *Store) SearchCount(ctx context.Context, query string) (int, error) {
+	return s.storer.SearchCount(ctx, query)
+}
+
+// current reads the stored product for a snapshot, nil when it can't be
+// read.
+func (s *Store) current(ctx context.Context, productID uuid.UUID) any {
+	prd, err := s.storer.QueryByID(ctx, productID)
+	if err != nil {
+		return nil
+	}
+
+	return prd
+}
@@ -0,0 +1,59 @@
+package productdb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+)
+
+// applyFilter returns the WHERE clause for the filter, limited to the tenant
+// the context is scoped to, and adds the values of its parameters to the
+// data. The clause is empty when nothing is filtered on.
+func applyFilter(ctx context.Context, filter product.QueryFilter, data map[string]any) string {
+	var wc []string
+
+	if filter.ID != nil {
+		data["product_id"] = *filter.ID
+		wc = append(wc, "product_id = :product_id")
+	}
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Name != nil {
+		data["name"] = sqldb.ContainsPattern(strings.ToLower(*filter.Name))
+		wc = append(wc, "LOWER(name) LIKE :name")
+	}
+
+	if filter.MinCost != nil {
+		data["min_cost"] = *filter.MinCost
+		wc = append(wc, "cost >= :min_cost")
+	}
+
+	if filter.MaxCost != nil {
+		data["max_cost"] = *filter.MaxCost
+		wc = append(wc, "cost <= :max_cost")
+	}
+
+	if filter.AfterID != nil {
+		data["after_id"] = *filter.AfterID
+		wc = append(wc, "product_id > :after_id")
+	}
+
+	wc = sqldb.ExcludeDeleted(wc, filter.WithDeleted)
+	wc = scopeTenant(ctx, wc, data)
+
+	return sqldb.Where(wc)
+}
+
+// scopeTenant limits the conditions to the products of the tenant the
+// context is scoped to.
+func scopeTenant(ctx context.Context, wc []string, data map[string]any) []string {
+	tenantID, _ := tenant.IDFromContext(ctx)
+	return sqldb.ScopeTenant(wc, data, tenantID)
+}
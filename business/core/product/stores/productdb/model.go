@@ -0,0 +1,72 @@
+package productdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+type dbProduct struct {
+	ID          uuid.UUID     `db:"product_id"`
+	TenantID    uuid.NullUUID `db:"tenant_id"`
+	UserID      uuid.UUID     `db:"user_id"`
+	Name        string        `db:"name"`
+	Cost        float64       `db:"cost"`
+	Quantity    int           `db:"quantity"`
+	Version     int           `db:"version"`
+	DateCreated time.Time     `db:"date_created"`
+	DateUpdated time.Time     `db:"date_updated"`
+	DateDeleted sql.NullTime  `db:"deleted_at"`
+}
+
+func toDBProduct(prd product.Product) dbProduct {
+	return dbProduct{
+		ID: prd.ID,
+		TenantID: uuid.NullUUID{
+			UUID:  prd.TenantID,
+			Valid: prd.TenantID != uuid.Nil,
+		},
+		UserID:      prd.UserID,
+		Name:        prd.Name,
+		Cost:        prd.Cost,
+		Quantity:    prd.Quantity,
+		Version:     prd.Version,
+		DateCreated: prd.DateCreated.UTC(),
+		DateUpdated: prd.DateUpdated.UTC(),
+		DateDeleted: sql.NullTime{
+			Time:  prd.DateDeleted.UTC(),
+			Valid: !prd.DateDeleted.IsZero(),
+		},
+	}
+}
+
+func toCoreProduct(dbPrd dbProduct) product.Product {
+	prd := product.Product{
+		ID:          dbPrd.ID,
+		TenantID:    dbPrd.TenantID.UUID,
+		UserID:      dbPrd.UserID,
+		Name:        dbPrd.Name,
+		Cost:        dbPrd.Cost,
+		Quantity:    dbPrd.Quantity,
+		Version:     dbPrd.Version,
+		DateCreated: dbPrd.DateCreated.In(time.Local),
+		DateUpdated: dbPrd.DateUpdated.In(time.Local),
+	}
+
+	if dbPrd.DateDeleted.Valid {
+		prd.DateDeleted = dbPrd.DateDeleted.Time.In(time.Local)
+	}
+
+	return prd
+}
+
+func toCoreProductSlice(dbProducts []dbProduct) []product.Product {
+	prds := make([]product.Product, len(dbProducts))
+	for i, dbPrd := range dbProducts {
+		prds[i] = toCoreProduct(dbPrd)
+	}
+
+	return prds
+}
@@ -0,0 +1,36 @@
+package productdb
+
+import (
+	"fmt"
+
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+var orderByFields = map[string]string{
+	product.OrderByID:       "product_id",
+	product.OrderByUserID:   "user_id",
+	product.OrderByName:     "name",
+	product.OrderByCost:     "cost",
+	product.OrderByQuantity: "quantity",
+}
+
+// orderByClause translates the order to SQL, breaking ties by id so pages
+// don't overlap.
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	if orderBy.Direction != order.ASC && orderBy.Direction != order.DESC {
+		return "", fmt.Errorf("direction %q does not exist", orderBy.Direction)
+	}
+
+	clause := by + " " + orderBy.Direction
+	if by != "product_id" {
+		clause += ", product_id"
+	}
+
+	return clause, nil
+}
@@ -0,0 +1,283 @@
+// Package productdb contains product related CRUD functionality.
+package productdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/product"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for product database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (product.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new product into the database.
+func (s *Store) Create(ctx context.Context, prd product.Product) error {
+	const q = `
+	INSERT INTO products
+		(product_id, tenant_id, user_id, name, cost, quantity, version, date_created, date_updated)
+	VALUES
+		(:product_id, :tenant_id, :user_id, :name, :cost, :quantity, :version, :date_created, :date_updated)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a product document in the database if it is still at
+// the version before the one of the product.
+func (s *Store) Update(ctx context.Context, prd product.Product) error {
+	const q = `
+	UPDATE
+		products
+	SET
+		"name" = :name,
+		"cost" = :cost,
+		"quantity" = :quantity,
+		"version" = :version,
+		"date_updated" = :date_updated
+	WHERE
+		product_id = :product_id AND
+		"version" = :version - 1`
+
+	if err := sqldb.NamedExecVersioned(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("namedexecversioned: %w: %w", product.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("namedexecversioned: %w", err)
+	}
+
+	return nil
+}
+
+// Upsert inserts the product or, when one with the same id exists, replaces
+// it if it is still at the version before the one of the product and isn't
+// deleted. A product at version 1 never replaces a stored one.
+func (s *Store) Upsert(ctx context.Context, prd product.Product) error {
+	d := sqldb.DialectOf(s.db)
+
+	// Postgres skips the update with a WHERE. MySQL has none, so every
+	// column keeps its value unless the condition holds, with the version
+	// set last since the condition reads it.
+	cond := `products."version" = ` + d.Excluded(`"version"`) + ` - 1 AND products.deleted_at IS NULL`
+
+	set := func(column string) string {
+		if d == sqldb.MySQL {
+			return fmt.Sprintf("%s = IF(%s, %s, products.%s)", column, cond, d.Excluded(column), column)
+		}
+		return column + " = " + d.Excluded(column)
+	}
+
+	q := `
+	INSERT INTO products
+		(product_id, tenant_id, user_id, name, cost, quantity, version, date_created, date_updated)
+	VALUES
+		(:product_id, :tenant_id, :user_id, :name, :cost, :quantity, :version, :date_created, :date_updated)
+	` + d.Upsert([]string{"product_id"},
+		set(`"name"`),
+		set(`"cost"`),
+		set(`"quantity"`),
+		set(`"date_updated"`),
+		set(`"version"`),
+	)
+
+	if d == sqldb.Postgres {
+		q += `
+	WHERE
+		` + cond
+	}
+
+	if err := sqldb.NamedExecVersioned(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("namedexecversioned: %w: %w", product.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("namedexecversioned: %w", err)
+	}
+
+	return nil
+}
+
+// Delete marks the product as deleted if it is still at the version before
+// the one of the product.
+func (s *Store) Delete(ctx context.Context, prd product.Product) error {
+	const q = `
+	UPDATE
+		products
+	SET
+		"version" = :version,
+		"date_updated" = :date_updated,
+		"deleted_at" = :deleted_at
+	WHERE
+		product_id = :product_id AND
+		"version" = :version - 1`
+
+	if err := sqldb.NamedExecVersioned(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("namedexecversioned: %w: %w", product.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("namedexecversioned: %w", err)
+	}
+
+	return nil
+}
+
+// Restore clears the deletion of the product identified by a given ID.
+func (s *Store) Restore(ctx context.Context, productID uuid.UUID, dateUpdated time.Time) error {
+	data := map[string]any{
+		"product_id":   productID.String(),
+		"date_updated": dateUpdated.UTC(),
+	}
+
+	wc := scopeTenant(ctx, []string{"product_id = :product_id", "deleted_at IS NOT NULL"}, data)
+
+	q := `
+	UPDATE
+		products
+	SET
+		"version" = "version" + 1,
+		"date_updated" = :date_updated,
+		"deleted_at" = NULL` + sqldb.Where(wc)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a page of products from the database.
+func (s *Store) Query(ctx context.Context, filter product.QueryFilter, orderBy order.By, pg page.Page) ([]product.Product, error) {
+	data := map[string]any{
+		"offset":        pg.Offset(),
+		"rows_per_page": pg.RowsPerPage(),
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+	SELECT
+		product_id, tenant_id, user_id, name, cost, quantity, version, date_created, date_updated, deleted_at
+	FROM
+		products` + applyFilter(ctx, filter, data) + `
+	ORDER BY
+		` + orderByClause + `
+	LIMIT :rows_per_page OFFSET :offset`
+
+	var dbPrds []dbProduct
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreProductSlice(dbPrds), nil
+}
+
+// Count returns the total number of products in the database matching the
+// filter.
+func (s *Store) Count(ctx context.Context, filter product.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	q := `
+	SELECT
+		count(1) AS "count"
+	FROM
+		products` + applyFilter(ctx, filter, data)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID finds the product identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (product.Product, error) {
+	data := map[string]any{
+		"product_id": productID.String(),
+	}
+
+	wc := scopeTenant(ctx, []string{"product_id = :product_id", sqldb.NotDeleted}, data)
+
+	q := `
+	SELECT
+		product_id, tenant_id, user_id, name, cost, quantity, version, date_created, date_updated, deleted_at
+	FROM
+		products` + sqldb.Where(wc)
+
+	var dbPrd dbProduct
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return product.Product{}, fmt.Errorf("namedquerystruct: %w", product.ErrNotFound)
+		}
+		return product.Product{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreProduct(dbPrd), nil
+}
+
+// QueryByUserID finds the products owned by the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]product.Product, error) {
+	data := map[string]any{
+		"user_id": userID.String(),
+	}
+
+	wc := scopeTenant(ctx, []string{"user_id = :user_id", sqldb.NotDeleted}, data)
+
+	q := `
+	SELECT
+		product_id, tenant_id, user_id, name, cost, quantity, version, date_created, date_updated, deleted_at
+	FROM
+		products` + sqldb.Where(wc) + `
+	ORDER BY
+		product_id`
+
+	var dbPrds []dbProduct
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreProductSlice(dbPrds), nil
+}
@@ -0,0 +1,99 @@
+package productdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+// dbSearchResult is a product with the rank the database gave its match.
+type dbSearchResult struct {
+	dbProduct
+	Score float64 `db:"score"`
+}
+
+// Search retrieves a page of the products whose name matches the query
+// with full text search, ranked by the database. Postgres matches the terms
+// of a web search style query against the stemmed words of the name and
+// MySQL runs the query in natural language mode.
+func (s *Store) Search(ctx context.Context, query string, pg page.Page) ([]product.SearchResult, error) {
+	data := map[string]any{
+		"query":         query,
+		"offset":        pg.Offset(),
+		"rows_per_page": pg.RowsPerPage(),
+	}
+
+	wc := scopeTenant(ctx, []string{matchExpr(sqldb.DialectOf(s.db)), sqldb.NotDeleted}, data)
+
+	q := `
+	SELECT
+		product_id, tenant_id, user_id, name, cost, quantity, version, date_created, date_updated, deleted_at,
+		` + scoreExpr(sqldb.DialectOf(s.db)) + ` AS score
+	FROM
+		products` + sqldb.Where(wc) + `
+	ORDER BY
+		score DESC, product_id
+	LIMIT :rows_per_page OFFSET :offset`
+
+	var dbResults []dbSearchResult
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbResults); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	results := make([]product.SearchResult, len(dbResults))
+	for i, dbRes := range dbResults {
+		results[i] = product.SearchResult{
+			Product: toCoreProduct(dbRes.dbProduct),
+			Rank:    dbRes.Score,
+		}
+	}
+
+	return results, nil
+}
+
+// SearchCount returns the total number of products whose name matches the
+// query.
+func (s *Store) SearchCount(ctx context.Context, query string) (int, error) {
+	data := map[string]any{
+		"query": query,
+	}
+
+	wc := scopeTenant(ctx, []string{matchExpr(sqldb.DialectOf(s.db)), sqldb.NotDeleted}, data)
+
+	q := `
+	SELECT
+		count(1) AS "count"
+	FROM
+		products` + sqldb.Where(wc)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// matchExpr returns the condition a product matches the query with. They
+// use the indexes added for the search.
+func matchExpr(d sqldb.Dialect) string {
+	if d == sqldb.MySQL {
+		return "MATCH (name) AGAINST (:query IN NATURAL LANGUAGE MODE)"
+	}
+
+	return "search @@ websearch_to_tsquery('english', :query)"
+}
+
+// scoreExpr returns the rank of how well a product matches the query.
+func scoreExpr(d sqldb.Dialect) string {
+	if d == sqldb.MySQL {
+		return "MATCH (name) AGAINST (:query IN NATURAL LANGUAGE MODE)"
+	}
+
+	return "ts_rank(search, websearch_to_tsquery('english', :query))"
+}
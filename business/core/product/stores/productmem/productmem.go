@@ -0,0 +1,264 @@
+// Package productmem contains an in-memory implementation of the product
+// store for unit tests.
+package productmem
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/product"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain product
+
+// Update replaces the product in the store if it is still at the version
+// before the one of the product.
+func (s *Store) Update(ctx context.Context, prd product.Product) error {
+	err := s.table.UpdateIf(prd, func(existing product.Product) bool {
+		return existing.Version == prd.Version-1
+	})
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("update: %w: %w", product.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Upsert inserts the product or, when one with the same id exists, replaces
+// it if it is still at the version before the one of the product and isn't
+// deleted. A product at version 1 never replaces a stored one.
+func (s *Store) Upsert(ctx context.Context, prd product.Product) error {
+	if prd.Version == 1 {
+		if err := s.table.Insert(prd); err != nil {
+			if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+				return fmt.Errorf("upsert: %w: %w", product.ErrVersionConflict, sqldb.ErrDBVersionConflict)
+			}
+			return fmt.Errorf("upsert: %w", err)
+		}
+
+		return nil
+	}
+
+	err := s.table.UpdateIf(prd, func(existing product.Product) bool {
+		return existing.Version == prd.Version-1 && existing.DateDeleted.IsZero()
+	})
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("upsert: %w: %w", product.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	return nil
+}
+
+// Delete marks the product as deleted if it is still at the version before
+// the one of the product.
+func (s *Store) Delete(ctx context.Context, prd product.Product) error {
+	return s.Update(ctx, prd)
+}
+
+// Restore clears the deletion of the specified product.
+func (s *Store) Restore(ctx context.Context, productID uuid.UUID, dateUpdated time.Time) error {
+	match := func(prd product.Product) bool {
+		return prd.ID == productID && !prd.DateDeleted.IsZero() && memstore.InTenant(ctx, prd.TenantID)
+	}
+
+	s.table.UpdateFunc(match, func(prd product.Product) product.Product {
+		prd.Version++
+		prd.DateUpdated = dateUpdated
+		prd.DateDeleted = time.Time{}
+		return prd
+	})
+
+	return nil
+}
+
+// QueryByID gets the specified product from the store, unless it was
+// deleted or belongs to another tenant.
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (product.Product, error) {
+	prd, err := s.table.Get(productID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return product.Product{}, fmt.Errorf("query: %w", product.ErrNotFound)
+		}
+		return product.Product{}, fmt.Errorf("query: %w", err)
+	}
+
+	if !prd.DateDeleted.IsZero() || !memstore.InTenant(ctx, prd.TenantID) {
+		return product.Product{}, fmt.Errorf("query: %w", product.ErrNotFound)
+	}
+
+	return prd, nil
+}
+
+// Query retrieves a page of the products matching the filter, ordered like
+// the database store.
+func (s *Store) Query(ctx context.Context, filter product.QueryFilter, orderBy order.By, pg page.Page) ([]product.Product, error) {
+	less, err := lessBy(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	prds := s.table.Query(memstore.Query[product.Product]{
+		Match:  match(ctx, filter),
+		Less:   less,
+		Offset: pg.Offset(),
+		Limit:  pg.RowsPerPage(),
+	})
+
+	return prds, nil
+}
+
+// Count returns the total number of products matching the filter.
+func (s *Store) Count(ctx context.Context, filter product.QueryFilter) (int, error) {
+	return s.table.Count(match(ctx, filter)), nil
+}
+
+// QueryByUserID finds the products owned by the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]product.Product, error) {
+	prds := s.table.Query(memstore.Query[product.Product]{
+		Match: func(prd product.Product) bool {
+			return prd.UserID == userID && prd.DateDeleted.IsZero() && memstore.InTenant(ctx, prd.TenantID)
+		},
+		Less: byID,
+	})
+
+	return prds, nil
+}
+
+func byID(a product.Product, b product.Product) bool {
+	return a.ID.String() < b.ID.String()
+}
+
+func match(ctx context.Context, filter product.QueryFilter) func(product.Product) bool {
+	return func(prd product.Product) bool {
+		switch {
+		case !memstore.InTenant(ctx, prd.TenantID):
+			return false
+		case !filter.WithDeleted && !prd.DateDeleted.IsZero():
+			return false
+		case filter.ID != nil && prd.ID != *filter.ID:
+			return false
+		case filter.UserID != nil && prd.UserID != *filter.UserID:
+			return false
+		case filter.Name != nil && !strings.Contains(strings.ToLower(prd.Name), strings.ToLower(*filter.Name)):
+			return false
+		case filter.MinCost != nil && prd.Cost < *filter.MinCost:
+			return false
+		case filter.MaxCost != nil && prd.Cost > *filter.MaxCost:
+			return false
+		case filter.AfterID != nil && prd.ID.String() <= filter.AfterID.String():
+			return false
+		}
+
+		return true
+	}
+}
+
+// lessBy orders like the database store, where rows that tie are ordered
+// by id ascending whatever the direction.
+func lessBy(orderBy order.By) (func(a product.Product, b product.Product) bool, error) {
+	var compare func(a product.Product, b product.Product) int
+
+	switch orderBy.Field {
+	case product.OrderByID:
+		compare = func(a product.Product, b product.Product) int { return strings.Compare(a.ID.String(), b.ID.String()) }
+	case product.OrderByUserID:
+		compare = func(a product.Product, b product.Product) int {
+			return strings.Compare(a.UserID.String(), b.UserID.String())
+		}
+	case product.OrderByName:
+		compare = func(a product.Product, b product.Product) int { return strings.Compare(a.Name, b.Name) }
+	case product.OrderByCost:
+		compare = func(a product.Product, b product.Product) int { return cmp.Compare(a.Cost, b.Cost) }
+	case product.OrderByQuantity:
+		compare = func(a product.Product, b product.Product) int { return cmp.Compare(a.Quantity, b.Quantity) }
+	default:
+		return nil, fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	less := func(a product.Product, b product.Product) bool {
+		c := compare(a, b)
+		if orderBy.Direction == order.DESC {
+			c = -c
+		}
+
+		if c == 0 {
+			return byID(a, b)
+		}
+		return c < 0
+	}
+
+	return less, nil
+}
+
+// Search retrieves a page of the products whose name has a word matching a
+// term of the query, ranked by the number of terms matched.
+func (s *Store) Search(ctx context.Context, query string, pg page.Page) ([]product.SearchResult, error) {
+	terms := product.SearchTerms(query)
+
+	prds := s.table.Query(memstore.Query[product.Product]{
+		Match: func(prd product.Product) bool {
+			return prd.DateDeleted.IsZero() && memstore.InTenant(ctx, prd.TenantID) && rank(prd, terms) > 0
+		},
+		Less: func(a product.Product, b product.Product) bool {
+			ra, rb := rank(a, terms), rank(b, terms)
+			if ra != rb {
+				return ra > rb
+			}
+			return byID(a, b)
+		},
+		Offset: pg.Offset(),
+		Limit:  pg.RowsPerPage(),
+	})
+
+	results := make([]product.SearchResult, len(prds))
+	for i, prd := range prds {
+		results[i] = product.SearchResult{
+			Product: prd,
+			Rank:    rank(prd, terms),
+		}
+	}
+
+	return results, nil
+}
+
+// SearchCount returns the total number of products matching the query.
+func (s *Store) SearchCount(ctx context.Context, query string) (int, error) {
+	terms := product.SearchTerms(query)
+
+	n := s.table.Count(func(prd product.Product) bool {
+		return prd.DateDeleted.IsZero() && memstore.InTenant(ctx, prd.TenantID) && rank(prd, terms) > 0
+	})
+
+	return n, nil
+}
+
+// rank returns the number of terms a word of the name of the product
+// matches.
+func rank(prd product.Product, terms []string) float64 {
+	words := product.SearchTerms(prd.Name)
+
+	var n float64
+	for _, term := range terms {
+		if slices.ContainsFunc(words, func(w string) bool { return product.MatchesTerm(w, term) }) {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,19 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Instance represents a running instance of a service.
+type Instance struct {
+	ID          uuid.UUID
+	Service     string
+	Build       string
+	Host        string
+	Address     string
+	Healthy     bool
+	DateStarted time.Time
+	LastSeen    time.Time
+}
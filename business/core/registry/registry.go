@@ -0,0 +1,92 @@
+// Package registry provides support for service instances to report
+// themselves in a shared table, so the versions running across an
+// environment can be seen in one place.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Upsert(ctx context.Context, inst Instance) error
+	Delete(ctx context.Context, inst Instance) error
+	QuerySeenSince(ctx context.Context, since time.Time) ([]Instance, error)
+}
+
+// HealthFn reports whether the instance is able to serve traffic.
+type HealthFn func(ctx context.Context) bool
+
+// Core manages the set of APIs for registry access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewCore constructs a core for registry api access.
+func NewCore(log *logger.Logger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Report records the instance as seen now.
+func (c *Core) Report(ctx context.Context, inst Instance) error {
+	inst.LastSeen = time.Now()
+
+	if err := c.storer.Upsert(ctx, inst); err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	return nil
+}
+
+// Run reports the instance and its health at the specified interval until
+// the context is canceled, then removes the instance so it stops being
+// listed right away.
+func (c *Core) Run(ctx context.Context, inst Instance, interval time.Duration, health HealthFn) {
+	report := func() {
+		inst.Healthy = health(ctx)
+		if err := c.Report(ctx, inst); err != nil {
+			c.log.Error(ctx, "registry", "status", "report failed", "msg", err)
+		}
+	}
+
+	report()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+
+			if err := c.storer.Delete(ctx, inst); err != nil {
+				c.log.Error(ctx, "registry", "status", "remove failed", "msg", err)
+			}
+			return
+
+		case <-ticker.C:
+			report()
+		}
+	}
+}
+
+// QueryLive returns the instances that reported within the staleAfter
+// duration.
+func (c *Core) QueryLive(ctx context.Context, staleAfter time.Duration) ([]Instance, error) {
+	insts, err := c.storer.QuerySeenSince(ctx, time.Now().Add(-staleAfter))
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return insts, nil
+}
@@ -0,0 +1,45 @@
+package registrydb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/registry"
+)
+
+type dbInstance struct {
+	ID          uuid.UUID `db:"instance_id"`
+	Service     string    `db:"service"`
+	Build       string    `db:"build"`
+	Host        string    `db:"host"`
+	Address     string    `db:"address"`
+	Healthy     bool      `db:"healthy"`
+	DateStarted time.Time `db:"date_started"`
+	LastSeen    time.Time `db:"last_seen"`
+}
+
+func toDBInstance(inst registry.Instance) dbInstance {
+	return dbInstance{
+		ID:          inst.ID,
+		Service:     inst.Service,
+		Build:       inst.Build,
+		Host:        inst.Host,
+		Address:     inst.Address,
+		Healthy:     inst.Healthy,
+		DateStarted: inst.DateStarted.UTC(),
+		LastSeen:    inst.LastSeen.UTC(),
+	}
+}
+
+func toCoreInstance(dbInst dbInstance) registry.Instance {
+	return registry.Instance{
+		ID:          dbInst.ID,
+		Service:     dbInst.Service,
+		Build:       dbInst.Build,
+		Host:        dbInst.Host,
+		Address:     dbInst.Address,
+		Healthy:     dbInst.Healthy,
+		DateStarted: dbInst.DateStarted.In(time.Local),
+		LastSeen:    dbInst.LastSeen.In(time.Local),
+	}
+}
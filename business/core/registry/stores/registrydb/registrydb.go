@@ -0,0 +1,89 @@
+// Package registrydb contains registry related CRUD functionality.
+package registrydb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/registry"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for registry database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Upsert adds the instance or refreshes its health and last seen time.
+func (s *Store) Upsert(ctx context.Context, inst registry.Instance) error {
+	d := sqldb.DialectOf(s.db)
+
+	q := `
+	INSERT INTO service_instances
+		(instance_id, service, build, host, address, healthy, date_started, last_seen)
+	VALUES
+		(:instance_id, :service, :build, :host, :address, :healthy, :date_started, :last_seen)
+	` + d.Upsert([]string{"instance_id"},
+		"healthy = "+d.Excluded("healthy"),
+		"last_seen = "+d.Excluded("last_seen"),
+	)
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBInstance(inst)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the instance from the database.
+func (s *Store) Delete(ctx context.Context, inst registry.Instance) error {
+	const q = `
+	DELETE FROM
+		service_instances
+	WHERE
+		instance_id = ?`
+
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind(q), inst.ID); err != nil {
+		return fmt.Errorf("execcontext: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySeenSince retrieves the instances that reported since the specified
+// time, ordered by service and start time.
+func (s *Store) QuerySeenSince(ctx context.Context, since time.Time) ([]registry.Instance, error) {
+	const q = `
+	SELECT
+		instance_id, service, build, host, address, healthy, date_started, last_seen
+	FROM
+		service_instances
+	WHERE
+		last_seen >= ?
+	ORDER BY
+		service, date_started`
+
+	var dbInsts []dbInstance
+	if err := s.db.SelectContext(ctx, &dbInsts, s.db.Rebind(q), since.UTC()); err != nil {
+		return nil, fmt.Errorf("selectcontext: %w", err)
+	}
+
+	insts := make([]registry.Instance, len(dbInsts))
+	for i, dbInst := range dbInsts {
+		insts[i] = toCoreInstance(dbInst)
+	}
+
+	return insts, nil
+}
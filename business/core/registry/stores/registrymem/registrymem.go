@@ -0,0 +1,48 @@
+// Package registrymem contains an in-memory implementation of the registry
+// store for unit tests.
+package registrymem
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/core/registry"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain registry
+
+// Upsert inserts the instance or, when it already exists, only refreshes
+// its health and when it was last seen.
+func (s *Store) Upsert(ctx context.Context, inst registry.Instance) error {
+	merge := func(existing registry.Instance) registry.Instance {
+		existing.Healthy = inst.Healthy
+		existing.LastSeen = inst.LastSeen
+		return existing
+	}
+
+	if err := s.table.UpsertFunc(inst, merge); err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySeenSince retrieves the instances that reported since the specified
+// time, ordered by service and start time.
+func (s *Store) QuerySeenSince(ctx context.Context, since time.Time) ([]registry.Instance, error) {
+	insts := s.table.Query(memstore.Query[registry.Instance]{
+		Match: func(inst registry.Instance) bool {
+			return !inst.LastSeen.Before(since)
+		},
+		Less: func(a registry.Instance, b registry.Instance) bool {
+			if a.Service != b.Service {
+				return a.Service < b.Service
+			}
+			return a.DateStarted.Before(b.DateStarted)
+		},
+	})
+
+	return insts, nil
+}
@@ -0,0 +1,65 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ctxKey int
+
+const tenantKey ctxKey = 1
+
+// denied is the tenant a denied context is scoped to. It is the max uuid,
+// which is never the id of a tenant, so the context matches no data.
+var denied = uuid.Max
+
+// WithID returns a copy of the context that scopes data access to the
+// specified tenant.
+func WithID(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// Deny returns a copy of the context that can't access the data of any
+// tenant, for a caller that must belong to a tenant but doesn't. The stores
+// find nothing for it and data can't be created with it.
+func Deny(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantKey, denied)
+}
+
+// IDFromContext returns the tenant data access is scoped to. The boolean is
+// false when no tenant has been resolved for the context.
+func IDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	v, ok := ctx.Value(tenantKey).(uuid.UUID)
+	if !ok || v == uuid.Nil {
+		return uuid.Nil, false
+	}
+
+	return v, true
+}
+
+// CheckAccess reports whether data that belongs to the specified tenant,
+// uuid.Nil for none, can be accessed from the context. A context scoped to
+// a tenant can only access that tenant's data, one that is denied none, and
+// one that isn't scoped can access any. ErrCrossTenant is returned when
+// access is denied.
+func CheckAccess(ctx context.Context, tenantID uuid.UUID) error {
+	scoped, ok := IDFromContext(ctx)
+	if !ok || scoped == tenantID {
+		return nil
+	}
+
+	return ErrCrossTenant
+}
+
+// OwnerFromContext returns the tenant the data created from the context
+// belongs to, uuid.Nil for none. ErrNoTenant is returned for a context that
+// has been denied access.
+func OwnerFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, _ := IDFromContext(ctx)
+	if tenantID == denied {
+		return uuid.Nil, ErrNoTenant
+	}
+
+	return tenantID, nil
+}
@@ -0,0 +1,37 @@
+package tenant
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant represents an isolated customer of the system.
+type Tenant struct {
+	ID          uuid.UUID
+	Slug        string
+	Name        string
+	Enabled     bool
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewTenant contains information needed to create a new tenant.
+type NewTenant struct {
+	Slug string
+	Name string
+}
+
+// UpdateTenant contains information needed to update a tenant. Fields that
+// are nil are left unchanged.
+type UpdateTenant struct {
+	Name    *string
+	Enabled *bool
+}
+
+// Expect contains the state a tenant must be in for an update to apply.
+// Fields that are nil aren't checked.
+type Expect struct {
+	Enabled       *bool
+	UpdatedBefore *time.Time
+}
@@ -0,0 +1,39 @@
+package tenantdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+)
+
+type dbTenant struct {
+	ID          uuid.UUID `db:"tenant_id"`
+	Slug        string    `db:"slug"`
+	Name        string    `db:"name"`
+	Enabled     bool      `db:"enabled"`
+	DateCreated time.Time `db:"date_created"`
+	DateUpdated time.Time `db:"date_updated"`
+}
+
+func toDBTenant(tnt tenant.Tenant) dbTenant {
+	return dbTenant{
+		ID:          tnt.ID,
+		Slug:        tnt.Slug,
+		Name:        tnt.Name,
+		Enabled:     tnt.Enabled,
+		DateCreated: tnt.DateCreated.UTC(),
+		DateUpdated: tnt.DateUpdated.UTC(),
+	}
+}
+
+func toCoreTenant(dbTnt dbTenant) tenant.Tenant {
+	return tenant.Tenant{
+		ID:          dbTnt.ID,
+		Slug:        dbTnt.Slug,
+		Name:        dbTnt.Name,
+		Enabled:     dbTnt.Enabled,
+		DateCreated: dbTnt.DateCreated.In(time.Local),
+		DateUpdated: dbTnt.DateUpdated.In(time.Local),
+	}
+}
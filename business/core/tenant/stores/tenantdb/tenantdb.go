@@ -0,0 +1,181 @@
+// Package tenantdb contains tenant related CRUD functionality.
+package tenantdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for tenant database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new tenant into the database.
+func (s *Store) Create(ctx context.Context, tnt tenant.Tenant) error {
+	const q = `
+	INSERT INTO tenants
+		(tenant_id, slug, name, enabled, date_created, date_updated)
+	VALUES
+		(:tenant_id, :slug, :name, :enabled, :date_created, :date_updated)`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBTenant(tnt)); err != nil {
+		if errors.Is(sqldb.TranslateError(err), sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", tenant.ErrUniqueSlug)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a tenant document in the database.
+func (s *Store) Update(ctx context.Context, tnt tenant.Tenant) error {
+	const q = `
+	UPDATE
+		tenants
+	SET
+		"slug" = :slug,
+		"name" = :name,
+		"enabled" = :enabled,
+		"date_updated" = :date_updated
+	WHERE
+		tenant_id = :tenant_id`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBTenant(tnt)); err != nil {
+		if errors.Is(sqldb.TranslateError(err), sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", tenant.ErrUniqueSlug)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateIf replaces a tenant document in the database if it's still in the
+// expected state. ErrChanged is returned when the expectation didn't hold.
+func (s *Store) UpdateIf(ctx context.Context, tnt tenant.Tenant, exp tenant.Expect) error {
+	dbTnt := toDBTenant(tnt)
+
+	var b strings.Builder
+	b.WriteString(`
+	UPDATE
+		tenants
+	SET
+		"name" = ?,
+		"enabled" = ?,
+		"date_updated" = ?
+	WHERE
+		tenant_id = ?`)
+
+	args := []any{dbTnt.Name, dbTnt.Enabled, dbTnt.DateUpdated, dbTnt.ID}
+
+	if exp.Enabled != nil {
+		b.WriteString(` AND "enabled" = ?`)
+		args = append(args, *exp.Enabled)
+	}
+
+	if exp.UpdatedBefore != nil {
+		b.WriteString(` AND "date_updated" < ?`)
+		args = append(args, exp.UpdatedBefore.UTC())
+	}
+
+	res, err := s.db.ExecContext(ctx, s.db.Rebind(b.String()), args...)
+	if err != nil {
+		return fmt.Errorf("execcontext: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rowsaffected: %w", err)
+	}
+
+	if n == 0 {
+		return fmt.Errorf("execcontext: %w", tenant.ErrChanged)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified tenant from the database.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID) (tenant.Tenant, error) {
+	const q = `
+	SELECT
+		tenant_id, slug, name, enabled, date_created, date_updated
+	FROM
+		tenants
+	WHERE
+		tenant_id = ?`
+
+	return s.queryOne(ctx, q, tenantID)
+}
+
+// QueryByIDs gets the tenants with the specified IDs from the database.
+func (s *Store) QueryByIDs(ctx context.Context, tenantIDs []uuid.UUID) ([]tenant.Tenant, error) {
+	const q = `
+	SELECT
+		tenant_id, slug, name, enabled, date_created, date_updated
+	FROM
+		tenants
+	WHERE
+		tenant_id IN (?)`
+
+	query, args, err := sqlx.In(q, tenantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("in: %w", err)
+	}
+
+	var dbTnts []dbTenant
+	if err := s.db.SelectContext(ctx, &dbTnts, s.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("selectcontext: %w", err)
+	}
+
+	tnts := make([]tenant.Tenant, len(dbTnts))
+	for i, dbTnt := range dbTnts {
+		tnts[i] = toCoreTenant(dbTnt)
+	}
+
+	return tnts, nil
+}
+
+// QueryBySlug gets the tenant with the specified slug from the database.
+func (s *Store) QueryBySlug(ctx context.Context, slug string) (tenant.Tenant, error) {
+	const q = `
+	SELECT
+		tenant_id, slug, name, enabled, date_created, date_updated
+	FROM
+		tenants
+	WHERE
+		slug = ?`
+
+	return s.queryOne(ctx, q, slug)
+}
+
+func (s *Store) queryOne(ctx context.Context, q string, arg any) (tenant.Tenant, error) {
+	var dbTnt dbTenant
+	if err := s.db.GetContext(ctx, &dbTnt, s.db.Rebind(q), arg); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return tenant.Tenant{}, fmt.Errorf("getcontext: %w", tenant.ErrNotFound)
+		}
+		return tenant.Tenant{}, fmt.Errorf("getcontext: %w", err)
+	}
+
+	return toCoreTenant(dbTnt), nil
+}
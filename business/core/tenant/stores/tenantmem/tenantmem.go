@@ -0,0 +1,56 @@
+// Package tenantmem contains an in-memory implementation of the tenant store
+// for unit tests.
+package tenantmem
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain tenant -unique Slug=ErrUniqueSlug
+
+// UpdateIf replaces the tenant only when its current state matches the
+// expectation, otherwise it returns tenant.ErrChanged.
+func (s *Store) UpdateIf(ctx context.Context, tnt tenant.Tenant, exp tenant.Expect) error {
+	match := func(cur tenant.Tenant) bool {
+		switch {
+		case cur.ID != tnt.ID:
+			return false
+		case exp.Enabled != nil && cur.Enabled != *exp.Enabled:
+			return false
+		case exp.UpdatedBefore != nil && !cur.DateUpdated.Before(*exp.UpdatedBefore):
+			return false
+		}
+		return true
+	}
+
+	update := func(cur tenant.Tenant) tenant.Tenant {
+		cur.Name = tnt.Name
+		cur.Enabled = tnt.Enabled
+		cur.DateUpdated = tnt.DateUpdated
+		return cur
+	}
+
+	if n := s.table.UpdateFunc(match, update); n == 0 {
+		return fmt.Errorf("update: %w", tenant.ErrChanged)
+	}
+
+	return nil
+}
+
+// QueryByIDs gets the specified tenants from the store. Tenants that don't
+// exist are left out.
+func (s *Store) QueryByIDs(ctx context.Context, tenantIDs []uuid.UUID) ([]tenant.Tenant, error) {
+	tnts := s.table.Query(memstore.Query[tenant.Tenant]{
+		Match: func(tnt tenant.Tenant) bool {
+			return slices.Contains(tenantIDs, tnt.ID)
+		},
+	})
+
+	return tnts, nil
+}
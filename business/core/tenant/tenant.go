@@ -0,0 +1,247 @@
+// Package tenant provides business access to the tenants data is isolated
+// by.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/precondition"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound    = errors.New("tenant not found")
+	ErrDisabled    = errors.New("tenant disabled")
+	ErrUniqueSlug  = errors.New("slug is not unique")
+	ErrExists      = errors.New("tenant already exists")
+	ErrChanged     = errors.New("tenant changed")
+	ErrInvalidSlug = errors.New("slug must be lowercase letters, digits and dashes")
+	ErrCrossTenant = errors.New("data belongs to another tenant")
+	ErrNoTenant    = errors.New("caller belongs to no tenant")
+)
+
+var validSlug = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, tnt Tenant) error
+	Update(ctx context.Context, tnt Tenant) error
+	UpdateIf(ctx context.Context, tnt Tenant, exp Expect) error
+	QueryByID(ctx context.Context, tenantID uuid.UUID) (Tenant, error)
+	QueryByIDs(ctx context.Context, tenantIDs []uuid.UUID) ([]Tenant, error)
+	QueryBySlug(ctx context.Context, slug string) (Tenant, error)
+}
+
+// Core manages the set of APIs for tenant access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewCore constructs a core for tenant api access.
+func NewCore(log *logger.Logger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Create adds a new tenant to the system.
+func (c *Core) Create(ctx context.Context, nt NewTenant) (Tenant, error) {
+	if !validSlug.MatchString(nt.Slug) {
+		return Tenant{}, ErrInvalidSlug
+	}
+
+	now := time.Now()
+
+	tnt := Tenant{
+		ID:          uuid.New(),
+		Slug:        nt.Slug,
+		Name:        nt.Name,
+		Enabled:     true,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.storer.Create(ctx, tnt); err != nil {
+		return Tenant{}, fmt.Errorf("create: %w", err)
+	}
+
+	return tnt, nil
+}
+
+// Upsert creates the tenant with the ID supplied by the caller or replaces the
+// slug and name of the tenant that already has it, so a sync job can apply
+// the same request any number of times. When createOnly is set an existing
+// tenant is left untouched and ErrExists is returned. The returned flag
+// reports whether the tenant was created.
+func (c *Core) Upsert(ctx context.Context, tenantID uuid.UUID, nt NewTenant, createOnly bool) (Tenant, bool, error) {
+	if !validSlug.MatchString(nt.Slug) {
+		return Tenant{}, false, ErrInvalidSlug
+	}
+
+	tnt, err := c.storer.QueryByID(ctx, tenantID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		now := time.Now()
+
+		tnt = Tenant{
+			ID:          tenantID,
+			Slug:        nt.Slug,
+			Name:        nt.Name,
+			Enabled:     true,
+			DateCreated: now,
+			DateUpdated: now,
+		}
+
+		err := c.storer.Create(ctx, tnt)
+		if err == nil {
+			return tnt, true, nil
+		}
+
+		// A duplicate can also mean a concurrent request created the same
+		// tenant first, which only the ID tells apart from a taken slug.
+		if !errors.Is(err, ErrUniqueSlug) {
+			return Tenant{}, false, fmt.Errorf("create: %w", err)
+		}
+
+		if tnt, err = c.storer.QueryByID(ctx, tenantID); err != nil {
+			return Tenant{}, false, fmt.Errorf("create: %w", ErrUniqueSlug)
+		}
+
+	case err != nil:
+		return Tenant{}, false, fmt.Errorf("query: tenantID[%s]: %w", tenantID, err)
+	}
+
+	if createOnly {
+		return Tenant{}, false, ErrExists
+	}
+
+	tnt.Slug = nt.Slug
+	tnt.Name = nt.Name
+	tnt.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, tnt); err != nil {
+		return Tenant{}, false, fmt.Errorf("update: %w", err)
+	}
+
+	return tnt, false, nil
+}
+
+// UpdateIf applies the changes to the tenant only if it's still in the
+// expected state. The check is made by the same statement that writes the
+// change, so two clients racing on a transition can't both win. A losing
+// caller gets a *precondition.Error describing the current state.
+func (c *Core) UpdateIf(ctx context.Context, tenantID uuid.UUID, ut UpdateTenant, exp Expect) (Tenant, error) {
+	tnt, err := c.storer.QueryByID(ctx, tenantID)
+	if err != nil {
+		return Tenant{}, fmt.Errorf("query: tenantID[%s]: %w", tenantID, err)
+	}
+
+	if ut.Name != nil {
+		tnt.Name = *ut.Name
+	}
+
+	if ut.Enabled != nil {
+		tnt.Enabled = *ut.Enabled
+	}
+
+	tnt.DateUpdated = time.Now()
+
+	err = c.storer.UpdateIf(ctx, tnt, exp)
+	if err == nil {
+		return tnt, nil
+	}
+
+	if !errors.Is(err, ErrChanged) {
+		return Tenant{}, fmt.Errorf("update: %w", err)
+	}
+
+	cur, err := c.storer.QueryByID(ctx, tenantID)
+	if err != nil {
+		return Tenant{}, fmt.Errorf("query: tenantID[%s]: %w", tenantID, err)
+	}
+
+	var pe precondition.Error
+
+	if exp.Enabled != nil {
+		pe.Add("enabled", *exp.Enabled, cur.Enabled)
+	}
+
+	if exp.UpdatedBefore != nil && !cur.DateUpdated.Before(*exp.UpdatedBefore) {
+		pe.Add("dateUpdated", "before "+exp.UpdatedBefore.UTC().Format(time.RFC3339), cur.DateUpdated.UTC().Format(time.RFC3339))
+	}
+
+	// The row changed again before it was read back and now matches, so
+	// there's nothing useful to report beyond the conflict itself.
+	if err := pe.ToError(); err != nil {
+		return Tenant{}, err
+	}
+
+	return Tenant{}, fmt.Errorf("update: %w", ErrChanged)
+}
+
+// QueryByID finds the tenant by the specified ID.
+func (c *Core) QueryByID(ctx context.Context, tenantID uuid.UUID) (Tenant, error) {
+	tnt, err := c.storer.QueryByID(ctx, tenantID)
+	if err != nil {
+		return Tenant{}, fmt.Errorf("query: tenantID[%s]: %w", tenantID, err)
+	}
+
+	return tnt, nil
+}
+
+// QueryByIDs finds the tenants with the specified IDs. IDs that don't
+// match a tenant are ignored.
+func (c *Core) QueryByIDs(ctx context.Context, tenantIDs []uuid.UUID) ([]Tenant, error) {
+	if len(tenantIDs) == 0 {
+		return nil, nil
+	}
+
+	tnts, err := c.storer.QueryByIDs(ctx, tenantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return tnts, nil
+}
+
+// QueryBySlug finds the tenant by the specified slug.
+func (c *Core) QueryBySlug(ctx context.Context, slug string) (Tenant, error) {
+	tnt, err := c.storer.QueryBySlug(ctx, slug)
+	if err != nil {
+		return Tenant{}, fmt.Errorf("query: slug[%s]: %w", slug, err)
+	}
+
+	return tnt, nil
+}
+
+// Resolve finds the enabled tenant identified by either its ID or its slug.
+func (c *Core) Resolve(ctx context.Context, ref string) (Tenant, error) {
+	var tnt Tenant
+	var err error
+
+	switch tenantID, perr := uuid.Parse(ref); {
+	case perr == nil:
+		tnt, err = c.QueryByID(ctx, tenantID)
+	default:
+		tnt, err = c.QueryBySlug(ctx, ref)
+	}
+
+	if err != nil {
+		return Tenant{}, err
+	}
+
+	if !tnt.Enabled {
+		return Tenant{}, ErrDisabled
+	}
+
+	return tnt, nil
+}
@@ -0,0 +1,30 @@
+package usage
+
+import "time"
+
+// Usage represents the requests made by a subject against a route within
+// a window of time.
+type Usage struct {
+	Subject     string
+	Route       string
+	WindowStart time.Time
+	Requests    int64
+	Bytes       int64
+	Errors      int64
+}
+
+// ErrorRate returns the percentage of requests that failed.
+func (u Usage) ErrorRate() float64 {
+	if u.Requests == 0 {
+		return 0
+	}
+
+	return float64(u.Errors) / float64(u.Requests) * 100
+}
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	Route string
+	Since time.Time
+	Limit int
+}
@@ -0,0 +1,46 @@
+package usagedb
+
+import (
+	"time"
+
+	"github.com/mrcruz117/al-service/business/core/usage"
+)
+
+type dbUsage struct {
+	Subject     string    `db:"subject"`
+	Route       string    `db:"route"`
+	WindowStart time.Time `db:"window_start"`
+	Requests    int64     `db:"requests"`
+	Bytes       int64     `db:"bytes"`
+	Errors      int64     `db:"errors"`
+}
+
+func toDBUsage(u usage.Usage) dbUsage {
+	return dbUsage{
+		Subject:     u.Subject,
+		Route:       u.Route,
+		WindowStart: u.WindowStart.UTC(),
+		Requests:    u.Requests,
+		Bytes:       u.Bytes,
+		Errors:      u.Errors,
+	}
+}
+
+func toCoreUsage(dbu dbUsage) usage.Usage {
+	return usage.Usage{
+		Subject:     dbu.Subject,
+		Route:       dbu.Route,
+		WindowStart: dbu.WindowStart.In(time.Local),
+		Requests:    dbu.Requests,
+		Bytes:       dbu.Bytes,
+		Errors:      dbu.Errors,
+	}
+}
+
+func toCoreUsageSlice(dbUsages []dbUsage) []usage.Usage {
+	usages := make([]usage.Usage, len(dbUsages))
+	for i, dbu := range dbUsages {
+		usages[i] = toCoreUsage(dbu)
+	}
+	return usages
+}
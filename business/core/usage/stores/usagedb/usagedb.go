@@ -0,0 +1,116 @@
+// Package usagedb contains usage related CRUD functionality.
+package usagedb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/usage"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for usage database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Upsert adds the specified usage to any usage already recorded for the
+// same subject, route and window.
+func (s *Store) Upsert(ctx context.Context, usages []usage.Usage) error {
+	d := sqldb.DialectOf(s.db)
+
+	q := `
+	INSERT INTO "usage"
+		(subject, route, window_start, requests, bytes, errors)
+	VALUES
+		(:subject, :route, :window_start, :requests, :bytes, :errors)
+	` + d.Upsert([]string{"subject", "route", "window_start"},
+		`requests = "usage".requests + `+d.Excluded("requests"),
+		`bytes    = "usage".bytes + `+d.Excluded("bytes"),
+		`errors   = "usage".errors + `+d.Excluded("errors"),
+	)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, u := range usages {
+		if _, err := tx.NamedExecContext(ctx, q, toDBUsage(u)); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// QueryTop returns the subjects with the most requests per route.
+func (s *Store) QueryTop(ctx context.Context, filter usage.QueryFilter) ([]usage.Usage, error) {
+	data := map[string]any{
+		"route": filter.Route,
+		"since": filter.Since,
+		"limit": filter.Limit,
+	}
+
+	const q = `
+	SELECT
+		subject, route, window_start, requests, bytes, errors
+	FROM (
+		SELECT
+			subject,
+			route,
+			MIN(window_start) AS window_start,
+			SUM(requests)     AS requests,
+			SUM(bytes)        AS bytes,
+			SUM(errors)       AS errors,
+			ROW_NUMBER() OVER (PARTITION BY route ORDER BY SUM(requests) DESC) AS row_num
+		FROM
+			"usage"
+		WHERE
+			window_start >= :since AND
+			(:route = '' OR route = :route)
+		GROUP BY
+			subject, route
+	) AS ranked
+	WHERE
+		row_num <= :limit
+	ORDER BY
+		route, row_num`
+
+	rows, err := sqlx.NamedQueryContext(ctx, s.db, q, data)
+	if err != nil {
+		return nil, fmt.Errorf("namedquerycontext: %w", err)
+	}
+	defer rows.Close()
+
+	var dbUsages []dbUsage
+	for rows.Next() {
+		var dbu dbUsage
+		if err := rows.StructScan(&dbu); err != nil {
+			return nil, fmt.Errorf("structscan: %w", err)
+		}
+		dbUsages = append(dbUsages, dbu)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return toCoreUsageSlice(dbUsages), nil
+}
@@ -0,0 +1,121 @@
+// Package usagemem contains an in-memory implementation of the usage store
+// for unit tests. Usage is keyed by subject, route and window and aggregated
+// when written, so it is written by hand rather than generated.
+package usagemem
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/core/usage"
+)
+
+// key represents the primary key of the usage table.
+type key struct {
+	subject     string
+	route       string
+	windowStart time.Time
+}
+
+// Store manages the set of APIs for usage in-memory access.
+type Store struct {
+	table *memstore.Table[key, usage.Usage]
+}
+
+// NewStore constructs the api for in-memory access.
+func NewStore() *Store {
+	return &Store{
+		table: memstore.New(func(u usage.Usage) key {
+			return key{subject: u.Subject, route: u.Route, windowStart: u.WindowStart.UTC()}
+		}),
+	}
+}
+
+var _ usage.Storer = (*Store)(nil)
+
+// Upsert adds the usage to what is already stored for the same subject,
+// route and window.
+func (s *Store) Upsert(ctx context.Context, usages []usage.Usage) error {
+	for _, u := range usages {
+		merge := func(existing usage.Usage) usage.Usage {
+			existing.Requests += u.Requests
+			existing.Bytes += u.Bytes
+			existing.Errors += u.Errors
+			return existing
+		}
+
+		if err := s.table.UpsertFunc(u, merge); err != nil {
+			return fmt.Errorf("upsert: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// QueryTop returns the subjects with the most requests per route.
+func (s *Store) QueryTop(ctx context.Context, filter usage.QueryFilter) ([]usage.Usage, error) {
+	rows := s.table.Query(memstore.Query[usage.Usage]{
+		Match: func(u usage.Usage) bool {
+			return !u.WindowStart.Before(filter.Since) && (filter.Route == "" || u.Route == filter.Route)
+		},
+	})
+
+	// Sum the windows of each subject per route.
+	type group struct {
+		subject string
+		route   string
+	}
+
+	totals := make(map[group]*usage.Usage)
+	var order []group
+
+	for _, u := range rows {
+		g := group{subject: u.Subject, route: u.Route}
+
+		t, exists := totals[g]
+		if !exists {
+			t = &usage.Usage{Subject: u.Subject, Route: u.Route, WindowStart: u.WindowStart}
+			totals[g] = t
+			order = append(order, g)
+		}
+
+		if u.WindowStart.Before(t.WindowStart) {
+			t.WindowStart = u.WindowStart
+		}
+		t.Requests += u.Requests
+		t.Bytes += u.Bytes
+		t.Errors += u.Errors
+	}
+
+	top := make([]usage.Usage, 0, len(order))
+	for _, g := range order {
+		top = append(top, *totals[g])
+	}
+
+	slices.SortStableFunc(top, func(a usage.Usage, b usage.Usage) int {
+		if c := cmp.Compare(a.Route, b.Route); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Requests, a.Requests)
+	})
+
+	// Keep the top limit subjects of each route.
+	var ranked []usage.Usage
+	var rank int
+	for i, u := range top {
+		if i == 0 || u.Route != top[i-1].Route {
+			rank = 0
+		}
+		rank++
+
+		if rank <= filter.Limit {
+			ranked = append(ranked, u)
+		}
+	}
+
+	return ranked, nil
+}
@@ -0,0 +1,202 @@
+// Package usage provides support for accounting how clients use the API.
+// Usage is aggregated in memory and periodically flushed to storage.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/queuemon"
+	"github.com/mrcruz117/al-service/foundation/subsystem"
+)
+
+// Anonymous is the subject used when a request carries no claims.
+const Anonymous = "anonymous"
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Upsert(ctx context.Context, usages []Usage) error
+	QueryTop(ctx context.Context, filter QueryFilter) ([]Usage, error)
+}
+
+type key struct {
+	subject string
+	route   string
+	window  time.Time
+}
+
+// Core manages the set of APIs for usage access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+	window time.Duration
+
+	mu        sync.Mutex
+	usages    map[key]*Usage
+	oldest    time.Time
+	lastFlush time.Time
+}
+
+// NewCore constructs a core for usage api access. Usage is bucketed into
+// windows of the specified size.
+func NewCore(log *logger.Logger, storer Storer, window time.Duration) *Core {
+	return &Core{
+		log:       log,
+		storer:    storer,
+		window:    window,
+		usages:    make(map[key]*Usage),
+		lastFlush: time.Now(),
+	}
+}
+
+// Record accounts for a single request made by the subject against the route.
+func (c *Core) Record(subject string, route string, bytes int64, failed bool) {
+	if subject == "" {
+		subject = Anonymous
+	}
+
+	k := key{
+		subject: subject,
+		route:   route,
+		window:  time.Now().UTC().Truncate(c.window),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.usages) == 0 {
+		c.oldest = time.Now()
+	}
+
+	u, exists := c.usages[k]
+	if !exists {
+		u = &Usage{
+			Subject:     k.subject,
+			Route:       k.route,
+			WindowStart: k.window,
+		}
+		c.usages[k] = u
+	}
+
+	u.Requests++
+	u.Bytes += bytes
+	if failed {
+		u.Errors++
+	}
+}
+
+// Flush writes the usage aggregated since the last flush to storage. If the
+// write fails, the usage is merged back so it can be retried.
+func (c *Core) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	pending := c.usages
+	oldest := c.oldest
+	c.usages = make(map[key]*Usage)
+	c.mu.Unlock()
+
+	if len(pending) == 0 {
+		c.markFlushed()
+		return nil
+	}
+
+	usages := make([]Usage, 0, len(pending))
+	for _, u := range pending {
+		usages = append(usages, *u)
+	}
+
+	if err := c.storer.Upsert(ctx, usages); err != nil {
+		c.merge(pending, oldest)
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	c.markFlushed()
+
+	return nil
+}
+
+// Stats reports the state of the usage waiting to be flushed for monitoring.
+func (c *Core) Stats(ctx context.Context) (queuemon.Stats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := queuemon.Stats{
+		Depth: int64(len(c.usages)),
+		Lag:   time.Since(c.lastFlush),
+	}
+
+	if len(c.usages) > 0 {
+		stats.OldestAge = time.Since(c.oldest)
+	}
+
+	return stats, nil
+}
+
+// Run flushes the aggregated usage at the specified interval until the
+// context is canceled. While the switch is paused usage keeps aggregating in
+// memory and nothing is written.
+func (c *Core) Run(ctx context.Context, interval time.Duration, sw *subsystem.Switch) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if sw.Paused() {
+				continue
+			}
+
+			if err := c.Flush(ctx); err != nil {
+				c.log.Error(ctx, "usage", "status", "flush failed", "msg", err)
+			}
+		}
+	}
+}
+
+// QueryTop returns the top consumers per route for the specified filter.
+func (c *Core) QueryTop(ctx context.Context, filter QueryFilter) ([]Usage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+
+	usages, err := c.storer.QueryTop(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return usages, nil
+}
+
+func (c *Core) markFlushed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastFlush = time.Now()
+}
+
+func (c *Core) merge(pending map[key]*Usage, oldest time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.usages) == 0 || oldest.Before(c.oldest) {
+		c.oldest = oldest
+	}
+
+	for k, p := range pending {
+		u, exists := c.usages[k]
+		if !exists {
+			c.usages[k] = p
+			continue
+		}
+
+		u.Requests += p.Requests
+		u.Bytes += p.Bytes
+		u.Errors += p.Errors
+	}
+}
@@ -0,0 +1,77 @@
+package user
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+)
+
+// DomainName represents the name of this domain for delegate calls.
+const DomainName = "user"
+
+// Set of delegate actions the user core calls.
+const (
+	// ActionCreated is called after a user is created, with the parameters
+	// in an ActionCreatedParms.
+	ActionCreated = "created"
+
+	// ActionUpdated is called after a user is updated, with the parameters
+	// in an ActionUpdatedParms.
+	ActionUpdated = "updated"
+)
+
+// ActionCreatedParms represents the parameters of the created action. It
+// leaves the password hash and the rest of what is private to the user out,
+// since the parameters are published to other services as an event.
+type ActionCreatedParms struct {
+	UserID   uuid.UUID `json:"userID"`
+	TenantID uuid.UUID `json:"tenantID,omitzero"`
+	Email    string    `json:"email"`
+	Roles    []string  `json:"roles"`
+}
+
+// ActionCreatedData constructs the data for the created action.
+func ActionCreatedData(usr User) delegate.Data {
+	params := ActionCreatedParms{
+		UserID:   usr.ID,
+		TenantID: usr.TenantID,
+		Email:    usr.Email.Address,
+		Roles:    usr.Roles,
+	}
+
+	// Encoding a struct of plain values can't fail.
+	rawParams, _ := json.Marshal(params)
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    ActionCreated,
+		RawParams: rawParams,
+	}
+}
+
+// ActionUpdatedParms represents the parameters of the updated action. It
+// describes the user as it is after the update.
+type ActionUpdatedParms struct {
+	UserID  uuid.UUID `json:"userID"`
+	Enabled bool      `json:"enabled"`
+	Roles   []string  `json:"roles"`
+}
+
+// ActionUpdatedData constructs the data for the updated action.
+func ActionUpdatedData(usr User) delegate.Data {
+	params := ActionUpdatedParms{
+		UserID:  usr.ID,
+		Enabled: usr.Enabled,
+		Roles:   usr.Roles,
+	}
+
+	// Encoding a struct of plain values can't fail.
+	rawParams, _ := json.Marshal(params)
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    ActionUpdated,
+		RawParams: rawParams,
+	}
+}
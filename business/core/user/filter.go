@@ -0,0 +1,34 @@
+package user
+
+import (
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on. Fields
+// that are nil aren't filtered on.
+type QueryFilter struct {
+	ID               *uuid.UUID
+	Name             *string
+	Email            *mail.Address
+	Department       *string
+	Enabled          *bool
+	StartCreatedDate *time.Time
+	EndCreatedDate   *time.Time
+}
+
+// Validate checks the filter is considered clean before it is used.
+func (qf QueryFilter) Validate() error {
+	if qf.Name != nil && *qf.Name == "" {
+		return errors.New("name filter must not be empty")
+	}
+
+	if qf.StartCreatedDate != nil && qf.EndCreatedDate != nil && qf.EndCreatedDate.Before(*qf.StartCreatedDate) {
+		return errors.New("end created date must not be before start created date")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package user
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User represents information about an individual user. TenantID is
+// uuid.Nil for a user that belongs to no tenant.
+type User struct {
+	ID            uuid.UUID
+	TenantID      uuid.UUID
+	Name          string
+	Email         mail.Address
+	EmailVerified bool
+	Roles         []string
+	PasswordHash  []byte
+	Department    string
+	Enabled       bool
+	Version       int
+	DateCreated   time.Time
+	DateUpdated   time.Time
+}
+
+// Attributes returns the attributes of the user that can be mapped into the
+// claims of the tokens issued to it, keyed by name. The tenant is empty for
+// a user that belongs to no tenant.
+func (u User) Attributes() map[string]string {
+	attrs := map[string]string{
+		"name":       u.Name,
+		"email":      u.Email.Address,
+		"department": u.Department,
+	}
+
+	if u.TenantID != uuid.Nil {
+		attrs["tenant"] = u.TenantID.String()
+	}
+
+	return attrs
+}
+
+// NewUser contains information needed to create a new user.
+type NewUser struct {
+	Name       string
+	Email      mail.Address
+	Roles      []string
+	Department string
+	Password   string
+}
+
+// UpdateUser contains information needed to update a user. Fields that are
+// nil are left unchanged. Version, when set, is the version of the user the
+// change was made against.
+type UpdateUser struct {
+	Name          *string
+	Email         *mail.Address
+	EmailVerified *bool
+	Roles         []string
+	Department    *string
+	Password      *string
+	Enabled       *bool
+	Version       *int
+}
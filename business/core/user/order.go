@@ -0,0 +1,15 @@
+package user
+
+import "github.com/mrcruz117/al-service/business/api/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByID, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID          = "id"
+	OrderByName        = "name"
+	OrderByEmail       = "email"
+	OrderByEnabled     = "enabled"
+	OrderByDateCreated = "date_created"
+)
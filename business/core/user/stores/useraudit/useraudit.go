@@ -0,0 +1,130 @@
+// Package useraudit contains a user store that records the changes written
+// through it in the entity audit trail.
+package useraudit
+
+import (
+	"context"
+	"net/mail"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/entityaudit"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+// entity is the name the changes to users are recorded under.
+const entity = "user"
+
+// Store wraps a user store and records every successful change.
+type Store struct {
+	storer    user.Storer
+	auditCore *entityaudit.Core
+}
+
+// NewStore constructs the api for audited access.
+func NewStore(storer user.Storer, auditCore *entityaudit.Core) *Store {
+	return &Store{
+		storer:    storer,
+		auditCore: auditCore,
+	}
+}
+
+var _ user.Storer = (*Store)(nil)
+
+// ExecuteUnderTransaction constructs a new Store value with the wrapped
+// store and the audit trail under the transaction, so a change and its
+// record commit or roll back together.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (user.Storer, error) {
+	storer, err := s.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	auditCore, err := s.auditCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:    storer,
+		auditCore: auditCore,
+	}
+
+	return &store, nil
+}
+
+// Create inserts the user and records it.
+func (s *Store) Create(ctx context.Context, usr user.User) error {
+	if err := s.storer.Create(ctx, usr); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, usr.ID.String(), entityaudit.ActionCreate, nil, snapshot(usr))
+
+	return nil
+}
+
+// Update replaces the user and records what it was replaced from.
+func (s *Store) Update(ctx context.Context, usr user.User) error {
+	before := s.current(ctx, usr.ID)
+
+	if err := s.storer.Update(ctx, usr); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, usr.ID.String(), entityaudit.ActionUpdate, before, snapshot(usr))
+
+	return nil
+}
+
+// Delete removes the user and records what it was before.
+func (s *Store) Delete(ctx context.Context, usr user.User) error {
+	before := s.current(ctx, usr.ID)
+
+	if err := s.storer.Delete(ctx, usr); err != nil {
+		return err
+	}
+
+	s.auditCore.Record(ctx, entity, usr.ID.String(), entityaudit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// Query implements the user.Storer interface.
+func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.By, pg page.Page) ([]user.User, error) {
+	return s.storer.Query(ctx, filter, orderBy, pg)
+}
+
+// Count implements the user.Storer interface.
+func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID implements the user.Storer interface.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	return s.storer.QueryByID(ctx, userID)
+}
+
+// QueryByEmail implements the user.Storer interface.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
+	return s.storer.QueryByEmail(ctx, email)
+}
+
+// current reads the stored user for a snapshot, nil when it can't be read.
+func (s *Store) current(ctx context.Context, userID uuid.UUID) any {
+	usr, err := s.storer.QueryByID(ctx, userID)
+	if err != nil {
+		return nil
+	}
+
+	return snapshot(usr)
+}
+
+// snapshot leaves the password hash out of what is recorded. That the
+// password changed still shows through the version and date updated.
+func snapshot(usr user.User) user.User {
+	usr.PasswordHash = nil
+	return usr
+}
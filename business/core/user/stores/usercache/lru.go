@@ -0,0 +1,90 @@
+package usercache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is a fixed size set of entries that evicts the least recently used
+// one to make room. An entry past its expiry is treated as missing. It is
+// safe for concurrent use.
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type entry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// get returns the value for the key when it hasn't expired by now.
+func (l *lru) get(key string, now time.Time) (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, exists := l.entries[key]
+	if !exists {
+		return nil, false
+	}
+
+	e := elem.Value.(*entry)
+	if !now.Before(e.expires) {
+		l.remove(elem)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+
+	return e.value, true
+}
+
+// set stores the value for the key until it expires, evicting the least
+// recently used entry when the set is full.
+func (l *lru) set(key string, value any, expires time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, exists := l.entries[key]; exists {
+		e := elem.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&entry{key: key, value: value, expires: expires})
+
+	if l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+}
+
+// delete removes the keys, ignoring the ones that aren't stored.
+func (l *lru) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, exists := l.entries[key]; exists {
+			l.remove(elem)
+		}
+	}
+}
+
+func (l *lru) remove(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.entries, elem.Value.(*entry).key)
+}
@@ -0,0 +1,357 @@
+// Package usercache contains a user store that caches the users looked up
+// through it, which takes the lookups made to authenticate every request
+// off the database. Users are kept in process and, when a cache store is
+// configured, in Redis where every instance of the service shares them and
+// hears about the users the others change.
+package usercache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/cachestore"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Config is the optional properties of the cache.
+type Config struct {
+	// Size is how many users are kept in process, 10000 when zero.
+	Size int
+
+	// TTL is how long a user is cached for, a minute when zero. A write
+	// removes the user from the process that made it and from the cache
+	// store, and the other processes running Run remove their copy once
+	// they hear of it. A process that misses it keeps its copy until it
+	// expires.
+	TTL time.Duration
+
+	// Cache, when set, shares the cached users between the instances.
+	Cache *cachestore.Store
+}
+
+// Store wraps a user store and caches the users it looks up by id and
+// email. Writes go straight through and remove the user from the cache.
+type Store struct {
+	log    *logger.Logger
+	storer user.Storer
+	local  *lru
+	shared *cachestore.Store
+	ttl    time.Duration
+	tx     transaction.Transaction
+}
+
+// NewStore constructs the api for cached access.
+func NewStore(log *logger.Logger, storer user.Storer, cfg Config) *Store {
+	if cfg.Size <= 0 {
+		cfg.Size = 10_000
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+
+	return &Store{
+		log:    log,
+		storer: storer,
+		local:  newLRU(cfg.Size),
+		shared: cfg.Cache,
+		ttl:    cfg.TTL,
+	}
+}
+
+var _ user.Storer = (*Store)(nil)
+
+// ExecuteUnderTransaction constructs a new Store value with the wrapped
+// store under the transaction. What the transaction reads isn't committed
+// yet, so its lookups skip the cache, and its writes remove the users from
+// it once the transaction commits.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (user.Storer, error) {
+	storer, err := s.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:    s.log,
+		storer: storer,
+		local:  s.local,
+		shared: s.shared,
+		ttl:    s.ttl,
+		tx:     tx,
+	}
+
+	return &store, nil
+}
+
+// Create implements the user.Storer interface. Nothing is cached for a
+// user until it is looked up.
+func (s *Store) Create(ctx context.Context, usr user.User) error {
+	return s.storer.Create(ctx, usr)
+}
+
+// Update replaces the user and removes it from the cache.
+func (s *Store) Update(ctx context.Context, usr user.User) error {
+	if err := s.storer.Update(ctx, usr); err != nil {
+		return err
+	}
+
+	s.afterWrite(ctx, usr.ID)
+
+	return nil
+}
+
+// Delete removes the user and removes it from the cache.
+func (s *Store) Delete(ctx context.Context, usr user.User) error {
+	if err := s.storer.Delete(ctx, usr); err != nil {
+		return err
+	}
+
+	s.afterWrite(ctx, usr.ID)
+
+	return nil
+}
+
+// Query implements the user.Storer interface.
+func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.By, pg page.Page) ([]user.User, error) {
+	return s.storer.Query(ctx, filter, orderBy, pg)
+}
+
+// Count implements the user.Storer interface.
+func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified user from the cache, reading it from the
+// wrapped store when it isn't cached.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	if s.tx != nil {
+		return s.storer.QueryByID(ctx, userID)
+	}
+
+	if usr, exists := s.get(ctx, userID); exists {
+		return usr, nil
+	}
+
+	usr, err := s.storer.QueryByID(ctx, userID)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	s.set(ctx, usr)
+
+	return usr, nil
+}
+
+// QueryByEmail gets the specified user from the cache, reading it from the
+// wrapped store when it isn't cached. The email is cached as the id of the
+// user, which is only trusted while the user cached for that id still has
+// the email. Removing a user by id is then enough to drop its email too.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
+	if s.tx != nil {
+		return s.storer.QueryByEmail(ctx, email)
+	}
+
+	if userID, exists := s.getEmail(ctx, email.Address); exists {
+		if usr, exists := s.get(ctx, userID); exists && usr.Email.Address == email.Address {
+			return usr, nil
+		}
+	}
+
+	usr, err := s.storer.QueryByEmail(ctx, email)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	s.set(ctx, usr)
+
+	return usr, nil
+}
+
+// Run removes the users the other instances change from the process cache
+// as it hears of them, until the context is canceled. Without a cache store
+// there is nothing to hear of and it returns right away.
+func (s *Store) Run(ctx context.Context) {
+	if s.shared == nil {
+		return
+	}
+
+	sub := s.shared.Subscribe(ctx, invalidations)
+	defer sub.Close()
+
+	ch := sub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			userID, err := uuid.Parse(msg.Payload)
+			if err != nil {
+				s.log.Error(ctx, "usercache", "status", "decoding removal", "msg", err)
+				continue
+			}
+
+			s.local.delete(idKey(userID))
+		}
+	}
+}
+
+// =============================================================================
+
+// invalidations is the channel the users removed from the cache are
+// published on.
+const invalidations = "usercache:invalidate"
+
+// get returns the cached user, filling the process cache from the cache
+// store on a miss. The cache store failing is logged and treated as a
+// miss so lookups fall back to the database.
+func (s *Store) get(ctx context.Context, userID uuid.UUID) (user.User, bool) {
+	key := idKey(userID)
+	now := time.Now()
+
+	if v, exists := s.local.get(key, now); exists {
+		return clone(v.(user.User)), true
+	}
+
+	if s.shared == nil {
+		return user.User{}, false
+	}
+
+	data, err := s.shared.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cachestore.ErrNotFound) {
+			s.log.Error(ctx, "usercache", "status", "reading user", "userID", userID, "msg", err)
+		}
+		return user.User{}, false
+	}
+
+	var usr user.User
+	if err := json.Unmarshal(data, &usr); err != nil {
+		s.log.Error(ctx, "usercache", "status", "decoding user", "userID", userID, "msg", err)
+		return user.User{}, false
+	}
+
+	s.local.set(key, usr, now.Add(s.ttl))
+
+	return clone(usr), true
+}
+
+// getEmail returns the id of the user cached for the email.
+func (s *Store) getEmail(ctx context.Context, email string) (uuid.UUID, bool) {
+	key := emailKey(email)
+
+	if v, exists := s.local.get(key, time.Now()); exists {
+		return v.(uuid.UUID), true
+	}
+
+	if s.shared == nil {
+		return uuid.UUID{}, false
+	}
+
+	data, err := s.shared.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cachestore.ErrNotFound) {
+			s.log.Error(ctx, "usercache", "status", "reading email", "msg", err)
+		}
+		return uuid.UUID{}, false
+	}
+
+	userID, err := uuid.ParseBytes(data)
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+
+	return userID, true
+}
+
+// set caches the user under its id and its email.
+func (s *Store) set(ctx context.Context, usr user.User) {
+	expires := time.Now().Add(s.ttl)
+
+	s.local.set(idKey(usr.ID), clone(usr), expires)
+	s.local.set(emailKey(usr.Email.Address), usr.ID, expires)
+
+	if s.shared == nil {
+		return
+	}
+
+	data, err := json.Marshal(usr)
+	if err != nil {
+		s.log.Error(ctx, "usercache", "status", "encoding user", "userID", usr.ID, "msg", err)
+		return
+	}
+
+	if err := s.shared.Set(ctx, idKey(usr.ID), data, s.ttl); err != nil {
+		s.log.Error(ctx, "usercache", "status", "storing user", "userID", usr.ID, "msg", err)
+		return
+	}
+
+	if err := s.shared.Set(ctx, emailKey(usr.Email.Address), []byte(usr.ID.String()), s.ttl); err != nil {
+		s.log.Error(ctx, "usercache", "status", "storing email", "userID", usr.ID, "msg", err)
+	}
+}
+
+// afterWrite removes the written user from the cache. Under a transaction
+// that waits for the commit, so a lookup made in the meantime can't cache
+// the user as it was again once it has been removed.
+func (s *Store) afterWrite(ctx context.Context, userID uuid.UUID) {
+	if s.tx == nil {
+		s.invalidate(ctx, userID)
+		return
+	}
+
+	transaction.AfterCommit(s.tx, func() {
+		s.invalidate(ctx, userID)
+	})
+}
+
+// invalidate removes the user from the cache and tells the other instances
+// to remove it. A user the cache store fails to remove is served from it
+// until it expires, which is logged.
+func (s *Store) invalidate(ctx context.Context, userID uuid.UUID) {
+	key := idKey(userID)
+
+	s.local.delete(key)
+
+	if s.shared == nil {
+		return
+	}
+
+	if err := s.shared.Delete(ctx, key); err != nil {
+		s.log.Error(ctx, "usercache", "status", "removing user", "userID", userID, "msg", err)
+	}
+
+	if err := s.shared.Publish(ctx, invalidations, []byte(userID.String())); err != nil {
+		s.log.Error(ctx, "usercache", "status", "publishing removal", "userID", userID, "msg", err)
+	}
+}
+
+func idKey(userID uuid.UUID) string {
+	return "usercache:id:" + userID.String()
+}
+
+func emailKey(email string) string {
+	return "usercache:email:" + email
+}
+
+// clone copies the slices of the user so callers can't change the cached
+// copy through them.
+func clone(usr user.User) user.User {
+	usr.Roles = slices.Clone(usr.Roles)
+	usr.PasswordHash = slices.Clone(usr.PasswordHash)
+	return usr
+}
@@ -0,0 +1,104 @@
+package usercache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"testing"
+
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usercache"
+	"github.com/mrcruz117/al-service/business/core/user/stores/usermem"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Test_Transaction updates a cached user under a transaction and checks the
+// cache keeps serving the committed user until the transaction commits, and
+// keeps it when the transaction rolls back.
+func Test_Transaction(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+	ctx := context.Background()
+
+	userCore := user.NewCore(log, delegate.New(log), usercache.NewStore(log, usermem.NewStore(), usercache.Config{}))
+
+	usr, err := userCore.Create(ctx, user.NewUser{
+		Name:     "Gopher",
+		Email:    mail.Address{Address: "gopher@example.com"},
+		Roles:    []string{"USER"},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	queryName := func() string {
+		t.Helper()
+
+		got, err := userCore.QueryByID(ctx, usr.ID)
+		if err != nil {
+			t.Fatalf("Should be able to retrieve the user : %s", err)
+		}
+
+		return got.Name
+	}
+
+	// Cache the user.
+	queryName()
+
+	update := func(name string, fnErr error) {
+		t.Helper()
+
+		err := transaction.Execute(ctx, beginner{}, func(tx transaction.Transaction) error {
+			txCore, err := userCore.ExecuteUnderTransaction(tx)
+			if err != nil {
+				return err
+			}
+
+			if usr, err = txCore.Update(ctx, usr, user.UpdateUser{Name: &name}); err != nil {
+				return err
+			}
+
+			if got := queryName(); got != "Gopher" {
+				t.Errorf("Should serve the committed user until the commit, got %q", got)
+			}
+
+			return fnErr
+		})
+		if !errors.Is(err, fnErr) {
+			t.Fatalf("Should get the error of the transaction, got %v", err)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	// The memory store can't roll back, so the cache is left holding the
+	// user as it was before the update.
+	errRollback := errors.New("rollback")
+	update("Rolled Back", errRollback)
+
+	if got := queryName(); got != "Gopher" {
+		t.Errorf("Should keep the cached user after a rollback, got %q", got)
+	}
+
+	update("Committed", nil)
+
+	if got := queryName(); got != "Committed" {
+		t.Errorf("Should read the user again after the commit, got %q", got)
+	}
+}
+
+// =============================================================================
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type beginner struct{}
+
+func (beginner) Begin(context.Context) (transaction.Transaction, error) {
+	return fakeTx{}, nil
+}
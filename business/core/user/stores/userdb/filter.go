@@ -0,0 +1,63 @@
+package userdb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+// applyFilter returns the WHERE clause for the filter, limited to the tenant
+// the context is scoped to, and adds the values of its parameters to the
+// data. The clause is empty when nothing is filtered on.
+func applyFilter(ctx context.Context, filter user.QueryFilter, data map[string]any) string {
+	var wc []string
+
+	if filter.ID != nil {
+		data["user_id"] = *filter.ID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Name != nil {
+		data["name"] = sqldb.ContainsPattern(strings.ToLower(*filter.Name))
+		wc = append(wc, "LOWER(name) LIKE :name")
+	}
+
+	if filter.Email != nil {
+		data["email"] = filter.Email.Address
+		wc = append(wc, "email = :email")
+	}
+
+	if filter.Department != nil {
+		data["department"] = *filter.Department
+		wc = append(wc, "department = :department")
+	}
+
+	if filter.Enabled != nil {
+		data["enabled"] = *filter.Enabled
+		wc = append(wc, "enabled = :enabled")
+	}
+
+	if filter.StartCreatedDate != nil {
+		data["start_date_created"] = filter.StartCreatedDate.UTC()
+		wc = append(wc, "date_created >= :start_date_created")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_date_created"] = filter.EndCreatedDate.UTC()
+		wc = append(wc, "date_created <= :end_date_created")
+	}
+
+	wc = scopeTenant(ctx, wc, data)
+
+	return sqldb.Where(wc)
+}
+
+// scopeTenant limits the conditions to the users of the tenant the context
+// is scoped to.
+func scopeTenant(ctx context.Context, wc []string, data map[string]any) []string {
+	tenantID, _ := tenant.IDFromContext(ctx)
+	return sqldb.ScopeTenant(wc, data, tenantID)
+}
@@ -0,0 +1,79 @@
+package userdb
+
+import (
+	"database/sql"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/sqldb/dbarray"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+type dbUser struct {
+	ID            uuid.UUID      `db:"user_id"`
+	TenantID      uuid.NullUUID  `db:"tenant_id"`
+	Name          string         `db:"name"`
+	Email         string         `db:"email"`
+	EmailVerified bool           `db:"email_verified"`
+	Roles         dbarray.String `db:"roles"`
+	PasswordHash  []byte         `db:"password_hash"`
+	Department    sql.NullString `db:"department"`
+	Enabled       bool           `db:"enabled"`
+	Version       int            `db:"version"`
+	DateCreated   time.Time      `db:"date_created"`
+	DateUpdated   time.Time      `db:"date_updated"`
+}
+
+func toDBUser(usr user.User) dbUser {
+	return dbUser{
+		ID: usr.ID,
+		TenantID: uuid.NullUUID{
+			UUID:  usr.TenantID,
+			Valid: usr.TenantID != uuid.Nil,
+		},
+		Name:          usr.Name,
+		Email:         usr.Email.Address,
+		EmailVerified: usr.EmailVerified,
+		Roles:         usr.Roles,
+		PasswordHash:  usr.PasswordHash,
+		Department: sql.NullString{
+			String: usr.Department,
+			Valid:  usr.Department != "",
+		},
+		Enabled:     usr.Enabled,
+		Version:     usr.Version,
+		DateCreated: usr.DateCreated.UTC(),
+		DateUpdated: usr.DateUpdated.UTC(),
+	}
+}
+
+func toCoreUser(dbUsr dbUser) user.User {
+	addr := mail.Address{
+		Address: dbUsr.Email,
+	}
+
+	return user.User{
+		ID:            dbUsr.ID,
+		TenantID:      dbUsr.TenantID.UUID,
+		Name:          dbUsr.Name,
+		Email:         addr,
+		EmailVerified: dbUsr.EmailVerified,
+		Roles:         dbUsr.Roles,
+		PasswordHash:  dbUsr.PasswordHash,
+		Department:    dbUsr.Department.String,
+		Enabled:       dbUsr.Enabled,
+		Version:       dbUsr.Version,
+		DateCreated:   dbUsr.DateCreated.In(time.Local),
+		DateUpdated:   dbUsr.DateUpdated.In(time.Local),
+	}
+}
+
+func toCoreUserSlice(dbUsers []dbUser) []user.User {
+	usrs := make([]user.User, len(dbUsers))
+	for i, dbUsr := range dbUsers {
+		usrs[i] = toCoreUser(dbUsr)
+	}
+
+	return usrs
+}
@@ -0,0 +1,37 @@
+package userdb
+
+import (
+	"fmt"
+
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+var orderByFields = map[string]string{
+	user.OrderByID:          "user_id",
+	user.OrderByName:        "name",
+	user.OrderByEmail:       "email",
+	user.OrderByEnabled:     "enabled",
+	user.OrderByDateCreated: "date_created",
+}
+
+// orderByClause translates the order to SQL. Only the columns above and the
+// two directions can end up in the query. Rows that tie are ordered by id
+// so pages don't overlap.
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	if orderBy.Direction != order.ASC && orderBy.Direction != order.DESC {
+		return "", fmt.Errorf("direction %q does not exist", orderBy.Direction)
+	}
+
+	clause := by + " " + orderBy.Direction
+	if by != "user_id" {
+		clause += ", user_id"
+	}
+
+	return clause, nil
+}
@@ -0,0 +1,218 @@
+// Package userdb contains user related CRUD functionality.
+package userdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/user"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for user database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (user.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new user into the database.
+func (s *Store) Create(ctx context.Context, usr user.User) error {
+	const q = `
+	INSERT INTO users
+		(user_id, tenant_id, name, email, email_verified, roles, password_hash, department, enabled, version, date_created, date_updated)
+	VALUES
+		(:user_id, :tenant_id, :name, :email, :email_verified, :roles, :password_hash, :department, :enabled, :version, :date_created, :date_updated)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", user.ErrUniqueEmail)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a user document in the database if it is still at the
+// version before the one of the user.
+func (s *Store) Update(ctx context.Context, usr user.User) error {
+	const q = `
+	UPDATE
+		users
+	SET
+		"name" = :name,
+		"email" = :email,
+		"email_verified" = :email_verified,
+		"roles" = :roles,
+		"password_hash" = :password_hash,
+		"department" = :department,
+		"enabled" = :enabled,
+		"version" = :version,
+		"date_updated" = :date_updated
+	WHERE
+		user_id = :user_id AND
+		"version" = :version - 1`
+
+	if err := sqldb.NamedExecVersioned(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrDBDuplicatedEntry):
+			return fmt.Errorf("namedexecversioned: %w", user.ErrUniqueEmail)
+		case errors.Is(err, sqldb.ErrDBVersionConflict):
+			return fmt.Errorf("namedexecversioned: %w: %w", user.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("namedexecversioned: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user from the database.
+func (s *Store) Delete(ctx context.Context, usr user.User) error {
+	data := map[string]any{
+		"user_id": usr.ID.String(),
+	}
+
+	wc := scopeTenant(ctx, []string{"user_id = :user_id"}, data)
+
+	q := `
+	DELETE FROM
+		users` + sqldb.Where(wc)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a page of users from the database.
+func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.By, pg page.Page) ([]user.User, error) {
+	data := map[string]any{
+		"offset":        pg.Offset(),
+		"rows_per_page": pg.RowsPerPage(),
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+	SELECT
+		user_id, tenant_id, name, email, email_verified, roles, password_hash, department, enabled, version, date_created, date_updated
+	FROM
+		users` + applyFilter(ctx, filter, data) + `
+	ORDER BY
+		` + orderByClause + `
+	LIMIT :rows_per_page OFFSET :offset`
+
+	var dbUsrs []dbUser
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreUserSlice(dbUsrs), nil
+}
+
+// Count returns the total number of users in the database matching the
+// filter.
+func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	q := `
+	SELECT
+		count(1) AS "count"
+	FROM
+		users` + applyFilter(ctx, filter, data)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified user from the database.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	data := map[string]any{
+		"user_id": userID.String(),
+	}
+
+	wc := scopeTenant(ctx, []string{"user_id = :user_id"}, data)
+
+	q := `
+	SELECT
+		user_id, tenant_id, name, email, email_verified, roles, password_hash, department, enabled, version, date_created, date_updated
+	FROM
+		users` + sqldb.Where(wc)
+
+	var dbUsr dbUser
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return user.User{}, fmt.Errorf("namedquerystruct: %w", user.ErrNotFound)
+		}
+		return user.User{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreUser(dbUsr), nil
+}
+
+// QueryByEmail gets the specified user from the database by email.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
+	data := map[string]any{
+		"email": email.Address,
+	}
+
+	wc := scopeTenant(ctx, []string{"email = :email"}, data)
+
+	q := `
+	SELECT
+		user_id, tenant_id, name, email, email_verified, roles, password_hash, department, enabled, version, date_created, date_updated
+	FROM
+		users` + sqldb.Where(wc)
+
+	var dbUsr dbUser
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return user.User{}, fmt.Errorf("namedquerystruct: %w", user.ErrNotFound)
+		}
+		return user.User{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreUser(dbUsr), nil
+}
@@ -0,0 +1,61 @@
+package userdb_test
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"os"
+	"testing"
+
+	"github.com/mrcruz117/al-service/business/api/dbtest"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Run(m))
+}
+
+func Test_User(t *testing.T) {
+	test := dbtest.New(t)
+	ctx := context.Background()
+
+	admin, err := test.Core.User.QueryByEmail(ctx, mail.Address{Address: "admin@example.com"})
+	if err != nil {
+		t.Fatalf("Should be able to query the seeded admin : %s", err)
+	}
+	if admin.Name != "Admin Gopher" {
+		t.Errorf("Should get the seeded admin, got %q", admin.Name)
+	}
+
+	nu := user.NewUser{
+		Name:     "Store Gopher",
+		Email:    mail.Address{Address: "store@example.com"},
+		Roles:    []string{"USER"},
+		Password: "gophers",
+	}
+
+	usr, err := test.Core.User.Create(ctx, nu)
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	got, err := test.Core.User.QueryByID(ctx, usr.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the user by id : %s", err)
+	}
+	if got.Email.Address != nu.Email.Address {
+		t.Errorf("Should get the user back, got email %q", got.Email.Address)
+	}
+
+	if _, err := test.Core.User.Create(ctx, nu); !errors.Is(err, user.ErrUniqueEmail) {
+		t.Errorf("Should not be able to create a second user with the email, got %v", err)
+	}
+
+	if err := test.Core.User.Delete(ctx, usr); err != nil {
+		t.Fatalf("Should be able to delete the user : %s", err)
+	}
+
+	if _, err := test.Core.User.QueryByID(ctx, usr.ID); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("Should not find the deleted user, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+// Package usermem contains an in-memory implementation of the user store for
+// unit tests.
+package usermem
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/memstore"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain user -unique Email.Address=ErrUniqueEmail
+
+// Update replaces the user in the store if it is still at the version
+// before the one of the user.
+func (s *Store) Update(ctx context.Context, usr user.User) error {
+	err := s.table.UpdateIf(usr, func(existing user.User) bool {
+		return existing.Version == usr.Version-1
+	})
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBVersionConflict) {
+			return fmt.Errorf("update: %w: %w", user.ErrVersionConflict, err)
+		}
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the user from the store, unless it belongs to another
+// tenant.
+func (s *Store) Delete(ctx context.Context, usr user.User) error {
+	existing, err := s.table.Get(usr.ID)
+	if err != nil || !memstore.InTenant(ctx, existing.TenantID) {
+		return nil
+	}
+
+	s.table.Delete(usr.ID)
+
+	return nil
+}
+
+// QueryByID gets the specified user from the store, unless it belongs to
+// another tenant.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	usr, err := s.table.Get(userID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return user.User{}, fmt.Errorf("query: %w", user.ErrNotFound)
+		}
+		return user.User{}, fmt.Errorf("query: %w", err)
+	}
+
+	if !memstore.InTenant(ctx, usr.TenantID) {
+		return user.User{}, fmt.Errorf("query: %w", user.ErrNotFound)
+	}
+
+	return usr, nil
+}
+
+// QueryByEmail gets the specified user from the store by email, unless it
+// belongs to another tenant.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
+	usr, err := s.table.First(func(usr user.User) bool {
+		return usr.Email.Address == email.Address && memstore.InTenant(ctx, usr.TenantID)
+	})
+	if err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return user.User{}, fmt.Errorf("query: %w", user.ErrNotFound)
+		}
+		return user.User{}, fmt.Errorf("query: %w", err)
+	}
+
+	return usr, nil
+}
+
+// Query retrieves a page of the users matching the filter, ordered like the
+// database store.
+func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.By, pg page.Page) ([]user.User, error) {
+	less, err := lessBy(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	usrs := s.table.Query(memstore.Query[user.User]{
+		Match:  match(ctx, filter),
+		Less:   less,
+		Offset: pg.Offset(),
+		Limit:  pg.RowsPerPage(),
+	})
+
+	return usrs, nil
+}
+
+// Count returns the total number of users matching the filter.
+func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error) {
+	return s.table.Count(match(ctx, filter)), nil
+}
+
+func match(ctx context.Context, filter user.QueryFilter) func(user.User) bool {
+	return func(usr user.User) bool {
+		switch {
+		case !memstore.InTenant(ctx, usr.TenantID):
+			return false
+		case filter.ID != nil && usr.ID != *filter.ID:
+			return false
+		case filter.Name != nil && !strings.Contains(strings.ToLower(usr.Name), strings.ToLower(*filter.Name)):
+			return false
+		case filter.Email != nil && usr.Email.Address != filter.Email.Address:
+			return false
+		case filter.Department != nil && usr.Department != *filter.Department:
+			return false
+		case filter.Enabled != nil && usr.Enabled != *filter.Enabled:
+			return false
+		case filter.StartCreatedDate != nil && usr.DateCreated.Before(*filter.StartCreatedDate):
+			return false
+		case filter.EndCreatedDate != nil && usr.DateCreated.After(*filter.EndCreatedDate):
+			return false
+		}
+
+		return true
+	}
+}
+
+// lessBy orders like the database store, where rows that tie are ordered
+// by id ascending whatever the direction.
+func lessBy(orderBy order.By) (func(a user.User, b user.User) bool, error) {
+	var compare func(a user.User, b user.User) int
+
+	switch orderBy.Field {
+	case user.OrderByID:
+		compare = func(a user.User, b user.User) int { return strings.Compare(a.ID.String(), b.ID.String()) }
+	case user.OrderByName:
+		compare = func(a user.User, b user.User) int { return strings.Compare(a.Name, b.Name) }
+	case user.OrderByEmail:
+		compare = func(a user.User, b user.User) int { return strings.Compare(a.Email.Address, b.Email.Address) }
+	case user.OrderByEnabled:
+		compare = func(a user.User, b user.User) int { return compareBool(a.Enabled, b.Enabled) }
+	case user.OrderByDateCreated:
+		compare = func(a user.User, b user.User) int { return a.DateCreated.Compare(b.DateCreated) }
+	default:
+		return nil, fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	less := func(a user.User, b user.User) bool {
+		c := compare(a, b)
+		if orderBy.Direction == order.DESC {
+			c = -c
+		}
+
+		if c == 0 {
+			return a.ID.String() < b.ID.String()
+		}
+		return c < 0
+	}
+
+	return less, nil
+}
+
+func compareBool(a bool, b bool) int {
+	toInt := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+
+	return cmp.Compare(toInt(a), toInt(b))
+}
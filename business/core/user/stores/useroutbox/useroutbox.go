@@ -0,0 +1,137 @@
+// Package useroutbox contains a user store that adds the events about the
+// users written through it to the outbox, in the same transaction as the
+// write.
+package useroutbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/outbox"
+	"github.com/mrcruz117/al-service/business/core/user"
+)
+
+// The event added when a user is created. Its data is the parameters of
+// the created action, the version is bumped when they change in a way a
+// consumer would notice.
+const (
+	eventCreated        = "user.created"
+	eventCreatedVersion = 1
+)
+
+// Store wraps a user store and adds an event to the outbox for every user
+// created.
+type Store struct {
+	storer     user.Storer
+	outboxCore *outbox.Core
+	beginner   transaction.Beginner
+}
+
+// NewStore constructs the api for access that adds events to the outbox. A
+// write made outside of a transaction is made in one begun by the
+// beginner, so the user and its event are stored together either way.
+func NewStore(storer user.Storer, outboxCore *outbox.Core, beginner transaction.Beginner) *Store {
+	return &Store{
+		storer:     storer,
+		outboxCore: outboxCore,
+		beginner:   beginner,
+	}
+}
+
+var _ user.Storer = (*Store)(nil)
+
+// ExecuteUnderTransaction constructs a new Store value with the wrapped
+// store and the outbox under the transaction, so a change and its event
+// commit or roll back together.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (user.Storer, error) {
+	return s.underTransaction(tx)
+}
+
+// Create inserts the user and adds the user.created event.
+func (s *Store) Create(ctx context.Context, usr user.User) error {
+	if s.beginner == nil {
+		return s.create(ctx, usr)
+	}
+
+	f := func(tx transaction.Transaction) error {
+		store, err := s.underTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		return store.create(ctx, usr)
+	}
+
+	return transaction.Execute(ctx, s.beginner, f)
+}
+
+// Update implements the user.Storer interface.
+func (s *Store) Update(ctx context.Context, usr user.User) error {
+	return s.storer.Update(ctx, usr)
+}
+
+// Delete implements the user.Storer interface.
+func (s *Store) Delete(ctx context.Context, usr user.User) error {
+	return s.storer.Delete(ctx, usr)
+}
+
+// Query implements the user.Storer interface.
+func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.By, pg page.Page) ([]user.User, error) {
+	return s.storer.Query(ctx, filter, orderBy, pg)
+}
+
+// Count implements the user.Storer interface.
+func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// QueryByID implements the user.Storer interface.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	return s.storer.QueryByID(ctx, userID)
+}
+
+// QueryByEmail implements the user.Storer interface.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
+	return s.storer.QueryByEmail(ctx, email)
+}
+
+// underTransaction constructs the store under the transaction. It has no
+// beginner, the owner of the transaction decides whether it commits.
+func (s *Store) underTransaction(tx transaction.Transaction) (*Store, error) {
+	storer, err := s.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxCore, err := s.outboxCore.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:     storer,
+		outboxCore: outboxCore,
+	}
+
+	return &store, nil
+}
+
+func (s *Store) create(ctx context.Context, usr user.User) error {
+	if err := s.storer.Create(ctx, usr); err != nil {
+		return err
+	}
+
+	data := json.RawMessage(user.ActionCreatedData(usr).RawParams)
+
+	if err := s.outboxCore.Add(ctx, eventCreated, eventCreatedVersion, usr.ID.String(), data); err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+// Package user provides business access to user data in the system.
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/delegate"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/transaction"
+	"github.com/mrcruz117/al-service/business/core/tenant"
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound              = errors.New("user not found")
+	ErrUniqueEmail           = errors.New("email is not unique")
+	ErrUserDisabled          = errors.New("user disabled")
+	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrVersionConflict       = errors.New("user was changed by another request")
+)
+
+// dummyHash is compared against when no user has the email, so an unknown
+// email takes as long to refuse as a wrong password. It is made at the
+// default cost passwords are hashed with.
+var dummyHash = []byte("$2a$10$8KF62ojrJRTc3HGNd5kNVu4kMS5Qikrh6S8o5ajX2RcdZq1VN4//G")
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, usr User) error
+	Update(ctx context.Context, usr User) error
+	Delete(ctx context.Context, usr User) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]User, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
+	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+}
+
+// Core manages the set of APIs for user access.
+type Core struct {
+	log      *logger.Logger
+	delegate *delegate.Delegate
+	storer   Storer
+}
+
+// NewCore constructs a core for user api access.
+func NewCore(log *logger.Logger, delegate *delegate.Delegate, storer Storer) *Core {
+	return &Core{
+		log:      log,
+		delegate: delegate,
+		storer:   storer,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls. The functions the
+// delegate calls run outside of it, against the committed data.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	core := Core{
+		log:      c.log,
+		delegate: c.delegate,
+		storer:   storer,
+	}
+
+	return &core, nil
+}
+
+// Create adds a new user to the system. The user belongs to the tenant the
+// context is scoped to, if any.
+func (c *Core) Create(ctx context.Context, nu NewUser) (User, error) {
+	tenantID, err := tenant.OwnerFromContext(ctx)
+	if err != nil {
+		return User{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(nu.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return User{}, fmt.Errorf("generatefrompassword: %w", err)
+	}
+
+	now := time.Now()
+
+	usr := User{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		Name:         nu.Name,
+		Email:        nu.Email,
+		Roles:        nu.Roles,
+		PasswordHash: hash,
+		Department:   nu.Department,
+		Enabled:      true,
+		Version:      1,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	if err := c.storer.Create(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("create: %w", err)
+	}
+
+	if err := c.delegate.Call(ctx, ActionCreatedData(usr)); err != nil {
+		c.log.Error(ctx, "user", "status", "delegate call failed", "action", ActionCreated, "userID", usr.ID, "msg", err)
+	}
+
+	return usr, nil
+}
+
+// Update modifies information about a user, as long as no other update was
+// made since it was read and it is at the expected version when one is
+// given. ErrVersionConflict is returned otherwise.
+func (c *Core) Update(ctx context.Context, usr User, uu UpdateUser) (User, error) {
+	if err := checkTenant(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+	}
+
+	if uu.Version != nil && *uu.Version != usr.Version {
+		return User{}, fmt.Errorf("update: version[%d] expected[%d]: %w", usr.Version, *uu.Version, ErrVersionConflict)
+	}
+
+	if uu.Name != nil {
+		usr.Name = *uu.Name
+	}
+
+	if uu.Email != nil {
+		if usr.Email.Address != uu.Email.Address {
+			usr.EmailVerified = false
+		}
+		usr.Email = *uu.Email
+	}
+
+	if uu.EmailVerified != nil {
+		usr.EmailVerified = *uu.EmailVerified
+	}
+
+	if uu.Roles != nil {
+		usr.Roles = uu.Roles
+	}
+
+	if uu.Department != nil {
+		usr.Department = *uu.Department
+	}
+
+	if uu.Password != nil {
+		pw, err := bcrypt.GenerateFromPassword([]byte(*uu.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return User{}, fmt.Errorf("generatefrompassword: %w", err)
+		}
+		usr.PasswordHash = pw
+	}
+
+	if uu.Enabled != nil {
+		usr.Enabled = *uu.Enabled
+	}
+
+	usr.Version++
+	usr.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	// The update is stored by now, so the domains that failed to act on it
+	// are logged rather than failing the update.
+	if err := c.delegate.Call(ctx, ActionUpdatedData(usr)); err != nil {
+		c.log.Error(ctx, "user", "status", "delegate call failed", "action", ActionUpdated, "userID", usr.ID, "msg", err)
+	}
+
+	return usr, nil
+}
+
+// Delete removes the specified user.
+func (c *Core) Delete(ctx context.Context, usr User) error {
+	if err := checkTenant(ctx, usr); err != nil {
+		return fmt.Errorf("delete: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := c.storer.Delete(ctx, usr); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a page of users matching the filter, in the order
+// asked for.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]User, error) {
+	users, err := c.storer.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return users, nil
+}
+
+// Count returns the total number of users matching the filter.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	n, err := c.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+
+	return n, nil
+}
+
+// QueryByID finds the user by the specified ID.
+func (c *Core) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
+	usr, err := c.storer.QueryByID(ctx, userID)
+	if err != nil {
+		return User{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	if err := checkTenant(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return usr, nil
+}
+
+// QueryByEmail finds the user by a specified user email.
+func (c *Core) QueryByEmail(ctx context.Context, email mail.Address) (User, error) {
+	usr, err := c.storer.QueryByEmail(ctx, email)
+	if err != nil {
+		return User{}, fmt.Errorf("query: email[%s]: %w", email.Address, err)
+	}
+
+	if err := checkTenant(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("query: email[%s]: %w", email.Address, err)
+	}
+
+	return usr, nil
+}
+
+// Authenticate finds a user by their email and verifies their password. On
+// success it returns the User representing this user.
+func (c *Core) Authenticate(ctx context.Context, email mail.Address, password string) (User, error) {
+	usr, err := c.QueryByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+			return User{}, ErrAuthenticationFailure
+		}
+		return User{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
+		return User{}, ErrAuthenticationFailure
+	}
+
+	if !usr.Enabled {
+		return User{}, ErrUserDisabled
+	}
+
+	return usr, nil
+}
+
+// CheckPassword verifies the password is the one the user has, for changes
+// that must be confirmed with it. ErrAuthenticationFailure is returned
+// otherwise.
+func (c *Core) CheckPassword(usr User, password string) error {
+	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
+		return ErrAuthenticationFailure
+	}
+
+	return nil
+}
+
+// checkTenant denies access to a user of another tenant than the one the
+// context is scoped to. The user is reported as not found, so a tenant
+// can't tell the users of others apart from ones that don't exist.
+func checkTenant(ctx context.Context, usr User) error {
+	if err := tenant.CheckAccess(ctx, usr.TenantID); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	return nil
+}
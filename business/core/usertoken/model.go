@@ -0,0 +1,28 @@
+package usertoken
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of purposes a token can be issued for.
+const (
+	VerifyEmail   Purpose = "verify_email"
+	ResetPassword Purpose = "reset_password"
+)
+
+// Purpose represents the action a token authorizes.
+type Purpose string
+
+// Token represents a single use token issued to a user. Only the hash of the
+// token is ever stored.
+type Token struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Purpose     Purpose
+	Hash        string
+	ExpiresAt   time.Time
+	UsedAt      time.Time
+	DateCreated time.Time
+}
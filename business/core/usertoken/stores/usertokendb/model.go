@@ -0,0 +1,51 @@
+package usertokendb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/usertoken"
+)
+
+type dbToken struct {
+	ID          uuid.UUID    `db:"token_id"`
+	UserID      uuid.UUID    `db:"user_id"`
+	Purpose     string       `db:"purpose"`
+	Hash        string       `db:"token_hash"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+	UsedAt      sql.NullTime `db:"used_at"`
+	DateCreated time.Time    `db:"date_created"`
+}
+
+func toDBToken(tkn usertoken.Token) dbToken {
+	return dbToken{
+		ID:        tkn.ID,
+		UserID:    tkn.UserID,
+		Purpose:   string(tkn.Purpose),
+		Hash:      tkn.Hash,
+		ExpiresAt: tkn.ExpiresAt.UTC(),
+		UsedAt: sql.NullTime{
+			Time:  tkn.UsedAt.UTC(),
+			Valid: !tkn.UsedAt.IsZero(),
+		},
+		DateCreated: tkn.DateCreated.UTC(),
+	}
+}
+
+func toCoreToken(dbTkn dbToken) usertoken.Token {
+	tkn := usertoken.Token{
+		ID:          dbTkn.ID,
+		UserID:      dbTkn.UserID,
+		Purpose:     usertoken.Purpose(dbTkn.Purpose),
+		Hash:        dbTkn.Hash,
+		ExpiresAt:   dbTkn.ExpiresAt.In(time.Local),
+		DateCreated: dbTkn.DateCreated.In(time.Local),
+	}
+
+	if dbTkn.UsedAt.Valid {
+		tkn.UsedAt = dbTkn.UsedAt.Time.In(time.Local)
+	}
+
+	return tkn
+}
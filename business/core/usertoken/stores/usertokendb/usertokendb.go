@@ -0,0 +1,130 @@
+// Package usertokendb contains user token related CRUD functionality.
+package usertokendb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/usertoken"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for user token database access.
+type Store struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new token into the database.
+func (s *Store) Create(ctx context.Context, tkn usertoken.Token) error {
+	const q = `
+	INSERT INTO user_tokens
+		(token_id, user_id, purpose, token_hash, expires_at, used_at, date_created)
+	VALUES
+		(:token_id, :user_id, :purpose, :token_hash, :expires_at, :used_at, :date_created)`
+
+	if _, err := s.db.NamedExecContext(ctx, q, toDBToken(tkn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByHash gets the token with the specified hash from the database.
+func (s *Store) QueryByHash(ctx context.Context, hash string) (usertoken.Token, error) {
+	const q = `
+	SELECT
+		token_id, user_id, purpose, token_hash, expires_at, used_at, date_created
+	FROM
+		user_tokens
+	WHERE
+		token_hash = ?`
+
+	var dbTkn dbToken
+	if err := s.db.GetContext(ctx, &dbTkn, s.db.Rebind(q), hash); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return usertoken.Token{}, fmt.Errorf("getcontext: %w", usertoken.ErrNotFound)
+		}
+		return usertoken.Token{}, fmt.Errorf("getcontext: %w", err)
+	}
+
+	return toCoreToken(dbTkn), nil
+}
+
+// CountSince returns the number of tokens issued to the user for the purpose
+// since the specified time.
+func (s *Store) CountSince(ctx context.Context, userID uuid.UUID, purpose usertoken.Purpose, since time.Time) (int, error) {
+	const q = `
+	SELECT
+		COUNT(*)
+	FROM
+		user_tokens
+	WHERE
+		user_id = ? AND purpose = ? AND date_created >= ?`
+
+	var n int
+	if err := s.db.GetContext(ctx, &n, s.db.Rebind(q), userID, string(purpose), since.UTC()); err != nil {
+		return 0, fmt.Errorf("getcontext: %w", err)
+	}
+
+	return n, nil
+}
+
+// MarkUsed records the token as used. The update only applies to a token
+// that hasn't been used yet so two concurrent requests can't both consume
+// the same token.
+func (s *Store) MarkUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	const q = `
+	UPDATE
+		user_tokens
+	SET
+		"used_at" = ?
+	WHERE
+		token_id = ? AND used_at IS NULL`
+
+	res, err := s.db.ExecContext(ctx, s.db.Rebind(q), usedAt.UTC(), tokenID)
+	if err != nil {
+		return fmt.Errorf("execcontext: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rowsaffected: %w", err)
+	}
+
+	if n == 0 {
+		return fmt.Errorf("execcontext: %w", usertoken.ErrUsed)
+	}
+
+	return nil
+}
+
+// RevokeAll marks every outstanding token of the purpose for the user as used.
+func (s *Store) RevokeAll(ctx context.Context, userID uuid.UUID, purpose usertoken.Purpose, usedAt time.Time) error {
+	const q = `
+	UPDATE
+		user_tokens
+	SET
+		"used_at" = ?
+	WHERE
+		user_id = ? AND purpose = ? AND used_at IS NULL`
+
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind(q), usedAt.UTC(), userID, string(purpose)); err != nil {
+		return fmt.Errorf("execcontext: %w", err)
+	}
+
+	return nil
+}
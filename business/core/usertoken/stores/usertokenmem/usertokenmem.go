@@ -0,0 +1,56 @@
+// Package usertokenmem contains an in-memory implementation of the user
+// token store for unit tests.
+package usertokenmem
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/usertoken"
+)
+
+//go:generate go run github.com/mrcruz117/al-service/api/cmd/tooling/storegen -domain usertoken -unique Hash
+
+// CountSince returns how many tokens of the purpose were issued to the user
+// since the specified time.
+func (s *Store) CountSince(ctx context.Context, userID uuid.UUID, purpose usertoken.Purpose, since time.Time) (int, error) {
+	n := s.table.Count(func(tkn usertoken.Token) bool {
+		return tkn.UserID == userID && tkn.Purpose == purpose && !tkn.DateCreated.Before(since)
+	})
+
+	return n, nil
+}
+
+// MarkUsed records the token as used. Like the database store, a token that
+// was already used returns usertoken.ErrUsed.
+func (s *Store) MarkUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	match := func(tkn usertoken.Token) bool {
+		return tkn.ID == tokenID && tkn.UsedAt.IsZero()
+	}
+
+	if n := s.table.UpdateFunc(match, markUsed(usedAt)); n == 0 {
+		return fmt.Errorf("update: %w", usertoken.ErrUsed)
+	}
+
+	return nil
+}
+
+// RevokeAll marks every outstanding token of the purpose for the user as used.
+func (s *Store) RevokeAll(ctx context.Context, userID uuid.UUID, purpose usertoken.Purpose, usedAt time.Time) error {
+	match := func(tkn usertoken.Token) bool {
+		return tkn.UserID == userID && tkn.Purpose == purpose && tkn.UsedAt.IsZero()
+	}
+
+	s.table.UpdateFunc(match, markUsed(usedAt))
+
+	return nil
+}
+
+func markUsed(usedAt time.Time) func(tkn usertoken.Token) usertoken.Token {
+	return func(tkn usertoken.Token) usertoken.Token {
+		tkn.UsedAt = usedAt
+		return tkn
+	}
+}
@@ -0,0 +1,148 @@
+// Package usertoken provides support for the single use tokens that back
+// email verification and password resets.
+package usertoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/api/credential"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Set of error variables for token operations.
+var (
+	ErrNotFound    = errors.New("token not found")
+	ErrInvalid     = errors.New("token is invalid")
+	ErrExpired     = errors.New("token has expired")
+	ErrUsed        = errors.New("token has already been used")
+	ErrRateLimited = errors.New("too many tokens requested")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, tkn Token) error
+	QueryByHash(ctx context.Context, hash string) (Token, error)
+	CountSince(ctx context.Context, userID uuid.UUID, purpose Purpose, since time.Time) (int, error)
+	MarkUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error
+	RevokeAll(ctx context.Context, userID uuid.UUID, purpose Purpose, usedAt time.Time) error
+}
+
+// Config represents the lifetime of each purpose and the number of tokens a
+// single account can request within the window.
+type Config struct {
+	TTL        map[Purpose]time.Duration
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// Core manages the set of APIs for token access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+	cfg    Config
+}
+
+// NewCore constructs a core for token api access.
+func NewCore(log *logger.Logger, storer Storer, cfg Config) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+		cfg:    cfg,
+	}
+}
+
+// Issue creates a new token for the user and returns the plain text value
+// that is delivered to them. It can't be recovered afterwards.
+func (c *Core) Issue(ctx context.Context, userID uuid.UUID, purpose Purpose) (string, error) {
+	ttl, exists := c.cfg.TTL[purpose]
+	if !exists {
+		return "", fmt.Errorf("purpose[%s]: %w", purpose, ErrInvalid)
+	}
+
+	now := time.Now()
+
+	if c.cfg.RateLimit > 0 {
+		n, err := c.storer.CountSince(ctx, userID, purpose, now.Add(-c.cfg.RateWindow))
+		if err != nil {
+			return "", fmt.Errorf("count: %w", err)
+		}
+
+		if n >= c.cfg.RateLimit {
+			return "", ErrRateLimited
+		}
+	}
+
+	plain, err := credential.Generate(32)
+	if err != nil {
+		return "", fmt.Errorf("random: %w", err)
+	}
+
+	tkn := Token{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Purpose:     purpose,
+		Hash:        credential.Hash(plain),
+		ExpiresAt:   now.Add(ttl),
+		DateCreated: now,
+	}
+
+	if err := c.storer.Create(ctx, tkn); err != nil {
+		return "", fmt.Errorf("create: %w", err)
+	}
+
+	return plain, nil
+}
+
+// Consume validates the plain text token for the purpose and marks it used so
+// it can't be presented again. The token is returned so the caller knows
+// which user it was issued to.
+func (c *Core) Consume(ctx context.Context, purpose Purpose, plain string) (Token, error) {
+	if plain == "" {
+		return Token{}, ErrInvalid
+	}
+
+	tkn, err := c.storer.QueryByHash(ctx, credential.Hash(plain))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Token{}, ErrInvalid
+		}
+		return Token{}, fmt.Errorf("query: %w", err)
+	}
+
+	if tkn.Purpose != purpose {
+		return Token{}, ErrInvalid
+	}
+
+	if !tkn.UsedAt.IsZero() {
+		return Token{}, ErrUsed
+	}
+
+	now := time.Now()
+
+	if now.After(tkn.ExpiresAt) {
+		return Token{}, ErrExpired
+	}
+
+	if err := c.storer.MarkUsed(ctx, tkn.ID, now); err != nil {
+		return Token{}, fmt.Errorf("markused: %w", err)
+	}
+
+	tkn.UsedAt = now
+
+	return tkn, nil
+}
+
+// RevokeAll marks every outstanding token of the purpose for the user as
+// used, such as after their password has been reset.
+func (c *Core) RevokeAll(ctx context.Context, userID uuid.UUID, purpose Purpose) error {
+	if err := c.storer.RevokeAll(ctx, userID, purpose, time.Now()); err != nil {
+		return fmt.Errorf("revokeall: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package vproduct
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on. Fields
+// that are nil aren't filtered on.
+type QueryFilter struct {
+	ID       *uuid.UUID
+	UserID   *uuid.UUID
+	Name     *string
+	MinCost  *float64
+	MaxCost  *float64
+	UserName *string
+}
+
+// Validate checks the filter is considered clean before it is used.
+func (qf QueryFilter) Validate() error {
+	if qf.Name != nil && *qf.Name == "" {
+		return errors.New("name filter must not be empty")
+	}
+
+	if qf.UserName != nil && *qf.UserName == "" {
+		return errors.New("user name filter must not be empty")
+	}
+
+	if qf.MinCost != nil && qf.MaxCost != nil && *qf.MaxCost < *qf.MinCost {
+		return errors.New("max cost must not be less than min cost")
+	}
+
+	return nil
+}
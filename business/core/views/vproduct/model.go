@@ -0,0 +1,21 @@
+package vproduct
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product represents a product along with the details of the user who owns
+// it, as read from the products view.
+type Product struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Name        string
+	Cost        float64
+	Quantity    int
+	Version     int
+	DateCreated time.Time
+	DateUpdated time.Time
+	UserName    string
+}
@@ -0,0 +1,43 @@
+package vproductdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+)
+
+type dbProduct struct {
+	ID          uuid.UUID `db:"product_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Name        string    `db:"name"`
+	Cost        float64   `db:"cost"`
+	Quantity    int       `db:"quantity"`
+	Version     int       `db:"version"`
+	DateCreated time.Time `db:"date_created"`
+	DateUpdated time.Time `db:"date_updated"`
+	UserName    string    `db:"user_name"`
+}
+
+func toCoreProduct(dbPrd dbProduct) vproduct.Product {
+	return vproduct.Product{
+		ID:          dbPrd.ID,
+		UserID:      dbPrd.UserID,
+		Name:        dbPrd.Name,
+		Cost:        dbPrd.Cost,
+		Quantity:    dbPrd.Quantity,
+		Version:     dbPrd.Version,
+		DateCreated: dbPrd.DateCreated.In(time.Local),
+		DateUpdated: dbPrd.DateUpdated.In(time.Local),
+		UserName:    dbPrd.UserName,
+	}
+}
+
+func toCoreProductSlice(dbProducts []dbProduct) []vproduct.Product {
+	prds := make([]vproduct.Product, len(dbProducts))
+	for i, dbPrd := range dbProducts {
+		prds[i] = toCoreProduct(dbPrd)
+	}
+
+	return prds
+}
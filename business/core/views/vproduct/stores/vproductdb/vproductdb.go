@@ -0,0 +1,78 @@
+// Package vproductdb provides access to the products view in the database.
+package vproductdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/business/api/sqldb"
+	"github.com/mrcruz117/al-service/business/core/views/vproduct"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Store manages the set of APIs for view product database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Query retrieves a page of products from the view.
+func (s *Store) Query(ctx context.Context, filter vproduct.QueryFilter, orderBy order.By, pg page.Page) ([]vproduct.Product, error) {
+	data := map[string]any{
+		"offset":        pg.Offset(),
+		"rows_per_page": pg.RowsPerPage(),
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+	SELECT
+		product_id, user_id, name, cost, quantity, version, date_created, date_updated, user_name
+	FROM
+		view_products` + applyFilter(ctx, filter, data) + `
+	ORDER BY
+		` + orderByClause + `
+	LIMIT :rows_per_page OFFSET :offset`
+
+	var dbPrds []dbProduct
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreProductSlice(dbPrds), nil
+}
+
+// Count returns the total number of products in the view matching the
+// filter.
+func (s *Store) Count(ctx context.Context, filter vproduct.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	q := `
+	SELECT
+		count(1) AS "count"
+	FROM
+		view_products` + applyFilter(ctx, filter, data)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -0,0 +1,56 @@
+// Package vproduct provides read only access to products joined with the
+// users who own them. It is a view over the product and user data, read in
+// one query, for clients that would otherwise fetch the owner of every
+// product they list.
+package vproduct
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrcruz117/al-service/business/api/order"
+	"github.com/mrcruz117/al-service/business/api/page"
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Storer interface declares the behavior this package needs to retrieve
+// data.
+type Storer interface {
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]Product, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Core manages the set of APIs for view product access.
+type Core struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewCore constructs a core for view product api access.
+func NewCore(log *logger.Logger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Query retrieves a page of products matching the filter, in the order
+// asked for. Deleted products are left out.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pg page.Page) ([]Product, error) {
+	prds, err := c.storer.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return prds, nil
+}
+
+// Count returns the total number of products matching the filter.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	n, err := c.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,189 @@
+// Package cachestore provides a shared Redis client for the features that
+// need a fast shared store, such as rate limiting, caching decisions,
+// sessions and pub/sub. Every feature uses the same pool, metrics and
+// failover settings instead of constructing a client of its own.
+package cachestore
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound is returned when a key doesn't exist.
+var ErrNotFound = errors.New("key not found")
+
+// Config is the required properties to use the store. A single address
+// connects to a standalone server, multiple addresses to a cluster and a
+// master name to a set of sentinels for failover.
+type Config struct {
+	Addrs         []string
+	MasterName    string
+	Username      string
+	Password      string
+	DB            int
+	PoolSize      int
+	MinIdleConns  int
+	DialTimeout   time.Duration
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+	SlowThreshold time.Duration
+	EnableTLS     bool
+}
+
+// Store provides access to Redis.
+type Store struct {
+	client redis.UniversalClient
+}
+
+// Open constructs a store. Connections are established lazily, use
+// StatusCheck to confirm the server is reachable.
+func Open(log *logger.Logger, cfg Config) (*Store, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, errors.New("at least one address is required")
+	}
+
+	opts := redis.UniversalOptions{
+		Addrs:        cfg.Addrs,
+		MasterName:   cfg.MasterName,
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+
+	if cfg.EnableTLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	client := redis.NewUniversalClient(&opts)
+	client.AddHook(newHook(log, cfg.SlowThreshold))
+
+	return &Store{client: client}, nil
+}
+
+// Close releases the connections held by the pool.
+func (s *Store) Close() error {
+	return s.client.Close()
+}
+
+// Client returns the underlying client for the commands not covered by the
+// helpers in this package. Commands issued through it are still instrumented.
+func (s *Store) Client() redis.UniversalClient {
+	return s.client
+}
+
+// StatusCheck returns nil if it can successfully talk to the server. It
+// returns a non-nil error otherwise.
+func (s *Store) StatusCheck(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Second)
+		defer cancel()
+	}
+
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the value stored for the key.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := s.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	return b, nil
+}
+
+// Set stores the value for the key. A ttl of zero keeps the key until it's
+// deleted.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+
+	return nil
+}
+
+// Expire sets the time the key has left to live. It returns ErrNotFound
+// if the key doesn't exist.
+func (s *Store) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	ok, err := s.client.Expire(ctx, key, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("expire: %w", err)
+	}
+
+	if !ok {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes the keys.
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("del: %w", err)
+	}
+
+	return nil
+}
+
+// IncrWindow increments the counter for the key and returns its new value.
+// The counter expires once the window has passed since its first increment,
+// which gives fixed window rate limiting in a single round trip.
+func (s *Store) IncrWindow(ctx context.Context, key string, window time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+
+	err := s.Pipeline(ctx, func(p redis.Pipeliner) error {
+		incr = p.Incr(ctx, key)
+		p.ExpireNX(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// Pipeline sends every command queued by the function in a single round
+// trip. Results are read from the commands once Pipeline returns.
+func (s *Store) Pipeline(ctx context.Context, fn func(p redis.Pipeliner) error) error {
+	if _, err := s.client.Pipelined(ctx, fn); err != nil {
+		return fmt.Errorf("pipeline: %w", err)
+	}
+
+	return nil
+}
+
+// Publish sends the message to every subscriber of the channel.
+func (s *Store) Publish(ctx context.Context, channel string, message []byte) error {
+	if err := s.client.Publish(ctx, channel, message).Err(); err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe listens for messages published to the channels. The caller must
+// close the subscription when done.
+func (s *Store) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return s.client.Subscribe(ctx, channels...)
+}
@@ -0,0 +1,85 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// hook instruments every command with metrics published under the
+// "cachestore" expvar key and logs failed and slow commands with the trace
+// id of the request that issued them.
+type hook struct {
+	log  *logger.Logger
+	slow time.Duration
+	vars *expvar.Map
+}
+
+func newHook(log *logger.Logger, slow time.Duration) *hook {
+	vars, ok := expvar.Get("cachestore").(*expvar.Map)
+	if !ok {
+		vars = expvar.NewMap("cachestore")
+	}
+
+	return &hook{
+		log:  log,
+		slow: slow,
+		vars: vars,
+	}
+}
+
+func (h *hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.vars.Add("dial_errors", 1)
+			h.log.Error(ctx, "cachestore", "status", "dial failed", "addr", addr, "msg", err)
+		}
+
+		return conn, err
+	}
+}
+
+func (h *hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(ctx, cmd.Name(), 1, time.Since(start), err)
+
+		return err
+	}
+}
+
+func (h *hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+
+		h.vars.Add("pipelines", 1)
+		h.observe(ctx, "pipeline", len(cmds), time.Since(start), err)
+
+		return err
+	}
+}
+
+func (h *hook) observe(ctx context.Context, name string, cmds int, since time.Duration, err error) {
+	h.vars.Add("commands", int64(cmds))
+	h.vars.Add("latency_us", since.Microseconds())
+
+	// A missing key is an expected result, not a failure.
+	if err != nil && !errors.Is(err, redis.Nil) {
+		h.vars.Add("errors", 1)
+		h.log.Error(ctx, "cachestore", "status", "command failed", "cmd", name, "since", since.String(), "msg", err)
+		return
+	}
+
+	if h.slow > 0 && since > h.slow {
+		h.vars.Add("slow", 1)
+		h.log.Info(ctx, "cachestore", "status", "slow command", "cmd", name, "since", since.String())
+	}
+}
@@ -0,0 +1,142 @@
+// Package docker provides support for starting and stopping docker
+// containers for running tests, such as a database or a message broker the
+// code under test needs. It drives the docker cli, which must be installed.
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// ErrNotInstalled is returned when the docker cli can't be found, which
+// tests usually take as a reason to skip.
+var ErrNotInstalled = errors.New("docker is not installed")
+
+// Container tracks information about the docker container started for
+// tests. HostPort is the address the port of the container is published
+// on.
+type Container struct {
+	Name     string
+	Image    string
+	HostPort string
+}
+
+// Installed reports whether the docker cli can be found.
+func Installed() bool {
+	_, err := exec.LookPath("docker")
+	return err == nil
+}
+
+// StartContainer runs the image in the background under the name and
+// publishes the port of the container on a free port of the host. The
+// docker args go to docker run, such as environment variables, and the app
+// args to the entry point of the image.
+func StartContainer(image string, name string, port string, dockerArgs []string, appArgs []string) (Container, error) {
+	if !Installed() {
+		return Container{}, ErrNotInstalled
+	}
+
+	args := []string{"run", "-d", "--name", name, "-p", "127.0.0.1::" + port}
+	args = append(args, dockerArgs...)
+	args = append(args, image)
+	args = append(args, appArgs...)
+
+	if out, err := exec.Command("docker", args...).CombinedOutput(); err != nil {
+		return Container{}, fmt.Errorf("could not start container %s: %w: %s", image, err, bytes.TrimSpace(out))
+	}
+
+	c, err := InspectContainer(name, port)
+	if err != nil {
+		StopContainer(name)
+		return Container{}, err
+	}
+
+	return c, nil
+}
+
+// InspectContainer returns the container with the name and the address the
+// port is published on.
+func InspectContainer(name string, port string) (Container, error) {
+	out, err := exec.Command("docker", "inspect", name).Output()
+	if err != nil {
+		return Container{}, fmt.Errorf("could not inspect container %s: %w", name, err)
+	}
+
+	var info []struct {
+		Config struct {
+			Image string
+		}
+		NetworkSettings struct {
+			Ports map[string][]struct {
+				HostIP   string `json:"HostIp"`
+				HostPort string `json:"HostPort"`
+			}
+		}
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return Container{}, fmt.Errorf("could not decode container %s: %w", name, err)
+	}
+
+	if len(info) == 0 {
+		return Container{}, fmt.Errorf("container %s not found", name)
+	}
+
+	bindings := info[0].NetworkSettings.Ports[port+"/tcp"]
+	if len(bindings) == 0 {
+		return Container{}, fmt.Errorf("container %s doesn't publish port %s", name, port)
+	}
+
+	c := Container{
+		Name:     name,
+		Image:    info[0].Config.Image,
+		HostPort: bindings[0].HostIP + ":" + bindings[0].HostPort,
+	}
+
+	return c, nil
+}
+
+// WaitReady calls the check until it succeeds, which tells the service in
+// the container is up rather than only the container. When it doesn't
+// succeed before the context is done, the error holds the logs of the
+// container to tell why.
+func WaitReady(ctx context.Context, c Container, check func(ctx context.Context) error) error {
+	for wait := 100 * time.Millisecond; ; wait = min(2*wait, 2*time.Second) {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("container %s not ready: %w\n%s", c.Name, err, DumpContainerLogs(c.Name))
+
+		case <-timer.C:
+		}
+	}
+}
+
+// StopContainer stops and removes the container along with its volumes.
+func StopContainer(name string) error {
+	if out, err := exec.Command("docker", "rm", "-f", "-v", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("could not remove container %s: %w: %s", name, err, bytes.TrimSpace(out))
+	}
+
+	return nil
+}
+
+// DumpContainerLogs returns what the container wrote to stdout and stderr.
+func DumpContainerLogs(name string) []byte {
+	out, err := exec.Command("docker", "logs", name).CombinedOutput()
+	if err != nil {
+		return fmt.Appendf(nil, "could not read the logs of container %s: %s", name, err)
+	}
+
+	return out
+}
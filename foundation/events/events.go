@@ -0,0 +1,128 @@
+// Package events provides support for publishing events to a message broker
+// for other services to consume. An event travels as a JSON envelope that
+// carries the version of the schema of its data, so a consumer can tell the
+// shapes an event type had over time apart.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event represents something that happened, in the envelope it's published
+// in. Type names what happened, such as user.created, and Version is the
+// version of the schema of Data. Key groups the events that must be
+// consumed in order, such as the ones about the same user.
+type Event struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Source  string          `json:"source"`
+	Key     string          `json:"key,omitempty"`
+	Time    time.Time       `json:"time"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// New constructs an event with a new id, the data is encoded as JSON.
+func New(source string, typ string, version int, key string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal: %w", err)
+	}
+
+	evt := Event{
+		ID:      uuid.NewString(),
+		Type:    typ,
+		Version: version,
+		Source:  source,
+		Key:     key,
+		Time:    time.Now().UTC(),
+		Data:    raw,
+	}
+
+	return evt, nil
+}
+
+// Publisher declares the behavior of a message broker events are published
+// to. Publish returns once the broker has the event, or fails.
+type Publisher interface {
+	Publish(ctx context.Context, evt Event) error
+	Close() error
+}
+
+// =============================================================================
+
+// Discard is a publisher that drops every event, for when no broker is
+// configured.
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(ctx context.Context, evt Event) error { return nil }
+func (discard) Close() error                                 { return nil }
+
+// Logger represents a function that will be called to add information
+// to the logs.
+type Logger func(ctx context.Context, msg string, v ...any)
+
+// Log is a publisher that logs every event instead of sending it anywhere,
+// to see what a service publishes while developing it.
+func Log(log Logger) Publisher {
+	return logPublisher{log: log}
+}
+
+type logPublisher struct {
+	log Logger
+}
+
+func (p logPublisher) Publish(ctx context.Context, evt Event) error {
+	p.log(ctx, "event published", "id", evt.ID, "type", evt.Type, "version", evt.Version, "key", evt.Key, "data", string(evt.Data))
+	return nil
+}
+
+func (p logPublisher) Close() error { return nil }
+
+// =============================================================================
+
+// Metered wraps the publisher to count the events published and the ones
+// that failed by type, under the "events" expvar key.
+func Metered(p Publisher) Publisher {
+	vars, ok := expvar.Get("events").(*expvar.Map)
+	if !ok {
+		vars = expvar.NewMap("events")
+		vars.Set("published", new(expvar.Map))
+		vars.Set("failed", new(expvar.Map))
+	}
+
+	return metered{
+		next:      p,
+		published: vars.Get("published").(*expvar.Map),
+		failed:    vars.Get("failed").(*expvar.Map),
+	}
+}
+
+type metered struct {
+	next      Publisher
+	published *expvar.Map
+	failed    *expvar.Map
+}
+
+func (m metered) Publish(ctx context.Context, evt Event) error {
+	if err := m.next.Publish(ctx, evt); err != nil {
+		m.failed.Add(evt.Type, 1)
+		return err
+	}
+
+	m.published.Add(evt.Type, 1)
+
+	return nil
+}
+
+func (m metered) Close() error {
+	return m.next.Close()
+}
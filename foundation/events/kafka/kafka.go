@@ -0,0 +1,149 @@
+// Package kafka provides an events publisher for Kafka through a REST proxy
+// that speaks the v2 API of the Confluent REST Proxy. Publishing over HTTP
+// keeps the service free of a Kafka client, and the proxy answers once the
+// brokers have the records.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/events"
+)
+
+// contentType is the media type of records with JSON keys and values.
+const contentType = "application/vnd.kafka.json.v2+json"
+
+// Config represents the settings of the REST proxy. The topic of an event is
+// its type after the prefix, and the key of the record is the key of the
+// event so the events about the same thing land in the same partition.
+type Config struct {
+	URL         string
+	User        string
+	Password    string
+	TopicPrefix string
+	Timeout     time.Duration
+	Client      *http.Client
+}
+
+// Publisher publishes events to Kafka through the REST proxy. It is safe for
+// concurrent use.
+type Publisher struct {
+	cfg    Config
+	client *http.Client
+}
+
+// New constructs a publisher for the REST proxy at the URL.
+func New(cfg Config) (*Publisher, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing url: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("url scheme %q isn't http or https", u.Scheme)
+	}
+
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	p := Publisher{
+		cfg:    cfg,
+		client: client,
+	}
+
+	return &p, nil
+}
+
+// Publish produces the event to the topic of its type.
+func (p *Publisher) Publish(ctx context.Context, evt events.Event) error {
+	topic := p.cfg.TopicPrefix + evt.Type
+
+	type record struct {
+		Key   string       `json:"key,omitempty"`
+		Value events.Event `json:"value"`
+	}
+
+	body, err := json.Marshal(struct {
+		Records []record `json:"records"`
+	}{
+		Records: []record{{Key: evt.Key, Value: evt}},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("publish: topic[%s]: %w", topic, err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json, application/json")
+
+	if p.cfg.User != "" {
+		req.SetBasicAuth(p.cfg.User, p.cfg.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("publish: topic[%s]: %w", topic, err)
+	}
+	defer resp.Body.Close()
+
+	// The proxy answers an error with a code and a message.
+	if resp.StatusCode != http.StatusOK {
+		var perr struct {
+			Code    int    `json:"error_code"`
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &perr) == nil && perr.Message != "" {
+			return fmt.Errorf("publish: topic[%s]: status %d: %d: %s", topic, resp.StatusCode, perr.Code, perr.Message)
+		}
+		return fmt.Errorf("publish: topic[%s]: status %d", topic, resp.StatusCode)
+	}
+
+	// A record the brokers refused is reported in its offset with a 200.
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("publish: topic[%s]: decoding response: %w", topic, err)
+	}
+
+	for _, off := range result.Offsets {
+		if off.ErrorCode != nil || off.Error != "" {
+			return fmt.Errorf("publish: topic[%s]: record refused: %s", topic, off.Error)
+		}
+	}
+
+	return nil
+}
+
+// Close releases the idle connections to the proxy.
+func (p *Publisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,304 @@
+// Package nats provides an events publisher for a NATS server. It speaks
+// the text protocol of the client directly, since publishing needs only a
+// small part of it, and waits on a PING after each message so Publish
+// returns once the server has the event.
+package nats
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/events"
+)
+
+// Config represents the settings of the connection to the server. The URL
+// is of the form nats://host:4222, and tls://host:4222 to require TLS. The
+// subject of an event is its type after the prefix.
+type Config struct {
+	URL           string
+	Name          string
+	User          string
+	Password      string
+	Token         string
+	SubjectPrefix string
+	Timeout       time.Duration
+}
+
+// Publisher publishes events to a NATS server. It connects when it
+// publishes the first event and again after the connection fails. It is
+// safe for concurrent use.
+type Publisher struct {
+	cfg  Config
+	host string
+	tls  bool
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// New constructs a publisher for the server. It doesn't connect until an
+// event is published, so a service can start while the server is down.
+func New(cfg Config) (*Publisher, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing url: %w", err)
+	}
+
+	var requireTLS bool
+	switch u.Scheme {
+	case "nats":
+	case "tls":
+		requireTLS = true
+	default:
+		return nil, fmt.Errorf("url scheme %q isn't nats or tls", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	if cfg.User == "" && u.User != nil {
+		cfg.User = u.User.Username()
+		cfg.Password, _ = u.User.Password()
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	p := Publisher{
+		cfg:  cfg,
+		host: host,
+		tls:  requireTLS,
+	}
+
+	return &p, nil
+}
+
+// Publish sends the event to the subject of its type and waits for the
+// server to acknowledge it. A connection the server dropped while it was
+// idle is replaced and the event sent again once.
+func (p *Publisher) Publish(ctx context.Context, evt events.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	subject := p.cfg.SubjectPrefix + evt.Type
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reused := p.conn != nil
+
+	err = p.publish(ctx, subject, data)
+	if err != nil && reused && ctx.Err() == nil {
+		err = p.publish(ctx, subject, data)
+	}
+
+	if err != nil {
+		return fmt.Errorf("publish: subject[%s]: %w", subject, err)
+	}
+
+	return nil
+}
+
+// Close closes the connection to the server.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+
+	return err
+}
+
+// =============================================================================
+
+// publish sends the message over the connection, connecting first if there
+// is none. The connection is dropped on any error so the next publish
+// starts on a new one. The caller must hold the mutex.
+func (p *Publisher) publish(ctx context.Context, subject string, data []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	p.setDeadline(ctx)
+
+	msg := make([]byte, 0, len(subject)+len(data)+32)
+	msg = fmt.Appendf(msg, "PUB %s %d\r\n", subject, len(data))
+	msg = append(msg, data...)
+	msg = append(msg, "\r\nPING\r\n"...)
+
+	if _, err := p.conn.Write(msg); err != nil {
+		p.drop()
+		return err
+	}
+
+	if err := p.waitPong(); err != nil {
+		p.drop()
+		return err
+	}
+
+	return nil
+}
+
+// connect dials the server and goes through the handshake, which completes
+// when the server answers the PING sent after CONNECT.
+func (p *Publisher) connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.host)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	r := bufio.NewReader(conn)
+
+	line, err := readLine(r)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("reading info: %w", err)
+	}
+
+	op, args, _ := strings.Cut(line, " ")
+	if !strings.EqualFold(op, "INFO") {
+		conn.Close()
+		return fmt.Errorf("expected INFO, got %q", line)
+	}
+
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	if err := json.Unmarshal([]byte(args), &info); err != nil {
+		conn.Close()
+		return fmt.Errorf("decoding info: %w", err)
+	}
+
+	if p.tls || info.TLSRequired {
+		host, _, _ := net.SplitHostPort(p.host)
+		tc := tls.Client(conn, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return fmt.Errorf("tls handshake: %w", err)
+		}
+
+		conn = tc
+		r = bufio.NewReader(conn)
+	}
+
+	opts := struct {
+		Verbose  bool   `json:"verbose"`
+		Pedantic bool   `json:"pedantic"`
+		Name     string `json:"name,omitempty"`
+		Lang     string `json:"lang"`
+		Version  string `json:"version"`
+		Protocol int    `json:"protocol"`
+		User     string `json:"user,omitempty"`
+		Pass     string `json:"pass,omitempty"`
+		Token    string `json:"auth_token,omitempty"`
+	}{
+		Name:     p.cfg.Name,
+		Lang:     "go",
+		Version:  "1.0.0",
+		Protocol: 1,
+		User:     p.cfg.User,
+		Pass:     p.cfg.Password,
+		Token:    p.cfg.Token,
+	}
+
+	connect, _ := json.Marshal(opts)
+
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		conn.Close()
+		return fmt.Errorf("connect: %w", err)
+	}
+
+	p.conn = conn
+	p.r = r
+
+	if err := p.waitPong(); err != nil {
+		p.drop()
+		return fmt.Errorf("connect: %w", err)
+	}
+
+	return nil
+}
+
+// waitPong reads until the server answers the PING. A PING of the server
+// is answered on the way and a -ERR fails the wait.
+func (p *Publisher) waitPong() error {
+	for {
+		line, err := readLine(p.r)
+		if err != nil {
+			return err
+		}
+
+		op, args, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(op) {
+		case "PONG":
+			return nil
+
+		case "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+
+		case "-ERR":
+			return fmt.Errorf("server: %s", strings.Trim(args, "' "))
+
+		case "+OK", "INFO":
+
+		default:
+			return fmt.Errorf("unexpected %q from server", line)
+		}
+	}
+}
+
+func (p *Publisher) setDeadline(ctx context.Context) {
+	deadline := time.Now().Add(p.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	p.conn.SetDeadline(deadline)
+}
+
+func (p *Publisher) drop() {
+	p.conn.Close()
+	p.conn = nil
+	p.r = nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
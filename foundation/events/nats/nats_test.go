@@ -0,0 +1,114 @@
+package nats_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mrcruz117/al-service/foundation/events"
+	"github.com/mrcruz117/al-service/foundation/events/nats"
+)
+
+func Test_Publish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Should be able to listen : %s", err)
+	}
+	defer ln.Close()
+
+	msgs := make(chan string, 10)
+	go serve(ln, msgs)
+
+	pub, err := nats.New(nats.Config{URL: "nats://" + ln.Addr().String(), SubjectPrefix: "sales."})
+	if err != nil {
+		t.Fatalf("Should be able to construct the publisher : %s", err)
+	}
+	defer pub.Close()
+
+	ctx := context.Background()
+
+	evt, err := events.New("test", "user.created", 1, "u1", map[string]string{"userID": "u1"})
+	if err != nil {
+		t.Fatalf("Should be able to construct the event : %s", err)
+	}
+
+	for i := range 2 {
+		if err := pub.Publish(ctx, evt); err != nil {
+			t.Fatalf("Should be able to publish event %d : %s", i, err)
+		}
+
+		subject, data, _ := strings.Cut(<-msgs, " ")
+		if subject != "sales.user.created" {
+			t.Errorf("Should publish to the subject of the type, got %q", subject)
+		}
+
+		var got events.Event
+		if err := json.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("Should be able to decode the event : %s", err)
+		}
+		if got.ID != evt.ID || got.Version != 1 || got.Key != "u1" {
+			t.Errorf("Should get the event back, got %+v", got)
+		}
+	}
+
+	// The server refuses the subject.
+	evt.Type = "denied"
+	if err := pub.Publish(ctx, evt); err == nil {
+		t.Error("Should fail when the server refuses the message")
+	}
+}
+
+// serve is enough of a server to accept a client, the messages published
+// are sent to the channel as the subject and the payload. A message to the
+// denied subject is answered with a -ERR.
+func serve(ln net.Listener, msgs chan<- string) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			r := bufio.NewReader(conn)
+			fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+				switch op {
+				case "PING":
+					fmt.Fprint(conn, "PONG\r\n")
+
+				case "PUB":
+					subject, size, _ := strings.Cut(args, " ")
+					n, _ := strconv.Atoi(size)
+
+					data := make([]byte, n+2)
+					if _, err := io.ReadFull(r, data); err != nil {
+						return
+					}
+
+					if strings.HasSuffix(subject, "denied") {
+						fmt.Fprintf(conn, "-ERR 'Permissions Violation for Publish to %s'\r\n", subject)
+						return
+					}
+
+					msgs <- subject + " " + string(data[:n])
+				}
+			}
+		}()
+	}
+}
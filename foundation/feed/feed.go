@@ -0,0 +1,143 @@
+// Package feed provides an in memory pipeline of recent events that clients
+// can follow with a cursor, either by streaming or by polling. Each event is
+// given a sequence number and only the most recent events are retained.
+package feed
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event represents something that happened in the system that clients may
+// want to react to. An event without a tenant is visible to every tenant.
+type Event struct {
+	Seq      uint64
+	Type     string
+	TenantID string
+	Data     any
+	Time     time.Time
+}
+
+// Page represents a set of events read from the feed.
+type Page struct {
+	Events []Event
+
+	// Cursor is the sequence number to resume reading from.
+	Cursor uint64
+
+	// Missed reports whether events after the requested cursor were
+	// dropped from the feed before they could be read.
+	Missed bool
+}
+
+// Feed retains the most recent events published to it.
+type Feed struct {
+	mu     sync.Mutex
+	events []Event
+	size   int
+	seq    uint64
+	notify chan struct{}
+}
+
+// New constructs a feed that retains at most size events.
+func New(size int) *Feed {
+	return &Feed{
+		events: make([]Event, 0, size),
+		size:   size,
+		notify: make(chan struct{}),
+	}
+}
+
+// Publish adds an event to the feed and wakes every reader waiting on it.
+func (f *Feed) Publish(typ string, tenantID string, data any) Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seq++
+
+	evt := Event{
+		Seq:      f.seq,
+		Type:     typ,
+		TenantID: tenantID,
+		Data:     data,
+		Time:     time.Now().UTC(),
+	}
+
+	if len(f.events) == f.size {
+		copy(f.events, f.events[1:])
+		f.events = f.events[:f.size-1]
+	}
+	f.events = append(f.events, evt)
+
+	close(f.notify)
+	f.notify = make(chan struct{})
+
+	return evt
+}
+
+// Head returns the sequence number of the last event published.
+func (f *Feed) Head() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.seq
+}
+
+// Read returns up to limit events after the cursor that match the filter.
+// A nil filter matches every event.
+func (f *Feed) Read(cursor uint64, limit int, match func(Event) bool) Page {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	page := Page{
+		Cursor: cursor,
+	}
+
+	if cursor > f.seq {
+		page.Cursor = f.seq
+		return page
+	}
+
+	if len(f.events) > 0 && f.events[0].Seq > cursor+1 {
+		page.Missed = true
+	}
+
+	for _, evt := range f.events {
+		if evt.Seq <= cursor {
+			continue
+		}
+
+		if len(page.Events) == limit {
+			break
+		}
+
+		page.Cursor = evt.Seq
+
+		if match == nil || match(evt) {
+			page.Events = append(page.Events, evt)
+		}
+	}
+
+	return page
+}
+
+// Wait blocks until an event after the cursor is published or the context
+// is done.
+func (f *Feed) Wait(ctx context.Context, cursor uint64) error {
+	f.mu.Lock()
+	seq := f.seq
+	notify := f.notify
+	f.mu.Unlock()
+
+	if seq > cursor {
+		return nil
+	}
+
+	select {
+	case <-notify:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
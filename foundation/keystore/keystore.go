@@ -1,9 +1,13 @@
 // Package keystore implements the auth.KeyLookup interface. This implements
-// an in-memory keystore for JWT support.
+// an in-memory keystore for JWT support, and a remote one for keys held by a
+// key service such as Vault or a KMS.
 package keystore
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -54,11 +58,24 @@ func (ks *KeyStore) PublicKey(kid string) (string, error) {
 	return key.publicPEM, nil
 }
 
-// LoadRSAKeys loads a set of RSA PEM files rooted inside of a directory. The
-// name of each PEM file will be used as the key id.
-// Example: ks.LoadRSAKeys(os.DirFS("/zarf/keys/"))
+// PublicKeys returns the public key of every key in the store by kid, such
+// as to publish them for other services to verify tokens with.
+func (ks *KeyStore) PublicKeys() map[string]string {
+	keys := make(map[string]string, len(ks.store))
+	for kid, key := range ks.store {
+		keys[kid] = key.publicPEM
+	}
+
+	return keys
+}
+
+// LoadKeys loads a set of RSA, ECDSA P-256 or Ed25519 private key PEM files
+// rooted inside of a directory. The name of each PEM file will be used as
+// the key id, and the type of the key decides the algorithm the tokens it
+// signs use.
+// Example: ks.LoadKeys(os.DirFS("/zarf/keys/"))
 // Example: /zarf/keys/54bb2165-71e1-41a6-af3e-7da4a0e1e2c1.pem
-func (ks *KeyStore) LoadRSAKeys(fsys fs.FS) error {
+func (ks *KeyStore) LoadKeys(fsys fs.FS) error {
 	fn := func(fileName string, dirEntry fs.DirEntry, err error) error {
 		if err != nil {
 			return fmt.Errorf("walkdir failure: %w", err)
@@ -120,16 +137,29 @@ func toPublicPEM(privatePEM string) (string, error) {
 	if err != nil {
 		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
-			return "", err
+			parsedKey, err = x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return "", errors.New("invalid key: Key must be a PKCS1, PKCS8 or SEC 1 key")
+			}
 		}
 	}
 
-	pk, ok := parsedKey.(*rsa.PrivateKey)
-	if !ok {
-		return "", errors.New("key is not a valid RSA private key")
+	var publicKey any
+	switch pk := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		publicKey = &pk.PublicKey
+	case *ecdsa.PrivateKey:
+		if pk.Curve != elliptic.P256() {
+			return "", errors.New("key is not a P-256 ECDSA private key")
+		}
+		publicKey = &pk.PublicKey
+	case ed25519.PrivateKey:
+		publicKey = pk.Public()
+	default:
+		return "", errors.New("key is not a RSA, ECDSA or Ed25519 private key")
 	}
 
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&pk.PublicKey)
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(publicKey)
 	if err != nil {
 		return "", fmt.Errorf("marshaling public key: %w", err)
 	}
@@ -0,0 +1,311 @@
+// Package kms implements the keystore.Backend interface with AWS KMS, which
+// signs with asymmetric keys that can't be exported. The requests are made
+// directly against the KMS JSON API and signed with AWS Signature Version 4.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Config is the required properties to use KMS. Credentials that aren't set
+// are read from the standard AWS_ environment variables. The endpoint
+// defaults to the regional one.
+type Config struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Endpoint        string
+	Client          *http.Client
+}
+
+// KMS provides access to the asymmetric keys of an account. The key names
+// are key ids, key ARNs or aliases such as alias/jwt-signing. The keys must
+// be RSA or ECC_NIST_P256 keys with the SIGN_VERIFY usage, KMS doesn't sign
+// with Ed25519.
+type KMS struct {
+	region       string
+	accessKeyID  string
+	secretKey    string
+	sessionToken string
+	endpoint     string
+	http         *http.Client
+}
+
+// New constructs a KMS client.
+func New(cfg Config) (*KMS, error) {
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	if cfg.Region == "" {
+		return nil, errors.New("region is required")
+	}
+
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("credentials are required")
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", cfg.Region)
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	k := KMS{
+		region:       cfg.Region,
+		accessKeyID:  cfg.AccessKeyID,
+		secretKey:    cfg.SecretAccessKey,
+		sessionToken: cfg.SessionToken,
+		endpoint:     strings.TrimSuffix(cfg.Endpoint, "/"),
+		http:         cfg.Client,
+	}
+
+	return &k, nil
+}
+
+// PublicKey implements the keystore.Backend interface.
+func (k *KMS) PublicKey(ctx context.Context, name string) (string, error) {
+	req := struct {
+		KeyId string
+	}{
+		KeyId: name,
+	}
+
+	var resp struct {
+		PublicKey string
+		KeySpec   string
+	}
+
+	if err := k.do(ctx, "GetPublicKey", req, &resp); err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(resp.KeySpec, "RSA_") && resp.KeySpec != "ECC_NIST_P256" {
+		return "", fmt.Errorf("key %s is a %s key, it must be an rsa or ecc_nist_p256 key", name, resp.KeySpec)
+	}
+
+	// The key is returned as a base64 encoded DER SubjectPublicKeyInfo, the
+	// same bytes a PUBLIC KEY PEM block holds.
+	der, err := base64.StdEncoding.DecodeString(resp.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("decoding public key: %w", err)
+	}
+
+	block := pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: der,
+	}
+
+	return string(pem.EncodeToMemory(&block)), nil
+}
+
+// Sign implements the keystore.Backend interface.
+func (k *KMS) Sign(ctx context.Context, name string, alg string, message []byte) ([]byte, error) {
+	var algorithm string
+	switch alg {
+	case "RS256":
+		algorithm = "RSASSA_PKCS1_V1_5_SHA_256"
+	case "ES256":
+		algorithm = "ECDSA_SHA_256"
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	req := struct {
+		KeyId            string
+		Message          string
+		MessageType      string
+		SigningAlgorithm string
+	}{
+		KeyId:            name,
+		Message:          base64.StdEncoding.EncodeToString(message),
+		MessageType:      "RAW",
+		SigningAlgorithm: algorithm,
+	}
+
+	var resp struct {
+		Signature string
+	}
+
+	if err := k.do(ctx, "Sign", req, &resp); err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	if alg == "ES256" {
+		return jwsECDSA(sig)
+	}
+
+	return sig, nil
+}
+
+// jwsECDSA converts the DER encoded ECDSA signature KMS returns to the
+// fixed size r and s a JWS carries.
+func jwsECDSA(der []byte) ([]byte, error) {
+	var sig struct {
+		R *big.Int
+		S *big.Int
+	}
+
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("decoding ecdsa signature: %w", err)
+	}
+
+	const size = 32
+
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+
+	return raw, nil
+}
+
+func (k *KMS) do(ctx context.Context, action string, in any, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+
+	k.sign(req, body, "kms", time.Now().UTC())
+
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(data, &e); err == nil && e.Type != "" {
+			return fmt.Errorf("kms: %s: status %d: %s: %s", action, resp.StatusCode, e.Type, e.Message)
+		}
+		return fmt.Errorf("kms: %s: status %d", action, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// sign adds the Signature Version 4 authorization for the service to the
+// request. Every header set on the request is signed.
+func (k *KMS) sign(req *http.Request, body []byte, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if k.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", k.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := date + "/" + k.region + "/" + service + "/aws4_request"
+
+	toSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonical)),
+	}, "\n")
+
+	key := signingKey(k.secretKey, date, k.region, service)
+	signature := hex.EncodeToString(hmacSHA256(key, []byte(toSign)))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", k.accessKeyID, scope, signedHeaders, signature))
+}
+
+func signingKey(secret string, date string, region string, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(service))
+	return hmacSHA256(key, []byte("aws4_request"))
+}
+
+func hmacSHA256(key []byte, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,101 @@
+package kms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Test_Sign signs the get-vanilla request of the AWS Signature Version 4
+// test suite and checks the authorization is the one published with it.
+func Test_Sign(t *testing.T) {
+	k := KMS{
+		region:      "us-east-1",
+		accessKeyID: "AKIDEXAMPLE",
+		secretKey:   "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("Should be able to create the request : %s", err)
+	}
+
+	k.sign(req, nil, "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	const want = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Should sign the request as published\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// The signing key derived in the example of the AWS documentation.
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	if got := hex.EncodeToString(key); got != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Errorf("Should derive the published signing key, got %s", got)
+	}
+}
+
+// Test_JWSECDSA converts DER encoded signatures to the fixed size form a
+// JWS carries and checks they still verify, and that a short r or s is
+// padded.
+func Test_JWSECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key : %s", err)
+	}
+
+	hash := sha256.Sum256([]byte("header.payload"))
+
+	for i := range 100 {
+		der, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+		if err != nil {
+			t.Fatalf("Should be able to sign : %s", err)
+		}
+
+		raw, err := jwsECDSA(der)
+		if err != nil {
+			t.Fatalf("Should be able to convert signature %d : %s", i, err)
+		}
+
+		if len(raw) != 64 {
+			t.Fatalf("Should convert to 64 bytes, got %d", len(raw))
+		}
+
+		r := new(big.Int).SetBytes(raw[:32])
+		s := new(big.Int).SetBytes(raw[32:])
+
+		if !ecdsa.Verify(&key.PublicKey, hash[:], r, s) {
+			t.Fatalf("Should verify converted signature %d", i)
+		}
+	}
+
+	// An r and s of a single byte are padded to their full size.
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{big.NewInt(1), big.NewInt(2)})
+	if err != nil {
+		t.Fatalf("Should be able to encode a signature : %s", err)
+	}
+
+	raw, err := jwsECDSA(der)
+	if err != nil {
+		t.Fatalf("Should be able to convert a short signature : %s", err)
+	}
+
+	want := make([]byte, 64)
+	want[31], want[63] = 1, 2
+
+	if !bytes.Equal(raw, want) {
+		t.Errorf("Should pad r and s to 32 bytes each, got %x", raw)
+	}
+
+	if _, err := jwsECDSA([]byte("not der")); err == nil {
+		t.Errorf("Should fail to convert a signature that isn't DER")
+	}
+}
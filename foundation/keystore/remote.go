@@ -0,0 +1,170 @@
+package keystore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrPrivateKeyUnavailable is returned by a Remote store when asked for a
+// private key, which never leaves the key service.
+var ErrPrivateKeyUnavailable = errors.New("private key is held by the key service")
+
+// Backend declares the behavior of a key service that holds the private keys
+// and signs with them, such as Vault transit or AWS KMS. The alg is the JWS
+// algorithm, RS256, ES256 or EdDSA, and the signature is returned in the
+// JWS form for it.
+type Backend interface {
+	PublicKey(ctx context.Context, name string) (publicPEM string, err error)
+	Sign(ctx context.Context, name string, alg string, message []byte) (signature []byte, err error)
+}
+
+// RemoteConfig is the required properties to use a Remote store. Keys maps
+// the kid used in tokens to the name of the key in the backend.
+type RemoteConfig struct {
+	Backend Backend
+	Keys    map[string]string
+	TTL     time.Duration
+	Timeout time.Duration
+}
+
+type cachedKey struct {
+	publicPEM string
+	fetched   time.Time
+}
+
+// Remote implements the KeyLookup interface, and the signing one of the
+// auth package, on top of a key service. Public keys are cached for the
+// ttl, and the last key fetched keeps being used while the service can't be
+// reached.
+type Remote struct {
+	backend Backend
+	keys    map[string]string
+	ttl     time.Duration
+	timeout time.Duration
+	mu      sync.RWMutex
+	cache   map[string]cachedKey
+}
+
+// NewRemote constructs a store backed by the key service.
+func NewRemote(cfg RemoteConfig) (*Remote, error) {
+	if cfg.Backend == nil {
+		return nil, errors.New("backend is required")
+	}
+
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	r := Remote{
+		backend: cfg.Backend,
+		keys:    cfg.Keys,
+		ttl:     cfg.TTL,
+		timeout: cfg.Timeout,
+		cache:   make(map[string]cachedKey, len(cfg.Keys)),
+	}
+
+	return &r, nil
+}
+
+// PrivateKey implements the KeyLookup interface. The private keys can't be
+// read, tokens are signed with Sign instead.
+func (r *Remote) PrivateKey(kid string) (string, error) {
+	return "", ErrPrivateKeyUnavailable
+}
+
+// PublicKey implements the KeyLookup interface.
+func (r *Remote) PublicKey(kid string) (string, error) {
+	name, exists := r.keys[kid]
+	if !exists {
+		return "", errors.New("kid lookup failed")
+	}
+
+	r.mu.RLock()
+	key, cached := r.cache[kid]
+	r.mu.RUnlock()
+
+	if cached && time.Since(key.fetched) < r.ttl {
+		return key.publicPEM, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	publicPEM, err := r.fetch(ctx, kid, name)
+	if err != nil {
+		if cached {
+			return key.publicPEM, nil
+		}
+		return "", err
+	}
+
+	return publicPEM, nil
+}
+
+// PublicKeys returns the public key of every configured key that could be
+// fetched, so they can be published for other services.
+func (r *Remote) PublicKeys() map[string]string {
+	keys := make(map[string]string, len(r.keys))
+	for kid := range r.keys {
+		if pem, err := r.PublicKey(kid); err == nil {
+			keys[kid] = pem
+		}
+	}
+
+	return keys
+}
+
+// Sign has the key service sign the message with the key of the kid.
+func (r *Remote) Sign(kid string, alg string, message []byte) ([]byte, error) {
+	name, exists := r.keys[kid]
+	if !exists {
+		return nil, errors.New("kid lookup failed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	sig, err := r.backend.Sign(ctx, name, alg, message)
+	if err != nil {
+		return nil, fmt.Errorf("key[%s]: %w", kid, err)
+	}
+
+	return sig, nil
+}
+
+// StatusCheck returns nil if the public key of every configured key can be
+// fetched from the key service, which confirms it is reachable and the
+// credentials are valid. The cache is refreshed as a result.
+func (r *Remote) StatusCheck(ctx context.Context) error {
+	for kid, name := range r.keys {
+		if _, err := r.fetch(ctx, kid, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Remote) fetch(ctx context.Context, kid string, name string) (string, error) {
+	publicPEM, err := r.backend.PublicKey(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("key[%s]: %w", kid, err)
+	}
+
+	r.mu.Lock()
+	r.cache[kid] = cachedKey{publicPEM: publicPEM, fetched: time.Now()}
+	r.mu.Unlock()
+
+	return publicPEM, nil
+}
@@ -0,0 +1,224 @@
+// Package vault implements the keystore.Backend interface with the transit
+// secrets engine of HashiCorp Vault, which signs with keys that can't be
+// exported.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the required properties to use Vault. The token needs the
+// read capability on the keys and the update capability on sign for every
+// key used.
+type Config struct {
+	Addr      string
+	Token     string
+	Mount     string
+	Namespace string
+	Client    *http.Client
+}
+
+// Transit provides access to the keys of a transit mount.
+type Transit struct {
+	addr      string
+	token     string
+	mount     string
+	namespace string
+	http      *http.Client
+}
+
+// New constructs a transit client. The mount defaults to transit.
+func New(cfg Config) (*Transit, error) {
+	if cfg.Addr == "" {
+		return nil, errors.New("address is required")
+	}
+
+	if cfg.Token == "" {
+		return nil, errors.New("token is required")
+	}
+
+	if cfg.Mount == "" {
+		cfg.Mount = "transit"
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	t := Transit{
+		addr:      strings.TrimSuffix(cfg.Addr, "/"),
+		token:     cfg.Token,
+		mount:     strings.Trim(cfg.Mount, "/"),
+		namespace: cfg.Namespace,
+		http:      cfg.Client,
+	}
+
+	return &t, nil
+}
+
+// PublicKey implements the keystore.Backend interface. It returns the
+// latest version of the key, the one Sign uses, so a key should be rotated
+// by adding a new kid rather than in place.
+func (t *Transit) PublicKey(ctx context.Context, name string) (string, error) {
+	var resp struct {
+		Data struct {
+			LatestVersion int `json:"latest_version"`
+			Keys          map[string]struct {
+				PublicKey string `json:"public_key"`
+			} `json:"keys"`
+		} `json:"data"`
+	}
+
+	if err := t.do(ctx, http.MethodGet, "keys/"+url.PathEscape(name), nil, &resp); err != nil {
+		return "", err
+	}
+
+	key, exists := resp.Data.Keys[strconv.Itoa(resp.Data.LatestVersion)]
+	if !exists || key.PublicKey == "" {
+		return "", fmt.Errorf("key %s has no public key, it must be an asymmetric signing key", name)
+	}
+
+	if strings.HasPrefix(key.PublicKey, "-----BEGIN") {
+		return key.PublicKey, nil
+	}
+
+	// Ed25519 keys are returned as the base64 encoded key rather than PEM.
+	raw, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("key %s has an unexpected public key format", name)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(ed25519.PublicKey(raw))
+	if err != nil {
+		return "", fmt.Errorf("marshaling public key: %w", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// Sign implements the keystore.Backend interface.
+func (t *Transit) Sign(ctx context.Context, name string, alg string, message []byte) ([]byte, error) {
+	req := struct {
+		Input              string `json:"input"`
+		SignatureAlgorithm string `json:"signature_algorithm,omitempty"`
+		MarshalingAlgo     string `json:"marshaling_algorithm,omitempty"`
+	}{
+		Input: base64.StdEncoding.EncodeToString(message),
+	}
+
+	path := "sign/" + url.PathEscape(name)
+
+	switch alg {
+	case "RS256":
+		path += "/sha2-256"
+		req.SignatureAlgorithm = "pkcs1v15"
+
+	case "ES256":
+		path += "/sha2-256"
+		req.MarshalingAlgo = "jws"
+
+	case "EdDSA":
+		// Ed25519 signs the message itself, there is no hash to pick.
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	var resp struct {
+		Data struct {
+			Signature string `json:"signature"`
+		} `json:"data"`
+	}
+
+	if err := t.do(ctx, http.MethodPost, path, req, &resp); err != nil {
+		return nil, err
+	}
+
+	// Signatures are returned as vault:v<version>:<signature>, which is
+	// base64url encoded when marshaled as jws and base64 otherwise.
+	i := strings.LastIndexByte(resp.Data.Signature, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("unexpected signature format %q", resp.Data.Signature)
+	}
+	encoded := resp.Data.Signature[i+1:]
+
+	decode := base64.StdEncoding.DecodeString
+	if req.MarshalingAlgo == "jws" {
+		decode = base64.RawURLEncoding.DecodeString
+		encoded = strings.TrimRight(encoded, "=")
+	}
+
+	sig, err := decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	return sig, nil
+}
+
+func (t *Transit) do(ctx context.Context, method string, path string, body any, v any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s/%s", t.addr, t.mount, path)
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, r)
+	if err != nil {
+		return fmt.Errorf("create request error: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", t.token)
+	if t.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", t.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		if err := json.Unmarshal(data, &e); err == nil && len(e.Errors) > 0 {
+			return fmt.Errorf("vault: status %d: %s", resp.StatusCode, strings.Join(e.Errors, "; "))
+		}
+		return fmt.Errorf("vault: status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
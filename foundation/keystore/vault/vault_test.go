@@ -0,0 +1,233 @@
+package vault_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mrcruz117/al-service/foundation/keystore/vault"
+)
+
+// Test_Transit serves the transit keys and sign endpoints the way Vault
+// does and checks the public keys are read back as PEM and the signatures
+// made with each algorithm verify against them.
+func Test_Transit(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to generate an rsa key : %s", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate an ecdsa key : %s", err)
+	}
+
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate an ed25519 key : %s", err)
+	}
+
+	publicPEM := func(key crypto.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatalf("Should be able to marshal the public key : %s", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+
+	// Ed25519 public keys are returned base64 encoded rather than as PEM.
+	publicKeys := map[string]string{
+		"rsa": publicPEM(&rsaKey.PublicKey),
+		"ec":  publicPEM(&ecKey.PublicKey),
+		"ed":  base64.StdEncoding.EncodeToString(edPublic),
+	}
+
+	sign := func(name string, path string, marshaling string, message []byte) (string, bool) {
+		hash := sha256.Sum256(message)
+
+		switch {
+		case name == "rsa" && path == "/sha2-256":
+			sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+			if err != nil {
+				t.Errorf("Should be able to sign with rsa : %s", err)
+			}
+			return base64.StdEncoding.EncodeToString(sig), true
+
+		case name == "ec" && path == "/sha2-256" && marshaling == "jws":
+			r, s, err := ecdsa.Sign(rand.Reader, ecKey, hash[:])
+			if err != nil {
+				t.Errorf("Should be able to sign with ecdsa : %s", err)
+			}
+			raw := make([]byte, 64)
+			r.FillBytes(raw[:32])
+			s.FillBytes(raw[32:])
+			return base64.RawURLEncoding.EncodeToString(raw), true
+
+		case name == "ed" && path == "":
+			return base64.StdEncoding.EncodeToString(ed25519.Sign(edKey, message)), true
+		}
+
+		return "", false
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" || r.Header.Get("X-Vault-Namespace") != "sales" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/keys-mount/keys/"):
+			name := strings.TrimPrefix(r.URL.Path, "/v1/keys-mount/keys/")
+
+			key, exists := publicKeys[name]
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string][]string{"errors": {}})
+				return
+			}
+
+			// The latest version is the one Sign uses.
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{
+					"latest_version": 2,
+					"keys": map[string]map[string]string{
+						"1": {"public_key": "old"},
+						"2": {"public_key": key},
+					},
+				},
+			})
+
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/keys-mount/sign/"):
+			var req struct {
+				Input              string `json:"input"`
+				SignatureAlgorithm string `json:"signature_algorithm"`
+				MarshalingAlgo     string `json:"marshaling_algorithm"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			message, err := base64.StdEncoding.DecodeString(req.Input)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			name, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/keys-mount/sign/"), "/")
+			if path != "" {
+				path = "/" + path
+			}
+
+			if name == "rsa" && req.SignatureAlgorithm != "pkcs1v15" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			sig, ok := sign(name, path, req.MarshalingAlgo, message)
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string][]string{"errors": {"unsupported signing request"}})
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"signature": "vault:v2:" + sig}})
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	transit, err := vault.New(vault.Config{
+		Addr:      srv.URL + "/",
+		Token:     "token",
+		Mount:     "/keys-mount/",
+		Namespace: "sales",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the transit client : %s", err)
+	}
+
+	ctx := context.Background()
+	message := []byte("header.payload")
+	hash := sha256.Sum256(message)
+
+	tests := []struct {
+		name   string
+		alg    string
+		verify func(key crypto.PublicKey, sig []byte) bool
+	}{
+		{"rsa", "RS256", func(key crypto.PublicKey, sig []byte) bool {
+			pub, ok := key.(*rsa.PublicKey)
+			return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) == nil
+		}},
+		{"ec", "ES256", func(key crypto.PublicKey, sig []byte) bool {
+			pub, ok := key.(*ecdsa.PublicKey)
+			return ok && len(sig) == 64 && ecdsa.Verify(pub, hash[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+		}},
+		{"ed", "EdDSA", func(key crypto.PublicKey, sig []byte) bool {
+			pub, ok := key.(ed25519.PublicKey)
+			return ok && ed25519.Verify(pub, message, sig)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pemKey, err := transit.PublicKey(ctx, tt.name)
+			if err != nil {
+				t.Fatalf("Should be able to read the public key : %s", err)
+			}
+
+			block, _ := pem.Decode([]byte(pemKey))
+			if block == nil || block.Type != "PUBLIC KEY" {
+				t.Fatalf("Should return the public key as PEM, got %q", pemKey)
+			}
+
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				t.Fatalf("Should be able to parse the public key : %s", err)
+			}
+
+			sig, err := transit.Sign(ctx, tt.name, tt.alg, message)
+			if err != nil {
+				t.Fatalf("Should be able to sign : %s", err)
+			}
+
+			if !tt.verify(key, sig) {
+				t.Errorf("Should make a signature that verifies with the public key")
+			}
+		})
+	}
+
+	// -------------------------------------------------------------------------
+
+	if _, err := transit.Sign(ctx, "rsa", "HS256", message); err == nil {
+		t.Errorf("Should refuse an algorithm transit doesn't sign with")
+	}
+
+	denied, err := vault.New(vault.Config{Addr: srv.URL, Token: "other", Mount: "keys-mount", Namespace: "sales"})
+	if err != nil {
+		t.Fatalf("Should be able to construct the transit client : %s", err)
+	}
+
+	if _, err := denied.PublicKey(ctx, "rsa"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Should report the errors vault responds with, got %v", err)
+	}
+}
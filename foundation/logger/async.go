@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy represents what an AsyncWriter does with a log when its buffer is
+// full.
+type Policy int
+
+// A set of possible backpressure policies. Block keeps every log at the cost
+// of stalling the caller until there is room, drop keeps the caller moving
+// at the cost of losing logs.
+const (
+	PolicyBlock Policy = iota
+	PolicyDrop
+)
+
+// String returns the name of the policy.
+func (p Policy) String() string {
+	switch p {
+	case PolicyDrop:
+		return "drop"
+	default:
+		return "block"
+	}
+}
+
+// ParsePolicy converts a policy name such as "block" or "drop" to a Policy.
+func ParsePolicy(s string) (Policy, error) {
+	switch strings.ToLower(s) {
+	case "block":
+		return PolicyBlock, nil
+	case "drop":
+		return PolicyDrop, nil
+	default:
+		return 0, fmt.Errorf("parse policy: unknown policy %q", s)
+	}
+}
+
+// AsyncConfig represents the settings for an AsyncWriter.
+type AsyncConfig struct {
+
+	// Size is the number of logs that can be waiting to be written.
+	Size int
+
+	// Policy decides what happens to a log when Size logs are already
+	// waiting.
+	Policy Policy
+}
+
+// asyncItem is either a log waiting to be written or a request to signal
+// once everything queued before it has been written.
+type asyncItem struct {
+	p       []byte
+	flushed chan struct{}
+}
+
+// AsyncWriter moves writing the logs off the caller's goroutine. Logs are
+// queued in a bounded buffer and written in order by a single goroutine.
+// Close must be called on shutdown so the buffered logs are written.
+type AsyncWriter struct {
+	w       io.Writer
+	policy  Policy
+	items   chan asyncItem
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// NewAsyncWriter constructs an AsyncWriter that writes to w.
+func NewAsyncWriter(w io.Writer, cfg AsyncConfig) *AsyncWriter {
+	aw := AsyncWriter{
+		w:      w,
+		policy: cfg.Policy,
+		items:  make(chan asyncItem, max(cfg.Size, 1)),
+		done:   make(chan struct{}),
+	}
+
+	go aw.run()
+
+	return &aw
+}
+
+// Write queues a copy of p to be written. Any error writing to the
+// underlying writer is lost since the caller has already moved on. Once the
+// writer is closed, logs are written directly.
+func (aw *AsyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+
+	if aw.closed {
+		return aw.w.Write(p)
+	}
+
+	// The handlers reuse their buffers once Write returns.
+	it := asyncItem{p: append([]byte(nil), p...)}
+
+	switch aw.policy {
+	case PolicyDrop:
+		select {
+		case aw.items <- it:
+		default:
+			aw.dropped.Add(1)
+		}
+
+	default:
+		aw.items <- it
+	}
+
+	return len(p), nil
+}
+
+// Flush blocks until every log queued before the call has been written.
+func (aw *AsyncWriter) Flush() {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+
+	if aw.closed {
+		return
+	}
+
+	flushed := make(chan struct{})
+	aw.items <- asyncItem{flushed: flushed}
+	<-flushed
+}
+
+// Dropped returns the number of logs dropped because the buffer was full.
+func (aw *AsyncWriter) Dropped() uint64 {
+	return aw.dropped.Load()
+}
+
+// Close writes the buffered logs and stops the background goroutine. It
+// doesn't close the underlying writer.
+func (aw *AsyncWriter) Close() error {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	if aw.closed {
+		return nil
+	}
+
+	aw.closed = true
+	close(aw.items)
+	<-aw.done
+
+	return nil
+}
+
+func (aw *AsyncWriter) run() {
+	defer close(aw.done)
+
+	for it := range aw.items {
+		if it.flushed != nil {
+			close(it.flushed)
+			continue
+		}
+
+		aw.w.Write(it.p)
+	}
+}
@@ -0,0 +1,243 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Format represents how the logs are encoded.
+type Format int32
+
+// A set of possible log formats. JSON is meant for production where the logs
+// are collected and indexed, console for reading them in a terminal.
+const (
+	FormatJSON Format = iota
+	FormatConsole
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case FormatConsole:
+		return "console"
+	default:
+		return "json"
+	}
+}
+
+// ParseFormat converts a format name such as "json" or "console" to a
+// Format.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "json":
+		return FormatJSON, nil
+	case "console":
+		return FormatConsole, nil
+	default:
+		return 0, fmt.Errorf("parse format: unknown format %q", s)
+	}
+}
+
+// =============================================================================
+
+// formatHandler sends each record to the handler for the currently selected
+// format so the format can be changed at runtime.
+type formatHandler struct {
+	format  *atomic.Int32
+	json    slog.Handler
+	console slog.Handler
+}
+
+func (h *formatHandler) current() slog.Handler {
+	if Format(h.format.Load()) == FormatConsole {
+		return h.console
+	}
+
+	return h.json
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *formatHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.current().Enabled(ctx, level)
+}
+
+// WithAttrs returns a new formatHandler with the attributes added to the
+// handler of each format.
+func (h *formatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &formatHandler{format: h.format, json: h.json.WithAttrs(attrs), console: h.console.WithAttrs(attrs)}
+}
+
+// WithGroup returns a new formatHandler with the group added to the handler
+// of each format.
+func (h *formatHandler) WithGroup(name string) slog.Handler {
+	return &formatHandler{format: h.format, json: h.json.WithGroup(name), console: h.console.WithGroup(name)}
+}
+
+// Handle formats the record with the handler for the current format.
+func (h *formatHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.current().Handle(ctx, r)
+}
+
+// =============================================================================
+
+// msgWidth is the width messages are padded to so the keys that follow line
+// up across logs.
+const msgWidth = 40
+
+// ANSI escape codes used to colorize the level.
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+)
+
+// consoleHandler writes a log per line in a form meant to be read by a
+// person: a short timestamp, a colorized level, the file and the message
+// followed by the key/value pairs.
+type consoleHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	attrs  string
+	prefix string
+}
+
+func newConsoleHandler(w io.Writer, level slog.Leveler) *consoleHandler {
+	return &consoleHandler{
+		mu:    &sync.Mutex{},
+		w:     w,
+		level: level,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *consoleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// WithAttrs returns a new consoleHandler that writes the attributes with
+// every log.
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b bytes.Buffer
+	for _, a := range attrs {
+		writeAttr(&b, h.prefix, a)
+	}
+
+	h2 := *h
+	h2.attrs += b.String()
+
+	return &h2
+}
+
+// WithGroup returns a new consoleHandler that qualifies the keys of every
+// attribute added afterwards with the group name.
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.prefix += name + "."
+
+	return &h2
+}
+
+// Handle formats the record as a single line.
+func (h *consoleHandler) Handle(ctx context.Context, r slog.Record) error {
+	var b bytes.Buffer
+
+	b.WriteString(colorGray)
+	b.WriteString(r.Time.Format(time.TimeOnly + ".000"))
+	b.WriteString(colorReset)
+	b.WriteByte(' ')
+
+	color, name := levelStyle(r.Level)
+	b.WriteString(color)
+	b.WriteString(name)
+	b.WriteString(colorReset)
+	b.WriteByte(' ')
+
+	if r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		f, _ := frames.Next()
+
+		b.WriteString(colorGray)
+		fmt.Fprintf(&b, "%-20s", filepath.Base(f.File)+":"+strconv.Itoa(f.Line))
+		b.WriteString(colorReset)
+		b.WriteByte(' ')
+	}
+
+	fmt.Fprintf(&b, "%-*s", msgWidth, r.Message)
+
+	b.WriteString(h.attrs)
+
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+func levelStyle(level slog.Level) (string, string) {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed, "ERR"
+	case level >= slog.LevelWarn:
+		return colorYellow, "WRN"
+	case level >= slog.LevelInfo:
+		return colorCyan, "INF"
+	default:
+		return colorGray, "DBG"
+	}
+}
+
+func writeAttr(b *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, prefix, ga)
+		}
+
+		return
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(colorGray)
+	b.WriteString(prefix + a.Key)
+	b.WriteByte('=')
+	b.WriteString(colorReset)
+
+	v := a.Value.String()
+	if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+		v = strconv.Quote(v)
+	}
+	b.WriteString(v)
+}
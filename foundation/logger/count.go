@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// CountFn represents a function that is called for every log written, such
+// as to feed the log volume per level into the metrics.
+type CountFn func(service string, level Level)
+
+// countHandler calls the count function for each record before passing it
+// on, so the volume is known even for records the events don't care about.
+type countHandler struct {
+	handler slog.Handler
+	service string
+	count   *atomic.Pointer[CountFn]
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *countHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new countHandler with the attributes added to the
+// handler it wraps.
+func (h *countHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &countHandler{handler: h.handler.WithAttrs(attrs), service: h.service, count: h.count}
+}
+
+// WithGroup returns a new countHandler with the group added to the handler
+// it wraps.
+func (h *countHandler) WithGroup(name string) slog.Handler {
+	return &countHandler{handler: h.handler.WithGroup(name), service: h.service, count: h.count}
+}
+
+// Handle counts the record and then hands it to the wrapped handler.
+func (h *countHandler) Handle(ctx context.Context, r slog.Record) error {
+	if fn := h.count.Load(); fn != nil {
+		(*fn)(h.service, Level(r.Level))
+	}
+
+	return h.handler.Handle(ctx, r)
+}
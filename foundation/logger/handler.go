@@ -65,3 +65,69 @@ func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
 
 	return h.handler.Handle(ctx, r)
 }
+
+// fanoutHandler sends each record to every handler that is enabled for its
+// level.
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func newFanoutHandler(handlers []slog.Handler) slog.Handler {
+	if len(handlers) == 1 {
+		return handlers[0]
+	}
+
+	return &fanoutHandler{handlers: handlers}
+}
+
+// Enabled reports whether any of the handlers handles records at the given
+// level.
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, hdl := range h.handlers {
+		if hdl.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithAttrs returns a new fanoutHandler with the attributes added to every
+// handler.
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hdl := range h.handlers {
+		handlers[i] = hdl.WithAttrs(attrs)
+	}
+
+	return &fanoutHandler{handlers: handlers}
+}
+
+// WithGroup returns a new fanoutHandler with the group added to every
+// handler.
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hdl := range h.handlers {
+		handlers[i] = hdl.WithGroup(name)
+	}
+
+	return &fanoutHandler{handlers: handlers}
+}
+
+// Handle sends the record to the enabled handlers. Every handler is given a
+// chance to write the record and the first error is returned.
+func (h *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+
+	for _, hdl := range h.handlers {
+		if !hdl.Enabled(ctx, r.Level) {
+			continue
+		}
+
+		if err := hdl.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Levels maps package paths to the minimum level logged by the code in that
+// package, such as business/core/user=debug to see the debug logs of a
+// single domain. A path matches the package it names and the packages below
+// it, and is matched against the trailing elements of the import path so
+// the module prefix can be left out. The most specific path wins.
+type Levels map[string]Level
+
+// ParseLevels converts a comma separated list of path=level pairs such as
+// "business/core/user=debug,foundation/web=info" to Levels.
+func ParseLevels(s string) (Levels, error) {
+	levels := make(Levels)
+
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		path, name, found := strings.Cut(pair, "=")
+		path = strings.Trim(strings.TrimSpace(path), "/")
+		if !found || path == "" {
+			return nil, fmt.Errorf("parse levels: expected path=level, got %q", pair)
+		}
+
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("parse levels: %s: %w", path, err)
+		}
+
+		levels[path] = level
+	}
+
+	return levels, nil
+}
+
+// String returns the levels in the form ParseLevels accepts, sorted by
+// path.
+func (l Levels) String() string {
+	pairs := make([]string, 0, len(l))
+	for _, path := range slices.Sorted(maps.Keys(l)) {
+		pairs = append(pairs, path+"="+l[path].String())
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// =============================================================================
+
+type override struct {
+	path  string
+	level slog.Level
+}
+
+// levels holds the minimum level of the logger and the per package
+// overrides. The handlers are only given the lowest of all of them, so a
+// package can log below the logger's level, and the per package decision
+// is made before a record is handed to them.
+type levels struct {
+	global    slog.LevelVar
+	floor     slog.LevelVar
+	mu        sync.Mutex
+	overrides atomic.Pointer[[]override]
+	pkgs      sync.Map
+}
+
+func newLevels(level Level) *levels {
+	var l levels
+	l.global.Set(slog.Level(level))
+	l.floor.Set(slog.Level(level))
+	l.overrides.Store(&[]override{})
+
+	return &l
+}
+
+func (l *levels) level() Level {
+	return Level(l.global.Level())
+}
+
+func (l *levels) setLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.global.Set(slog.Level(level))
+	l.setFloor(*l.overrides.Load())
+}
+
+func (l *levels) packages() Levels {
+	ovs := *l.overrides.Load()
+
+	pkgs := make(Levels, len(ovs))
+	for _, ov := range ovs {
+		pkgs[ov.path] = Level(ov.level)
+	}
+
+	return pkgs
+}
+
+func (l *levels) setPackages(pkgs Levels) {
+	ovs := make([]override, 0, len(pkgs))
+	for path, level := range pkgs {
+		ovs = append(ovs, override{path: strings.Trim(path, "/"), level: slog.Level(level)})
+	}
+
+	// Longest first so the most specific path is the first match.
+	slices.SortFunc(ovs, func(a override, b override) int {
+		return len(b.path) - len(a.path)
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.overrides.Store(&ovs)
+	l.setFloor(ovs)
+}
+
+func (l *levels) setFloor(ovs []override) {
+	floor := l.global.Level()
+	for _, ov := range ovs {
+		floor = min(floor, ov.level)
+	}
+
+	l.floor.Set(floor)
+}
+
+// enabled reports whether a log at the level written from the code at pc
+// should be logged. The handlers have already accepted the level, so
+// without overrides there is nothing more to check.
+func (l *levels) enabled(level slog.Level, pc uintptr) bool {
+	ovs := *l.overrides.Load()
+	if len(ovs) == 0 {
+		return true
+	}
+
+	pkg := l.pkg(pc)
+	for _, ov := range ovs {
+		if matchPath(pkg, ov.path) {
+			return level >= ov.level
+		}
+	}
+
+	return level >= l.global.Level()
+}
+
+// pkg returns the import path of the package holding the code at pc. The
+// lookups are cached since the same call sites log over and over.
+func (l *levels) pkg(pc uintptr) string {
+	if pkg, exists := l.pkgs.Load(pc); exists {
+		return pkg.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	// The function name is the import path followed by the qualified name,
+	// such as github.com/a/b/core/user.(*Core).Create. Only the last
+	// element of the path can be followed by a dot.
+	pkg := frame.Function
+	slash := strings.LastIndexByte(pkg, '/')
+	if dot := strings.IndexByte(pkg[slash+1:], '.'); dot >= 0 {
+		pkg = pkg[:slash+1+dot]
+	}
+
+	l.pkgs.Store(pc, pkg)
+
+	return pkg
+}
+
+// matchPath reports whether the import path is the package named by path or
+// one below it, matching whole elements from any point in the import path.
+func matchPath(pkg string, path string) bool {
+	pkg = "/" + pkg + "/"
+	return strings.Contains(pkg, "/"+path+"/")
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,17 +21,29 @@ type TraceIDFn func(ctx context.Context) string
 type Logger struct {
 	discard   bool
 	handler   slog.Handler
+	levels    *levels
+	sampler   *sampler
+	out       *output
+	format    *atomic.Int32
+	count     *atomic.Pointer[CountFn]
 	traceIDFn TraceIDFn
 }
 
 // New constructs a new log for application use.
 func New(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn) *Logger {
-	return new(w, minLevel, serviceName, traceIDFn, Events{})
+	return new([]Output{{Writer: w, MinLevel: LevelDebug}}, minLevel, serviceName, traceIDFn, Events{})
 }
 
 // NewWithEvents constructs a new log for application use with events.
 func NewWithEvents(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
-	return new(w, minLevel, serviceName, traceIDFn, events)
+	return new([]Output{{Writer: w, MinLevel: LevelDebug}}, minLevel, serviceName, traceIDFn, events)
+}
+
+// NewWithOutputs constructs a new log for application use that writes every
+// log to each of the outputs whose minimum level it meets, such as stdout
+// for everything and a file for warnings and errors only.
+func NewWithOutputs(outputs []Output, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
+	return new(outputs, minLevel, serviceName, traceIDFn, events)
 }
 
 // NewWithHandler returns a new log for application use with the underlying
@@ -44,6 +57,116 @@ func NewStdLogger(logger *Logger, level Level) *log.Logger {
 	return slog.NewLogLogger(logger.handler, slog.Level(level))
 }
 
+// With returns a child logger that adds the key/value pairs to every log it
+// writes, such as the domain or tenant a package is working on. The child
+// shares the level of its parent.
+func (log *Logger) With(args ...any) *Logger {
+	if len(args) == 0 {
+		return log
+	}
+
+	child := *log
+	child.handler = slog.New(log.handler).With(args...).Handler()
+
+	return &child
+}
+
+// SetLevel changes the minimum level logged while the program is running.
+// It has no effect on a logger constructed with NewWithHandler since the
+// level is owned by that handler.
+func (log *Logger) SetLevel(level Level) {
+	if log.levels == nil {
+		return
+	}
+
+	log.levels.setLevel(level)
+}
+
+// SetLevels changes the minimum level of individual packages while the
+// program is running, such as turning on debug logs for one domain while
+// the rest of the service stays at info. The levels replace any set before
+// and passing nil leaves every package at the logger's level again. Like
+// SetLevel, it has no effect on a logger constructed with NewWithHandler.
+func (log *Logger) SetLevels(pkgs Levels) {
+	if log.levels == nil {
+		return
+	}
+
+	log.levels.setPackages(pkgs)
+}
+
+// SetOutput changes where the logs are written while the program is
+// running, such as to a file once the configuration is known. With several
+// outputs only the first one is changed. Like SetLevel, it has no effect on
+// a logger constructed with NewWithHandler.
+func (log *Logger) SetOutput(w io.Writer) {
+	if log.out == nil {
+		return
+	}
+
+	log.out.set(w)
+}
+
+// SetFormat changes how the logs are encoded while the program is running,
+// such as to the console format once the configuration asks for it. Like
+// SetOutput, only the first output is changed and it has no effect on a
+// logger constructed with NewWithHandler.
+func (log *Logger) SetFormat(f Format) {
+	if log.format == nil {
+		return
+	}
+
+	log.format.Store(int32(f))
+}
+
+// SetSampling changes which levels have identical messages sampled, such as
+// the request logs of health check traffic. Passing nil logs everything
+// again. Like SetLevel, it has no effect on a logger constructed with
+// NewWithHandler.
+func (log *Logger) SetSampling(s Sampling) {
+	if log.sampler == nil {
+		return
+	}
+
+	log.sampler.set(s)
+}
+
+// SetCounter sets the function called for every log written, such as to
+// count the logs per level in the metrics. Passing nil stops the counting.
+// Like SetLevel, it has no effect on a logger constructed with
+// NewWithHandler.
+func (log *Logger) SetCounter(fn CountFn) {
+	if log.count == nil {
+		return
+	}
+
+	if fn == nil {
+		log.count.Store(nil)
+		return
+	}
+
+	log.count.Store(&fn)
+}
+
+// Level returns the minimum level currently being logged.
+func (log *Logger) Level() Level {
+	if log.levels == nil {
+		return LevelInfo
+	}
+
+	return log.levels.level()
+}
+
+// Levels returns the minimum level of the packages that don't log at the
+// logger's level.
+func (log *Logger) Levels() Levels {
+	if log.levels == nil {
+		return Levels{}
+	}
+
+	return log.levels.packages()
+}
+
 // Debug logs at LevelDebug with the given context.
 func (log *Logger) Debug(ctx context.Context, msg string, args ...any) {
 	if log.discard {
@@ -126,21 +249,37 @@ func (log *Logger) write(ctx context.Context, level Level, caller int, msg strin
 	var pcs [1]uintptr
 	runtime.Callers(caller, pcs[:])
 
-	r := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
+	if log.levels != nil && !log.levels.enabled(slogLevel, pcs[0]) {
+		return
+	}
+
+	now := time.Now()
+
+	if log.sampler != nil && !log.sampler.allow(level, msg, now) {
+		return
+	}
+
+	r := slog.NewRecord(now, slogLevel, msg, pcs[0])
 
 	if log.traceIDFn != nil {
 		args = append(args, "trace_id", log.traceIDFn(ctx))
 	}
 	r.Add(args...)
 
+	if level >= LevelError {
+		if err := errorOf(r); err != nil {
+			addErrorDetail(&r, err, caller)
+		}
+	}
+
 	log.handler.Handle(ctx, r)
 }
 
-func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
+func new(outputs []Output, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
 
 	// Convert the file name to just the name.ext when this key/value will
 	// be logged.
-	f := func(groups []string, a slog.Attr) slog.Attr {
+	replace := func(groups []string, a slog.Attr) slog.Attr {
 		if a.Key == slog.SourceKey {
 			if source, ok := a.Value.Any().(*slog.Source); ok {
 				v := fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line)
@@ -151,8 +290,43 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 		return a
 	}
 
-	// Construct the slog JSON handler for use.
-	handler := slog.Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: slog.Level(minLevel), ReplaceAttr: f}))
+	// The levels are held in variables so they can be changed at runtime.
+	lvls := newLevels(minLevel)
+
+	// Construct a handler for each output that can encode the logs as JSON
+	// or for the console. The writers are held behind an output and the
+	// format in a variable so both can be changed at runtime.
+	var out *output
+	var format *atomic.Int32
+	var discard bool
+	handlers := make([]slog.Handler, len(outputs))
+
+	for i, o := range outputs {
+		w := newOutput(o.Writer)
+
+		var f atomic.Int32
+		f.Store(int32(o.Format))
+
+		if i == 0 {
+			out = w
+			format = &f
+			discard = true
+		}
+
+		// Mark if we are only using the no-op writer to discard logs.
+		if _, exists := o.Writer.(noopWriter); !exists {
+			discard = false
+		}
+
+		lvl := outputLevel{level: &lvls.floor, min: slog.Level(o.MinLevel)}
+		handlers[i] = &formatHandler{
+			format:  &f,
+			json:    slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: lvl, ReplaceAttr: replace}),
+			console: newConsoleHandler(w, lvl),
+		}
+	}
+
+	handler := slog.Handler(newFanoutHandler(handlers))
 
 	// If events are to be processed, wrap the JSON handler around the custom
 	// log handler.
@@ -160,6 +334,11 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 		handler = newLogHandler(handler, events)
 	}
 
+	// The logs are counted before the events run so a burst shows in the
+	// metrics even for a level without an event.
+	var count atomic.Pointer[CountFn]
+	handler = &countHandler{handler: handler, service: serviceName, count: &count}
+
 	// Attributes to add to every log.
 	attrs := []slog.Attr{
 		{Key: "service", Value: slog.StringValue(serviceName)},
@@ -168,15 +347,14 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 	// Add those attributes and capture the final handler.
 	handler = handler.WithAttrs(attrs)
 
-	// Mark if we are using the no-op writer to discard logs.
-	var discard bool
-	if _, exists := w.(noopWriter); exists {
-		discard = true
-	}
-
 	return &Logger{
 		discard:   discard,
 		handler:   handler,
+		levels:    lvls,
+		sampler:   &sampler{},
+		out:       out,
+		format:    format,
+		count:     &count,
 		traceIDFn: traceIDFn,
 	}
 }
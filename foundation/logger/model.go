@@ -2,7 +2,9 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,21 @@ const (
 	LevelError = Level(slog.LevelError)
 )
 
+// String returns the name of the level in lowercase.
+func (l Level) String() string {
+	return strings.ToLower(slog.Level(l).String())
+}
+
+// ParseLevel converts a level name such as "debug" or "INFO" to a Level.
+func ParseLevel(s string) (Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("parse level: %w", err)
+	}
+
+	return Level(l), nil
+}
+
 // Record represents the data that is being logged.
 type Record struct {
 	Time       time.Time
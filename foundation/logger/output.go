@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"sync/atomic"
+)
+
+// output is a writer whose destination can be swapped while logs are being
+// written.
+type output struct {
+	w atomic.Pointer[io.Writer]
+}
+
+func newOutput(w io.Writer) *output {
+	var out output
+	out.set(w)
+
+	return &out
+}
+
+func (o *output) set(w io.Writer) {
+	o.w.Store(&w)
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	return (*o.w.Load()).Write(p)
+}
+
+// Output represents a destination for the logs. An output only receives
+// the logs at or above both its own minimum level and the logger's level.
+// Like slog, the zero MinLevel is LevelInfo, so an output that should follow
+// the logger's level down to debug needs MinLevel set to LevelDebug. The
+// zero Format is JSON.
+type Output struct {
+	Writer   io.Writer
+	MinLevel Level
+	Format   Format
+}
+
+// outputLevel combines the lowest level the logger or any of its packages
+// log at with the minimum level of an output.
+type outputLevel struct {
+	level *slog.LevelVar
+	min   slog.Level
+}
+
+func (l outputLevel) Level() slog.Level {
+	return max(l.level.Level(), l.min)
+}
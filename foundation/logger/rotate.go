@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is used in the name of a rotated file so the backups sort
+// in the order they were written.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateConfig represents the settings of a RotatingFile.
+type RotateConfig struct {
+
+	// MaxSize is the size in bytes a file can grow to before it's rotated.
+	// Zero never rotates.
+	MaxSize int64
+
+	// MaxAge is how long rotated files are kept. Zero keeps them forever.
+	MaxAge time.Duration
+
+	// Compress gzips the rotated files.
+	Compress bool
+}
+
+// RotatingFile is a writer that appends to a file and moves it aside once it
+// reaches its maximum size, for hosts without a log shipper to keep the disk
+// from filling up.
+type RotatingFile struct {
+	cfg  RotateConfig
+	path string
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	wg   sync.WaitGroup
+	bgMu sync.Mutex
+}
+
+// NewRotatingFile opens the file at the path for appending, creating it and
+// its directory if they don't exist.
+func NewRotatingFile(path string, cfg RotateConfig) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating directory: %w", err)
+	}
+
+	f := RotatingFile{
+		cfg:  cfg,
+		path: path,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	f.prune()
+
+	return &f, nil
+}
+
+// Write appends the data to the file, rotating the file first if the data
+// would take it past its maximum size.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.cfg.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.cfg.MaxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the file and waits for any rotated files to be compressed.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.wg.Wait()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("closing file: %w", err)
+	}
+	f.file = nil
+
+	backup := f.backupName()
+
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("renaming file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	// Compressing and pruning happen off the write path, one rotation at
+	// a time.
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		f.bgMu.Lock()
+		defer f.bgMu.Unlock()
+
+		if f.cfg.Compress {
+			if err := compress(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: compressing %s: %s\n", backup, err)
+			}
+		}
+
+		f.prune()
+	}()
+
+	return nil
+}
+
+// backupName returns an unused name for the file being rotated.
+func (f *RotatingFile) backupName() string {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext) + "-" + time.Now().UTC().Format(backupTimeFormat)
+
+	name := base + ext
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+
+	return name
+}
+
+// prune removes the rotated files older than the maximum age.
+func (f *RotatingFile) prune() {
+	if f.cfg.MaxAge <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.path)
+	pattern := strings.TrimSuffix(f.path, ext) + "-*" + ext + "*"
+
+	backups, err := filepath.Glob(pattern)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-f.cfg.MaxAge)
+
+	for _, backup := range backups {
+		info, err := os.Stat(backup)
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		os.Remove(backup)
+	}
+}
+
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// maxSampled bounds the number of distinct messages tracked for sampling.
+// Messages are expected to be constants, so reaching it means expired
+// entries are pruned.
+const maxSampled = 10_000
+
+// Sample represents how often identical messages are logged. Within each
+// window the first occurrence is logged and then every Nth one after it.
+type Sample struct {
+	Every  int
+	Window time.Duration
+}
+
+// Sampling maps the levels that are sampled to their rate. Levels that are
+// not present are always logged.
+type Sampling map[Level]Sample
+
+type sampleKey struct {
+	level Level
+	msg   string
+}
+
+type sampleCount struct {
+	start time.Time
+	n     int
+}
+
+// sampler tracks the occurrences of each message and level.
+type sampler struct {
+	mu     sync.Mutex
+	rates  Sampling
+	counts map[sampleKey]*sampleCount
+}
+
+func (s *sampler) set(rates Sampling) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rates = make(Sampling, len(rates))
+	for level, rate := range rates {
+		if rate.Every > 1 && rate.Window > 0 {
+			s.rates[level] = rate
+		}
+	}
+
+	s.counts = make(map[sampleKey]*sampleCount)
+}
+
+// allow reports whether this occurrence of the message should be logged.
+func (s *sampler) allow(level Level, msg string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rate, exists := s.rates[level]
+	if !exists {
+		return true
+	}
+
+	key := sampleKey{level: level, msg: msg}
+
+	c, exists := s.counts[key]
+	if !exists {
+		if len(s.counts) >= maxSampled {
+			s.prune(now)
+		}
+
+		c = &sampleCount{start: now}
+		s.counts[key] = c
+	}
+
+	if now.Sub(c.start) >= rate.Window {
+		c.start = now
+		c.n = 0
+	}
+
+	c.n++
+
+	return (c.n-1)%rate.Every == 0
+}
+
+func (s *sampler) prune(now time.Time) {
+	for key, c := range s.counts {
+		if now.Sub(c.start) >= s.rates[key.level].Window {
+			delete(s.counts, key)
+		}
+	}
+}
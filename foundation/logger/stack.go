@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStack bounds the number of frames attached to an error log. The frames
+// closest to the log call are the ones worth reading.
+const maxStack = 16
+
+// errorOf returns the first error value in the record.
+func errorOf(r slog.Record) error {
+	var err error
+
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Value.Kind() != slog.KindAny {
+			return true
+		}
+
+		if e, ok := a.Value.Any().(error); ok {
+			err = e
+			return false
+		}
+
+		return true
+	})
+
+	return err
+}
+
+// addErrorDetail attaches the stack of the log call and every error in the
+// chain of err to the record, so an error log says where it was logged from
+// and what it was caused by and not just the final message.
+func addErrorDetail(r *slog.Record, err error, caller int) {
+	r.AddAttrs(
+		slog.Any("stack", stack(caller+1)),
+		slog.Any("error_chain", chain(err)),
+	)
+}
+
+// stack returns the frames of the calling goroutine starting at caller as
+// "function file:line". The runtime frames and those of the http server
+// that started the goroutine are left out.
+func stack(caller int) []string {
+	var pcs [maxStack + 8]uintptr
+	n := runtime.Callers(caller+1, pcs[:])
+
+	frames := runtime.CallersFrames(pcs[:n])
+
+	lines := make([]string, 0, maxStack)
+	for len(lines) < maxStack {
+		f, more := frames.Next()
+
+		if f.Function == "" || strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "net/http.") {
+			if !more {
+				break
+			}
+			continue
+		}
+
+		lines = append(lines, f.Function+" "+filepath.Base(f.File)+":"+strconv.Itoa(f.Line))
+
+		if !more {
+			break
+		}
+	}
+
+	return lines
+}
+
+// chain returns the type and message of err and of every error it wraps,
+// outermost first. An error that formats itself with %+v, like one carrying
+// its own stack, is written that way.
+func chain(err error) []string {
+	var links []string
+
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+
+		links = append(links, fmt.Sprintf("%T: %+v", err, err))
+
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+
+	walk(err)
+
+	return links
+}
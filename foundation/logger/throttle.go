@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Throttle returns an event function that calls fn when threshold records
+// have been logged within a window, and at most once per window. A burst of
+// warnings raises a single alert instead of one per log, and a threshold of
+// 1 simply limits fn to once per window. The function is called on the
+// goroutine doing the logging, outside of any lock held by Throttle.
+func Throttle(threshold int, window time.Duration, fn EventFn) EventFn {
+	var mu sync.Mutex
+	var start time.Time
+	var count int
+
+	f := func(ctx context.Context, r Record) {
+		mu.Lock()
+
+		if r.Time.Sub(start) >= window {
+			start = r.Time
+			count = 0
+		}
+
+		count++
+		fire := count == threshold
+
+		mu.Unlock()
+
+		if fire {
+			fn(ctx, r)
+		}
+	}
+
+	return f
+}
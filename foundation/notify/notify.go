@@ -0,0 +1,144 @@
+// Package notify provides support for delivering notifications such as email
+// through an in memory queue so callers never wait on the delivery provider.
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/queuemon"
+)
+
+// ErrQueueFull is returned when a message can't be accepted because the
+// queue is at capacity.
+var ErrQueueFull = errors.New("notification queue is full")
+
+// Message represents a notification to deliver.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers a message to its recipient.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type item struct {
+	msg      Message
+	queued   time.Time
+	attempts int
+}
+
+// Queue buffers messages and delivers them in the background.
+type Queue struct {
+	log      *logger.Logger
+	sender   Sender
+	retries  int
+	backoff  time.Duration
+	items    chan item
+	mu       sync.Mutex
+	inflight map[*item]struct{}
+	lastSent time.Time
+}
+
+// NewQueue constructs a queue holding up to size messages. Failed deliveries
+// are retried up to retries times, waiting backoff multiplied by the attempt
+// between each try.
+func NewQueue(log *logger.Logger, sender Sender, size int, retries int, backoff time.Duration) *Queue {
+	return &Queue{
+		log:      log,
+		sender:   sender,
+		retries:  retries,
+		backoff:  backoff,
+		items:    make(chan item, size),
+		inflight: make(map[*item]struct{}),
+		lastSent: time.Now(),
+	}
+}
+
+// Enqueue accepts a message for delivery without blocking.
+func (q *Queue) Enqueue(msg Message) error {
+	select {
+	case q.items <- item{msg: msg, queued: time.Now()}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Run delivers queued messages until the context is canceled.
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case it := <-q.items:
+			q.deliver(ctx, &it)
+		}
+	}
+}
+
+func (q *Queue) deliver(ctx context.Context, it *item) {
+	q.mu.Lock()
+	q.inflight[it] = struct{}{}
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		delete(q.inflight, it)
+		q.mu.Unlock()
+	}()
+
+	for {
+		it.attempts++
+
+		err := q.sender.Send(ctx, it.msg)
+		if err == nil {
+			q.mu.Lock()
+			q.lastSent = time.Now()
+			q.mu.Unlock()
+			return
+		}
+
+		if it.attempts > q.retries {
+			q.log.Error(ctx, "notify", "status", "delivery failed", "to", it.msg.To, "attempts", it.attempts, "msg", err)
+			return
+		}
+
+		q.log.Info(ctx, "notify", "status", "delivery retry", "to", it.msg.To, "attempts", it.attempts, "msg", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.backoff * time.Duration(it.attempts)):
+		}
+	}
+}
+
+// Stats reports the state of the queue for monitoring.
+func (q *Queue) Stats(ctx context.Context) (queuemon.Stats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := queuemon.Stats{
+		Depth: int64(len(q.items) + len(q.inflight)),
+	}
+
+	if stats.Depth > 0 {
+		stats.Lag = time.Since(q.lastSent)
+	}
+
+	for it := range q.inflight {
+		if age := time.Since(it.queued); age > stats.OldestAge {
+			stats.OldestAge = age
+		}
+	}
+
+	return stats, nil
+}
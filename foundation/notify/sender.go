@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"regexp"
+	"strings"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// tokenParam matches the value of a token query parameter of a link.
+var tokenParam = regexp.MustCompile(`([?&]token=)[^&\s]+`)
+
+// LogSender writes messages to the log instead of delivering them. It's
+// meant for development environments. The tokens of the links in the body
+// are redacted, since a link to reset a password grants the account to
+// whoever reads the log.
+type LogSender struct {
+	Log *logger.Logger
+}
+
+// Send implements the Sender interface.
+func (s LogSender) Send(ctx context.Context, msg Message) error {
+	body := tokenParam.ReplaceAllString(msg.Body, "${1}REDACTED")
+
+	s.Log.Info(ctx, "notify", "to", msg.To, "subject", msg.Subject, "body", body)
+	return nil
+}
+
+// SMTPSender delivers messages as plain text email through an SMTP relay.
+type SMTPSender struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+// Send implements the Sender interface.
+func (s SMTPSender) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid header value")
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return fmt.Errorf("addr: %w", err)
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	if err := smtp.SendMail(s.Addr, auth, s.From, []string{msg.To}, []byte(b.String())); err != nil {
+		return fmt.Errorf("sendmail: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+// Package openapi builds OpenAPI 3 documents describing an api. Operations
+// are added with the Go values they take and return, and the schemas of
+// those values are derived from their types and json tags.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is the version of the specification documents are written in.
+const Version = "3.0.3"
+
+// Operation describes a single method and path of the api. Request and
+// Response are zero values of the types the operation decodes and
+// responds with, nil when there is no body. Status is the code of a
+// successful response, 200 when zero.
+type Operation struct {
+	Summary  string
+	Tags     []string
+	Request  any
+	Response any
+	Status   int
+	Public   bool
+}
+
+// Document is an OpenAPI document. It is safe for concurrent use.
+type Document struct {
+	mu        sync.Mutex
+	title     string
+	version   string
+	paths     map[string]map[string]operation
+	schemas   map[string]*schema
+	errSchema *schema
+}
+
+// New constructs an empty document for the api. The error body is a zero
+// value of what the api responds with when a request fails, which is
+// documented as the default response of every operation.
+func New(title string, version string, errorBody any) *Document {
+	d := Document{
+		title:   title,
+		version: version,
+		paths:   make(map[string]map[string]operation),
+		schemas: make(map[string]*schema),
+	}
+
+	if errorBody != nil {
+		d.errSchema = d.schemaOf(reflect.TypeOf(errorBody))
+	}
+
+	return &d
+}
+
+// Add describes the operation for the method and path. The path uses the
+// {name} form for its parameters, which are documented as strings.
+func (d *Document) Add(method string, path string, op Operation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := operation{
+		Summary:   op.Summary,
+		Tags:      op.Tags,
+		Responses: map[string]response{},
+	}
+
+	for _, name := range pathParams(path) {
+		o.Parameters = append(o.Parameters, parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &schema{Type: "string"},
+		})
+	}
+
+	if op.Request != nil {
+		o.RequestBody = &requestBody{
+			Required: true,
+			Content:  jsonContent(d.schemaOf(reflect.TypeOf(op.Request))),
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	res := response{Description: http.StatusText(status)}
+	if op.Response != nil {
+		res.Content = jsonContent(d.schemaOf(reflect.TypeOf(op.Response)))
+	}
+	o.Responses[strconv.Itoa(status)] = res
+
+	if d.errSchema != nil {
+		o.Responses["default"] = response{
+			Description: "Error",
+			Content:     jsonContent(d.errSchema),
+		}
+	}
+
+	// An empty list overrides the bearer token the document requires.
+	if op.Public {
+		o.Security = &[]map[string][]string{}
+	}
+
+	if d.paths[path] == nil {
+		d.paths[path] = make(map[string]operation)
+	}
+	d.paths[path][strings.ToLower(method)] = o
+}
+
+// MarshalJSON encodes the document.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	doc := struct {
+		OpenAPI    string                          `json:"openapi"`
+		Info       map[string]string               `json:"info"`
+		Paths      map[string]map[string]operation `json:"paths"`
+		Components map[string]any                  `json:"components"`
+		Security   []map[string][]string           `json:"security"`
+	}{
+		OpenAPI: Version,
+		Info:    map[string]string{"title": d.title, "version": d.version},
+		Paths:   d.paths,
+		Components: map[string]any{
+			"schemas": d.schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]string{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		Security: []map[string][]string{{"bearer": {}}},
+	}
+
+	return json.Marshal(doc)
+}
+
+// Handler returns a handler that responds with the document.
+func (d *Document) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.Marshal(d)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// =============================================================================
+
+type operation struct {
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []parameter            `json:"parameters,omitempty"`
+	RequestBody *requestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]response    `json:"responses"`
+	Security    *[]map[string][]string `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool           `json:"required"`
+	Content  map[string]any `json:"content"`
+}
+
+type response struct {
+	Description string         `json:"description"`
+	Content     map[string]any `json:"content,omitempty"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+}
+
+func jsonContent(s *schema) map[string]any {
+	return map[string]any{
+		"application/json": map[string]*schema{"schema": s},
+	}
+}
+
+var paramRE = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+func pathParams(path string) []string {
+	var names []string
+	for _, m := range paramRE.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+
+	return names
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+)
+
+// schemaOf returns the schema of the type. Named structs are added to the
+// components of the document once and referred to from then on. Types that
+// encode themselves as text, such as ids, are strings and types with their
+// own json encoding can't be described, so they are left as any value.
+func (d *Document) schemaOf(t reflect.Type) *schema {
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t.Kind() != reflect.Pointer && (t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler)):
+		return &schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler)):
+		return &schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := d.schemaOf(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &schema{Type: "integer", Format: "int32"}
+
+	case reflect.Int64, reflect.Uint64:
+		return &schema{Type: "integer", Format: "int64"}
+
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+
+	case reflect.String:
+		return &schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: d.schemaOf(t.Elem())}
+
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+
+		name := schemaName(t)
+		if _, exists := d.schemas[name]; !exists {
+			// The entry is reserved before the fields are walked so a type
+			// that refers to itself ends with a reference.
+			d.schemas[name] = &schema{}
+			*d.schemas[name] = *d.structSchema(t)
+		}
+		return &schema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &schema{}
+}
+
+func (d *Document) structSchema(t reflect.Type) *schema {
+	s := schema{
+		Type:       "object",
+		Properties: map[string]*schema{},
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// The fields of embedded structs without a name of their own are
+		// promoted, like encoding/json does.
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := d.structSchema(f.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = d.schemaOf(f.Type)
+	}
+
+	return &s
+}
+
+var pkgPathRE = regexp.MustCompile(`[\w.\-]+/`)
+var invalidNameRE = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// schemaName names the schema of a type after its package and name, which
+// keeps types of the same name in different packages apart. Type arguments
+// are named the same way.
+func schemaName(t reflect.Type) string {
+	name := t.String()
+	if t.PkgPath() != "" {
+		name = t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:] + "." + t.Name()
+	}
+
+	name = pkgPathRE.ReplaceAllString(name, "")
+	name = invalidNameRE.ReplaceAllString(name, "_")
+
+	return strings.TrimRight(name, "_")
+}
@@ -0,0 +1,103 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/foundation/openapi"
+)
+
+type page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type widget struct {
+	ID      uuid.UUID `json:"id"`
+	Name    *string   `json:"name"`
+	Created time.Time `json:"created"`
+	secret  string
+	Skipped string `json:"-"`
+}
+
+type failure struct {
+	Message string `json:"message"`
+}
+
+func Test_Document(t *testing.T) {
+	doc := openapi.New("Widgets", "1.0", failure{})
+	doc.Add("GET", "/widgets", openapi.Operation{Response: page[widget]{}})
+	doc.Add("POST", "/widgets/{widget_id}", openapi.Operation{Request: widget{}, Status: http.StatusCreated})
+	doc.Add("GET", "/health", openapi.Operation{Public: true})
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal: %s", err)
+	}
+
+	var got struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+			Responses map[string]json.RawMessage `json:"responses"`
+			Security  *[]any                     `json:"security"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Type     string `json:"type"`
+					Format   string `json:"format"`
+					Nullable bool   `json:"nullable"`
+				} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %s", err)
+	}
+
+	w, exists := got.Components.Schemas["openapi_test.widget"]
+	if !exists {
+		t.Fatalf("widget schema missing: %s", data)
+	}
+
+	if len(w.Properties) != 3 {
+		t.Errorf("widget properties: got %d, want 3", len(w.Properties))
+	}
+	if p := w.Properties["id"]; p.Type != "string" {
+		t.Errorf("id: got type %q, want string", p.Type)
+	}
+	if p := w.Properties["name"]; !p.Nullable {
+		t.Error("name: should be nullable")
+	}
+	if p := w.Properties["created"]; p.Format != "date-time" {
+		t.Errorf("created: got format %q, want date-time", p.Format)
+	}
+
+	if _, exists := got.Components.Schemas["openapi_test.page_openapi_test.widget"]; !exists {
+		t.Errorf("generic page schema missing: %v", got.Components.Schemas)
+	}
+
+	post := got.Paths["/widgets/{widget_id}"]["post"]
+	if len(post.Parameters) != 1 || post.Parameters[0].Name != "widget_id" || post.Parameters[0].In != "path" {
+		t.Errorf("path parameters: got %+v", post.Parameters)
+	}
+	if _, exists := post.Responses["201"]; !exists {
+		t.Errorf("created response missing: %v", post.Responses)
+	}
+	if _, exists := post.Responses["default"]; !exists {
+		t.Error("error response missing")
+	}
+
+	if s := got.Paths["/health"]["get"].Security; s == nil || len(*s) != 0 {
+		t.Errorf("public operation should clear the security requirement, got %v", s)
+	}
+	if s := got.Paths["/widgets"]["get"].Security; s != nil {
+		t.Errorf("operation should use the document security requirement, got %v", *s)
+	}
+}
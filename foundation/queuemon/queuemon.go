@@ -0,0 +1,158 @@
+// Package queuemon provides support for monitoring the health of asynchronous
+// pipelines such as relays, delivery queues and event consumers. Each pipeline
+// reports its depth, lag and the age of its oldest item which are published
+// as metrics and checked against alert thresholds.
+package queuemon
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+)
+
+// Stats represents a snapshot of the state of a pipeline.
+type Stats struct {
+	Depth     int64
+	Lag       time.Duration
+	OldestAge time.Duration
+}
+
+// Thresholds represents the limits at which an alert is raised. A zero value
+// for any field disables that check.
+type Thresholds struct {
+	Depth     int64
+	Lag       time.Duration
+	OldestAge time.Duration
+}
+
+// ProbeFn returns the current stats for a pipeline.
+type ProbeFn func(ctx context.Context) (Stats, error)
+
+type queue struct {
+	probe      ProbeFn
+	thresholds Thresholds
+	breached   bool
+	vars       *expvar.Map
+}
+
+// Monitor periodically probes the registered pipelines.
+type Monitor struct {
+	log    *logger.Logger
+	mu     sync.Mutex
+	queues map[string]*queue
+	vars   *expvar.Map
+}
+
+// New constructs a Monitor that publishes its metrics under the "queues"
+// expvar key.
+func New(log *logger.Logger) *Monitor {
+	vars, ok := expvar.Get("queues").(*expvar.Map)
+	if !ok {
+		vars = expvar.NewMap("queues")
+	}
+
+	return &Monitor{
+		log:    log,
+		queues: make(map[string]*queue),
+		vars:   vars,
+	}
+}
+
+// Register adds a pipeline to be monitored under the specified name.
+func (m *Monitor) Register(name string, probe ProbeFn, thresholds Thresholds) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q := queue{
+		probe:      probe,
+		thresholds: thresholds,
+		vars:       new(expvar.Map).Init(),
+	}
+
+	m.queues[name] = &q
+	m.vars.Set(name, q.vars)
+}
+
+// Run probes every registered pipeline at the specified interval until the
+// context is canceled.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check probes every registered pipeline once. An error is logged the first
+// time a pipeline breaches any of its thresholds so the alerting events
+// configured on the logger fire once per incident.
+func (m *Monitor) Check(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, q := range m.queues {
+		log := m.log.With("queue", name)
+
+		stats, err := q.probe(ctx)
+		if err != nil {
+			log.Warn(ctx, "queuemon", "status", "probe failed", "msg", err)
+			continue
+		}
+
+		q.vars.Set("depth", intVar(stats.Depth))
+		q.vars.Set("lag_ms", intVar(stats.Lag.Milliseconds()))
+		q.vars.Set("oldest_age_ms", intVar(stats.OldestAge.Milliseconds()))
+
+		reasons := q.thresholds.exceeded(stats)
+
+		switch {
+		case len(reasons) > 0 && !q.breached:
+			q.breached = true
+			log.Error(ctx, "queuemon", "status", "threshold exceeded", "reasons", reasons, "depth", stats.Depth, "lag", stats.Lag.String(), "oldestAge", stats.OldestAge.String())
+
+		case len(reasons) == 0 && q.breached:
+			q.breached = false
+			log.Info(ctx, "queuemon", "status", "threshold recovered", "depth", stats.Depth, "lag", stats.Lag.String(), "oldestAge", stats.OldestAge.String())
+		}
+
+		breached := int64(0)
+		if q.breached {
+			breached = 1
+		}
+		q.vars.Set("breached", intVar(breached))
+	}
+}
+
+func (th Thresholds) exceeded(stats Stats) []string {
+	var reasons []string
+
+	if th.Depth > 0 && stats.Depth > th.Depth {
+		reasons = append(reasons, "depth")
+	}
+
+	if th.Lag > 0 && stats.Lag > th.Lag {
+		reasons = append(reasons, "lag")
+	}
+
+	if th.OldestAge > 0 && stats.OldestAge > th.OldestAge {
+		reasons = append(reasons, "oldestAge")
+	}
+
+	return reasons
+}
+
+func intVar(v int64) *expvar.Int {
+	var i expvar.Int
+	i.Set(v)
+	return &i
+}
@@ -0,0 +1,110 @@
+package reqsign_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/reqsign"
+)
+
+// Test_Verify signs a request and checks the signature is only accepted
+// as it was made, by the service holding the secret and within the skew.
+func Test_Verify(t *testing.T) {
+	const skew = 5 * time.Minute
+
+	secrets := map[string][]byte{
+		"sales": []byte("sales-secret"),
+		"other": []byte("other-secret"),
+	}
+
+	body := `{"userID":"1"}`
+
+	r := httptest.NewRequest(http.MethodPost, "/auth/authorize?x=1", strings.NewReader(body))
+	if err := reqsign.NewSigner("sales", secrets["sales"]).Sign(r); err != nil {
+		t.Fatalf("Should be able to sign the request : %s", err)
+	}
+
+	signed := reqsign.FromRequest(r, []byte(body))
+	now := time.Now()
+
+	service, err := reqsign.Verify(secrets, signed, now, skew)
+	if err != nil {
+		t.Fatalf("Should be able to verify the request : %s", err)
+	}
+	if service != "sales" {
+		t.Errorf("Should get the identity of the signer, got %q", service)
+	}
+
+	// The signer holding another secret.
+	wrongKey := httptest.NewRequest(http.MethodPost, "/auth/authorize?x=1", strings.NewReader(body))
+	if err := reqsign.NewSigner("sales", []byte("not-the-secret")).Sign(wrongKey); err != nil {
+		t.Fatalf("Should be able to sign the request : %s", err)
+	}
+
+	tests := []struct {
+		name string
+		msg  func(msg reqsign.Message) reqsign.Message
+		now  time.Time
+		err  error
+	}{
+		{"unsigned", func(msg reqsign.Message) reqsign.Message { msg.Signature = ""; return msg }, now, reqsign.ErrMissing},
+		{"unknown-identity", func(msg reqsign.Message) reqsign.Message { msg.Identity = "nobody"; return msg }, now, reqsign.ErrUnknownIdentity},
+		{"other-identity", func(msg reqsign.Message) reqsign.Message { msg.Identity = "other"; return msg }, now, reqsign.ErrSignature},
+		{"wrong-key", func(reqsign.Message) reqsign.Message { return reqsign.FromRequest(wrongKey, []byte(body)) }, now, reqsign.ErrSignature},
+		{"tampered-body", func(msg reqsign.Message) reqsign.Message { msg.Body = []byte(`{"userID":"2"}`); return msg }, now, reqsign.ErrDigest},
+		{"tampered-digest", func(msg reqsign.Message) reqsign.Message {
+			// The digest is made to match the tampered body, which the
+			// signature covering it gives away.
+			tampered := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"userID":"2"}`))
+			reqsign.NewSigner("other", nil).Sign(tampered)
+
+			msg.Body = []byte(`{"userID":"2"}`)
+			msg.Digest = tampered.Header.Get(reqsign.DigestHeader)
+			return msg
+		}, now, reqsign.ErrSignature},
+		{"tampered-target", func(msg reqsign.Message) reqsign.Message { msg.Target = "/auth/authorize?x=2"; return msg }, now, reqsign.ErrSignature},
+		{"tampered-method", func(msg reqsign.Message) reqsign.Message { msg.Method = http.MethodPut; return msg }, now, reqsign.ErrSignature},
+		{"tampered-timestamp", func(msg reqsign.Message) reqsign.Message { msg.Timestamp = "1"; return msg }, now, reqsign.ErrExpired},
+		{"moved-timestamp", func(msg reqsign.Message) reqsign.Message {
+			// A replay made to look recent, within the skew of when it is
+			// replayed, no longer matches the signature.
+			msg.Timestamp = shift(t, msg.Timestamp, 2*skew)
+			return msg
+		}, now.Add(2 * skew), reqsign.ErrSignature},
+		{"malformed-timestamp", func(msg reqsign.Message) reqsign.Message { msg.Timestamp = "now"; return msg }, now, reqsign.ErrExpired},
+		{"replayed", func(msg reqsign.Message) reqsign.Message { return msg }, now.Add(skew + time.Minute), reqsign.ErrExpired},
+		{"signer-ahead", func(msg reqsign.Message) reqsign.Message { return msg }, now.Add(-skew - time.Minute), reqsign.ErrExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := reqsign.Verify(secrets, tt.msg(signed), tt.now, skew); !errors.Is(err, tt.err) {
+				t.Errorf("Should fail with %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	// Within the skew either side the signature is still accepted.
+	for _, at := range []time.Time{now.Add(skew - time.Minute), now.Add(-skew + time.Minute)} {
+		if _, err := reqsign.Verify(secrets, signed, at, skew); err != nil {
+			t.Errorf("Should accept the request within the skew : %s", err)
+		}
+	}
+}
+
+// shift moves the unix timestamp by d.
+func shift(t *testing.T, ts string, d time.Duration) string {
+	t.Helper()
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		t.Fatalf("Should be able to parse the timestamp : %s", err)
+	}
+
+	return strconv.FormatInt(sec+int64(d/time.Second), 10)
+}
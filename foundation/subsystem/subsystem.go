@@ -0,0 +1,153 @@
+// Package subsystem provides support for pausing and resuming individual
+// background pipelines of a service at runtime, so a misbehaving pipeline
+// can be contained without taking the rest of the service down.
+package subsystem
+
+import (
+	"sync"
+	"time"
+)
+
+// State represents whether a subsystem is paused and since when.
+type State struct {
+	Name   string
+	Paused bool
+	Since  time.Time
+}
+
+// Switch controls a single subsystem. A nil Switch is never paused so
+// pipelines can accept one unconditionally. It is safe for concurrent use.
+type Switch struct {
+	name   string
+	mu     sync.RWMutex
+	paused bool
+	since  time.Time
+}
+
+// Name returns the name the subsystem was registered under.
+func (sw *Switch) Name() string {
+	if sw == nil {
+		return ""
+	}
+
+	return sw.name
+}
+
+// Paused reports whether the subsystem is paused.
+func (sw *Switch) Paused() bool {
+	if sw == nil {
+		return false
+	}
+
+	sw.mu.RLock()
+	defer sw.mu.RUnlock()
+
+	return sw.paused
+}
+
+// Pause stops the subsystem from doing any more work until it is resumed.
+func (sw *Switch) Pause() {
+	sw.set(true)
+}
+
+// Resume allows a paused subsystem to continue.
+func (sw *Switch) Resume() {
+	sw.set(false)
+}
+
+// State returns the current state of the subsystem.
+func (sw *Switch) State() State {
+	sw.mu.RLock()
+	defer sw.mu.RUnlock()
+
+	return State{
+		Name:   sw.name,
+		Paused: sw.paused,
+		Since:  sw.since,
+	}
+}
+
+func (sw *Switch) set(paused bool) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.paused == paused {
+		return
+	}
+
+	sw.paused = paused
+	sw.since = time.Now()
+}
+
+// =============================================================================
+
+// Set maintains the switches for every subsystem of a service.
+type Set struct {
+	mu       sync.RWMutex
+	switches map[string]*Switch
+	names    []string
+}
+
+// New constructs an empty Set.
+func New() *Set {
+	return &Set{
+		switches: make(map[string]*Switch),
+	}
+}
+
+// Register returns the switch for the named subsystem, adding it in the
+// running state if it doesn't exist yet. Registering against a nil Set
+// returns a nil Switch which is never paused.
+func (s *Set) Register(name string) *Switch {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sw, exists := s.switches[name]; exists {
+		return sw
+	}
+
+	sw := Switch{
+		name:  name,
+		since: time.Now(),
+	}
+
+	s.switches[name] = &sw
+	s.names = append(s.names, name)
+
+	return &sw
+}
+
+// Lookup returns the switch for the named subsystem.
+func (s *Set) Lookup(name string) (*Switch, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sw, exists := s.switches[name]
+	return sw, exists
+}
+
+// States returns the state of every subsystem in the order they were
+// registered.
+func (s *Set) States() []State {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]State, len(s.names))
+	for i, name := range s.names {
+		states[i] = s.switches[name].State()
+	}
+
+	return states
+}
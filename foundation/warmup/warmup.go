@@ -0,0 +1,124 @@
+// Package warmup provides support for running a set of hooks that must
+// complete before a service reports itself as ready to take traffic, and
+// for reporting it's no longer ready once it starts shutting down.
+package warmup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger represents a function that will be called to add information
+// to the logs.
+type Logger func(ctx context.Context, msg string, v ...any)
+
+// HookFn represents a function that primes some part of the system.
+type HookFn func(ctx context.Context) error
+
+// hook represents a named function and the state of its execution.
+type hook struct {
+	name string
+	fn   HookFn
+	done bool
+}
+
+// Warmup maintains the set of hooks that need to complete before the service
+// can be marked ready.
+type Warmup struct {
+	log      Logger
+	retry    time.Duration
+	mu       sync.Mutex
+	hooks    []*hook
+	ready    atomic.Bool
+	draining atomic.Bool
+}
+
+// New constructs a Warmup that will retry failing hooks at the specified
+// interval until they succeed.
+func New(log Logger, retry time.Duration) *Warmup {
+	return &Warmup{
+		log:   log,
+		retry: retry,
+	}
+}
+
+// Add registers a hook to be executed when Run is called. Hooks are executed
+// in the order they are added.
+func (w *Warmup) Add(name string, fn HookFn) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.hooks = append(w.hooks, &hook{name: name, fn: fn})
+}
+
+// Run executes all the registered hooks. Any hook that fails is retried
+// until it succeeds or the context is canceled. Once every hook has completed
+// the warmup is marked as ready.
+func (w *Warmup) Run(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for {
+		pending := 0
+
+		for _, h := range w.hooks {
+			if h.done {
+				continue
+			}
+
+			start := time.Now()
+
+			if err := h.fn(ctx); err != nil {
+				w.log(ctx, "warmup", "status", "hook failed", "hook", h.name, "msg", err)
+				pending++
+				continue
+			}
+
+			h.done = true
+			w.log(ctx, "warmup", "status", "hook complete", "hook", h.name, "since", time.Since(start).String())
+		}
+
+		if pending == 0 {
+			w.ready.Store(true)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.retry):
+		}
+	}
+}
+
+// Ready reports whether all the hooks have completed and the service isn't
+// draining. A nil Warmup is always ready.
+func (w *Warmup) Ready() bool {
+	if w == nil {
+		return true
+	}
+
+	return w.ready.Load() && !w.draining.Load()
+}
+
+// Drain marks the service as shutting down, so Ready reports false from
+// then on and the load balancer moves traffic elsewhere while the requests
+// in flight finish.
+func (w *Warmup) Drain() {
+	if w == nil {
+		return
+	}
+
+	w.draining.Store(true)
+}
+
+// Draining reports whether Drain was called.
+func (w *Warmup) Draining() bool {
+	if w == nil {
+		return false
+	}
+
+	return w.draining.Load()
+}
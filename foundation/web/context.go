@@ -9,6 +9,10 @@ type ctxKey int
 
 const key ctxKey = 1
 
+// TraceIDHeader is set on every response with the trace id of the request so
+// clients can quote it when reporting a problem.
+const TraceIDHeader = "X-Trace-ID"
+
 // Values represent state for each request.
 type Values struct {
 	TraceID    string
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"slices"
+
+	"github.com/mrcruz117/al-service/foundation/openapi"
+)
+
+// Route represents a route registered with the app along with how it was
+// documented.
+type Route struct {
+	Pattern string
+	Doc     openapi.Operation
+}
+
+// Document describes the route with the pattern for the api documentation.
+// The route is documented with only its method and path until it is.
+func (a *App) Document(pattern string, op openapi.Operation) {
+	if a.docs == nil {
+		a.docs = make(map[string]openapi.Operation)
+	}
+	a.docs[pattern] = op
+}
+
+// Routes returns the routes registered with the app in the order they were
+// added. Experimental routes are left out so they stay undocumented.
+func (a *App) Routes() []Route {
+	routes := make([]Route, 0, len(a.patterns))
+	for _, pattern := range a.patterns {
+		if slices.Contains(a.experimental, pattern) {
+			continue
+		}
+
+		routes = append(routes, Route{
+			Pattern: pattern,
+			Doc:     a.docs[pattern],
+		})
+	}
+
+	return routes
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// ExperimentalHeader is set on every response from an experimental route
+// with the capability the route belongs to.
+const ExperimentalHeader = "X-Experimental"
+
+// EnableExperimental turns on the experimental routes that belong to the
+// specified capabilities. It must be called before the routes are added.
+func (a *App) EnableExperimental(capabilities ...string) {
+	a.capabilities = append(a.capabilities, capabilities...)
+}
+
+// HandleFuncExperimental sets a handler function for a route that isn't
+// ready for general use. The route is only registered when its capability
+// has been enabled, or the binary was built with the experimental tag, so
+// incomplete work can ship dark. Every response from the route carries the
+// X-Experimental header.
+func (a *App) HandleFuncExperimental(capability string, pattern string, handler Handler, mw ...MidHandler) {
+	if !experimentalBuild && !slices.Contains(a.capabilities, capability) {
+		return
+	}
+
+	a.experimental = append(a.experimental, pattern)
+
+	// The header is set ahead of the route middleware so responses written
+	// for errors returned by the middleware carry it as well.
+	mw = append([]MidHandler{markExperimental(capability)}, mw...)
+
+	a.HandleFunc(pattern, handler, mw...)
+}
+
+// ExperimentalRoutes returns the patterns of the experimental routes that
+// were registered so they can be left out of the public documentation.
+func (a *App) ExperimentalRoutes() []string {
+	return slices.Clone(a.experimental)
+}
+
+func markExperimental(capability string) MidHandler {
+	m := func(handler Handler) Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set(ExperimentalHeader, capability)
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,7 @@
+//go:build experimental
+
+package web
+
+// experimentalBuild registers every experimental route regardless of the
+// capabilities that are enabled.
+const experimentalBuild = true
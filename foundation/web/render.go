@@ -0,0 +1,84 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Renderer renders HTML pages that share a common layout.
+type Renderer struct {
+	layout string
+	pages  map[string]*template.Template
+}
+
+// NewRenderer parses the layout file together with every page matching the
+// pattern in the file system. Pages are referenced by their file name without
+// the extension and are expected to define the blocks the layout executes.
+func NewRenderer(fsys fs.FS, layout string, pattern string, funcs template.FuncMap) (*Renderer, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+
+	pages := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		if file == layout {
+			continue
+		}
+
+		tmpl, err := template.New(path.Base(layout)).Funcs(funcs).ParseFS(fsys, layout, file)
+		if err != nil {
+			return nil, fmt.Errorf("parsing page[%s]: %w", file, err)
+		}
+
+		name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+		pages[name] = tmpl
+	}
+
+	rn := Renderer{
+		layout: path.Base(layout),
+		pages:  pages,
+	}
+
+	return &rn, nil
+}
+
+// Render executes the named page within the layout and sends the HTML to
+// the client. The page is fully rendered before anything is written so a
+// template error never produces a partial response.
+func (rn *Renderer) Render(ctx context.Context, w http.ResponseWriter, page string, data any, statusCode int) error {
+	tmpl, exists := rn.pages[page]
+	if !exists {
+		return fmt.Errorf("web.render: page %q does not exist", page)
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&b, rn.layout, data); err != nil {
+		return fmt.Errorf("web.render: execute: %w", err)
+	}
+
+	setStatusCode(ctx, statusCode)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("web.render: write: %w", err)
+	}
+
+	return nil
+}
+
+// Redirect replies to the request with a redirect to the specified url.
+func Redirect(ctx context.Context, w http.ResponseWriter, r *http.Request, url string, statusCode int) error {
+	setStatusCode(ctx, statusCode)
+	http.Redirect(w, r, url, statusCode)
+
+	return nil
+}
@@ -3,8 +3,10 @@ package web
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-json-experiment/json"
+	"github.com/google/uuid"
 )
 
 // Param returns the web call parameters from the request.
@@ -12,6 +14,44 @@ func Param(r *http.Request, key string) string {
 	return r.PathValue(key)
 }
 
+// ParseClientID validates an ID chosen by a client against the ID scheme of
+// the system: a non nil UUID in its canonical lowercase form, so the same
+// resource can't be addressed by two spellings of its ID.
+func ParseClientID(s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("id must be a uuid: %w", err)
+	}
+
+	if id == uuid.Nil || id.String() != s {
+		return uuid.UUID{}, fmt.Errorf("id must be a non nil uuid in canonical form")
+	}
+
+	return id, nil
+}
+
+// IfNoneMatchAny reports whether the request carries If-None-Match: *, which
+// asks for the resource to be created only if it doesn't already exist.
+func IfNoneMatchAny(r *http.Request) bool {
+	return strings.TrimSpace(r.Header.Get("If-None-Match")) == "*"
+}
+
+// IfMatch returns the entity tag from the If-Match header without its quotes
+// and reports whether the header was sent.
+func IfMatch(r *http.Request) (string, bool) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		return "", false
+	}
+
+	return strings.Trim(strings.TrimPrefix(v, "W/"), `"`), true
+}
+
+// ETag formats the value as a strong entity tag for the ETag header.
+func ETag(v string) string {
+	return `"` + v + `"`
+}
+
 type validator interface {
 	Validate() error
 }
@@ -2,8 +2,11 @@ package web
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/go-json-experiment/json"
 )
@@ -31,3 +34,51 @@ func Respond(ctx context.Context, w http.ResponseWriter, data any, statusCode in
 
 	return nil
 }
+
+// streamWriteTimeout is how long the writes after each flush of a stream
+// have, in place of the write timeout of the server, which would otherwise
+// cut a long stream off.
+const streamWriteTimeout = 30 * time.Second
+
+// Stream sends a response whose body fn writes as it is produced, such as
+// an export too large to hold in memory. The body is sent with chunked
+// transfer encoding and fn sends what it has written so far by calling
+// flush. The status is sent before fn runs, so an error from fn leaves the
+// client with a truncated body and can't be responded to.
+func Stream(ctx context.Context, w http.ResponseWriter, contentType string, statusCode int, fn func(w io.Writer, flush func() error) error) error {
+	setStatusCode(ctx, statusCode)
+
+	rc := http.NewResponseController(w)
+
+	extend := func() error {
+		err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	flush := func() error {
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return extend()
+	}
+
+	if err := extend(); err != nil {
+		return fmt.Errorf("web.stream: deadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	if err := fn(w, flush); err != nil {
+		return fmt.Errorf("web.stream: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return fmt.Errorf("web.stream: flush: %w", err)
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/foundation/openapi"
 )
 
 // A Handler is a type that handles a http request within our own little mini
@@ -24,14 +25,20 @@ type Logger func(ctx context.Context, msg string, v ...any)
 // data/logic on this App struct.
 type App struct {
 	*http.ServeMux
-	log Logger
-	mw  []MidHandler
+	log          Logger
+	mw           []MidHandler
+	capabilities []string
+	experimental []string
+	routeMW      func(pattern string) []MidHandler
+	patterns     []string
+	docs         map[string]openapi.Operation
 }
 
 // NewApp creates an App value that handle a set of routes for the application.
 func NewApp(log Logger, mw ...MidHandler) *App {
 	return &App{
 		ServeMux: http.NewServeMux(),
+		log:      log,
 		mw:       mw,
 	}
 }
@@ -39,6 +46,10 @@ func NewApp(log Logger, mw ...MidHandler) *App {
 // HandleFunc sets a handler function for a given HTTP method and path pair
 // to the application server mux.
 func (a *App) HandleFunc(pattern string, handler Handler, mw ...MidHandler) {
+	if a.routeMW != nil {
+		handler = wrapMiddleware(a.routeMW(pattern), handler)
+	}
+
 	handler = wrapMiddleware(mw, handler)
 	handler = wrapMiddleware(a.mw, handler)
 
@@ -49,6 +60,7 @@ func (a *App) HandleFunc(pattern string, handler Handler, mw ...MidHandler) {
 		}
 
 		ctx := setValues(r.Context(), &v)
+		w.Header().Set(TraceIDHeader, v.TraceID)
 
 		if err := handler(ctx, w, r); err != nil {
 			if validateError(err) {
@@ -60,6 +72,15 @@ func (a *App) HandleFunc(pattern string, handler Handler, mw ...MidHandler) {
 	}
 
 	a.ServeMux.HandleFunc(pattern, h)
+	a.patterns = append(a.patterns, pattern)
+}
+
+// UseRouteMiddleware sets a function that returns additional middleware for
+// a route based on its pattern. The middleware runs after the middleware the
+// route is registered with, so it can rely on the request being
+// authenticated. It must be called before the routes are added.
+func (a *App) UseRouteMiddleware(fn func(pattern string) []MidHandler) {
+	a.routeMW = fn
 }
 
 // HandleFuncNoMiddleware sets a handler function for a given HTTP method and path pair
@@ -74,6 +95,7 @@ func (a *App) HandleFuncNoMiddleware(pattern string, handler Handler, mw ...MidH
 		}
 
 		ctx := setValues(r.Context(), &v)
+		w.Header().Set(TraceIDHeader, v.TraceID)
 
 		if err := handler(ctx, w, r); err != nil {
 			if validateError(err) {
@@ -85,6 +107,7 @@ func (a *App) HandleFuncNoMiddleware(pattern string, handler Handler, mw ...MidH
 	}
 
 	a.ServeMux.HandleFunc(pattern, h)
+	a.patterns = append(a.patterns, pattern)
 }
 
 func validateError(err error) bool {
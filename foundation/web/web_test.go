@@ -0,0 +1,90 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+// Benchmark_MiddlewareChain measures what the framework adds to a request
+// for every middleware a route is wrapped in.
+func Benchmark_MiddlewareChain(b *testing.B) {
+	for _, n := range []int{0, 5, 10, 20} {
+		b.Run(fmt.Sprintf("mid=%d", n), func(b *testing.B) {
+			mw := make([]web.MidHandler, n)
+			for i := range mw {
+				mw[i] = func(handler web.Handler) web.Handler {
+					return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+						return handler(ctx, w, r)
+					}
+				}
+			}
+
+			app := web.NewApp(func(context.Context, string, ...any) {}, mw...)
+			app.HandleFunc("GET /bench", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				return nil
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/bench", nil)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				app.ServeHTTP(httptest.NewRecorder(), r)
+			}
+		})
+	}
+}
+
+// Benchmark_Respond measures encoding a response for a single document and
+// for a page of documents.
+func Benchmark_Respond(b *testing.B) {
+	type doc struct {
+		ID          string   `json:"id"`
+		Name        string   `json:"name"`
+		Email       string   `json:"email"`
+		Roles       []string `json:"roles"`
+		Enabled     bool     `json:"enabled"`
+		DateCreated string   `json:"dateCreated"`
+	}
+
+	d := doc{
+		ID:          "5cf37266-3473-4006-984f-9325122678b7",
+		Name:        "Bill Kennedy",
+		Email:       "bill@example.com",
+		Roles:       []string{"ADMIN", "USER"},
+		Enabled:     true,
+		DateCreated: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339),
+	}
+
+	page := make([]doc, 100)
+	for i := range page {
+		page[i] = d
+	}
+
+	tests := []struct {
+		name string
+		data any
+	}{
+		{"single", d},
+		{"page=100", page},
+	}
+
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := web.Respond(ctx, httptest.NewRecorder(), tt.data, http.StatusOK); err != nil {
+					b.Fatalf("Should be able to respond: %s", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Package worker provides a pool of goroutines that run jobs in the
+// background, so work a request causes but doesn't have to wait for, such
+// as sending an email or recomputing an aggregate, is done after the
+// response. The pool and its queue are bounded, so a burst of jobs fails to
+// be submitted rather than piling up goroutines.
+package worker
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/queuemon"
+)
+
+// Set of errors returned by Submit.
+var (
+	ErrQueueFull = errors.New("worker queue is full")
+	ErrStopped   = errors.New("worker pool is shutting down")
+)
+
+// Job represents the work to be done. A job that fails is logged and
+// counted, it isn't retried.
+type Job func(ctx context.Context) error
+
+// Config represents the size of the pool. Workers is the number of jobs run
+// at once and QueueSize the number waiting for a worker. Timeout, when set,
+// bounds how long each job can run.
+type Config struct {
+	Workers   int
+	QueueSize int
+	Timeout   time.Duration
+}
+
+type job struct {
+	ctx    context.Context
+	name   string
+	fn     Job
+	queued time.Time
+}
+
+// Pool runs the jobs submitted to it on a fixed number of goroutines.
+type Pool struct {
+	log     *logger.Logger
+	timeout time.Duration
+	jobs    chan job
+
+	// ctx is canceled when a shutdown runs out of time, which cancels the
+	// jobs still running.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+	running atomic.Int64
+
+	submitted *expvar.Int
+	completed *expvar.Int
+	failed    *expvar.Int
+	panics    *expvar.Int
+	rejected  *expvar.Int
+	dropped   *expvar.Int
+}
+
+// New constructs a pool and starts its workers. The counters of the jobs
+// run are published under the name in the "workers" expvar.
+func New(log *logger.Logger, name string, cfg Config) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := Pool{
+		log:       log,
+		timeout:   cfg.Timeout,
+		jobs:      make(chan job, max(cfg.QueueSize, 0)),
+		ctx:       ctx,
+		cancel:    cancel,
+		submitted: new(expvar.Int),
+		completed: new(expvar.Int),
+		failed:    new(expvar.Int),
+		panics:    new(expvar.Int),
+		rejected:  new(expvar.Int),
+		dropped:   new(expvar.Int),
+	}
+
+	vars, ok := expvar.Get("workers").(*expvar.Map)
+	if !ok {
+		vars = expvar.NewMap("workers")
+	}
+
+	pool := new(expvar.Map).Init()
+	pool.Set("submitted", p.submitted)
+	pool.Set("completed", p.completed)
+	pool.Set("failed", p.failed)
+	pool.Set("panics", p.panics)
+	pool.Set("rejected", p.rejected)
+	pool.Set("dropped", p.dropped)
+	pool.Set("queued", expvar.Func(func() any { return len(p.jobs) }))
+	pool.Set("running", expvar.Func(func() any { return p.running.Load() }))
+	vars.Set(name, pool)
+
+	for range max(cfg.Workers, 1) {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work()
+		}()
+	}
+
+	return &p
+}
+
+// Submit queues the job without blocking. The job runs with a context that
+// keeps the values of ctx, such as the trace id, but not its cancellation,
+// since the request that submitted the job is usually done by the time it
+// runs. The name identifies the job in the logs.
+func (p *Pool) Submit(ctx context.Context, name string, fn Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		p.rejected.Add(1)
+		return ErrStopped
+	}
+
+	j := job{
+		ctx:    context.WithoutCancel(ctx),
+		name:   name,
+		fn:     fn,
+		queued: time.Now(),
+	}
+
+	select {
+	case p.jobs <- j:
+		p.submitted.Add(1)
+		return nil
+	default:
+		p.rejected.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops the pool from accepting jobs and waits for the jobs queued
+// and running to finish. When the context is done first the running jobs
+// are canceled and the queued ones dropped, and Shutdown returns without
+// waiting on a job that doesn't stop when its context is canceled.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+
+	case <-ctx.Done():
+		p.cancel()
+		return fmt.Errorf("draining: %w", ctx.Err())
+	}
+}
+
+// Stats reports the jobs waiting and running for monitoring.
+func (p *Pool) Stats(ctx context.Context) (queuemon.Stats, error) {
+	stats := queuemon.Stats{
+		Depth: int64(len(p.jobs)) + p.running.Load(),
+	}
+
+	return stats, nil
+}
+
+// =============================================================================
+
+func (p *Pool) work() {
+	for j := range p.jobs {
+		if p.ctx.Err() != nil {
+			p.dropped.Add(1)
+			p.log.Error(j.ctx, "worker", "status", "job dropped", "job", j.name, "queued", time.Since(j.queued))
+			continue
+		}
+
+		p.run(j)
+	}
+}
+
+// run runs the job, recovering from a panic so it doesn't take the service
+// down with it.
+func (p *Pool) run(j job) {
+	p.running.Add(1)
+	defer p.running.Add(-1)
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if p.timeout > 0 {
+		ctx, cancel = context.WithTimeout(j.ctx, p.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(j.ctx)
+	}
+	defer cancel()
+
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer stop()
+
+	start := time.Now()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			p.panics.Add(1)
+			p.failed.Add(1)
+			p.log.Error(ctx, "worker", "status", "job panicked", "job", j.name, "msg", rec, "stack", string(debug.Stack()))
+		}
+	}()
+
+	if err := j.fn(ctx); err != nil {
+		p.failed.Add(1)
+		p.log.Error(ctx, "worker", "status", "job failed", "job", j.name, "took", time.Since(start), "msg", err)
+		return
+	}
+
+	p.completed.Add(1)
+}
@@ -0,0 +1,93 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrcruz117/al-service/foundation/logger"
+	"github.com/mrcruz117/al-service/foundation/worker"
+)
+
+func Test_Pool(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	pool := worker.New(log, "test", worker.Config{Workers: 2, QueueSize: 10})
+
+	var ran atomic.Int64
+	jobs := []worker.Job{
+		func(ctx context.Context) error { ran.Add(1); return nil },
+		func(ctx context.Context) error { ran.Add(1); return errors.New("failed") },
+		func(ctx context.Context) error { ran.Add(1); panic("boom") },
+		func(ctx context.Context) error { time.Sleep(10 * time.Millisecond); ran.Add(1); return nil },
+	}
+
+	// The context of the request is canceled once it's submitted, the jobs
+	// must still run.
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, job := range jobs {
+		if err := pool.Submit(ctx, "job", job); err != nil {
+			t.Fatalf("Should be able to submit a job : %s", err)
+		}
+	}
+	cancel()
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to drain the pool : %s", err)
+	}
+
+	if n := ran.Load(); n != int64(len(jobs)) {
+		t.Errorf("Should run every job before the shutdown returns, ran %d", n)
+	}
+
+	if err := pool.Submit(context.Background(), "job", jobs[0]); !errors.Is(err, worker.ErrStopped) {
+		t.Errorf("Should not accept jobs once shut down, got %v", err)
+	}
+}
+
+func Test_ShutdownTimeout(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	pool := worker.New(log, "test-timeout", worker.Config{Workers: 1, QueueSize: 1})
+
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+
+	blocking := func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}
+
+	if err := pool.Submit(context.Background(), "blocking", blocking); err != nil {
+		t.Fatalf("Should be able to submit a job : %s", err)
+	}
+	<-started
+
+	noop := func(ctx context.Context) error { return nil }
+
+	if err := pool.Submit(context.Background(), "queued", noop); err != nil {
+		t.Fatalf("Should be able to queue a job : %s", err)
+	}
+
+	if err := pool.Submit(context.Background(), "rejected", noop); !errors.Is(err, worker.ErrQueueFull) {
+		t.Errorf("Should reject a job when the queue is full, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Should time out draining, got %v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Should cancel the running job when the drain times out")
+	}
+}
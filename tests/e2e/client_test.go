@@ -0,0 +1,205 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// env holds the addresses and credentials of the running services.
+type env struct {
+	authURL       string
+	salesURL      string
+	debugURL      string
+	user          string
+	password      string
+	kid           string
+	partner       string
+	partnerSecret string
+
+	// notReady is why the services didn't report ready in time.
+	notReady error
+}
+
+// ready fails the test when the services didn't report ready.
+func (e env) ready(t *testing.T) {
+	t.Helper()
+
+	if e.notReady != nil {
+		t.Fatalf("services: %s", e.notReady)
+	}
+}
+
+func loadEnv() env {
+	return env{
+		authURL:       getenv("E2E_AUTH_URL", "http://localhost:6000"),
+		salesURL:      getenv("E2E_SALES_URL", "http://localhost:3000"),
+		debugURL:      getenv("E2E_SALES_DEBUG_URL", "http://localhost:3010"),
+		user:          getenv("E2E_USER", "admin@example.com"),
+		password:      getenv("E2E_PASSWORD", "gophers"),
+		kid:           getenv("E2E_KID", "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"),
+		partner:       os.Getenv("E2E_WEBHOOK_PARTNER"),
+		partnerSecret: os.Getenv("E2E_WEBHOOK_SECRET"),
+	}
+}
+
+func getenv(key string, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
+
+// waitReady blocks until the service reports ready or the timeout passes.
+func waitReady(ctx context.Context, url string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/readiness", nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready: %w", url, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// response represents what came back from a call to a service.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (r response) decode(t *testing.T, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("decoding response %s: %s", r.body, err)
+	}
+}
+
+// call sends the request and returns the response, failing the test if the
+// request can't be made.
+func call(t *testing.T, method string, url string, body any, header http.Header) response {
+	t.Helper()
+
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		r = bytes.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("encoding request: %s", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), method, url, r)
+	if err != nil {
+		t.Fatalf("constructing request: %s", err)
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %s", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response: %s", err)
+	}
+
+	return response{
+		status: resp.StatusCode,
+		header: resp.Header,
+		body:   data,
+	}
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+// token retrieves a token for the configured user from the auth service.
+func token(t *testing.T, e env) string {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, e.authURL+"/auth/token/"+e.kid, nil)
+	if err != nil {
+		t.Fatalf("constructing request: %s", err)
+	}
+	req.SetBasicAuth(e.user, e.password)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("requesting token: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("requesting token: status %d", resp.StatusCode)
+	}
+
+	var tkn struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tkn); err != nil {
+		t.Fatalf("decoding token: %s", err)
+	}
+
+	return tkn.Token
+}
+
+// metric reads an integer metric published by the sales service.
+func metric(t *testing.T, e env, name string) int64 {
+	t.Helper()
+
+	resp := call(t, http.MethodGet, e.debugURL+"/debug/vars/", nil, nil)
+	if resp.status != http.StatusOK {
+		t.Fatalf("reading metrics: status %d", resp.status)
+	}
+
+	var vars map[string]json.RawMessage
+	resp.decode(t, &vars)
+
+	var v int64
+	if err := json.Unmarshal(vars[name], &v); err != nil {
+		t.Fatalf("metric %q: %s", name, err)
+	}
+
+	return v
+}
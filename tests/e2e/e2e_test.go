@@ -0,0 +1,200 @@
+//go:build e2e
+
+// Package e2e exercises the auth and sales services together over HTTP.
+// The services must already be running, such as in the dev cluster started
+// with make dev-up. Run the suite with:
+//
+//	go test -tags e2e -count=1 ./tests/e2e/...
+//
+// The addresses and credentials default to the dev cluster and can be
+// changed with the E2E_* environment variables.
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrcruz117/al-service/app/api/mid"
+	"github.com/mrcruz117/al-service/foundation/web"
+)
+
+var e2e env
+
+func TestMain(m *testing.M) {
+	e2e = loadEnv()
+
+	for _, url := range []string{e2e.authURL, e2e.salesURL} {
+		if err := waitReady(context.Background(), url, time.Minute); err != nil {
+			e2e.notReady = err
+			break
+		}
+	}
+
+	os.Exit(m.Run())
+}
+
+func Test_TenantFlow(t *testing.T) {
+	e2e.ready(t)
+
+	tkn := token(t, e2e)
+
+	slug := "e2e-" + uuid.NewString()[:8]
+
+	resp := call(t, http.MethodPost, e2e.salesURL+"/admin/tenants", map[string]string{"slug": slug, "name": "End To End"}, bearer(tkn))
+	if resp.status != http.StatusCreated {
+		t.Fatalf("create tenant: status %d: %s", resp.status, resp.body)
+	}
+
+	var tnt struct {
+		ID   string `json:"id"`
+		Slug string `json:"slug"`
+	}
+	resp.decode(t, &tnt)
+
+	if tnt.Slug != slug {
+		t.Fatalf("create tenant: got slug %q, exp %q", tnt.Slug, slug)
+	}
+
+	resp = call(t, http.MethodPost, e2e.salesURL+"/admin/apikeys", map[string]any{"tenant": slug, "name": "e2e", "roles": []string{"USER"}}, bearer(tkn))
+	if resp.status != http.StatusCreated {
+		t.Fatalf("create api key: status %d: %s", resp.status, resp.body)
+	}
+
+	var key struct {
+		ID string `json:"id"`
+	}
+	resp.decode(t, &key)
+
+	resp = call(t, http.MethodGet, e2e.salesURL+"/admin/apikeys/"+key.ID+"?expand=tenant", nil, bearer(tkn))
+	if resp.status != http.StatusOK {
+		t.Fatalf("query api key: status %d: %s", resp.status, resp.body)
+	}
+
+	var info struct {
+		Embedded struct {
+			Tenant struct {
+				ID string `json:"id"`
+			} `json:"tenant"`
+		} `json:"embedded"`
+	}
+	resp.decode(t, &info)
+
+	if info.Embedded.Tenant.ID != tnt.ID {
+		t.Fatalf("query api key: got embedded tenant %q, exp %q", info.Embedded.Tenant.ID, tnt.ID)
+	}
+
+	resp = call(t, http.MethodDelete, e2e.salesURL+"/admin/apikeys/"+key.ID, nil, bearer(tkn))
+	if resp.status != http.StatusNoContent {
+		t.Fatalf("disable api key: status %d: %s", resp.status, resp.body)
+	}
+}
+
+func Test_EventPoll(t *testing.T) {
+	e2e.ready(t)
+
+	tkn := token(t, e2e)
+
+	start := time.Now()
+
+	resp := call(t, http.MethodGet, e2e.salesURL+"/v1/events/poll?wait=1s", nil, bearer(tkn))
+	if resp.status != http.StatusOK {
+		t.Fatalf("poll: status %d: %s", resp.status, resp.body)
+	}
+
+	var page struct {
+		Cursor string `json:"cursor"`
+	}
+	resp.decode(t, &page)
+
+	if _, err := strconv.ParseUint(page.Cursor, 10, 64); err != nil {
+		t.Fatalf("poll: got cursor %q: %s", page.Cursor, err)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Fatalf("poll: held open for %s, exp about 1s", time.Since(start))
+	}
+}
+
+func Test_WebhookDelivery(t *testing.T) {
+	e2e.ready(t)
+
+	if e2e.partner == "" {
+		t.Skip("E2E_WEBHOOK_PARTNER is not set")
+	}
+
+	body := []byte(`{"id":"` + uuid.NewString() + `","type":"e2e.ping","data":{}}`)
+
+	signed := func(secret string, at time.Time) http.Header {
+		ts := strconv.FormatInt(at.Unix(), 10)
+
+		return http.Header{
+			"X-Signature-Timestamp": {ts},
+			"X-Signature":           {mid.Sign([]byte(secret), ts, body)},
+		}
+	}
+
+	header := signed(e2e.partnerSecret, time.Now())
+
+	resp := call(t, http.MethodPost, e2e.salesURL+"/webhooks/"+e2e.partner, body, header)
+	if resp.status != http.StatusAccepted {
+		t.Fatalf("deliver webhook: status %d: %s", resp.status, resp.body)
+	}
+
+	resp = call(t, http.MethodPost, e2e.salesURL+"/webhooks/"+e2e.partner, body, header)
+	if resp.status != http.StatusUnauthorized {
+		t.Fatalf("deliver webhook again: got status %d, exp %d", resp.status, http.StatusUnauthorized)
+	}
+
+	resp = call(t, http.MethodPost, e2e.salesURL+"/webhooks/"+e2e.partner, body, signed("wrong", time.Now()))
+	if resp.status != http.StatusUnauthorized {
+		t.Fatalf("deliver webhook with bad signature: got status %d, exp %d", resp.status, http.StatusUnauthorized)
+	}
+
+	resp = call(t, http.MethodPost, e2e.salesURL+"/webhooks/"+e2e.partner, body, signed(e2e.partnerSecret, time.Now().Add(-time.Hour)))
+	if resp.status != http.StatusUnauthorized {
+		t.Fatalf("deliver expired webhook: got status %d, exp %d", resp.status, http.StatusUnauthorized)
+	}
+}
+
+func Test_TracesAndMetrics(t *testing.T) {
+	e2e.ready(t)
+
+	before := metric(t, e2e, "errors")
+
+	// The test error route only fails half of the time.
+	var resp response
+	for range 20 {
+		if resp = call(t, http.MethodGet, e2e.salesURL+"/testerror", nil, nil); resp.status >= http.StatusBadRequest {
+			break
+		}
+	}
+
+	if resp.status < http.StatusBadRequest {
+		t.Fatalf("test error: got status %d, exp an error", resp.status)
+	}
+
+	traceID := resp.header.Get(web.TraceIDHeader)
+	if traceID == "" {
+		t.Fatalf("test error: missing %s header", web.TraceIDHeader)
+	}
+
+	var env struct {
+		Error struct {
+			TraceID string `json:"trace_id"`
+		} `json:"error"`
+	}
+	resp.decode(t, &env)
+
+	if env.Error.TraceID != traceID {
+		t.Fatalf("test error: got trace id %q in the body, exp %q", env.Error.TraceID, traceID)
+	}
+
+	if after := metric(t, e2e, "errors"); after <= before {
+		t.Fatalf("errors metric: got %d, exp more than %d", after, before)
+	}
+}